@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package applyset implements the ApplySet convention, see
+// https://git.k8s.io/enhancements/keps/sig-cli/3659-kubectl-apply-prune, to prune the
+// objects a reconciler stopped applying for a parent object. Unlike garbage collection
+// through owner references, it also prunes the objects of a parent that still exists,
+// and works for cluster-scoped and cross-namespace objects.
+package applyset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// IDLabel is the label of the parent object holding the ID of its ApplySet.
+	IDLabel = "applyset.kubernetes.io/id"
+
+	// PartOfLabel is the label of the members of an ApplySet holding its ID.
+	PartOfLabel = "applyset.kubernetes.io/part-of"
+
+	// ToolingAnnotation is the annotation of the parent object holding the tool managing
+	// the ApplySet.
+	ToolingAnnotation = "applyset.kubernetes.io/tooling"
+
+	// ContainsGroupKindsAnnotation is the annotation of the parent object holding the
+	// kinds of the members of the ApplySet.
+	ContainsGroupKindsAnnotation = "applyset.kubernetes.io/contains-group-kinds"
+
+	// AdditionalNamespacesAnnotation is the annotation of the parent object holding the
+	// namespaces of the members of the ApplySet other than the namespace of the parent.
+	AdditionalNamespacesAnnotation = "applyset.kubernetes.io/additional-namespaces"
+)
+
+// Options are the options of an ApplySet.
+type Options struct {
+	// Tooling identifies the tool managing the ApplySet, in the form name/version, e.g.
+	// my-operator/v1. ApplySets managed by another tool are refused. Required.
+	Tooling string
+}
+
+// ApplySet tracks the objects a reconciler applies for a parent object, so that the
+// objects of previous reconciles that are not applied anymore can be pruned:
+//
+//	set, err := applyset.New(c, parent, applyset.Options{Tooling: "my-operator/v1"})
+//	...
+//	for _, obj := range desired {
+//		if err := set.Add(obj); err != nil {
+//			...
+//		}
+//	}
+//	if err := set.UpdateParent(ctx); err != nil {
+//		...
+//	}
+//	for _, obj := range desired {
+//		// Create, update or apply obj.
+//	}
+//	if err := set.Prune(ctx); err != nil {
+//		...
+//	}
+//
+// The parent must be the latest version of the object, e.g. the object being reconciled.
+// An ApplySet is meant to be used for a single reconcile and is not safe for concurrent use.
+type ApplySet struct {
+	client  client.Client
+	parent  client.Object
+	tooling string
+	id      string
+
+	parentGVK  schema.GroupVersionKind
+	namespaced bool
+
+	members    sets.Set[memberKey]
+	groupKinds sets.Set[schema.GroupKind]
+	namespaces sets.Set[string]
+}
+
+// memberKey identifies a member of an ApplySet.
+type memberKey struct {
+	groupKind schema.GroupKind
+	types.NamespacedName
+}
+
+// New returns an empty ApplySet for the given parent object.
+func New(c client.Client, parent client.Object, opts Options) (*ApplySet, error) {
+	if opts.Tooling == "" {
+		return nil, errors.New("the tooling of the ApplySet must be set")
+	}
+	if tooling, ok := parent.GetAnnotations()[ToolingAnnotation]; ok && tooling != opts.Tooling {
+		return nil, fmt.Errorf("the ApplySet of %s is managed by %s and not %s", client.ObjectKeyFromObject(parent), tooling, opts.Tooling)
+	}
+	gvk, err := apiutil.GVKForObject(parent, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	namespaced, err := c.IsObjectNamespaced(parent)
+	if err != nil {
+		return nil, err
+	}
+	return &ApplySet{
+		client:     c,
+		parent:     parent,
+		tooling:    opts.Tooling,
+		id:         ID(parent.GetName(), parent.GetNamespace(), gvk.GroupKind()),
+		parentGVK:  gvk,
+		namespaced: namespaced,
+		members:    sets.New[memberKey](),
+		groupKinds: sets.New[schema.GroupKind](),
+		namespaces: sets.New[string](),
+	}, nil
+}
+
+// ID returns the ID of the ApplySet of the parent object with the given name, namespace
+// and kind, as defined by the ApplySet convention.
+func ID(name, namespace string, gk schema.GroupKind) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{name, namespace, gk.Kind, gk.Group}, ".")))
+	return fmt.Sprintf("applyset-%s-v1", base64.RawURLEncoding.EncodeToString(hash[:]))
+}
+
+// ID returns the ID of the ApplySet.
+func (s *ApplySet) ID() string {
+	return s.id
+}
+
+// Add adds obj to the ApplySet and labels it as a member, it must be called before
+// obj is applied.
+func (s *ApplySet) Add(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, s.client.Scheme())
+	if err != nil {
+		return err
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[PartOfLabel] = s.id
+	obj.SetLabels(labels)
+
+	s.members.Insert(memberKey{groupKind: gvk.GroupKind(), NamespacedName: client.ObjectKeyFromObject(obj)})
+	s.groupKinds.Insert(gvk.GroupKind())
+	if obj.GetNamespace() != "" {
+		s.namespaces.Insert(obj.GetNamespace())
+	}
+	return nil
+}
+
+// UpdateParent records the kinds and namespaces of the objects added to the ApplySet
+// on the parent object, in addition to the ones it already records. It should be
+// called before applying the objects, so that they are pruned by a later reconcile even
+// if this one fails before Prune.
+func (s *ApplySet) UpdateParent(ctx context.Context) error {
+	groupKinds, namespaces := s.recorded()
+	return s.patchParent(ctx, groupKinds.Union(s.groupKinds), namespaces.Union(s.namespaces))
+}
+
+// Prune deletes the members of the ApplySet from previous reconciles that were not
+// added to it, and then records exactly the kinds and namespaces of the added objects on
+// the parent object.
+func (s *ApplySet) Prune(ctx context.Context) error {
+	groupKinds, namespaces := s.recorded()
+	groupKinds, namespaces = groupKinds.Union(s.groupKinds), namespaces.Union(s.namespaces)
+	if err := s.patchParent(ctx, groupKinds, namespaces); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, gk := range sortedGroupKinds(groupKinds) {
+		if err := s.pruneGroupKind(ctx, gk, namespaces); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return kerrors.NewAggregate(errs)
+	}
+	return s.patchParent(ctx, s.groupKinds, s.namespaces)
+}
+
+// pruneGroupKind deletes the members of the given kind that were not added to the ApplySet.
+func (s *ApplySet) pruneGroupKind(ctx context.Context, gk schema.GroupKind, namespaces sets.Set[string]) error {
+	mapping, err := s.client.RESTMapper().RESTMapping(gk)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The kind doesn't exist anymore, neither do its objects.
+			return nil
+		}
+		return err
+	}
+
+	listNamespaces := []string{""}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		listNamespaces = sets.List(namespaces)
+	}
+	for _, namespace := range listNamespaces {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(mapping.GroupVersionKind.GroupVersion().WithKind(mapping.GroupVersionKind.Kind + "List"))
+		if err := s.client.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{PartOfLabel: s.id}); err != nil {
+			return fmt.Errorf("failed to list the members of kind %s: %w", gk, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if s.members.Has(memberKey{groupKind: gk, NamespacedName: client.ObjectKeyFromObject(obj)}) {
+				continue
+			}
+			obj.SetGroupVersionKind(mapping.GroupVersionKind)
+			if err := s.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to prune %s %s: %w", gk, client.ObjectKeyFromObject(obj), err)
+			}
+		}
+	}
+	return nil
+}
+
+// recorded returns the kinds and namespaces recorded on the parent object.
+func (s *ApplySet) recorded() (sets.Set[schema.GroupKind], sets.Set[string]) {
+	annotations := s.parent.GetAnnotations()
+	groupKinds := sets.New[schema.GroupKind]()
+	for _, gk := range strings.Split(annotations[ContainsGroupKindsAnnotation], ",") {
+		if gk == "" {
+			continue
+		}
+		groupKinds.Insert(schema.ParseGroupKind(gk))
+	}
+	namespaces := sets.New[string]()
+	for _, namespace := range strings.Split(annotations[AdditionalNamespacesAnnotation], ",") {
+		if namespace != "" {
+			namespaces.Insert(namespace)
+		}
+	}
+	if s.namespaced {
+		namespaces.Insert(s.parent.GetNamespace())
+	}
+	return groupKinds, namespaces
+}
+
+// patchParent records the given kinds and namespaces on the parent object, if they
+// differ from the recorded ones.
+func (s *ApplySet) patchParent(ctx context.Context, groupKinds sets.Set[schema.GroupKind], namespaces sets.Set[string]) error {
+	gks := make([]string, 0, groupKinds.Len())
+	for _, gk := range sortedGroupKinds(groupKinds) {
+		gks = append(gks, gk.String())
+	}
+	additionalNamespaces := sets.List(namespaces.Clone().Delete(s.parent.GetNamespace()))
+
+	annotations := map[string]string{
+		ToolingAnnotation:            s.tooling,
+		ContainsGroupKindsAnnotation: strings.Join(gks, ","),
+	}
+	if len(additionalNamespaces) > 0 {
+		annotations[AdditionalNamespacesAnnotation] = strings.Join(additionalNamespaces, ",")
+	}
+
+	current := s.parent.GetAnnotations()
+	changed := s.parent.GetLabels()[IDLabel] != s.id || current[AdditionalNamespacesAnnotation] != annotations[AdditionalNamespacesAnnotation]
+	for key, value := range annotations {
+		changed = changed || current[key] != value
+	}
+	if !changed {
+		return nil
+	}
+
+	patchAnnotations := map[string]interface{}{
+		// Removes the annotation unless it is set below.
+		AdditionalNamespacesAnnotation: nil,
+	}
+	for key, value := range annotations {
+		patchAnnotations[key] = value
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]string{IDLabel: s.id},
+			"annotations": patchAnnotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	parent := &metav1.PartialObjectMetadata{}
+	parent.SetGroupVersionKind(s.parentGVK)
+	parent.SetNamespace(s.parent.GetNamespace())
+	parent.SetName(s.parent.GetName())
+	if err := s.client.Patch(ctx, parent, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("the parent of the ApplySet %s was not found: %w", s.id, err)
+		}
+		return err
+	}
+	s.parent.SetLabels(parent.GetLabels())
+	s.parent.SetAnnotations(parent.GetAnnotations())
+	return nil
+}
+
+// sortedGroupKinds returns the kinds of the set sorted by their string form, e.g.
+// Deployment.apps.
+func sortedGroupKinds(groupKinds sets.Set[schema.GroupKind]) []schema.GroupKind {
+	sorted := groupKinds.UnsortedList()
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
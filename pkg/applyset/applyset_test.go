@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applyset
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ApplySet", func() {
+	var (
+		ctx    = context.Background()
+		cl     client.Client
+		parent *corev1.ConfigMap
+	)
+
+	configMap := func(namespace, name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	exists := func(obj client.Object) bool {
+		err := cl.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+	apply := func(objs ...client.Object) *ApplySet {
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(parent), parent)).To(Succeed())
+		set, err := New(cl, parent, Options{Tooling: "test/v1"})
+		Expect(err).NotTo(HaveOccurred())
+		for _, obj := range objs {
+			Expect(set.Add(obj)).To(Succeed())
+		}
+		Expect(set.UpdateParent(ctx)).To(Succeed())
+		for _, obj := range objs {
+			Expect(client.IgnoreAlreadyExists(cl.Create(ctx, obj))).To(Succeed())
+		}
+		Expect(set.Prune(ctx)).To(Succeed())
+		return set
+	}
+
+	BeforeEach(func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+		parent = configMap("default", "parent")
+		cl = fake.NewClientBuilder().WithRESTMapper(mapper).WithObjects(parent.DeepCopy()).Build()
+	})
+
+	It("should record the members on the parent", func() {
+		set := apply(configMap("default", "a"), secret("other", "b"))
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(parent), parent)).To(Succeed())
+		Expect(parent.Labels).To(HaveKeyWithValue(IDLabel, set.ID()))
+		Expect(parent.Annotations).To(Equal(map[string]string{
+			ToolingAnnotation:              "test/v1",
+			ContainsGroupKindsAnnotation:   "ConfigMap,Secret",
+			AdditionalNamespacesAnnotation: "other",
+		}))
+
+		member := configMap("default", "a")
+		Expect(exists(member)).To(BeTrue())
+		Expect(member.Labels).To(HaveKeyWithValue(PartOfLabel, set.ID()))
+	})
+
+	It("should prune the members that are not applied anymore", func() {
+		apply(configMap("default", "a"), configMap("default", "b"), secret("other", "c"))
+		unrelated := configMap("default", "unrelated")
+		Expect(cl.Create(ctx, unrelated)).To(Succeed())
+
+		apply(configMap("default", "a"))
+
+		Expect(exists(configMap("default", "a"))).To(BeTrue())
+		Expect(exists(configMap("default", "b"))).To(BeFalse())
+		Expect(exists(secret("other", "c"))).To(BeFalse())
+		Expect(exists(unrelated)).To(BeTrue())
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(parent), parent)).To(Succeed())
+		Expect(parent.Annotations).To(HaveKeyWithValue(ContainsGroupKindsAnnotation, "ConfigMap"))
+		Expect(parent.Annotations).NotTo(HaveKey(AdditionalNamespacesAnnotation))
+	})
+
+	It("should refuse ApplySets managed by other tools", func() {
+		parent.Annotations = map[string]string{ToolingAnnotation: "other/v1"}
+		_, err := New(cl, parent, Options{Tooling: "test/v1"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should compute the ID of the ApplySet convention", func() {
+		Expect(ID("parent", "default", schema.GroupKind{Kind: "ConfigMap"})).To(MatchRegexp(`^applyset-[A-Za-z0-9_-]{43}-v1$`))
+		Expect(ID("parent", "default", schema.GroupKind{Kind: "ConfigMap"})).NotTo(Equal(ID("parent", "other", schema.GroupKind{Kind: "ConfigMap"})))
+	})
+})
@@ -17,18 +17,20 @@ limitations under the License.
 package builder
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/go-logr/logr"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	internalsource "sigs.k8s.io/controller-runtime/pkg/internal/source"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -57,11 +59,15 @@ type Builder struct {
 	forInput         ForInput
 	ownsInput        []OwnsInput
 	watchesInput     []WatchesInput
+	referencesInput  []ReferencesInput
 	mgr              manager.Manager
 	globalPredicates []predicate.Predicate
 	ctrl             controller.Controller
 	ctrlOptions      controller.Options
 	name             string
+	ctrlName         string
+
+	errorConditions *controllerutil.ErrorConditionOptions
 }
 
 // ControllerManagedBy returns a new controller builder that will be started by the provided Manager.
@@ -74,6 +80,7 @@ type ForInput struct {
 	object           client.Object
 	predicates       []predicate.Predicate
 	objectProjection objectProjection
+	initialListLess  func(a, b client.Object) bool
 	err              error
 }
 
@@ -101,6 +108,7 @@ type OwnsInput struct {
 	object           client.Object
 	predicates       []predicate.Predicate
 	objectProjection objectProjection
+	initialListLess  func(a, b client.Object) bool
 }
 
 // Owns defines types of Objects being *generated* by the ControllerManagedBy, and configures the ControllerManagedBy to respond to
@@ -127,6 +135,7 @@ type WatchesInput struct {
 	eventHandler     handler.EventHandler
 	predicates       []predicate.Predicate
 	objectProjection objectProjection
+	initialListLess  func(a, b client.Object) bool
 }
 
 // Watches defines the type of Object to watch, and configures the ControllerManagedBy to respond to create / delete /
@@ -139,6 +148,24 @@ func (blder *Builder) Watches(object client.Object, eventHandler handler.EventHa
 	return blder.WatchesRawSource(src, eventHandler, opts...)
 }
 
+// ReferencesInput represents the information set by WatchesReferences method.
+type ReferencesInput struct {
+	object  client.Object
+	field   string
+	watches []WatchesOption
+}
+
+// WatchesReferences defines a type of Object that is *referenced* by the object being reconciled through the
+// field declared with `ref:"<field>"` (see handler.ReferenceTag), and configures the ControllerManagedBy to respond
+// to create / delete / update events by *reconciling the referrers*.
+//
+// This is the equivalent of indexing the object given to For(...) with handler.ReferenceIndexFunc(field) and calling
+// Watches(object, handler.EnqueueRequestsForReferrers([...], forType, field), opts...).
+func (blder *Builder) WatchesReferences(object client.Object, field string, opts ...WatchesOption) *Builder {
+	blder.referencesInput = append(blder.referencesInput, ReferencesInput{object: object, field: field, watches: opts})
+	return blder
+}
+
 // WatchesMetadata is the same as Watches, but forces the internal cache to only watch PartialObjectMetadata.
 //
 // This is useful when watching lots of objects, really big objects, or objects for which you only know
@@ -207,6 +234,24 @@ func (blder *Builder) WithLogConstructor(logConstructor func(*reconcile.Request)
 	return blder
 }
 
+// EngageWithClustersMatching makes the controller only engage with the provider clusters
+// whose labels match the given selector, e.g. to only reconcile in clusters of a region
+// or an environment, see controller.Options.ClusterSelector.
+func (blder *Builder) EngageWithClustersMatching(selector labels.Selector) *Builder {
+	blder.ctrlOptions.ClusterSelector = selector
+	return blder
+}
+
+// WithReconcileErrorConditions makes reconcile errors visible to the users of the objects
+// of the For() kind: errors are written to a condition on the status of the reconciled
+// object, which is cleared once it is reconciled successfully again, see
+// controllerutil.WithErrorCondition. The status of the kind must have a list of
+// metav1.Condition in its conditions field.
+func (blder *Builder) WithReconcileErrorConditions(opts controllerutil.ErrorConditionOptions) *Builder {
+	blder.errorConditions = &opts
+	return blder
+}
+
 // Named sets the name of the controller to the given name. The name shows up
 // in metrics, among other things, and thus should be a prometheus compatible name
 // (underscores and alphanumeric characters only).
@@ -253,12 +298,10 @@ func (blder *Builder) project(obj client.Object, proj objectProjection) (client.
 	case projectAsNormal:
 		return obj, nil
 	case projectAsMetadata:
-		metaObj := &metav1.PartialObjectMetadata{}
-		gvk, err := getGvk(obj, blder.mgr.GetScheme())
+		metaObj, err := apiutil.PartialObjectMetadataFor(obj, blder.mgr.GetScheme())
 		if err != nil {
-			return nil, fmt.Errorf("unable to determine GVK of %T for a metadata-only watch: %w", obj, err)
+			return nil, err
 		}
-		metaObj.SetGroupVersionKind(gvk)
 		return metaObj, nil
 	default:
 		panic(fmt.Sprintf("unexpected projection type %v on type %T, should not be possible since this is an internal field", proj, obj))
@@ -272,11 +315,12 @@ func (blder *Builder) doWatch() error {
 		if err != nil {
 			return err
 		}
-		src := source.Kind(blder.mgr.GetCache(), obj)
+		src := source.Kind(blder.mgr.GetCache(), obj, kindOptions(blder.forInput.initialListLess)...)
 		hdler := &handler.EnqueueRequestForObject{}
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
 		allPredicates = append(allPredicates, blder.forInput.predicates...)
-		if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
+		instrumentedHdler, instrumentedPredicates := blder.instrumentWatch(obj, hdler, allPredicates)
+		if err := blder.ctrl.Watch(src, instrumentedHdler, instrumentedPredicates...); err != nil {
 			return err
 		}
 	}
@@ -290,7 +334,7 @@ func (blder *Builder) doWatch() error {
 		if err != nil {
 			return err
 		}
-		src := source.Kind(blder.mgr.GetCache(), obj)
+		src := source.Kind(blder.mgr.GetCache(), obj, kindOptions(own.initialListLess)...)
 		opts := []handler.OwnerOption{}
 		if !own.matchEveryOwner {
 			opts = append(opts, handler.OnlyControllerOwner())
@@ -302,33 +346,60 @@ func (blder *Builder) doWatch() error {
 		)
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
 		allPredicates = append(allPredicates, own.predicates...)
-		if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
+		instrumentedHdler, instrumentedPredicates := blder.instrumentWatch(obj, hdler, allPredicates)
+		if err := blder.ctrl.Watch(src, instrumentedHdler, instrumentedPredicates...); err != nil {
 			return err
 		}
 	}
 
+	// Watches the referenced types
+	if len(blder.referencesInput) > 0 && blder.forInput.object == nil {
+		return errors.New("WatchesReferences() can only be used together with For()")
+	}
+	for _, ref := range blder.referencesInput {
+		if err := blder.mgr.GetFieldIndexer().IndexField(context.Background(), blder.forInput.object, ref.field, handler.ReferenceIndexFunc(ref.field)); err != nil {
+			return fmt.Errorf("failed to index references in %s: %w", ref.field, err)
+		}
+		hdler := handler.EnqueueRequestsForReferrers(blder.mgr.GetCache(), blder.mgr.GetScheme(), blder.forInput.object, ref.field)
+		blder.Watches(ref.object, hdler, ref.watches...)
+	}
+
 	// Do the watch requests
 	if len(blder.watchesInput) == 0 && blder.forInput.object == nil {
 		return errors.New("there are no watches configured, controller will never get triggered. Use For(), Owns() or Watches() to set them up")
 	}
 	for _, w := range blder.watchesInput {
 		// If the source of this watch is of type Kind, project it.
+		var obj client.Object
 		if srcKind, ok := w.src.(*internalsource.Kind); ok {
 			typeForSrc, err := blder.project(srcKind.Type, w.objectProjection)
 			if err != nil {
 				return err
 			}
 			srcKind.Type = typeForSrc
+			if w.initialListLess != nil {
+				srcKind.InitialListLess = w.initialListLess
+			}
+			obj = typeForSrc
 		}
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
 		allPredicates = append(allPredicates, w.predicates...)
-		if err := blder.ctrl.Watch(w.src, w.eventHandler, allPredicates...); err != nil {
+		instrumentedHdler, instrumentedPredicates := blder.instrumentWatch(obj, w.eventHandler, allPredicates)
+		if err := blder.ctrl.Watch(w.src, instrumentedHdler, instrumentedPredicates...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// kindOptions returns the options for a Kind source ordering the initial list by less, if set.
+func kindOptions(less func(a, b client.Object) bool) []source.KindOption {
+	if less == nil {
+		return nil
+	}
+	return []source.KindOption{source.WithInitialListOrder(less)}
+}
+
 func (blder *Builder) getControllerName(gvk schema.GroupVersionKind, hasGVK bool) (string, error) {
 	if blder.name != "" {
 		return blder.name, nil
@@ -349,6 +420,12 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 	if ctrlOptions.Reconciler == nil {
 		ctrlOptions.Reconciler = r
 	}
+	if blder.errorConditions != nil {
+		if blder.forInput.object == nil {
+			return errors.New("WithReconcileErrorConditions() can only be used together with For()")
+		}
+		ctrlOptions.Reconciler = controllerutil.WithErrorCondition(blder.mgr.GetClient(), blder.forInput.object, ctrlOptions.Reconciler, *blder.errorConditions)
+	}
 
 	// Retrieve the GVK from the object we're reconciling
 	// to pre-populate logger information, and to optionally generate a default name.
@@ -370,6 +447,9 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 			ctrlOptions.MaxConcurrentReconciles = concurrency
 		}
 	}
+	if ctrlOptions.GroupKind == "" && hasGVK {
+		ctrlOptions.GroupKind = gvk.GroupKind().String()
+	}
 
 	// Setup cache sync timeout.
 	if ctrlOptions.CacheSyncTimeout == 0 && globalOpts.CacheSyncTimeout > 0 {
@@ -380,6 +460,7 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	blder.ctrlName = controllerName
 
 	// Setup the logger.
 	if ctrlOptions.LogConstructor == nil {
@@ -402,6 +483,9 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 				log = log.WithValues(
 					"namespace", req.Namespace, "name", req.Name,
 				)
+				if req.ClusterName != "" {
+					log = log.WithValues("cluster", req.ClusterName)
+				}
 			}
 			return log
 		}
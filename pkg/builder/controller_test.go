@@ -39,6 +39,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -138,6 +139,33 @@ var _ = Describe("application", func() {
 
 		})
 
+		It("should return an error when using WatchesReferences without For", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			instance, err := ControllerManagedBy(m).
+				Named("my_referrer_controller").
+				WatchesReferences(&corev1.Secret{}, "spec.secretRef").
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("WatchesReferences() can only be used together with For()")))
+			Expect(instance).To(BeNil())
+		})
+
+		It("should return an error when using WithReconcileErrorConditions without For", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			instance, err := ControllerManagedBy(m).
+				Named("my_error_conditions_controller").
+				Watches(&corev1.Secret{}, &handler.EnqueueRequestForObject{}).
+				WithReconcileErrorConditions(controllerutil.ErrorConditionOptions{}).
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("WithReconcileErrorConditions() can only be used together with For()")))
+			Expect(instance).To(BeNil())
+		})
+
 		It("should return an error when there are no watches", func() {
 			By("creating a controller manager")
 			m, err := manager.New(cfg, manager.Options{})
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	labelCreate  = "create"
+	labelUpdate  = "update"
+	labelDelete  = "delete"
+	labelGeneric = "generic"
+
+	// unknownGVK is used as the gvk label for sources whose object type can not be determined.
+	unknownGVK = "unknown"
+)
+
+// instrumentWatch wraps the event handler and predicates of a watch, so that the events
+// flowing through them are recorded in the watch pipeline metrics of the controller.
+func (blder *Builder) instrumentWatch(obj client.Object, hdler handler.EventHandler, prct []predicate.Predicate) (handler.EventHandler, []predicate.Predicate) {
+	gvkLabel := unknownGVK
+	if obj != nil {
+		if gvk, err := getGvk(obj, blder.mgr.GetScheme()); err == nil {
+			gvkLabel = gvk.String()
+		}
+	}
+
+	instrumented := make([]predicate.Predicate, 0, len(prct)+1)
+	instrumented = append(instrumented, &receivedPredicate{controller: blder.ctrlName, gvk: gvkLabel})
+	for i, p := range prct {
+		instrumented = append(instrumented, &filterCountingPredicate{
+			Predicate:  p,
+			controller: blder.ctrlName,
+			gvk:        gvkLabel,
+			name:       predicateName(p, i),
+		})
+	}
+
+	return &countingEventHandler{EventHandler: hdler, controller: blder.ctrlName, gvk: gvkLabel}, instrumented
+}
+
+// predicateName returns the name used for a predicate in the filtered events metric.
+// Predicates without a name are identified by their position and type.
+func predicateName(p predicate.Predicate, index int) string {
+	if name := predicate.NameOf(p); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%d:%T", index, p)
+}
+
+// receivedPredicate counts all events of a watch and never filters them.
+type receivedPredicate struct {
+	controller string
+	gvk        string
+}
+
+func (p *receivedPredicate) inc(evt string) bool {
+	ctrlmetrics.WatchEventsTotal.WithLabelValues(p.controller, p.gvk, evt).Inc()
+	return true
+}
+
+// Create implements predicate.Predicate.
+func (p *receivedPredicate) Create(event.CreateEvent) bool { return p.inc(labelCreate) }
+
+// Update implements predicate.Predicate.
+func (p *receivedPredicate) Update(event.UpdateEvent) bool { return p.inc(labelUpdate) }
+
+// Delete implements predicate.Predicate.
+func (p *receivedPredicate) Delete(event.DeleteEvent) bool { return p.inc(labelDelete) }
+
+// Generic implements predicate.Predicate.
+func (p *receivedPredicate) Generic(event.GenericEvent) bool { return p.inc(labelGeneric) }
+
+// filterCountingPredicate counts the events rejected by the wrapped predicate.
+type filterCountingPredicate struct {
+	predicate.Predicate
+	controller string
+	gvk        string
+	name       string
+}
+
+func (p *filterCountingPredicate) record(evt string, ok bool) bool {
+	if !ok {
+		ctrlmetrics.WatchEventsFilteredTotal.WithLabelValues(p.controller, p.gvk, evt, p.name).Inc()
+	}
+	return ok
+}
+
+// Create implements predicate.Predicate.
+func (p *filterCountingPredicate) Create(e event.CreateEvent) bool {
+	return p.record(labelCreate, p.Predicate.Create(e))
+}
+
+// Update implements predicate.Predicate.
+func (p *filterCountingPredicate) Update(e event.UpdateEvent) bool {
+	return p.record(labelUpdate, p.Predicate.Update(e))
+}
+
+// Delete implements predicate.Predicate.
+func (p *filterCountingPredicate) Delete(e event.DeleteEvent) bool {
+	return p.record(labelDelete, p.Predicate.Delete(e))
+}
+
+// Generic implements predicate.Predicate.
+func (p *filterCountingPredicate) Generic(e event.GenericEvent) bool {
+	return p.record(labelGeneric, p.Predicate.Generic(e))
+}
+
+// countingEventHandler counts the requests the wrapped handler adds to the queue
+// and the events that did not result in any request.
+type countingEventHandler struct {
+	handler.EventHandler
+	controller string
+	gvk        string
+}
+
+func (h *countingEventHandler) record(evt string, q *countingQueue) {
+	added := q.added.Load()
+	if added == 0 {
+		ctrlmetrics.WatchEventsDroppedTotal.WithLabelValues(h.controller, h.gvk, evt).Inc()
+		return
+	}
+	ctrlmetrics.WatchRequestsTotal.WithLabelValues(h.controller, h.gvk, evt).Add(float64(added))
+}
+
+// Create implements handler.EventHandler.
+func (h *countingEventHandler) Create(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	cq := &countingQueue{RateLimitingInterface: q}
+	h.EventHandler.Create(ctx, e, cq)
+	h.record(labelCreate, cq)
+}
+
+// Update implements handler.EventHandler.
+func (h *countingEventHandler) Update(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	cq := &countingQueue{RateLimitingInterface: q}
+	h.EventHandler.Update(ctx, e, cq)
+	h.record(labelUpdate, cq)
+}
+
+// Delete implements handler.EventHandler.
+func (h *countingEventHandler) Delete(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	cq := &countingQueue{RateLimitingInterface: q}
+	h.EventHandler.Delete(ctx, e, cq)
+	h.record(labelDelete, cq)
+}
+
+// Generic implements handler.EventHandler.
+func (h *countingEventHandler) Generic(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	cq := &countingQueue{RateLimitingInterface: q}
+	h.EventHandler.Generic(ctx, e, cq)
+	h.record(labelGeneric, cq)
+}
+
+// countingQueue counts the items added to the wrapped queue.
+type countingQueue struct {
+	workqueue.RateLimitingInterface
+	added atomic.Int64
+}
+
+// Add implements workqueue.Interface.
+func (q *countingQueue) Add(item interface{}) {
+	q.added.Add(1)
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *countingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.added.Add(1)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *countingQueue) AddRateLimited(item interface{}) {
+	q.added.Add(1)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
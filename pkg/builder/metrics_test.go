@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("watch pipeline metrics", func() {
+	counterValue := func(c interface{ Write(*dto.Metric) error }) float64 {
+		var metric dto.Metric
+		Expect(c.Write(&metric)).To(Succeed())
+		return metric.GetCounter().GetValue()
+	}
+
+	It("should count received, filtered, mapped and dropped events", func() {
+		blder := &Builder{ctrlName: "pipeline-metrics"}
+		rejectBar := predicate.NewPredicateFuncs(func(o client.Object) bool { return o.GetName() != "bar" })
+		mapToNothingForBaz := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			if o.GetName() == "baz" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(o)}}
+		})
+
+		hdler, prct := blder.instrumentWatch(nil, mapToNothingForBaz, []predicate.Predicate{rejectBar})
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		for _, name := range []string{"foo", "bar", "baz"} {
+			evt := event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}}
+			passed := true
+			for _, p := range prct {
+				if !p.Create(evt) {
+					passed = false
+					break
+				}
+			}
+			if passed {
+				hdler.Create(context.Background(), evt, q)
+			}
+		}
+
+		Expect(counterValue(ctrlmetrics.WatchEventsTotal.WithLabelValues("pipeline-metrics", unknownGVK, labelCreate))).To(Equal(3.0))
+		Expect(counterValue(ctrlmetrics.WatchEventsFilteredTotal.WithLabelValues("pipeline-metrics", unknownGVK, labelCreate, predicateName(rejectBar, 0)))).To(Equal(1.0))
+		Expect(counterValue(ctrlmetrics.WatchRequestsTotal.WithLabelValues("pipeline-metrics", unknownGVK, labelCreate))).To(Equal(1.0))
+		Expect(counterValue(ctrlmetrics.WatchEventsDroppedTotal.WithLabelValues("pipeline-metrics", unknownGVK, labelCreate))).To(Equal(1.0))
+		Expect(q.Len()).To(Equal(1))
+	})
+})
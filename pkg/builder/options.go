@@ -17,6 +17,7 @@ limitations under the License.
 package builder
 
 import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -75,6 +76,37 @@ var _ ForOption = &Predicates{}
 var _ OwnsOption = &Predicates{}
 var _ WatchesOption = &Predicates{}
 
+// WithInitialListOrder delivers the Create events for the initial list of objects in the
+// order given by less when the controller starts, e.g. source.ByNamespacedName. For sources
+// of Watches that are not a Kind, it has no effect.
+func WithInitialListOrder(less func(a, b client.Object) bool) InitialListOrder {
+	return InitialListOrder{less: less}
+}
+
+// InitialListOrder orders the Create events for the initial list of objects.
+type InitialListOrder struct {
+	less func(a, b client.Object) bool
+}
+
+// ApplyToFor applies this configuration to the given ForInput options.
+func (o InitialListOrder) ApplyToFor(opts *ForInput) {
+	opts.initialListLess = o.less
+}
+
+// ApplyToOwns applies this configuration to the given OwnsInput options.
+func (o InitialListOrder) ApplyToOwns(opts *OwnsInput) {
+	opts.initialListLess = o.less
+}
+
+// ApplyToWatches applies this configuration to the given WatchesInput options.
+func (o InitialListOrder) ApplyToWatches(opts *WatchesInput) {
+	opts.initialListLess = o.less
+}
+
+var _ ForOption = &InitialListOrder{}
+var _ OwnsOption = &InitialListOrder{}
+var _ WatchesOption = &InitialListOrder{}
+
 // }}}
 
 // {{{ For & Owns Dual-Type options
@@ -18,6 +18,7 @@ package builder
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -30,6 +31,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
@@ -44,6 +46,9 @@ type WebhookBuilder struct {
 	config          *rest.Config
 	recoverPanic    bool
 	logConstructor  func(base logr.Logger, req *admission.Request) logr.Logger
+
+	admissionServer  string
+	conversionServer string
 }
 
 // WebhookManagedBy returns a new webhook builder.
@@ -79,6 +84,22 @@ func (blder *WebhookBuilder) WithLogConstructor(logConstructor func(base logr.Lo
 	return blder
 }
 
+// WithWebhookServer registers the defaulting and validating webhooks on the webhook
+// server with the given name, see manager.Options.WebhookServers. By default, they are
+// registered on the server returned by GetWebhookServer.
+func (blder *WebhookBuilder) WithWebhookServer(name string) *WebhookBuilder {
+	blder.admissionServer = name
+	return blder
+}
+
+// WithConversionWebhookServer registers the conversion webhook on the webhook server
+// with the given name, see manager.Options.WebhookServers. By default, it is registered
+// on the server returned by GetWebhookServer.
+func (blder *WebhookBuilder) WithConversionWebhookServer(name string) *WebhookBuilder {
+	blder.conversionServer = name
+	return blder
+}
+
 // RecoverPanic indicates whether panics caused by the webhook should be recovered.
 func (blder *WebhookBuilder) RecoverPanic() *WebhookBuilder {
 	blder.recoverPanic = true
@@ -135,8 +156,12 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 	}
 
 	// Register webhook(s) for type
-	blder.registerDefaultingWebhook()
-	blder.registerValidatingWebhook()
+	if err := blder.registerDefaultingWebhook(); err != nil {
+		return err
+	}
+	if err := blder.registerValidatingWebhook(); err != nil {
+		return err
+	}
 
 	err = blder.registerConversionWebhook()
 	if err != nil {
@@ -145,22 +170,36 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 	return nil
 }
 
+// webhookServer returns the webhook server with the given name.
+func (blder *WebhookBuilder) webhookServer(name string) (webhook.Server, error) {
+	srv := manager.GetNamedWebhookServer(blder.mgr, name)
+	if srv == nil {
+		return nil, fmt.Errorf("no webhook server named %q configured in the manager", name)
+	}
+	return srv, nil
+}
+
 // registerDefaultingWebhook registers a defaulting webhook if necessary.
-func (blder *WebhookBuilder) registerDefaultingWebhook() {
+func (blder *WebhookBuilder) registerDefaultingWebhook() error {
 	mwh := blder.getDefaultingWebhook()
 	if mwh != nil {
+		srv, err := blder.webhookServer(blder.admissionServer)
+		if err != nil {
+			return err
+		}
 		mwh.LogConstructor = blder.logConstructor
 		path := generateMutatePath(blder.gvk)
 
 		// Checking if the path is already registered.
 		// If so, just skip it.
-		if !blder.isAlreadyHandled(path) {
+		if !isAlreadyHandled(srv, path) {
 			log.Info("Registering a mutating webhook",
 				"GVK", blder.gvk,
 				"path", path)
-			blder.mgr.GetWebhookServer().Register(path, mwh)
+			srv.Register(path, mwh)
 		}
 	}
+	return nil
 }
 
 func (blder *WebhookBuilder) getDefaultingWebhook() *admission.Webhook {
@@ -177,21 +216,26 @@ func (blder *WebhookBuilder) getDefaultingWebhook() *admission.Webhook {
 }
 
 // registerValidatingWebhook registers a validating webhook if necessary.
-func (blder *WebhookBuilder) registerValidatingWebhook() {
+func (blder *WebhookBuilder) registerValidatingWebhook() error {
 	vwh := blder.getValidatingWebhook()
 	if vwh != nil {
+		srv, err := blder.webhookServer(blder.admissionServer)
+		if err != nil {
+			return err
+		}
 		vwh.LogConstructor = blder.logConstructor
 		path := generateValidatePath(blder.gvk)
 
 		// Checking if the path is already registered.
 		// If so, just skip it.
-		if !blder.isAlreadyHandled(path) {
+		if !isAlreadyHandled(srv, path) {
 			log.Info("Registering a validating webhook",
 				"GVK", blder.gvk,
 				"path", path)
-			blder.mgr.GetWebhookServer().Register(path, vwh)
+			srv.Register(path, vwh)
 		}
 	}
+	return nil
 }
 
 func (blder *WebhookBuilder) getValidatingWebhook() *admission.Webhook {
@@ -214,8 +258,12 @@ func (blder *WebhookBuilder) registerConversionWebhook() error {
 		return err
 	}
 	if ok {
-		if !blder.isAlreadyHandled("/convert") {
-			blder.mgr.GetWebhookServer().Register("/convert", conversion.NewWebhookHandler(blder.mgr.GetScheme()))
+		srv, err := blder.webhookServer(blder.conversionServer)
+		if err != nil {
+			return err
+		}
+		if !isAlreadyHandled(srv, "/convert") {
+			srv.Register("/convert", conversion.NewWebhookHandler(blder.mgr.GetScheme()))
 		}
 		log.Info("Conversion webhook enabled", "GVK", blder.gvk)
 	}
@@ -230,11 +278,11 @@ func (blder *WebhookBuilder) getType() (runtime.Object, error) {
 	return nil, errors.New("For() must be called with a valid object")
 }
 
-func (blder *WebhookBuilder) isAlreadyHandled(path string) bool {
-	if blder.mgr.GetWebhookServer().WebhookMux() == nil {
+func isAlreadyHandled(srv webhook.Server, path string) bool {
+	if srv.WebhookMux() == nil {
 		return false
 	}
-	h, p := blder.mgr.GetWebhookServer().WebhookMux().Handler(&http.Request{URL: &url.URL{Path: path}})
+	h, p := srv.WebhookMux().Handler(&http.Request{URL: &url.URL{Path: path}})
 	if p == path && h != nil {
 		return true
 	}
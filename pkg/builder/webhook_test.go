@@ -39,6 +39,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
@@ -672,6 +673,53 @@ func runTests(admissionReviewVersion string) {
 		ExpectWithOffset(1, w.Body).To(ContainSubstring(`"allowed":true`))
 		ExpectWithOffset(1, w.Body).To(ContainSubstring(`"code":200`))
 	})
+
+	It("should register the webhooks on the named webhook server", func() {
+		By("creating a controller manager with a named webhook server")
+		admissionServer := webhook.NewServer(webhook.Options{Port: 9444})
+		servers := map[string]webhook.Server{"admission": admissionServer}
+		m, err := manager.New(cfg, manager.Options{
+			WebhookServers: servers,
+		})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		// Changing the options after creating the manager has no effect.
+		delete(servers, "admission")
+
+		By("registering the type in the Scheme")
+		builder := scheme.Builder{GroupVersion: testDefaulterGVK.GroupVersion()}
+		builder.Register(&TestDefaulter{}, &TestDefaulterList{})
+		err = builder.AddToScheme(m.GetScheme())
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		err = WebhookManagedBy(m).
+			For(&TestDefaulter{}).
+			WithWebhookServer("admission").
+			Complete()
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		ExpectWithOffset(1, manager.GetNamedWebhookServer(m, "admission")).To(BeIdenticalTo(admissionServer))
+
+		path := generateMutatePath(testDefaulterGVK)
+		ExpectWithOffset(1, isAlreadyHandled(admissionServer, path)).To(BeTrue())
+		ExpectWithOffset(1, isAlreadyHandled(m.GetWebhookServer(), path)).To(BeFalse())
+	})
+
+	It("should fail to register webhooks on an unknown webhook server", func() {
+		By("creating a controller manager")
+		m, err := manager.New(cfg, manager.Options{})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		By("registering the type in the Scheme")
+		builder := scheme.Builder{GroupVersion: testDefaulterGVK.GroupVersion()}
+		builder.Register(&TestDefaulter{}, &TestDefaulterList{})
+		err = builder.AddToScheme(m.GetScheme())
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		err = WebhookManagedBy(m).
+			For(&TestDefaulter{}).
+			WithWebhookServer("unknown").
+			Complete()
+		ExpectWithOffset(1, err).To(MatchError(ContainSubstring(`no webhook server named "unknown"`)))
+	})
 }
 
 // TestDefaulter.
@@ -179,6 +179,61 @@ type Options struct {
 	// for every new requested resource.
 	ReaderFailOnMissingInformer bool
 
+	// ReaderReadThroughOnMissingInformer configures the cache to read the objects of the
+	// kinds it has no started informer for with Get() and List() from the API server,
+	// instead of starting an informer for every new requested resource. This is useful
+	// for controllers that rarely read the objects of many kinds, as informers hold all
+	// the objects of their kind in memory.
+	//
+	// Informers are still started by GetInformer(), e.g. for the watches of controllers,
+	// after which the cache serves the reads of their kinds. The objects read from the
+	// API server are restricted to the namespaces and selectors of the cache, but not
+	// transformed, and field selectors must be supported by the API server. The reads are
+	// counted in the controller_runtime_cache_read_through_total metric.
+	//
+	// This must not be set together with ReaderFailOnMissingInformer.
+	ReaderReadThroughOnMissingInformer bool
+
+	// IdleInformerTTL, if set, makes the cache stop and remove the informers that have
+	// had no event handlers and weren't used to read objects during this duration, like
+	// RemoveInformer does. This frees the memory and watches of the kinds that are only
+	// read occasionally, at the cost of starting their informers again when they are
+	// needed next. Informers with field indexes and informers passed in ByObject are
+	// never removed.
+	//
+	// The removed informers are counted in the
+	// controller_runtime_cache_informer_evictions_total metric.
+	//
+	// Defaults to zero, which means that informers are only removed by RemoveInformer.
+	IdleInformerTTL time.Duration
+
+	// UseWatchList makes the informers stream the objects they initially list with a
+	// watch sending the initial events, which the API server serves from its watch
+	// cache, instead of listing them. This avoids the memory spikes of the API server
+	// serving large lists and speeds up the initial sync of informers of many objects.
+	//
+	// The streamed objects are still collected into a list on the client before they
+	// are added to the informer, so this doesn't reduce the memory of the cache.
+	//
+	// It requires the WatchList feature of the API server, informers fall back to
+	// paginated lists if it isn't enabled. API servers that don't support it are
+	// detected when they reject the watch or don't mark the end of the initial
+	// objects within ten seconds of the last one. Objects whose ByObject.ListWatch or
+	// ByObject.PollInterval is set are always listed.
+	UseWatchList bool
+
+	// TrackInformerObjects makes the informers count their objects and approximate
+	// their size, which is reported in their InformerStats and the
+	// controller_runtime_cache_informer_objects and
+	// controller_runtime_cache_informer_object_bytes metrics. The size of every added,
+	// updated and deleted object is computed with its protobuf encoding, or its JSON
+	// encoding for the kinds that don't support protobuf, which can be expensive for
+	// caches with frequently changing objects.
+	//
+	// Defaults to false, which means that only the last syncs and watch errors of the
+	// informers are tracked.
+	TrackInformerObjects bool
+
 	// DefaultNamespaces maps namespace names to cache configs. If set, only
 	// the namespaces in here will be watched and it will by used to default
 	// ByObject.Namespaces for all objects if that is nil.
@@ -190,6 +245,8 @@ type Options struct {
 	//
 	// The options in the Config that are nil will be defaulted from
 	// the respective Default* settings.
+	//
+	// The namespaces can be changed at runtime with SetNamespaces.
 	DefaultNamespaces map[string]Config
 
 	// DefaultLabelSelector will be used as a label selector for all objects
@@ -202,6 +259,9 @@ type Options struct {
 
 	// DefaultTransform will be used as transform for all object types
 	// unless there is already one set in ByObject or DefaultNamespaces.
+	//
+	// See TransformStripManagedFields and TransformChain to reduce the memory
+	// used by the cache.
 	DefaultTransform toolscache.TransformFunc
 
 	// DefaultWatchErrorHandler will be used to the WatchErrorHandler which is called
@@ -210,6 +270,13 @@ type Options struct {
 	// After calling this handler, the informer will backoff and retry.
 	DefaultWatchErrorHandler toolscache.WatchErrorHandler
 
+	// InformerWatchErrorHandler will be used as the WatchErrorHandler of all informers
+	// unless there is already one set in ByObject. Unlike DefaultWatchErrorHandler, it
+	// can stop the informers, e.g. with StopOnPersistentWatchErrors, and it takes
+	// precedence over DefaultWatchErrorHandler, which is only called for the informers
+	// without a WatchErrorHandler.
+	InformerWatchErrorHandler WatchErrorHandler
+
 	// DefaultUnsafeDisableDeepCopy is the default for UnsafeDisableDeepCopy
 	// for everything that doesn't specify this.
 	//
@@ -224,6 +291,23 @@ type Options struct {
 	// object, this will fall through to Default* settings.
 	ByObject map[client.Object]ByObject
 
+	// externalInformers are the informers of ByObject that were created externally.
+	externalInformers map[schema.GroupVersionKind]toolscache.SharedIndexInformer
+
+	// listWatches are the ListWatch customizations of ByObject.
+	listWatches map[schema.GroupVersionKind]internal.ListWatchFunc
+
+	// watchErrorHandlers are the WatchErrorHandlers of ByObject.
+	watchErrorHandlers map[schema.GroupVersionKind]WatchErrorHandler
+
+	// byObjectWithDefaultNamespaces are the kinds of ByObject whose Namespaces were
+	// defaulted to DefaultNamespaces.
+	byObjectWithDefaultNamespaces map[schema.GroupVersionKind]bool
+
+	// readThroughClient is the client reading the objects of kinds without informers if
+	// ReaderReadThroughOnMissingInformer is set.
+	readThroughClient client.Reader
+
 	// newInformer allows overriding of NewSharedIndexInformer for testing.
 	newInformer *func(toolscache.ListerWatcher, runtime.Object, time.Duration, toolscache.Indexers) toolscache.SharedIndexInformer
 }
@@ -270,8 +354,61 @@ type ByObject struct {
 	// Be very careful with this, when enabled you must DeepCopy any object before mutating it,
 	// otherwise you will mutate the object in the cache.
 	UnsafeDisableDeepCopy *bool
+
+	// Informer is an externally created informer for the object, e.g. from a client-go
+	// SharedInformerFactory, that the cache uses instead of creating its own, so that a
+	// single watch is shared. The informer must hold objects of the type of the object.
+	// It is only used for structured objects, unstructured and metadata-only objects of
+	// the same type get informers of the cache.
+	//
+	// The cache runs the informer if it wasn't started yet, and it is stopped with the
+	// cache in that case. An informer started by its creator is never run again by the
+	// cache and keeps running when the cache stops. External informers are never
+	// evicted as idle and have no stats.
+	//
+	// Indexers, including those added through IndexField and the namespace index the
+	// cache requires, can only be added to an informer that wasn't started yet. Either
+	// start the informer after the cache and its indexes were set up, or create it with
+	// a cache.NamespaceIndex indexer and don't call IndexField for the object.
+	//
+	// Namespaces, Label, Field and Transform must not be set together with Informer,
+	// as the informer is configured by its creator.
+	Informer toolscache.SharedIndexInformer
+
+	// ListWatch customizes how the informer of the object lists and watches it, e.g. to
+	// change the page size of lists, to use different endpoints or to poll APIs that
+	// don't support watches, like metrics.k8s.io, using PollingListWatch.
+	//
+	// It is called with the default ListerWatcher of the object. Namespaces, Label
+	// and Field are applied to the options before they are passed to the returned
+	// ListerWatcher.
+	//
+	// This must not be set together with Informer.
+	ListWatch ListWatchFunc
+
+	// PollInterval, if set, makes the cache poll the objects in this interval instead
+	// of watching them, for APIs that don't support watches. The changes between polls
+	// are delivered to event handlers like watch events, so that polled objects can be
+	// used like any other object, e.g. in the sources of controllers. See
+	// PollingListWatch for details.
+	//
+	// If ListWatch is set as well, the ListerWatcher returned by it is polled.
+	//
+	// This must not be set together with Informer.
+	PollInterval time.Duration
+
+	// WatchErrorHandler decides whether the informer of the object retries or stops
+	// after an error ended its watch, e.g. to stop it on persistent Forbidden errors
+	// instead of retrying forever. Defaults to the cache's InformerWatchErrorHandler.
+	//
+	// This must not be set together with Informer.
+	WatchErrorHandler WatchErrorHandler
 }
 
+// ListWatchFunc customizes the ListerWatcher used by the cache for a GroupVersionKind.
+// It gets passed the default ListerWatcher and returns the one to use instead.
+type ListWatchFunc func(gvk schema.GroupVersionKind, lw toolscache.ListerWatcher) toolscache.ListerWatcher
+
 // Config describes all potential options for a given watch.
 type Config struct {
 	// LabelSelector specifies a label selector. A nil value allows to
@@ -310,10 +447,17 @@ func New(cfg *rest.Config, opts Options) (Cache, error) {
 
 	newCacheFunc := newCache(cfg, opts)
 
+	// The namespaces of caches watching all namespaces can't be changed with SetNamespaces.
+	var addedNamespaceConfig *Config
+	if _, hasAllNamespaces := opts.DefaultNamespaces[metav1.NamespaceAll]; !hasAllNamespaces {
+		defaultConfig := optionDefaultsToConfig(&opts)
+		addedNamespaceConfig = &defaultConfig
+	}
+
 	var defaultCache Cache
 	if len(opts.DefaultNamespaces) > 0 {
 		defaultConfig := optionDefaultsToConfig(&opts)
-		defaultCache = newMultiNamespaceCache(newCacheFunc, opts.Scheme, opts.Mapper, opts.DefaultNamespaces, &defaultConfig)
+		defaultCache = newMultiNamespaceCache(newCacheFunc, opts.Scheme, opts.Mapper, opts.DefaultNamespaces, &defaultConfig, addedNamespaceConfig)
 	} else {
 		defaultCache = newCacheFunc(optionDefaultsToConfig(&opts), corev1.NamespaceAll)
 	}
@@ -335,7 +479,12 @@ func New(cfg *rest.Config, opts Options) (Cache, error) {
 		}
 		var cache Cache
 		if len(config.Namespaces) > 0 {
-			cache = newMultiNamespaceCache(newCacheFunc, opts.Scheme, opts.Mapper, config.Namespaces, nil)
+			// Only the namespaces defaulted to DefaultNamespaces follow SetNamespaces.
+			var byObjectAddedNamespaceConfig *Config
+			if opts.byObjectWithDefaultNamespaces[gvk] {
+				byObjectAddedNamespaceConfig = addedNamespaceConfig
+			}
+			cache = newMultiNamespaceCache(newCacheFunc, opts.Scheme, opts.Mapper, config.Namespaces, nil, byObjectAddedNamespaceConfig)
 		} else {
 			cache = newCacheFunc(byObjectToConfig(config), corev1.NamespaceAll)
 		}
@@ -367,6 +516,15 @@ type newCacheFunc func(config Config, namespace string) Cache
 
 func newCache(restConfig *rest.Config, opts Options) newCacheFunc {
 	return func(config Config, namespace string) Cache {
+		var readThrough *readThroughReader
+		if opts.readThroughClient != nil {
+			readThrough = &readThroughReader{
+				reader:    opts.readThroughClient,
+				namespace: namespace,
+				label:     config.LabelSelector,
+				field:     config.FieldSelector,
+			}
+		}
 		return &informerCache{
 			scheme: opts.Scheme,
 			Informers: internal.NewInformers(restConfig, &internal.InformersOpts{
@@ -383,8 +541,16 @@ func newCache(restConfig *rest.Config, opts Options) newCacheFunc {
 				WatchErrorHandler:     opts.DefaultWatchErrorHandler,
 				UnsafeDisableDeepCopy: ptr.Deref(config.UnsafeDisableDeepCopy, false),
 				NewInformer:           opts.newInformer,
+				ExternalInformers:     opts.externalInformers,
+				ListWatches:           opts.listWatches,
+				UseWatchList:          opts.UseWatchList,
+				WatchErrorPolicy:      watchErrorPolicy(opts),
+				IdleTTL:               opts.IdleInformerTTL,
+				TrackObjects:          opts.TrackInformerObjects,
+				OnIdleEviction:        recordInformerEviction,
 			}),
 			readerFailOnMissingInformer: opts.ReaderFailOnMissingInformer,
+			readThrough:                 readThrough,
 		}
 	}
 }
@@ -426,7 +592,77 @@ func defaultOpts(config *rest.Config, opts Options) (Options, error) {
 		opts.DefaultNamespaces[namespace] = cfg
 	}
 
+	if opts.ReaderReadThroughOnMissingInformer {
+		if opts.ReaderFailOnMissingInformer {
+			return opts, fmt.Errorf("ReaderFailOnMissingInformer and ReaderReadThroughOnMissingInformer are mutually exclusive")
+		}
+		var err error
+		opts.readThroughClient, err = client.New(config, client.Options{
+			HTTPClient: opts.HTTPClient,
+			Scheme:     opts.Scheme,
+			Mapper:     opts.Mapper,
+		})
+		if err != nil {
+			return opts, fmt.Errorf("could not create client for reading through the cache: %w", err)
+		}
+	}
+
+	if opts.IdleInformerTTL < 0 {
+		return opts, fmt.Errorf("IdleInformerTTL must not be negative")
+	}
+
 	for obj, byObject := range opts.ByObject {
+		if byObject.WatchErrorHandler != nil {
+			if byObject.Informer != nil {
+				return opts, fmt.Errorf("type %T has an external ByObject.Informer, which must not be combined with WatchErrorHandler", obj)
+			}
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+			if err != nil {
+				return opts, fmt.Errorf("failed to get GVK for type %T: %w", obj, err)
+			}
+			if opts.watchErrorHandlers == nil {
+				opts.watchErrorHandlers = map[schema.GroupVersionKind]WatchErrorHandler{}
+			}
+			opts.watchErrorHandlers[gvk] = byObject.WatchErrorHandler
+		}
+
+		if byObject.ListWatch != nil || byObject.PollInterval != 0 {
+			if byObject.Informer != nil {
+				return opts, fmt.Errorf("type %T has an external ByObject.Informer, which must not be combined with ListWatch or PollInterval", obj)
+			}
+			if byObject.PollInterval < 0 {
+				return opts, fmt.Errorf("type %T has a negative ByObject.PollInterval", obj)
+			}
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+			if err != nil {
+				return opts, fmt.Errorf("failed to get GVK for type %T: %w", obj, err)
+			}
+			if opts.listWatches == nil {
+				opts.listWatches = map[schema.GroupVersionKind]internal.ListWatchFunc{}
+			}
+			opts.listWatches[gvk] = internal.ListWatchFunc(listWatchFor(byObject))
+		}
+
+		if byObject.Informer != nil {
+			if byObject.Namespaces != nil || byObject.Label != nil || byObject.Field != nil || byObject.Transform != nil {
+				return opts, fmt.Errorf("type %T has an external ByObject.Informer, which must not be combined with Namespaces, Label, Field or Transform", obj)
+			}
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+			if err != nil {
+				return opts, fmt.Errorf("failed to get GVK for type %T: %w", obj, err)
+			}
+			if opts.externalInformers == nil {
+				opts.externalInformers = map[schema.GroupVersionKind]toolscache.SharedIndexInformer{}
+			}
+			opts.externalInformers[gvk] = byObject.Informer
+
+			if byObject.UnsafeDisableDeepCopy == nil {
+				byObject.UnsafeDisableDeepCopy = opts.DefaultUnsafeDisableDeepCopy
+			}
+			opts.ByObject[obj] = byObject
+			continue
+		}
+
 		isNamespaced, err := apiutil.IsObjectNamespaced(obj, opts.Scheme, opts.Mapper)
 		if err != nil {
 			return opts, fmt.Errorf("failed to determine if %T is namespaced: %w", obj, err)
@@ -469,6 +705,14 @@ func defaultOpts(config *rest.Config, opts Options) (Options, error) {
 
 		if isNamespaced && byObject.Namespaces == nil {
 			byObject.Namespaces = opts.DefaultNamespaces
+			gvk, err := apiutil.GVKForObject(obj, opts.Scheme)
+			if err != nil {
+				return opts, fmt.Errorf("failed to get GVK for type %T: %w", obj, err)
+			}
+			if opts.byObjectWithDefaultNamespaces == nil {
+				opts.byObjectWithDefaultNamespaces = map[schema.GroupVersionKind]bool{}
+			}
+			opts.byObjectWithDefaultNamespaces[gvk] = true
 		}
 
 		opts.ByObject[obj] = byObject
@@ -481,6 +725,20 @@ func defaultOpts(config *rest.Config, opts Options) (Options, error) {
 	return opts, nil
 }
 
+// listWatchFor returns the ListWatchFunc for the ListWatch and PollInterval of byObject.
+func listWatchFor(byObject ByObject) ListWatchFunc {
+	if byObject.PollInterval == 0 {
+		return byObject.ListWatch
+	}
+	poll := PollingListWatch(byObject.PollInterval)
+	if byObject.ListWatch == nil {
+		return poll
+	}
+	return func(gvk schema.GroupVersionKind, lw toolscache.ListerWatcher) toolscache.ListerWatcher {
+		return poll(gvk, byObject.ListWatch(gvk, lw))
+	}
+}
+
 func defaultConfig(toDefault, defaultFrom Config) Config {
 	if toDefault.LabelSelector == nil {
 		toDefault.LabelSelector = defaultFrom.LabelSelector
@@ -235,6 +235,24 @@ func TestDefaultOpts(t *testing.T) {
 				return cmp.Diff(expected, o.DefaultNamespaces)
 			},
 		},
+		{
+			name: "ByObject.PollInterval polls the object",
+			in: Options{
+				ByObject: map[client.Object]ByObject{pod: {PollInterval: time.Minute}},
+			},
+
+			verification: func(o Options) string {
+				listWatch, ok := o.listWatches[corev1.SchemeGroupVersion.WithKind("Pod")]
+				if !ok {
+					return "expected a ListWatch for pods"
+				}
+				polling, ok := listWatch(corev1.SchemeGroupVersion.WithKind("Pod"), &cache.ListWatch{}).(*pollingListWatch)
+				if !ok {
+					return "expected a polling ListWatch for pods"
+				}
+				return cmp.Diff(time.Minute, polling.interval)
+			},
+		},
 	}
 
 	for _, tc := range testCases {
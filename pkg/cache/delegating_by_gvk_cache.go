@@ -18,6 +18,8 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -110,11 +112,36 @@ func (dbt *delegatingByGVKCache) WaitForCacheSync(ctx context.Context) bool {
 }
 
 func (dbt *delegatingByGVKCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	return dbt.IndexFieldWithOptions(ctx, obj, field, extractValue)
+}
+
+// IndexFieldWithOptions implements client.FieldIndexerWithOptions.
+func (dbt *delegatingByGVKCache) IndexFieldWithOptions(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc, opts ...client.IndexOption) error {
 	cache, err := dbt.cacheForObject(obj)
 	if err != nil {
 		return err
 	}
-	return cache.IndexField(ctx, obj, field, extractValue)
+	return client.IndexFieldWithOptions(ctx, cache, obj, field, extractValue, opts...)
+}
+
+// setNamespaces implements SetNamespaces for the default cache and the caches of the
+// kinds whose namespaces default to its namespaces.
+func (dbt *delegatingByGVKCache) setNamespaces(ctx context.Context, namespaces []string) error {
+	defaultCache, ok := dbt.defaultCache.(*multiNamespaceCache)
+	if !ok {
+		return errors.New("the namespaces of a cache watching all namespaces can't be changed")
+	}
+	if err := defaultCache.setNamespaces(ctx, namespaces); err != nil {
+		return err
+	}
+	for gvk, cache := range dbt.caches {
+		if cache, ok := cache.(*multiNamespaceCache); ok && cache.addedNamespaceConfig != nil {
+			if err := cache.setNamespaces(ctx, namespaces); err != nil {
+				return fmt.Errorf("failed to set the namespaces of the cache of %s: %w", gvk, err)
+			}
+		}
+	}
+	return nil
 }
 
 func (dbt *delegatingByGVKCache) cacheForObject(o runtime.Object) (Cache, error) {
@@ -0,0 +1,334 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// EvictionPolicy decides which objects an EvictingReader keeps.
+type EvictionPolicy struct {
+	// MaxBytes is the memory budget of the reader. It is approximated by the size of
+	// the JSON serialization of the kept objects. Once the budget is exceeded, the
+	// least recently read objects are evicted.
+	MaxBytes int64
+
+	// MaxAge evicts objects that were not read within MaxAge. Defaults to no
+	// age-based eviction.
+	MaxAge time.Duration
+}
+
+// EvictingReader is an experimental client.Reader for controllers that touch a
+// small, hot subset of a huge collection, for which an informer holding the whole
+// collection in memory is too expensive.
+//
+// Instead of an informer, it keeps only the recently read objects under the memory
+// budget of its EvictionPolicy, and re-fetches objects through the live reader on a
+// miss. An EvictingReader is meant to be used by a single controller, whose
+// reconciler reads the objects through it. To not serve stale objects, kept objects
+// are dropped once they change, which is detected by registering the
+// InvalidationHandler with an informer of the same kind, usually a metadata-only one
+// that also triggers the controller.
+//
+// List calls are always served by the live reader.
+type EvictingReader struct {
+	live   client.Reader
+	scheme *runtime.Scheme
+	policy EvictionPolicy
+
+	mu sync.Mutex
+	// lru holds the kept objects, the most recently read one first.
+	lru     *list.List
+	entries map[evictionKey]*list.Element
+	size    int64
+	// reads are the reads through the live reader in flight, so that invalidations
+	// that arrive before the result is kept are not lost.
+	reads map[evictionKey]*pendingRead
+}
+
+// pendingRead tracks the invalidations of an object that is being read through the
+// live reader.
+type pendingRead struct {
+	// readers is the number of reads of the object in flight.
+	readers int
+	// generation is incremented on every invalidation of the object.
+	generation uint64
+}
+
+var _ client.Reader = &EvictingReader{}
+
+// evictionKey identifies an object independently of its version, so that events
+// of metadata-only informers invalidate the typed objects.
+type evictionKey struct {
+	gk        schema.GroupKind
+	namespace string
+	name      string
+}
+
+type evictionEntry struct {
+	key      evictionKey
+	gvk      schema.GroupVersionKind
+	obj      client.Object
+	size     int64
+	lastRead time.Time
+}
+
+// NewEvictingReader returns an EvictingReader that re-fetches objects from live.
+func NewEvictingReader(live client.Reader, scheme *runtime.Scheme, policy EvictionPolicy) (*EvictingReader, error) {
+	if live == nil {
+		return nil, errors.New("must specify a live reader")
+	}
+	if scheme == nil {
+		return nil, errors.New("must specify a scheme")
+	}
+	if policy.MaxBytes <= 0 {
+		return nil, errors.New("must specify a positive memory budget")
+	}
+	return &EvictingReader{
+		live:    live,
+		scheme:  scheme,
+		policy:  policy,
+		lru:     list.New(),
+		entries: make(map[evictionKey]*list.Element),
+		reads:   make(map[evictionKey]*pendingRead),
+	}, nil
+}
+
+// Get implements client.Reader. Objects that are not kept are read through the
+// live reader and kept afterwards.
+func (r *EvictingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return err
+	}
+	k := evictionKey{gk: gvk.GroupKind(), namespace: key.Namespace, name: key.Name}
+
+	if r.get(k, gvk, obj) {
+		return nil
+	}
+
+	generation := r.startRead(k)
+	defer r.finishRead(k)
+	if err := r.live.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	return r.keep(k, gvk, obj, generation)
+}
+
+// List implements client.Reader by listing through the live reader.
+func (r *EvictingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return r.live.List(ctx, list, opts...)
+}
+
+// Len returns the number of kept objects.
+func (r *EvictingReader) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lru.Len()
+}
+
+// Size returns the approximated size of the kept objects in bytes.
+func (r *EvictingReader) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// Invalidate drops the given object, so that it is re-fetched on the next read.
+func (r *EvictingReader) Invalidate(obj client.Object) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidate(evictionKey{gk: gvk.GroupKind(), namespace: obj.GetNamespace(), name: obj.GetName()}, "")
+}
+
+// InvalidationHandler returns an event handler that drops the objects that changed.
+// It is meant to be registered with an informer, e.g. a metadata-only one:
+//
+//	informer, err := mgr.GetCache().GetInformer(ctx, &metav1.PartialObjectMetadata{TypeMeta: ...})
+//	...
+//	_, err = informer.AddEventHandler(reader.InvalidationHandler())
+func (r *EvictingReader) InvalidationHandler() toolscache.ResourceEventHandler {
+	// Deleted objects are always dropped, changed ones only if their version differs.
+	invalidate := func(obj interface{}, deleted bool) {
+		if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		o, ok := obj.(client.Object)
+		if !ok {
+			return
+		}
+		gvk, err := apiutil.GVKForObject(o, r.scheme)
+		if err != nil {
+			return
+		}
+		unlessResourceVersion := o.GetResourceVersion()
+		if deleted {
+			unlessResourceVersion = ""
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.invalidate(evictionKey{gk: gvk.GroupKind(), namespace: o.GetNamespace(), name: o.GetName()}, unlessResourceVersion)
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { invalidate(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { invalidate(obj, false) },
+		DeleteFunc: func(obj interface{}) { invalidate(obj, true) },
+	}
+}
+
+// get copies the kept object into obj and reports whether there was one.
+func (r *EvictingReader) get(k evictionKey, gvk schema.GroupVersionKind, obj client.Object) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[k]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*evictionEntry)
+	now := time.Now()
+	if entry.gvk != gvk || r.expired(entry, now) {
+		r.remove(k, "")
+		return false
+	}
+
+	objVal := reflect.ValueOf(entry.obj.DeepCopyObject())
+	outVal := reflect.ValueOf(obj)
+	if !objVal.Type().AssignableTo(outVal.Type()) {
+		r.remove(k, "")
+		return false
+	}
+	reflect.Indirect(outVal).Set(reflect.Indirect(objVal))
+
+	entry.lastRead = now
+	r.lru.MoveToFront(elem)
+	return true
+}
+
+// startRead records a read of the object with the given key through the live reader
+// and returns the generation of the object.
+func (r *EvictingReader) startRead(k evictionKey) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	read, ok := r.reads[k]
+	if !ok {
+		read = &pendingRead{}
+		r.reads[k] = read
+	}
+	read.readers++
+	return read.generation
+}
+
+// finishRead records the end of a read started with startRead.
+func (r *EvictingReader) finishRead(k evictionKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	read := r.reads[k]
+	read.readers--
+	if read.readers == 0 {
+		delete(r.reads, k)
+	}
+}
+
+// invalidate drops the object with the given key like remove and makes reads in
+// flight not keep their result, as it might predate the invalidation. It must be
+// called with r.mu held.
+func (r *EvictingReader) invalidate(k evictionKey, unlessResourceVersion string) {
+	if read, ok := r.reads[k]; ok {
+		read.generation++
+	}
+	r.remove(k, unlessResourceVersion)
+}
+
+// keep stores a copy of obj and evicts objects until the memory budget is met. obj
+// is not kept if it was invalidated since the read of the given generation started.
+func (r *EvictingReader) keep(k evictionKey, gvk schema.GroupVersionKind, obj client.Object, generation uint64) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to determine the size of %s %s: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+	}
+	entry := &evictionEntry{
+		key:      k,
+		gvk:      gvk,
+		obj:      obj.DeepCopyObject().(client.Object),
+		size:     int64(len(data)),
+		lastRead: time.Now(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reads[k].generation != generation {
+		// The object changed while it was read, the next read fetches it again.
+		return nil
+	}
+	r.remove(k, "")
+	if entry.size > r.policy.MaxBytes {
+		// The object alone exceeds the budget, it is never kept.
+		return nil
+	}
+	r.entries[k] = r.lru.PushFront(entry)
+	r.size += entry.size
+
+	for r.size > r.policy.MaxBytes {
+		r.remove(r.lru.Back().Value.(*evictionEntry).key, "")
+	}
+	for elem := r.lru.Back(); elem != nil && r.expired(elem.Value.(*evictionEntry), entry.lastRead); elem = r.lru.Back() {
+		r.remove(elem.Value.(*evictionEntry).key, "")
+	}
+	return nil
+}
+
+// remove drops the object with the given key, unless it has the given resource
+// version. It must be called with r.mu held.
+func (r *EvictingReader) remove(k evictionKey, unlessResourceVersion string) {
+	elem, ok := r.entries[k]
+	if !ok {
+		return
+	}
+	entry := elem.Value.(*evictionEntry)
+	if unlessResourceVersion != "" && entry.obj.GetResourceVersion() == unlessResourceVersion {
+		return
+	}
+	r.lru.Remove(elem)
+	delete(r.entries, k)
+	r.size -= entry.size
+}
+
+func (r *EvictingReader) expired(entry *evictionEntry, now time.Time) bool {
+	return r.policy.MaxAge > 0 && now.Sub(entry.lastRead) > r.policy.MaxAge
+}
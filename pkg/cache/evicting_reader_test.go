@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("EvictingReader", func() {
+	var (
+		ctx      = context.Background()
+		live     client.Client
+		liveGets atomic.Int32
+	)
+
+	newConfigMap := func(name string, size int) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"data": strings.Repeat("x", size)},
+		}
+	}
+
+	BeforeEach(func() {
+		liveGets.Store(0)
+		live = fake.NewClientBuilder().
+			WithObjects(newConfigMap("a", 1000), newConfigMap("b", 1000), newConfigMap("c", 1000)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					liveGets.Add(1)
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).
+			Build()
+	})
+
+	It("should require a memory budget", func() {
+		_, err := cache.NewEvictingReader(live, scheme.Scheme, cache.EvictionPolicy{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should serve recently read objects without reading them again", func() {
+		reader, err := cache.NewEvictingReader(live, scheme.Scheme, cache.EvictionPolicy{MaxBytes: 1 << 20})
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := &corev1.ConfigMap{}
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, cm)).To(Succeed())
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, cm)).To(Succeed())
+		Expect(cm.Data["data"]).To(HaveLen(1000))
+		Expect(liveGets.Load()).To(BeEquivalentTo(1))
+
+		By("handing out copies of the kept objects")
+		cm.Data["data"] = "mutated"
+		other := &corev1.ConfigMap{}
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, other)).To(Succeed())
+		Expect(other.Data["data"]).To(HaveLen(1000))
+	})
+
+	It("should evict the least recently read objects once the budget is exceeded", func() {
+		reader, err := cache.NewEvictingReader(live, scheme.Scheme, cache.EvictionPolicy{MaxBytes: 2500})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"a", "b", "a", "c"} {
+			Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, &corev1.ConfigMap{})).To(Succeed())
+		}
+		Expect(reader.Len()).To(Equal(2))
+		Expect(reader.Size()).To(BeNumerically("<=", 2500))
+		Expect(liveGets.Load()).To(BeEquivalentTo(3))
+
+		By("re-fetching the evicted object")
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(liveGets.Load()).To(BeEquivalentTo(3))
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "b"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(liveGets.Load()).To(BeEquivalentTo(4))
+	})
+
+	It("should drop objects that changed", func() {
+		reader, err := cache.NewEvictingReader(live, scheme.Scheme, cache.EvictionPolicy{MaxBytes: 1 << 20})
+		Expect(err).NotTo(HaveOccurred())
+		handler := reader.InvalidationHandler()
+
+		cm := &corev1.ConfigMap{}
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, cm)).To(Succeed())
+
+		By("ignoring events of the kept version")
+		meta := &metav1.PartialObjectMetadata{ObjectMeta: *cm.ObjectMeta.DeepCopy()}
+		meta.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+		handler.OnUpdate(meta, meta)
+		Expect(reader.Len()).To(Equal(1))
+
+		By("dropping the object once a new version is observed")
+		cm.Data["data"] = "changed"
+		Expect(live.Update(ctx, cm)).To(Succeed())
+		updated := meta.DeepCopy()
+		updated.ResourceVersion = cm.ResourceVersion
+		handler.OnUpdate(meta, updated)
+		Expect(reader.Len()).To(Equal(0))
+
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, cm)).To(Succeed())
+		Expect(cm.Data["data"]).To(Equal("changed"))
+
+		By("dropping the object once it is deleted")
+		Expect(reader.Len()).To(Equal(1))
+		handler.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "default/a", Obj: updated})
+		Expect(reader.Len()).To(Equal(0))
+	})
+
+	It("should not keep objects that changed while they were read", func() {
+		var reader *cache.EvictingReader
+		invalidating := fake.NewClientBuilder().
+			WithObjects(newConfigMap("a", 10)).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if err := c.Get(ctx, key, obj, opts...); err != nil {
+						return err
+					}
+					// The object changes after it was read, but before it is kept.
+					reader.Invalidate(obj)
+					return nil
+				},
+			}).
+			Build()
+		var err error
+		reader, err = cache.NewEvictingReader(invalidating, scheme.Scheme, cache.EvictionPolicy{MaxBytes: 1 << 20})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reader.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(reader.Len()).To(Equal(0))
+	})
+})
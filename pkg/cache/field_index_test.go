@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("informerCache field indexes", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		c      *informerCache
+	)
+
+	pod := func(namespace, name, node string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	byNodeAndPhase := func(obj client.Object) []string {
+		pod := obj.(*corev1.Pod)
+		return []string{client.CompositeIndexKey(pod.Spec.NodeName, string(pod.Status.Phase))}
+	}
+	byNode := func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	}
+	// Indexes must be added before the informers are started.
+	start := func() {
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(ctx)).To(BeTrue())
+	}
+
+	BeforeEach(func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		c = &informerCache{
+			scheme: scheme.Scheme,
+			Informers: internal.NewInformers(&rest.Config{}, &internal.InformersOpts{
+				HTTPClient: http.DefaultClient,
+				Scheme:     scheme.Scheme,
+				Mapper:     mapper,
+				ListWatches: map[schema.GroupVersionKind]internal.ListWatchFunc{
+					podGVK: func(schema.GroupVersionKind, toolscache.ListerWatcher) toolscache.ListerWatcher {
+						return &toolscache.ListWatch{
+							ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+								return &corev1.PodList{Items: []corev1.Pod{
+									pod("default", "a", "node-1", corev1.PodRunning),
+									pod("default", "b", "node-1", corev1.PodPending),
+									pod("default", "c", "node-2", corev1.PodRunning),
+									pod("other", "d", "node-1", corev1.PodRunning),
+								}}, nil
+							},
+							WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+								return watch.NewFake(), nil
+							},
+						}
+					},
+				},
+			}),
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should list the objects matching all fields of a composite index", func() {
+		Expect(c.IndexField(ctx, &corev1.Pod{}, client.CompositeIndexField("spec.nodeName", "status.phase"), byNodeAndPhase)).To(Succeed())
+		start()
+
+		pods := &corev1.PodList{}
+		Expect(c.List(ctx, pods, client.MatchingFields{"spec.nodeName": "node-1", "status.phase": "Running"})).To(Succeed())
+		Expect(pods.Items).To(ConsistOf(
+			HaveField("Name", "a"),
+			HaveField("Name", "d"),
+		))
+
+		Expect(c.List(ctx, pods, client.InNamespace("default"), client.MatchingFields{"status.phase": "Running", "spec.nodeName": "node-1"})).To(Succeed())
+		Expect(pods.Items).To(ConsistOf(HaveField("Name", "a")))
+
+		Expect(c.List(ctx, pods, client.MatchingFields{
+			client.CompositeIndexField("spec.nodeName", "status.phase"): client.CompositeIndexKey("node-1", "Pending"),
+		})).To(Succeed())
+		Expect(pods.Items).To(ConsistOf(HaveField("Name", "b")))
+	})
+
+	It("should fail to list objects with duplicate keys of unique indexes", func() {
+		Expect(client.IndexFieldWithOptions(ctx, c, &corev1.Pod{}, "spec.nodeName", byNode, client.UniqueIndex)).To(Succeed())
+		start()
+
+		err := c.List(ctx, &corev1.PodList{}, client.InNamespace("default"), client.MatchingFields{"spec.nodeName": "node-1"})
+		Expect(err).To(MatchError(ContainSubstring("unique index spec.nodeName")))
+		_, err = client.GetByIndex[*corev1.Pod](ctx, c, "spec.nodeName", "node-1")
+		Expect(err).To(HaveOccurred())
+
+		p, err := client.GetByIndex[*corev1.Pod](ctx, c, "spec.nodeName", "node-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Name).To(Equal("c"))
+	})
+
+	It("should fail to add indexes with options to field indexers that don't support them", func() {
+		err := client.IndexFieldWithOptions(ctx, struct{ client.FieldIndexer }{c}, &corev1.Pod{}, "spec.nodeName", byNode, client.UniqueIndex)
+		Expect(err).To(MatchError(ContainSubstring("doesn't support index options")))
+		Expect(client.IndexFieldWithOptions(ctx, struct{ client.FieldIndexer }{c}, &corev1.Pod{}, "spec.nodeName", byNode)).To(Succeed())
+	})
+
+	It("should get objects by the keys of unique indexes", func() {
+		Expect(client.IndexFieldWithOptions(ctx, c, &corev1.Pod{}, client.CompositeIndexField("spec.nodeName", "status.phase"), byNodeAndPhase, client.UniqueIndex)).To(Succeed())
+		start()
+
+		field := client.CompositeIndexField("spec.nodeName", "status.phase")
+		p, err := client.GetByIndex[*corev1.Pod](ctx, c, field, client.CompositeIndexKey("node-2", "Running"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Name).To(Equal("c"))
+
+		p, err = client.GetByIndex[*corev1.Pod](ctx, c, field, client.CompositeIndexKey("node-1", "Running"), client.InNamespace("other"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Name).To(Equal("d"))
+
+		_, err = client.GetByIndex[*corev1.Pod](ctx, c, field, client.CompositeIndexKey("node-3", "Running"))
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// The options of the caller are left untouched.
+		opts := make([]client.ListOption, 1, 2)
+		opts[0] = client.InNamespace("other")
+		_, err = client.GetByIndex[*corev1.Pod](ctx, c, field, client.CompositeIndexKey("node-1", "Running"), opts...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts[:2][1]).To(BeNil())
+
+		// Objects of different namespaces may have the same key.
+		pods := &corev1.PodList{}
+		Expect(c.List(ctx, pods, client.MatchingFields{field: client.CompositeIndexKey("node-1", "Running")})).To(Succeed())
+		Expect(pods.Items).To(HaveLen(2))
+	})
+})
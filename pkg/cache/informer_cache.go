@@ -34,9 +34,10 @@ import (
 )
 
 var (
-	_ Informers     = &informerCache{}
-	_ client.Reader = &informerCache{}
-	_ Cache         = &informerCache{}
+	_ Informers                      = &informerCache{}
+	_ client.Reader                  = &informerCache{}
+	_ Cache                          = &informerCache{}
+	_ client.FieldIndexerWithOptions = &informerCache{}
 )
 
 // ErrCacheNotStarted is returned when trying to read from the cache that wasn't started.
@@ -68,6 +69,8 @@ type informerCache struct {
 	scheme *runtime.Scheme
 	*internal.Informers
 	readerFailOnMissingInformer bool
+	// readThrough reads the objects of kinds without a started informer if set.
+	readThrough *readThroughReader
 }
 
 // Get implements Reader.
@@ -77,6 +80,10 @@ func (ic *informerCache) Get(ctx context.Context, key client.ObjectKey, out clie
 		return err
 	}
 
+	if ic.readThrough != nil && !ic.hasStartedInformer(gvk, out) {
+		return ic.readThrough.Get(ctx, gvk, key, out, opts...)
+	}
+
 	started, cache, err := ic.getInformerForKind(ctx, gvk, out)
 	if err != nil {
 		return err
@@ -95,6 +102,10 @@ func (ic *informerCache) List(ctx context.Context, out client.ObjectList, opts .
 		return err
 	}
 
+	if ic.readThrough != nil && !ic.hasStartedInformer(*gvk, cacheTypeObj) {
+		return ic.readThrough.List(ctx, *gvk, out, opts...)
+	}
+
 	started, cache, err := ic.getInformerForKind(ctx, *gvk, cacheTypeObj)
 	if err != nil {
 		return err
@@ -190,6 +201,13 @@ func (ic *informerCache) getInformerForKind(ctx context.Context, gvk schema.Grou
 	return ic.Informers.Get(ctx, gvk, obj, &internal.GetOptions{})
 }
 
+// hasStartedInformer returns whether the cache is started and has an informer for the
+// objects of the given kind.
+func (ic *informerCache) hasStartedInformer(gvk schema.GroupVersionKind, obj runtime.Object) bool {
+	_, started, ok := ic.Informers.Peek(gvk, obj)
+	return started && ok
+}
+
 // RemoveInformer deactivates and removes the informer from the cache.
 func (ic *informerCache) RemoveInformer(_ context.Context, obj client.Object) error {
 	gvk, err := apiutil.GVKForObject(obj, ic.scheme)
@@ -213,14 +231,20 @@ func (ic *informerCache) NeedLeaderElection() bool {
 // The values may be anything. They will automatically be prefixed with the namespace of the
 // given object, if present. The objects passed are guaranteed to be objects of the correct type.
 func (ic *informerCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	return ic.IndexFieldWithOptions(ctx, obj, field, extractValue)
+}
+
+// IndexFieldWithOptions implements client.FieldIndexerWithOptions.
+func (ic *informerCache) IndexFieldWithOptions(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc, opts ...client.IndexOption) error {
 	informer, err := ic.GetInformer(ctx, obj)
 	if err != nil {
 		return err
 	}
-	return indexByField(informer, field, extractValue)
+	return indexByField(informer, field, extractValue, opts...)
 }
 
-func indexByField(informer Informer, field string, extractValue client.IndexerFunc) error {
+func indexByField(informer Informer, field string, extractValue client.IndexerFunc, opts ...client.IndexOption) error {
+	indexOpts := (&client.IndexOptions{}).ApplyOptions(opts)
 	indexFunc := func(objRaw interface{}) ([]string, error) {
 		// TODO(directxman12): check if this is the correct type?
 		obj, isObj := objRaw.(client.Object)
@@ -256,5 +280,13 @@ func indexByField(informer Informer, field string, extractValue client.IndexerFu
 		return vals, nil
 	}
 
-	return informer.AddIndexers(cache.Indexers{internal.FieldIndexName(field): indexFunc})
+	if !indexOpts.Unique {
+		return informer.AddIndexers(cache.Indexers{internal.FieldIndexName(field): indexFunc})
+	}
+	// The objects are only indexed by the index over the field, the other index marks
+	// it as unique for the cache reader.
+	return informer.AddIndexers(cache.Indexers{
+		internal.FieldIndexName(field):       indexFunc,
+		internal.UniqueFieldIndexName(field): func(interface{}) ([]string, error) { return nil, nil },
+	})
 }
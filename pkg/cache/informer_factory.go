@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/informers/admissionregistration"
+	"k8s.io/client-go/informers/apiserverinternal"
+	"k8s.io/client-go/informers/apps"
+	"k8s.io/client-go/informers/autoscaling"
+	"k8s.io/client-go/informers/batch"
+	"k8s.io/client-go/informers/certificates"
+	"k8s.io/client-go/informers/coordination"
+	"k8s.io/client-go/informers/core"
+	"k8s.io/client-go/informers/discovery"
+	"k8s.io/client-go/informers/events"
+	"k8s.io/client-go/informers/extensions"
+	"k8s.io/client-go/informers/flowcontrol"
+	"k8s.io/client-go/informers/internalinterfaces"
+	"k8s.io/client-go/informers/networking"
+	"k8s.io/client-go/informers/node"
+	"k8s.io/client-go/informers/policy"
+	"k8s.io/client-go/informers/rbac"
+	"k8s.io/client-go/informers/resource"
+	"k8s.io/client-go/informers/scheduling"
+	"k8s.io/client-go/informers/storage"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewSharedInformerFactory returns a client-go SharedInformerFactory backed by the informers
+// of the given cache, so that client-go based controllers and listers share a single watch
+// per GroupVersionKind with controller-runtime. The scheme and mapper are used to map
+// resources to objects in ForResource, resources whose kind is not registered in the scheme
+// are served as unstructured objects.
+//
+// The informers are configured and started by the cache: Start and Shutdown of the factory
+// are no-ops, and the namespace and list options of the typed informers are ignored. The
+// cache must hand out client-go SharedIndexInformers, which is not the case for caches that
+// span multiple namespaces. InformerFor panics if no such informer can be obtained, use
+// ForResource to handle this as an error.
+func NewSharedInformerFactory(c Cache, scheme *runtime.Scheme, mapper meta.RESTMapper) informers.SharedInformerFactory {
+	return &sharedInformerFactory{
+		cache:  c,
+		scheme: scheme,
+		mapper: mapper,
+	}
+}
+
+var _ informers.SharedInformerFactory = &sharedInformerFactory{}
+
+type sharedInformerFactory struct {
+	cache  Cache
+	scheme *runtime.Scheme
+	mapper meta.RESTMapper
+
+	// mu guards requested.
+	mu sync.Mutex
+	// requested are the informers handed out by the factory, by the type of their objects.
+	requested map[reflect.Type][]toolscache.SharedIndexInformer
+}
+
+func (f *sharedInformerFactory) informerFor(obj runtime.Object) (toolscache.SharedIndexInformer, error) {
+	cObj, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", obj)
+	}
+
+	// Don't block, client-go code waits for informers to sync through WaitForCacheSync.
+	informer, err := f.cache.GetInformer(context.Background(), cObj, BlockUntilSynced(false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get informer for %T from the cache: %w", obj, err)
+	}
+	sharedIndexInformer, ok := informer.(toolscache.SharedIndexInformer)
+	if !ok {
+		return nil, fmt.Errorf("informer %T for %T is not a client-go SharedIndexInformer", informer, obj)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.requested == nil {
+		f.requested = map[reflect.Type][]toolscache.SharedIndexInformer{}
+	}
+	t := reflect.TypeOf(obj)
+	f.requested[t] = append(f.requested[t], sharedIndexInformer)
+	return sharedIndexInformer, nil
+}
+
+// InformerFor implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) InformerFor(obj runtime.Object, _ internalinterfaces.NewInformerFunc) toolscache.SharedIndexInformer {
+	informer, err := f.informerFor(obj)
+	if err != nil {
+		panic(err)
+	}
+	return informer
+}
+
+// ForResource implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (informers.GenericInformer, error) {
+	gvk, err := f.mapper.KindFor(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := f.scheme.New(gvk)
+	if err != nil {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		obj = u
+	}
+
+	informer, err := f.informerFor(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &genericInformer{informer: informer, resource: resource.GroupResource()}, nil
+}
+
+// Start implements informers.SharedInformerFactory. The informers are started by the cache.
+func (f *sharedInformerFactory) Start(<-chan struct{}) {}
+
+// Shutdown implements informers.SharedInformerFactory. The informers are stopped by the cache.
+func (f *sharedInformerFactory) Shutdown() {}
+
+// WaitForCacheSync implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool {
+	f.mu.Lock()
+	requested := make(map[reflect.Type][]toolscache.SharedIndexInformer, len(f.requested))
+	for t, informers := range f.requested {
+		requested[t] = append([]toolscache.SharedIndexInformer(nil), informers...)
+	}
+	f.mu.Unlock()
+
+	res := make(map[reflect.Type]bool, len(requested))
+	for t, informers := range requested {
+		synced := make([]toolscache.InformerSynced, 0, len(informers))
+		for _, informer := range informers {
+			synced = append(synced, informer.HasSynced)
+		}
+		res[t] = toolscache.WaitForCacheSync(stopCh, synced...)
+	}
+	return res
+}
+
+// Admissionregistration implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Admissionregistration() admissionregistration.Interface {
+	return admissionregistration.New(f, metav1.NamespaceAll, nil)
+}
+
+// Internal implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Internal() apiserverinternal.Interface {
+	return apiserverinternal.New(f, metav1.NamespaceAll, nil)
+}
+
+// Apps implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Apps() apps.Interface {
+	return apps.New(f, metav1.NamespaceAll, nil)
+}
+
+// Autoscaling implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Autoscaling() autoscaling.Interface {
+	return autoscaling.New(f, metav1.NamespaceAll, nil)
+}
+
+// Batch implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Batch() batch.Interface {
+	return batch.New(f, metav1.NamespaceAll, nil)
+}
+
+// Certificates implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Certificates() certificates.Interface {
+	return certificates.New(f, metav1.NamespaceAll, nil)
+}
+
+// Coordination implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Coordination() coordination.Interface {
+	return coordination.New(f, metav1.NamespaceAll, nil)
+}
+
+// Core implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Core() core.Interface {
+	return core.New(f, metav1.NamespaceAll, nil)
+}
+
+// Discovery implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Discovery() discovery.Interface {
+	return discovery.New(f, metav1.NamespaceAll, nil)
+}
+
+// Events implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Events() events.Interface {
+	return events.New(f, metav1.NamespaceAll, nil)
+}
+
+// Extensions implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Extensions() extensions.Interface {
+	return extensions.New(f, metav1.NamespaceAll, nil)
+}
+
+// Flowcontrol implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Flowcontrol() flowcontrol.Interface {
+	return flowcontrol.New(f, metav1.NamespaceAll, nil)
+}
+
+// Networking implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Networking() networking.Interface {
+	return networking.New(f, metav1.NamespaceAll, nil)
+}
+
+// Node implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Node() node.Interface {
+	return node.New(f, metav1.NamespaceAll, nil)
+}
+
+// Policy implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Policy() policy.Interface {
+	return policy.New(f, metav1.NamespaceAll, nil)
+}
+
+// Rbac implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Rbac() rbac.Interface {
+	return rbac.New(f, metav1.NamespaceAll, nil)
+}
+
+// Resource implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Resource() resource.Interface {
+	return resource.New(f, metav1.NamespaceAll, nil)
+}
+
+// Scheduling implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Scheduling() scheduling.Interface {
+	return scheduling.New(f, metav1.NamespaceAll, nil)
+}
+
+// Storage implements informers.SharedInformerFactory.
+func (f *sharedInformerFactory) Storage() storage.Interface {
+	return storage.New(f, metav1.NamespaceAll, nil)
+}
+
+// genericInformer implements informers.GenericInformer for an informer of the cache.
+type genericInformer struct {
+	informer toolscache.SharedIndexInformer
+	resource schema.GroupResource
+}
+
+// Informer implements informers.GenericInformer.
+func (i *genericInformer) Informer() toolscache.SharedIndexInformer {
+	return i.informer
+}
+
+// Lister implements informers.GenericInformer.
+func (i *genericInformer) Lister() toolscache.GenericLister {
+	return toolscache.NewGenericLister(i.informer.GetIndexer(), i.resource)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+)
+
+var _ = Describe("NewSharedInformerFactory", func() {
+	var factoryCache *informertest.FakeInformers
+	var podInformer toolscache.SharedIndexInformer
+	var mapper *meta.DefaultRESTMapper
+
+	BeforeEach(func() {
+		podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+		podInformer = toolscache.NewSharedIndexInformer(&toolscache.ListWatch{}, &corev1.Pod{}, 0, toolscache.Indexers{
+			toolscache.NamespaceIndex: toolscache.MetaNamespaceIndexFunc,
+		})
+		Expect(podInformer.GetIndexer().Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}})).To(Succeed())
+
+		factoryCache = &informertest.FakeInformers{
+			InformersByGVK: map[schema.GroupVersionKind]toolscache.SharedIndexInformer{podGVK: podInformer},
+		}
+		mapper = meta.NewDefaultRESTMapper(nil)
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+	})
+
+	It("should serve typed informers and listers from the cache", func() {
+		factory := cache.NewSharedInformerFactory(factoryCache, scheme.Scheme, mapper)
+
+		Expect(factory.Core().V1().Pods().Informer()).To(BeIdenticalTo(podInformer))
+		pods, err := factory.Core().V1().Pods().Lister().Pods("default").List(labels.Everything())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods).To(HaveLen(1))
+		Expect(pods[0].Name).To(Equal("foo"))
+	})
+
+	It("should serve generic informers and listers from the cache", func() {
+		factory := cache.NewSharedInformerFactory(factoryCache, scheme.Scheme, mapper)
+
+		informer, err := factory.ForResource(corev1.SchemeGroupVersion.WithResource("pods"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(informer.Informer()).To(BeIdenticalTo(podInformer))
+		obj, err := informer.Lister().ByNamespace("default").Get("foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.(*corev1.Pod).Name).To(Equal("foo"))
+
+		_, err = factory.ForResource(corev1.SchemeGroupVersion.WithResource("unknowns"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should wait for the requested informers to sync", func() {
+		factory := cache.NewSharedInformerFactory(factoryCache, scheme.Scheme, mapper)
+		factory.Core().V1().Pods().Informer()
+
+		stop := make(chan struct{})
+		close(stop)
+		Expect(factory.WaitForCacheSync(stop)).To(Equal(map[reflect.Type]bool{
+			reflect.TypeOf(&corev1.Pod{}): false,
+		}))
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"golang.org/x/exp/maps"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+)
+
+// InformerKey identifies an informer of a cache by the kind and type of the objects it
+// stores and the namespace it is restricted to.
+type InformerKey = internal.InformerKey
+
+// InformerStats are the stats of an informer of a cache: the number and approximate
+// size of the objects it stores if Options.TrackInformerObjects is set, the time of its
+// last successful list and the number of errors that ended its watches.
+//
+// The same stats are exposed per group, version and kind in the
+// controller_runtime_cache_informer_* metrics.
+type InformerStats internal.InformerStats
+
+// informerStatsReporter is implemented by the caches reporting the stats of their
+// informers.
+type informerStatsReporter interface {
+	informerStats() []InformerStats
+}
+
+// GetInformerStats returns the stats of the informers of the cache c, e.g. to find the
+// kinds using most of the memory of a controller. Informers passed in ByObject are
+// omitted.
+func GetInformerStats(c Cache) ([]InformerStats, error) {
+	reporter, ok := c.(informerStatsReporter)
+	if !ok {
+		return nil, fmt.Errorf("cache %T doesn't report the stats of its informers", c)
+	}
+	stats := reporter.informerStats()
+	slices.SortFunc(stats, func(a, b InformerStats) int {
+		return a.InformerKey.Compare(b.InformerKey)
+	})
+	return stats, nil
+}
+
+// InformerStatsHandler returns a handler serving the stats of the informers of the cache
+// c as JSON, for debugging. It can be added to the metrics server of a manager:
+//
+//	metricsserver.Options{
+//		ExtraHandlers: map[string]http.Handler{
+//			"/debug/cache/informers": cache.InformerStatsHandler(mgr.GetCache()),
+//		},
+//	}
+func InformerStatsHandler(c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		stats, err := GetInformerStats(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		if stats == nil {
+			stats = []InformerStats{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(stats); err != nil {
+			log.Error(err, "failed to write informer stats")
+		}
+	})
+}
+
+func (ic *informerCache) informerStats() []InformerStats {
+	var stats []InformerStats
+	for _, s := range ic.Informers.Stats() {
+		stats = append(stats, InformerStats(s))
+	}
+	return stats
+}
+
+func (c *multiNamespaceCache) informerStats() []InformerStats {
+	return collect(c.caches(), informerStatsReporter.informerStats)
+}
+
+func (dbt *delegatingByGVKCache) informerStats() []InformerStats {
+	return collect(append(maps.Values(dbt.caches), dbt.defaultCache), informerStatsReporter.informerStats)
+}
+
+// collect concatenates the results of f for the caches implementing the interface T,
+// e.g. to aggregate the debugging information of the caches a cache delegates to.
+func collect[T any, R any](caches []Cache, f func(T) []R) []R {
+	var results []R
+	for _, cache := range caches {
+		if t, ok := cache.(T); ok {
+			results = append(results, f(t)...)
+		}
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+)
+
+var _ = Describe("InformerStatsHandler", func() {
+	It("should serve the stats of the informers of the cache", func() {
+		secretGVK := corev1.SchemeGroupVersion.WithKind("Secret")
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(secretGVK, meta.RESTScopeNamespace)
+		c := &informerCache{
+			scheme: scheme.Scheme,
+			Informers: internal.NewInformers(&rest.Config{}, &internal.InformersOpts{
+				HTTPClient:   http.DefaultClient,
+				Scheme:       scheme.Scheme,
+				Mapper:       mapper,
+				TrackObjects: true,
+				ListWatches: map[schema.GroupVersionKind]internal.ListWatchFunc{
+					secretGVK: func(schema.GroupVersionKind, toolscache.ListerWatcher) toolscache.ListerWatcher {
+						return &toolscache.ListWatch{
+							ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+								return &corev1.SecretList{Items: []corev1.Secret{
+									{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+								}}, nil
+							},
+							WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+								return watch.NewFake(), nil
+							},
+						}
+					},
+				},
+			}),
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		_, err := c.GetInformer(ctx, &corev1.Secret{})
+		Expect(err).NotTo(HaveOccurred())
+
+		// The event handlers are notified asynchronously.
+		var stats []InformerStats
+		Eventually(func(g Gomega) {
+			recorder := httptest.NewRecorder()
+			InformerStatsHandler(c).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/cache/informers", nil))
+			g.Expect(recorder.Code).To(Equal(http.StatusOK))
+			g.Expect(json.Unmarshal(recorder.Body.Bytes(), &stats)).To(Succeed())
+			g.Expect(stats).To(HaveLen(1))
+			g.Expect(stats[0].Objects).To(BeEquivalentTo(1))
+		}).Should(Succeed())
+		Expect(stats[0].Kind).To(Equal("Secret"))
+		Expect(stats[0].LastSyncTime).NotTo(BeNil())
+	})
+
+	It("should fail for caches not reporting the stats of their informers", func() {
+		recorder := httptest.NewRecorder()
+		InformerStatsHandler(&fakeNamespaceCache{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/cache/informers", nil))
+		Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+	})
+})
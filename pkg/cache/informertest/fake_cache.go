@@ -130,6 +130,11 @@ func (c *FakeInformers) IndexField(ctx context.Context, obj client.Object, field
 	return nil
 }
 
+// IndexFieldWithOptions implements client.FieldIndexerWithOptions.
+func (c *FakeInformers) IndexFieldWithOptions(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc, opts ...client.IndexOption) error {
+	return nil
+}
+
 // Get implements Cache.
 func (c *FakeInformers) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
 	return nil
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
@@ -126,6 +127,9 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 		// namespaced index key. Otherwise, ask for the non-namespaced variant by using the fake "all namespaces"
 		// namespace.
 		objs, err = byIndexes(c.indexer, listOpts.FieldSelector.Requirements(), listOpts.Namespace)
+		if err == nil {
+			err = checkUniqueIndexes(c.indexer, listOpts.FieldSelector.Requirements(), objs)
+		}
 	case listOpts.Namespace != "":
 		objs, err = c.indexer.ByIndex(cache.NamespaceIndex, listOpts.Namespace)
 	default:
@@ -184,6 +188,11 @@ func byIndexes(indexer cache.Indexer, requires fields.Requirements, namespace st
 		vals []string
 	)
 	indexers := indexer.GetIndexers()
+	if len(requires) > 1 {
+		if indexName, indexedValue, ok := compositeIndexFor(indexers, requires, namespace); ok {
+			return indexer.ByIndex(indexName, indexedValue)
+		}
+	}
 	for idx, req := range requires {
 		indexName := FieldIndexName(req.Field)
 		indexedValue := KeyToNamespacedKey(namespace, req.Value)
@@ -225,6 +234,63 @@ func byIndexes(indexer cache.Indexer, requires fields.Requirements, namespace st
 	return objs, nil
 }
 
+// compositeIndexFor returns the name of a composite index over exactly the fields of
+// requires, and the key of the objects matching requires in it.
+func compositeIndexFor(indexers cache.Indexers, requires fields.Requirements, namespace string) (string, string, bool) {
+	values := make(map[string]string, len(requires))
+	for _, req := range requires {
+		if value, ok := values[req.Field]; ok && value != req.Value {
+			return "", "", false
+		}
+		values[req.Field] = req.Value
+	}
+	for indexName := range indexers {
+		field, isField := strings.CutPrefix(indexName, FieldIndexName(""))
+		if !isField {
+			continue
+		}
+		indexFields := client.CompositeIndexFields(field)
+		if len(indexFields) != len(values) {
+			continue
+		}
+		keyValues := make([]string, 0, len(indexFields))
+		for _, indexField := range indexFields {
+			value, ok := values[indexField]
+			if !ok {
+				break
+			}
+			keyValues = append(keyValues, value)
+		}
+		if len(keyValues) == len(indexFields) {
+			return indexName, KeyToNamespacedKey(namespace, client.CompositeIndexKey(keyValues...)), true
+		}
+	}
+	return "", "", false
+}
+
+// checkUniqueIndexes returns an error if objs, the objects matching requires, contain
+// several objects of the same namespace with the key of a unique index.
+func checkUniqueIndexes(indexer cache.Indexer, requires fields.Requirements, objs []interface{}) error {
+	indexers := indexer.GetIndexers()
+	for _, req := range requires {
+		if _, unique := indexers[UniqueFieldIndexName(req.Field)]; !unique {
+			continue
+		}
+		namespaces := make(map[string]bool, len(objs))
+		for _, obj := range objs {
+			objMeta, err := apimeta.Accessor(obj)
+			if err != nil {
+				return err
+			}
+			if namespaces[objMeta.GetNamespace()] {
+				return fmt.Errorf("several objects have the key %q of the unique index %s", req.Value, req.Field)
+			}
+			namespaces[objMeta.GetNamespace()] = true
+		}
+	}
+	return nil
+}
+
 // objectKeyToStorageKey converts an object key to store key.
 // It's akin to MetaNamespaceKeyFunc. It's separate from
 // String to allow keeping the key format easily in sync with
@@ -242,6 +308,12 @@ func FieldIndexName(field string) string {
 	return "field:" + field
 }
 
+// UniqueFieldIndexName constructs the name of the index marking the index over the
+// given field as unique. It doesn't index any object.
+func UniqueFieldIndexName(field string) string {
+	return "unique-field:" + field
+}
+
 // allNamespacesNamespace is used as the "namespace" when we want to list across all namespaces.
 const allNamespacesNamespace = "__all_namespaces"
 
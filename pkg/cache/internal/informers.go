@@ -22,11 +22,13 @@ import (
 	"math/rand"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
@@ -51,8 +53,29 @@ type InformersOpts struct {
 	Transform             cache.TransformFunc
 	UnsafeDisableDeepCopy bool
 	WatchErrorHandler     cache.WatchErrorHandler
+	ExternalInformers     map[schema.GroupVersionKind]cache.SharedIndexInformer
+	ListWatches           map[schema.GroupVersionKind]ListWatchFunc
+	UseWatchList          bool
+
+	// WatchErrorPolicy, if set, is called instead of WatchErrorHandler with the errors
+	// ending the watches of informers, which are stopped if it returns true.
+	WatchErrorPolicy func(r *cache.Reflector, gvk schema.GroupVersionKind, err error) bool
+
+	// IdleTTL, if set, is the duration after which informers without event handlers
+	// nor indexers that weren't gotten are stopped and removed. OnIdleEviction is
+	// called with the GroupVersionKind of every removed informer.
+	IdleTTL        time.Duration
+	OnIdleEviction func(gvk schema.GroupVersionKind)
+
+	// TrackObjects makes the informers count their objects and approximate their size
+	// in their stats, with an event handler computing the size of every added, updated
+	// and deleted object.
+	TrackObjects bool
 }
 
+// ListWatchFunc customizes the ListerWatcher used by the informer of a GroupVersionKind.
+type ListWatchFunc func(gvk schema.GroupVersionKind, lw cache.ListerWatcher) cache.ListerWatcher
+
 // NewInformers creates a new InformersMap that can create informers under the hood.
 func NewInformers(config *rest.Config, options *InformersOpts) *Informers {
 	newInformer := cache.NewSharedIndexInformer
@@ -79,6 +102,13 @@ func NewInformers(config *rest.Config, options *InformersOpts) *Informers {
 		unsafeDisableDeepCopy: options.UnsafeDisableDeepCopy,
 		newInformer:           newInformer,
 		watchErrorHandler:     options.WatchErrorHandler,
+		watchErrorPolicy:      options.WatchErrorPolicy,
+		externalInformers:     options.ExternalInformers,
+		listWatches:           options.ListWatches,
+		useWatchList:          options.UseWatchList,
+		idleTTL:               options.IdleTTL,
+		trackObjects:          options.TrackObjects,
+		onIdleEviction:        options.OnIdleEviction,
 	}
 }
 
@@ -91,7 +121,92 @@ type Cache struct {
 	Reader CacheReader
 
 	// Stop can be used to stop this individual informer.
-	stop chan struct{}
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// idle tracks whether the informer is idle, it is nil for informers that are never
+	// evicted.
+	idle *idleTrackingInformer
+
+	// stats tracks the stats of the informer, it is nil for external informers.
+	stats *informerStats
+
+	// resyncable resyncs the informer on demand, it is nil for external informers.
+	resyncable *resyncableInformer
+
+	// external is true if the informer was created outside of the cache.
+	external bool
+
+	// mu guards stopped and removed, which decide when the objects of the informer are
+	// removed from its stats.
+	mu      sync.Mutex
+	stopped bool
+	removed bool
+}
+
+// idleTrackingInformer is a SharedIndexInformer that tracks whether it is idle, i.e. it
+// has no event handlers nor indexers and wasn't used since some time.
+type idleTrackingInformer struct {
+	cache.SharedIndexInformer
+
+	handlers atomic.Int64
+	// indexed is set once indexers were added, as removing the informer would drop them.
+	indexed atomic.Bool
+	// lastUsed is the time the informer was last gotten or had its last event handler
+	// removed, in Unix nanoseconds.
+	lastUsed atomic.Int64
+}
+
+func newIdleTrackingInformer(informer cache.SharedIndexInformer) *idleTrackingInformer {
+	i := &idleTrackingInformer{SharedIndexInformer: informer}
+	i.touch()
+	return i
+}
+
+// AddEventHandler implements cache.SharedIndexInformer.
+func (i *idleTrackingInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	registration, err := i.SharedIndexInformer.AddEventHandler(handler)
+	if err == nil {
+		i.handlers.Add(1)
+	}
+	return registration, err
+}
+
+// AddEventHandlerWithResyncPeriod implements cache.SharedIndexInformer.
+func (i *idleTrackingInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error) {
+	registration, err := i.SharedIndexInformer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	if err == nil {
+		i.handlers.Add(1)
+	}
+	return registration, err
+}
+
+// RemoveEventHandler implements cache.SharedIndexInformer.
+func (i *idleTrackingInformer) RemoveEventHandler(handle cache.ResourceEventHandlerRegistration) error {
+	if err := i.SharedIndexInformer.RemoveEventHandler(handle); err != nil {
+		return err
+	}
+	i.handlers.Add(-1)
+	i.touch()
+	return nil
+}
+
+// AddIndexers implements cache.SharedIndexInformer.
+func (i *idleTrackingInformer) AddIndexers(indexers cache.Indexers) error {
+	if err := i.SharedIndexInformer.AddIndexers(indexers); err != nil {
+		return err
+	}
+	i.indexed.Store(true)
+	return nil
+}
+
+func (i *idleTrackingInformer) touch() {
+	i.lastUsed.Store(time.Now().UnixNano())
+}
+
+// idleSince returns whether the informer has been idle since at least since.
+func (i *idleTrackingInformer) idleSince(since time.Time) bool {
+	return !i.indexed.Load() && i.handlers.Load() <= 0 && i.lastUsed.Load() < since.UnixNano()
 }
 
 // Start starts the informer managed by a MapEntry.
@@ -102,7 +217,65 @@ func (c *Cache) Start(stop <-chan struct{}) {
 	// Stop on either the whole map stopping or just this informer being removed.
 	internalStop, cancel := syncs.MergeChans(stop, c.stop)
 	defer cancel()
-	c.Informer.Run(internalStop)
+	if !c.external {
+		c.Informer.Run(internalStop)
+	} else {
+		// An informer started by its creator must not be run twice. Run returns right
+		// away if the creator started it concurrently, so wait for the stop either way.
+		if started, ok := c.Informer.(interface{ HasStarted() bool }); !ok || !started.HasStarted() {
+			c.Informer.Run(internalStop)
+		}
+		<-internalStop
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	// An informer stopped after a watch error keeps serving its objects until it is
+	// removed. The notifications its stats were still waiting for are dropped once it
+	// stopped, so they are counted again from its store.
+	if c.removed || c.stopError() == "" {
+		c.resetStats()
+	} else if c.stats != nil && c.stats.tracksObjects {
+		c.stats.recount(c.Informer.GetStore().List())
+	}
+}
+
+// stopInformer stops the informer, it can be called more than once.
+func (c *Cache) stopInformer() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// removeInformer stops the informer when it is removed from the cache.
+func (c *Cache) removeInformer() {
+	c.stopInformer()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removed = true
+	if c.stopped {
+		c.resetStats()
+	}
+}
+
+// resetStats removes the objects of the informer from its stats.
+func (c *Cache) resetStats() {
+	if c.stats != nil {
+		c.stats.reset()
+	}
+}
+
+// stopError returns the watch error the informer was stopped after, if any.
+func (c *Cache) stopError() string {
+	if c.stats == nil {
+		return ""
+	}
+	if stopError := c.stats.stopError.Load(); stopError != nil {
+		return *stopError
+	}
+	return ""
 }
 
 type tracker struct {
@@ -181,6 +354,28 @@ type Informers struct {
 	// watchErrorHandler to be set by overriding the options
 	// or to use the default watchErrorHandler
 	watchErrorHandler cache.WatchErrorHandler
+
+	// watchErrorPolicy decides whether informers are stopped after watch errors, see
+	// InformersOpts.WatchErrorPolicy.
+	watchErrorPolicy func(r *cache.Reflector, gvk schema.GroupVersionKind, err error) bool
+
+	// externalInformers are informers for structured objects created outside of the
+	// cache, which are used instead of creating new ones.
+	externalInformers map[schema.GroupVersionKind]cache.SharedIndexInformer
+
+	// listWatches customize the ListerWatcher of informers per GroupVersionKind.
+	listWatches map[schema.GroupVersionKind]ListWatchFunc
+
+	// useWatchList makes the informers stream their initial lists with watches, unless
+	// their ListWatch is customized.
+	useWatchList bool
+
+	// idleTTL is the duration after which idle informers are evicted, if set.
+	idleTTL        time.Duration
+	onIdleEviction func(gvk schema.GroupVersionKind)
+
+	// trackObjects makes the informers count their objects and their size in their stats.
+	trackObjects bool
 }
 
 // Start calls Run on each of the informers and sets started to true. Blocks on the context.
@@ -204,6 +399,14 @@ func (ip *Informers) Start(ctx context.Context) error {
 			ip.startInformerLocked(i)
 		}
 
+		if ip.idleTTL > 0 {
+			ip.waitGroup.Add(1)
+			go func() {
+				defer ip.waitGroup.Done()
+				ip.evictIdleInformers(ctx)
+			}()
+		}
+
 		// Set started to true so we immediately start any informers added later.
 		ip.started = true
 		close(ip.startWait)
@@ -231,6 +434,46 @@ func (ip *Informers) startInformerLocked(cacheEntry *Cache) {
 	}()
 }
 
+// minIdleEvictionInterval is the minimum interval between two checks for idle informers.
+const minIdleEvictionInterval = 100 * time.Millisecond
+
+// evictIdleInformers stops and removes the informers that have been idle for idleTTL
+// until ctx is done.
+func (ip *Informers) evictIdleInformers(ctx context.Context) {
+	// Don't check more often than minIdleEvictionInterval, which also keeps very short
+	// TTLs from making the ticker interval zero.
+	ticker := time.NewTicker(max(ip.idleTTL/2, minIdleEvictionInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ip.evictInformersIdleSince(now.Add(-ip.idleTTL))
+		}
+	}
+}
+
+// evictInformersIdleSince stops and removes the informers that have been idle since
+// since.
+func (ip *Informers) evictInformersIdleSince(since time.Time) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+
+	for _, informerMap := range []map[schema.GroupVersionKind]*Cache{ip.tracker.Structured, ip.tracker.Unstructured, ip.tracker.Metadata} {
+		for gvk, entry := range informerMap {
+			if entry.idle == nil || !entry.idle.idleSince(since) {
+				continue
+			}
+			entry.removeInformer()
+			delete(informerMap, gvk)
+			if ip.onIdleEviction != nil {
+				ip.onIdleEviction(gvk)
+			}
+		}
+	}
+}
+
 func (ip *Informers) waitForStarted(ctx context.Context) bool {
 	select {
 	case <-ip.startWait:
@@ -273,6 +516,9 @@ func (ip *Informers) Peek(gvk schema.GroupVersionKind, obj runtime.Object) (res
 	ip.mu.RLock()
 	defer ip.mu.RUnlock()
 	i, ok := ip.informersByType(obj)[gvk]
+	if ok && i.idle != nil {
+		i.idle.touch()
+	}
 	return i, ip.started, ok
 }
 
@@ -295,7 +541,18 @@ func (ip *Informers) Get(ctx context.Context, gvk schema.GroupVersionKind, obj r
 
 	if shouldBlock && started && !i.Informer.HasSynced() {
 		// Wait for it to sync before returning the Informer so that folks don't read from a stale cache.
-		if !cache.WaitForCacheSync(ctx.Done(), i.Informer.HasSynced) {
+		// Informers stopped after watch errors or removed never sync.
+		stop, cancel := syncs.MergeChans(ctx.Done(), i.stop)
+		defer cancel()
+		if !cache.WaitForCacheSync(stop, i.Informer.HasSynced) {
+			select {
+			case <-i.stop:
+				if stopError := i.stopError(); stopError != "" {
+					return started, nil, fmt.Errorf("informer for %s was stopped before it synced after watch error: %s", gvk, stopError)
+				}
+				return started, nil, fmt.Errorf("informer for %s was removed before it synced", gvk)
+			default:
+			}
 			return started, nil, apierrors.NewTimeoutError(fmt.Sprintf("failed waiting for %T Informer to sync", obj), 0)
 		}
 	}
@@ -303,6 +560,46 @@ func (ip *Informers) Get(ctx context.Context, gvk schema.GroupVersionKind, obj r
 	return started, i, nil
 }
 
+// Stats returns the stats of the informers that aren't external.
+func (ip *Informers) Stats() []InformerStats {
+	var stats []InformerStats
+	for _, i := range ip.entries() {
+		if i.entry.stats != nil {
+			stats = append(stats, i.entry.stats.stats(i.key))
+		}
+	}
+	return stats
+}
+
+// Resync delivers the objects of the informers of the given kind to their event handlers
+// as updates that didn't change them. It returns false if there is no informer of the
+// kind.
+func (ip *Informers) Resync(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	ip.mu.RLock()
+	var informers []*resyncableInformer
+	found := false
+	for _, informerMap := range []map[schema.GroupVersionKind]*Cache{ip.tracker.Structured, ip.tracker.Unstructured, ip.tracker.Metadata} {
+		entry, ok := informerMap[gvk]
+		if !ok {
+			continue
+		}
+		found = true
+		if entry.resyncable == nil {
+			ip.mu.RUnlock()
+			return true, fmt.Errorf("the informer of %s was created externally and can't be resynced", gvk)
+		}
+		informers = append(informers, entry.resyncable)
+	}
+	ip.mu.RUnlock()
+
+	for _, informer := range informers {
+		if err := informer.resync(ctx); err != nil {
+			return true, err
+		}
+	}
+	return found, nil
+}
+
 // Remove removes an informer entry and stops it if it was running.
 func (ip *Informers) Remove(gvk schema.GroupVersionKind, obj runtime.Object) {
 	ip.mu.Lock()
@@ -314,7 +611,7 @@ func (ip *Informers) Remove(gvk schema.GroupVersionKind, obj runtime.Object) {
 	if !ok {
 		return
 	}
-	close(entry.stop)
+	entry.removeInformer()
 	delete(informerMap, gvk)
 }
 
@@ -341,61 +638,150 @@ func (ip *Informers) addInformerToMap(gvk schema.GroupVersionKind, obj runtime.O
 		return i, ip.started, nil
 	}
 
-	// Create a NewSharedIndexInformer and add it to the map.
-	listWatcher, err := ip.makeListWatcher(gvk, obj)
+	// Create the new entry first, as the watch error policy stops the informer through it.
+	i := &Cache{stop: make(chan struct{})}
+	var sharedIndexInformer cache.SharedIndexInformer
+	var idle *idleTrackingInformer
+	var stats *informerStats
+	var resyncable *resyncableInformer
+	if external, ok := ip.externalInformers[gvk]; ok && isStructured(obj) {
+		// Lists by namespace rely on the namespace index.
+		if _, ok := external.GetIndexer().GetIndexers()[cache.NamespaceIndex]; !ok {
+			if err := external.AddIndexers(cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}); err != nil {
+				return nil, false, fmt.Errorf("failed to add namespace index to external informer for %s: %w", gvk, err)
+			}
+		}
+		sharedIndexInformer = external
+		i.external = true
+	} else {
+		stats = newInformerStats(gvk)
+		var err error
+		if sharedIndexInformer, err = ip.newSharedIndexInformer(gvk, obj, stats, i.stopInformer); err != nil {
+			return nil, false, err
+		}
+		resyncable = &resyncableInformer{SharedIndexInformer: sharedIndexInformer}
+		sharedIndexInformer = resyncable
+		// External informers are owned by their creator, so they are never evicted.
+		if ip.idleTTL > 0 {
+			idle = newIdleTrackingInformer(sharedIndexInformer)
+			sharedIndexInformer = idle
+		}
+	}
+
+	mapping, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
 		return nil, false, err
 	}
+
+	// Complete the entry and set it in the map.
+	i.Informer = sharedIndexInformer
+	i.Reader = CacheReader{
+		indexer:          sharedIndexInformer.GetIndexer(),
+		groupVersionKind: gvk,
+		scopeName:        mapping.Scope.Name(),
+		disableDeepCopy:  ip.unsafeDisableDeepCopy,
+	}
+	i.idle = idle
+	i.stats = stats
+	i.resyncable = resyncable
+	ip.informersByType(obj)[gvk] = i
+
+	// Start the informer in case the InformersMap has started, otherwise it will be
+	// started when the InformersMap starts.
+	if ip.started {
+		ip.startInformerLocked(i)
+	}
+	return i, ip.started, nil
+}
+
+// isStructured returns true if obj is neither unstructured nor metadata only.
+func isStructured(obj runtime.Object) bool {
+	switch obj.(type) {
+	case runtime.Unstructured, *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		return false
+	default:
+		return true
+	}
+}
+
+// newSharedIndexInformer creates a new SharedIndexInformer for the GVK.
+func (ip *Informers) newSharedIndexInformer(gvk schema.GroupVersionKind, obj runtime.Object, stats *informerStats, stop func()) (cache.SharedIndexInformer, error) {
+	var listWatcher cache.ListerWatcher
+	listWatcher, err := ip.makeListWatcher(gvk, obj)
+	if err != nil {
+		return nil, err
+	}
+	if listWatch, ok := ip.listWatches[gvk]; ok {
+		listWatcher = listWatch(gvk, listWatcher)
+	} else if ip.useWatchList {
+		listWatcher = newWatchListListerWatcher(listWatcher, func() (runtime.Object, error) {
+			return ip.newList(gvk, obj)
+		})
+	}
 	sharedIndexInformer := ip.newInformer(&cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
 			ip.selector.ApplyToList(&opts)
-			return listWatcher.ListFunc(opts)
+			list, err := listWatcher.List(opts)
+			if err == nil {
+				stats.synced()
+			}
+			return list, err
 		},
 		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
 			ip.selector.ApplyToList(&opts)
 			opts.Watch = true // Watch needs to be set to true separately
-			return listWatcher.WatchFunc(opts)
+			return listWatcher.Watch(opts)
 		},
 	}, obj, calculateResyncPeriod(ip.resync), cache.Indexers{
 		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
 	})
 
-	// Set WatchErrorHandler on SharedIndexInformer if set
-	if ip.watchErrorHandler != nil {
-		if err := sharedIndexInformer.SetWatchErrorHandler(ip.watchErrorHandler); err != nil {
-			return nil, false, err
+	// Count the watch errors before passing them to the WatchErrorPolicy or the
+	// WatchErrorHandler if set
+	watchErrorHandler := ip.watchErrorHandler
+	if watchErrorHandler == nil {
+		watchErrorHandler = cache.DefaultWatchErrorHandler
+	}
+	if err := sharedIndexInformer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		stats.watchError()
+		if ip.watchErrorPolicy == nil {
+			watchErrorHandler(r, err)
+			return
+		}
+		if ip.watchErrorPolicy(r, gvk, err) {
+			stats.stopped(err)
+			stop()
 		}
+	}); err != nil {
+		return nil, err
+	}
+	if ip.trackObjects {
+		if _, err := sharedIndexInformer.AddEventHandler(stats); err != nil {
+			return nil, err
+		}
+		stats.tracksObjects = true
 	}
 
 	// Check to see if there is a transformer for this gvk
 	if err := sharedIndexInformer.SetTransform(ip.transform); err != nil {
-		return nil, false, err
-	}
-
-	mapping, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
-	if err != nil {
-		return nil, false, err
-	}
-
-	// Create the new entry and set it in the map.
-	i := &Cache{
-		Informer: sharedIndexInformer,
-		Reader: CacheReader{
-			indexer:          sharedIndexInformer.GetIndexer(),
-			groupVersionKind: gvk,
-			scopeName:        mapping.Scope.Name(),
-			disableDeepCopy:  ip.unsafeDisableDeepCopy,
-		},
-		stop: make(chan struct{}),
+		return nil, err
 	}
-	ip.informersByType(obj)[gvk] = i
+	return sharedIndexInformer, nil
+}
 
-	// Start the informer in case the InformersMap has started, otherwise it will be
-	// started when the InformersMap starts.
-	if ip.started {
-		ip.startInformerLocked(i)
+// newList returns an empty list of the objects of the given kind.
+func (ip *Informers) newList(gvk schema.GroupVersionKind, obj runtime.Object) (runtime.Object, error) {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	switch obj.(type) {
+	case runtime.Unstructured:
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(listGVK)
+		return list, nil
+	case *metav1.PartialObjectMetadata:
+		return &metav1.PartialObjectMetadataList{}, nil
+	default:
+		return ip.scheme.New(listGVK)
 	}
-	return i, ip.started, nil
 }
 
 func (ip *Informers) makeListWatcher(gvk schema.GroupVersionKind, obj runtime.Object) (*cache.ListWatch, error) {
@@ -17,14 +17,29 @@ limitations under the License.
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Test that gvkFixupWatcher behaves like watch.FakeWatcher
@@ -92,3 +107,356 @@ var _ = Describe("gvkFixupWatcher", func() {
 		consumer(gvkfw)
 	})
 })
+
+var _ = Describe("Informers with external informers", func() {
+	It("should use the external informer instead of creating one", func() {
+		podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+
+		external := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Pod{}, 0, cache.Indexers{})
+
+		ip := NewInformers(nil, &InformersOpts{
+			Scheme:            scheme.Scheme,
+			Mapper:            mapper,
+			ExternalInformers: map[schema.GroupVersionKind]cache.SharedIndexInformer{podGVK: external},
+		})
+		_, entry, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entry.Informer).To(BeIdenticalTo(external))
+
+		Expect(external.GetIndexer().Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}})).To(Succeed())
+		Expect(external.GetIndexer().Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "bar"}})).To(Succeed())
+
+		pods := &corev1.PodList{}
+		Expect(entry.Reader.List(context.Background(), pods, client.InNamespace("default"))).To(Succeed())
+		Expect(pods.Items).To(HaveLen(1))
+		Expect(pods.Items[0].Name).To(Equal("foo"))
+	})
+
+	It("should not run an external informer that was already started", func() {
+		podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+
+		external := &startedInformer{SharedIndexInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Pod{}, 0, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		})}
+		ip := NewInformers(nil, &InformersOpts{
+			Scheme:            scheme.Scheme,
+			Mapper:            mapper,
+			ExternalInformers: map[schema.GroupVersionKind]cache.SharedIndexInformer{podGVK: external},
+		})
+		_, entry, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			entry.Start(stop)
+		}()
+		Consistently(done).ShouldNot(BeClosed())
+		close(stop)
+		Eventually(done).Should(BeClosed())
+		Expect(external.runs.Load()).To(BeZero())
+	})
+})
+
+// startedInformer is an informer that was started by its creator.
+type startedInformer struct {
+	cache.SharedIndexInformer
+	runs atomic.Int64
+}
+
+func (i *startedInformer) HasStarted() bool {
+	return true
+}
+
+func (i *startedInformer) Run(stop <-chan struct{}) {
+	i.runs.Add(1)
+	i.SharedIndexInformer.Run(stop)
+}
+
+var _ = Describe("Informers with custom ListWatches", func() {
+	It("should list and watch through the customized ListerWatcher", func() {
+		podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+
+		var listOpts []metav1.ListOptions
+		var listWatcher cache.ListerWatcher
+		newInformer := func(lw cache.ListerWatcher, obj runtime.Object, resync time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+			listWatcher = lw
+			return cache.NewSharedIndexInformer(lw, obj, resync, indexers)
+		}
+		ip := NewInformers(&rest.Config{}, &InformersOpts{
+			HTTPClient:  http.DefaultClient,
+			Scheme:      scheme.Scheme,
+			Mapper:      mapper,
+			NewInformer: &newInformer,
+			Selector:    Selector{Label: labels.SelectorFromSet(labels.Set{"app": "foo"})},
+			ListWatches: map[schema.GroupVersionKind]ListWatchFunc{
+				podGVK: func(gvk schema.GroupVersionKind, lw cache.ListerWatcher) cache.ListerWatcher {
+					Expect(gvk).To(Equal(podGVK))
+					Expect(lw).NotTo(BeNil())
+					return &cache.ListWatch{
+						ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+							listOpts = append(listOpts, opts)
+							return &corev1.PodList{}, nil
+						},
+					}
+				},
+			},
+		})
+		_, _, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = listWatcher.List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listOpts).To(HaveLen(1))
+		Expect(listOpts[0].LabelSelector).To(Equal("app=foo"))
+	})
+})
+
+var _ = Describe("Informers with an idle TTL", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var (
+		mapper  *meta.DefaultRESTMapper
+		evicted []schema.GroupVersionKind
+		ip      *Informers
+	)
+
+	newInformers := func(ttl time.Duration, external map[schema.GroupVersionKind]cache.SharedIndexInformer) *Informers {
+		return NewInformers(&rest.Config{}, &InformersOpts{
+			HTTPClient:        http.DefaultClient,
+			Scheme:            scheme.Scheme,
+			Mapper:            mapper,
+			ExternalInformers: external,
+			ListWatches: map[schema.GroupVersionKind]ListWatchFunc{
+				podGVK: func(schema.GroupVersionKind, cache.ListerWatcher) cache.ListerWatcher {
+					return &cache.ListWatch{
+						ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+							return &corev1.PodList{}, nil
+						},
+						WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+							return watch.NewFake(), nil
+						},
+					}
+				},
+			},
+			IdleTTL: ttl,
+			OnIdleEviction: func(gvk schema.GroupVersionKind) {
+				evicted = append(evicted, gvk)
+			},
+		})
+	}
+
+	BeforeEach(func() {
+		mapper = meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		evicted = nil
+		ip = newInformers(time.Hour, nil)
+	})
+
+	It("should remove informers without event handlers that weren't gotten since the TTL", func() {
+		_, entry, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+
+		ip.evictInformersIdleSince(time.Now().Add(-time.Minute))
+		_, _, found := ip.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeTrue())
+
+		ip.evictInformersIdleSince(time.Now().Add(time.Minute))
+		_, _, found = ip.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeFalse())
+		Expect(entry.stop).To(BeClosed())
+		Expect(evicted).To(ConsistOf(podGVK))
+	})
+
+	It("should keep informers with event handlers or indexers", func() {
+		_, entry, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+
+		registration, err := entry.Informer.AddEventHandler(cache.ResourceEventHandlerFuncs{})
+		Expect(err).NotTo(HaveOccurred())
+		ip.evictInformersIdleSince(time.Now().Add(time.Minute))
+		_, _, found := ip.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeTrue())
+
+		Expect(entry.Informer.RemoveEventHandler(registration)).To(Succeed())
+		Expect(entry.Informer.AddIndexers(cache.Indexers{"name": func(interface{}) ([]string, error) { return nil, nil }})).To(Succeed())
+		ip.evictInformersIdleSince(time.Now().Add(time.Minute))
+		_, _, found = ip.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeTrue())
+		Expect(evicted).To(BeEmpty())
+	})
+
+	It("should never remove external informers", func() {
+		external := cache.NewSharedIndexInformer(&cache.ListWatch{}, &corev1.Pod{}, 0, cache.Indexers{})
+		ip = newInformers(time.Hour, map[schema.GroupVersionKind]cache.SharedIndexInformer{podGVK: external})
+		_, entry, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entry.Informer).To(BeIdenticalTo(external))
+
+		ip.evictInformersIdleSince(time.Now().Add(time.Minute))
+		_, _, found := ip.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeTrue())
+	})
+
+	It("should remove idle informers periodically once started", func() {
+		// The TTL is shorter than the minimum interval between two checks.
+		ip = newInformers(time.Nanosecond, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(ip.Start(ctx)).To(Succeed())
+		}()
+
+		_, _, err := ip.Get(ctx, podGVK, &corev1.Pod{}, &GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		// Peeking would count as using the informer.
+		Eventually(func() bool {
+			ip.mu.RLock()
+			defer ip.mu.RUnlock()
+			_, found := ip.tracker.Structured[podGVK]
+			return found
+		}).Should(BeFalse())
+		Expect(evicted).To(ConsistOf(podGVK))
+	})
+})
+
+var _ = Describe("Informers stats", func() {
+	It("should track the objects stored by informers and their lists", func() {
+		configMapGVK := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+
+		ip := NewInformers(&rest.Config{}, &InformersOpts{
+			HTTPClient:   http.DefaultClient,
+			Scheme:       scheme.Scheme,
+			Mapper:       mapper,
+			Namespace:    "default",
+			TrackObjects: true,
+			ListWatches: map[schema.GroupVersionKind]ListWatchFunc{
+				configMapGVK: func(schema.GroupVersionKind, cache.ListerWatcher) cache.ListerWatcher {
+					return &cache.ListWatch{
+						ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+							return &corev1.ConfigMapList{Items: []corev1.ConfigMap{
+								{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+								{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar"}, Data: map[string]string{"key": "value"}},
+							}}, nil
+						},
+						WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+							return watch.NewFake(), nil
+						},
+					}
+				},
+			},
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(ip.Start(ctx)).To(Succeed())
+		}()
+		_, _, err := ip.Get(ctx, configMapGVK, &corev1.ConfigMap{}, &GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		objects := informerObjects.WithLabelValues("", "v1", "ConfigMap")
+		Eventually(func() float64 { return testutil.ToFloat64(objects) }).Should(BeEquivalentTo(2))
+		stats := ip.Stats()
+		Expect(stats).To(HaveLen(1))
+		Expect(stats[0].Kind).To(Equal("ConfigMap"))
+		Expect(stats[0].Namespace).To(Equal("default"))
+		Expect(stats[0].Objects).To(BeEquivalentTo(2))
+		Expect(stats[0].ApproximateBytes).To(BeNumerically(">", 0))
+		Expect(stats[0].LastSyncTime).NotTo(BeNil())
+		Expect(testutil.ToFloat64(informerObjectBytes.WithLabelValues("", "v1", "ConfigMap"))).To(BeEquivalentTo(stats[0].ApproximateBytes))
+
+		ip.Remove(configMapGVK, &corev1.ConfigMap{})
+		Eventually(func() float64 { return testutil.ToFloat64(objects) }).Should(BeZero())
+		Expect(ip.Stats()).To(BeEmpty())
+	})
+
+	It("should keep the objects of informers stopped after watch errors until they are removed", func() {
+		synced := make(chan struct{})
+		secretGVK := corev1.SchemeGroupVersion.WithKind("Secret")
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(secretGVK, meta.RESTScopeNamespace)
+
+		ip := NewInformers(&rest.Config{}, &InformersOpts{
+			HTTPClient:   http.DefaultClient,
+			Scheme:       scheme.Scheme,
+			Mapper:       mapper,
+			TrackObjects: true,
+			ListWatches: map[schema.GroupVersionKind]ListWatchFunc{
+				secretGVK: func(schema.GroupVersionKind, cache.ListerWatcher) cache.ListerWatcher {
+					return &cache.ListWatch{
+						ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+							return &corev1.SecretList{Items: []corev1.Secret{
+								{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+							}}, nil
+						},
+						WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+							<-synced
+							return nil, errors.New("watch failed")
+						},
+					}
+				},
+			},
+			WatchErrorPolicy: func(*cache.Reflector, schema.GroupVersionKind, error) bool {
+				return true
+			},
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(ip.Start(ctx)).To(Succeed())
+		}()
+		_, entry, err := ip.Get(ctx, secretGVK, &corev1.Secret{}, &GetOptions{BlockUntilSynced: ptr.To(false)})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Fail the watch once the listed objects are stored, but maybe before they were
+		// delivered to the event handlers.
+		Eventually(entry.Informer.HasSynced).Should(BeTrue())
+		close(synced)
+		Eventually(func() bool {
+			entry.mu.Lock()
+			defer entry.mu.Unlock()
+			return entry.stopped
+		}).Should(BeTrue())
+		Expect(ip.Stats()[0].Objects).To(BeEquivalentTo(1))
+
+		ip.Remove(secretGVK, &corev1.Secret{})
+		Expect(entry.stats.stats(InformerKey{}).Objects).To(BeZero())
+	})
+
+	It("should track the size of updated and deleted objects", func() {
+		stats := newInformerStats(schema.GroupVersionKind{Group: "test", Version: "v1", Kind: "Stats"})
+		small := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "1"}}
+		large := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: "2"}, Data: map[string]string{"key": "value"}}
+
+		stats.OnAdd(small, true)
+		stats.OnUpdate(small, large)
+		// Resyncs don't change the objects.
+		stats.OnUpdate(large, large)
+		Expect(stats.stats(InformerKey{}).Objects).To(BeEquivalentTo(1))
+		Expect(stats.stats(InformerKey{}).ApproximateBytes).To(BeEquivalentTo(large.Size()))
+
+		stats.OnDelete(cache.DeletedFinalStateUnknown{Key: "foo", Obj: large})
+		Expect(stats.stats(InformerKey{}).Objects).To(BeZero())
+		Expect(stats.stats(InformerKey{}).ApproximateBytes).To(BeZero())
+
+		// The objects of stopped informers are counted again from their store.
+		stats.recount([]interface{}{small, large})
+		Expect(stats.stats(InformerKey{}).Objects).To(BeEquivalentTo(2))
+		Expect(stats.stats(InformerKey{}).ApproximateBytes).To(BeEquivalentTo(small.Size() + large.Size()))
+
+		stats.watchError()
+		Expect(stats.stats(InformerKey{}).WatchErrors).To(BeEquivalentTo(1))
+		Expect(stats.stats(InformerKey{}).LastSyncTime).To(BeNil())
+	})
+})
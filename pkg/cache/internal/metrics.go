@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The gauges of the objects are the sums over the informers of each kind, e.g. of the
+// informers of all namespaces of a cache.
+var (
+	// informerObjects is the number of objects stored by informers.
+	informerObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cache_informer_objects",
+		Help: "Number of objects stored by the informers of the cache per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// informerObjectBytes is the approximate size of the objects stored by informers.
+	informerObjectBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cache_informer_object_bytes",
+		Help: "Approximate size in bytes of the serialized objects stored by the informers of the cache per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// informerLastSync is the time of the last successful list of informers.
+	informerLastSync = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cache_informer_last_sync_timestamp_seconds",
+		Help: "Unix timestamp of the last successful list of the informers of the cache per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// informerWatchErrors counts the errors that ended the watches of informers.
+	informerWatchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cache_informer_watch_errors_total",
+		Help: "Total number of errors that ended the watches of the informers of the cache per group, version and kind",
+	}, []string{"group", "version", "kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(informerObjects, informerObjectBytes, informerLastSync, informerWatchErrors)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// resyncableInformer is a SharedIndexInformer whose objects can be redelivered to its
+// event handlers on demand, see Informers.Resync.
+type resyncableInformer struct {
+	cache.SharedIndexInformer
+
+	mu       sync.Mutex
+	handlers []*resyncableHandler
+}
+
+// resyncableHandler is an event handler of a resyncableInformer. The informer never
+// notifies a handler concurrently, so the resyncs are serialized with its notifications.
+type resyncableHandler struct {
+	mu           sync.Mutex
+	handler      cache.ResourceEventHandler
+	registration cache.ResourceEventHandlerRegistration
+}
+
+// OnAdd implements cache.ResourceEventHandler.
+func (h *resyncableHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler.OnAdd(obj, isInInitialList)
+}
+
+// OnUpdate implements cache.ResourceEventHandler.
+func (h *resyncableHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler.OnUpdate(oldObj, newObj)
+}
+
+// OnDelete implements cache.ResourceEventHandler.
+func (h *resyncableHandler) OnDelete(obj interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler.OnDelete(obj)
+}
+
+// AddEventHandler implements cache.SharedIndexInformer.
+func (i *resyncableInformer) AddEventHandler(handler cache.ResourceEventHandler) (cache.ResourceEventHandlerRegistration, error) {
+	h := &resyncableHandler{handler: handler}
+	registration, err := i.SharedIndexInformer.AddEventHandler(h)
+	if err != nil {
+		return nil, err
+	}
+	i.addHandler(h, registration)
+	return registration, nil
+}
+
+// AddEventHandlerWithResyncPeriod implements cache.SharedIndexInformer.
+func (i *resyncableInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) (cache.ResourceEventHandlerRegistration, error) {
+	h := &resyncableHandler{handler: handler}
+	registration, err := i.SharedIndexInformer.AddEventHandlerWithResyncPeriod(h, resyncPeriod)
+	if err != nil {
+		return nil, err
+	}
+	i.addHandler(h, registration)
+	return registration, nil
+}
+
+// RemoveEventHandler implements cache.SharedIndexInformer.
+func (i *resyncableInformer) RemoveEventHandler(handle cache.ResourceEventHandlerRegistration) error {
+	if err := i.SharedIndexInformer.RemoveEventHandler(handle); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = slices.DeleteFunc(i.handlers, func(h *resyncableHandler) bool {
+		return h.registration == handle
+	})
+	return nil
+}
+
+func (i *resyncableInformer) addHandler(h *resyncableHandler, registration cache.ResourceEventHandlerRegistration) {
+	h.registration = registration
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = append(i.handlers, h)
+}
+
+// resync delivers all stored objects to the event handlers as updates that didn't
+// change them, like the periodic resyncs of informers.
+func (i *resyncableInformer) resync(ctx context.Context) error {
+	i.mu.Lock()
+	handlers := slices.Clone(i.handlers)
+	i.mu.Unlock()
+
+	objs := i.GetStore().List()
+	for _, h := range handlers {
+		for _, obj := range objs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			h.OnUpdate(obj, obj)
+		}
+	}
+	return nil
+}
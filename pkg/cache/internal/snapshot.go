@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"cmp"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The object types of the informers in an InformerKey.
+const (
+	ObjectTypeStructured   = "structured"
+	ObjectTypeUnstructured = "unstructured"
+	ObjectTypeMetadata     = "metadata"
+)
+
+// InformerKey identifies an informer of a cache.
+type InformerKey struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// ObjectType is the type of the objects stored by the informer, which is one of
+	// structured, unstructured or metadata.
+	ObjectType string `json:"objectType"`
+	// Namespace is the namespace the informer is restricted to, if any.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Compare orders informer keys by group, version, kind, object type and namespace.
+func (k InformerKey) Compare(other InformerKey) int {
+	for _, c := range []int{
+		cmp.Compare(k.Group, other.Group),
+		cmp.Compare(k.Version, other.Version),
+		cmp.Compare(k.Kind, other.Kind),
+		cmp.Compare(k.ObjectType, other.ObjectType),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(k.Namespace, other.Namespace)
+}
+
+// KindSnapshot summarizes the objects stored by the informer of a kind.
+type KindSnapshot struct {
+	InformerKey
+
+	// Synced is true if the informer has synced.
+	Synced bool `json:"synced"`
+	// LastSyncResourceVersion is the resource version the informer last observed.
+	LastSyncResourceVersion string `json:"lastSyncResourceVersion,omitempty"`
+	// Objects is the number of objects stored by the informer.
+	Objects int `json:"objects"`
+	// Namespaces maps namespaces to the number of objects stored in them, cluster-scoped
+	// objects are counted under the empty namespace.
+	Namespaces map[string]int `json:"namespaces,omitempty"`
+}
+
+// informerEntry is an informer of the cache with its key.
+type informerEntry struct {
+	key   InformerKey
+	entry *Cache
+}
+
+// entries returns the informers of the cache, so that callers can inspect them without
+// holding the lock, which would block creating informers.
+func (ip *Informers) entries() []informerEntry {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	var entries []informerEntry
+	for objectType, informerMap := range ip.informerMapsByObjectType() {
+		for gvk, entry := range informerMap {
+			entries = append(entries, informerEntry{
+				key:   InformerKey{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, ObjectType: objectType, Namespace: ip.namespace},
+				entry: entry,
+			})
+		}
+	}
+	return entries
+}
+
+// Snapshot summarizes the objects stored by the informers.
+func (ip *Informers) Snapshot() []KindSnapshot {
+	informers := ip.entries()
+	snapshots := make([]KindSnapshot, 0, len(informers))
+	for _, i := range informers {
+		snapshot := KindSnapshot{InformerKey: i.key}
+		snapshot.Synced = i.entry.Informer.HasSynced()
+		snapshot.LastSyncResourceVersion = i.entry.Informer.LastSyncResourceVersion()
+		snapshot.Namespaces = map[string]int{}
+		for _, obj := range i.entry.Informer.GetStore().List() {
+			snapshot.Objects++
+			if accessor, err := meta.Accessor(obj); err == nil {
+				snapshot.Namespaces[accessor.GetNamespace()]++
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+// StoredObjects returns the objects with the given key, in the format of
+// cache.MetaNamespaceKeyFunc, stored by the informers of the given kind, mapped by the
+// object type of the informers. The objects must not be mutated.
+func (ip *Informers) StoredObjects(gvk schema.GroupVersionKind, key string) (map[string]runtime.Object, error) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+
+	objs := map[string]runtime.Object{}
+	for objectType, informerMap := range ip.informerMapsByObjectType() {
+		entry, ok := informerMap[gvk]
+		if !ok {
+			continue
+		}
+		obj, exists, err := entry.Informer.GetStore().GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		if runtimeObj, ok := obj.(runtime.Object); ok {
+			objs[objectType] = runtimeObj
+		}
+	}
+	return objs, nil
+}
+
+func (ip *Informers) informerMapsByObjectType() map[string]map[schema.GroupVersionKind]*Cache {
+	return map[string]map[schema.GroupVersionKind]*Cache{
+		ObjectTypeStructured:   ip.tracker.Structured,
+		ObjectTypeUnstructured: ip.tracker.Unstructured,
+		ObjectTypeMetadata:     ip.tracker.Metadata,
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// InformerStats are the stats of an informer.
+type InformerStats struct {
+	InformerKey
+
+	// Objects is the number of objects stored by the informer, if the cache tracks them.
+	Objects int64 `json:"objects"`
+	// ApproximateBytes is the approximate size of the serialized objects stored by the
+	// informer after they were transformed, if the cache tracks them.
+	ApproximateBytes int64 `json:"approximateBytes"`
+	// LastSyncTime is the time of the last successful list of the informer, if any.
+	LastSyncTime *time.Time `json:"lastSyncTime,omitempty"`
+	// WatchErrors is the number of errors that ended the watches of the informer.
+	WatchErrors int64 `json:"watchErrors"`
+	// StopError is the watch error after which the informer was stopped instead of
+	// retrying its watch, if any.
+	StopError string `json:"stopError,omitempty"`
+}
+
+// informerStats records the stats of an informer in the metrics of the cache. It is
+// also an event handler tracking the objects stored by the informer, which is only
+// added to the informers if TrackObjects is set.
+type informerStats struct {
+	gvk schema.GroupVersionKind
+	// tracksObjects is set if the stats are an event handler of the informer.
+	tracksObjects bool
+
+	objects     atomic.Int64
+	bytes       atomic.Int64
+	lastSync    atomic.Int64
+	watchErrors atomic.Int64
+	stopError   atomic.Pointer[string]
+
+	objectsGauge     prometheus.Gauge
+	bytesGauge       prometheus.Gauge
+	lastSyncGauge    prometheus.Gauge
+	watchErrorsCount prometheus.Counter
+}
+
+func newInformerStats(gvk schema.GroupVersionKind) *informerStats {
+	return &informerStats{
+		gvk:              gvk,
+		objectsGauge:     informerObjects.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind),
+		bytesGauge:       informerObjectBytes.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind),
+		lastSyncGauge:    informerLastSync.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind),
+		watchErrorsCount: informerWatchErrors.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind),
+	}
+}
+
+var _ cache.ResourceEventHandler = &informerStats{}
+
+// OnAdd implements cache.ResourceEventHandler.
+func (s *informerStats) OnAdd(obj interface{}, _ bool) {
+	s.add(1, approximateSize(obj))
+}
+
+// OnUpdate implements cache.ResourceEventHandler.
+func (s *informerStats) OnUpdate(oldObj, newObj interface{}) {
+	// Resyncs deliver updates of unchanged objects.
+	if oldMeta, err := meta.Accessor(oldObj); err == nil {
+		if newMeta, err := meta.Accessor(newObj); err == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+			return
+		}
+	}
+	s.add(0, approximateSize(newObj)-approximateSize(oldObj))
+}
+
+// OnDelete implements cache.ResourceEventHandler.
+func (s *informerStats) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	s.add(-1, -approximateSize(obj))
+}
+
+func (s *informerStats) add(objects, bytes int64) {
+	s.objects.Add(objects)
+	s.bytes.Add(bytes)
+	s.objectsGauge.Add(float64(objects))
+	s.bytesGauge.Add(float64(bytes))
+}
+
+// recount replaces the tracked objects with objs, e.g. the objects stored by a stopped
+// informer.
+func (s *informerStats) recount(objs []interface{}) {
+	var bytes int64
+	for _, obj := range objs {
+		bytes += approximateSize(obj)
+	}
+	s.add(int64(len(objs))-s.objects.Load(), bytes-s.bytes.Load())
+}
+
+// synced records a successful list.
+func (s *informerStats) synced() {
+	now := time.Now()
+	s.lastSync.Store(now.UnixNano())
+	s.lastSyncGauge.Set(float64(now.UnixNano()) / float64(time.Second))
+}
+
+// watchError records an error that ended a watch.
+func (s *informerStats) watchError() {
+	s.watchErrors.Add(1)
+	s.watchErrorsCount.Inc()
+}
+
+// stopped records the watch error the informer was stopped after.
+func (s *informerStats) stopped(err error) {
+	msg := err.Error()
+	s.stopError.Store(&msg)
+}
+
+// reset removes the objects of a stopped informer from the metrics.
+func (s *informerStats) reset() {
+	s.add(-s.objects.Load(), -s.bytes.Load())
+}
+
+// stats returns the stats of the informer with the given key.
+func (s *informerStats) stats(key InformerKey) InformerStats {
+	stats := InformerStats{
+		InformerKey:      key,
+		Objects:          s.objects.Load(),
+		ApproximateBytes: s.bytes.Load(),
+		WatchErrors:      s.watchErrors.Load(),
+	}
+	if stopError := s.stopError.Load(); stopError != nil {
+		stats.StopError = *stopError
+	}
+	if lastSync := s.lastSync.Load(); lastSync != 0 {
+		t := time.Unix(0, lastSync)
+		stats.LastSyncTime = &t
+	}
+	return stats
+}
+
+// approximateSize returns the size of the protobuf encoding of obj if it supports it,
+// like the built-in types, or the size of its JSON encoding otherwise.
+func approximateSize(obj interface{}) int64 {
+	if sizer, ok := obj.(interface{ Size() int }); ok {
+		return int64(sizer.Size())
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
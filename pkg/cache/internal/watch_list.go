@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// initialEventsEndAnnotation marks the bookmark a watch sends after the initial
+	// events of the objects that existed when it started.
+	initialEventsEndAnnotation = "k8s.io/initial-events-end"
+
+	// watchListTimeout is the time after which the API server ends the watches
+	// streaming the initial objects, like the timeout of list requests.
+	watchListTimeout = time.Minute
+
+	// watchListIdleTimeout is the time after the last event of a watch streaming the
+	// initial objects after which the API server is assumed to not support WatchList.
+	// API servers without it send the events of the existing objects but never the
+	// bookmark marking their end.
+	watchListIdleTimeout = 10 * time.Second
+)
+
+// watchListListerWatcher is a ListerWatcher that lists objects by streaming them with a
+// watch sending the initial events (WatchList) instead of listing them. The API server
+// serves these watches from its watch cache in constant memory, while large lists are
+// held in memory as a whole. The streamed objects are still collected into a list on the
+// client, so this only saves memory on the API server. Lists fall back to the wrapped
+// ListerWatcher if streaming the objects fails, and are no longer streamed once the API
+// server rejected it or didn't mark the end of the initial events.
+type watchListListerWatcher struct {
+	cache.ListerWatcher
+
+	// newList returns an empty list of the watched objects.
+	newList func() (runtime.Object, error)
+
+	// idleTimeout is the time after the last event after which WatchList is assumed to
+	// be unsupported.
+	idleTimeout time.Duration
+
+	unsupported atomic.Bool
+}
+
+func newWatchListListerWatcher(lw cache.ListerWatcher, newList func() (runtime.Object, error)) *watchListListerWatcher {
+	return &watchListListerWatcher{ListerWatcher: lw, newList: newList, idleTimeout: watchListIdleTimeout}
+}
+
+// List implements cache.Lister.
+func (lw *watchListListerWatcher) List(opts metav1.ListOptions) (runtime.Object, error) {
+	// Continued and exact lists can't be streamed.
+	if lw.unsupported.Load() || opts.Continue != "" || opts.ResourceVersionMatch == metav1.ResourceVersionMatchExact {
+		return lw.ListerWatcher.List(opts)
+	}
+	list, err := lw.watchList(opts)
+	if err != nil {
+		// API servers without the WatchList feature reject the options of the watch.
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) || errors.Is(err, errInitialEventsEndMissing) {
+			lw.unsupported.Store(true)
+		}
+		return lw.ListerWatcher.List(opts)
+	}
+	return list, nil
+}
+
+// errInitialEventsEndMissing is returned when a watch sent no event for the idle timeout
+// before marking the end of the initial events.
+var errInitialEventsEndMissing = errors.New("the watch didn't mark the end of the initial events")
+
+// watchList returns the list of the objects the API server sends as the initial events
+// of a watch.
+func (lw *watchListListerWatcher) watchList(opts metav1.ListOptions) (runtime.Object, error) {
+	w, err := lw.Watch(metav1.ListOptions{
+		LabelSelector:        opts.LabelSelector,
+		FieldSelector:        opts.FieldSelector,
+		Watch:                true,
+		ResourceVersion:      opts.ResourceVersion,
+		ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan,
+		SendInitialEvents:    ptr.To(true),
+		AllowWatchBookmarks:  true,
+		TimeoutSeconds:       ptr.To(int64(watchListTimeout.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer w.Stop()
+
+	idle := time.NewTimer(lw.idleTimeout)
+	defer idle.Stop()
+
+	objects := map[string]runtime.Object{}
+	for {
+		var event watch.Event
+		select {
+		case <-idle.C:
+			return nil, errInitialEventsEndMissing
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil, errors.New("the watch ended before all initial events were received")
+			}
+			event = e
+		}
+		if !idle.Stop() {
+			<-idle.C
+		}
+		idle.Reset(lw.idleTimeout)
+
+		switch event.Type {
+		case watch.Added, watch.Modified, watch.Deleted:
+			key, err := cache.MetaNamespaceKeyFunc(event.Object)
+			if err != nil {
+				return nil, err
+			}
+			if event.Type == watch.Deleted {
+				delete(objects, key)
+				continue
+			}
+			objects[key] = event.Object
+		case watch.Bookmark:
+			bookmark, err := meta.Accessor(event.Object)
+			if err != nil {
+				return nil, err
+			}
+			if bookmark.GetAnnotations()[initialEventsEndAnnotation] != "true" {
+				continue
+			}
+			return lw.listOf(objects, bookmark.GetResourceVersion())
+		case watch.Error:
+			return nil, apierrors.FromObject(event.Object)
+		}
+	}
+}
+
+// listOf returns a list of objects ordered by their keys at resourceVersion.
+func (lw *watchListListerWatcher) listOf(objects map[string]runtime.Object, resourceVersion string) (runtime.Object, error) {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]runtime.Object, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, objects[key])
+	}
+
+	list, err := lw.newList()
+	if err != nil {
+		return nil, err
+	}
+	if err := meta.SetList(list, items); err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	listMeta.SetResourceVersion(resourceVersion)
+	return list, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("watchListListerWatcher", func() {
+	var (
+		lists   []metav1.ListOptions
+		watches []metav1.ListOptions
+		watcher *watch.FakeWatcher
+		watchFn func(metav1.ListOptions) (watch.Interface, error)
+		lw      *watchListListerWatcher
+	)
+
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	bookmark := func(resourceVersion string, initialEventsEnd bool) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: resourceVersion}}
+		if initialEventsEnd {
+			p.Annotations = map[string]string{initialEventsEndAnnotation: "true"}
+		}
+		return p
+	}
+
+	BeforeEach(func() {
+		lists, watches = nil, nil
+		watcher = watch.NewFakeWithChanSize(10, false)
+		watchFn = func(metav1.ListOptions) (watch.Interface, error) {
+			return watcher, nil
+		}
+		lw = newWatchListListerWatcher(&cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				lists = append(lists, opts)
+				return &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}, Items: []corev1.Pod{*pod("listed")}}, nil
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				watches = append(watches, opts)
+				return watchFn(opts)
+			},
+		}, func() (runtime.Object, error) {
+			return &corev1.PodList{}, nil
+		})
+	})
+
+	It("should list the initial events of a watch", func() {
+		watcher.Add(pod("foo"))
+		watcher.Add(pod("bar"))
+		watcher.Action(watch.Bookmark, bookmark("5", false))
+		watcher.Add(pod("baz"))
+		watcher.Delete(pod("bar"))
+		watcher.Action(watch.Bookmark, bookmark("10", true))
+
+		list, err := lw.List(metav1.ListOptions{ResourceVersion: "0", LabelSelector: "app=foo", Limit: 500})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lists).To(BeEmpty())
+		Expect(watches).To(HaveLen(1))
+		Expect(watches[0].LabelSelector).To(Equal("app=foo"))
+		Expect(watches[0].ResourceVersion).To(Equal("0"))
+		Expect(watches[0].ResourceVersionMatch).To(Equal(metav1.ResourceVersionMatchNotOlderThan))
+		Expect(watches[0].SendInitialEvents).To(Equal(ptr.To(true)))
+		Expect(watches[0].AllowWatchBookmarks).To(BeTrue())
+		Expect(watcher.IsStopped()).To(BeTrue())
+
+		pods := list.(*corev1.PodList)
+		Expect(pods.ResourceVersion).To(Equal("10"))
+		Expect(pods.Items).To(HaveLen(2))
+		Expect(pods.Items[0].Name).To(Equal("baz"))
+		Expect(pods.Items[1].Name).To(Equal("foo"))
+	})
+
+	It("should fall back to listing when the API server rejects the watch", func() {
+		watchFn = func(metav1.ListOptions) (watch.Interface, error) {
+			return nil, apierrors.NewInvalid(schema.GroupKind{Group: "meta.k8s.io", Kind: "ListOptions"}, "", field.ErrorList{
+				field.Forbidden(field.NewPath("sendInitialEvents"), "sendInitialEvents is forbidden for watch unless the WatchList feature gate is enabled"),
+			})
+		}
+
+		list, err := lw.List(metav1.ListOptions{ResourceVersion: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.(*corev1.PodList).Items[0].Name).To(Equal("listed"))
+		Expect(lists).To(HaveLen(1))
+
+		_, err = lw.List(metav1.ListOptions{ResourceVersion: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watches).To(HaveLen(1))
+		Expect(lists).To(HaveLen(2))
+	})
+
+	It("should fall back to listing when the watch ends early", func() {
+		watcher.Add(pod("foo"))
+		watcher.Stop()
+
+		list, err := lw.List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.(*corev1.PodList).Items[0].Name).To(Equal("listed"))
+		Expect(lists).To(HaveLen(1))
+		Expect(lw.unsupported.Load()).To(BeFalse())
+	})
+
+	It("should fall back to listing when the watch doesn't mark the end of the initial events", func() {
+		lw.idleTimeout = 10 * time.Millisecond
+		watcher.Add(pod("foo"))
+
+		list, err := lw.List(metav1.ListOptions{ResourceVersion: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.(*corev1.PodList).Items[0].Name).To(Equal("listed"))
+		Expect(watcher.IsStopped()).To(BeTrue())
+		Expect(lw.unsupported.Load()).To(BeTrue())
+
+		_, err = lw.List(metav1.ListOptions{ResourceVersion: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watches).To(HaveLen(1))
+		Expect(lists).To(HaveLen(2))
+	})
+
+	It("should list continued and exact lists", func() {
+		_, err := lw.List(metav1.ListOptions{Continue: "token"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = lw.List(metav1.ListOptions{ResourceVersion: "5", ResourceVersionMatch: metav1.ResourceVersionMatchExact})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watches).To(BeEmpty())
+		Expect(lists).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+// ListPageSize returns a ListWatchFunc that lists objects in pages of the given size.
+func ListPageSize(size int64) ListWatchFunc {
+	return func(_ schema.GroupVersionKind, lw toolscache.ListerWatcher) toolscache.ListerWatcher {
+		return &toolscache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				// A zero limit means that the informer didn't ask for a paginated list.
+				if opts.Limit != 0 {
+					opts.Limit = size
+				}
+				return lw.List(opts)
+			},
+			WatchFunc: lw.Watch,
+		}
+	}
+}
+
+// PollingListWatch returns a ListWatchFunc for APIs that can be listed but not watched,
+// like metrics.k8s.io. Instead of watching, the objects are listed in the given interval
+// and the changes since the previous list are sent as watch events.
+//
+// Objects are considered changed if their resourceVersion changed or, for APIs that
+// don't set it, if they aren't semantically equal.
+//
+// The time of the last successful poll, the poll durations and errors are recorded in
+// the controller_runtime_cache_poll_* metrics.
+func PollingListWatch(interval time.Duration) ListWatchFunc {
+	return func(gvk schema.GroupVersionKind, lw toolscache.ListerWatcher) toolscache.ListerWatcher {
+		return &pollingListWatch{gvk: gvk, lw: lw, interval: interval}
+	}
+}
+
+type pollingListWatch struct {
+	gvk      schema.GroupVersionKind
+	lw       toolscache.ListerWatcher
+	interval time.Duration
+
+	mu sync.Mutex
+	// objects are the objects of the most recent list by key.
+	objects map[string]runtime.Object
+}
+
+// List lists the objects and remembers them, so that a following watch only
+// sends the changes since this list.
+func (p *pollingListWatch) List(opts metav1.ListOptions) (runtime.Object, error) {
+	start := time.Now()
+	list, err := p.lw.List(opts)
+	recordPoll(p.gvk, start, err)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := objectsByKey(list)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Continued lists only contain the next page of the objects.
+	if opts.Continue == "" || p.objects == nil {
+		p.objects = objects
+	} else {
+		for key, obj := range objects {
+			p.objects[key] = obj
+		}
+	}
+	return list, nil
+}
+
+// Watch polls the objects until the returned watch is stopped.
+func (p *pollingListWatch) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	// Reflectors streaming their lists fall back to listing when this is rejected.
+	if ptr.Deref(opts.SendInitialEvents, false) {
+		return nil, apierrors.NewBadRequest("polled objects can't be streamed with the initial events of watches")
+	}
+	listOpts := metav1.ListOptions{
+		LabelSelector: opts.LabelSelector,
+		FieldSelector: opts.FieldSelector,
+	}
+	w := &pollingWatcher{
+		result: make(chan watch.Event),
+		stop:   make(chan struct{}),
+	}
+	go p.poll(listOpts, w)
+	return w, nil
+}
+
+func (p *pollingListWatch) poll(opts metav1.ListOptions, w *pollingWatcher) {
+	defer close(w.result)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		list, err := p.lw.List(opts)
+		recordPoll(p.gvk, start, err)
+		if err != nil {
+			w.send(watch.Event{Type: watch.Error, Object: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: fmt.Sprintf("failed to poll objects: %v", err),
+			}})
+			return
+		}
+		objects, err := objectsByKey(list)
+		if err != nil {
+			w.send(watch.Event{Type: watch.Error, Object: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}})
+			return
+		}
+
+		p.mu.Lock()
+		previous := p.objects
+		p.objects = objects
+		p.mu.Unlock()
+
+		for key, obj := range objects {
+			old, ok := previous[key]
+			switch {
+			case !ok:
+				if !w.send(watch.Event{Type: watch.Added, Object: obj}) {
+					return
+				}
+			case changed(old, obj):
+				if !w.send(watch.Event{Type: watch.Modified, Object: obj}) {
+					return
+				}
+			}
+		}
+		for key, obj := range previous {
+			if _, ok := objects[key]; !ok {
+				if !w.send(watch.Event{Type: watch.Deleted, Object: obj}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func objectsByKey(list runtime.Object) (map[string]runtime.Object, error) {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[string]runtime.Object, len(items))
+	for _, item := range items {
+		key, err := toolscache.MetaNamespaceKeyFunc(item)
+		if err != nil {
+			return nil, err
+		}
+		objects[key] = item
+	}
+	return objects, nil
+}
+
+func changed(old, obj runtime.Object) bool {
+	oldMeta, err := meta.Accessor(old)
+	if err != nil {
+		return true
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return true
+	}
+	if rv := objMeta.GetResourceVersion(); rv != "" {
+		return rv != oldMeta.GetResourceVersion()
+	}
+	return !equality.Semantic.DeepEqual(old, obj)
+}
+
+// pollingWatcher is the watch.Interface of a pollingListWatch.
+type pollingWatcher struct {
+	result   chan watch.Event
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *pollingWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
+
+func (w *pollingWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+// send sends the event unless the watcher was stopped and returns if it was sent.
+func (w *pollingWatcher) send(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("ListWatchFuncs", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+
+	newPod := func(name, image string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: image}}},
+		}
+	}
+
+	It("should set the page size of paginated lists", func() {
+		var limits []int64
+		lw := cache.ListPageSize(10)(podGVK, &toolscache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				limits = append(limits, opts.Limit)
+				return &corev1.PodList{}, nil
+			},
+		})
+
+		_, err := lw.List(metav1.ListOptions{Limit: 500})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = lw.List(metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limits).To(Equal([]int64{10, 0}))
+	})
+
+	Describe("PollingListWatch", func() {
+		var mu sync.Mutex
+		var pods []corev1.Pod
+		var listErr error
+		var lw toolscache.ListerWatcher
+
+		setPods := func(p ...corev1.Pod) {
+			mu.Lock()
+			defer mu.Unlock()
+			pods = p
+		}
+
+		BeforeEach(func() {
+			mu.Lock()
+			listErr = nil
+			mu.Unlock()
+			setPods(newPod("a", "v1"), newPod("b", "v1"))
+			lw = cache.PollingListWatch(10*time.Millisecond)(podGVK, &toolscache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if listErr != nil {
+						return nil, listErr
+					}
+					return &corev1.PodList{Items: append([]corev1.Pod(nil), pods...)}, nil
+				},
+				WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return nil, errors.New("watch is not supported")
+				},
+			})
+		})
+
+		It("should send the changes since the previous list as events", func() {
+			list, err := lw.List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.(*corev1.PodList).Items).To(HaveLen(2))
+
+			w, err := lw.Watch(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			defer w.Stop()
+
+			setPods(newPod("a", "v2"), newPod("c", "v1"))
+
+			events := map[watch.EventType]string{}
+			for i := 0; i < 3; i++ {
+				var event watch.Event
+				Eventually(w.ResultChan()).Should(Receive(&event))
+				events[event.Type] = event.Object.(*corev1.Pod).Name
+			}
+			Expect(events).To(Equal(map[watch.EventType]string{
+				watch.Modified: "a",
+				watch.Added:    "c",
+				watch.Deleted:  "b",
+			}))
+			Consistently(w.ResultChan(), 50*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("should record the time of the last successful poll", func() {
+			before := float64(time.Now().Unix())
+			_, err := lw.List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			families, err := metrics.Registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			var lastSuccess float64
+			for _, family := range families {
+				if family.GetName() != "controller_runtime_cache_poll_last_success_timestamp_seconds" {
+					continue
+				}
+				for _, metric := range family.GetMetric() {
+					for _, label := range metric.GetLabel() {
+						if label.GetName() == "kind" && label.GetValue() == "Pod" {
+							lastSuccess = metric.GetGauge().GetValue()
+						}
+					}
+				}
+			}
+			Expect(lastSuccess).To(BeNumerically(">=", before))
+		})
+
+		It("should end the watch with an error event if polling fails", func() {
+			_, err := lw.List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			mu.Lock()
+			listErr = errors.New("boom")
+			mu.Unlock()
+
+			w, err := lw.Watch(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			var event watch.Event
+			Eventually(w.ResultChan()).Should(Receive(&event))
+			Expect(event.Type).To(Equal(watch.Error))
+			Expect(event.Object.(*metav1.Status).Message).To(ContainSubstring("boom"))
+			Eventually(w.ResultChan()).Should(BeClosed())
+		})
+
+		It("should reject watches streaming the initial events", func() {
+			_, err := lw.Watch(metav1.ListOptions{SendInitialEvents: ptr.To(true)})
+			Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+		})
+
+		It("should close the result channel when stopped", func() {
+			w, err := lw.Watch(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			w.Stop()
+			w.Stop()
+			Eventually(w.ResultChan()).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// pollLastSuccess is the time of the last successful list of polled objects. The
+	// staleness of the cached objects is the time since then.
+	pollLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cache_poll_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last successful poll of objects that are polled instead of watched per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// pollErrors counts the failed lists of polled objects.
+	pollErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cache_poll_errors_total",
+		Help: "Total number of failed polls of objects that are polled instead of watched per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// pollDuration observes how long lists of polled objects take.
+	pollDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "controller_runtime_cache_poll_duration_seconds",
+		Help:    "Duration of polls of objects that are polled instead of watched per group, version and kind",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"group", "version", "kind"})
+
+	// informerEvictions counts the informers removed because they were idle.
+	informerEvictions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cache_informer_evictions_total",
+		Help: "Total number of informers removed from the cache after being idle for the IdleInformerTTL per group, version and kind",
+	}, []string{"group", "version", "kind"})
+
+	// readThroughs counts the reads of objects without informers from the API server.
+	readThroughs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cache_read_through_total",
+		Help: "Total number of reads of objects without a started informer from the API server per group, version, kind and verb",
+	}, []string{"group", "version", "kind", "verb"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(pollLastSuccess, pollErrors, pollDuration, informerEvictions, readThroughs)
+}
+
+// recordPoll records the outcome of a poll of the objects of the given kind.
+func recordPoll(gvk schema.GroupVersionKind, start time.Time, err error) {
+	pollDuration.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Observe(time.Since(start).Seconds())
+	if err != nil {
+		pollErrors.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+		return
+	}
+	pollLastSuccess.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).SetToCurrentTime()
+}
+
+// recordInformerEviction records the removal of the idle informer of the given kind.
+func recordInformerEviction(gvk schema.GroupVersionKind) {
+	informerEvictions.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+}
+
+// recordReadThrough records a read of the objects of the given kind from the API server.
+func recordReadThrough(gvk schema.GroupVersionKind, verb string) {
+	readThroughs.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, verb).Inc()
+}
@@ -18,7 +18,11 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -26,6 +30,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	toolscache "k8s.io/client-go/tools/cache"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,12 +40,40 @@ import (
 // a new global namespaced cache to handle cluster scoped resources.
 const globalCache = "_cluster-scope"
 
+// SetNamespaces changes the namespaces watched by c, a cache created with
+// Options.DefaultNamespaces, at runtime, e.g. for operators whose namespaces are
+// configured by a custom resource. The caches of the namespaces that remain watched are
+// kept, those of removed namespaces are stopped and those of added namespaces are
+// started, with the informers, event handlers and indexes of the existing ones. The
+// ByObject settings without Namespaces follow the changes as well.
+//
+// The objects of removed namespaces are dropped without delete events. If c was
+// started, SetNamespaces waits for the caches of added namespaces to sync until ctx is
+// done.
+//
+// The namespaces of caches that watch all namespaces, including those whose
+// DefaultNamespaces contain AllNamespaces, can't be changed.
+func SetNamespaces(ctx context.Context, c Cache, namespaces []string) error {
+	if slices.Contains(namespaces, metav1.NamespaceAll) {
+		return errors.New("namespaces must not contain AllNamespaces")
+	}
+	switch c := c.(type) {
+	case *multiNamespaceCache:
+		return c.setNamespaces(ctx, namespaces)
+	case *delegatingByGVKCache:
+		return c.setNamespaces(ctx, namespaces)
+	default:
+		return fmt.Errorf("the namespaces of cache %T can't be changed, it must be created with DefaultNamespaces", c)
+	}
+}
+
 func newMultiNamespaceCache(
 	newCache newCacheFunc,
 	scheme *runtime.Scheme,
 	restMapper apimeta.RESTMapper,
 	namespaces map[string]Config,
 	globalConfig *Config, // may be nil in which case no cache for cluster-scoped objects will be created
+	addedNamespaceConfig *Config, // may be nil in which case the namespaces can't be changed with SetNamespaces
 ) Cache {
 	// Create every namespace cache.
 	caches := map[string]Cache{}
@@ -55,10 +88,14 @@ func newMultiNamespaceCache(
 	}
 
 	return &multiNamespaceCache{
-		namespaceToCache: caches,
-		Scheme:           scheme,
-		RESTMapper:       restMapper,
-		clusterCache:     clusterCache,
+		namespaceToCache:     caches,
+		Scheme:               scheme,
+		RESTMapper:           restMapper,
+		clusterCache:         clusterCache,
+		newCache:             newCache,
+		addedNamespaceConfig: addedNamespaceConfig,
+		stopNamespace:        map[string]context.CancelFunc{},
+		informers:            map[informerKey]*multiNamespaceInformer{},
 	}
 }
 
@@ -67,13 +104,53 @@ func newMultiNamespaceCache(
 // operator to a list of namespaces instead of watching every namespace
 // in the cluster.
 type multiNamespaceCache struct {
-	Scheme           *runtime.Scheme
-	RESTMapper       apimeta.RESTMapper
+	Scheme       *runtime.Scheme
+	RESTMapper   apimeta.RESTMapper
+	clusterCache Cache
+	newCache     newCacheFunc
+
+	// addedNamespaceConfig is the config of the namespaces added by SetNamespaces, or
+	// nil if the namespaces of the cache can't be changed.
+	addedNamespaceConfig *Config
+
+	// mu guards the namespaced caches, which are changed by SetNamespaces, and what is
+	// needed to set up the caches of added namespaces like the existing ones.
+	mu               sync.RWMutex
 	namespaceToCache map[string]Cache
-	clusterCache     Cache
+	// ctx is the context the cache was started with, or nil if it wasn't started yet.
+	ctx           context.Context
+	stopNamespace map[string]context.CancelFunc
+	// informers are the informers of namespaced objects returned by the cache, which
+	// get the informers of added namespaces.
+	informers map[informerKey]*multiNamespaceInformer
+	// indexes are the indexes of namespaced objects, which are added to the caches of
+	// added namespaces.
+	indexes []index
+}
+
+// informerKey identifies the informers returned by the cache.
+type informerKey struct {
+	gvk schema.GroupVersionKind
+	// objType is the type of the object of the informer, or nil for informers gotten
+	// by kind.
+	objType reflect.Type
+}
+
+// index is an index added with IndexField.
+type index struct {
+	obj          client.Object
+	field        string
+	extractValue client.IndexerFunc
+	opts         []client.IndexOption
 }
 
-var _ Cache = &multiNamespaceCache{}
+// informerGetter gets an informer from the cache of a namespace.
+type informerGetter func(ctx context.Context, cache Cache, opts ...InformerGetOption) (Informer, error)
+
+var (
+	_ Cache                          = &multiNamespaceCache{}
+	_ client.FieldIndexerWithOptions = &multiNamespaceCache{}
+)
 
 // Methods for multiNamespaceCache to conform to the Informers interface.
 
@@ -97,16 +174,13 @@ func (c *multiNamespaceCache) GetInformer(ctx context.Context, obj client.Object
 		}, nil
 	}
 
-	namespaceToInformer := map[string]Informer{}
-	for ns, cache := range c.namespaceToCache {
-		informer, err := cache.GetInformer(ctx, obj, opts...)
-		if err != nil {
-			return nil, err
-		}
-		namespaceToInformer[ns] = informer
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme)
+	if err != nil {
+		return nil, err
 	}
-
-	return &multiNamespaceInformer{namespaceToInformer: namespaceToInformer}, nil
+	return c.getNamespacedInformer(ctx, informerKey{gvk: gvk, objType: reflect.TypeOf(obj)}, func(ctx context.Context, cache Cache, extraOpts ...InformerGetOption) (Informer, error) {
+		return cache.GetInformer(ctx, obj, append(opts[:len(opts):len(opts)], extraOpts...)...)
+	})
 }
 
 func (c *multiNamespaceCache) RemoveInformer(ctx context.Context, obj client.Object) error {
@@ -120,13 +194,24 @@ func (c *multiNamespaceCache) RemoveInformer(ctx context.Context, obj client.Obj
 		return c.clusterCache.RemoveInformer(ctx, obj)
 	}
 
-	for _, cache := range c.namespaceToCache {
+	for _, cache := range c.namespaceCaches() {
 		err := cache.RemoveInformer(ctx, obj)
 		if err != nil {
 			return err
 		}
 	}
 
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.informers {
+		if key.gvk == gvk {
+			delete(c.informers, key)
+		}
+	}
 	return nil
 }
 
@@ -150,16 +235,52 @@ func (c *multiNamespaceCache) GetInformerForKind(ctx context.Context, gvk schema
 		}, nil
 	}
 
+	return c.getNamespacedInformer(ctx, informerKey{gvk: gvk}, func(ctx context.Context, cache Cache, extraOpts ...InformerGetOption) (Informer, error) {
+		return cache.GetInformerForKind(ctx, gvk, append(opts[:len(opts):len(opts)], extraOpts...)...)
+	})
+}
+
+// getNamespacedInformer returns the informer of namespaced objects identified by key,
+// which get gets from the cache of every namespace.
+func (c *multiNamespaceCache) getNamespacedInformer(ctx context.Context, key informerKey, get informerGetter) (Informer, error) {
+	// Get the informers without holding the lock, as getting them can block until they
+	// are synced.
 	namespaceToInformer := map[string]Informer{}
+	for ns, cache := range c.namespaceCaches() {
+		informer, err := get(ctx, cache)
+		if err != nil {
+			return nil, err
+		}
+		namespaceToInformer[ns] = informer
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The informers of the namespaces are replaced when they were removed for being idle,
+	// see Options.IdleInformerTTL.
+	if informer, ok := c.informers[key]; ok && informer.uses(namespaceToInformer) {
+		return informer, nil
+	}
+	// The namespaces may have changed in the meantime.
 	for ns, cache := range c.namespaceToCache {
-		informer, err := cache.GetInformerForKind(ctx, gvk, opts...)
+		if _, ok := namespaceToInformer[ns]; ok {
+			continue
+		}
+		informer, err := get(ctx, cache, BlockUntilSynced(false))
 		if err != nil {
 			return nil, err
 		}
 		namespaceToInformer[ns] = informer
 	}
+	for ns := range namespaceToInformer {
+		if _, ok := c.namespaceToCache[ns]; !ok {
+			delete(namespaceToInformer, ns)
+		}
+	}
 
-	return &multiNamespaceInformer{namespaceToInformer: namespaceToInformer}, nil
+	informer := &multiNamespaceInformer{namespaceToInformer: namespaceToInformer, get: get}
+	c.informers[key] = informer
+	return informer, nil
 }
 
 func (c *multiNamespaceCache) Start(ctx context.Context) error {
@@ -174,21 +295,136 @@ func (c *multiNamespaceCache) Start(ctx context.Context) error {
 	}
 
 	// start namespaced caches
+	c.mu.Lock()
+	c.ctx = ctx
 	for ns, cache := range c.namespaceToCache {
-		go func(ns string, cache Cache) {
-			if err := cache.Start(ctx); err != nil {
-				log.Error(err, "multi-namespace cache failed to start namespaced informer", "namespace", ns)
-			}
-		}(ns, cache)
+		c.startNamespace(ns, cache)
 	}
+	c.mu.Unlock()
 
 	<-ctx.Done()
 	return nil
 }
 
+// startNamespace starts the cache of the namespace ns. c.mu must be held.
+func (c *multiNamespaceCache) startNamespace(ns string, cache Cache) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.stopNamespace[ns] = cancel
+	go func() {
+		if err := cache.Start(ctx); err != nil {
+			log.Error(err, "multi-namespace cache failed to start namespaced informer", "namespace", ns)
+		}
+	}()
+}
+
+// setNamespaces implements SetNamespaces.
+func (c *multiNamespaceCache) setNamespaces(ctx context.Context, namespaces []string) error {
+	if c.addedNamespaceConfig == nil {
+		return errors.New("the namespaces of a cache watching all namespaces can't be changed")
+	}
+	watched := sets.New(namespaces...)
+
+	c.mu.Lock()
+	for ns := range c.namespaceToCache {
+		if !watched.Has(ns) {
+			c.removeNamespace(ns)
+		}
+	}
+	var added []Cache
+	for _, ns := range sets.List(watched) {
+		if _, ok := c.namespaceToCache[ns]; ok {
+			continue
+		}
+		cache, err := c.addNamespace(ctx, ns)
+		if err != nil {
+			c.mu.Unlock()
+			return fmt.Errorf("failed to add namespace %s to the cache: %w", ns, err)
+		}
+		added = append(added, cache)
+	}
+	started := c.ctx != nil
+	c.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	for _, cache := range added {
+		if !cache.WaitForCacheSync(ctx) {
+			return errors.New("failed waiting for the caches of the added namespaces to sync")
+		}
+	}
+	return nil
+}
+
+// addNamespace creates the cache of the namespace ns with the indexes and informers of
+// the caches of the other namespaces, and starts it if the cache was started. c.mu must
+// be held.
+func (c *multiNamespaceCache) addNamespace(ctx context.Context, ns string) (Cache, error) {
+	cache := c.newCache(*c.addedNamespaceConfig, ns)
+	for _, index := range c.indexes {
+		if err := client.IndexFieldWithOptions(ctx, cache, index.obj, index.field, index.extractValue, index.opts...); err != nil {
+			return nil, err
+		}
+	}
+	for _, multiInformer := range c.informers {
+		informer, err := multiInformer.get(ctx, cache, BlockUntilSynced(false))
+		if err == nil {
+			err = multiInformer.addNamespace(ns, informer)
+		}
+		if err != nil {
+			for _, multiInformer := range c.informers {
+				multiInformer.removeNamespace(ns)
+			}
+			return nil, err
+		}
+	}
+
+	c.namespaceToCache[ns] = cache
+	if c.ctx != nil {
+		c.startNamespace(ns, cache)
+	}
+	return cache, nil
+}
+
+// removeNamespace stops and removes the cache of the namespace ns. c.mu must be held.
+func (c *multiNamespaceCache) removeNamespace(ns string) {
+	if stop, ok := c.stopNamespace[ns]; ok {
+		stop()
+		delete(c.stopNamespace, ns)
+	}
+	delete(c.namespaceToCache, ns)
+	for _, informer := range c.informers {
+		informer.removeNamespace(ns)
+	}
+}
+
+// namespaceCaches returns the current caches of the namespaces.
+func (c *multiNamespaceCache) namespaceCaches() map[string]Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	caches := make(map[string]Cache, len(c.namespaceToCache))
+	for ns, cache := range c.namespaceToCache {
+		caches[ns] = cache
+	}
+	return caches
+}
+
+// caches returns the cluster-scoped cache, if any, and the namespace caches.
+func (c *multiNamespaceCache) caches() []Cache {
+	namespaceCaches := c.namespaceCaches()
+	caches := make([]Cache, 0, len(namespaceCaches)+1)
+	if c.clusterCache != nil {
+		caches = append(caches, c.clusterCache)
+	}
+	for _, cache := range namespaceCaches {
+		caches = append(caches, cache)
+	}
+	return caches
+}
+
 func (c *multiNamespaceCache) WaitForCacheSync(ctx context.Context) bool {
 	synced := true
-	for _, cache := range c.namespaceToCache {
+	for _, cache := range c.namespaceCaches() {
 		if !cache.WaitForCacheSync(ctx) {
 			synced = false
 		}
@@ -202,17 +438,27 @@ func (c *multiNamespaceCache) WaitForCacheSync(ctx context.Context) bool {
 }
 
 func (c *multiNamespaceCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	return c.IndexFieldWithOptions(ctx, obj, field, extractValue)
+}
+
+// IndexFieldWithOptions implements client.FieldIndexerWithOptions.
+func (c *multiNamespaceCache) IndexFieldWithOptions(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc, opts ...client.IndexOption) error {
 	isNamespaced, err := apiutil.IsObjectNamespaced(obj, c.Scheme, c.RESTMapper)
 	if err != nil {
 		return err
 	}
 
 	if !isNamespaced {
-		return c.clusterCache.IndexField(ctx, obj, field, extractValue)
+		return client.IndexFieldWithOptions(ctx, c.clusterCache, obj, field, extractValue, opts...)
 	}
 
-	for _, cache := range c.namespaceToCache {
-		if err := cache.IndexField(ctx, obj, field, extractValue); err != nil {
+	// The index is recorded first, so that namespaces added in the meantime get it.
+	c.mu.Lock()
+	c.indexes = append(c.indexes, index{obj: obj, field: field, extractValue: extractValue, opts: opts})
+	c.mu.Unlock()
+
+	for _, cache := range c.namespaceCaches() {
+		if err := client.IndexFieldWithOptions(ctx, cache, obj, field, extractValue, opts...); err != nil {
 			return err
 		}
 	}
@@ -230,9 +476,10 @@ func (c *multiNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj
 		return c.clusterCache.Get(ctx, key, obj)
 	}
 
-	cache, ok := c.namespaceToCache[key.Namespace]
+	caches := c.namespaceCaches()
+	cache, ok := caches[key.Namespace]
 	if !ok {
-		if global, hasGlobal := c.namespaceToCache[metav1.NamespaceAll]; hasGlobal {
+		if global, hasGlobal := caches[metav1.NamespaceAll]; hasGlobal {
 			return global.Get(ctx, key, obj, opts...)
 		}
 		return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", key)
@@ -255,8 +502,9 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 		return c.clusterCache.List(ctx, list, opts...)
 	}
 
+	caches := c.namespaceCaches()
 	if listOpts.Namespace != corev1.NamespaceAll {
-		cache, ok := c.namespaceToCache[listOpts.Namespace]
+		cache, ok := caches[listOpts.Namespace]
 		if !ok {
 			return fmt.Errorf("unable to list: %v because of unknown namespace for the cache", listOpts.Namespace)
 		}
@@ -276,7 +524,7 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 	limitSet := listOpts.Limit > 0
 
 	var resourceVersion string
-	for _, cache := range c.namespaceToCache {
+	for _, cache := range caches {
 		listObj := list.DeepCopyObject().(client.ObjectList)
 		err = cache.List(ctx, listObj, &listOpts)
 		if err != nil {
@@ -314,10 +562,35 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 
 // multiNamespaceInformer knows how to handle interacting with the underlying informer across multiple namespaces.
 type multiNamespaceInformer struct {
+	// get gets the informer of added namespaces. It is nil for the informers of cluster
+	// scoped objects.
+	get informerGetter
+
+	// mu guards the informers, which change with the namespaces of the cache, and the
+	// event handlers and indexers that are added to the informers of added namespaces.
+	mu                  sync.RWMutex
 	namespaceToInformer map[string]Informer
+	registrations       []*handlerRegistration
+	indexers            []toolscache.Indexers
+}
+
+// uses returns whether i uses the informers of namespaceToInformer for their namespaces.
+func (i *multiNamespaceInformer) uses(namespaceToInformer map[string]Informer) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for ns, informer := range namespaceToInformer {
+		if current, ok := i.namespaceToInformer[ns]; ok && current != informer {
+			return false
+		}
+	}
+	return true
 }
 
 type handlerRegistration struct {
+	handler      toolscache.ResourceEventHandler
+	resyncPeriod *time.Duration
+
+	mu      sync.RWMutex
 	handles map[string]toolscache.ResourceEventHandlerRegistration
 }
 
@@ -327,7 +600,9 @@ type syncer interface {
 
 // HasSynced asserts that the handler has been called for the full initial state of the informer.
 // This uses syncer to be compatible between client-go 1.27+ and older versions when the interface changed.
-func (h handlerRegistration) HasSynced() bool {
+func (h *handlerRegistration) HasSynced() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	for _, reg := range h.handles {
 		if s, ok := reg.(syncer); ok {
 			if !s.HasSynced() {
@@ -338,50 +613,63 @@ func (h handlerRegistration) HasSynced() bool {
 	return true
 }
 
+// add adds the handler to informer, the informer of the namespace ns.
+func (h *handlerRegistration) add(ns string, informer Informer) error {
+	var handle toolscache.ResourceEventHandlerRegistration
+	var err error
+	if h.resyncPeriod != nil {
+		handle, err = informer.AddEventHandlerWithResyncPeriod(h.handler, *h.resyncPeriod)
+	} else {
+		handle, err = informer.AddEventHandler(h.handler)
+	}
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handles[ns] = handle
+	return nil
+}
+
 var _ Informer = &multiNamespaceInformer{}
 
 // AddEventHandler adds the handler to each informer.
 func (i *multiNamespaceInformer) AddEventHandler(handler toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
-	handles := handlerRegistration{
-		handles: make(map[string]toolscache.ResourceEventHandlerRegistration, len(i.namespaceToInformer)),
-	}
-
-	for ns, informer := range i.namespaceToInformer {
-		registration, err := informer.AddEventHandler(handler)
-		if err != nil {
-			return nil, err
-		}
-		handles.handles[ns] = registration
-	}
-
-	return handles, nil
+	return i.addEventHandler(&handlerRegistration{handler: handler})
 }
 
 // AddEventHandlerWithResyncPeriod adds the handler with a resync period to each namespaced informer.
 func (i *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) (toolscache.ResourceEventHandlerRegistration, error) {
-	handles := handlerRegistration{
-		handles: make(map[string]toolscache.ResourceEventHandlerRegistration, len(i.namespaceToInformer)),
-	}
+	return i.addEventHandler(&handlerRegistration{handler: handler, resyncPeriod: &resyncPeriod})
+}
+
+func (i *multiNamespaceInformer) addEventHandler(registration *handlerRegistration) (toolscache.ResourceEventHandlerRegistration, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 
+	registration.handles = make(map[string]toolscache.ResourceEventHandlerRegistration, len(i.namespaceToInformer))
 	for ns, informer := range i.namespaceToInformer {
-		registration, err := informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
-		if err != nil {
+		if err := registration.add(ns, informer); err != nil {
 			return nil, err
 		}
-		handles.handles[ns] = registration
 	}
+	i.registrations = append(i.registrations, registration)
 
-	return handles, nil
+	return registration, nil
 }
 
 // RemoveEventHandler removes a previously added event handler given by its registration handle.
 func (i *multiNamespaceInformer) RemoveEventHandler(h toolscache.ResourceEventHandlerRegistration) error {
-	handles, ok := h.(handlerRegistration)
+	handles, ok := h.(*handlerRegistration)
 	if !ok {
 		return fmt.Errorf("registration is not a registration returned by multiNamespaceInformer")
 	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	for ns, informer := range i.namespaceToInformer {
+		handles.mu.RLock()
 		registration, ok := handles.handles[ns]
+		handles.mu.RUnlock()
 		if !ok {
 			continue
 		}
@@ -389,22 +677,30 @@ func (i *multiNamespaceInformer) RemoveEventHandler(h toolscache.ResourceEventHa
 			return err
 		}
 	}
+	i.registrations = slices.DeleteFunc(i.registrations, func(registration *handlerRegistration) bool {
+		return registration == handles
+	})
 	return nil
 }
 
 // AddIndexers adds the indexers to each informer.
 func (i *multiNamespaceInformer) AddIndexers(indexers toolscache.Indexers) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	for _, informer := range i.namespaceToInformer {
 		err := informer.AddIndexers(indexers)
 		if err != nil {
 			return err
 		}
 	}
+	i.indexers = append(i.indexers, indexers)
 	return nil
 }
 
 // HasSynced checks if each informer has synced.
 func (i *multiNamespaceInformer) HasSynced() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	for _, informer := range i.namespaceToInformer {
 		if !informer.HasSynced() {
 			return false
@@ -415,6 +711,8 @@ func (i *multiNamespaceInformer) HasSynced() bool {
 
 // IsStopped checks if each namespaced informer has stopped, returns false if any are still running.
 func (i *multiNamespaceInformer) IsStopped() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	for _, informer := range i.namespaceToInformer {
 		if stopped := informer.IsStopped(); !stopped {
 			return false
@@ -422,3 +720,34 @@ func (i *multiNamespaceInformer) IsStopped() bool {
 	}
 	return true
 }
+
+// addNamespace adds informer, the informer of the added namespace ns, with the event
+// handlers and indexers of the other informers.
+func (i *multiNamespaceInformer) addNamespace(ns string, informer Informer) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, indexers := range i.indexers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	for _, registration := range i.registrations {
+		if err := registration.add(ns, informer); err != nil {
+			return err
+		}
+	}
+	i.namespaceToInformer[ns] = informer
+	return nil
+}
+
+// removeNamespace removes the informer of the removed namespace ns.
+func (i *multiNamespaceInformer) removeNamespace(ns string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.namespaceToInformer, ns)
+	for _, registration := range i.registrations {
+		registration.mu.Lock()
+		delete(registration.handles, ns)
+		registration.mu.Unlock()
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+// fakeNamespaceCache is the cache of a namespace with a single informer.
+type fakeNamespaceCache struct {
+	Cache
+	informer *controllertest.FakeInformer
+
+	mu      sync.Mutex
+	indexes []string
+	ctx     context.Context
+}
+
+func (c *fakeNamespaceCache) GetInformer(context.Context, client.Object, ...InformerGetOption) (Informer, error) {
+	return c.informer, nil
+}
+
+func (c *fakeNamespaceCache) GetInformerForKind(context.Context, schema.GroupVersionKind, ...InformerGetOption) (Informer, error) {
+	return c.informer, nil
+}
+
+func (c *fakeNamespaceCache) IndexField(_ context.Context, _ client.Object, field string, _ client.IndexerFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.indexes = append(c.indexes, field)
+	return nil
+}
+
+func (c *fakeNamespaceCache) Start(ctx context.Context) error {
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (c *fakeNamespaceCache) WaitForCacheSync(context.Context) bool {
+	return true
+}
+
+func (c *fakeNamespaceCache) started() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx
+}
+
+var _ = Describe("SetNamespaces", func() {
+	var caches map[string]*fakeNamespaceCache
+	var multiCache Cache
+
+	BeforeEach(func() {
+		caches = map[string]*fakeNamespaceCache{}
+		newCache := func(_ Config, namespace string) Cache {
+			cache := &fakeNamespaceCache{informer: &controllertest.FakeInformer{}}
+			caches[namespace] = cache
+			return cache
+		}
+		mapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), apimeta.RESTScopeNamespace)
+		config := Config{}
+		multiCache = newMultiNamespaceCache(newCache, scheme.Scheme, mapper, map[string]Config{"a": {}, "b": {}}, nil, &config)
+	})
+
+	It("should replace the caches of the changed namespaces", func(ctx SpecContext) {
+		Expect(multiCache.IndexField(ctx, &corev1.Pod{}, "spec.nodeName", func(client.Object) []string { return nil })).To(Succeed())
+		informer, err := multiCache.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		var added []string
+		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				added = append(added, obj.(client.Object).GetNamespace())
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(multiCache.Start(ctx)).To(Succeed())
+		}()
+		a, b := caches["a"], caches["b"]
+		Eventually(a.started).ShouldNot(BeNil())
+		Eventually(b.started).ShouldNot(BeNil())
+
+		Expect(SetNamespaces(ctx, multiCache, []string{"b", "c"})).To(Succeed())
+		Expect(caches).To(HaveKey("c"))
+		c := caches["c"]
+		Expect(caches["b"]).To(BeIdenticalTo(b))
+		Expect(a.started().Err()).To(HaveOccurred())
+		Expect(b.started().Err()).NotTo(HaveOccurred())
+		Eventually(c.started).ShouldNot(BeNil())
+		Expect(c.indexes).To(Equal([]string{"spec.nodeName"}))
+
+		b.informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "b"}})
+		c.informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "c"}})
+		Expect(added).To(Equal([]string{"b", "c"}))
+
+		sameInformer, err := multiCache.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sameInformer).To(BeIdenticalTo(informer))
+		Expect(informer.(*multiNamespaceInformer).namespaceToInformer).To(HaveLen(2))
+
+		err = multiCache.Get(ctx, client.ObjectKey{Namespace: "a", Name: "foo"}, &corev1.Pod{})
+		Expect(err).To(MatchError(ContainSubstring("unknown namespace")))
+	})
+
+	It("should create the caches of added namespaces before the cache is started", func(ctx SpecContext) {
+		Expect(SetNamespaces(ctx, multiCache, []string{"a", "c"})).To(Succeed())
+		Expect(caches).To(HaveKey("c"))
+		Expect(caches["c"].started()).To(BeNil())
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(multiCache.Start(ctx)).To(Succeed())
+		}()
+		Eventually(caches["c"].started).ShouldNot(BeNil())
+		Expect(caches["b"].started()).To(BeNil())
+	})
+
+	It("should refuse to watch all namespaces", func(ctx SpecContext) {
+		Expect(SetNamespaces(ctx, multiCache, []string{"a", metav1.NamespaceAll})).NotTo(Succeed())
+	})
+
+	It("should refuse to change the namespaces of caches watching all namespaces", func(ctx SpecContext) {
+		Expect(SetNamespaces(ctx, &informerCache{}, []string{"a"})).NotTo(Succeed())
+	})
+})
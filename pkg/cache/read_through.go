@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// readThroughReader reads the objects of the kinds that have no started informer from
+// the API server, see Options.ReaderReadThroughOnMissingInformer. The objects it reads
+// are restricted to the ones the informers of the cache would hold.
+type readThroughReader struct {
+	reader client.Reader
+
+	// namespace is the namespace the informers are restricted to, if any.
+	namespace string
+	label     labels.Selector
+	field     fields.Selector
+}
+
+// Get reads the object from the API server, and returns a NotFound error if the
+// informers of the cache wouldn't hold it.
+func (r *readThroughReader) Get(ctx context.Context, gvk schema.GroupVersionKind, key client.ObjectKey, out client.Object, opts ...client.GetOption) error {
+	recordReadThrough(gvk, "get")
+	notFound := apierrors.NewNotFound(schema.GroupResource{
+		Group: gvk.Group,
+		// Resource gets set as Kind in the error like for cached objects
+		Resource: gvk.Kind,
+	}, key.Name)
+	if r.namespace != "" && key.Namespace != "" && key.Namespace != r.namespace {
+		return notFound
+	}
+
+	if err := r.reader.Get(ctx, key, out, opts...); err != nil {
+		return err
+	}
+	if r.label != nil && !r.label.Matches(labels.Set(out.GetLabels())) {
+		return notFound
+	}
+	// Only the fields every object has can be matched.
+	if r.field != nil && onlyMetadataFields(r.field) && !r.field.Matches(fields.Set{
+		"metadata.name":      out.GetName(),
+		"metadata.namespace": out.GetNamespace(),
+	}) {
+		return notFound
+	}
+	return nil
+}
+
+// List lists the objects from the API server, restricted by the namespace and selectors
+// of the informers of the cache in addition to opts.
+func (r *readThroughReader) List(ctx context.Context, gvk schema.GroupVersionKind, out client.ObjectList, opts ...client.ListOption) error {
+	recordReadThrough(gvk, "list")
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	if r.namespace != "" {
+		if listOpts.Namespace != "" && listOpts.Namespace != r.namespace {
+			return apimeta.SetList(out, nil)
+		}
+		listOpts.Namespace = r.namespace
+	}
+	if r.label != nil {
+		if listOpts.LabelSelector == nil {
+			listOpts.LabelSelector = r.label
+		} else if requirements, selectable := r.label.Requirements(); selectable {
+			listOpts.LabelSelector = listOpts.LabelSelector.Add(requirements...)
+		}
+	}
+	if r.field != nil {
+		if listOpts.FieldSelector == nil {
+			listOpts.FieldSelector = r.field
+		} else {
+			listOpts.FieldSelector = fields.AndSelectors(listOpts.FieldSelector, r.field)
+		}
+	}
+	return r.reader.List(ctx, out, &listOpts)
+}
+
+// onlyMetadataFields returns whether selector only selects objects by their name and
+// namespace.
+func onlyMetadataFields(selector fields.Selector) bool {
+	for _, requirement := range selector.Requirements() {
+		if requirement.Field != "metadata.name" && requirement.Field != "metadata.namespace" {
+			return false
+		}
+	}
+	return true
+}
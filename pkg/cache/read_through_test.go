@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("informerCache reading through", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var c *informerCache
+
+	pod := func(namespace, name, app string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": app}}}
+	}
+
+	BeforeEach(func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		c = &informerCache{
+			scheme: scheme.Scheme,
+			Informers: internal.NewInformers(&rest.Config{}, &internal.InformersOpts{
+				HTTPClient: http.DefaultClient,
+				Scheme:     scheme.Scheme,
+				Mapper:     mapper,
+				Namespace:  "default",
+				ListWatches: map[schema.GroupVersionKind]internal.ListWatchFunc{
+					podGVK: func(schema.GroupVersionKind, toolscache.ListerWatcher) toolscache.ListerWatcher {
+						return &toolscache.ListWatch{
+							ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+								return &corev1.PodList{Items: []corev1.Pod{*pod("default", "cached", "foo")}}, nil
+							},
+							WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+								return watch.NewFake(), nil
+							},
+						}
+					},
+				},
+			}),
+			readThrough: &readThroughReader{
+				reader: fake.NewClientBuilder().WithObjects(
+					pod("default", "foo", "foo"),
+					pod("default", "bar", "bar"),
+					pod("other", "baz", "foo"),
+				).Build(),
+				namespace: "default",
+				label:     labels.SelectorFromSet(labels.Set{"app": "foo"}),
+			},
+		}
+	})
+
+	It("should get objects without an informer from the API server", func() {
+		gets := readThroughs.WithLabelValues("", "v1", "Pod", "get")
+		before := testutil.ToFloat64(gets)
+
+		out := &corev1.Pod{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo"}, out)).To(Succeed())
+		Expect(out.Name).To(Equal("foo"))
+		Expect(testutil.ToFloat64(gets)).To(Equal(before + 1))
+
+		By("not getting objects the informers wouldn't hold")
+		err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "bar"}, out)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = c.Get(context.Background(), client.ObjectKey{Namespace: "other", Name: "baz"}, out)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		_, _, found := c.Informers.Peek(podGVK, &corev1.Pod{})
+		Expect(found).To(BeFalse())
+	})
+
+	It("should list objects without an informer from the API server", func() {
+		pods := &corev1.PodList{}
+		Expect(c.List(context.Background(), pods)).To(Succeed())
+		Expect(pods.Items).To(HaveLen(1))
+		Expect(pods.Items[0].Name).To(Equal("foo"))
+
+		Expect(c.List(context.Background(), pods, client.InNamespace("other"))).To(Succeed())
+		Expect(pods.Items).To(BeEmpty())
+	})
+
+	It("should read objects with a started informer from it", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		_, err := c.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.WaitForCacheSync(ctx)).To(BeTrue())
+
+		pods := &corev1.PodList{}
+		Expect(c.List(ctx, pods)).To(Succeed())
+		Expect(pods.Items).To(HaveLen(1))
+		Expect(pods.Items[0].Name).To(Equal("cached"))
+	})
+})
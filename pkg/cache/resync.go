@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resyncer is implemented by the caches whose informers can be resynced on demand.
+type resyncer interface {
+	// resync resyncs the informers of the kind gvk, and returns false if there are none.
+	resync(ctx context.Context, gvk schema.GroupVersionKind) (bool, error)
+}
+
+// Resync delivers all objects of the kind gvk stored by the cache c to the event handlers
+// of its informers as updates, like the periodic resyncs of the SyncPeriod, e.g. so that
+// controllers reconcile all their objects on demand. It returns after the objects were
+// delivered, which for the handlers of controllers means that they were queued.
+//
+// It returns an ErrResourceNotCached error if the cache has no informer of the kind,
+// and fails for informers passed in ByObject.
+func Resync(ctx context.Context, c Cache, gvk schema.GroupVersionKind) error {
+	r, ok := c.(resyncer)
+	if !ok {
+		return fmt.Errorf("the informers of cache %T can't be resynced", c)
+	}
+	found, err := r.resync(ctx, gvk)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return &ErrResourceNotCached{GVK: gvk}
+	}
+	return nil
+}
+
+func (ic *informerCache) resync(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	return ic.Informers.Resync(ctx, gvk)
+}
+
+func (c *multiNamespaceCache) resync(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	found := false
+	for _, cache := range c.caches() {
+		r, ok := cache.(resyncer)
+		if !ok {
+			continue
+		}
+		resynced, err := r.resync(ctx, gvk)
+		if err != nil {
+			return true, err
+		}
+		found = found || resynced
+	}
+	return found, nil
+}
+
+func (dbt *delegatingByGVKCache) resync(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	r, ok := dbt.cacheForGVK(gvk).(resyncer)
+	if !ok {
+		return false, nil
+	}
+	return r.resync(ctx, gvk)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+)
+
+var _ = Describe("Resync", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		c      *informerCache
+	)
+
+	BeforeEach(func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+		c = &informerCache{
+			scheme: scheme.Scheme,
+			Informers: internal.NewInformers(&rest.Config{}, &internal.InformersOpts{
+				HTTPClient: http.DefaultClient,
+				Scheme:     scheme.Scheme,
+				Mapper:     mapper,
+				ListWatches: map[schema.GroupVersionKind]internal.ListWatchFunc{
+					podGVK: func(schema.GroupVersionKind, toolscache.ListerWatcher) toolscache.ListerWatcher {
+						return &toolscache.ListWatch{
+							ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+								return &corev1.PodList{Items: []corev1.Pod{
+									{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+									{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}},
+								}}, nil
+							},
+							WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+								return watch.NewFake(), nil
+							},
+						}
+					},
+				},
+			}),
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		Expect(c.WaitForCacheSync(ctx)).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("should deliver all objects to the event handlers as updates", func() {
+		informer, err := c.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var mu sync.Mutex
+		var added, updated []string
+		registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				added = append(added, obj.(*corev1.Pod).Name)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(oldObj).To(BeIdenticalTo(newObj))
+				updated = append(updated, newObj.(*corev1.Pod).Name)
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return added
+		}).Should(HaveLen(2))
+
+		Expect(Resync(ctx, c, podGVK)).To(Succeed())
+		mu.Lock()
+		Expect(updated).To(ConsistOf("a", "b"))
+		mu.Unlock()
+
+		By("not delivering the objects to removed event handlers")
+		Expect(informer.RemoveEventHandler(registration)).To(Succeed())
+		Expect(Resync(ctx, c, podGVK)).To(Succeed())
+		mu.Lock()
+		Expect(updated).To(HaveLen(2))
+		mu.Unlock()
+	})
+
+	It("should fail for kinds without an informer", func() {
+		err := Resync(ctx, c, corev1.SchemeGroupVersion.WithKind("Secret"))
+		Expect(err).To(BeAssignableToTypeOf(&ErrResourceNotCached{}))
+	})
+})
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"golang.org/x/exp/maps"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KindSnapshot summarizes the objects of a kind stored by an informer of a cache: their
+// number in total and per namespace, whether the informer synced and the resource
+// version it last observed, e.g. to compare them with the API server when debugging
+// stale caches or missing events.
+type KindSnapshot internal.KindSnapshot
+
+// snapshotter is implemented by the caches that can summarize the objects they store.
+type snapshotter interface {
+	snapshot() []KindSnapshot
+	storedObjects(gvk schema.GroupVersionKind, key string) (map[string]runtime.Object, error)
+}
+
+// GetSnapshot summarizes the objects stored by the informers of the cache c, without
+// starting informers.
+func GetSnapshot(c Cache) ([]KindSnapshot, error) {
+	s, ok := c.(snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cache %T can't summarize the objects it stores", c)
+	}
+	snapshots := s.snapshot()
+	slices.SortFunc(snapshots, func(a, b KindSnapshot) int {
+		return a.InformerKey.Compare(b.InformerKey)
+	})
+	return snapshots, nil
+}
+
+// GetStoredObjects returns the objects of the kind gvk with the given key exactly as they
+// are stored by the informers of the cache c, without starting informers. They are
+// mapped by the type of the objects stored by the informers, which is "structured",
+// "unstructured" or "metadata", and must not be mutated.
+func GetStoredObjects(c Cache, gvk schema.GroupVersionKind, key client.ObjectKey) (map[string]runtime.Object, error) {
+	s, ok := c.(snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("cache %T can't summarize the objects it stores", c)
+	}
+	storeKey := key.Name
+	if key.Namespace != "" {
+		storeKey = key.Namespace + "/" + key.Name
+	}
+	return s.storedObjects(gvk, storeKey)
+}
+
+// SnapshotHandler returns a handler serving the snapshot of the cache c as JSON, for
+// debugging. It can be added to the metrics server of a manager:
+//
+//	metricsserver.Options{
+//		ExtraHandlers: map[string]http.Handler{
+//			"/debug/cache/snapshot": cache.SnapshotHandler(mgr.GetCache()),
+//		},
+//	}
+func SnapshotHandler(c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		snapshots, err := GetSnapshot(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		if snapshots == nil {
+			snapshots = []KindSnapshot{}
+		}
+		writeSnapshotJSON(w, snapshots)
+	})
+}
+
+// StoredObjectsHandler returns a handler serving the objects of a kind with a key stored
+// by the cache c as JSON, for debugging. The kind is passed in the group, version and
+// kind query parameters and the key in the namespace and name query parameters.
+//
+// The objects are served as they are stored, except for the values of the data and
+// the last applied configuration of Secrets, which are redacted. As they may still
+// contain sensitive information, the handler should only be added to servers whose
+// clients are allowed to read the cached objects, e.g. to the metrics server of a
+// manager whose metrics are served with authentication and authorization:
+//
+//	metricsserver.Options{
+//		FilterProvider: filters.WithAuthenticationAndAuthorization,
+//		ExtraHandlers: map[string]http.Handler{
+//			"/debug/cache/objects": cache.StoredObjectsHandler(mgr.GetCache()),
+//		},
+//	}
+func StoredObjectsHandler(c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		gvk := schema.GroupVersionKind{Group: query.Get("group"), Version: query.Get("version"), Kind: query.Get("kind")}
+		name := query.Get("name")
+		if gvk.Version == "" || gvk.Kind == "" || name == "" {
+			http.Error(w, "the version, kind and name query parameters are required", http.StatusBadRequest)
+			return
+		}
+		objs, err := GetStoredObjects(c, gvk, client.ObjectKey{Namespace: query.Get("namespace"), Name: name})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(objs) == 0 {
+			http.Error(w, fmt.Sprintf("%s %s is not stored by the cache", gvk.Kind, name), http.StatusNotFound)
+			return
+		}
+		for objectType, obj := range objs {
+			objs[objectType] = redactSecret(gvk, obj)
+		}
+		writeSnapshotJSON(w, objs)
+	})
+}
+
+func writeSnapshotJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error(err, "failed to write cache snapshot")
+	}
+}
+
+// redactSecret returns a copy of obj without the values of its data and its last
+// applied configuration if it is a Secret, or obj otherwise.
+func redactSecret(gvk schema.GroupVersionKind, obj runtime.Object) runtime.Object {
+	if gvk.GroupKind() != corev1.SchemeGroupVersion.WithKind("Secret").GroupKind() {
+		return obj
+	}
+	const redacted = "REDACTED"
+	obj = obj.DeepCopyObject()
+	switch secret := obj.(type) {
+	case *corev1.Secret:
+		for key := range secret.Data {
+			secret.Data[key] = []byte(redacted)
+		}
+		for key := range secret.StringData {
+			secret.StringData[key] = redacted
+		}
+	case *unstructured.Unstructured:
+		for _, field := range []string{"data", "stringData"} {
+			if values, ok := secret.Object[field].(map[string]interface{}); ok {
+				for key := range values {
+					values[key] = redacted
+				}
+			}
+		}
+	}
+	// The last applied configuration includes the data, also of metadata only objects.
+	if accessor, err := meta.Accessor(obj); err == nil {
+		if annotations := accessor.GetAnnotations(); annotations[corev1.LastAppliedConfigAnnotation] != "" {
+			annotations[corev1.LastAppliedConfigAnnotation] = redacted
+			accessor.SetAnnotations(annotations)
+		}
+	}
+	return obj
+}
+
+func (ic *informerCache) snapshot() []KindSnapshot {
+	var snapshots []KindSnapshot
+	for _, s := range ic.Informers.Snapshot() {
+		snapshots = append(snapshots, KindSnapshot(s))
+	}
+	return snapshots
+}
+
+func (ic *informerCache) storedObjects(gvk schema.GroupVersionKind, key string) (map[string]runtime.Object, error) {
+	return ic.Informers.StoredObjects(gvk, key)
+}
+
+func (c *multiNamespaceCache) snapshot() []KindSnapshot {
+	return collect(c.caches(), snapshotter.snapshot)
+}
+
+func (c *multiNamespaceCache) storedObjects(gvk schema.GroupVersionKind, key string) (map[string]runtime.Object, error) {
+	objs := map[string]runtime.Object{}
+	for _, cache := range c.caches() {
+		s, ok := cache.(snapshotter)
+		if !ok {
+			continue
+		}
+		cacheObjs, err := s.storedObjects(gvk, key)
+		if err != nil {
+			return nil, err
+		}
+		for objectType, obj := range cacheObjs {
+			objs[objectType] = obj
+		}
+	}
+	return objs, nil
+}
+
+func (dbt *delegatingByGVKCache) snapshot() []KindSnapshot {
+	return collect(append(maps.Values(dbt.caches), dbt.defaultCache), snapshotter.snapshot)
+}
+
+func (dbt *delegatingByGVKCache) storedObjects(gvk schema.GroupVersionKind, key string) (map[string]runtime.Object, error) {
+	s, ok := dbt.cacheForGVK(gvk).(snapshotter)
+	if !ok {
+		return map[string]runtime.Object{}, nil
+	}
+	return s.storedObjects(gvk, key)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache/internal"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SnapshotHandler", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		c      *informerCache
+	)
+
+	BeforeEach(func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		c = &informerCache{
+			scheme: scheme.Scheme,
+			Informers: internal.NewInformers(&rest.Config{}, &internal.InformersOpts{
+				HTTPClient: http.DefaultClient,
+				Scheme:     scheme.Scheme,
+				Mapper:     mapper,
+				ListWatches: map[schema.GroupVersionKind]internal.ListWatchFunc{
+					podGVK: func(schema.GroupVersionKind, toolscache.ListerWatcher) toolscache.ListerWatcher {
+						return &toolscache.ListWatch{
+							ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+								return &corev1.PodList{
+									ListMeta: metav1.ListMeta{ResourceVersion: "42"},
+									Items: []corev1.Pod{
+										{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a"}},
+										{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b"}},
+										{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "a"}},
+									},
+								}, nil
+							},
+							WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+								return watch.NewFake(), nil
+							},
+						}
+					},
+				},
+			}),
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		_, err := c.GetInformer(ctx, &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.WaitForCacheSync(ctx)).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	serve := func(handler http.Handler, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	It("should serve the number of objects per kind and namespace", func() {
+		recorder := serve(SnapshotHandler(c), "/debug/cache/snapshot")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		var snapshots []KindSnapshot
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &snapshots)).To(Succeed())
+		Expect(snapshots).To(Equal([]KindSnapshot{{
+			InformerKey:             InformerKey{Version: "v1", Kind: "Pod", ObjectType: "structured"},
+			Synced:                  true,
+			LastSyncResourceVersion: "42",
+			Objects:                 3,
+			Namespaces:              map[string]int{"default": 2, "other": 1},
+		}}))
+	})
+
+	It("should serve the stored objects with a key", func() {
+		recorder := serve(StoredObjectsHandler(c), "/debug/cache/objects?version=v1&kind=Pod&namespace=other&name=a")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		var objs map[string]corev1.Pod
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &objs)).To(Succeed())
+		Expect(objs).To(HaveKey("structured"))
+		pod := objs["structured"]
+		Expect(client.ObjectKeyFromObject(&pod)).To(Equal(client.ObjectKey{Namespace: "other", Name: "a"}))
+
+		Expect(serve(StoredObjectsHandler(c), "/debug/cache/objects?version=v1&kind=Pod&namespace=other&name=b").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(StoredObjectsHandler(c), "/debug/cache/objects?name=b").Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should redact the data of Secrets", func() {
+		secretGVK := corev1.SchemeGroupVersion.WithKind("Secret")
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"data":{"password":"c2VjcmV0"}}`,
+			}},
+			Data: map[string][]byte{"password": []byte("secret")},
+		}
+		redacted := redactSecret(secretGVK, secret).(*corev1.Secret)
+		Expect(redacted.Data).To(Equal(map[string][]byte{"password": []byte("REDACTED")}))
+		Expect(redacted.Annotations[corev1.LastAppliedConfigAnnotation]).To(Equal("REDACTED"))
+		Expect(secret.Data["password"]).To(Equal([]byte("secret")))
+
+		u := &unstructured.Unstructured{}
+		Expect(scheme.Scheme.Convert(secret, u, nil)).To(Succeed())
+		redactedUnstructured := redactSecret(secretGVK, u).(*unstructured.Unstructured)
+		Expect(redactedUnstructured.Object["data"]).To(Equal(map[string]interface{}{"password": "REDACTED"}))
+
+		metadata := &metav1.PartialObjectMetadata{ObjectMeta: secret.ObjectMeta}
+		redactedMetadata := redactSecret(secretGVK, metadata).(*metav1.PartialObjectMetadata)
+		Expect(redactedMetadata.Annotations[corev1.LastAppliedConfigAnnotation]).To(Equal("REDACTED"))
+
+		Expect(redactSecret(podGVK, secret)).To(BeIdenticalTo(secret))
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The transforms below reduce the memory used by the cache by removing the fields of
+// objects controllers don't need before they are stored, e.g. for all objects:
+//
+//	cache.Options{
+//		DefaultTransform: cache.TransformChain(
+//			cache.TransformStripManagedFields(),
+//			cache.TransformStripAnnotations(corev1.LastAppliedConfigAnnotation),
+//		),
+//	}
+//
+// Transforms are applied to the objects received from the API server before they are
+// stored and passed to event handlers, so they may change them in place. The objects
+// read from the cache lack the removed fields, and are still deep copied on read unless
+// UnsafeDisableDeepCopy is set. Updating them doesn't clear the managedFields of the
+// objects on the API server, which keeps them when they are omitted, but the removed
+// annotations are cleared by updates, so they should only be removed from objects the
+// controller patches instead of updating them.
+//
+// Like the other settings, the transform of ByObject replaces DefaultTransform instead
+// of being combined with it. Chain them to apply both.
+
+// TransformStripManagedFields returns a TransformFunc removing the managedFields of
+// objects, which typically make up a large part of their size.
+func TransformStripManagedFields() toolscache.TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		// Objects the informer couldn't observe the deletion of, like
+		// DeletedFinalStateUnknown, are passed on unchanged.
+		if accessor, err := meta.Accessor(in); err == nil {
+			accessor.SetManagedFields(nil)
+		}
+		return in, nil
+	}
+}
+
+// TransformStripAnnotations returns a TransformFunc removing the given annotations from
+// objects, e.g. corev1.LastAppliedConfigAnnotation, which holds a copy of objects
+// applied with client-side apply.
+func TransformStripAnnotations(keys ...string) toolscache.TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		accessor, err := meta.Accessor(in)
+		if err != nil {
+			return in, nil
+		}
+		annotations := accessor.GetAnnotations()
+		if len(annotations) == 0 {
+			return in, nil
+		}
+		for _, key := range keys {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		accessor.SetAnnotations(annotations)
+		return in, nil
+	}
+}
+
+// TransformObject returns a TransformFunc calling fn with the objects of type T, e.g.
+// to remove the fields of a kind the controller doesn't need in ByObject.Transform.
+// Objects of other types are passed on unchanged.
+func TransformObject[T client.Object](fn func(obj T)) toolscache.TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		if obj, ok := in.(T); ok {
+			fn(obj)
+		}
+		return in, nil
+	}
+}
+
+// TransformChain returns a TransformFunc applying the given transforms in order, e.g.
+// to combine the DefaultTransform with the transform of an object. Nil transforms are
+// skipped.
+func TransformChain(transforms ...toolscache.TransformFunc) toolscache.TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		for _, transform := range transforms {
+			if transform == nil {
+				continue
+			}
+			var err error
+			if in, err = transform(in); err != nil {
+				return nil, err
+			}
+		}
+		return in, nil
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+var _ = Describe("Transforms", func() {
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: "{}",
+				"keep":                             "me",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}},
+		}}
+	}
+
+	It("should strip the managed fields of typed and unstructured objects", func() {
+		out, err := cache.TransformStripManagedFields()(newPod())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*corev1.Pod).ManagedFields).To(BeEmpty())
+
+		u := &unstructured.Unstructured{}
+		u.SetManagedFields(newPod().ManagedFields)
+		out, err = cache.TransformStripManagedFields()(u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*unstructured.Unstructured).Object).To(HaveKeyWithValue("metadata", Not(HaveKey("managedFields"))))
+	})
+
+	It("should pass on objects it can't transform", func() {
+		tombstone := toolscache.DeletedFinalStateUnknown{Key: "default/foo", Obj: newPod()}
+		out, err := cache.TransformStripManagedFields()(tombstone)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(tombstone))
+	})
+
+	It("should strip the given annotations", func() {
+		out, err := cache.TransformStripAnnotations(corev1.LastAppliedConfigAnnotation)(newPod())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*corev1.Pod).Annotations).To(Equal(map[string]string{"keep": "me"}))
+
+		out, err = cache.TransformStripAnnotations(corev1.LastAppliedConfigAnnotation, "keep")(newPod())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*corev1.Pod).Annotations).To(BeNil())
+	})
+
+	It("should transform the objects of a type", func() {
+		transform := cache.TransformObject(func(pod *corev1.Pod) {
+			pod.Spec.Containers = nil
+		})
+
+		pod := newPod()
+		pod.Spec.Containers = []corev1.Container{{Name: "c"}}
+		out, err := transform(pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*corev1.Pod).Spec.Containers).To(BeNil())
+
+		cm := &corev1.ConfigMap{Data: map[string]string{"a": "b"}}
+		out, err = transform(cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal(&corev1.ConfigMap{Data: map[string]string{"a": "b"}}))
+	})
+
+	It("should chain transforms", func() {
+		out, err := cache.TransformChain(
+			cache.TransformStripManagedFields(),
+			nil,
+			cache.TransformStripAnnotations(corev1.LastAppliedConfigAnnotation),
+		)(newPod())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*corev1.Pod).ManagedFields).To(BeEmpty())
+		Expect(out.(*corev1.Pod).Annotations).To(Equal(map[string]string{"keep": "me"}))
+
+		var called bool
+		_, err = cache.TransformChain(
+			func(interface{}) (interface{}, error) { return nil, errors.New("failed") },
+			func(in interface{}) (interface{}, error) { called = true; return in, nil },
+		)(newPod())
+		Expect(err).To(MatchError("failed"))
+		Expect(called).To(BeFalse())
+	})
+})
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// WatchErrorAction is what an informer does after an error ended its watch.
+type WatchErrorAction int
+
+const (
+	// WatchErrorRetry makes the informer backoff and retry its watch.
+	WatchErrorRetry WatchErrorAction = iota
+
+	// WatchErrorStop stops the informer. It keeps serving the objects it stored, which
+	// aren't updated anymore, until it is removed with RemoveInformer, after which it is
+	// started again when it is needed next. Reads of its kind fail instead of blocking if
+	// it was stopped before it synced. The error is reported in the StopError of its
+	// InformerStats, and the controllers watching its kind report it in their status.
+	WatchErrorStop
+)
+
+// WatchErrorHandler is called whenever the watch of the informer of the kind gvk ends with
+// the error err, and decides whether the informer retries its watch or stops, e.g. instead
+// of retrying a watch that is forbidden forever.
+//
+// Unlike DefaultWatchErrorHandler, it is called instead of the logging of the errors by
+// client-go. It must be safe for concurrent use, as it is shared by the informers of the
+// kinds and namespaces it is configured for.
+type WatchErrorHandler func(gvk schema.GroupVersionKind, err error) WatchErrorAction
+
+// StopOnPersistentWatchErrors returns a WatchErrorHandler stopping the informer of a kind
+// once threshold consecutive errors of its watches matched isPersistent, which defaults
+// to apierrors.IsForbidden. It retries the watches otherwise,
+// only logging their errors at a higher verbosity.
+func StopOnPersistentWatchErrors(threshold int, isPersistent func(error) bool) WatchErrorHandler {
+	if isPersistent == nil {
+		isPersistent = apierrors.IsForbidden
+	}
+	var mu sync.Mutex
+	consecutive := map[schema.GroupVersionKind]int{}
+	return func(gvk schema.GroupVersionKind, err error) WatchErrorAction {
+		mu.Lock()
+		defer mu.Unlock()
+		if !isPersistent(err) {
+			delete(consecutive, gvk)
+			log.V(1).Info("retrying watch after error", "gvk", gvk, "error", err.Error())
+			return WatchErrorRetry
+		}
+		consecutive[gvk]++
+		if consecutive[gvk] < threshold {
+			log.V(1).Info("retrying watch after error", "gvk", gvk, "error", err.Error(), "consecutiveErrors", consecutive[gvk])
+			return WatchErrorRetry
+		}
+		delete(consecutive, gvk)
+		log.Error(err, "stopping informer after persistent watch errors", "gvk", gvk, "consecutiveErrors", threshold)
+		return WatchErrorStop
+	}
+}
+
+// watchErrorPolicy returns the policy of the informers deciding whether they stop after
+// watch errors with the WatchErrorHandlers of opts, or nil if there are none.
+func watchErrorPolicy(opts Options) func(r *toolscache.Reflector, gvk schema.GroupVersionKind, err error) bool {
+	if opts.InformerWatchErrorHandler == nil && len(opts.watchErrorHandlers) == 0 {
+		return nil
+	}
+	return func(r *toolscache.Reflector, gvk schema.GroupVersionKind, err error) bool {
+		handler, ok := opts.watchErrorHandlers[gvk]
+		if !ok {
+			handler = opts.InformerWatchErrorHandler
+		}
+		if handler == nil {
+			if opts.DefaultWatchErrorHandler != nil {
+				opts.DefaultWatchErrorHandler(r, err)
+			} else {
+				toolscache.DefaultWatchErrorHandler(r, err)
+			}
+			return false
+		}
+		return handler(gvk, err) == WatchErrorStop
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("WatchErrorHandler", func() {
+	podGVK := corev1.SchemeGroupVersion.WithKind("Pod")
+	var (
+		ctx      context.Context
+		cancel   context.CancelFunc
+		server   *httptest.Server
+		requests atomic.Int64
+		cfg      *rest.Config
+		mapper   meta.RESTMapper
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		requests.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			status := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("not allowed")).ErrStatus
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			Expect(json.NewEncoder(w).Encode(status)).To(Succeed())
+		}))
+		cfg = &rest.Config{Host: server.URL}
+		restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		restMapper.Add(podGVK, meta.RESTScopeNamespace)
+		mapper = restMapper
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	startInformer := func(opts cache.Options) cache.Cache {
+		opts.Mapper = mapper
+		c, err := cache.New(cfg, opts)
+		Expect(err).NotTo(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+		_, err = c.GetInformer(ctx, &corev1.Pod{}, cache.BlockUntilSynced(false))
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	It("should stop informers on persistent watch errors", func() {
+		c := startInformer(cache.Options{
+			InformerWatchErrorHandler: cache.StopOnPersistentWatchErrors(2, nil),
+		})
+
+		Eventually(func(g Gomega) {
+			stats, err := cache.GetInformerStats(c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(stats).To(HaveLen(1))
+			g.Expect(stats[0].WatchErrors).To(BeEquivalentTo(2))
+			g.Expect(stats[0].StopError).To(ContainSubstring("pods"))
+		}).WithTimeout(10 * time.Second).Should(Succeed())
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should fail reads of informers stopped before they synced", func() {
+		c := startInformer(cache.Options{
+			InformerWatchErrorHandler: cache.StopOnPersistentWatchErrors(1, nil),
+		})
+
+		// The reads fail instead of waiting for the informer to sync, once the cache started.
+		Eventually(func() error {
+			return c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "foo"}, &corev1.Pod{})
+		}).WithTimeout(10 * time.Second).Should(MatchError(ContainSubstring("stopped before it synced")))
+	})
+
+	It("should prefer the WatchErrorHandler of ByObject", func() {
+		var byObjectErrors atomic.Int64
+		c := startInformer(cache.Options{
+			InformerWatchErrorHandler: cache.StopOnPersistentWatchErrors(1, nil),
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {
+					WatchErrorHandler: func(gvk schema.GroupVersionKind, err error) cache.WatchErrorAction {
+						Expect(gvk).To(Equal(podGVK))
+						Expect(apierrors.IsForbidden(err)).To(BeTrue())
+						if byObjectErrors.Add(1) < 2 {
+							return cache.WatchErrorRetry
+						}
+						return cache.WatchErrorStop
+					},
+				},
+			},
+		})
+
+		Eventually(func(g Gomega) {
+			stats, err := cache.GetInformerStats(c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(stats).To(HaveLen(1))
+			g.Expect(stats[0].StopError).NotTo(BeEmpty())
+		}).WithTimeout(10 * time.Second).Should(Succeed())
+		Expect(byObjectErrors.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should reject a WatchErrorHandler of an external informer", func() {
+		_, err := cache.New(cfg, cache.Options{
+			Mapper: mapper,
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}: {
+					Informer:          toolscache.NewSharedIndexInformer(&toolscache.ListWatch{}, &corev1.Pod{}, 0, toolscache.Indexers{}),
+					WatchErrorHandler: cache.StopOnPersistentWatchErrors(1, nil),
+				},
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring("must not be combined with WatchErrorHandler")))
+	})
+})
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PartialObjectMetadataFor projects obj, which may be a typed, an unstructured or a
+// PartialObjectMetadata object, onto a PartialObjectMetadata with the GroupVersionKind
+// of obj as determined by GVKForObject and a copy of the ObjectMeta of obj. Passing an
+// empty object, e.g. &corev1.Pod{}, returns an empty PartialObjectMetadata of its kind,
+// as used by the builder for metadata-only watches.
+func PartialObjectMetadataFor(obj runtime.Object, scheme *runtime.Scheme) (*metav1.PartialObjectMetadata, error) {
+	gvk, err := GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine GVK of %T for a metadata-only projection: %w", obj, err)
+	}
+	if meta.IsListType(obj) {
+		return nil, fmt.Errorf("unable to project list %T onto a PartialObjectMetadata, use PartialObjectMetadataListFor instead", obj)
+	}
+	return partialObjectMetadataFor(obj, gvk)
+}
+
+// PartialObjectMetadataListFor projects obj onto a PartialObjectMetadataList. If obj is a
+// list, the list has its GroupVersionKind and its items are projected as by
+// PartialObjectMetadataFor. Otherwise an empty list of the kind of obj, i.e. its kind
+// suffixed with "List", is returned.
+func PartialObjectMetadataListFor(obj runtime.Object, scheme *runtime.Scheme) (*metav1.PartialObjectMetadataList, error) {
+	gvk, err := listGVKFor(obj, scheme)
+	if err != nil {
+		return nil, err
+	}
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(gvk)
+	if !meta.IsListType(obj) {
+		return list, nil
+	}
+
+	itemGVK := gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List"))
+	if err := copyListMeta(obj, &list.ListMeta); err != nil {
+		return nil, err
+	}
+	if err := meta.EachListItem(obj, func(item runtime.Object) error {
+		partial, err := partialObjectMetadataFor(item, itemGVK)
+		if err != nil {
+			return err
+		}
+		list.Items = append(list.Items, *partial)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// UnstructuredFor projects obj, which may be a typed, an unstructured or a
+// PartialObjectMetadata object, onto an Unstructured with the GroupVersionKind of obj as
+// determined by GVKForObject and a copy of the content of obj.
+func UnstructuredFor(obj runtime.Object, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	gvk, err := GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine GVK of %T for an unstructured projection: %w", obj, err)
+	}
+	if meta.IsListType(obj) {
+		return nil, fmt.Errorf("unable to project list %T onto an Unstructured, use UnstructuredListFor instead", obj)
+	}
+	return unstructuredFor(obj, gvk)
+}
+
+// UnstructuredListFor projects obj onto an UnstructuredList. If obj is a list, the list
+// has its GroupVersionKind and its items are projected as by UnstructuredFor. Otherwise
+// an empty list of the kind of obj, i.e. its kind suffixed with "List", is returned.
+func UnstructuredListFor(obj runtime.Object, scheme *runtime.Scheme) (*unstructured.UnstructuredList, error) {
+	gvk, err := listGVKFor(obj, scheme)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if !meta.IsListType(obj) {
+		return list, nil
+	}
+
+	itemGVK := gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List"))
+	var listMeta metav1.ListMeta
+	if err := copyListMeta(obj, &listMeta); err != nil {
+		return nil, err
+	}
+	list.SetResourceVersion(listMeta.ResourceVersion)
+	list.SetContinue(listMeta.Continue)
+	list.SetRemainingItemCount(listMeta.RemainingItemCount)
+	if err := meta.EachListItem(obj, func(item runtime.Object) error {
+		u, err := unstructuredFor(item, itemGVK)
+		if err != nil {
+			return err
+		}
+		list.Items = append(list.Items, *u)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// listGVKFor returns the GroupVersionKind of the list of obj, which is either a list or
+// an object.
+func listGVKFor(obj runtime.Object, scheme *runtime.Scheme) (schema.GroupVersionKind, error) {
+	gvk, err := GVKForObject(obj, scheme)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("unable to determine GVK of %T for a list projection: %w", obj, err)
+	}
+	if !meta.IsListType(obj) {
+		gvk.Kind += "List"
+	}
+	return gvk, nil
+}
+
+// partialObjectMetadataFor projects obj onto a PartialObjectMetadata of the given kind.
+func partialObjectMetadataFor(obj runtime.Object, gvk schema.GroupVersionKind) (*metav1.PartialObjectMetadata, error) {
+	partial := &metav1.PartialObjectMetadata{}
+	switch o := obj.(type) {
+	case *metav1.PartialObjectMetadata:
+		o.ObjectMeta.DeepCopyInto(&partial.ObjectMeta)
+	case runtime.Unstructured:
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.UnstructuredContent(), partial); err != nil {
+			return nil, fmt.Errorf("unable to convert the metadata of %T: %w", obj, err)
+		}
+	case metav1.ObjectMetaAccessor:
+		if objectMeta, ok := o.GetObjectMeta().(*metav1.ObjectMeta); ok {
+			objectMeta.DeepCopyInto(&partial.ObjectMeta)
+		}
+	default:
+		return nil, fmt.Errorf("unable to project %T without metadata onto a PartialObjectMetadata", obj)
+	}
+	partial.SetGroupVersionKind(gvk)
+	return partial, nil
+}
+
+// unstructuredFor projects obj onto an Unstructured of the given kind.
+func unstructuredFor(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	if src, ok := obj.(runtime.Unstructured); ok {
+		u.Object = runtime.DeepCopyJSON(src.UnstructuredContent())
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert %T to unstructured: %w", obj, err)
+		}
+		u.Object = content
+	}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+// copyListMeta copies the ListMeta of the list obj into listMeta.
+func copyListMeta(obj runtime.Object, listMeta *metav1.ListMeta) error {
+	accessor, err := meta.ListAccessor(obj)
+	if err != nil {
+		return err
+	}
+	listMeta.ResourceVersion = accessor.GetResourceVersion()
+	listMeta.Continue = accessor.GetContinue()
+	listMeta.RemainingItemCount = accessor.GetRemainingItemCount()
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil_test
+
+import (
+	"testing"
+
+	gmg "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+func TestProjections(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	podListGVK := schema.GroupVersionKind{Version: "v1", Kind: "PodList"}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod", Labels: map[string]string{"app": "app"}},
+		Spec:       corev1.PodSpec{NodeName: "node"},
+	}
+
+	t.Run("PartialObjectMetadataFor projects typed objects", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		partial, err := apiutil.PartialObjectMetadataFor(pod, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(partial.GroupVersionKind()).To(gmg.Equal(podGVK))
+		g.Expect(partial.ObjectMeta).To(gmg.Equal(pod.ObjectMeta))
+
+		partial.Labels["app"] = "other"
+		g.Expect(pod.Labels["app"]).To(gmg.Equal("app"))
+	})
+
+	t.Run("PartialObjectMetadataFor projects empty objects like the builder", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		partial, err := apiutil.PartialObjectMetadataFor(&corev1.Pod{}, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		expected := &metav1.PartialObjectMetadata{}
+		expected.SetGroupVersionKind(podGVK)
+		g.Expect(partial).To(gmg.Equal(expected))
+	})
+
+	t.Run("PartialObjectMetadataFor projects unstructured objects", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		u, err := apiutil.UnstructuredFor(pod, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		partial, err := apiutil.PartialObjectMetadataFor(u, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(partial.GroupVersionKind()).To(gmg.Equal(podGVK))
+		g.Expect(partial.Labels).To(gmg.Equal(pod.Labels))
+	})
+
+	t.Run("PartialObjectMetadataFor fails for unregistered types and lists", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		_, err := apiutil.PartialObjectMetadataFor(&metav1.PartialObjectMetadata{}, scheme.Scheme)
+		g.Expect(err).To(gmg.HaveOccurred())
+		_, err = apiutil.PartialObjectMetadataFor(&corev1.PodList{}, scheme.Scheme)
+		g.Expect(err).To(gmg.HaveOccurred())
+	})
+
+	t.Run("UnstructuredFor projects typed objects", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		u, err := apiutil.UnstructuredFor(pod, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(u.GroupVersionKind()).To(gmg.Equal(podGVK))
+		g.Expect(u.GetName()).To(gmg.Equal("pod"))
+		nodeName, _, err := unstructured.NestedString(u.Object, "spec", "nodeName")
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(nodeName).To(gmg.Equal("node"))
+	})
+
+	t.Run("list variants project lists and objects", func(t *testing.T) {
+		g := gmg.NewWithT(t)
+		pods := &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "42"}, Items: []corev1.Pod{*pod}}
+
+		partialList, err := apiutil.PartialObjectMetadataListFor(pods, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(partialList.GroupVersionKind()).To(gmg.Equal(podListGVK))
+		g.Expect(partialList.ResourceVersion).To(gmg.Equal("42"))
+		g.Expect(partialList.Items).To(gmg.HaveLen(1))
+		g.Expect(partialList.Items[0].GroupVersionKind()).To(gmg.Equal(podGVK))
+		g.Expect(partialList.Items[0].Name).To(gmg.Equal("pod"))
+
+		unstructuredList, err := apiutil.UnstructuredListFor(pods, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(unstructuredList.GroupVersionKind()).To(gmg.Equal(podListGVK))
+		g.Expect(unstructuredList.GetResourceVersion()).To(gmg.Equal("42"))
+		g.Expect(unstructuredList.Items).To(gmg.HaveLen(1))
+		g.Expect(unstructuredList.Items[0].GroupVersionKind()).To(gmg.Equal(podGVK))
+
+		emptyList, err := apiutil.PartialObjectMetadataListFor(&corev1.Pod{}, scheme.Scheme)
+		g.Expect(err).NotTo(gmg.HaveOccurred())
+		g.Expect(emptyList.GroupVersionKind()).To(gmg.Equal(podListGVK))
+		g.Expect(emptyList.Items).To(gmg.BeEmpty())
+	})
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ServerSideApply applies applyConfiguration with server-side apply, i.e. it patches the
+// object with the Apply patch. applyConfiguration is either a typed apply configuration,
+// e.g. from k8s.io/client-go/applyconfigurations, or an unstructured object, and must set
+// its apiVersion, kind and name. The field owner must be set with the FieldOwner option,
+// and conflicts can be resolved with the ForceOwnership option:
+//
+//	cm := corev1ac.ConfigMap("name", "namespace").WithData(map[string]string{"key": "value"})
+//	applied, err := client.ServerSideApply(ctx, c, cm, client.FieldOwner("my-controller"), client.ForceOwnership)
+//
+// The object returned by the API server is returned as unstructured object.
+func ServerSideApply(ctx context.Context, c Writer, applyConfiguration interface{}, opts ...PatchOption) (*unstructured.Unstructured, error) {
+	obj, err := ApplyConfigurationToUnstructured(applyConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Patch(ctx, obj, Apply, opts...); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ServerSideApplyStatus is like ServerSideApply, but applies applyConfiguration to the
+// status subresource.
+func ServerSideApplyStatus(ctx context.Context, c StatusClient, applyConfiguration interface{}, opts ...SubResourcePatchOption) (*unstructured.Unstructured, error) {
+	obj, err := ApplyConfigurationToUnstructured(applyConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Status().Patch(ctx, obj, Apply, opts...); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ApplyConfigurationToUnstructured converts applyConfiguration, a typed apply configuration
+// or an unstructured object, into an unstructured object that can be patched with the
+// Apply patch. It fails if applyConfiguration doesn't set its apiVersion, kind and name.
+func ApplyConfigurationToUnstructured(applyConfiguration interface{}) (*unstructured.Unstructured, error) {
+	if applyConfiguration == nil || reflect.ValueOf(applyConfiguration).Kind() == reflect.Ptr && reflect.ValueOf(applyConfiguration).IsNil() {
+		return nil, fmt.Errorf("apply configuration must not be nil")
+	}
+
+	obj := &unstructured.Unstructured{}
+	if u, ok := applyConfiguration.(runtime.Unstructured); ok {
+		obj.Object = runtime.DeepCopyJSON(u.UnstructuredContent())
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(applyConfiguration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert apply configuration %T to unstructured: %w", applyConfiguration, err)
+		}
+		obj.Object = content
+	}
+
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return nil, fmt.Errorf("apply configuration %T must set apiVersion and kind", applyConfiguration)
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("apply configuration %T must set the name", applyConfiguration)
+	}
+	return obj, nil
+}
+
+// ExtractApplyConfiguration extracts the fields of the live object obj owned by
+// fieldManager through server-side apply into an unstructured apply configuration, which
+// can be modified and applied again with ServerSideApply. Fields that aren't part of it
+// anymore are removed from the object when it is applied. obj is either a typed or an
+// unstructured object and must have its managed fields. If fieldManager doesn't own any
+// field, the apply configuration only has the apiVersion, kind, name and namespace. The
+// kind of obj is determined with the scheme, see apiutil.GVKForObject.
+func ExtractApplyConfiguration(obj Object, fieldManager string, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	return extractApplyConfiguration(obj, fieldManager, "", scheme)
+}
+
+// ExtractStatusApplyConfiguration is like ExtractApplyConfiguration, but extracts the
+// fields owned by fieldManager through the status subresource.
+func ExtractStatusApplyConfiguration(obj Object, fieldManager string, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	return extractApplyConfiguration(obj, fieldManager, "status", scheme)
+}
+
+func extractApplyConfiguration(obj Object, fieldManager, subresource string, scheme *runtime.Scheme) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine GVK of %T for an apply configuration: %w", obj, err)
+	}
+
+	var content map[string]interface{}
+	if u, ok := obj.(runtime.Unstructured); ok {
+		content = u.UnstructuredContent()
+	} else {
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			return nil, fmt.Errorf("unable to convert %T to unstructured: %w", obj, err)
+		}
+	}
+
+	extracted := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.Subresource != subresource {
+			continue
+		}
+		if entry.FieldsV1 == nil {
+			break
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("unable to decode managed fields of %q: %w", fieldManager, err)
+		}
+		if m, ok := extractFields(content, fields).(map[string]interface{}); ok {
+			extracted.Object = m
+		}
+		break
+	}
+
+	extracted.SetGroupVersionKind(gvk)
+	extracted.SetName(obj.GetName())
+	if obj.GetNamespace() != "" {
+		extracted.SetNamespace(obj.GetNamespace())
+	}
+	return extracted, nil
+}
+
+// extractFields returns the parts of value that are members of the field set fields,
+// which is encoded in the FieldsV1 format of the managed fields.
+func extractFields(value interface{}, fields map[string]interface{}) interface{} {
+	if isLeaf(fields) {
+		return runtime.DeepCopyJSONValue(value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, subFields := range fields {
+			name, ok := strings.CutPrefix(key, "f:")
+			if !ok {
+				continue
+			}
+			child, ok := v[name]
+			if !ok {
+				continue
+			}
+			result[name] = extractFields(child, asFields(subFields))
+		}
+		return result
+	case []interface{}:
+		extracted := map[int]interface{}{}
+		for key, subFields := range fields {
+			i := listItemIndex(v, key)
+			if i < 0 {
+				continue
+			}
+			item := extractFields(v[i], asFields(subFields))
+			// The key fields of an item are always part of its apply configuration.
+			if keyFields, ok := strings.CutPrefix(key, "k:"); ok {
+				if m, ok := item.(map[string]interface{}); ok {
+					var keys map[string]interface{}
+					_ = json.Unmarshal([]byte(keyFields), &keys)
+					for name := range keys {
+						if _, ok := m[name]; !ok {
+							m[name] = runtime.DeepCopyJSONValue(v[i].(map[string]interface{})[name])
+						}
+					}
+				}
+			}
+			extracted[i] = item
+		}
+		indexes := make([]int, 0, len(extracted))
+		for i := range extracted {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		result := make([]interface{}, 0, len(indexes))
+		for _, i := range indexes {
+			result = append(result, extracted[i])
+		}
+		return result
+	default:
+		return runtime.DeepCopyJSONValue(value)
+	}
+}
+
+// listItemIndex returns the index of the item of list that the path element key, i.e.
+// k:<key fields>, v:<value> or i:<index>, refers to, or -1 if there is none.
+func listItemIndex(list []interface{}, key string) int {
+	switch {
+	case strings.HasPrefix(key, "k:"):
+		var keys map[string]interface{}
+		if err := json.Unmarshal([]byte(key[2:]), &keys); err != nil {
+			return -1
+		}
+		for i, item := range list {
+			m, ok := item.(map[string]interface{})
+			if ok && matchesJSON(m, keys) {
+				return i
+			}
+		}
+	case strings.HasPrefix(key, "v:"):
+		var value interface{}
+		if err := json.Unmarshal([]byte(key[2:]), &value); err != nil {
+			return -1
+		}
+		for i, item := range list {
+			if equalJSON(item, value) {
+				return i
+			}
+		}
+	case strings.HasPrefix(key, "i:"):
+		i, err := strconv.Atoi(key[2:])
+		if err == nil && i >= 0 && i < len(list) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesJSON returns whether the item has the given key fields.
+func matchesJSON(item, keys map[string]interface{}) bool {
+	for name, value := range keys {
+		if !equalJSON(item[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalJSON returns whether a and b encode to the same JSON, as the numbers of
+// unstructured objects and of decoded JSON have different types.
+func equalJSON(a, b interface{}) bool {
+	aJSON, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aJSON) == string(bJSON)
+}
+
+// isLeaf returns whether the field set doesn't have any children.
+func isLeaf(fields map[string]interface{}) bool {
+	for key := range fields {
+		if key != "." {
+			return false
+		}
+	}
+	return true
+}
+
+func asFields(value interface{}) map[string]interface{} {
+	fields, _ := value.(map[string]interface{})
+	return fields
+}
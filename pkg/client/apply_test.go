@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("ServerSideApply", func() {
+	var patches []client.Patch
+	var patchOpts *client.PatchOptions
+	var cl client.WithWatch
+
+	BeforeEach(func() {
+		patches = nil
+		patchOpts = &client.PatchOptions{}
+		cl = interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			Patch: func(_ context.Context, _ client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				patches = append(patches, patch)
+				patchOpts.ApplyOptions(opts)
+				obj.SetUID(types.UID("uid"))
+				return nil
+			},
+		})
+	})
+
+	It("should apply typed apply configurations", func() {
+		cm := corev1ac.ConfigMap("name", "namespace").WithData(map[string]string{"key": "value"})
+		applied, err := client.ServerSideApply(context.Background(), cl, cm, client.FieldOwner("owner"), client.ForceOwnership)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(patches).To(HaveLen(1))
+		Expect(patches[0]).To(Equal(client.Apply))
+		Expect(patchOpts.FieldManager).To(Equal("owner"))
+		Expect(*patchOpts.Force).To(BeTrue())
+
+		By("returning the object returned by the API server")
+		Expect(applied.GetUID()).To(Equal(types.UID("uid")))
+		Expect(applied.GetAPIVersion()).To(Equal("v1"))
+		Expect(applied.GetKind()).To(Equal("ConfigMap"))
+		Expect(applied.GetNamespace()).To(Equal("namespace"))
+		Expect(applied.Object).To(HaveKeyWithValue("data", map[string]interface{}{"key": "value"}))
+	})
+
+	It("should apply unstructured objects", func() {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetName("name")
+		_, err := client.ServerSideApply(context.Background(), cl, u, client.FieldOwner("owner"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patches).To(HaveLen(1))
+		Expect(u.GetUID()).To(BeEmpty())
+	})
+
+	It("should fail for apply configurations without kind or name", func() {
+		_, err := client.ServerSideApply(context.Background(), cl, &corev1ac.ConfigMapApplyConfiguration{}, client.FieldOwner("owner"))
+		Expect(err).To(HaveOccurred())
+		_, err = client.ServerSideApply(context.Background(), cl, corev1ac.ConfigMap("", "namespace"), client.FieldOwner("owner"))
+		Expect(err).To(HaveOccurred())
+		_, err = client.ServerSideApply(context.Background(), cl, nil, client.FieldOwner("owner"))
+		Expect(err).To(HaveOccurred())
+		Expect(patches).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ExtractApplyConfiguration", func() {
+	managedFields := func(manager, subresource string, fields map[string]interface{}) metav1.ManagedFieldsEntry {
+		raw, err := json.Marshal(fields)
+		Expect(err).NotTo(HaveOccurred())
+		return metav1.ManagedFieldsEntry{
+			Manager:     manager,
+			Operation:   metav1.ManagedFieldsOperationApply,
+			Subresource: subresource,
+			FieldsType:  "FieldsV1",
+			FieldsV1:    &metav1.FieldsV1{Raw: raw},
+		}
+	}
+
+	pod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "pod",
+				Labels:    map[string]string{"owned": "true", "other": "true"},
+				ManagedFields: []metav1.ManagedFieldsEntry{
+					managedFields("owner", "", map[string]interface{}{
+						"f:metadata": map[string]interface{}{
+							"f:labels": map[string]interface{}{"f:owned": map[string]interface{}{}},
+						},
+						"f:spec": map[string]interface{}{
+							"f:containers": map[string]interface{}{
+								`k:{"name":"app"}`: map[string]interface{}{
+									".":       map[string]interface{}{},
+									"f:image": map[string]interface{}{},
+								},
+							},
+						},
+					}),
+					managedFields("owner", "status", map[string]interface{}{
+						"f:status": map[string]interface{}{"f:message": map[string]interface{}{}},
+					}),
+				},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "sidecar", Image: "sidecar"},
+				{Name: "app", Image: "app", ImagePullPolicy: corev1.PullAlways},
+			}},
+			Status: corev1.PodStatus{Message: "message", Reason: "reason"},
+		}
+	}
+
+	It("should extract the fields owned by the field manager", func() {
+		ac, err := client.ExtractApplyConfiguration(pod(), "owner", scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ac.Object).To(Equal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"namespace": "default",
+				"name":      "pod",
+				"labels":    map[string]interface{}{"owned": "true"},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app"},
+				},
+			},
+		}))
+	})
+
+	It("should extract the fields owned through the status subresource", func() {
+		ac, err := client.ExtractStatusApplyConfiguration(pod(), "owner", scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ac.Object).To(HaveKeyWithValue("status", map[string]interface{}{"message": "message"}))
+		Expect(ac.Object).NotTo(HaveKey("spec"))
+	})
+
+	It("should extract unstructured objects", func() {
+		u := &unstructured.Unstructured{}
+		Expect(scheme.Scheme.Convert(pod(), u, nil)).To(Succeed())
+		ac, err := client.ExtractApplyConfiguration(u, "owner", scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ac.GetLabels()).To(Equal(map[string]string{"owned": "true"}))
+	})
+
+	It("should only extract the identity if the field manager doesn't own any field", func() {
+		ac, err := client.ExtractApplyConfiguration(pod(), "other", scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ac.Object).To(Equal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"namespace": "default", "name": "pod"},
+		}))
+	})
+})
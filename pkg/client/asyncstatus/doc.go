@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package asyncstatus provides a Writer that writes status updates asynchronously, so that
+slow status writes, e.g. while the API server throttles requests, don't extend the latency
+of reconciles or hold their workers.
+
+The Writer is added to the manager as a Runnable. Reconcilers hand status updates to it,
+keyed by object: only the latest queued update of an object is written, after a short
+batch interval in which further updates of the object replace it. Failed writes are
+retried with backoff.
+
+	writer := asyncstatus.NewWriter(mgr.GetClient(), asyncstatus.Options{})
+	if err := mgr.Add(writer); err != nil {
+		return err
+	}
+
+	// In the reconciler:
+	deployment.Status.ObservedGeneration = deployment.Generation
+	writer.Update(deployment)
+*/
+package asyncstatus
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package asyncstatus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+const (
+	defaultWorkers       = 2
+	defaultBatchInterval = 100 * time.Millisecond
+	defaultMaxRetries    = 10
+)
+
+var log = logf.RuntimeLog.WithName("asyncstatus")
+
+// Options are the options of a Writer.
+type Options struct {
+	// Workers is the number of concurrent status writes. Defaults to 2.
+	Workers int
+
+	// BatchInterval is the time a queued status update waits to be written. Updates of
+	// the same object that are queued in this interval replace it. Defaults to 100ms.
+	BatchInterval time.Duration
+
+	// RateLimiter is the rate limiter of the retries of failed writes.
+	// Defaults to workqueue.DefaultControllerRateLimiter().
+	RateLimiter workqueue.RateLimiter
+
+	// MaxRetries is the number of times a failed write is retried before it is
+	// dropped, unless it is replaced by a newer update. Defaults to 10.
+	MaxRetries int
+
+	// OnError, if set, is called with the status update that is dropped after its
+	// retries are exhausted, e.g. to record an Event.
+	OnError func(obj client.Object, err error)
+}
+
+// key identifies the object of a status update.
+type key struct {
+	gvk schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// update is a queued status update.
+type update struct {
+	obj        client.Object
+	patch      client.Patch
+	updateOpts []client.SubResourceUpdateOption
+	patchOpts  []client.SubResourcePatchOption
+	isPatch    bool
+}
+
+// Writer writes status updates asynchronously. It is a Runnable that must be added to
+// the manager, updates that are queued before it is started are written once it starts.
+type Writer struct {
+	client client.Client
+	opts   Options
+	queue  workqueue.RateLimitingInterface
+
+	mu      sync.Mutex
+	pending map[key]*update
+}
+
+// NewWriter returns a Writer that writes the status updates with the given client.
+func NewWriter(c client.Client, opts Options) *Writer {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.BatchInterval <= 0 {
+		opts.BatchInterval = defaultBatchInterval
+	}
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = workqueue.DefaultControllerRateLimiter()
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	return &Writer{
+		client: c,
+		opts:   opts,
+		queue: workqueue.NewRateLimitingQueueWithConfig(opts.RateLimiter, workqueue.RateLimitingQueueConfig{
+			Name: "asyncstatus",
+		}),
+		pending: map[key]*update{},
+	}
+}
+
+// Update queues an update of the status of obj, replacing any queued status update
+// of the same object. The object is copied, so it can be modified afterwards. If the
+// update conflicts, it is retried with the latest resourceVersion of the object, i.e.
+// the queued status wins.
+func (w *Writer) Update(obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return w.enqueue(obj, &update{updateOpts: opts})
+}
+
+// Patch queues a patch of the status of obj, replacing any queued status update of
+// the same object. The object is copied, so it can be modified afterwards.
+func (w *Writer) Patch(obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return w.enqueue(obj, &update{patch: patch, patchOpts: opts, isPatch: true})
+}
+
+func (w *Writer) enqueue(obj client.Object, u *update) error {
+	gvk, err := w.client.GroupVersionKindFor(obj)
+	if err != nil {
+		return err
+	}
+	k := key{gvk: gvk, NamespacedName: client.ObjectKeyFromObject(obj)}
+	u.obj = obj.DeepCopyObject().(client.Object)
+
+	w.mu.Lock()
+	w.pending[k] = u
+	w.mu.Unlock()
+
+	// The queue deduplicates the key, so updates queued in the batch interval are
+	// written once. Forget resets the backoff of a failing previous update.
+	w.queue.Forget(k)
+	w.queue.AddAfter(k, w.opts.BatchInterval)
+	return nil
+}
+
+// Pending returns the number of status updates that weren't written yet.
+func (w *Writer) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending)
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface. The Writer
+// runs on all replicas, as it only writes what reconcilers queue.
+func (w *Writer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start writes the queued status updates until ctx is done.
+func (w *Writer) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	wg.Add(w.opts.Workers)
+	for i := 0; i < w.opts.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for w.processNext(ctx) {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	w.queue.ShutDown()
+	wg.Wait()
+	return nil
+}
+
+func (w *Writer) processNext(ctx context.Context) bool {
+	item, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer w.queue.Done(item)
+	k := item.(key)
+
+	w.mu.Lock()
+	u, ok := w.pending[k]
+	w.mu.Unlock()
+	if !ok {
+		return true
+	}
+
+	err := w.write(ctx, u)
+	if err == nil || apierrors.IsNotFound(err) {
+		w.finish(k, u)
+		if err != nil {
+			log.V(1).Info("Dropping status update of deleted object", "kind", k.gvk.Kind, "object", k.NamespacedName)
+		}
+		return true
+	}
+
+	if w.queue.NumRequeues(k) < w.opts.MaxRetries {
+		log.V(1).Info("Retrying status update", "kind", k.gvk.Kind, "object", k.NamespacedName, "error", err.Error())
+		w.queue.AddRateLimited(k)
+		return true
+	}
+
+	log.Error(err, "Dropping status update after retries", "kind", k.gvk.Kind, "object", k.NamespacedName)
+	if w.finish(k, u) && w.opts.OnError != nil {
+		w.opts.OnError(u.obj, err)
+	}
+	return true
+}
+
+// finish removes the written update, unless it was replaced by a newer update in the
+// meantime. It returns whether the update was removed.
+func (w *Writer) finish(k key, u *update) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending[k] != u {
+		return false
+	}
+	delete(w.pending, k)
+	w.queue.Forget(k)
+	return true
+}
+
+func (w *Writer) write(ctx context.Context, u *update) error {
+	if u.isPatch {
+		return w.client.Status().Patch(ctx, u.obj, u.patch, u.patchOpts...)
+	}
+
+	// Write a copy, so that a concurrent retry can't observe a partially updated object.
+	obj := u.obj.DeepCopyObject().(client.Object)
+	err := w.client.Status().Update(ctx, obj, u.updateOpts...)
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+
+	// The queued status wins, so retry with the latest resourceVersion of the object.
+	latest := u.obj.DeepCopyObject().(client.Object)
+	if err := w.client.Get(ctx, client.ObjectKeyFromObject(latest), latest); err != nil {
+		return fmt.Errorf("failed to get latest resourceVersion after conflict: %w", err)
+	}
+	obj.SetResourceVersion(latest.GetResourceVersion())
+	return w.client.Status().Update(ctx, obj, u.updateOpts...)
+}
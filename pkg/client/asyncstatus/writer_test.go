@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package asyncstatus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		ctx        context.Context
+		cancel     context.CancelFunc
+		deployment *appsv1.Deployment
+		writes     atomic.Int32
+		writeErr   func(attempt int32) error
+		c          client.Client
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+		writes.Store(0)
+		writeErr = func(int32) error { return nil }
+		c = fake.NewClientBuilder().
+			WithObjects(deployment).
+			WithStatusSubresource(deployment).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if err := writeErr(writes.Add(1)); err != nil {
+						return err
+					}
+					return c.SubResource(subResource).Update(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	start := func(w *Writer) {
+		go func() {
+			defer GinkgoRecover()
+			Expect(w.Start(ctx)).To(Succeed())
+		}()
+	}
+
+	observedGeneration := func() int64 {
+		current := &appsv1.Deployment{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(deployment), current)).To(Succeed())
+		return current.Status.ObservedGeneration
+	}
+
+	It("should only write the latest queued status update of an object", func() {
+		w := NewWriter(c, Options{BatchInterval: 50 * time.Millisecond})
+		for i := int64(1); i <= 3; i++ {
+			deployment.Status.ObservedGeneration = i
+			Expect(w.Update(deployment)).To(Succeed())
+		}
+		// The queued updates are copies of the object.
+		deployment.Status.ObservedGeneration = 42
+		start(w)
+
+		Eventually(observedGeneration).Should(Equal(int64(3)))
+		Eventually(w.Pending).Should(BeZero())
+		Expect(writes.Load()).To(Equal(int32(1)))
+	})
+
+	It("should write the queued status of objects with a stale resourceVersion", func() {
+		stale := deployment.DeepCopy()
+		deployment.Labels = map[string]string{"changed": "true"}
+		Expect(c.Update(ctx, deployment)).To(Succeed())
+
+		w := NewWriter(c, Options{BatchInterval: time.Millisecond})
+		stale.Status.ObservedGeneration = 7
+		Expect(w.Update(stale)).To(Succeed())
+		start(w)
+
+		Eventually(observedGeneration).Should(Equal(int64(7)))
+	})
+
+	It("should retry failed writes with backoff", func() {
+		writeErr = func(attempt int32) error {
+			if attempt < 3 {
+				return apierrors.NewTooManyRequests("slow down", 0)
+			}
+			return nil
+		}
+		w := NewWriter(c, Options{
+			BatchInterval: time.Millisecond,
+			RateLimiter:   workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond),
+		})
+		deployment.Status.ObservedGeneration = 5
+		Expect(w.Update(deployment)).To(Succeed())
+		start(w)
+
+		Eventually(observedGeneration).Should(Equal(int64(5)))
+		Expect(writes.Load()).To(Equal(int32(3)))
+	})
+
+	It("should drop status updates once their retries are exhausted", func() {
+		writeErr = func(int32) error { return errors.New("boom") }
+		var mu sync.Mutex
+		var dropped []client.Object
+		w := NewWriter(c, Options{
+			BatchInterval: time.Millisecond,
+			RateLimiter:   workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond),
+			MaxRetries:    2,
+			OnError: func(obj client.Object, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(err).To(MatchError("boom"))
+				dropped = append(dropped, obj)
+			},
+		})
+		Expect(w.Update(deployment)).To(Succeed())
+		start(w)
+
+		Eventually(w.Pending).Should(BeZero())
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(dropped).To(HaveLen(1))
+		Expect(dropped[0].GetName()).To(Equal("foo"))
+		Expect(writes.Load()).To(Equal(int32(3)))
+	})
+
+	It("should patch the status", func() {
+		w := NewWriter(c, Options{BatchInterval: time.Millisecond})
+		patch := client.MergeFrom(deployment.DeepCopy())
+		deployment.Status.ObservedGeneration = 9
+		Expect(w.Patch(deployment, patch)).To(Succeed())
+		start(w)
+
+		Eventually(observedGeneration).Should(Equal(int64(9)))
+	})
+
+	It("should reject objects of unknown kinds", func() {
+		w := NewWriter(c, Options{})
+		obj := &metav1.PartialObjectMetadata{}
+		Expect(w.Update(obj)).NotTo(Succeed())
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+		Expect(w.Update(obj)).To(Succeed())
+	})
+})
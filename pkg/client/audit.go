@@ -0,0 +1,321 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// auditedMutations counts the mutating requests of clients with an audit trail.
+var auditedMutations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "controller_runtime_client_mutations_total",
+	Help: "Total number of mutating requests of clients with an audit trail per verb, group, version, kind, subresource and outcome",
+}, []string{"verb", "group", "version", "kind", "subresource", "outcome"})
+
+func init() {
+	metrics.Registry.MustRegister(auditedMutations)
+}
+
+// AuditOptions are the options of the audit trail of a client, see WithAudit.
+type AuditOptions struct {
+	// Logger is the logger the mutations are logged with. Defaults to the
+	// "client-audit" logger of the log package.
+	Logger *logr.Logger
+
+	// EventRecorder, if set, records an Event on the mutated object for every
+	// mutation, of type Warning for failed ones.
+	EventRecorder record.EventRecorder
+}
+
+// WithAudit wraps an existing client so that it leaves an audit trail of the changes
+// it makes: every create, update, patch and deletion, including those of subresources,
+// is logged with its verb, kind, namespace and name, a summary of the changed fields,
+// the controller making it, see WithControllerName, and its outcome. Mutations are
+// also counted by the controller_runtime_client_mutations_total metric, and optionally
+// recorded as Events.
+//
+// The summary of the changed fields lists the fields set by patches, e.g. spec.replicas,
+// and the resource versions before and after updates.
+func WithAudit(c Client, opts AuditOptions) Client {
+	if opts.Logger == nil {
+		logger := log.Log.WithName("client-audit")
+		opts.Logger = &logger
+	}
+	return &auditClient{client: c, opts: opts}
+}
+
+var _ Client = &auditClient{}
+
+// auditClient is a Client that wraps another Client in order to audit its mutations.
+type auditClient struct {
+	client Client
+	opts   AuditOptions
+}
+
+// Scheme returns the scheme this client is using.
+func (c *auditClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *auditClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *auditClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *auditClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *auditClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	err := c.client.Create(ctx, obj, opts...)
+	c.audit(ctx, "create", "", obj, nil, err)
+	return err
+}
+
+// Update implements client.Client.
+func (c *auditClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	fromResourceVersion := obj.GetResourceVersion()
+	err := c.client.Update(ctx, obj, opts...)
+	c.audit(ctx, "update", "", obj, updateSummary(fromResourceVersion, obj, err), err)
+	return err
+}
+
+// Delete implements client.Client.
+func (c *auditClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	err := c.client.Delete(ctx, obj, opts...)
+	c.audit(ctx, "delete", "", obj, nil, err)
+	return err
+}
+
+// DeleteAllOf implements client.Client.
+func (c *auditClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	err := c.client.DeleteAllOf(ctx, obj, opts...)
+	deleteAllOfOpts := DeleteAllOfOptions{}
+	deleteAllOfOpts.ApplyOptions(opts)
+	var summary []interface{}
+	if deleteAllOfOpts.LabelSelector != nil {
+		summary = append(summary, "labelSelector", deleteAllOfOpts.LabelSelector.String())
+	}
+	if deleteAllOfOpts.FieldSelector != nil {
+		summary = append(summary, "fieldSelector", deleteAllOfOpts.FieldSelector.String())
+	}
+	if deleteAllOfOpts.Namespace != "" {
+		summary = append(summary, "namespace", deleteAllOfOpts.Namespace)
+	}
+	c.audit(ctx, "deletecollection", "", obj, summary, err)
+	return err
+}
+
+// Patch implements client.Client.
+func (c *auditClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	summary := patchSummary(patch, obj)
+	err := c.client.Patch(ctx, obj, patch, opts...)
+	c.audit(ctx, "patch", "", obj, summary, err)
+	return err
+}
+
+// Get implements client.Client.
+func (c *auditClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *auditClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *auditClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClient.
+func (c *auditClient) SubResource(subResource string) SubResourceClient {
+	return &auditSubResourceClient{client: c.client.SubResource(subResource), subResource: subResource, parent: c}
+}
+
+// audit logs, counts and optionally records as an Event a mutation of obj.
+func (c *auditClient) audit(ctx context.Context, verb, subResource string, obj Object, summary []interface{}, err error) {
+	gvk, gvkErr := c.client.GroupVersionKindFor(obj)
+	if gvkErr != nil {
+		gvk = obj.GetObjectKind().GroupVersionKind()
+	}
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	auditedMutations.WithLabelValues(verb, gvk.Group, gvk.Version, gvk.Kind, subResource, outcome).Inc()
+
+	keysAndValues := []interface{}{
+		"verb", verb,
+		"group", gvk.Group,
+		"version", gvk.Version,
+		"kind", gvk.Kind,
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName(),
+		"outcome", outcome,
+	}
+	if subResource != "" {
+		keysAndValues = append(keysAndValues, "subresource", subResource)
+	}
+	if controller := ControllerNameFromContext(ctx); controller != "" {
+		keysAndValues = append(keysAndValues, "controller", controller)
+	}
+	if impersonate, ok := impersonationFrom(ctx); ok {
+		keysAndValues = append(keysAndValues, "impersonatedUser", impersonate.UserName)
+	}
+	keysAndValues = append(keysAndValues, summary...)
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+	c.opts.Logger.Info("Mutation", keysAndValues...)
+
+	if c.opts.EventRecorder == nil || verb == "deletecollection" {
+		return
+	}
+	action := verb
+	if subResource != "" {
+		action = fmt.Sprintf("%s %s", verb, subResource)
+	}
+	if err != nil {
+		c.opts.EventRecorder.Eventf(obj, corev1.EventTypeWarning, "MutationFailed", "Failed to %s %s: %v", action, gvk.Kind, err)
+		return
+	}
+	c.opts.EventRecorder.Eventf(obj, corev1.EventTypeNormal, "Mutated", "Succeeded to %s %s", action, gvk.Kind)
+}
+
+// updateSummary returns the resource versions of obj before and after an update.
+func updateSummary(fromResourceVersion string, obj Object, err error) []interface{} {
+	if err != nil {
+		return []interface{}{"fromResourceVersion", fromResourceVersion}
+	}
+	return []interface{}{"fromResourceVersion", fromResourceVersion, "toResourceVersion", obj.GetResourceVersion()}
+}
+
+// patchSummary returns the type of patch and the fields it sets on obj.
+func patchSummary(patch Patch, obj Object) []interface{} {
+	summary := []interface{}{"patchType", string(patch.Type())}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return summary
+	}
+	return append(summary, "fields", patchFields(patch.Type(), data))
+}
+
+// patchFields returns the fields set by a patch, up to the second level of nesting,
+// e.g. spec.replicas. The identity and the resource version of objects are omitted.
+func patchFields(patchType types.PatchType, data []byte) []string {
+	fields := map[string]struct{}{}
+	if patchType == types.JSONPatchType {
+		var operations []struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(data, &operations); err != nil {
+			return nil
+		}
+		for _, op := range operations {
+			parts := strings.SplitN(strings.TrimPrefix(op.Path, "/"), "/", 3)
+			fields[strings.Join(parts[:min(len(parts), 2)], ".")] = struct{}{}
+		}
+	} else {
+		var content map[string]interface{}
+		if err := json.Unmarshal(data, &content); err != nil {
+			return nil
+		}
+		for key, value := range content {
+			nested, ok := value.(map[string]interface{})
+			if !ok || len(nested) == 0 {
+				fields[key] = struct{}{}
+				continue
+			}
+			for nestedKey := range nested {
+				fields[key+"."+nestedKey] = struct{}{}
+			}
+		}
+	}
+	for _, identity := range []string{"apiVersion", "kind", "metadata.name", "metadata.namespace", "metadata.resourceVersion", "metadata.uid"} {
+		delete(fields, identity)
+	}
+
+	sorted := make([]string, 0, len(fields))
+	for field := range fields {
+		sorted = append(sorted, field)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// ensure auditSubResourceClient implements client.SubResourceClient.
+var _ SubResourceClient = &auditSubResourceClient{}
+
+// auditSubResourceClient is a client.SubResourceClient that audits its mutations.
+type auditSubResourceClient struct {
+	client      SubResourceClient
+	subResource string
+	parent      *auditClient
+}
+
+// Get implements client.SubResourceClient.
+func (sw *auditSubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	return sw.client.Get(ctx, obj, subResource, opts...)
+}
+
+// Create implements client.SubResourceWriter.
+func (sw *auditSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	err := sw.client.Create(ctx, obj, subResource, opts...)
+	sw.parent.audit(ctx, "create", sw.subResource, obj, nil, err)
+	return err
+}
+
+// Update implements client.SubResourceWriter.
+func (sw *auditSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	fromResourceVersion := obj.GetResourceVersion()
+	err := sw.client.Update(ctx, obj, opts...)
+	sw.parent.audit(ctx, "update", sw.subResource, obj, updateSummary(fromResourceVersion, obj, err), err)
+	return err
+}
+
+// Patch implements client.SubResourceWriter.
+func (sw *auditSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	summary := patchSummary(patch, obj)
+	err := sw.client.Patch(ctx, obj, patch, opts...)
+	sw.parent.audit(ctx, "patch", sw.subResource, obj, summary, err)
+	return err
+}
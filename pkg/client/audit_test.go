@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("WithAudit", func() {
+	var (
+		ctx      = context.Background()
+		lines    []string
+		recorder *record.FakeRecorder
+		cl       client.Client
+		cm       *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		lines = nil
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{})
+		recorder = record.NewFakeRecorder(10)
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+		cl = client.WithAudit(fake.NewClientBuilder().WithObjects(cm.DeepCopy()).Build(), client.AuditOptions{
+			Logger:        &logger,
+			EventRecorder: recorder,
+		})
+	})
+
+	It("should log the changed fields of patches", func() {
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		patch := client.MergeFrom(cm.DeepCopy())
+		cm.Data = map[string]string{"key": "value"}
+		cm.Labels = map[string]string{"label": "value"}
+
+		Expect(cl.Patch(client.WithControllerName(ctx, "my-controller"), cm, patch)).To(Succeed())
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(ContainSubstring(`"verb"="patch"`))
+		Expect(lines[0]).To(ContainSubstring(`"kind"="ConfigMap"`))
+		Expect(lines[0]).To(ContainSubstring(`"namespace"="default" "name"="cm"`))
+		Expect(lines[0]).To(ContainSubstring(`"outcome"="success"`))
+		Expect(lines[0]).To(ContainSubstring(`"controller"="my-controller"`))
+		Expect(lines[0]).To(ContainSubstring(`"fields"=["data.key" "metadata.labels"]`))
+		Expect(recorder.Events).To(Receive(Equal("Normal Mutated Succeeded to patch ConfigMap")))
+	})
+
+	It("should log the resource versions of updates", func() {
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		from := cm.ResourceVersion
+		Expect(cl.Update(ctx, cm)).To(Succeed())
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(ContainSubstring(`"fromResourceVersion"="` + from + `"`))
+		Expect(lines[0]).To(ContainSubstring(`"toResourceVersion"="` + cm.ResourceVersion + `"`))
+	})
+
+	It("should log failed mutations", func() {
+		Expect(cl.Create(ctx, cm.DeepCopy())).NotTo(Succeed())
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(ContainSubstring(`"verb"="create"`))
+		Expect(lines[0]).To(ContainSubstring(`"outcome"="error"`))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning MutationFailed Failed to create ConfigMap")))
+	})
+
+	It("should not log reads", func() {
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		Expect(cl.List(ctx, &corev1.ConfigMapList{})).To(Succeed())
+		Expect(lines).To(BeEmpty())
+	})
+})
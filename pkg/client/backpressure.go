@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	defaultBackpressureThreshold       = 5
+	defaultBackpressureMinOpenDuration = time.Second
+)
+
+// throttledRequests counts the requests rejected by the API server with 429 Too Many
+// Requests, e.g. by API Priority and Fairness.
+var throttledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "controller_runtime_client_throttled_requests_total",
+	Help: "Total number of requests throttled by the API server with 429 Too Many Requests per verb, group, version and resource",
+}, []string{"verb", "group", "version", "resource"})
+
+func init() {
+	metrics.Registry.MustRegister(throttledRequests)
+}
+
+// BackpressureOptions are the options of a Backpressure.
+type BackpressureOptions struct {
+	// Threshold is the number of consecutive throttled requests that opens the circuit
+	// breaker. Defaults to 5.
+	Threshold int
+
+	// MinOpenDuration is the minimum duration the circuit breaker stays open, used if
+	// the API server doesn't send a longer Retry-After delay. Defaults to 1s.
+	MinOpenDuration time.Duration
+}
+
+// Backpressure observes the requests throttled by the API server with 429 Too Many
+// Requests, e.g. by API Priority and Fairness, for the clients created with it in
+// Options.Backpressure. Throttled requests are counted by the
+// controller_runtime_client_throttled_requests_total metric, and retried by the REST
+// client with the delay of the Retry-After header sent by the API server.
+//
+// Backpressure is a circuit breaker, that opens once Threshold requests in a row were
+// throttled and stays open for the Retry-After delay. Controllers can consult it to slow
+// down while the API server is overloaded, instead of adding to its load:
+//
+//	if bp.Open() {
+//		return reconcile.Result{RequeueAfter: bp.Delay()}, nil
+//	}
+//
+// A Backpressure can be shared by several clients. It is safe for concurrent use.
+type Backpressure struct {
+	threshold       int
+	minOpenDuration time.Duration
+
+	mu          sync.Mutex
+	consecutive int
+	openUntil   time.Time
+}
+
+// NewBackpressure returns a new Backpressure with a closed circuit breaker.
+func NewBackpressure(opts BackpressureOptions) *Backpressure {
+	if opts.Threshold <= 0 {
+		opts.Threshold = defaultBackpressureThreshold
+	}
+	if opts.MinOpenDuration <= 0 {
+		opts.MinOpenDuration = defaultBackpressureMinOpenDuration
+	}
+	return &Backpressure{threshold: opts.Threshold, minOpenDuration: opts.MinOpenDuration}
+}
+
+// Open returns whether the circuit breaker is open, i.e. the API server has recently
+// been throttling the requests of the clients.
+func (b *Backpressure) Open() bool {
+	return b.Delay() > 0
+}
+
+// Delay returns the time until the circuit breaker closes, or zero if it is closed.
+func (b *Backpressure) Delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if delay := time.Until(b.openUntil); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// observe records the response to a request.
+func (b *Backpressure) observe(resp *http.Response) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		b.consecutive = 0
+		return
+	}
+	b.consecutive++
+	if b.consecutive < b.threshold {
+		return
+	}
+	openDuration := b.minOpenDuration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second > openDuration {
+		openDuration = time.Duration(seconds) * time.Second
+	}
+	if openUntil := time.Now().Add(openDuration); openUntil.After(b.openUntil) {
+		b.openUntil = openUntil
+	}
+}
+
+// withBackpressureTransport returns a copy of httpClient whose transport records the
+// throttled requests with b.
+func withBackpressureTransport(httpClient *http.Client, b *Backpressure) *http.Client {
+	delegate := httpClient.Transport
+	if delegate == nil {
+		delegate = http.DefaultTransport
+	}
+	observed := *httpClient
+	observed.Transport = &backpressureRoundTripper{delegate: delegate, backpressure: b}
+	return &observed
+}
+
+// backpressureRoundTripper records the responses of requests with a Backpressure.
+type backpressureRoundTripper struct {
+	delegate     http.RoundTripper
+	backpressure *Backpressure
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *backpressureRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	rt.backpressure.observe(resp)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		verb, group, version, resource := describeRequest(req)
+		throttledRequests.WithLabelValues(verb, group, version, resource).Inc()
+	}
+	return resp, nil
+}
+
+// WrappedRoundTripper returns the round tripper wrapped by rt.
+func (rt *backpressureRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
+
+// describeRequest returns the verb and the resource of a request to the API server,
+// e.g. list and the group, version and resource of /apis/apps/v1/namespaces/default/deployments.
+func describeRequest(req *http.Request) (verb, group, version, resource string) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		version, parts = parts[1], parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		group, version, parts = parts[1], parts[2], parts[3:]
+	default:
+		return strings.ToLower(req.Method), "", "", ""
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	var named bool
+	if len(parts) > 0 {
+		resource, named = parts[0], len(parts) > 1
+	}
+	if len(parts) > 2 {
+		resource += "/" + parts[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		switch {
+		case req.URL.Query().Get("watch") == "true":
+			verb = "watch"
+		case named:
+			verb = "get"
+		default:
+			verb = "list"
+		}
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+		if !named {
+			verb = "deletecollection"
+		}
+	default:
+		verb = strings.ToLower(req.Method)
+	}
+	return verb, group, version, resource
+}
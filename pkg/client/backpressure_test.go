@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("Backpressure", func() {
+	var (
+		requests  atomic.Int32
+		throttled atomic.Bool
+		cl        client.Client
+		bp        *client.Backpressure
+	)
+
+	throttledRequests := func(verb, resource string) float64 {
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "controller_runtime_client_throttled_requests_total" {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				labels := map[string]string{}
+				for _, label := range m.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["verb"] == verb && labels["group"] == "" && labels["version"] == "v1" && labels["resource"] == resource {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	BeforeEach(func() {
+		requests.Store(0)
+		throttled.Store(true)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			if throttled.Load() {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"TooManyRequests","code":429}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"namespace":"default","name":"cm"}}`))
+		}))
+		DeferCleanup(server.Close)
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		bp = client.NewBackpressure(client.BackpressureOptions{Threshold: 3, MinOpenDuration: time.Minute})
+		var err error
+		cl, err = client.New(&rest.Config{Host: server.URL}, client.Options{Mapper: mapper, Backpressure: bp})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should open the circuit breaker once requests are throttled repeatedly", func() {
+		before := throttledRequests("get", "configmaps")
+		Expect(bp.Open()).To(BeFalse())
+
+		By("retrying the throttled requests")
+		err := cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cm"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+		Expect(requests.Load()).To(BeNumerically(">", 1))
+
+		Expect(bp.Open()).To(BeTrue())
+		Expect(bp.Delay()).To(BeNumerically(">", 50*time.Second))
+		Expect(throttledRequests("get", "configmaps") - before).To(BeEquivalentTo(requests.Load()))
+	})
+
+	It("should not open the circuit breaker for requests that are not throttled", func() {
+		throttled.Store(false)
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cm"}, &corev1.ConfigMap{})).To(Succeed())
+		Expect(bp.Open()).To(BeFalse())
+	})
+})
@@ -56,8 +56,35 @@ type Options struct {
 
 	// DryRun instructs the client to only perform dry run requests.
 	DryRun *bool
+
+	// ConvertToServedVersion instructs the client to convert typed objects of
+	// a version the cluster does not serve to a served version before sending
+	// them. See NewServedVersionClient for details.
+	ConvertToServedVersion bool
+
+	// FieldOwnerFromController defaults the field owner of creates, updates and patches
+	// made by controllers to the name of the controller. See WithFieldOwnerFromContext.
+	FieldOwnerFromController bool
+
+	// Audit, if set, makes the client leave an audit trail of the changes it makes.
+	// See WithAudit.
+	Audit *AuditOptions
+
+	// Interceptors wrap the client, e.g. to log, audit or rewrite requests, or to scope
+	// them to a tenant. The first interceptor is the outermost one, so it sees every
+	// request first. They wrap the client after DryRun, ConvertToServedVersion,
+	// FieldOwnerFromController and Audit are applied. See interceptor.FromFuncs to
+	// only intercept some of the methods.
+	Interceptors []Interceptor
+
+	// Backpressure, if set, observes the requests throttled by the API server and
+	// opens its circuit breaker when the API server is overloaded. See Backpressure.
+	Backpressure *Backpressure
 }
 
+// Interceptor wraps a client in another client that intercepts its requests.
+type Interceptor func(Client) Client
+
 // WarningHandlerOptions are options for configuring a
 // warning handler for the client which is responsible
 // for surfacing API Server warnings.
@@ -97,7 +124,8 @@ type NewClientFunc func(config *rest.Config, options Options) (Client, error)
 // If both Options.Cache and Options.Cache.Reader are non-nil,
 // the client reads from a local cache. However, specific
 // resources can still be configured to bypass the cache based
-// on Options.Cache.Unstructured and Options.Cache.DisableFor.
+// on Options.Cache.Unstructured and Options.Cache.DisableFor, and single
+// reads can bypass it with the BypassCache option.
 // Write operations are always performed directly on the API server.
 //
 // The client understands how to work with normal types (both custom resources
@@ -108,9 +136,23 @@ type NewClientFunc func(config *rest.Config, options Options) (Client, error)
 // from the corresponding fields on the object.
 func New(config *rest.Config, options Options) (c Client, err error) {
 	c, err = newClient(config, options)
+	if err == nil && options.ConvertToServedVersion {
+		c = NewServedVersionClient(c)
+	}
 	if err == nil && options.DryRun != nil && *options.DryRun {
 		c = NewDryRunClient(c)
 	}
+	if err == nil && options.FieldOwnerFromController {
+		c = WithFieldOwnerFromContext(c)
+	}
+	if err == nil && options.Audit != nil {
+		c = WithAudit(c, *options.Audit)
+	}
+	if err == nil {
+		for i := len(options.Interceptors) - 1; i >= 0; i-- {
+			c = options.Interceptors[i](c)
+		}
+	}
 	return c, err
 }
 
@@ -162,8 +204,14 @@ func newClient(config *rest.Config, options Options) (*client, error) {
 		}
 	}
 
+	// Impersonate the users set with WithImpersonation on the contexts of the requests.
+	httpClient := withImpersonationTransport(options.HTTPClient)
+	if options.Backpressure != nil {
+		httpClient = withBackpressureTransport(httpClient, options.Backpressure)
+	}
+
 	resources := &clientRestResources{
-		httpClient: options.HTTPClient,
+		httpClient: httpClient,
 		config:     config,
 		scheme:     options.Scheme,
 		mapper:     options.Mapper,
@@ -173,7 +221,7 @@ func newClient(config *rest.Config, options Options) (*client, error) {
 		unstructuredResourceByType: make(map[schema.GroupVersionKind]*resourceMeta),
 	}
 
-	rawMetaClient, err := metadata.NewForConfigAndClient(metadata.ConfigFor(config), options.HTTPClient)
+	rawMetaClient, err := metadata.NewForConfigAndClient(metadata.ConfigFor(config), httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("unable to construct metadata-only client for use as part of client: %w", err)
 	}
@@ -232,10 +280,14 @@ type client struct {
 	cacheUnstructured bool
 }
 
-func (c *client) shouldBypassCache(obj runtime.Object) (bool, error) {
+func (c *client) shouldBypassCache(ctx context.Context, obj runtime.Object) (bool, error) {
 	if c.cache == nil {
 		return true, nil
 	}
+	// The cache isn't authorized per user, read impersonated requests from the API server.
+	if _, impersonated := impersonationFrom(ctx); impersonated {
+		return true, nil
+	}
 
 	gvk, err := c.GroupVersionKindFor(obj)
 	if err != nil {
@@ -349,9 +401,9 @@ func (c *client) Patch(ctx context.Context, obj Object, patch Patch, opts ...Pat
 
 // Get implements client.Client.
 func (c *client) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
-	if isUncached, err := c.shouldBypassCache(obj); err != nil {
+	if isUncached, err := c.shouldBypassCache(ctx, obj); err != nil {
 		return err
-	} else if !isUncached {
+	} else if !isUncached && !(&GetOptions{}).ApplyOptions(opts).BypassCache {
 		// Attempt to get from the cache.
 		return c.cache.Get(ctx, key, obj, opts...)
 	}
@@ -371,9 +423,9 @@ func (c *client) Get(ctx context.Context, key ObjectKey, obj Object, opts ...Get
 
 // List implements client.Client.
 func (c *client) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
-	if isUncached, err := c.shouldBypassCache(obj); err != nil {
+	if isUncached, err := c.shouldBypassCache(ctx, obj); err != nil {
 		return err
-	} else if !isUncached {
+	} else if !isUncached && !(&ListOptions{}).ApplyOptions(opts).BypassCache {
 		// Attempt to get from the cache.
 		return c.cache.List(ctx, obj, opts...)
 	}
@@ -300,6 +300,16 @@ U5wwSivyi7vmegHKmblOzNVKA5qPO8zWzqBC
 			Expect(cl.List(ctx, &corev1.NamespaceList{})).To(Succeed())
 			Expect(cache.Called).To(Equal(0))
 		})
+
+		It("should not use the provided reader cache, on get and list with the BypassCache option", func() {
+			cache := &fakeReader{}
+			cl, err := client.New(cfg, client.Options{Cache: &client.CacheOptions{Reader: cache}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cl).NotTo(BeNil())
+			Expect(cl.Get(ctx, client.ObjectKey{Name: "default"}, &corev1.Namespace{}, client.BypassCache)).To(Succeed())
+			Expect(cl.List(ctx, &corev1.NamespaceList{}, client.BypassCache)).To(Succeed())
+			Expect(cache.Called).To(Equal(0))
+		})
 	})
 
 	Describe("Create", func() {
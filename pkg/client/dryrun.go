@@ -25,7 +25,11 @@ import (
 )
 
 // NewDryRunClient wraps an existing client and enforces DryRun mode
-// on all mutating api calls.
+// on all mutating api calls. The API server validates and admits the
+// requests and returns the objects as they would have been persisted,
+// without persisting them, so reconcilers can be run end-to-end to preview
+// their changes. Use Options.DryRun to enforce it for all the clients of a
+// manager. The options passed by callers are never modified.
 func NewDryRunClient(c Client) Client {
 	return &dryRunClient{client: c}
 }
@@ -59,27 +63,27 @@ func (c *dryRunClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
 
 // Create implements client.Client.
 func (c *dryRunClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
-	return c.client.Create(ctx, obj, append(opts, DryRunAll)...)
+	return c.client.Create(ctx, obj, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Update implements client.Client.
 func (c *dryRunClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
-	return c.client.Update(ctx, obj, append(opts, DryRunAll)...)
+	return c.client.Update(ctx, obj, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Delete implements client.Client.
 func (c *dryRunClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
-	return c.client.Delete(ctx, obj, append(opts, DryRunAll)...)
+	return c.client.Delete(ctx, obj, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // DeleteAllOf implements client.Client.
 func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
-	return c.client.DeleteAllOf(ctx, obj, append(opts, DryRunAll)...)
+	return c.client.DeleteAllOf(ctx, obj, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Patch implements client.Client.
 func (c *dryRunClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
-	return c.client.Patch(ctx, obj, patch, append(opts, DryRunAll)...)
+	return c.client.Patch(ctx, obj, patch, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Get implements client.Client.
@@ -116,15 +120,15 @@ func (sw *dryRunSubResourceClient) Get(ctx context.Context, obj, subResource Obj
 }
 
 func (sw *dryRunSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
-	return sw.client.Create(ctx, obj, subResource, append(opts, DryRunAll)...)
+	return sw.client.Create(ctx, obj, subResource, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Update implements client.SubResourceWriter.
 func (sw *dryRunSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
-	return sw.client.Update(ctx, obj, append(opts, DryRunAll)...)
+	return sw.client.Update(ctx, obj, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
 
 // Patch implements client.SubResourceWriter.
 func (sw *dryRunSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
-	return sw.client.Patch(ctx, obj, patch, append(opts, DryRunAll)...)
+	return sw.client.Patch(ctx, obj, patch, append(opts[:len(opts):len(opts)], DryRunAll)...)
 }
@@ -31,6 +31,7 @@ import (
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("DryRunClient", func() {
@@ -263,3 +264,19 @@ var _ = Describe("DryRunClient", func() {
 		Expect(actual).To(BeEquivalentTo(dep))
 	})
 })
+
+var _ = Describe("DryRunClient options", func() {
+	It("should not modify the options passed by the caller", func() {
+		cl := client.NewDryRunClient(fake.NewClientBuilder().Build())
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+
+		opts := make([]client.CreateOption, 1, 2)
+		opts[0] = client.FieldOwner("owner")
+		Expect(cl.Create(context.Background(), cm, opts...)).To(Succeed())
+		Expect(opts[:2][1]).To(BeNil())
+
+		By("not persisting the object")
+		err := cl.Get(context.Background(), client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -203,6 +203,27 @@ func ExampleClient_apply() {
 	_ = c.Patch(context.Background(), u, client.Apply, client.ForceOwnership, client.FieldOwner("field-owner"))
 }
 
+// This example shows how to use ServerSideApply to create/patch objects using Server Side Apply with typed apply
+// configurations, and ExtractApplyConfiguration to modify the fields owned by a field manager.
+func ExampleServerSideApply() {
+	configMap := corev1ac.ConfigMap("name", "namespace").WithData(map[string]string{"key": "value"})
+	// c is a created client.
+	applied, err := client.ServerSideApply(context.Background(), c, configMap, client.ForceOwnership, client.FieldOwner("field-owner"))
+	if err != nil {
+		fmt.Printf("failed to apply config map: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Remove the key from the fields owned by field-owner, which removes it from the config map.
+	ac, err := client.ExtractApplyConfiguration(applied, "field-owner", c.Scheme())
+	if err != nil {
+		fmt.Printf("failed to extract apply configuration: %v\n", err)
+		os.Exit(1)
+	}
+	unstructured.RemoveNestedField(ac.Object, "data", "key")
+	_, _ = client.ServerSideApply(context.Background(), c, ac, client.ForceOwnership, client.FieldOwner("field-owner"))
+}
+
 // This example shows how to use the client with typed and unstructured objects to patch objects' status.
 func ExampleClient_patchStatus() {
 	u := &unstructured.Unstructured{}
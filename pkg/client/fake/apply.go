@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultFieldManager is the field manager of the requests without one to objects whose
+// fields are managed. The API server names it after the user agent of the client instead.
+const defaultFieldManager = "fake-client"
+
+// apply applies the apply patch data to the object of obj with server-side apply, or
+// creates it if it doesn't exist yet, and returns the resulting object.
+func (c *fakeClient) apply(gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, obj client.Object, data []byte, isStatus bool, patchOptions *client.PatchOptions) (runtime.Object, error) {
+	if patchOptions.FieldManager == "" {
+		return nil, apierrors.NewBadRequest("PatchOptions.fieldManager is required for apply requests")
+	}
+	applied := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &applied.Object); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("error decoding apply patch: %v", err))
+	}
+	if applied.GetAPIVersion() == "" || applied.GetKind() == "" {
+		return nil, apierrors.NewBadRequest("apiVersion and kind must be set in apply patches")
+	}
+
+	fieldManager, err := c.newFieldManager(gvk, isStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := c.tracker.Get(gvr, obj.GetNamespace(), obj.GetName())
+	create := apierrors.IsNotFound(err) && !isStatus
+	switch {
+	case create:
+		if live, err = (fieldManagerScheme{c.scheme}).New(gvk); err != nil {
+			return nil, err
+		}
+		accessor, err := meta.Accessor(live)
+		if err != nil {
+			return nil, err
+		}
+		accessor.SetNamespace(obj.GetNamespace())
+		accessor.SetName(obj.GetName())
+	case err != nil:
+		return nil, err
+	}
+	live.GetObjectKind().SetGroupVersionKind(gvk)
+
+	force := patchOptions.Force != nil && *patchOptions.Force
+	merged, err := fieldManager.Apply(live, applied, patchOptions.FieldManager, force)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		return merged, c.tracker.Create(gvr, merged, obj.GetNamespace())
+	}
+	return merged, c.tracker.update(gvr, merged, obj.GetNamespace(), isStatus, false)
+}
+
+// updateManagedFields records the fields changed by a create, update or patch from live
+// to obj in the managed fields of obj, as managed by fieldManager. The managed fields are
+// only tracked for requests with a field manager and for objects whose fields are
+// already managed, e.g. by server-side apply, so that the objects of the tests not
+// concerned with them remain unchanged.
+func (c *fakeClient) updateManagedFields(gvk schema.GroupVersionKind, isStatus bool, live, obj runtime.Object, fieldManager string) error {
+	liveAccessor, err := meta.Accessor(live)
+	if err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if fieldManager == "" {
+		if len(liveAccessor.GetManagedFields()) == 0 {
+			if isStatus {
+				// The managed fields can't be changed through subresources.
+				accessor.SetManagedFields(nil)
+			}
+			return nil
+		}
+		fieldManager = defaultFieldManager
+	}
+
+	fm, err := c.newFieldManager(gvk, isStatus)
+	if err != nil {
+		return err
+	}
+	live = live.DeepCopyObject()
+	live.GetObjectKind().SetGroupVersionKind(gvk)
+	updated, err := fm.Update(live, obj.DeepCopyObject(), fieldManager)
+	if err != nil {
+		return err
+	}
+	updatedAccessor, err := meta.Accessor(updated)
+	if err != nil {
+		return err
+	}
+	accessor.SetManagedFields(updatedAccessor.GetManagedFields())
+	return nil
+}
+
+// newFieldManager returns the field manager of the objects of kind gvk, or of their status
+// subresource if isStatus is set. The fields of the status are reset by requests to
+// objects with a status subresource, and the other fields by requests to their status.
+func (c *fakeClient) newFieldManager(gvk schema.GroupVersionKind, isStatus bool) (*managedfields.FieldManager, error) {
+	var subresource string
+	var resetFields map[fieldpath.APIVersion]*fieldpath.Set
+	if c.withStatusSubresource.Has(gvk) {
+		reset := fieldpath.NewSet(fieldpath.MakePathOrDie("status"))
+		if isStatus {
+			subresource = "status"
+			reset = fieldpath.NewSet(fieldpath.MakePathOrDie("spec"), fieldpath.MakePathOrDie("metadata"))
+		}
+		resetFields = map[fieldpath.APIVersion]*fieldpath.Set{fieldpath.APIVersion(gvk.GroupVersion().String()): reset}
+	}
+
+	s := fieldManagerScheme{c.scheme}
+	return managedfields.NewDefaultFieldManager(c.typeConverter, s, s, s, gvk, gvk.GroupVersion(), subresource, resetFields)
+}
+
+// fieldManagerScheme is the scheme of the fake client as seen by field managers, which
+// also creates and converts unstructured objects of kinds the scheme doesn't recognize.
+// The fake client only serves one version of every kind, so they are never converted
+// to another version.
+type fieldManagerScheme struct {
+	*runtime.Scheme
+}
+
+// New implements runtime.ObjectCreater.
+func (s fieldManagerScheme) New(gvk schema.GroupVersionKind) (runtime.Object, error) {
+	if s.Recognizes(gvk) {
+		return s.Scheme.New(gvk)
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+// ConvertToVersion implements runtime.ObjectConvertor.
+func (s fieldManagerScheme) ConvertToVersion(in runtime.Object, target runtime.GroupVersioner) (runtime.Object, error) {
+	if _, isUnstructured := in.(runtime.Unstructured); isUnstructured && !s.Recognizes(in.GetObjectKind().GroupVersionKind()) {
+		return in, nil
+	}
+	return s.Scheme.ConvertToVersion(in, target)
+}
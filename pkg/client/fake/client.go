@@ -17,13 +17,12 @@ limitations under the License.
 package fake
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
-	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,9 +30,7 @@ import (
 
 	// Using v4 to match upstream
 	jsonpatch "github.com/evanphx/json-patch"
-	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
-	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,7 +39,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/managedfields"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
@@ -54,14 +53,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
-	"sigs.k8s.io/controller-runtime/pkg/internal/field/selector"
 	"sigs.k8s.io/controller-runtime/pkg/internal/objectutil"
 )
 
 type versionedTracker struct {
 	testing.ObjectTracker
-	scheme                *runtime.Scheme
-	withStatusSubresource sets.Set[schema.GroupVersionKind]
+	scheme                 *runtime.Scheme
+	withStatusSubresource  sets.Set[schema.GroupVersionKind]
+	strictResourceVersions bool
 }
 
 type fakeClient struct {
@@ -69,6 +68,9 @@ type fakeClient struct {
 	scheme                *runtime.Scheme
 	restMapper            meta.RESTMapper
 	withStatusSubresource sets.Set[schema.GroupVersionKind]
+	typeConverter         managedfields.TypeConverter
+	watchEventInjector    *WatchEventInjector
+	podLogs               PodLogsFunc
 
 	// indexes maps each GroupVersionKind (GVK) to the indexes registered for that GVK.
 	// The inner map maps from index name to IndexerFunc.
@@ -106,6 +108,11 @@ type ClientBuilder struct {
 	withStatusSubresource []client.Object
 	objectTracker         testing.ObjectTracker
 	interceptorFuncs      *interceptor.Funcs
+	typeConverter         managedfields.TypeConverter
+	watchEventInjector    *WatchEventInjector
+	podLogs               PodLogsFunc
+
+	strictResourceVersions bool
 
 	// indexes maps each GroupVersionKind (GVK) to the indexes registered for that GVK.
 	// The inner map maps from index name to IndexerFunc.
@@ -158,6 +165,9 @@ func (f *ClientBuilder) WithObjectTracker(ot testing.ObjectTracker) *ClientBuild
 // Invoking WithIndex twice with the same `field` and GVK (via `obj`) arguments will panic.
 // WithIndex retrieves the GVK of `obj` using the scheme registered via WithScheme if
 // WithScheme was previously invoked, the default scheme otherwise.
+// Field selectors of List requests are served by the index registered for the field, if
+// any, and otherwise by the fields the API server can select objects by, e.g. metadata.name
+// or the spec.nodeName of Pods.
 func (f *ClientBuilder) WithIndex(obj runtime.Object, field string, extractValue client.IndexerFunc) *ClientBuilder {
 	objScheme := f.scheme
 	if objScheme == nil {
@@ -203,6 +213,45 @@ func (f *ClientBuilder) WithInterceptorFuncs(interceptorFuncs interceptor.Funcs)
 	return f
 }
 
+// WithTypeConverter sets the type converter used to merge apply patches and to track the
+// managed fields of objects. If not set, defaults to managedfields.NewDeducedTypeConverter(),
+// which merges maps and structs field by field, but replaces lists as a whole. Type
+// converters built from the OpenAPI schemas of the types, see managedfields.NewTypeConverter,
+// also merge lists like the API server.
+func (f *ClientBuilder) WithTypeConverter(typeConverter managedfields.TypeConverter) *ClientBuilder {
+	f.typeConverter = typeConverter
+	return f
+}
+
+// WithStrictResourceVersions makes the fake client track the generation of objects like
+// the API server, so that the code paths relying on it can be tested: the generation of
+// objects is set to 1 on creation, and incremented by changes of anything but their
+// metadata and, for objects with a status subresource, their status, as well as by their
+// deletion when they have finalizers. Like without this option, updates with a stale
+// resourceVersion, updates without one of the kinds the API server doesn't update
+// unconditionally and creates of existing objects are rejected with a Conflict and an
+// AlreadyExists error, and the UID and resourceVersion preconditions of deletions are
+// checked.
+func (f *ClientBuilder) WithStrictResourceVersions() *ClientBuilder {
+	f.strictResourceVersions = true
+	return f
+}
+
+// WithWatchEventInjector makes the watches established with the fake client receive the
+// events injected with injector, so that the code consuming watches can be tested with
+// bookmarks and errors. An injector is meant to be passed to a single fake client.
+func (f *ClientBuilder) WithWatchEventInjector(injector *WatchEventInjector) *ClientBuilder {
+	f.watchEventInjector = injector
+	return f
+}
+
+// WithPodLogs sets the function returning the logs of the containers of pods streamed by
+// the client, see PodLogStreamer. If not set, all containers log "fake logs".
+func (f *ClientBuilder) WithPodLogs(podLogs PodLogsFunc) *ClientBuilder {
+	f.podLogs = podLogs
+	return f
+}
+
 // Build builds and returns a new fake client.
 func (f *ClientBuilder) Build() client.WithWatch {
 	if f.scheme == nil {
@@ -211,6 +260,9 @@ func (f *ClientBuilder) Build() client.WithWatch {
 	if f.restMapper == nil {
 		f.restMapper = meta.NewDefaultRESTMapper([]schema.GroupVersion{})
 	}
+	if f.typeConverter == nil {
+		f.typeConverter = managedfields.NewDeducedTypeConverter()
+	}
 
 	var tracker versionedTracker
 
@@ -224,9 +276,9 @@ func (f *ClientBuilder) Build() client.WithWatch {
 	}
 
 	if f.objectTracker == nil {
-		tracker = versionedTracker{ObjectTracker: testing.NewObjectTracker(f.scheme, scheme.Codecs.UniversalDecoder()), scheme: f.scheme, withStatusSubresource: withStatusSubResource}
+		tracker = versionedTracker{ObjectTracker: testing.NewObjectTracker(f.scheme, scheme.Codecs.UniversalDecoder()), scheme: f.scheme, withStatusSubresource: withStatusSubResource, strictResourceVersions: f.strictResourceVersions}
 	} else {
-		tracker = versionedTracker{ObjectTracker: f.objectTracker, scheme: f.scheme, withStatusSubresource: withStatusSubResource}
+		tracker = versionedTracker{ObjectTracker: f.objectTracker, scheme: f.scheme, withStatusSubresource: withStatusSubResource, strictResourceVersions: f.strictResourceVersions}
 	}
 
 	for _, obj := range f.initObject {
@@ -245,19 +297,27 @@ func (f *ClientBuilder) Build() client.WithWatch {
 		}
 	}
 
-	var result client.WithWatch = &fakeClient{
+	c := &fakeClient{
 		tracker:               tracker,
 		scheme:                f.scheme,
 		restMapper:            f.restMapper,
 		indexes:               f.indexes,
 		withStatusSubresource: withStatusSubResource,
+		typeConverter:         f.typeConverter,
+		watchEventInjector:    f.watchEventInjector,
+		podLogs:               f.podLogs,
+	}
+	if f.watchEventInjector != nil {
+		f.watchEventInjector.mu.Lock()
+		f.watchEventInjector.scheme = f.scheme
+		f.watchEventInjector.mu.Unlock()
 	}
 
 	if f.interceptorFuncs != nil {
-		result = interceptor.NewClient(result, *f.interceptorFuncs)
+		return &interceptedClient{WithWatch: interceptor.NewClient(c, *f.interceptorFuncs), PodLogStreamer: c}
 	}
 
-	return result
+	return c
 }
 
 const trackerAddResourceVersion = "999"
@@ -316,6 +376,9 @@ func (t versionedTracker) Create(gvr schema.GroupVersionResource, obj runtime.Ob
 		return apierrors.NewBadRequest("resourceVersion can not be set for Create requests")
 	}
 	accessor.SetResourceVersion("1")
+	if t.strictResourceVersions {
+		accessor.SetGeneration(1)
+	}
 	obj, err = convertFromUnstructuredIfNecessary(t.scheme, obj)
 	if err != nil {
 		return err
@@ -360,13 +423,7 @@ func convertFromUnstructuredIfNecessary(s *runtime.Scheme, o runtime.Object) (ru
 }
 
 func (t versionedTracker) Update(gvr schema.GroupVersionResource, obj runtime.Object, ns string) error {
-	isStatus := false
-	// We apply patches using a client-go reaction that ends up calling the trackers Update. As we can't change
-	// that reaction, we use the callstack to figure out if this originated from the status client.
-	if bytes.Contains(debug.Stack(), []byte("sigs.k8s.io/controller-runtime/pkg/client/fake.(*fakeSubResourceClient).statusPatch")) {
-		isStatus = true
-	}
-	return t.update(gvr, obj, ns, isStatus, false)
+	return t.update(gvr, obj, ns, false, false)
 }
 
 func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Object, ns string, isStatus bool, deleting bool) error {
@@ -403,10 +460,12 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 				return fmt.Errorf("failed to copy non-status field for object with status subresouce: %w", err)
 			}
 			passedRV := accessor.GetResourceVersion()
+			passedManagedFields := accessor.GetManagedFields()
 			if err := copyFrom(oldObject, obj); err != nil {
 				return fmt.Errorf("failed to restore non-status fields: %w", err)
 			}
 			accessor.SetResourceVersion(passedRV)
+			accessor.SetManagedFields(passedManagedFields)
 		} else { // copy status from original object
 			if err := copyStatusFrom(oldObject, obj); err != nil {
 				return fmt.Errorf("failed to copy the status for object with status subresource: %w", err)
@@ -443,6 +502,17 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 		return fmt.Errorf("error: Unable to edit %s: metadata.deletionTimestamp field is immutable", accessor.GetName())
 	}
 
+	if t.strictResourceVersions {
+		accessor.SetGeneration(oldAccessor.GetGeneration())
+		changed, err := t.generationChanged(gvk, oldObject, obj)
+		if err != nil {
+			return err
+		}
+		if changed || deleting {
+			accessor.SetGeneration(oldAccessor.GetGeneration() + 1)
+		}
+	}
+
 	if !accessor.GetDeletionTimestamp().IsZero() && len(accessor.GetFinalizers()) == 0 {
 		return t.ObjectTracker.Delete(gvr, accessor.GetNamespace(), accessor.GetName())
 	}
@@ -453,6 +523,34 @@ func (t versionedTracker) update(gvr schema.GroupVersionResource, obj runtime.Ob
 	return t.ObjectTracker.Update(gvr, obj, ns)
 }
 
+// generationChanged returns whether the update of oldObject to obj changes the generation of
+// the object, i.e. changes anything but its metadata and, if it has a status subresource,
+// its status.
+func (t versionedTracker) generationChanged(gvk schema.GroupVersionKind, oldObject, obj runtime.Object) (bool, error) {
+	oldContent, err := toMapStringAny(oldObject)
+	if err != nil {
+		return false, err
+	}
+	content, err := toMapStringAny(obj)
+	if err != nil {
+		return false, err
+	}
+
+	ignored := []string{"apiVersion", "kind", "metadata"}
+	if t.withStatusSubresource.Has(gvk) {
+		ignored = append(ignored, "status")
+	}
+	for key := range sets.KeySet(oldContent).Union(sets.KeySet(content)) {
+		if slices.Contains(ignored, key) {
+			continue
+		}
+		if !apiequality.Semantic.DeepEqual(oldContent[key], content[key]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
 	gvr, err := getGVRFromObject(obj, c.scheme)
 	if err != nil {
@@ -485,18 +583,22 @@ func (c *fakeClient) Get(ctx context.Context, key client.ObjectKey, obj client.O
 }
 
 func (c *fakeClient) Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
-	gvk, err := apiutil.GVKForObject(list, c.scheme)
+	gvr, err := watchedResource(list, c.scheme)
 	if err != nil {
 		return nil, err
 	}
 
-	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
-
 	listOpts := client.ListOptions{}
 	listOpts.ApplyOptions(opts)
 
-	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
-	return c.tracker.Watch(gvr, listOpts.Namespace)
+	w, err := c.tracker.Watch(gvr, listOpts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if c.watchEventInjector != nil {
+		c.watchEventInjector.register(gvr, listOpts.Namespace, w)
+	}
+	return w, nil
 }
 
 func (c *fakeClient) List(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
@@ -587,31 +689,23 @@ func (c *fakeClient) filterList(list []runtime.Object, gvk schema.GroupVersionKi
 }
 
 func (c *fakeClient) filterWithFields(list []runtime.Object, gvk schema.GroupVersionKind, fs fields.Selector) ([]runtime.Object, error) {
-	requiresExact := selector.RequiresExactMatch(fs)
-	if !requiresExact {
-		return nil, fmt.Errorf("field selector %s is not in one of the two supported forms \"key==val\" or \"key=val\"",
-			fs)
-	}
-
-	// Field selection is mimicked via indexes, so there's no sane answer this function can give
-	// if there are no indexes registered for the GroupVersionKind of the objects in the list.
+	// Field selection is mimicked via indexes, or via the fields the API server can select
+	// objects by, e.g. metadata.name or the spec.nodeName of pods. Like the API server,
+	// selectors on other fields are rejected.
 	indexes := c.indexes[gvk]
 	for _, req := range fs.Requirements() {
-		if len(indexes) == 0 || indexes[req.Field] == nil {
-			return nil, fmt.Errorf("List on GroupVersionKind %v specifies selector on field %s, but no "+
-				"index with name %s has been registered for GroupVersionKind %v", gvk, req.Field, req.Field, gvk)
+		if indexes[req.Field] == nil && selectableField(gvk, req.Field) == nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("List on GroupVersionKind %v specifies selector on field %s, but no "+
+				"index with name %s has been registered for GroupVersionKind %v, and it is not a field the API server "+
+				"can select it by", gvk, req.Field, req.Field, gvk))
 		}
 	}
 
 	filteredList := make([]runtime.Object, 0, len(list))
 	for _, obj := range list {
-		matches := true
-		for _, req := range fs.Requirements() {
-			indexExtractor := indexes[req.Field]
-			if !c.objMatchesFieldSelector(obj, indexExtractor, req.Value) {
-				matches = false
-				break
-			}
+		matches, err := c.objMatchesFieldSelector(obj, gvk, fs)
+		if err != nil {
+			return nil, err
 		}
 		if matches {
 			filteredList = append(filteredList, obj)
@@ -620,19 +714,40 @@ func (c *fakeClient) filterWithFields(list []runtime.Object, gvk schema.GroupVer
 	return filteredList, nil
 }
 
-func (c *fakeClient) objMatchesFieldSelector(o runtime.Object, extractIndex client.IndexerFunc, val string) bool {
+func (c *fakeClient) objMatchesFieldSelector(o runtime.Object, gvk schema.GroupVersionKind, fs fields.Selector) (bool, error) {
 	obj, isClientObject := o.(client.Object)
 	if !isClientObject {
 		panic(fmt.Errorf("expected object %v to be of type client.Object, but it's not", o))
 	}
 
-	for _, extractedVal := range extractIndex(obj) {
-		if extractedVal == val {
-			return true
+	var content map[string]interface{}
+	for _, req := range fs.Requirements() {
+		var values []string
+		if extractIndex := c.indexes[gvk][req.Field]; extractIndex != nil {
+			values = extractIndex(obj)
+		} else {
+			if content == nil {
+				var err error
+				if content, err = unstructuredContent(obj); err != nil {
+					return false, err
+				}
+			}
+			values = []string{selectableField(gvk, req.Field)(content)}
+		}
+
+		found := false
+		for _, value := range values {
+			if value == req.Value {
+				found = true
+				break
+			}
+		}
+		if found == (req.Operator == selection.NotEquals) {
+			return false, nil
 		}
 	}
 
-	return false
+	return true, nil
 }
 
 func (c *fakeClient) Scheme() *runtime.Scheme {
@@ -672,6 +787,20 @@ func (c *fakeClient) Create(ctx context.Context, obj client.Object, opts ...clie
 		return err
 	}
 
+	if createOptions.FieldManager != "" {
+		gvk, err := apiutil.GVKForObject(obj, c.scheme)
+		if err != nil {
+			return err
+		}
+		empty, err := fieldManagerScheme{c.scheme}.New(gvk)
+		if err != nil {
+			return err
+		}
+		if err := c.updateManagedFields(gvk, false, empty, obj, createOptions.FieldManager); err != nil {
+			return err
+		}
+	}
+
 	if accessor.GetName() == "" && accessor.GetGenerateName() != "" {
 		base := accessor.GetGenerateName()
 		if len(base) > maxGeneratedNameLength {
@@ -705,6 +834,25 @@ func (c *fakeClient) Delete(ctx context.Context, obj client.Object, opts ...clie
 		}
 	}
 
+	// Check the UID if that Precondition was specified.
+	if delOptions.Preconditions != nil && delOptions.Preconditions.UID != nil {
+		dbObj, err := c.tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName())
+		if err != nil {
+			return err
+		}
+		oldAccessor, err := meta.Accessor(dbObj)
+		if err != nil {
+			return err
+		}
+		if actualUID, expectUID := oldAccessor.GetUID(), *delOptions.Preconditions.UID; actualUID != expectUID {
+			msg := fmt.Sprintf(
+				"the UID in the precondition (%s) does not match the UID in record (%s). "+
+					"The object might have been deleted and then recreated",
+				expectUID, actualUID)
+			return apierrors.NewConflict(gvr.GroupResource(), accessor.GetName(), errors.New(msg))
+		}
+	}
+
 	// Check the ResourceVersion if that Precondition was specified.
 	if delOptions.Preconditions != nil && delOptions.Preconditions.ResourceVersion != nil {
 		name := accessor.GetName()
@@ -794,14 +942,23 @@ func (c *fakeClient) update(obj client.Object, isStatus bool, opts ...client.Upd
 	if err != nil {
 		return err
 	}
+	if live, err := c.tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName()); err == nil {
+		gvk, err := apiutil.GVKForObject(obj, c.scheme)
+		if err != nil {
+			return err
+		}
+		if err := c.updateManagedFields(gvk, isStatus, live, obj, updateOptions.FieldManager); err != nil {
+			return err
+		}
+	}
 	return c.tracker.update(gvr, obj, accessor.GetNamespace(), isStatus, false)
 }
 
 func (c *fakeClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
-	return c.patch(obj, patch, opts...)
+	return c.patch(obj, patch, false, opts...)
 }
 
-func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+func (c *fakeClient) patch(obj client.Object, patch client.Patch, isStatus bool, opts ...client.PatchOption) error {
 	patchOptions := &client.PatchOptions{}
 	patchOptions.ApplyOptions(opts)
 
@@ -829,61 +986,72 @@ func (c *fakeClient) patch(obj client.Object, patch client.Patch, opts ...client
 		return err
 	}
 
-	oldObj, err := c.tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName())
+	var o runtime.Object
+	if patch.Type() == types.ApplyPatchType {
+		if o, err = c.apply(gvr, gvk, obj, data, isStatus, patchOptions); err != nil {
+			return err
+		}
+	} else if o, err = c.patchObject(gvr, gvk, accessor, patch.Type(), data, isStatus, patchOptions); err != nil {
+		return err
+	}
+
+	if _, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
+		ta, err := meta.TypeAccessor(o)
+		if err != nil {
+			return err
+		}
+		ta.SetKind(gvk.Kind)
+		ta.SetAPIVersion(gvk.GroupVersion().String())
+	}
+
+	j, err := json.Marshal(o)
 	if err != nil {
 		return err
 	}
+	zero(obj)
+	return json.Unmarshal(j, obj)
+}
+
+// patchObject patches the object of accessor with a JSON, merge or strategic merge patch
+// and returns the patched object.
+func (c *fakeClient) patchObject(gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, accessor metav1.Object, patchType types.PatchType, data []byte, isStatus bool, patchOptions *client.PatchOptions) (runtime.Object, error) {
+	oldObj, err := c.tracker.Get(gvr, accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, err
+	}
 	oldAccessor, err := meta.Accessor(oldObj)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Apply patch without updating object.
 	// To remain in accordance with the behavior of k8s api behavior,
 	// a patch must not allow for changes to the deletionTimestamp of an object.
-	// The reaction() function applies the patch to the object and calls Update(),
-	// whereas dryPatch() replicates this behavior but skips the call to Update().
+	// dryPatch() applies the patch to the object but skips the call to Update().
 	// This ensures that the patch may be rejected if a deletionTimestamp is modified, prior
 	// to updating the object.
-	action := testing.NewPatchAction(gvr, accessor.GetNamespace(), accessor.GetName(), patch.Type(), data)
+	action := testing.NewPatchAction(gvr, accessor.GetNamespace(), accessor.GetName(), patchType, data)
 	o, err := dryPatch(action, c.tracker)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	newObj, err := meta.Accessor(o)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Validate that deletionTimestamp has not been changed
 	if !deletionTimestampEqual(newObj, oldAccessor) {
-		return fmt.Errorf("rejected patch, metadata.deletionTimestamp immutable")
-	}
-
-	reaction := testing.ObjectReaction(c.tracker)
-	handled, o, err := reaction(action)
-	if err != nil {
-		return err
-	}
-	if !handled {
-		panic("tracker could not handle patch method")
+		return nil, fmt.Errorf("rejected patch, metadata.deletionTimestamp immutable")
 	}
 
-	if _, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
-		ta, err := meta.TypeAccessor(o)
-		if err != nil {
-			return err
-		}
-		ta.SetKind(gvk.Kind)
-		ta.SetAPIVersion(gvk.GroupVersion().String())
+	if err := c.updateManagedFields(gvk, isStatus, oldObj, o, patchOptions.FieldManager); err != nil {
+		return nil, err
 	}
-
-	j, err := json.Marshal(o)
-	if err != nil {
-		return err
+	if err := c.tracker.update(gvr, o, accessor.GetNamespace(), isStatus, false); err != nil {
+		return nil, err
 	}
-	zero(obj)
-	return json.Unmarshal(j, obj)
+	return o, nil
 }
 
 // Applying a patch results in a deletionTimestamp that is truncated to the nearest second.
@@ -955,8 +1123,6 @@ func dryPatch(action testing.PatchActionImpl, tracker testing.ObjectTracker) (ru
 		if err = json.Unmarshal(mergedByte, obj); err != nil {
 			return nil, err
 		}
-	case types.ApplyPatchType:
-		return nil, errors.New("apply patches are not supported in the fake client. Follow https://github.com/kubernetes/kubernetes/issues/115598 for the current status")
 	default:
 		return nil, fmt.Errorf("%s PatchType is not supported", action.GetPatchType())
 	}
@@ -1077,18 +1243,9 @@ func (sw *fakeSubResourceClient) Get(ctx context.Context, obj, subResource clien
 func (sw *fakeSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
 	switch sw.subResource {
 	case "eviction":
-		_, isEviction := subResource.(*policyv1beta1.Eviction)
-		if !isEviction {
-			_, isEviction = subResource.(*policyv1.Eviction)
-		}
-		if !isEviction {
-			return apierrors.NewBadRequest(fmt.Sprintf("got invalid type %t, expected Eviction", subResource))
-		}
-		if _, isPod := obj.(*corev1.Pod); !isPod {
-			return apierrors.NewNotFound(schema.GroupResource{}, "")
-		}
-
-		return sw.client.Delete(ctx, obj)
+		return sw.client.evict(ctx, obj, subResource)
+	case "binding":
+		return sw.client.bind(ctx, obj, subResource)
 	default:
 		return fmt.Errorf("fakeSubResourceWriter does not support create for %s", sw.subResource)
 	}
@@ -1114,16 +1271,7 @@ func (sw *fakeSubResourceClient) Patch(ctx context.Context, obj client.Object, p
 		body = patchOptions.SubResourceBody
 	}
 
-	// this is necessary to identify that last call was made for status patch, through stack trace.
-	if sw.subResource == "status" {
-		return sw.statusPatch(body, patch, patchOptions)
-	}
-
-	return sw.client.patch(body, patch, &patchOptions.PatchOptions)
-}
-
-func (sw *fakeSubResourceClient) statusPatch(body client.Object, patch client.Patch, patchOptions client.SubResourcePatchOptions) error {
-	return sw.client.patch(body, patch, &patchOptions.PatchOptions)
+	return sw.client.patch(body, patch, sw.subResource == "status", &patchOptions.PatchOptions)
 }
 
 func allowsUnconditionalUpdate(gvk schema.GroupVersionKind) bool {
@@ -20,7 +20,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -359,7 +361,7 @@ var _ = Describe("Fake client", func() {
 			Expect(list.Items).To(ConsistOf(*dep2))
 		})
 
-		It("should reject apply patches, they are not supported in the fake client", func() {
+		It("should be able to Patch with server-side apply", func() {
 			By("Creating a new configmap")
 			cm := &corev1.ConfigMap{
 				TypeMeta: metav1.TypeMeta{
@@ -374,9 +376,19 @@ var _ = Describe("Fake client", func() {
 			err := cl.Create(context.Background(), cm)
 			Expect(err).ToNot(HaveOccurred())
 
+			By("Applying the configmap")
 			cm.Data = map[string]string{"foo": "bar"}
-			err = cl.Patch(context.Background(), cm, client.Apply, client.ForceOwnership)
-			Expect(err).To(MatchError(ContainSubstring("apply patches are not supported in the fake client")))
+			cm.ResourceVersion = ""
+			err = cl.Patch(context.Background(), cm, client.Apply, client.FieldOwner("test-owner"), client.ForceOwnership)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("Getting the applied configmap")
+			obj := &corev1.ConfigMap{}
+			err = cl.Get(context.Background(), client.ObjectKeyFromObject(cm), obj)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(obj.Data).To(Equal(map[string]string{"foo": "bar"}))
+			Expect(obj.ResourceVersion).To(Equal("2"))
+			Expect(obj.ManagedFields).To(ContainElement(HaveField("Manager", "test-owner")))
 		})
 
 		It("should be able to Create", func() {
@@ -1898,6 +1910,134 @@ var _ = Describe("Fake client", func() {
 		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
 	})
 
+	Context("with PodDisruptionBudgets", func() {
+		var cl client.WithWatch
+		var pod *corev1.Pod
+		var pdb *policyv1.PodDisruptionBudget
+
+		BeforeEach(func() {
+			pod = &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", Labels: map[string]string{"app": "foo"}},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+			pdb = &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+				Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}}},
+				Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+			}
+		})
+
+		It("should evict pods whose budget allows disruptions and count the disruption", func() {
+			cl = NewClientBuilder().WithObjects(pod, pdb).Build()
+			Expect(cl.SubResource("eviction").Create(context.Background(), pod, &policyv1.Eviction{})).To(Succeed())
+
+			Expect(apierrors.IsNotFound(cl.Get(context.Background(), client.ObjectKeyFromObject(pod), pod))).To(BeTrue())
+			Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pdb), pdb)).To(Succeed())
+			Expect(pdb.Status.DisruptionsAllowed).To(BeEquivalentTo(0))
+			Expect(pdb.Status.DisruptedPods).To(HaveKey("foo"))
+		})
+
+		It("should refuse to evict pods whose budget doesn't allow disruptions", func() {
+			pdb.Status.DisruptionsAllowed = 0
+			cl = NewClientBuilder().WithObjects(pod, pdb).Build()
+
+			err := cl.SubResource("eviction").Create(context.Background(), pod, &policyv1.Eviction{})
+			Expect(apierrors.IsTooManyRequests(err)).To(BeTrue())
+			Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		})
+
+		It("should evict pods that aren't running regardless of their budget", func() {
+			pod.Status.Phase = corev1.PodSucceeded
+			pdb.Status.DisruptionsAllowed = 0
+			cl = NewClientBuilder().WithObjects(pod, pdb).Build()
+
+			Expect(cl.SubResource("eviction").Create(context.Background(), pod, &policyv1.Eviction{})).To(Succeed())
+		})
+
+		It("should honor the delete options of evictions", func() {
+			cl = NewClientBuilder().WithObjects(pod, pdb).Build()
+
+			eviction := &policyv1.Eviction{DeleteOptions: &metav1.DeleteOptions{DryRun: []string{metav1.DryRunAll}}}
+			Expect(cl.SubResource("eviction").Create(context.Background(), pod, eviction)).To(Succeed())
+			Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+			Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pdb), pdb)).To(Succeed())
+			Expect(pdb.Status.DisruptionsAllowed).To(BeEquivalentTo(1))
+
+			eviction = &policyv1.Eviction{DeleteOptions: &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{ResourceVersion: ptr.To("1")}}}
+			err := cl.SubResource("eviction").Create(context.Background(), pod, eviction)
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+		})
+	})
+
+	It("should assign pods to nodes through the binding subresource", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+		cl := NewClientBuilder().WithObjects(pod).Build()
+
+		binding := &corev1.Binding{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"scheduled-by": "test"}},
+			Target:     corev1.ObjectReference{Kind: "Node", Name: "node-1"},
+		}
+		Expect(cl.SubResource("binding").Create(context.Background(), pod, binding)).To(Succeed())
+
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		Expect(pod.Spec.NodeName).To(Equal("node-1"))
+		Expect(pod.Annotations).To(HaveKeyWithValue("scheduled-by", "test"))
+		Expect(pod.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", corev1.PodScheduled),
+			HaveField("Status", corev1.ConditionTrue),
+		)))
+
+		err := cl.SubResource("binding").Create(context.Background(), pod, binding)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+	})
+
+	It("should reject invalid bindings", func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+		cl := NewClientBuilder().WithObjects(pod).Build()
+
+		err := cl.SubResource("binding").Create(context.Background(), pod, &corev1.Binding{})
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+		err = cl.SubResource("binding").Create(context.Background(), pod, &corev1.Namespace{})
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	Context("with pod logs", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers:     []corev1.Container{{Name: "main"}},
+			},
+		}
+		readLogs := func(stream io.ReadCloser, err error) string {
+			Expect(err).NotTo(HaveOccurred())
+			defer stream.Close()
+			logs, err := io.ReadAll(stream)
+			Expect(err).NotTo(HaveOccurred())
+			return string(logs)
+		}
+
+		It("should stream fake logs by default", func() {
+			cl := NewClientBuilder().WithObjects(pod).Build()
+			Expect(readLogs(cl.(PodLogStreamer).StreamPodLogs(context.Background(), client.ObjectKeyFromObject(pod), nil))).To(Equal("fake logs"))
+		})
+
+		It("should stream the logs of the selected container", func() {
+			cl := NewClientBuilder().WithObjects(pod).WithPodLogs(func(ctx context.Context, pod *corev1.Pod, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(pod.Name + "/" + opts.Container)), nil
+			}).WithInterceptorFuncs(interceptor.Funcs{}).Build()
+			streamer := cl.(PodLogStreamer)
+
+			Expect(readLogs(streamer.StreamPodLogs(context.Background(), client.ObjectKeyFromObject(pod), nil))).To(Equal("foo/main"))
+			Expect(readLogs(streamer.StreamPodLogs(context.Background(), client.ObjectKeyFromObject(pod), &corev1.PodLogOptions{Container: "init"}))).To(Equal("foo/init"))
+
+			_, err := streamer.StreamPodLogs(context.Background(), client.ObjectKeyFromObject(pod), &corev1.PodLogOptions{Container: "sidecar"})
+			Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+			_, err = streamer.StreamPodLogs(context.Background(), client.ObjectKey{Namespace: "default", Name: "bar"}, nil)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	It("should leave typemeta empty on typed get", func() {
 		cl := NewClientBuilder().WithObjects(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
 			Namespace: "default",
@@ -2088,6 +2228,408 @@ func (t *WithPointerMeta) DeepCopyObject() runtime.Object {
 	return t.DeepCopy()
 }
 
+var _ = Describe("Fake client with strict resource versions", func() {
+	var cl client.WithWatch
+
+	BeforeEach(func() {
+		cl = NewClientBuilder().WithStrictResourceVersions().Build()
+	})
+
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dep"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+		}
+	}
+
+	It("should reject stale updates and updates without a resourceVersion like the API server", func() {
+		dep := newDeployment()
+		Expect(cl.Create(context.Background(), dep)).To(Succeed())
+
+		stale := dep.DeepCopy()
+		dep.Spec.Replicas = ptr.To[int32](2)
+		Expect(cl.Update(context.Background(), dep)).To(Succeed())
+
+		stale.Spec.Replicas = ptr.To[int32](3)
+		Expect(apierrors.IsConflict(cl.Update(context.Background(), stale))).To(BeTrue())
+
+		By("updating kinds the API server updates unconditionally without a resourceVersion")
+		stale.ResourceVersion = ""
+		Expect(cl.Update(context.Background(), stale)).To(Succeed())
+
+		By("rejecting updates of other kinds without a resourceVersion")
+		pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb"}}
+		Expect(cl.Create(context.Background(), pdb)).To(Succeed())
+		pdb.ResourceVersion = ""
+		Expect(apierrors.IsConflict(cl.Update(context.Background(), pdb))).To(BeTrue())
+	})
+
+	It("should reject creates of existing objects", func() {
+		Expect(cl.Create(context.Background(), newDeployment())).To(Succeed())
+		Expect(apierrors.IsAlreadyExists(cl.Create(context.Background(), newDeployment()))).To(BeTrue())
+	})
+
+	It("should increment the generation on changes of the spec", func() {
+		dep := newDeployment()
+		Expect(cl.Create(context.Background(), dep)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(1))
+
+		By("not incrementing it on changes of the metadata")
+		dep.Labels = map[string]string{"key": "value"}
+		Expect(cl.Update(context.Background(), dep)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(1))
+
+		By("not incrementing it on changes of the status")
+		dep.Status.Replicas = 1
+		Expect(cl.Status().Update(context.Background(), dep)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(1))
+
+		By("incrementing it on changes of the spec")
+		patch := client.MergeFrom(dep.DeepCopy())
+		dep.Spec.Replicas = ptr.To[int32](2)
+		Expect(cl.Patch(context.Background(), dep, patch)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(2))
+
+		By("ignoring the generation set by the client")
+		dep.Generation = 10
+		Expect(cl.Update(context.Background(), dep)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(2))
+
+		By("incrementing it on the deletion of objects with finalizers")
+		dep.Finalizers = []string{"finalizer"}
+		Expect(cl.Update(context.Background(), dep)).To(Succeed())
+		Expect(cl.Delete(context.Background(), dep)).To(Succeed())
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(dep), dep)).To(Succeed())
+		Expect(dep.Generation).To(BeEquivalentTo(3))
+	})
+
+	It("should check the UID preconditions of deletions", func() {
+		cl = NewClientBuilder().Build()
+		dep := newDeployment()
+		dep.UID = "uid"
+		Expect(cl.Create(context.Background(), dep)).To(Succeed())
+
+		err := cl.Delete(context.Background(), dep, client.Preconditions{UID: ptr.To(types.UID("other"))})
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(cl.Delete(context.Background(), dep, client.Preconditions{UID: ptr.To(types.UID("uid"))})).To(Succeed())
+	})
+})
+
+var _ = Describe("Fake client field selectors", func() {
+	var cl client.WithWatch
+
+	pod := func(name, nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	names := func(list *corev1.PodList) []string {
+		var names []string
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		cl = NewClientBuilder().WithObjects(
+			pod("a", "node-1", corev1.PodRunning),
+			pod("b", "node-2", corev1.PodRunning),
+			pod("c", "node-1", corev1.PodPending),
+		).Build()
+	})
+
+	It("should select objects by the fields the API server selects them by", func() {
+		list := &corev1.PodList{}
+		Expect(cl.List(context.Background(), list, client.MatchingFields{"spec.nodeName": "node-1"})).To(Succeed())
+		Expect(names(list)).To(ConsistOf("a", "c"))
+
+		Expect(cl.List(context.Background(), list, client.MatchingFields{"spec.nodeName": "node-1", "status.phase": "Running"})).To(Succeed())
+		Expect(names(list)).To(ConsistOf("a"))
+
+		Expect(cl.List(context.Background(), list, client.MatchingFields{"spec.hostNetwork": "false"})).To(Succeed())
+		Expect(names(list)).To(ConsistOf("a", "b", "c"))
+	})
+
+	It("should select objects of any kind by name and namespace", func() {
+		list := &corev1.PodList{}
+		Expect(cl.List(context.Background(), list, client.MatchingFields{"metadata.name": "b", "metadata.namespace": "default"})).To(Succeed())
+		Expect(names(list)).To(ConsistOf("b"))
+	})
+
+	It("should support inequality requirements", func() {
+		list := &corev1.PodList{}
+		Expect(cl.List(context.Background(), list, client.MatchingFieldsSelector{
+			Selector: fields.AndSelectors(
+				fields.OneTermNotEqualSelector("spec.nodeName", "node-2"),
+				fields.OneTermNotEqualSelector("metadata.name", "c"),
+			),
+		})).To(Succeed())
+		Expect(names(list)).To(ConsistOf("a"))
+	})
+
+	It("should reject selectors on fields the API server doesn't select objects by", func() {
+		err := cl.List(context.Background(), &corev1.PodList{}, client.MatchingFields{"spec.priorityClassName": "high"})
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Fake client watch event injection", func() {
+	var injector *WatchEventInjector
+	var cl client.WithWatch
+
+	BeforeEach(func() {
+		injector = NewWatchEventInjector()
+		cl = NewClientBuilder().WithWatchEventInjector(injector).Build()
+	})
+
+	receive := func(w watch.Interface) watch.Event {
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		return event
+	}
+
+	It("should inject bookmarks and errors into established watches", func() {
+		w, err := cl.Watch(context.Background(), &corev1.PodList{}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		bookmark := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42"}}
+		Expect(injector.Inject(&corev1.PodList{}, "default", watch.Event{Type: watch.Bookmark, Object: bookmark})).To(Equal(1))
+		status := &apierrors.NewResourceExpired("too old resource version").ErrStatus
+		Expect(injector.Inject(&corev1.PodList{}, "default", watch.Event{Type: watch.Error, Object: status})).To(Equal(1))
+
+		Expect(receive(w)).To(Equal(watch.Event{Type: watch.Bookmark, Object: bookmark}))
+		event := receive(w)
+		Expect(event.Type).To(Equal(watch.Error))
+		Expect(apierrors.IsResourceExpired(apierrors.FromObject(event.Object))).To(BeTrue())
+	})
+
+	It("should interleave injected events with the events of changes", func() {
+		w, err := cl.Watch(context.Background(), &corev1.ConfigMapList{})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+		Expect(cl.Create(context.Background(), cm)).To(Succeed())
+		injected := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "injected"}}
+		Expect(injector.Inject(&corev1.ConfigMapList{}, "default", watch.Event{Type: watch.Added, Object: injected})).To(Equal(1))
+		Expect(cl.Delete(context.Background(), cm)).To(Succeed())
+
+		Expect(receive(w).Type).To(Equal(watch.Added))
+		Expect(receive(w)).To(Equal(watch.Event{Type: watch.Added, Object: injected}))
+		Expect(receive(w).Type).To(Equal(watch.Deleted))
+	})
+
+	It("should only inject events into the watches of the kind and namespace", func() {
+		inDefault, err := cl.Watch(context.Background(), &corev1.PodList{}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+		defer inDefault.Stop()
+		inOther, err := cl.Watch(context.Background(), &corev1.PodList{}, client.InNamespace("other"))
+		Expect(err).NotTo(HaveOccurred())
+		defer inOther.Stop()
+		inAll, err := cl.Watch(context.Background(), &corev1.PodList{})
+		Expect(err).NotTo(HaveOccurred())
+		defer inAll.Stop()
+		secrets, err := cl.Watch(context.Background(), &corev1.SecretList{})
+		Expect(err).NotTo(HaveOccurred())
+		defer secrets.Stop()
+
+		bookmark := watch.Event{Type: watch.Bookmark, Object: &corev1.Pod{}}
+		Expect(injector.Inject(&corev1.PodList{}, "default", bookmark)).To(Equal(2))
+		Expect(receive(inDefault)).To(Equal(bookmark))
+		Expect(receive(inAll)).To(Equal(bookmark))
+		Consistently(inOther.ResultChan()).ShouldNot(Receive())
+		Consistently(secrets.ResultChan()).ShouldNot(Receive())
+
+		Expect(injector.Inject(&corev1.PodList{}, "", bookmark)).To(Equal(3))
+	})
+
+	It("should not inject events into stopped watches", func() {
+		w, err := cl.Watch(context.Background(), &corev1.PodList{})
+		Expect(err).NotTo(HaveOccurred())
+		w.Stop()
+
+		Expect(injector.Inject(&corev1.PodList{}, "", watch.Event{Type: watch.Bookmark, Object: &corev1.Pod{}})).To(Equal(0))
+	})
+
+	It("should inject events into the watches of intercepted clients", func() {
+		var intercepted bool
+		cl = NewClientBuilder().WithWatchEventInjector(injector).WithInterceptorFuncs(interceptor.Funcs{
+			Watch: func(ctx context.Context, client client.WithWatch, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+				intercepted = true
+				return client.Watch(ctx, list, opts...)
+			},
+		}).Build()
+
+		w, err := cl.Watch(context.Background(), &corev1.PodList{})
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+		Expect(intercepted).To(BeTrue())
+
+		Expect(injector.Inject(&corev1.PodList{}, "", watch.Event{Type: watch.Bookmark, Object: &corev1.Pod{}})).To(Equal(1))
+		Expect(receive(w).Type).To(Equal(watch.Bookmark))
+	})
+
+	It("should fail to inject events before being passed to a fake client", func() {
+		_, err := NewWatchEventInjector().Inject(&corev1.PodList{}, "", watch.Event{Type: watch.Bookmark, Object: &corev1.Pod{}})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Fake client server-side apply", func() {
+	var cl client.WithWatch
+
+	configMap := func(data map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetNamespace("default")
+		u.SetName("cm")
+		Expect(unstructured.SetNestedStringMap(u.Object, data, "data")).To(Succeed())
+		return u
+	}
+
+	get := func() *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		Expect(cl.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "cm"}, cm)).To(Succeed())
+		return cm
+	}
+
+	BeforeEach(func() {
+		cl = NewClientBuilder().Build()
+	})
+
+	It("should create objects that don't exist yet", func() {
+		applied := configMap(map[string]string{"key": "value"})
+		Expect(cl.Patch(context.Background(), applied, client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(applied.GetResourceVersion()).To(Equal("1"))
+
+		cm := get()
+		Expect(cm.Data).To(Equal(map[string]string{"key": "value"}))
+		Expect(cm.ManagedFields).To(HaveLen(1))
+		Expect(cm.ManagedFields[0].Manager).To(Equal("a"))
+		Expect(cm.ManagedFields[0].Operation).To(Equal(metav1.ManagedFieldsOperationApply))
+		Expect(string(cm.ManagedFields[0].FieldsV1.Raw)).To(ContainSubstring(`"f:key"`))
+	})
+
+	It("should reject conflicting changes of fields owned by other managers unless forced", func() {
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+
+		err := cl.Patch(context.Background(), configMap(map[string]string{"key": "b"}), client.Apply, client.FieldOwner("b"))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`conflict with "a"`)))
+		Expect(get().Data).To(Equal(map[string]string{"key": "a"}))
+
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "b"}), client.Apply, client.FieldOwner("b"), client.ForceOwnership)).To(Succeed())
+		cm := get()
+		Expect(cm.Data).To(Equal(map[string]string{"key": "b"}))
+		for _, entry := range cm.ManagedFields {
+			if entry.Manager == "a" {
+				Expect(string(entry.FieldsV1.Raw)).NotTo(ContainSubstring(`"f:key"`))
+			}
+		}
+	})
+
+	It("should share the ownership of fields applied with the same value", func() {
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "value", "a": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "value"}), client.Apply, client.FieldOwner("b"))).To(Succeed())
+
+		By("keeping the fields still applied by another manager")
+		Expect(cl.Patch(context.Background(), configMap(nil), client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(get().Data).To(Equal(map[string]string{"key": "value"}))
+	})
+
+	It("should remove the fields that aren't applied anymore", func() {
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"a": "a", "b": "b"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"a": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(get().Data).To(Equal(map[string]string{"a": "a"}))
+	})
+
+	It("should track the fields changed by other requests with a field manager", func() {
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+
+		cm := get()
+		cm.Data["key"] = "updated"
+		Expect(cl.Update(context.Background(), cm, client.FieldOwner("updater"))).To(Succeed())
+		Expect(cm.ManagedFields).To(ContainElement(And(
+			HaveField("Manager", "updater"),
+			HaveField("Operation", metav1.ManagedFieldsOperationUpdate),
+		)))
+
+		err := cl.Patch(context.Background(), configMap(map[string]string{"key": "a"}), client.Apply, client.FieldOwner("a"))
+		Expect(err).To(MatchError(ContainSubstring(`conflict with "updater"`)))
+	})
+
+	It("should track the fields changed by requests without a field manager once the fields are managed", func() {
+		Expect(cl.Patch(context.Background(), configMap(map[string]string{"key": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"data":{"key":"patched"}}`))
+		Expect(cl.Patch(context.Background(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}, patch)).To(Succeed())
+
+		err := cl.Patch(context.Background(), configMap(map[string]string{"key": "a"}), client.Apply, client.FieldOwner("a"))
+		Expect(err).To(MatchError(ContainSubstring(`conflict with "fake-client"`)))
+	})
+
+	It("should not track the fields of objects without managed fields", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}, Data: map[string]string{"key": "value"}}
+		Expect(cl.Create(context.Background(), cm)).To(Succeed())
+		cm.Data["key"] = "updated"
+		Expect(cl.Update(context.Background(), cm)).To(Succeed())
+		Expect(get().ManagedFields).To(BeEmpty())
+	})
+
+	It("should require a field manager", func() {
+		err := cl.Patch(context.Background(), configMap(nil), client.Apply)
+		Expect(apierrors.IsBadRequest(err)).To(BeTrue())
+	})
+
+	It("should apply the status subresource", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"},
+			Spec:       corev1.PodSpec{NodeName: "node"},
+		}
+		Expect(cl.Create(context.Background(), pod)).To(Succeed())
+
+		applied := &unstructured.Unstructured{}
+		applied.SetAPIVersion("v1")
+		applied.SetKind("Pod")
+		applied.SetNamespace("default")
+		applied.SetName("pod")
+		Expect(unstructured.SetNestedField(applied.Object, "Running", "status", "phase")).To(Succeed())
+		Expect(unstructured.SetNestedField(applied.Object, "other-node", "spec", "nodeName")).To(Succeed())
+		Expect(cl.Status().Patch(context.Background(), applied, client.Apply, client.FieldOwner("kubelet"))).To(Succeed())
+
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(pod), pod)).To(Succeed())
+		Expect(pod.Status.Phase).To(Equal(corev1.PodRunning))
+		Expect(pod.Spec.NodeName).To(Equal("node"))
+		Expect(pod.ManagedFields).To(ConsistOf(And(
+			HaveField("Manager", "kubelet"),
+			HaveField("Subresource", "status"),
+		)))
+	})
+
+	It("should apply unstructured objects of kinds unknown to the scheme", func() {
+		foo := func(spec map[string]interface{}) *unstructured.Unstructured {
+			u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+			u.SetAPIVersion("example.com/v1")
+			u.SetKind("Foo")
+			u.SetNamespace("default")
+			u.SetName("foo")
+			return u
+		}
+		Expect(cl.Patch(context.Background(), foo(map[string]interface{}{"a": "a"}), client.Apply, client.FieldOwner("a"))).To(Succeed())
+		Expect(cl.Patch(context.Background(), foo(map[string]interface{}{"b": "b"}), client.Apply, client.FieldOwner("b"))).To(Succeed())
+
+		applied := foo(nil)
+		Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(applied), applied)).To(Succeed())
+		Expect(applied.Object["spec"]).To(Equal(map[string]interface{}{"a": "a", "b": "b"}))
+	})
+})
+
 var _ = Describe("Fake client builder", func() {
 	It("panics when an index with the same name and GroupVersionKind is registered twice", func() {
 		// We need any realistic GroupVersionKind, the choice of apps/v1 Deployment is arbitrary.
@@ -24,6 +24,21 @@ You can create a fake client with optional objects.
 
 You can invoke the methods defined in the Client interface.
 
+Apply patches are merged with server-side apply, and the managed fields of objects are
+tracked once a field manager is involved, so that conflicts between field managers are
+reported like by the API server. Without the OpenAPI schemas of the types, see
+ClientBuilder.WithTypeConverter, lists are replaced as a whole instead of being merged.
+
+Watches receive the events of the changes of objects. Arbitrary events, e.g. bookmarks
+and errors, can be injected into them with a WatchEventInjector, see
+ClientBuilder.WithWatchEventInjector, and calls to Watch can be intercepted like the
+other methods with ClientBuilder.WithInterceptorFuncs.
+
+Besides the status subresource, pods can be evicted, honoring their PodDisruptionBudgets,
+and bound to nodes through their eviction and binding subresources.
+The logs of pods can be streamed with the PodLogStreamer the fake clients implement, see
+ClientBuilder.WithPodLogs.
+
 When in doubt, it's almost always better not to use this package and instead use
 envtest.Environment with a real client and API server.
 
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fieldValue returns the value of a field of an object for field selectors.
+type fieldValue func(content map[string]interface{}) string
+
+// selectableFields are the fields the API server can select the objects of built-in
+// kinds by, in addition to metadata.name and metadata.namespace.
+var selectableFields = map[schema.GroupKind]map[string]fieldValue{
+	{Kind: "Event"}: {
+		"involvedObject.kind":            stringField("involvedObject", "kind"),
+		"involvedObject.namespace":       stringField("involvedObject", "namespace"),
+		"involvedObject.name":            stringField("involvedObject", "name"),
+		"involvedObject.uid":             stringField("involvedObject", "uid"),
+		"involvedObject.apiVersion":      stringField("involvedObject", "apiVersion"),
+		"involvedObject.resourceVersion": stringField("involvedObject", "resourceVersion"),
+		"involvedObject.fieldPath":       stringField("involvedObject", "fieldPath"),
+		"reason":                         stringField("reason"),
+		"reportingComponent":             stringField("reportingComponent"),
+		"source":                         stringField("source", "component"),
+		"type":                           stringField("type"),
+	},
+	{Kind: "Namespace"}: {
+		"status.phase": stringField("status", "phase"),
+	},
+	{Kind: "Node"}: {
+		"spec.unschedulable": boolField("spec", "unschedulable"),
+	},
+	{Kind: "Pod"}: {
+		"spec.nodeName":            stringField("spec", "nodeName"),
+		"spec.restartPolicy":       stringField("spec", "restartPolicy"),
+		"spec.schedulerName":       stringField("spec", "schedulerName"),
+		"spec.serviceAccountName":  stringField("spec", "serviceAccountName"),
+		"spec.hostNetwork":         boolField("spec", "hostNetwork"),
+		"status.phase":             stringField("status", "phase"),
+		"status.podIP":             stringField("status", "podIP"),
+		"status.nominatedNodeName": stringField("status", "nominatedNodeName"),
+	},
+	{Kind: "ReplicationController"}: {
+		"status.replicas": intField("status", "replicas"),
+	},
+	{Kind: "Secret"}: {
+		"type": stringField("type"),
+	},
+	{Group: "apps", Kind: "ReplicaSet"}: {
+		"status.replicas": intField("status", "replicas"),
+	},
+	{Group: "batch", Kind: "Job"}: {
+		"status.successful": intField("status", "successful"),
+	},
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}: {
+		"spec.signerName": stringField("spec", "signerName"),
+	},
+}
+
+// selectableField returns the value of the field of the objects of kind gvk the API server
+// can select them by, or nil if it can't select them by field.
+func selectableField(gvk schema.GroupVersionKind, field string) fieldValue {
+	switch field {
+	case "metadata.name":
+		return stringField("metadata", "name")
+	case "metadata.namespace":
+		return stringField("metadata", "namespace")
+	}
+	return selectableFields[gvk.GroupKind()][field]
+}
+
+// unstructuredContent returns the content of obj for fieldValues.
+func unstructuredContent(obj runtime.Object) (map[string]interface{}, error) {
+	if u, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func stringField(path ...string) fieldValue {
+	return func(content map[string]interface{}) string {
+		value, found, err := unstructured.NestedFieldNoCopy(content, path...)
+		if !found || err != nil || value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+}
+
+func boolField(path ...string) fieldValue {
+	return func(content map[string]interface{}) string {
+		value, _, _ := unstructured.NestedBool(content, path...)
+		return strconv.FormatBool(value)
+	}
+}
+
+func intField(path ...string) fieldValue {
+	return func(content map[string]interface{}) string {
+		value, _, _ := unstructured.NestedInt64(content, path...)
+		return strconv.FormatInt(value, 10)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodLogStreamer streams the logs of the containers of pods, like the log subresource of
+// pods. The clients built by ClientBuilder implement it, so that the code reading the
+// logs of pods through an interface with this method can be tested with them.
+type PodLogStreamer interface {
+	// StreamPodLogs returns the stream of the logs of the container of the pod named by
+	// key selected by opts, which can be omitted for pods with a single container.
+	StreamPodLogs(ctx context.Context, key client.ObjectKey, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+// PodLogsFunc returns the stream of the logs of the container opts.Container of pod, see
+// ClientBuilder.WithPodLogs.
+type PodLogsFunc func(ctx context.Context, pod *corev1.Pod, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+
+// defaultPodLogs returns the same logs for all containers, like the fake clientset of
+// client-go.
+func defaultPodLogs(context.Context, *corev1.Pod, *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("fake logs")), nil
+}
+
+var _ PodLogStreamer = &fakeClient{}
+
+// StreamPodLogs implements PodLogStreamer.
+func (c *fakeClient) StreamPodLogs(ctx context.Context, key client.ObjectKey, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, key, pod); err != nil {
+		return nil, err
+	}
+
+	logOpts := &corev1.PodLogOptions{}
+	if opts != nil {
+		logOpts = opts.DeepCopy()
+	}
+	var containers []string
+	for _, container := range pod.Spec.InitContainers {
+		containers = append(containers, container.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		containers = append(containers, container.Name)
+	}
+	switch {
+	case logOpts.Container == "" && len(pod.Spec.Containers) == 1:
+		logOpts.Container = pod.Spec.Containers[0].Name
+	case logOpts.Container == "":
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("a container name must be specified for pod %s, choose one of: %v", pod.Name, containers))
+	case !slices.Contains(containers, logOpts.Container):
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("container %s is not valid for pod %s", logOpts.Container, pod.Name))
+	}
+
+	podLogs := c.podLogs
+	if podLogs == nil {
+		podLogs = defaultPodLogs
+	}
+	return podLogs(ctx, pod, logOpts)
+}
+
+// evict deletes the pod obj through the eviction subresource, unless its deletion is
+// prevented by a PodDisruptionBudget whose disruptions aren't allowed, and counts the
+// disruption in the status of that PodDisruptionBudget.
+func (c *fakeClient) evict(ctx context.Context, obj client.Object, eviction client.Object) error {
+	var deleteOptions *metav1.DeleteOptions
+	switch eviction := eviction.(type) {
+	case *policyv1.Eviction:
+		deleteOptions = eviction.DeleteOptions
+	case *policyv1beta1.Eviction:
+		deleteOptions = eviction.DeleteOptions
+	default:
+		return apierrors.NewBadRequest(fmt.Sprintf("got invalid type %T, expected Eviction", eviction))
+	}
+	if _, isPod := obj.(*corev1.Pod); !isPod {
+		return apierrors.NewNotFound(schema.GroupResource{}, "")
+	}
+
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), pod); err != nil {
+		return err
+	}
+
+	opts := &client.DeleteOptions{}
+	if deleteOptions != nil {
+		opts.GracePeriodSeconds = deleteOptions.GracePeriodSeconds
+		opts.Preconditions = deleteOptions.Preconditions
+		opts.PropagationPolicy = deleteOptions.PropagationPolicy
+		opts.DryRun = deleteOptions.DryRun
+	}
+	if err := c.checkDisruptionBudget(ctx, pod, slices.Contains(opts.DryRun, metav1.DryRunAll)); err != nil {
+		return err
+	}
+	return c.Delete(ctx, obj, opts)
+}
+
+// checkDisruptionBudget returns an error if the PodDisruptionBudget of pod doesn't allow
+// evicting it, or counts its eviction otherwise, unless dryRun is set. Like the API
+// server, the budgets of pods that aren't running or are already being deleted aren't
+// checked.
+func (c *fakeClient) checkDisruptionBudget(ctx context.Context, pod *corev1.Pod, dryRun bool) error {
+	switch {
+	case pod.Status.Phase == corev1.PodSucceeded, pod.Status.Phase == corev1.PodFailed, pod.Status.Phase == corev1.PodPending:
+		return nil
+	case pod.DeletionTimestamp != nil:
+		return nil
+	case !c.scheme.Recognizes(policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget")):
+		return nil
+	}
+
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := c.List(ctx, pdbs, client.InNamespace(pod.Namespace)); err != nil {
+		return err
+	}
+	var matching []policyv1.PodDisruptionBudget
+	for _, pdb := range pdbs.Items {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			matching = append(matching, pdb)
+		}
+	}
+	switch {
+	case len(matching) == 0:
+		return nil
+	case len(matching) > 1:
+		return apierrors.NewInternalError(fmt.Errorf("this pod has more than one PodDisruptionBudget, which the eviction subresource does not support"))
+	}
+
+	pdb := &matching[0]
+	if pdb.Status.DisruptionsAllowed <= 0 {
+		err := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		err.ErrStatus.Details.Causes = append(err.ErrStatus.Details.Causes, metav1.StatusCause{
+			Type:    policyv1.DisruptionBudgetCause,
+			Message: fmt.Sprintf("The disruption budget %s needs %d healthy pods and has %d currently", pdb.Name, pdb.Status.DesiredHealthy, pdb.Status.CurrentHealthy),
+		})
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+	pdb.Status.DisruptionsAllowed--
+	if pdb.Status.DisruptedPods == nil {
+		pdb.Status.DisruptedPods = map[string]metav1.Time{}
+	}
+	pdb.Status.DisruptedPods[pod.Name] = metav1.Now()
+	return c.Status().Update(ctx, pdb)
+}
+
+// bind assigns the pod obj to the node targeted by binding through the binding
+// subresource, like the scheduler does.
+func (c *fakeClient) bind(ctx context.Context, obj client.Object, binding client.Object) error {
+	b, isBinding := binding.(*corev1.Binding)
+	if !isBinding {
+		return apierrors.NewBadRequest(fmt.Sprintf("got invalid type %T, expected Binding", binding))
+	}
+	if _, isPod := obj.(*corev1.Pod); !isPod {
+		return apierrors.NewNotFound(schema.GroupResource{}, "")
+	}
+	if b.Target.Name == "" {
+		return apierrors.NewBadRequest("target.name is required for bindings")
+	}
+	if b.Target.Kind != "" && b.Target.Kind != "Node" {
+		return apierrors.NewBadRequest(fmt.Sprintf("target.kind must be Node, got %s", b.Target.Kind))
+	}
+
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), pod); err != nil {
+		return err
+	}
+	gr := corev1.Resource("pods")
+	if pod.DeletionTimestamp != nil {
+		return apierrors.NewConflict(gr, pod.Name, fmt.Errorf("pod %s is being deleted, cannot be assigned to a host", pod.Name))
+	}
+	if pod.Spec.NodeName != "" {
+		return apierrors.NewConflict(gr, pod.Name, fmt.Errorf("pod %s is already assigned to node %q", pod.Name, pod.Spec.NodeName))
+	}
+
+	pod.Spec.NodeName = b.Target.Name
+	for key, value := range b.Annotations {
+		metav1.SetMetaDataAnnotation(&pod.ObjectMeta, key, value)
+	}
+	if err := c.Update(ctx, pod); err != nil {
+		return err
+	}
+
+	scheduled := corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()}
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodScheduled {
+			pod.Status.Conditions[i] = scheduled
+			return c.Status().Update(ctx, pod)
+		}
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, scheduled)
+	return c.Status().Update(ctx, pod)
+}
+
+// interceptedClient is a client built with interceptor funcs, which still streams the
+// logs of pods.
+type interceptedClient struct {
+	client.WithWatch
+	PodLogStreamer
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// WatchEventInjector injects arbitrary events, e.g. bookmarks and errors, into the
+// watches established with a fake client, in addition to the events of the changes
+// of its objects, see ClientBuilder.WithWatchEventInjector:
+//
+//	injector := fake.NewWatchEventInjector()
+//	c := fake.NewClientBuilder().WithWatchEventInjector(injector).Build()
+//	...
+//	injector.Inject(&corev1.PodList{}, "default", watch.Event{Type: watch.Error, Object: &metav1.Status{...}})
+type WatchEventInjector struct {
+	mu       sync.Mutex
+	scheme   *runtime.Scheme
+	watchers map[schema.GroupVersionResource]map[string][]injectableWatcher
+}
+
+// injectableWatcher is a watcher of the object tracker events can be injected into,
+// e.g. a watch.RaceFreeFakeWatcher.
+type injectableWatcher interface {
+	watch.Interface
+	Action(action watch.EventType, obj runtime.Object)
+	IsStopped() bool
+}
+
+// NewWatchEventInjector returns a new WatchEventInjector.
+func NewWatchEventInjector() *WatchEventInjector {
+	return &WatchEventInjector{watchers: map[schema.GroupVersionResource]map[string][]injectableWatcher{}}
+}
+
+// Inject sends event to the active watches of the objects of the type of list in
+// namespace, including the watches of all namespaces, or to all the active watches of
+// these objects if namespace is empty. It returns the number of watches the event was
+// sent to. Like the events of the changes of objects, injected events are buffered, and
+// the fake client panics if the buffer of a watch is full.
+func (i *WatchEventInjector) Inject(list client.ObjectList, namespace string, event watch.Event) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.scheme == nil {
+		return 0, errors.New("the watch event injector hasn't been passed to a fake client")
+	}
+	gvr, err := watchedResource(list, i.scheme)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for ns, watchers := range i.watchers[gvr] {
+		active := watchers[:0]
+		for _, w := range watchers {
+			if !w.IsStopped() {
+				active = append(active, w)
+			}
+		}
+		i.watchers[gvr][ns] = active
+		if namespace != "" && ns != "" && ns != namespace {
+			continue
+		}
+		for _, w := range active {
+			w.Action(event.Type, event.Object)
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// register makes w, a watch of the objects of the resource gvr in namespace, receive the
+// injected events. Watches of object trackers not returning injectable watchers are
+// ignored.
+func (i *WatchEventInjector) register(gvr schema.GroupVersionResource, namespace string, w watch.Interface) {
+	injectable, ok := w.(injectableWatcher)
+	if !ok {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.watchers[gvr] == nil {
+		i.watchers[gvr] = map[string][]injectableWatcher{}
+	}
+	i.watchers[gvr][namespace] = append(i.watchers[gvr][namespace], injectable)
+}
+
+// watchedResource returns the resource of the objects of the type of list.
+func watchedResource(list client.ObjectList, scheme *runtime.Scheme) (schema.GroupVersionResource, error) {
+	gvk, err := apiutil.GVKForObject(list, scheme)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return gvr, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type controllerNameKey struct{}
+
+// WithControllerName returns a copy of ctx carrying the name of the controller making
+// the requests. Controllers set it on the context passed to their reconciler.
+func WithControllerName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, controllerNameKey{}, name)
+}
+
+// ControllerNameFromContext returns the name of the controller set by WithControllerName,
+// or an empty string if none was set.
+func ControllerNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(controllerNameKey{}).(string)
+	return name
+}
+
+// WithFieldOwnerFromContext wraps an existing client so that creates, updates and
+// patches, including server-side apply and those of subresources, default their field
+// owner to the name of the controller carried by the context, see WithControllerName.
+// Requests with an explicit FieldOwner and requests made outside of controllers are
+// passed through unchanged.
+func WithFieldOwnerFromContext(c Client) Client {
+	return &fieldOwnerClient{client: c}
+}
+
+var _ Client = &fieldOwnerClient{}
+
+// fieldOwnerClient is a Client that wraps another Client in order to default the field
+// owner of its requests.
+type fieldOwnerClient struct {
+	client Client
+}
+
+// Scheme returns the scheme this client is using.
+func (c *fieldOwnerClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *fieldOwnerClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *fieldOwnerClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *fieldOwnerClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *fieldOwnerClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&CreateOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *fieldOwnerClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&UpdateOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return c.client.Update(ctx, obj, opts...)
+}
+
+// Delete implements client.Client.
+func (c *fieldOwnerClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *fieldOwnerClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	return c.client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *fieldOwnerClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&PatchOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return c.client.Patch(ctx, obj, patch, opts...)
+}
+
+// Get implements client.Client.
+func (c *fieldOwnerClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *fieldOwnerClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *fieldOwnerClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClient.
+func (c *fieldOwnerClient) SubResource(subResource string) SubResourceClient {
+	return &fieldOwnerSubResourceClient{client: c.client.SubResource(subResource)}
+}
+
+// ensure fieldOwnerSubResourceClient implements client.SubResourceClient.
+var _ SubResourceClient = &fieldOwnerSubResourceClient{}
+
+// fieldOwnerSubResourceClient is a client.SubResourceClient that defaults the field
+// owner of its requests.
+type fieldOwnerSubResourceClient struct {
+	client SubResourceClient
+}
+
+func (sw *fieldOwnerSubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	return sw.client.Get(ctx, obj, subResource, opts...)
+}
+
+func (sw *fieldOwnerSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&SubResourceCreateOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return sw.client.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sw *fieldOwnerSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&SubResourceUpdateOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return sw.client.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.SubResourceWriter.
+func (sw *fieldOwnerSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	if owner := ControllerNameFromContext(ctx); owner != "" && (&SubResourcePatchOptions{}).ApplyOptions(opts).FieldManager == "" {
+		opts = append(opts[:len(opts):len(opts)], FieldOwner(owner))
+	}
+	return sw.client.Patch(ctx, obj, patch, opts...)
+}
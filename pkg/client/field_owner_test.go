@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("WithFieldOwnerFromContext", func() {
+	var (
+		managers []string
+		cl       client.Client
+		cm       *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		managers = nil
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+		cl = client.WithFieldOwnerFromContext(interceptor.NewClient(fake.NewClientBuilder().WithObjects(cm.DeepCopy()).Build(), interceptor.Funcs{
+			Update: func(_ context.Context, _ client.WithWatch, _ client.Object, opts ...client.UpdateOption) error {
+				managers = append(managers, (&client.UpdateOptions{}).ApplyOptions(opts).FieldManager)
+				return nil
+			},
+			Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, opts ...client.PatchOption) error {
+				managers = append(managers, (&client.PatchOptions{}).ApplyOptions(opts).FieldManager)
+				return nil
+			},
+			SubResourceUpdate: func(_ context.Context, _ client.Client, _ string, _ client.Object, opts ...client.SubResourceUpdateOption) error {
+				managers = append(managers, (&client.SubResourceUpdateOptions{}).ApplyOptions(opts).FieldManager)
+				return nil
+			},
+		}))
+	})
+
+	It("should default the field owner to the name of the controller", func() {
+		ctx := client.WithControllerName(context.Background(), "my-controller")
+		Expect(cl.Update(ctx, cm)).To(Succeed())
+		Expect(cl.Patch(ctx, cm, client.Apply)).To(Succeed())
+		Expect(cl.Status().Update(ctx, cm)).To(Succeed())
+		Expect(managers).To(Equal([]string{"my-controller", "my-controller", "my-controller"}))
+	})
+
+	It("should not override explicit field owners", func() {
+		ctx := client.WithControllerName(context.Background(), "my-controller")
+		Expect(cl.Update(ctx, cm, client.FieldOwner("explicit"))).To(Succeed())
+		Expect(managers).To(Equal([]string{"explicit"}))
+	})
+
+	It("should not set a field owner outside of controllers", func() {
+		Expect(cl.Update(context.Background(), cm)).To(Succeed())
+		Expect(managers).To(Equal([]string{""}))
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"k8s.io/client-go/transport"
+)
+
+type impersonationContextKey struct{}
+
+// WithImpersonation returns a copy of ctx that makes the clients created by New and
+// NewWithWatch send the requests made with it as the given user, member of the given
+// groups and with the given extra attributes, e.g. to authorize the operations of
+// multi-tenant operators with the permissions of the user requesting them:
+//
+//	ctx = client.WithImpersonation(ctx, "jane", []string{"tenant-a"}, nil)
+//	err := c.Create(ctx, obj)
+//
+// The requests share the connections of the client, no client is created per user.
+// Reads made with ctx bypass the cache of the client, as it isn't authorized per
+// user. The identity of the rest.Config of the client must be allowed to impersonate
+// the user, see https://kubernetes.io/docs/reference/access-authn-authz/authentication/#user-impersonation.
+func WithImpersonation(ctx context.Context, user string, groups []string, extra map[string][]string) context.Context {
+	return context.WithValue(ctx, impersonationContextKey{}, transport.ImpersonationConfig{
+		UserName: user,
+		Groups:   groups,
+		Extra:    extra,
+	})
+}
+
+// impersonationFrom returns the user to impersonate carried by ctx, if any.
+func impersonationFrom(ctx context.Context) (transport.ImpersonationConfig, bool) {
+	impersonate, ok := ctx.Value(impersonationContextKey{}).(transport.ImpersonationConfig)
+	return impersonate, ok && impersonate.UserName != ""
+}
+
+// withImpersonationTransport returns a copy of httpClient whose transport impersonates
+// the users set on the contexts of the requests with WithImpersonation.
+func withImpersonationTransport(httpClient *http.Client) *http.Client {
+	delegate := httpClient.Transport
+	if delegate == nil {
+		delegate = http.DefaultTransport
+	}
+	impersonating := *httpClient
+	impersonating.Transport = &impersonatingRoundTripper{delegate: delegate}
+	return &impersonating
+}
+
+// impersonatingRoundTripper impersonates the user carried by the context of a request.
+type impersonatingRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *impersonatingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	impersonate, ok := impersonationFrom(req.Context())
+	if !ok {
+		return rt.delegate.RoundTrip(req)
+	}
+	return transport.NewImpersonatingRoundTripper(impersonate, rt.delegate).RoundTrip(req)
+}
+
+// WrappedRoundTripper returns the round tripper wrapped by rt.
+func (rt *impersonatingRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.delegate
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("WithImpersonation", func() {
+	var (
+		server  *httptest.Server
+		headers chan http.Header
+		cl      client.Client
+	)
+
+	BeforeEach(func() {
+		headers = make(chan http.Header, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers <- r.Header.Clone()
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(&corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"},
+			})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+		var err error
+		cl, err = client.New(&rest.Config{Host: server.URL}, client.Options{
+			Mapper: mapper,
+			Cache:  &client.CacheOptions{Reader: fake.NewClientBuilder().Build()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should send the requests as the impersonated user", func() {
+		ctx := client.WithImpersonation(context.Background(), "jane", []string{"tenant-a", "tenant-b"}, map[string][]string{"scopes": {"view"}})
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+		Expect(cl.Update(ctx, cm)).To(Succeed())
+
+		var h http.Header
+		Eventually(headers).Should(Receive(&h))
+		Expect(h.Get("Impersonate-User")).To(Equal("jane"))
+		Expect(h.Values("Impersonate-Group")).To(Equal([]string{"tenant-a", "tenant-b"}))
+		Expect(h.Values("Impersonate-Extra-Scopes")).To(Equal([]string{"view"}))
+	})
+
+	It("should read impersonated requests from the API server", func() {
+		ctx := client.WithImpersonation(context.Background(), "jane", nil, nil)
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cm"}, &corev1.ConfigMap{})).To(Succeed())
+
+		var h http.Header
+		Eventually(headers).Should(Receive(&h))
+		Expect(h.Get("Impersonate-User")).To(Equal("jane"))
+	})
+
+	It("should not impersonate anyone without WithImpersonation", func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+		Expect(cl.Update(context.Background(), cm)).To(Succeed())
+
+		var h http.Header
+		Eventually(headers).Should(Receive(&h))
+		Expect(h.Get("Impersonate-User")).To(BeEmpty())
+	})
+})
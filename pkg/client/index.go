@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// compositeIndexFieldSeparator separates the fields in the names of composite
+	// indexes.
+	compositeIndexFieldSeparator = "+"
+
+	// compositeIndexKeySeparator separates the values in the keys of composite indexes.
+	// It can't be part of the values of fields selectable by the API server.
+	compositeIndexKeySeparator = "\x00"
+)
+
+// CompositeIndexField returns the name of a composite index over the given fields, e.g.
+// "spec.nodeName+status.phase", whose IndexerFunc returns the keys built with
+// CompositeIndexKey from the values of these fields:
+//
+//	mgr.GetFieldIndexer().IndexField(ctx, &corev1.Pod{}, client.CompositeIndexField("spec.nodeName", "status.phase"), func(obj client.Object) []string {
+//		pod := obj.(*corev1.Pod)
+//		return []string{client.CompositeIndexKey(pod.Spec.NodeName, string(pod.Status.Phase))}
+//	})
+//
+// Caches serve the lists matching all the fields of a composite index with a single
+// lookup of the index, e.g. client.MatchingFields{"spec.nodeName": "node", "status.phase": "Running"},
+// instead of filtering the objects of the index of one of the fields.
+func CompositeIndexField(fields ...string) string {
+	return strings.Join(fields, compositeIndexFieldSeparator)
+}
+
+// CompositeIndexFields returns the fields of the index named field, which are several
+// for composite indexes.
+func CompositeIndexFields(field string) []string {
+	return strings.Split(field, compositeIndexFieldSeparator)
+}
+
+// CompositeIndexKey returns the key of a composite index for the given values of its
+// fields, in the order of the fields in the name of the index.
+func CompositeIndexKey(values ...string) string {
+	return strings.Join(values, compositeIndexKeySeparator)
+}
+
+// IndexFieldWithOptions adds an index configured with opts to indexer, see
+// FieldIndexer.IndexField. It fails if opts are given and indexer doesn't implement
+// FieldIndexerWithOptions.
+func IndexFieldWithOptions(ctx context.Context, indexer FieldIndexer, obj Object, field string, extractValue IndexerFunc, opts ...IndexOption) error {
+	if withOptions, ok := indexer.(FieldIndexerWithOptions); ok {
+		return withOptions.IndexFieldWithOptions(ctx, obj, field, extractValue, opts...)
+	}
+	if len(opts) > 0 {
+		return fmt.Errorf("field indexer %T doesn't support index options", indexer)
+	}
+	return indexer.IndexField(ctx, obj, field, extractValue)
+}
+
+// GetByIndex returns the single object of type T, e.g. *corev1.Pod, whose index field
+// has the key value, typically in a unique index. It returns a NotFound error if no
+// object has the key, and an error if several objects have it. Unique indexes don't
+// reject the objects with a duplicate key when they are indexed, so the conflicts only
+// surface here, when the key is listed.
+//
+// The list of the objects of type T is created with the scheme of c if it has one, like
+// clients, or with the scheme of client-go otherwise.
+func GetByIndex[T Object](ctx context.Context, c Reader, field, value string, opts ...ListOption) (T, error) {
+	var zero T
+	typ := reflect.TypeOf(zero)
+	if typ == nil || typ.Kind() != reflect.Pointer {
+		return zero, fmt.Errorf("type %v must be a pointer to an object", typ)
+	}
+	obj, ok := reflect.New(typ.Elem()).Interface().(T)
+	if !ok {
+		return zero, fmt.Errorf("type %v must be a pointer to an object", typ)
+	}
+
+	s := scheme.Scheme
+	if withScheme, ok := c.(interface{ Scheme() *runtime.Scheme }); ok {
+		s = withScheme.Scheme()
+	}
+	gvk, err := apiutil.GVKForObject(obj, s)
+	if err != nil {
+		return zero, err
+	}
+	newList, err := s.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return zero, err
+	}
+	list, ok := newList.(ObjectList)
+	if !ok {
+		return zero, fmt.Errorf("list of %s of type %T is not an ObjectList", gvk, newList)
+	}
+
+	// Don't append to the array of opts, which belongs to the caller.
+	if err := c.List(ctx, list, append(opts[:len(opts):len(opts)], MatchingFields{field: value})...); err != nil {
+		return zero, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return zero, err
+	}
+	switch len(items) {
+	case 0:
+		return zero, apierrors.NewNotFound(schema.GroupResource{
+			Group: gvk.Group,
+			// Resource gets set as Kind in the error like for cached objects
+			Resource: gvk.Kind,
+		}, value)
+	case 1:
+		item, ok := items[0].(T)
+		if !ok {
+			return zero, fmt.Errorf("list of %s contains %T, expected %v", gvk, items[0], typ)
+		}
+		return item, nil
+	default:
+		return zero, fmt.Errorf("%d %s objects have the key %q of index %s, expected at most one", len(items), gvk.Kind, value, field)
+	}
+}
@@ -2,6 +2,7 @@ package interceptor
 
 import (
 	"context"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -35,6 +36,28 @@ func NewClient(interceptedClient client.WithWatch, funcs Funcs) client.WithWatch
 	}
 }
 
+// FromFuncs returns a client.Interceptor, e.g. for client.Options.Interceptors, that
+// wraps clients with NewClient. Calls of Watch fail if the wrapped client doesn't
+// implement client.WithWatch.
+func FromFuncs(funcs Funcs) client.Interceptor {
+	return func(c client.Client) client.Client {
+		withWatch, ok := c.(client.WithWatch)
+		if !ok {
+			withWatch = withoutWatch{Client: c}
+		}
+		return NewClient(withWatch, funcs)
+	}
+}
+
+// withoutWatch is a client.WithWatch that doesn't support Watch.
+type withoutWatch struct {
+	client.Client
+}
+
+func (c withoutWatch) Watch(_ context.Context, _ client.ObjectList, _ ...client.ListOption) (watch.Interface, error) {
+	return nil, fmt.Errorf("watch is not supported by %T", c.Client)
+}
+
 type interceptor struct {
 	client client.WithWatch
 	funcs  Funcs
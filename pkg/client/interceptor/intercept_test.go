@@ -11,9 +11,49 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+var _ = Describe("FromFuncs", func() {
+	ctx := context.Background()
+
+	It("should intercept the requests of real clients in the order of the interceptors", func() {
+		var calls []string
+		c, err := client.New(&rest.Config{Host: "http://localhost:0"}, client.Options{
+			Mapper: meta.NewDefaultRESTMapper(nil),
+			Interceptors: []client.Interceptor{
+				FromFuncs(Funcs{
+					Get: func(ctx context.Context, client client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						calls = append(calls, "outer")
+						return client.Get(ctx, key, obj, opts...)
+					},
+				}),
+				FromFuncs(Funcs{
+					Get: func(ctx context.Context, client client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+						calls = append(calls, "inner")
+						return nil
+					},
+				}),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: "foo"}, nil)).To(Succeed())
+		Expect(calls).To(Equal([]string{"outer", "inner"}))
+	})
+
+	It("should fail watches of clients that don't support them", func() {
+		c := FromFuncs(Funcs{})(dummyClient{})
+		_, err := c.(client.WithWatch).Watch(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		c = FromFuncs(Funcs{})(struct{ client.Client }{dummyClient{}})
+		_, err = c.(client.WithWatch).Watch(ctx, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 var _ = Describe("NewClient", func() {
 	wrappedClient := dummyClient{}
 	ctx := context.Background()
@@ -200,9 +200,22 @@ type FieldIndexer interface {
 	// and "equality" in the field selector means that at least one key matches the value.
 	// The FieldIndexer will automatically take care of indexing over namespace
 	// and supporting efficient all-namespace queries.
+	//
+	// Composite indexes over several fields, named with CompositeIndexField, serve
+	// lists matching all of these fields with a single lookup. See IndexFieldWithOptions
+	// for adding indexes with IndexOptions.
 	IndexField(ctx context.Context, obj Object, field string, extractValue IndexerFunc) error
 }
 
+// FieldIndexerWithOptions is a FieldIndexer that supports IndexOptions, like the
+// caches of the cache package.
+type FieldIndexerWithOptions interface {
+	FieldIndexer
+
+	// IndexFieldWithOptions adds an index like IndexField, configured with opts.
+	IndexFieldWithOptions(ctx context.Context, obj Object, field string, extractValue IndexerFunc, opts ...IndexOption) error
+}
+
 // IgnoreNotFound returns nil on NotFound errors.
 // All other values that are not NotFound errors or nil are returned unmodified.
 func IgnoreNotFound(err error) error {
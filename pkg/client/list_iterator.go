@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// DefaultPageSize is the number of objects listed per page by ListIterator and ForEach
+// if no PageSize or Limit option is given.
+const DefaultPageSize = 500
+
+// PageSize is the number of objects listed per page by ListIterator and ForEach. It is
+// equivalent to Limit.
+type PageSize int64
+
+// ApplyToList applies this configuration to the given list options.
+func (p PageSize) ApplyToList(opts *ListOptions) {
+	opts.Limit = int64(p)
+}
+
+// ListIterator iterates over the objects of a list page by page, following the continue
+// tokens returned by the API server, so that huge lists are never held in memory at
+// once:
+//
+//	it := client.NewListIterator(c, &corev1.PodList{}, client.InNamespace("default"))
+//	for it.Next(ctx) {
+//		pod := it.Object().(*corev1.Pod)
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Pagination is only supported by live reads, e.g. the APIReader of the manager. Readers
+// that don't return continue tokens, like the cache, only return the first page if a
+// PageSize or Limit option is given. Without one, a first page of DefaultPageSize objects
+// without a continue token is listed again without a limit, so that they return all
+// objects.
+type ListIterator struct {
+	reader Reader
+	list   ObjectList
+	opts   []ListOption
+	// unlimitedOpts are the options without the default page size, they are only set if
+	// defaultPageSize is.
+	unlimitedOpts   []ListOption
+	defaultPageSize bool
+
+	page          []Object
+	continueToken string
+	started       bool
+	err           error
+}
+
+// NewListIterator returns a ListIterator listing the objects of the type of list with
+// the reader. list is only used as a template and isn't modified.
+func NewListIterator(reader Reader, list ObjectList, opts ...ListOption) *ListIterator {
+	it := &ListIterator{reader: reader, list: list, opts: opts}
+	listOpts := ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Limit <= 0 {
+		it.defaultPageSize = true
+		it.unlimitedOpts = opts
+		it.opts = append(opts[:len(opts):len(opts)], PageSize(DefaultPageSize))
+	}
+	return it
+}
+
+// Next advances the iterator to the next object, listing the next page if needed. It
+// returns false once all objects were iterated over or listing a page failed, see Err.
+func (it *ListIterator) Next(ctx context.Context) bool {
+	if len(it.page) > 1 {
+		it.page = it.page[1:]
+		return true
+	}
+	it.page = nil
+	for it.err == nil && len(it.page) == 0 && (!it.started || it.continueToken != "") {
+		it.err = it.listPage(ctx)
+	}
+	return len(it.page) > 0
+}
+
+// Object returns the current object. The objects of each page are listed into a new
+// list, so they may be retained.
+func (it *ListIterator) Object() Object {
+	if len(it.page) == 0 {
+		return nil
+	}
+	return it.page[0]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// listPage lists the next page of objects.
+func (it *ListIterator) listPage(ctx context.Context) error {
+	list, ok := it.list.DeepCopyObject().(ObjectList)
+	if !ok {
+		return fmt.Errorf("unable to copy %T into a list", it.list)
+	}
+	firstPage := it.continueToken == ""
+	opts := it.opts
+	if !firstPage {
+		opts = append(opts[:len(opts):len(opts)], Continue(it.continueToken))
+	}
+	if err := it.reader.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	it.started = true
+	it.continueToken = list.GetContinue()
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	// A reader that doesn't paginate truncates the list to the default page size. This
+	// costs an additional List if exactly DefaultPageSize objects exist.
+	if firstPage && it.continueToken == "" && it.defaultPageSize && len(items) >= DefaultPageSize {
+		if err := it.reader.List(ctx, list, it.unlimitedOpts...); err != nil {
+			return err
+		}
+		if items, err = meta.ExtractList(list); err != nil {
+			return err
+		}
+	}
+	it.page = make([]Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(Object)
+		if !ok {
+			return fmt.Errorf("list item %T is not a client.Object", item)
+		}
+		it.page = append(it.page, obj)
+	}
+	return nil
+}
+
+// ForEach calls fn for every object of the type of list, listing them page by page with
+// a ListIterator. It stops at the first error returned by fn or a List call:
+//
+//	err := client.ForEach(ctx, c, &corev1.PodList{}, func(obj client.Object) error {
+//		...
+//	}, client.PageSize(100))
+func ForEach(ctx context.Context, reader Reader, list ObjectList, fn func(Object) error, opts ...ListOption) error {
+	it := NewListIterator(reader, list, opts...)
+	for it.Next(ctx) {
+		if err := fn(it.Object()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("ListIterator", func() {
+	var (
+		ctx      = context.Background()
+		cl       client.Client
+		requests []client.ListOptions
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		// The fake client doesn't paginate, so serve 5 config maps in pages.
+		cl = interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listOpts := client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				requests = append(requests, listOpts)
+
+				start := 0
+				if listOpts.Continue != "" {
+					start, _ = strconv.Atoi(listOpts.Continue)
+				}
+				end := min(start+int(listOpts.Limit), 5)
+				cms := list.(*corev1.ConfigMapList)
+				for i := start; i < end; i++ {
+					cms.Items = append(cms.Items, corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i)}})
+				}
+				if end < 5 {
+					cms.Continue = strconv.Itoa(end)
+				}
+				return nil
+			},
+		})
+	})
+
+	It("should iterate over all pages", func() {
+		var names []string
+		it := client.NewListIterator(cl, &corev1.ConfigMapList{}, client.InNamespace("default"), client.PageSize(2))
+		for it.Next(ctx) {
+			names = append(names, it.Object().GetName())
+		}
+		Expect(it.Err()).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"cm-0", "cm-1", "cm-2", "cm-3", "cm-4"}))
+
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[0].Continue).To(BeEmpty())
+		Expect(requests[1].Continue).To(Equal("2"))
+		Expect(requests[2].Continue).To(Equal("4"))
+		for _, req := range requests {
+			Expect(req.Namespace).To(Equal("default"))
+			Expect(req.Limit).To(BeEquivalentTo(2))
+		}
+	})
+
+	It("should use the default page size", func() {
+		Expect(client.ForEach(ctx, cl, &corev1.ConfigMapList{}, func(client.Object) error { return nil })).To(Succeed())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Limit).To(BeEquivalentTo(client.DefaultPageSize))
+	})
+
+	It("should list all objects of readers that don't paginate", func() {
+		var limits []int64
+		// Like the cache, truncate the list to the limit without returning a continue token.
+		cl = interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			List: func(_ context.Context, _ client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				listOpts := client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				limits = append(limits, listOpts.Limit)
+
+				end := client.DefaultPageSize + 1
+				if listOpts.Limit > 0 {
+					end = min(end, int(listOpts.Limit))
+				}
+				cms := list.(*corev1.ConfigMapList)
+				cms.Items = nil
+				for i := 0; i < end; i++ {
+					cms.Items = append(cms.Items, corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i)}})
+				}
+				return nil
+			},
+		})
+
+		var count int
+		Expect(client.ForEach(ctx, cl, &corev1.ConfigMapList{}, func(client.Object) error {
+			count++
+			return nil
+		})).To(Succeed())
+		Expect(count).To(Equal(client.DefaultPageSize + 1))
+		Expect(limits).To(Equal([]int64{client.DefaultPageSize, 0}))
+	})
+
+	It("should stop at the first error of the callback", func() {
+		var count int
+		err := client.ForEach(ctx, cl, &corev1.ConfigMapList{}, func(obj client.Object) error {
+			count++
+			if obj.GetName() == "cm-2" {
+				return errors.New("stop")
+			}
+			return nil
+		}, client.PageSize(2))
+		Expect(err).To(MatchError("stop"))
+		Expect(count).To(Equal(3))
+		Expect(requests).To(HaveLen(2))
+	})
+})
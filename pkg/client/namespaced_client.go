@@ -27,6 +27,10 @@ import (
 
 // NewNamespacedClient wraps an existing client enforcing the namespace value.
 // All functions using this client will have the same namespace declared here.
+// Namespace-scoped objects without a namespace are defaulted to it, while
+// requests for objects or lists in another namespace are rejected, so the
+// client can be used to isolate tenants or by operators watching a single
+// namespace.
 func NewNamespacedClient(c Client, ns string) Client {
 	return &namespacedClient{
 		client:    c,
@@ -124,6 +128,11 @@ func (n *namespacedClient) DeleteAllOf(ctx context.Context, obj Object, opts ...
 	}
 
 	if isNamespaceScoped {
+		deleteAllOfOpts := DeleteAllOfOptions{}
+		deleteAllOfOpts.ApplyOptions(opts)
+		if err := n.checkListNamespace(deleteAllOfOpts.Namespace); err != nil {
+			return err
+		}
 		opts = append(opts, InNamespace(n.namespace))
 	}
 	return n.client.DeleteAllOf(ctx, obj, opts...)
@@ -155,7 +164,7 @@ func (n *namespacedClient) Get(ctx context.Context, key ObjectKey, obj Object, o
 	}
 	if isNamespaceScoped {
 		if key.Namespace != "" && key.Namespace != n.namespace {
-			return fmt.Errorf("namespace %s provided for the object %s does not match the namespace %s on the client", key.Namespace, obj.GetName(), n.namespace)
+			return fmt.Errorf("namespace %s provided for the object %s does not match the namespace %s on the client", key.Namespace, key.Name, n.namespace)
 		}
 		key.Namespace = n.namespace
 	}
@@ -165,11 +174,25 @@ func (n *namespacedClient) Get(ctx context.Context, key ObjectKey, obj Object, o
 // List implements client.Client.
 func (n *namespacedClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
 	if n.namespace != "" {
+		listOpts := ListOptions{}
+		listOpts.ApplyOptions(opts)
+		if err := n.checkListNamespace(listOpts.Namespace); err != nil {
+			return err
+		}
 		opts = append(opts, InNamespace(n.namespace))
 	}
 	return n.client.List(ctx, obj, opts...)
 }
 
+// checkListNamespace returns an error if the namespace requested for a list or a
+// collection deletion is not the namespace of the client.
+func (n *namespacedClient) checkListNamespace(namespace string) error {
+	if namespace != "" && namespace != n.namespace {
+		return fmt.Errorf("namespace %s provided for the list does not match the namespace %s on the client", namespace, n.namespace)
+	}
+	return nil
+}
+
 // Status implements client.StatusClient.
 func (n *namespacedClient) Status() SubResourceWriter {
 	return n.SubResource("status")
@@ -125,14 +125,22 @@ var _ = Describe("NamespacedClient", func() {
 			Expect(result.Items[0]).To(BeEquivalentTo(*dep))
 		})
 
-		It("should List objects from the namespace specified in the client", func() {
+		It("should List objects when the namespace of the client is specified", func() {
 			result := &appsv1.DeploymentList{}
-			opts := client.InNamespace("non-default")
+			opts := client.InNamespace(ns)
 
 			Expect(getClient().List(ctx, result, opts)).NotTo(HaveOccurred())
 			Expect(len(result.Items)).To(BeEquivalentTo(1))
 			Expect(result.Items[0]).To(BeEquivalentTo(*dep))
 		})
+
+		It("should not List objects from other namespaces", func() {
+			result := &appsv1.DeploymentList{}
+			opts := client.InNamespace("non-default")
+
+			Expect(getClient().List(ctx, result, opts)).To(HaveOccurred())
+			Expect(result.Items).To(BeEmpty())
+		})
 	})
 
 	Describe("Create", func() {
@@ -470,6 +478,10 @@ var _ = Describe("NamespacedClient", func() {
 			err = getClient().DeleteAllOf(ctx, dep)
 			Expect(err).NotTo(HaveOccurred())
 
+			By("refusing to delete the deployments of the other namespace")
+			err = getClient().DeleteAllOf(ctx, dep, client.InNamespace(tns.Name))
+			Expect(err).To(HaveOccurred())
+
 			By("validating the Deployment exists")
 			actual, err := clientset.AppsV1().Deployments(tns.Name).Get(ctx, changedDep.Name, metav1.GetOptions{})
 			Expect(err).NotTo(HaveOccurred())
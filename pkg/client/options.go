@@ -376,6 +376,11 @@ type GetOptions struct {
 	// Raw represents raw GetOptions, as passed to the API server.  Note
 	// that these may not be respected by all implementations of interface.
 	Raw *metav1.GetOptions
+
+	// BypassCache indicates to read the object from the API server even if
+	// the client reads it from a cache otherwise.
+	// +optional
+	BypassCache bool
 }
 
 var _ GetOption = &GetOptions{}
@@ -385,6 +390,9 @@ func (o *GetOptions) ApplyToGet(lo *GetOptions) {
 	if o.Raw != nil {
 		lo.Raw = o.Raw
 	}
+	if o.BypassCache {
+		lo.BypassCache = true
+	}
 }
 
 // AsGetOptions returns these options as a flattened metav1.GetOptions.
@@ -443,6 +451,11 @@ type ListOptions struct {
 	// +optional
 	UnsafeDisableDeepCopy *bool
 
+	// BypassCache indicates to list the objects from the API server even if
+	// the client lists them from a cache otherwise.
+	// +optional
+	BypassCache bool
+
 	// Raw represents raw ListOptions, as passed to the API server.  Note
 	// that these may not be respected by all implementations of interface,
 	// and the LabelSelector, FieldSelector, Limit and Continue fields are ignored.
@@ -474,6 +487,9 @@ func (o *ListOptions) ApplyToList(lo *ListOptions) {
 	if o.UnsafeDisableDeepCopy != nil {
 		lo.UnsafeDisableDeepCopy = o.UnsafeDisableDeepCopy
 	}
+	if o.BypassCache {
+		lo.BypassCache = true
+	}
 }
 
 // AsListOptions returns these options as a flattened metav1.ListOptions.
@@ -651,6 +667,27 @@ func (d UnsafeDisableDeepCopyOption) ApplyToList(opts *ListOptions) {
 // UnsafeDisableDeepCopy indicates not to deep copy objects during list objects.
 const UnsafeDisableDeepCopy = UnsafeDisableDeepCopyOption(true)
 
+// BypassCacheOption indicates to read objects from the API server, even if the
+// client reads them from a cache otherwise, e.g. for a consistent read before a
+// decision that must not be taken on stale data. Clients without a cache ignore it.
+type BypassCacheOption bool
+
+// ApplyToGet applies this configuration to the given get options.
+func (b BypassCacheOption) ApplyToGet(opts *GetOptions) {
+	opts.BypassCache = bool(b)
+}
+
+// ApplyToList applies this configuration to the given list options.
+func (b BypassCacheOption) ApplyToList(opts *ListOptions) {
+	opts.BypassCache = bool(b)
+}
+
+// BypassCache indicates to read objects from the API server, even if the client
+// reads them from a cache otherwise:
+//
+//	err := c.Get(ctx, key, obj, client.BypassCache)
+const BypassCache = BypassCacheOption(true)
+
 // Continue sets a continuation token to retrieve chunks of results when using limit.
 // Continue does not implement DeleteAllOfOption interface because the server
 // does not support setting it for deletecollection operations.
@@ -840,3 +877,53 @@ func (o *DeleteAllOfOptions) ApplyToDeleteAllOf(do *DeleteAllOfOptions) {
 }
 
 // }}}
+
+// {{{ Index Options
+
+// IndexOption is some configuration that modifies the field indexes added with
+// IndexFieldWithOptions.
+type IndexOption interface {
+	// ApplyToIndex applies this configuration to the given index options.
+	ApplyToIndex(*IndexOptions)
+}
+
+// IndexOptions contains options for field indexes.
+type IndexOptions struct {
+	// Unique makes the index hold at most one object per key and namespace. As the
+	// objects in caches mirror the API server, objects with a duplicate key can't be
+	// rejected when they are indexed. Instead, lists by a key of the index, e.g. with
+	// GetByIndex, fail while several objects of a namespace have the key.
+	Unique bool
+}
+
+// ApplyOptions applies the given index options on these options,
+// and then returns itself (for convenient chaining).
+func (o *IndexOptions) ApplyOptions(opts []IndexOption) *IndexOptions {
+	for _, opt := range opts {
+		opt.ApplyToIndex(o)
+	}
+	return o
+}
+
+// ApplyToIndex implements IndexOption.
+func (o *IndexOptions) ApplyToIndex(io *IndexOptions) {
+	if o.Unique {
+		io.Unique = true
+	}
+}
+
+var _ IndexOption = &IndexOptions{}
+
+// UniqueIndex makes an index hold at most one object per key and namespace, see
+// IndexOptions.Unique. Duplicate keys are only detected when a key is listed, not
+// when the index is added or the objects are indexed.
+var UniqueIndex = uniqueIndex{}
+
+type uniqueIndex struct{}
+
+// ApplyToIndex applies this configuration to the given index options.
+func (uniqueIndex) ApplyToIndex(opts *IndexOptions) {
+	opts.Unique = true
+}
+
+// }}}
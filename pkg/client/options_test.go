@@ -66,6 +66,12 @@ var _ = Describe("ListOptions", func() {
 		o.ApplyToList(newListOpts)
 		Expect(newListOpts).To(Equal(o))
 	})
+	It("Should set BypassCache", func() {
+		o := &client.ListOptions{BypassCache: true}
+		newListOpts := &client.ListOptions{}
+		o.ApplyToList(newListOpts)
+		Expect(newListOpts).To(Equal(o))
+	})
 	It("Should not set anything", func() {
 		o := &client.ListOptions{}
 		newListOpts := &client.ListOptions{}
@@ -81,6 +87,12 @@ var _ = Describe("GetOptions", func() {
 		o.ApplyToGet(newGetOpts)
 		Expect(newGetOpts).To(Equal(o))
 	})
+	It("Should set BypassCache", func() {
+		o := &client.GetOptions{BypassCache: true}
+		newGetOpts := &client.GetOptions{}
+		o.ApplyToGet(newGetOpts)
+		Expect(newGetOpts).To(Equal(o))
+	})
 })
 
 var _ = Describe("CreateOptions", func() {
@@ -291,6 +303,19 @@ var _ = Describe("FieldOwner", func() {
 	})
 })
 
+var _ = Describe("BypassCache", func() {
+	It("Should apply to GetOptions", func() {
+		o := &client.GetOptions{}
+		client.BypassCache.ApplyToGet(o)
+		Expect(o.BypassCache).To(BeTrue())
+	})
+	It("Should apply to ListOptions", func() {
+		o := &client.ListOptions{}
+		client.BypassCache.ApplyToList(o)
+		Expect(o.BypassCache).To(BeTrue())
+	})
+})
+
 var _ = Describe("ForceOwnership", func() {
 	It("Should apply to PatchOptions", func() {
 		o := &client.PatchOptions{}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// WithRetryOnConflict wraps an existing client so that updates and patches passed the
+// ReapplyOnConflict option are retried with the given backoff when they fail with a
+// conflict, e.g. retry.DefaultRetry. Before every retry the latest version of the object
+// is read into it and the mutation of the option is applied to it again:
+//
+//	deploy.Spec.Replicas = ptr.To[int32](3)
+//	err := c.Update(ctx, deploy, client.ReapplyOnConflict(func() error {
+//		deploy.Spec.Replicas = ptr.To[int32](3)
+//		return nil
+//	}))
+//
+// This replaces wrapping the Get, the mutation and the write into retry.RetryOnConflict.
+// Updates and patches of subresources, e.g. of the status, are retried alike, while the
+// object is read from the main resource. Patches are only retried if they were created
+// with MergeFrom, MergeFromWithOptions or StrategicMergeFrom, in which case the patch is
+// computed against the latest version of the object. All other requests are passed
+// through unchanged.
+func WithRetryOnConflict(c Client, backoff wait.Backoff) Client {
+	return &retryOnConflictClient{client: c, backoff: backoff}
+}
+
+// ReapplyOnConflict is an option of updates and patches of the client returned by
+// WithRetryOnConflict, holding the mutation that is applied again to the latest version
+// of the object before an update or a patch is retried after a conflict. The mutation
+// is not applied before the first attempt, so other clients ignore it.
+type ReapplyOnConflict func() error
+
+// ApplyToUpdate implements UpdateOption.
+func (ReapplyOnConflict) ApplyToUpdate(*UpdateOptions) {}
+
+// ApplyToPatch implements PatchOption.
+func (ReapplyOnConflict) ApplyToPatch(*PatchOptions) {}
+
+// ApplyToSubResourceUpdate implements SubResourceUpdateOption.
+func (ReapplyOnConflict) ApplyToSubResourceUpdate(*SubResourceUpdateOptions) {}
+
+// ApplyToSubResourcePatch implements SubResourcePatchOption.
+func (ReapplyOnConflict) ApplyToSubResourcePatch(*SubResourcePatchOptions) {}
+
+var _ Client = &retryOnConflictClient{}
+
+// retryOnConflictClient is a Client that wraps another Client in order to retry
+// updates and patches on conflicts.
+type retryOnConflictClient struct {
+	client  Client
+	backoff wait.Backoff
+}
+
+// Scheme returns the scheme this client is using.
+func (c *retryOnConflictClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *retryOnConflictClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *retryOnConflictClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *retryOnConflictClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *retryOnConflictClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	return c.client.Create(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *retryOnConflictClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	mutate := reapplyOnConflictFrom(opts)
+	if mutate == nil {
+		return c.client.Update(ctx, obj, opts...)
+	}
+	return c.retry(ctx, obj, mutate, nil, func() error {
+		return c.client.Update(ctx, obj, opts...)
+	})
+}
+
+// Delete implements client.Client.
+func (c *retryOnConflictClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	return c.client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *retryOnConflictClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	return c.client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *retryOnConflictClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	mutate := reapplyOnConflictFrom(opts)
+	mergePatch, isMergePatch := patch.(*mergeFromPatch)
+	if mutate == nil || !isMergePatch {
+		return c.client.Patch(ctx, obj, patch, opts...)
+	}
+	return c.retry(ctx, obj, mutate, func() {
+		mergePatch = rebasePatch(mergePatch, obj)
+	}, func() error {
+		return c.client.Patch(ctx, obj, mergePatch, opts...)
+	})
+}
+
+// Get implements client.Client.
+func (c *retryOnConflictClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *retryOnConflictClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
+	return c.client.List(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient.
+func (c *retryOnConflictClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClient.
+func (c *retryOnConflictClient) SubResource(subResource string) SubResourceClient {
+	return &retryOnConflictSubResourceClient{client: c.client.SubResource(subResource), parent: c}
+}
+
+// retry calls write until it doesn't fail with a conflict or the backoff is exhausted.
+// Before every retry, the latest version of obj is read, passed to refetched if set,
+// and mutate is applied to it.
+func (c *retryOnConflictClient) retry(ctx context.Context, obj Object, mutate ReapplyOnConflict, refetched func(), write func() error) error {
+	attempt := 0
+	return retry.RetryOnConflict(c.backoff, func() error {
+		attempt++
+		if attempt > 1 {
+			if err := c.client.Get(ctx, ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+			if refetched != nil {
+				refetched()
+			}
+			if err := mutate(); err != nil {
+				return err
+			}
+		}
+		return write()
+	})
+}
+
+// ensure retryOnConflictSubResourceClient implements client.SubResourceClient.
+var _ SubResourceClient = &retryOnConflictSubResourceClient{}
+
+// retryOnConflictSubResourceClient is a client.SubResourceClient that retries updates
+// and patches on conflicts.
+type retryOnConflictSubResourceClient struct {
+	client SubResourceClient
+	parent *retryOnConflictClient
+}
+
+func (sw *retryOnConflictSubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	return sw.client.Get(ctx, obj, subResource, opts...)
+}
+
+func (sw *retryOnConflictSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	return sw.client.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sw *retryOnConflictSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	mutate := reapplyOnConflictFrom(opts)
+	if mutate == nil {
+		return sw.client.Update(ctx, obj, opts...)
+	}
+	return sw.parent.retry(ctx, obj, mutate, nil, func() error {
+		return sw.client.Update(ctx, obj, opts...)
+	})
+}
+
+// Patch implements client.SubResourceWriter.
+func (sw *retryOnConflictSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	mutate := reapplyOnConflictFrom(opts)
+	mergePatch, isMergePatch := patch.(*mergeFromPatch)
+	if mutate == nil || !isMergePatch {
+		return sw.client.Patch(ctx, obj, patch, opts...)
+	}
+	return sw.parent.retry(ctx, obj, mutate, func() {
+		mergePatch = rebasePatch(mergePatch, obj)
+	}, func() error {
+		return sw.client.Patch(ctx, obj, mergePatch, opts...)
+	})
+}
+
+// rebasePatch returns a copy of patch that is computed against a copy of obj, which
+// was read again after a conflict and not mutated yet.
+func rebasePatch(patch *mergeFromPatch, obj Object) *mergeFromPatch {
+	return &mergeFromPatch{
+		patchType:   patch.patchType,
+		createPatch: patch.createPatch,
+		from:        obj.DeepCopyObject().(Object),
+		opts:        patch.opts,
+	}
+}
+
+// reapplyOnConflictFrom returns the last ReapplyOnConflict option of opts, if any.
+func reapplyOnConflictFrom[T any](opts []T) ReapplyOnConflict {
+	var mutate ReapplyOnConflict
+	for _, opt := range opts {
+		if m, ok := any(opt).(ReapplyOnConflict); ok {
+			mutate = m
+		}
+	}
+	return mutate
+}
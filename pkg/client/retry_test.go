@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("RetryOnConflictClient", func() {
+	var (
+		ctx        = context.Background()
+		underlying client.Client
+		cl         client.Client
+		stale      *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"}}
+		underlying = fake.NewClientBuilder().WithObjects(pod).WithStatusSubresource(pod).Build()
+		cl = client.WithRetryOnConflict(underlying, retry.DefaultRetry)
+
+		stale = &corev1.Pod{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(pod), stale)).To(Succeed())
+
+		// Someone else updates the pod in the meantime.
+		current := stale.DeepCopy()
+		current.Labels = map[string]string{"other": "value"}
+		Expect(underlying.Update(ctx, current)).To(Succeed())
+	})
+
+	It("should re-apply the mutation to the latest version of the object on conflicts", func() {
+		setLabel := func() error {
+			if stale.Labels == nil {
+				stale.Labels = map[string]string{}
+			}
+			stale.Labels["mine"] = "value"
+			return nil
+		}
+		Expect(setLabel()).To(Succeed())
+		Expect(cl.Update(ctx, stale, client.ReapplyOnConflict(setLabel))).To(Succeed())
+
+		updated := &corev1.Pod{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(stale), updated)).To(Succeed())
+		Expect(updated.Labels).To(Equal(map[string]string{"other": "value", "mine": "value"}))
+	})
+
+	It("should not retry updates without the mutation", func() {
+		stale.Labels = map[string]string{"mine": "value"}
+		err := cl.Update(ctx, stale)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+	})
+
+	It("should recompute merge patches against the latest version of the object", func() {
+		patch := client.MergeFromWithOptions(stale.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		setAnnotation := func() error {
+			stale.Annotations = map[string]string{"mine": "value"}
+			return nil
+		}
+		Expect(setAnnotation()).To(Succeed())
+		Expect(cl.Patch(ctx, stale, patch, client.ReapplyOnConflict(setAnnotation))).To(Succeed())
+
+		patched := &corev1.Pod{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(stale), patched)).To(Succeed())
+		Expect(patched.Labels).To(Equal(map[string]string{"other": "value"}))
+		Expect(patched.Annotations).To(Equal(map[string]string{"mine": "value"}))
+	})
+
+	It("should retry status updates", func() {
+		setPhase := func() error {
+			stale.Status.Phase = corev1.PodRunning
+			return nil
+		}
+		Expect(setPhase()).To(Succeed())
+		Expect(cl.Status().Update(ctx, stale, client.ReapplyOnConflict(setPhase))).To(Succeed())
+
+		updated := &corev1.Pod{}
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(stale), updated)).To(Succeed())
+		Expect(updated.Status.Phase).To(Equal(corev1.PodRunning))
+		Expect(updated.Labels).To(Equal(map[string]string{"other": "value"}))
+	})
+})
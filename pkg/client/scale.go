@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GetScale returns the scale subresource of obj, which can be an object of any scalable
+// resource, e.g. a Deployment, a StatefulSet or a custom resource with the scale
+// subresource enabled. Only the namespace and the name of obj are used, its resource
+// is resolved with the RESTMapper of c. obj can be typed or unstructured.
+func GetScale(ctx context.Context, c SubResourceClientConstructor, obj Object, opts ...SubResourceGetOption) (*autoscalingv1.Scale, error) {
+	body := newScaleBody(obj)
+	if err := c.SubResource("scale").Get(ctx, obj, body, opts...); err != nil {
+		return nil, err
+	}
+	return scaleFromBody(body)
+}
+
+// Scale sets the desired number of replicas of obj through its scale subresource, see
+// GetScale. The replicas are set with a merge patch, so that the change doesn't
+// conflict with concurrent changes of obj.
+func Scale(ctx context.Context, c SubResourceClientConstructor, obj Object, replicas int32, opts ...SubResourcePatchOption) error {
+	patch := RawPatch(types.MergePatchType, []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)))
+	opts = append(opts[:len(opts):len(opts)], WithSubResourceBody(newScaleBody(obj)))
+	return c.SubResource("scale").Patch(ctx, obj, patch, opts...)
+}
+
+// newScaleBody returns the Scale to read the scale subresource of obj into, which is
+// unstructured if obj is, as the unstructured client only decodes unstructured objects.
+func newScaleBody(obj Object) Object {
+	if _, isUnstructured := obj.(runtime.Unstructured); isUnstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(autoscalingv1.SchemeGroupVersion.WithKind("Scale"))
+		return u
+	}
+	return &autoscalingv1.Scale{}
+}
+
+// scaleFromBody returns the Scale read into a body returned by newScaleBody.
+func scaleFromBody(body Object) (*autoscalingv1.Scale, error) {
+	u, isUnstructured := body.(*unstructured.Unstructured)
+	if !isUnstructured {
+		return body.(*autoscalingv1.Scale), nil
+	}
+	scale := &autoscalingv1.Scale{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, scale); err != nil {
+		return nil, fmt.Errorf("failed to convert %s to Scale: %w", u.GroupVersionKind(), err)
+	}
+	return scale, nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Scale", func() {
+	type request struct {
+		method, path, contentType, body string
+	}
+
+	var (
+		requests chan request
+		cl       client.Client
+	)
+
+	fooGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+
+	BeforeEach(func() {
+		requests = make(chan request, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			requests <- request{method: r.Method, path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: string(body)}
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(&autoscalingv1.Scale{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "obj"},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: 3},
+				Status:     autoscalingv1.ScaleStatus{Replicas: 2, Selector: "app=obj"},
+			})).To(Succeed())
+		}))
+		DeferCleanup(server.Close)
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+		mapper.Add(fooGVK, meta.RESTScopeNamespace)
+
+		var err error
+		cl, err = client.New(&rest.Config{Host: server.URL}, client.Options{Mapper: mapper})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should get the scale of typed objects", func() {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "obj"}}
+		scale, err := client.GetScale(context.Background(), cl, dep)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scale.Spec.Replicas).To(BeEquivalentTo(3))
+		Expect(scale.Status.Selector).To(Equal("app=obj"))
+
+		var req request
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.method).To(Equal(http.MethodGet))
+		Expect(req.path).To(Equal("/apis/apps/v1/namespaces/default/deployments/obj/scale"))
+	})
+
+	It("should get the scale of unstructured objects", func() {
+		foo := &unstructured.Unstructured{}
+		foo.SetGroupVersionKind(fooGVK)
+		foo.SetNamespace("default")
+		foo.SetName("obj")
+		scale, err := client.GetScale(context.Background(), cl, foo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scale.Status.Replicas).To(BeEquivalentTo(2))
+		Expect(foo.GroupVersionKind()).To(Equal(fooGVK))
+
+		var req request
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.path).To(Equal("/apis/example.com/v1/namespaces/default/foos/obj/scale"))
+	})
+
+	It("should set the replicas of typed objects", func() {
+		dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "obj"}}
+		Expect(client.Scale(context.Background(), cl, dep, 3)).To(Succeed())
+
+		var req request
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.method).To(Equal(http.MethodPatch))
+		Expect(req.path).To(Equal("/apis/apps/v1/namespaces/default/deployments/obj/scale"))
+		Expect(req.contentType).To(Equal("application/merge-patch+json"))
+		Expect(req.body).To(MatchJSON(`{"spec":{"replicas":3}}`))
+	})
+
+	It("should set the replicas of unstructured objects", func() {
+		foo := &unstructured.Unstructured{}
+		foo.SetGroupVersionKind(fooGVK)
+		foo.SetNamespace("default")
+		foo.SetName("obj")
+		Expect(client.Scale(context.Background(), cl, foo, 3)).To(Succeed())
+		Expect(foo.GroupVersionKind()).To(Equal(fooGVK))
+
+		var req request
+		Eventually(requests).Should(Receive(&req))
+		Expect(req.method).To(Equal(http.MethodPatch))
+		Expect(req.path).To(Equal("/apis/example.com/v1/namespaces/default/foos/obj/scale"))
+		Expect(req.body).To(MatchJSON(`{"spec":{"replicas":3}}`))
+	})
+})
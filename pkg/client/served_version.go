@@ -0,0 +1,382 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// NewServedVersionClient wraps an existing client so that typed objects of a
+// version the target cluster does not serve are converted to a served version
+// before they are sent, and converted back once the response is received.
+//
+// This allows a controller built against a newer version of a CRD to operate
+// on clusters that only serve an older version of it. The served versions are
+// discovered through the client's RESTMapper, and the conversion goes through
+// the scheme: types implementing conversion.Hub and conversion.Convertible are
+// converted through the hub, all other types through the conversion functions
+// registered with the scheme. A version is only picked as a target if its type
+// is registered with the scheme.
+//
+// Unstructured and metadata-only objects are passed through unchanged, as is
+// any object whose version is served. Patch requests can not be converted, as
+// the patch is computed against the unserved version, and therefore fail with
+// an error for objects of an unserved version.
+func NewServedVersionClient(c Client) Client {
+	return &servedVersionClient{client: c}
+}
+
+var _ Client = &servedVersionClient{}
+
+// servedVersionClient is a Client that wraps another Client in order to
+// convert objects to a version served by the target cluster.
+type servedVersionClient struct {
+	client Client
+}
+
+// Scheme returns the scheme this client is using.
+func (c *servedVersionClient) Scheme() *runtime.Scheme {
+	return c.client.Scheme()
+}
+
+// RESTMapper returns the rest mapper this client is using.
+func (c *servedVersionClient) RESTMapper() meta.RESTMapper {
+	return c.client.RESTMapper()
+}
+
+// GroupVersionKindFor returns the GroupVersionKind for the given object.
+func (c *servedVersionClient) GroupVersionKindFor(obj runtime.Object) (schema.GroupVersionKind, error) {
+	return c.client.GroupVersionKindFor(obj)
+}
+
+// IsObjectNamespaced returns true if the GroupVersionKind of the object is namespaced.
+func (c *servedVersionClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
+	return c.client.IsObjectNamespaced(obj)
+}
+
+// Create implements client.Client.
+func (c *servedVersionClient) Create(ctx context.Context, obj Object, opts ...CreateOption) error {
+	served, err := c.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return c.client.Create(ctx, obj, opts...)
+	}
+	if err := c.client.Create(ctx, served, opts...); err != nil {
+		return err
+	}
+	return c.fromServedVersion(served, obj)
+}
+
+// Update implements client.Client.
+func (c *servedVersionClient) Update(ctx context.Context, obj Object, opts ...UpdateOption) error {
+	served, err := c.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return c.client.Update(ctx, obj, opts...)
+	}
+	if err := c.client.Update(ctx, served, opts...); err != nil {
+		return err
+	}
+	return c.fromServedVersion(served, obj)
+}
+
+// Delete implements client.Client.
+func (c *servedVersionClient) Delete(ctx context.Context, obj Object, opts ...DeleteOption) error {
+	served, err := c.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return c.client.Delete(ctx, obj, opts...)
+	}
+	return c.client.Delete(ctx, served, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *servedVersionClient) DeleteAllOf(ctx context.Context, obj Object, opts ...DeleteAllOfOption) error {
+	served, err := c.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return c.client.DeleteAllOf(ctx, obj, opts...)
+	}
+	return c.client.DeleteAllOf(ctx, served, opts...)
+}
+
+// Patch implements client.Client.
+func (c *servedVersionClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error {
+	if err := c.ensureServed(obj); err != nil {
+		return err
+	}
+	return c.client.Patch(ctx, obj, patch, opts...)
+}
+
+// Get implements client.Client.
+func (c *servedVersionClient) Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error {
+	served, err := c.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return c.client.Get(ctx, key, obj, opts...)
+	}
+	if err := c.client.Get(ctx, key, served, opts...); err != nil {
+		return err
+	}
+	return c.fromServedVersion(served, obj)
+}
+
+// List implements client.Client.
+func (c *servedVersionClient) List(ctx context.Context, obj ObjectList, opts ...ListOption) error {
+	gvk, target, err := c.servedVersionFor(obj)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return c.client.List(ctx, obj, opts...)
+	}
+
+	servedList, err := c.client.Scheme().New(target.GroupVersion().WithKind(target.Kind + "List"))
+	if err != nil {
+		return err
+	}
+	served, ok := servedList.(ObjectList)
+	if !ok {
+		return fmt.Errorf("%T is not a client.ObjectList", servedList)
+	}
+	if err := c.client.List(ctx, served, opts...); err != nil {
+		return err
+	}
+
+	items, err := meta.ExtractList(served)
+	if err != nil {
+		return err
+	}
+	converted := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		out, err := c.client.Scheme().New(gvk)
+		if err != nil {
+			return err
+		}
+		if err := convertVersion(c.client.Scheme(), item, out); err != nil {
+			return err
+		}
+		converted = append(converted, out)
+	}
+	if err := meta.SetList(obj, converted); err != nil {
+		return err
+	}
+	obj.SetResourceVersion(served.GetResourceVersion())
+	obj.SetContinue(served.GetContinue())
+	obj.SetRemainingItemCount(served.GetRemainingItemCount())
+	return nil
+}
+
+// Status implements client.StatusClient.
+func (c *servedVersionClient) Status() SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClient.
+func (c *servedVersionClient) SubResource(subResource string) SubResourceClient {
+	return &servedVersionSubResourceClient{client: c.client.SubResource(subResource), parent: c}
+}
+
+// ensure servedVersionSubResourceClient implements client.SubResourceClient.
+var _ SubResourceClient = &servedVersionSubResourceClient{}
+
+// servedVersionSubResourceClient is a client.SubResourceClient that converts
+// objects to a served version before updating them.
+type servedVersionSubResourceClient struct {
+	client SubResourceClient
+	parent *servedVersionClient
+}
+
+func (sw *servedVersionSubResourceClient) Get(ctx context.Context, obj, subResource Object, opts ...SubResourceGetOption) error {
+	return sw.client.Get(ctx, obj, subResource, opts...)
+}
+
+func (sw *servedVersionSubResourceClient) Create(ctx context.Context, obj, subResource Object, opts ...SubResourceCreateOption) error {
+	return sw.client.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (sw *servedVersionSubResourceClient) Update(ctx context.Context, obj Object, opts ...SubResourceUpdateOption) error {
+	served, err := sw.parent.toServedVersion(obj)
+	if err != nil {
+		return err
+	}
+	if served == nil {
+		return sw.client.Update(ctx, obj, opts...)
+	}
+	if err := sw.client.Update(ctx, served, opts...); err != nil {
+		return err
+	}
+	return sw.parent.fromServedVersion(served, obj)
+}
+
+// Patch implements client.SubResourceWriter.
+func (sw *servedVersionSubResourceClient) Patch(ctx context.Context, obj Object, patch Patch, opts ...SubResourcePatchOption) error {
+	if err := sw.parent.ensureServed(obj); err != nil {
+		return err
+	}
+	return sw.client.Patch(ctx, obj, patch, opts...)
+}
+
+// servedVersionFor returns the GroupVersionKind of obj and, if that version is
+// not served by the cluster, the GroupVersionKind of the served version obj
+// should be converted to. For lists, the GroupVersionKinds of the items are
+// returned. A nil target means that obj can be sent as is.
+func (c *servedVersionClient) servedVersionFor(obj runtime.Object) (schema.GroupVersionKind, *schema.GroupVersionKind, error) {
+	switch obj.(type) {
+	case runtime.Unstructured, *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		return schema.GroupVersionKind{}, nil, nil
+	}
+
+	gvk, err := c.client.GroupVersionKindFor(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, nil, err
+	}
+	if _, isList := obj.(ObjectList); isList {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+
+	mappings, err := c.client.RESTMapper().RESTMappings(gvk.GroupKind())
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// Let the wrapped client surface the error.
+			return gvk, nil, nil
+		}
+		return schema.GroupVersionKind{}, nil, err
+	}
+	for _, mapping := range mappings {
+		if mapping.GroupVersionKind == gvk {
+			return gvk, nil, nil
+		}
+	}
+	for _, mapping := range mappings {
+		if c.client.Scheme().Recognizes(mapping.GroupVersionKind) {
+			target := mapping.GroupVersionKind
+			return gvk, &target, nil
+		}
+	}
+	return gvk, nil, fmt.Errorf("version %q of %s is not served and none of the served versions is registered with the scheme", gvk.Version, gvk.GroupKind())
+}
+
+// ensureServed returns an error if obj is of a version not served by the cluster.
+func (c *servedVersionClient) ensureServed(obj Object) error {
+	gvk, target, err := c.servedVersionFor(obj)
+	if err != nil {
+		return err
+	}
+	if target != nil {
+		return fmt.Errorf("can not patch %s: version %q is not served, only %q is", gvk.GroupKind(), gvk.Version, target.Version)
+	}
+	return nil
+}
+
+// toServedVersion converts obj to a served version. It returns nil if obj can be
+// sent as is.
+func (c *servedVersionClient) toServedVersion(obj Object) (Object, error) {
+	_, target, err := c.servedVersionFor(obj)
+	if err != nil || target == nil {
+		return nil, err
+	}
+
+	out, err := c.client.Scheme().New(*target)
+	if err != nil {
+		return nil, err
+	}
+	served, ok := out.(Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", out)
+	}
+	if err := convertVersion(c.client.Scheme(), obj, served); err != nil {
+		return nil, fmt.Errorf("failed to convert %s to version %q: %w", target.GroupKind(), target.Version, err)
+	}
+	return served, nil
+}
+
+// fromServedVersion converts served back into obj, keeping the TypeMeta of obj.
+func (c *servedVersionClient) fromServedVersion(served, obj Object) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if err := convertVersion(c.client.Scheme(), served, obj); err != nil {
+		return fmt.Errorf("failed to convert %s back to version %q: %w", gvk.GroupKind(), gvk.Version, err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// convertVersion converts src into dst, which must be different versions of
+// the same kind.
+func convertVersion(scheme *runtime.Scheme, src, dst runtime.Object) error {
+	srcConvertible, srcIsConvertible := src.(conversion.Convertible)
+	dstConvertible, dstIsConvertible := dst.(conversion.Convertible)
+
+	if hub, ok := dst.(conversion.Hub); ok && srcIsConvertible {
+		return srcConvertible.ConvertTo(hub)
+	}
+	if hub, ok := src.(conversion.Hub); ok && dstIsConvertible {
+		return dstConvertible.ConvertFrom(hub)
+	}
+	if srcIsConvertible && dstIsConvertible {
+		gvks, _, err := scheme.ObjectKinds(src)
+		if err != nil {
+			return err
+		}
+		hub, err := hubFor(scheme, gvks[0].GroupKind())
+		if err != nil {
+			return err
+		}
+		if err := srcConvertible.ConvertTo(hub); err != nil {
+			return err
+		}
+		return dstConvertible.ConvertFrom(hub)
+	}
+	return scheme.Convert(src, dst, nil)
+}
+
+// hubFor returns a new instance of the hub version of the given kind.
+func hubFor(scheme *runtime.Scheme, gk schema.GroupKind) (conversion.Hub, error) {
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.GroupKind() != gk {
+			continue
+		}
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if hub, ok := obj.(conversion.Hub); ok {
+			return hub, nil
+		}
+	}
+	return nil, fmt.Errorf("no hub version registered for %s", gk)
+}
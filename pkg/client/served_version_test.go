@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	jobsv1 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v1"
+	jobsv2 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v2"
+	jobsv3 "sigs.k8s.io/controller-runtime/pkg/webhook/conversion/testdata/api/v3"
+)
+
+var _ = Describe("ServedVersionClient", func() {
+	var (
+		ctx        = context.Background()
+		underlying client.Client
+		cl         client.Client
+	)
+
+	BeforeEach(func() {
+		s := runtime.NewScheme()
+		Expect(jobsv1.AddToScheme(s)).To(Succeed())
+		Expect(jobsv2.AddToScheme(s)).To(Succeed())
+		Expect(jobsv3.AddToScheme(s)).To(Succeed())
+
+		// The cluster only serves v1 of the ExternalJob CRD.
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{jobsv1.GroupVersion})
+		mapper.Add(jobsv1.GroupVersion.WithKind("ExternalJob"), meta.RESTScopeNamespace)
+
+		underlying = fake.NewClientBuilder().
+			WithScheme(s).
+			WithRESTMapper(mapper).
+			WithStatusSubresource(&jobsv1.ExternalJob{}).
+			Build()
+		cl = client.NewServedVersionClient(underlying)
+	})
+
+	newJob := func() *jobsv2.ExternalJob {
+		return &jobsv2.ExternalJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"},
+			Spec:       jobsv2.ExternalJobSpec{ScheduleAt: "now"},
+		}
+	}
+
+	It("should convert created objects to the served version", func() {
+		job := newJob()
+		Expect(cl.Create(ctx, job)).To(Succeed())
+		Expect(job.ResourceVersion).NotTo(BeEmpty())
+		Expect(job.Spec.ScheduleAt).To(Equal("now"))
+
+		stored := &jobsv1.ExternalJob{}
+		Expect(underlying.Get(ctx, client.ObjectKeyFromObject(job), stored)).To(Succeed())
+		Expect(stored.Spec.RunAt).To(Equal("now"))
+	})
+
+	It("should convert updated objects to the served version", func() {
+		job := newJob()
+		Expect(cl.Create(ctx, job)).To(Succeed())
+
+		job.Spec.ScheduleAt = "later"
+		Expect(cl.Update(ctx, job)).To(Succeed())
+
+		stored := &jobsv1.ExternalJob{}
+		Expect(underlying.Get(ctx, client.ObjectKeyFromObject(job), stored)).To(Succeed())
+		Expect(stored.Spec.RunAt).To(Equal("later"))
+		Expect(stored.ResourceVersion).To(Equal(job.ResourceVersion))
+	})
+
+	It("should convert status updates to the served version", func() {
+		job := newJob()
+		Expect(cl.Create(ctx, job)).To(Succeed())
+		Expect(cl.Status().Update(ctx, job)).To(Succeed())
+	})
+
+	It("should read objects of an unserved version through the hub", func() {
+		Expect(underlying.Create(ctx, &jobsv1.ExternalJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"},
+			Spec:       jobsv1.ExternalJobSpec{RunAt: "now"},
+		})).To(Succeed())
+
+		job := &jobsv3.ExternalJob{}
+		Expect(cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "job"}, job)).To(Succeed())
+		Expect(job.Spec.DeferredAt).To(Equal("now"))
+
+		jobs := &jobsv2.ExternalJobList{}
+		Expect(cl.List(ctx, jobs)).To(Succeed())
+		Expect(jobs.Items).To(HaveLen(1))
+		Expect(jobs.Items[0].Spec.ScheduleAt).To(Equal("now"))
+	})
+
+	It("should delete objects of an unserved version", func() {
+		job := newJob()
+		Expect(cl.Create(ctx, job)).To(Succeed())
+		Expect(cl.Delete(ctx, job)).To(Succeed())
+
+		jobs := &jobsv1.ExternalJobList{}
+		Expect(underlying.List(ctx, jobs)).To(Succeed())
+		Expect(jobs.Items).To(BeEmpty())
+	})
+
+	It("should refuse to patch objects of an unserved version", func() {
+		job := newJob()
+		Expect(cl.Create(ctx, job)).To(Succeed())
+
+		patch := client.MergeFrom(job.DeepCopy())
+		job.Spec.ScheduleAt = "later"
+		Expect(cl.Patch(ctx, job, patch)).NotTo(Succeed())
+	})
+
+	It("should pass objects of a served version through", func() {
+		job := &jobsv1.ExternalJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "default"},
+			Spec:       jobsv1.ExternalJobSpec{RunAt: "now"},
+		}
+		Expect(cl.Create(ctx, job)).To(Succeed())
+
+		patch := client.MergeFrom(job.DeepCopy())
+		job.Spec.RunAt = "later"
+		Expect(cl.Patch(ctx, job, patch)).To(Succeed())
+		Expect(job.Spec.RunAt).To(Equal("later"))
+	})
+})
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// WatchEvent is an event of a watch started with Watch.
+type WatchEvent[T Object] struct {
+	// Type is Added, Modified, Deleted or Error.
+	Type watch.EventType
+
+	// Object is the added, modified or deleted object. It is unset for Error events.
+	Object T
+
+	// Err is the error that stopped the watch for Error events.
+	Err error
+}
+
+// Watch watches the objects of the type of list, which must be the list of T, and
+// returns the channel of their events:
+//
+//	events, err := client.Watch[*corev1.Pod](ctx, c, &corev1.PodList{}, client.InNamespace("default"))
+//	...
+//	for event := range events {
+//		...
+//	}
+//
+// Unlike WithWatch.Watch, the watch is re-established from the last observed resource
+// version when the API server closes it, and from the current state of the objects if
+// that resource version is too old. Bookmarks are requested to keep track of the
+// resource version, but are not passed on. Watches are not served by the cache, they
+// are meant for short-lived watches of the API server.
+//
+// The channel is closed once ctx is done, or after an Error event if the watch can't be
+// re-established. Errors starting the watch are returned directly.
+func Watch[T Object](ctx context.Context, c WithWatch, list ObjectList, opts ...ListOption) (<-chan WatchEvent[T], error) {
+	listOpts := ListOptions{}
+	listOpts.ApplyOptions(opts)
+	listOpts.Raw = listOpts.AsListOptions().DeepCopy()
+	listOpts.Raw.AllowWatchBookmarks = true
+
+	tw := &typedWatch[T]{client: c, list: list, listOpts: listOpts, resourceVersion: listOpts.Raw.ResourceVersion}
+	w, err := tw.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan WatchEvent[T])
+	go func() {
+		defer close(events)
+		tw.run(ctx, w, events)
+	}()
+	return events, nil
+}
+
+// typedWatchRestartDelay is the delay before re-establishing a watch that was closed
+// without receiving any event.
+const typedWatchRestartDelay = time.Second
+
+// typedWatch is a watch re-established from the last observed resource version.
+type typedWatch[T Object] struct {
+	client          WithWatch
+	list            ObjectList
+	listOpts        ListOptions
+	resourceVersion string
+}
+
+// start starts a watch from the last observed resource version.
+func (tw *typedWatch[T]) start(ctx context.Context) (watch.Interface, error) {
+	listOpts := tw.listOpts
+	listOpts.Raw = tw.listOpts.Raw.DeepCopy()
+	listOpts.Raw.ResourceVersion = tw.resourceVersion
+	return tw.client.Watch(ctx, tw.list.DeepCopyObject().(ObjectList), &listOpts)
+}
+
+// run passes on the events of w, and of the watches re-establishing it, until ctx is
+// done or the watch can't be re-established.
+func (tw *typedWatch[T]) run(ctx context.Context, w watch.Interface, events chan<- WatchEvent[T]) {
+	send := func(event WatchEvent[T]) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		received, err := tw.consume(ctx, w, send)
+		w.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			// The resource version is too old, start over from the current state.
+			tw.resourceVersion = ""
+		} else if err != nil {
+			send(WatchEvent[T]{Type: watch.Error, Err: err})
+			return
+		}
+		if !received {
+			// Don't hammer the API server if it keeps closing the watch right away.
+			select {
+			case <-time.After(typedWatchRestartDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		w, err = tw.start(ctx)
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			tw.resourceVersion = ""
+			w, err = tw.start(ctx)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				send(WatchEvent[T]{Type: watch.Error, Err: err})
+			}
+			return
+		}
+	}
+}
+
+// consume passes on the events of w until it is closed. It returns whether any event
+// was received, and the error of its Error event, if any.
+func (tw *typedWatch[T]) consume(ctx context.Context, w watch.Interface, send func(WatchEvent[T]) bool) (received bool, err error) {
+	for {
+		var event watch.Event
+		var ok bool
+		select {
+		case event, ok = <-w.ResultChan():
+			if !ok {
+				return received, nil
+			}
+		case <-ctx.Done():
+			return received, nil
+		}
+
+		switch event.Type {
+		case watch.Error:
+			return received, apierrors.FromObject(event.Object)
+		case watch.Bookmark:
+			received = true
+			if accessor, err := meta.Accessor(event.Object); err == nil {
+				tw.resourceVersion = accessor.GetResourceVersion()
+			}
+		default:
+			received = true
+			obj, isT := event.Object.(T)
+			if !isT {
+				return received, fmt.Errorf("watch returned %T instead of %T", event.Object, obj)
+			}
+			tw.resourceVersion = obj.GetResourceVersion()
+			if !send(WatchEvent[T]{Type: event.Type, Object: obj}) {
+				return received, nil
+			}
+		}
+	}
+}
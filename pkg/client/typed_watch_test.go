@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("Watch", func() {
+	var (
+		mu               sync.Mutex
+		resourceVersions []string
+		watchers         chan *watch.FakeWatcher
+		watchErr         error
+		cl               client.WithWatch
+	)
+
+	pod := func(resourceVersion string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod", ResourceVersion: resourceVersion}}
+	}
+
+	BeforeEach(func() {
+		resourceVersions = nil
+		watchErr = nil
+		watchers = make(chan *watch.FakeWatcher, 10)
+		cl = interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+			Watch: func(_ context.Context, _ client.WithWatch, _ client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+				listOpts := client.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(listOpts.Raw.AllowWatchBookmarks).To(BeTrue())
+				resourceVersions = append(resourceVersions, listOpts.Raw.ResourceVersion)
+				if watchErr != nil {
+					return nil, watchErr
+				}
+				w := watch.NewFakeWithChanSize(10, false)
+				watchers <- w
+				return w, nil
+			},
+		})
+	})
+
+	It("should pass on typed events and re-establish the watch", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events, err := client.Watch[*corev1.Pod](ctx, cl, &corev1.PodList{}, client.InNamespace("default"))
+		Expect(err).NotTo(HaveOccurred())
+
+		var w *watch.FakeWatcher
+		Eventually(watchers).Should(Receive(&w))
+		w.Add(pod("1"))
+		var event client.WatchEvent[*corev1.Pod]
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.ResourceVersion).To(Equal("1"))
+
+		By("re-establishing the watch from the resource version of the last bookmark")
+		w.Action(watch.Bookmark, pod("5"))
+		w.Stop()
+		Eventually(watchers).Should(Receive(&w))
+
+		By("re-establishing the watch from the current state if the resource version is too old")
+		w.Add(pod("6"))
+		Eventually(events).Should(Receive(&event))
+		w.Error(&apierrors.NewResourceExpired("too old").ErrStatus)
+		Eventually(watchers).Should(Receive(&w))
+		w.Modify(pod("7"))
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+
+		mu.Lock()
+		Expect(resourceVersions).To(Equal([]string{"", "5", ""}))
+		mu.Unlock()
+
+		By("closing the channel once the context is done")
+		cancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should stop with an Error event if the watch can't be re-established", func() {
+		events, err := client.Watch[*corev1.Pod](context.Background(), cl, &corev1.PodList{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var w *watch.FakeWatcher
+		Eventually(watchers).Should(Receive(&w))
+		w.Add(pod("1"))
+		mu.Lock()
+		watchErr = errors.New("boom")
+		mu.Unlock()
+		Eventually(events).Should(Receive())
+		w.Stop()
+
+		var event client.WatchEvent[*corev1.Pod]
+		Eventually(events).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Error))
+		Expect(event.Err).To(MatchError("boom"))
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should return errors starting the watch", func() {
+		watchErr = errors.New("boom")
+		_, err := client.Watch[*corev1.Pod](context.Background(), cl, &corev1.PodList{})
+		Expect(err).To(MatchError("boom"))
+	})
+})
@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,13 +27,22 @@ import (
 	"k8s.io/client-go/rest"
 )
 
-// NewWithWatch returns a new WithWatch.
+// NewWithWatch returns a new WithWatch. The Interceptors of the options must return
+// clients implementing WithWatch.
 func NewWithWatch(config *rest.Config, options Options) (WithWatch, error) {
 	client, err := newClient(config, options)
 	if err != nil {
 		return nil, err
 	}
-	return &watchingClient{client: client}, nil
+	var c WithWatch = &watchingClient{client: client}
+	for i := len(options.Interceptors) - 1; i >= 0; i-- {
+		intercepted, ok := options.Interceptors[i](c).(WithWatch)
+		if !ok {
+			return nil, fmt.Errorf("interceptor %d does not return a client.WithWatch", i)
+		}
+		c = intercepted
+	}
+	return c, nil
 }
 
 type watchingClient struct {
@@ -74,6 +74,42 @@ type Cluster interface {
 	Start(ctx context.Context) error
 }
 
+// Metadata is the metadata of a cluster. Cluster providers populate it from the object
+// the cluster was discovered from, so that e.g. controllers can select clusters by label.
+type Metadata struct {
+	// Labels are the labels of the cluster, e.g. its region or environment.
+	Labels map[string]string
+
+	// Annotations are the annotations of the cluster.
+	Annotations map[string]string
+}
+
+// MetadataReporter is implemented by clusters that have metadata, like the clusters of
+// the built-in cluster providers.
+type MetadataReporter interface {
+	// Metadata returns the metadata of the cluster, like its labels, as populated by
+	// the cluster provider that discovered it.
+	Metadata() Metadata
+}
+
+// GetMetadata returns the metadata of cl, or empty metadata if cl doesn't implement
+// MetadataReporter.
+func GetMetadata(cl Cluster) Metadata {
+	reporter, ok := cl.(MetadataReporter)
+	if !ok {
+		return Metadata{}
+	}
+	return reporter.Metadata()
+}
+
+// MetadataNotifier is implemented by clusters whose metadata can change while they are
+// engaged, e.g. because the labels of the object they were discovered from changed.
+type MetadataNotifier interface {
+	// MetadataChanged returns a channel that is closed once the metadata of the cluster
+	// changed. Call it again for a channel that is closed on the next change.
+	MetadataChanged() <-chan struct{}
+}
+
 // Options are the possible options that can be configured for a Cluster.
 type Options struct {
 	// Scheme is the scheme used to resolve runtime.Objects to GroupVersionKinds / Resources
@@ -132,6 +168,10 @@ type Options struct {
 	// Only use a custom NewClient if you know what you are doing.
 	NewClient client.NewClientFunc
 
+	// Metadata is the metadata of the cluster, see MetadataReporter.
+	// Defaults to empty metadata.
+	Metadata Metadata
+
 	// EventBroadcaster records Events emitted by the manager and sends them to the Kubernetes API
 	// Use this to customize the event correlator and spam filter
 	//
@@ -231,9 +271,10 @@ func New(config *rest.Config, opts ...Option) (Cluster, error) {
 
 	// Create the API Reader, a client with no cache.
 	clientReader, err := client.New(config, client.Options{
-		HTTPClient: options.HTTPClient,
-		Scheme:     options.Scheme,
-		Mapper:     mapper,
+		HTTPClient:   options.HTTPClient,
+		Scheme:       options.Scheme,
+		Mapper:       mapper,
+		Interceptors: options.Client.Interceptors,
 	})
 	if err != nil {
 		return nil, err
@@ -258,6 +299,7 @@ func New(config *rest.Config, opts ...Option) (Cluster, error) {
 		recorderProvider: recorderProvider,
 		mapper:           mapper,
 		logger:           options.Logger,
+		metadata:         options.Metadata,
 	}, nil
 }
 
@@ -109,6 +109,25 @@ var _ = Describe("cluster.Cluster", func() {
 		})
 	})
 
+	Describe("FromContext", func() {
+		It("should return the cluster put into the context", func() {
+			c, err := New(cfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx := IntoContext(context.Background(), "cluster-a", c)
+			fromCtx, err := FromContext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fromCtx).To(BeIdenticalTo(c))
+			Expect(NameFromContext(ctx)).To(Equal("cluster-a"))
+		})
+
+		It("should return an error if there is no cluster in the context", func() {
+			_, err := FromContext(context.Background())
+			Expect(err).To(MatchError(ErrNoClusterInContext))
+			Expect(NameFromContext(context.Background())).To(BeEmpty())
+		})
+	})
+
 	It("should not leak goroutines when stopped", func() {
 		currentGRs := goleak.IgnoreCurrent()
 
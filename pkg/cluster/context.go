@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoClusterInContext is returned by FromContext if the context carries no cluster.
+var ErrNoClusterInContext = errors.New("no cluster in context")
+
+type clusterContextKey struct{}
+
+// clusterContext is the cluster carried by a context.
+type clusterContext struct {
+	name    string
+	cluster Cluster
+}
+
+// IntoContext returns a copy of ctx carrying the cluster with the given name. The
+// empty name refers to the cluster of the manager. Controllers use it to pass the
+// cluster of a reconcile.Request to the reconciler.
+func IntoContext(ctx context.Context, name string, cl Cluster) context.Context {
+	return context.WithValue(ctx, clusterContextKey{}, clusterContext{name: name, cluster: cl})
+}
+
+// FromContext returns the cluster carried by ctx, e.g. the cluster the object being
+// reconciled lives in, so that reconcilers can use its client.
+func FromContext(ctx context.Context) (Cluster, error) {
+	if c, ok := ctx.Value(clusterContextKey{}).(clusterContext); ok && c.cluster != nil {
+		return c.cluster, nil
+	}
+	return nil, ErrNoClusterInContext
+}
+
+// NameFromContext returns the name of the cluster carried by ctx. It returns the
+// empty name, which refers to the cluster of the manager, if ctx carries no cluster.
+func NameFromContext(ctx context.Context) string {
+	c, _ := ctx.Value(clusterContextKey{}).(clusterContext)
+	return c.name
+}
@@ -57,6 +57,9 @@ type cluster struct {
 	// Logger is the logger that should be used by this manager.
 	// If none is set, it defaults to log.Log global logger.
 	logger logr.Logger
+
+	// metadata is the metadata of the cluster populated by its provider.
+	metadata Metadata
 }
 
 func (c *cluster) GetConfig() *rest.Config {
@@ -99,6 +102,11 @@ func (c *cluster) GetLogger() logr.Logger {
 	return c.logger
 }
 
+// Metadata implements MetadataReporter.
+func (c *cluster) Metadata() Metadata {
+	return c.metadata
+}
+
 func (c *cluster) Start(ctx context.Context) error {
 	defer c.recorderProvider.Stop(ctx)
 	return c.cache.Start(ctx)
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClusterNotFound is returned by Provider.Get if no cluster with the given name is engaged.
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// Aware is implemented by components that operate on clusters discovered at runtime,
+// e.g. the Manager.
+type Aware interface {
+	// Engage starts operations for the given cluster. The context is cancelled when
+	// the cluster is disengaged, i.e. when it is removed by the provider. Engage must
+	// not block until the context is done.
+	Engage(ctx context.Context, name string, cl Cluster) error
+}
+
+// Provider discovers clusters at runtime, e.g. from a fleet inventory, and engages
+// them with an Aware component.
+type Provider interface {
+	// Get returns the engaged cluster with the given name, or ErrClusterNotFound.
+	Get(ctx context.Context, name string) (Cluster, error)
+
+	// Run discovers clusters until ctx is done. The provider is responsible for
+	// starting the clusters it discovers. It then engages them with aware, and
+	// disengages them by cancelling the context passed to Engage.
+	Run(ctx context.Context, aware Aware) error
+}
@@ -0,0 +1,472 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterapi provides a cluster.Provider that discovers the workload
+// clusters of Cluster API.
+//
+// Every Cluster API Cluster that matches the label selector is turned into a
+// cluster named namespace/name, created from the kubeconfig Secret Cluster API
+// maintains for it, whose metadata are the labels and annotations of the Cluster.
+// A cluster is only engaged once its control plane is ready
+// and reachable, it is re-engaged when its kubeconfig changes and disengaged
+// when the Cluster is deleted or its control plane is no longer ready.
+//
+// Clusters are read as unstructured objects, so the provider does not depend on
+// the Cluster API types.
+package clusterapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+var log = logf.RuntimeLog.WithName("clusterapi-provider")
+
+// ClusterGroupVersionKind is the GroupVersionKind of the Cluster API Clusters.
+var ClusterGroupVersionKind = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"}
+
+const (
+	// ClusterNameLabel is the label Cluster API sets on the objects of a Cluster,
+	// including its kubeconfig Secret.
+	ClusterNameLabel = "cluster.x-k8s.io/cluster-name"
+
+	// KubeconfigDataKey is the key of the kubeconfig in the data of the Secrets.
+	KubeconfigDataKey = "value"
+
+	// kubeconfigSecretSuffix is the suffix of the name of the kubeconfig Secret
+	// of a Cluster.
+	kubeconfigSecretSuffix = "-kubeconfig"
+
+	defaultReadinessTimeout = 10 * time.Second
+)
+
+// Options are the options of the Provider.
+type Options struct {
+	// Namespace restricts the Clusters to the given namespace. Defaults to all
+	// namespaces.
+	Namespace string
+
+	// Selector selects the Clusters. Defaults to all Clusters.
+	Selector labels.Selector
+
+	// ClusterOptions are applied to every cluster created from a kubeconfig.
+	ClusterOptions []cluster.Option
+
+	// NewCluster creates a cluster from a kubeconfig. Defaults to cluster.New.
+	NewCluster func(config *rest.Config, opts ...cluster.Option) (cluster.Cluster, error)
+
+	// NewCache creates the cache used to watch the Clusters and their kubeconfig
+	// Secrets. Defaults to cache.New.
+	NewCache cache.NewCacheFunc
+
+	// ReadinessCheck determines whether the control plane of a workload cluster is
+	// reachable before it is engaged. It is retried with RateLimiter until it succeeds.
+	// Defaults to requesting the server version of the workload cluster.
+	ReadinessCheck func(ctx context.Context, config *rest.Config) error
+
+	// RateLimiter limits the retries of Clusters that couldn't be engaged.
+	// Defaults to workqueue.DefaultControllerRateLimiter().
+	RateLimiter workqueue.RateLimiter
+}
+
+var _ cluster.Provider = &Provider{}
+
+// Provider is a cluster.Provider that discovers the workload clusters of Cluster API.
+type Provider struct {
+	opts      Options
+	informers cache.Informers
+	queue     workqueue.RateLimitingInterface
+
+	mu sync.Mutex
+	// ready holds the Clusters whose control plane is ready.
+	ready map[string]bool
+	// kubeconfigs holds the kubeconfigs of the Clusters.
+	kubeconfigs map[string][]byte
+	// metadata holds the metadata of the Clusters.
+	metadata map[string]cluster.Metadata
+	clusters map[string]*activeCluster
+}
+
+// activeCluster is an engaged workload cluster.
+type activeCluster struct {
+	cluster.Cluster
+
+	// kubeconfig is the kubeconfig the cluster was created from.
+	kubeconfig []byte
+
+	// cancel disengages and stops the cluster.
+	cancel context.CancelFunc
+
+	// metadataMu guards metadata and metadataChanged.
+	metadataMu sync.Mutex
+	// metadata is the metadata of the latest version of the Cluster.
+	metadata cluster.Metadata
+	// metadataChanged is closed once metadata changes.
+	metadataChanged chan struct{}
+}
+
+// Metadata implements cluster.MetadataReporter.
+func (c *activeCluster) Metadata() cluster.Metadata {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	return c.metadata
+}
+
+// MetadataChanged implements cluster.MetadataNotifier.
+func (c *activeCluster) MetadataChanged() <-chan struct{} {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	if c.metadataChanged == nil {
+		c.metadataChanged = make(chan struct{})
+	}
+	return c.metadataChanged
+}
+
+func (c *activeCluster) setMetadata(metadata cluster.Metadata) {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	if maps.Equal(c.metadata.Labels, metadata.Labels) && maps.Equal(c.metadata.Annotations, metadata.Annotations) {
+		return
+	}
+	c.metadata = metadata
+	if c.metadataChanged != nil {
+		close(c.metadataChanged)
+		c.metadataChanged = nil
+	}
+}
+
+// New returns a Provider that watches the Clusters with the given rest.Config.
+func New(config *rest.Config, opts Options) (*Provider, error) {
+	if config == nil {
+		return nil, errors.New("must specify Config")
+	}
+	if opts.Selector == nil {
+		opts.Selector = labels.Everything()
+	}
+	if opts.NewCluster == nil {
+		opts.NewCluster = cluster.New
+	}
+	if opts.NewCache == nil {
+		opts.NewCache = cache.New
+	}
+	if opts.ReadinessCheck == nil {
+		opts.ReadinessCheck = serverVersionCheck
+	}
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = workqueue.DefaultControllerRateLimiter()
+	}
+
+	hasClusterName, err := labels.NewRequirement(ClusterNameLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	cacheOpts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			newCluster():     {Label: opts.Selector},
+			&corev1.Secret{}: {Label: labels.NewSelector().Add(*hasClusterName)},
+		},
+	}
+	if opts.Namespace != "" {
+		cacheOpts.DefaultNamespaces = map[string]cache.Config{opts.Namespace: {}}
+	}
+	informers, err := opts.NewCache(config, cacheOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Cluster cache: %w", err)
+	}
+
+	return &Provider{
+		opts:      opts,
+		informers: informers,
+		queue: workqueue.NewRateLimitingQueueWithConfig(opts.RateLimiter, workqueue.RateLimitingQueueConfig{
+			Name: "clusterapi-provider",
+		}),
+		ready:       make(map[string]bool),
+		kubeconfigs: make(map[string][]byte),
+		metadata:    make(map[string]cluster.Metadata),
+		clusters:    make(map[string]*activeCluster),
+	}, nil
+}
+
+// Get implements cluster.Provider. Clusters are named namespace/name.
+func (p *Provider) Get(_ context.Context, name string) (cluster.Cluster, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cl, ok := p.clusters[name]; ok {
+		return cl, nil
+	}
+	return nil, cluster.ErrClusterNotFound
+}
+
+// Run implements cluster.Provider. It watches the Clusters and their kubeconfig
+// Secrets until ctx is done.
+func (p *Provider) Run(ctx context.Context, aware cluster.Aware) error {
+	clusterInformer, err := p.informers.GetInformer(ctx, newCluster())
+	if err != nil {
+		return fmt.Errorf("failed to get the Cluster informer: %w", err)
+	}
+	if _, err := clusterInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { p.observeCluster(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { p.observeCluster(obj, false) },
+		DeleteFunc: func(obj interface{}) { p.observeCluster(obj, true) },
+	}); err != nil {
+		return fmt.Errorf("failed to watch Clusters: %w", err)
+	}
+
+	secretInformer, err := p.informers.GetInformer(ctx, &corev1.Secret{})
+	if err != nil {
+		return fmt.Errorf("failed to get the Secret informer: %w", err)
+	}
+	if _, err := secretInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { p.observeSecret(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { p.observeSecret(obj, false) },
+		DeleteFunc: func(obj interface{}) { p.observeSecret(obj, true) },
+	}); err != nil {
+		return fmt.Errorf("failed to watch Secrets: %w", err)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- p.informers.Start(ctx)
+	}()
+	go func() {
+		<-ctx.Done()
+		p.queue.ShutDown()
+	}()
+	if !p.informers.WaitForCacheSync(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		return errors.New("failed to wait for the Cluster cache to sync")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for p.processNext(ctx, aware) {
+		}
+	}()
+	defer wg.Wait()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return fmt.Errorf("failed to run the Cluster cache: %w", err)
+		}
+		<-ctx.Done()
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// observeCluster records whether the control plane of a Cluster is ready.
+func (p *Provider) observeCluster(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	name := types.NamespacedName{Namespace: u.GetNamespace(), Name: u.GetName()}.String()
+
+	p.mu.Lock()
+	if deleted || u.GetDeletionTimestamp() != nil || !controlPlaneReady(u) {
+		delete(p.ready, name)
+	} else {
+		p.ready[name] = true
+	}
+	if deleted {
+		delete(p.metadata, name)
+	} else {
+		p.metadata[name] = cluster.Metadata{Labels: u.GetLabels(), Annotations: u.GetAnnotations()}
+	}
+	p.mu.Unlock()
+	p.queue.Add(name)
+}
+
+// observeSecret records the kubeconfig of a Cluster.
+func (p *Provider) observeSecret(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	clusterName := secret.Labels[ClusterNameLabel]
+	if clusterName == "" || secret.Name != clusterName+kubeconfigSecretSuffix {
+		return
+	}
+	name := types.NamespacedName{Namespace: secret.Namespace, Name: clusterName}.String()
+
+	p.mu.Lock()
+	if deleted || len(secret.Data[KubeconfigDataKey]) == 0 {
+		delete(p.kubeconfigs, name)
+	} else {
+		p.kubeconfigs[name] = secret.Data[KubeconfigDataKey]
+	}
+	p.mu.Unlock()
+	p.queue.Add(name)
+}
+
+func (p *Provider) processNext(ctx context.Context, aware cluster.Aware) bool {
+	item, shutdown := p.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer p.queue.Done(item)
+	name := item.(string)
+
+	if err := p.sync(ctx, aware, name); err != nil {
+		log.Error(err, "Failed to engage cluster, retrying", "cluster", name)
+		p.queue.AddRateLimited(name)
+		return true
+	}
+	p.queue.Forget(name)
+	return true
+}
+
+// sync engages the cluster with the given name if its control plane is ready and
+// reachable, and disengages it otherwise.
+func (p *Provider) sync(ctx context.Context, aware cluster.Aware, name string) error {
+	p.mu.Lock()
+	ready := p.ready[name]
+	kubeconfig := p.kubeconfigs[name]
+	metadata := p.metadata[name]
+	existing, ok := p.clusters[name]
+	p.mu.Unlock()
+
+	if !ready || len(kubeconfig) == 0 {
+		p.disengage(name)
+		return nil
+	}
+	if ok && bytes.Equal(existing.kubeconfig, kubeconfig) {
+		existing.setMetadata(metadata)
+		return nil
+	}
+	p.disengage(name)
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		// Retrying doesn't help, the Secret has to change.
+		log.Error(err, "Failed to load kubeconfig", "cluster", name)
+		return nil
+	}
+	if err := p.opts.ReadinessCheck(ctx, config); err != nil {
+		return fmt.Errorf("control plane is not reachable: %w", err)
+	}
+	cl, err := p.opts.NewCluster(config, p.opts.ClusterOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster: %w", err)
+	}
+
+	clusterCtx, cancel := context.WithCancel(ctx)
+	active := &activeCluster{Cluster: cl, kubeconfig: kubeconfig, cancel: cancel, metadata: metadata}
+	go func() {
+		if err := cl.Start(clusterCtx); err != nil {
+			log.Error(err, "Failed to start cluster", "cluster", name)
+		}
+	}()
+	if err := aware.Engage(clusterCtx, name, active); err != nil {
+		cancel()
+		return fmt.Errorf("failed to engage cluster: %w", err)
+	}
+
+	p.mu.Lock()
+	p.clusters[name] = active
+	p.mu.Unlock()
+	log.Info("Engaged cluster", "cluster", name)
+	return nil
+}
+
+// disengage stops the cluster with the given name, if any.
+func (p *Provider) disengage(name string) {
+	p.mu.Lock()
+	cl, ok := p.clusters[name]
+	delete(p.clusters, name)
+	p.mu.Unlock()
+
+	if ok {
+		cl.cancel()
+		log.Info("Disengaged cluster", "cluster", name)
+	}
+}
+
+func newCluster() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(ClusterGroupVersionKind)
+	return u
+}
+
+// controlPlaneReady reports whether Cluster API reports the control plane of
+// the Cluster as ready, either through status.controlPlaneReady or through the
+// ControlPlaneReady condition.
+func controlPlaneReady(u *unstructured.Unstructured) bool {
+	if ready, found, err := unstructured.NestedBool(u.Object, "status", "controlPlaneReady"); err == nil && found {
+		return ready
+	}
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "ControlPlaneReady" {
+			status, _ := condition["status"].(string)
+			return strings.EqualFold(status, string(corev1.ConditionTrue))
+		}
+	}
+	return false
+}
+
+// serverVersionCheck requests the server version of the workload cluster.
+func serverVersionCheck(_ context.Context, config *rest.Config) error {
+	config = rest.CopyConfig(config)
+	if config.Timeout == 0 {
+		config.Timeout = defaultReadinessTimeout
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = dc.ServerVersion()
+	return err
+}
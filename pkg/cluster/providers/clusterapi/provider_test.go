@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/cluster/providers/clusterapi"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`
+
+type fakeAware struct {
+	mu      sync.Mutex
+	engaged map[string]context.Context
+}
+
+func (a *fakeAware) Engage(ctx context.Context, name string, _ cluster.Cluster) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.engaged[name] = ctx
+	return nil
+}
+
+func (a *fakeAware) get(name string) context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.engaged[name]
+}
+
+// startNotifyingInformers closes started once the informers are started.
+type startNotifyingInformers struct {
+	*informertest.FakeInformers
+	started chan struct{}
+}
+
+func (i *startNotifyingInformers) Start(ctx context.Context) error {
+	close(i.started)
+	return i.FakeInformers.Start(ctx)
+}
+
+var _ = Describe("Provider", func() {
+	var (
+		ctx             context.Context
+		cancel          context.CancelFunc
+		provider        *clusterapi.Provider
+		aware           *fakeAware
+		clusterInformer *controllertest.FakeInformer
+		secretInformer  *controllertest.FakeInformer
+		reachable       atomic.Bool
+	)
+
+	newCluster := func(name string, controlPlaneReady bool) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(clusterapi.ClusterGroupVersionKind)
+		u.SetNamespace("fleet")
+		u.SetName(name)
+		Expect(unstructured.SetNestedField(u.Object, controlPlaneReady, "status", "controlPlaneReady")).To(Succeed())
+		return u
+	}
+
+	newSecret := func(clusterName, server string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName + "-kubeconfig",
+				Namespace: "fleet",
+				Labels:    map[string]string{clusterapi.ClusterNameLabel: clusterName},
+			},
+			Data: map[string][]byte{clusterapi.KubeconfigDataKey: []byte(fmt.Sprintf(kubeconfigTemplate, server))},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		reachable.Store(true)
+		informers := &startNotifyingInformers{FakeInformers: &informertest.FakeInformers{}, started: make(chan struct{})}
+		var err error
+		clusterInformer, err = informers.FakeInformerFor(ctx, newCluster("", false))
+		Expect(err).NotTo(HaveOccurred())
+		secretInformer, err = informers.FakeInformerFor(ctx, &corev1.Secret{})
+		Expect(err).NotTo(HaveOccurred())
+
+		provider, err = clusterapi.New(&rest.Config{}, clusterapi.Options{
+			NewCache: func(*rest.Config, cache.Options) (cache.Cache, error) {
+				return informers, nil
+			},
+			ReadinessCheck: func(context.Context, *rest.Config) error {
+				if !reachable.Load() {
+					return errors.New("unreachable")
+				}
+				return nil
+			},
+			RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(10*time.Millisecond, 10*time.Millisecond),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		aware = &fakeAware{engaged: map[string]context.Context{}}
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(provider.Run(ctx, aware)).To(Succeed())
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+		// Run registers its event handlers before starting the cache.
+		Eventually(informers.started).Should(BeClosed())
+	})
+
+	It("should engage a cluster once its control plane is ready", func() {
+		secretInformer.Add(newSecret("cluster-a", "https://a.example.com"))
+		notReady := newCluster("cluster-a", false)
+		clusterInformer.Add(notReady)
+		Consistently(func() context.Context { return aware.get("fleet/cluster-a") }).Should(BeNil())
+
+		clusterInformer.Update(notReady, newCluster("cluster-a", true))
+		Eventually(func() context.Context { return aware.get("fleet/cluster-a") }).ShouldNot(BeNil())
+		cl, err := provider.Get(ctx, "fleet/cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cl.GetConfig().Host).To(Equal("https://a.example.com"))
+	})
+
+	It("should retry engaging a cluster until its control plane is reachable", func() {
+		reachable.Store(false)
+		clusterInformer.Add(newCluster("cluster-a", true))
+		secretInformer.Add(newSecret("cluster-a", "https://a.example.com"))
+		Consistently(func() context.Context { return aware.get("fleet/cluster-a") }).Should(BeNil())
+
+		reachable.Store(true)
+		Eventually(func() context.Context { return aware.get("fleet/cluster-a") }).ShouldNot(BeNil())
+	})
+
+	It("should ignore Secrets that aren't kubeconfigs of Clusters", func() {
+		clusterInformer.Add(newCluster("cluster-a", true))
+		secret := newSecret("cluster-a", "https://a.example.com")
+		secret.Name = "cluster-a-ca"
+		secretInformer.Add(secret)
+
+		Consistently(func() context.Context { return aware.get("fleet/cluster-a") }).Should(BeNil())
+		_, err := provider.Get(ctx, "fleet/cluster-a")
+		Expect(err).To(MatchError(cluster.ErrClusterNotFound))
+	})
+
+	It("should re-engage a cluster when its kubeconfig changes", func() {
+		clusterInformer.Add(newCluster("cluster-a", true))
+		oldSecret := newSecret("cluster-a", "https://a.example.com")
+		secretInformer.Add(oldSecret)
+		Eventually(func() context.Context { return aware.get("fleet/cluster-a") }).ShouldNot(BeNil())
+		oldCtx := aware.get("fleet/cluster-a")
+
+		secretInformer.Update(oldSecret, newSecret("cluster-a", "https://b.example.com"))
+		Eventually(oldCtx.Done()).Should(BeClosed())
+		Eventually(func() string {
+			cl, err := provider.Get(ctx, "fleet/cluster-a")
+			if err != nil {
+				return ""
+			}
+			return cl.GetConfig().Host
+		}).Should(Equal("https://b.example.com"))
+	})
+
+	It("should disengage a cluster when it is deleted", func() {
+		c := newCluster("cluster-a", true)
+		clusterInformer.Add(c)
+		secretInformer.Add(newSecret("cluster-a", "https://a.example.com"))
+		Eventually(func() context.Context { return aware.get("fleet/cluster-a") }).ShouldNot(BeNil())
+
+		clusterInformer.Delete(c)
+		Eventually(aware.get("fleet/cluster-a").Done()).Should(BeClosed())
+		_, err := provider.Get(ctx, "fleet/cluster-a")
+		Expect(err).To(MatchError(cluster.ErrClusterNotFound))
+	})
+})
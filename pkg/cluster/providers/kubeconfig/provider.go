@@ -0,0 +1,359 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeconfig provides a cluster.Provider that discovers clusters from
+// Secrets containing a kubeconfig.
+//
+// Every Secret in the configured namespace that matches the label selector is
+// turned into a cluster named after the Secret, whose metadata are the labels and
+// annotations of the Secret. The cluster is engaged when the Secret appears,
+// re-engaged when its kubeconfig changes and disengaged when the Secret is deleted
+// or no longer matches the selector. Clusters that fail to be created or engaged are
+// retried with a backoff.
+package kubeconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+var log = logf.RuntimeLog.WithName("kubeconfig-provider")
+
+// DefaultDataKey is the key of the kubeconfig in the data of the Secrets.
+const DefaultDataKey = "kubeconfig"
+
+// Options are the options of the Provider.
+type Options struct {
+	// Namespace is the namespace of the Secrets. It is required, as the clusters
+	// are named after the Secrets.
+	Namespace string
+
+	// Selector selects the Secrets containing a kubeconfig. Defaults to all
+	// Secrets in the namespace.
+	Selector labels.Selector
+
+	// DataKey is the key of the kubeconfig in the data of the Secrets.
+	// Defaults to DefaultDataKey.
+	DataKey string
+
+	// ClusterOptions are applied to every cluster created from a kubeconfig.
+	ClusterOptions []cluster.Option
+
+	// NewCluster creates a cluster from a kubeconfig. Defaults to cluster.New.
+	NewCluster func(config *rest.Config, opts ...cluster.Option) (cluster.Cluster, error)
+
+	// NewCache creates the cache used to watch the Secrets. Defaults to cache.New.
+	NewCache cache.NewCacheFunc
+
+	// RateLimiter limits the retries of clusters that couldn't be engaged.
+	// Defaults to workqueue.DefaultControllerRateLimiter().
+	RateLimiter workqueue.RateLimiter
+}
+
+var _ cluster.Provider = &Provider{}
+
+// Provider is a cluster.Provider that discovers clusters from kubeconfig Secrets.
+type Provider struct {
+	opts      Options
+	informers cache.Informers
+	queue     workqueue.RateLimitingInterface
+
+	mu sync.Mutex
+	// kubeconfigs holds the kubeconfigs of the Secrets.
+	kubeconfigs map[string][]byte
+	// metadata holds the metadata of the Secrets.
+	metadata map[string]cluster.Metadata
+	clusters map[string]*activeCluster
+}
+
+// activeCluster is a cluster created from a Secret.
+type activeCluster struct {
+	cluster.Cluster
+
+	// kubeconfig is the kubeconfig the cluster was created from.
+	kubeconfig []byte
+
+	// cancel disengages and stops the cluster.
+	cancel context.CancelFunc
+
+	// metadataMu guards metadata and metadataChanged.
+	metadataMu sync.Mutex
+	// metadata is the metadata of the latest version of the Secret.
+	metadata cluster.Metadata
+	// metadataChanged is closed once metadata changes.
+	metadataChanged chan struct{}
+}
+
+// Metadata implements cluster.MetadataReporter.
+func (c *activeCluster) Metadata() cluster.Metadata {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	return c.metadata
+}
+
+// MetadataChanged implements cluster.MetadataNotifier.
+func (c *activeCluster) MetadataChanged() <-chan struct{} {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	if c.metadataChanged == nil {
+		c.metadataChanged = make(chan struct{})
+	}
+	return c.metadataChanged
+}
+
+func (c *activeCluster) setMetadata(metadata cluster.Metadata) {
+	c.metadataMu.Lock()
+	defer c.metadataMu.Unlock()
+	if maps.Equal(c.metadata.Labels, metadata.Labels) && maps.Equal(c.metadata.Annotations, metadata.Annotations) {
+		return
+	}
+	c.metadata = metadata
+	if c.metadataChanged != nil {
+		close(c.metadataChanged)
+		c.metadataChanged = nil
+	}
+}
+
+// New returns a Provider that watches the Secrets with the given rest.Config.
+func New(config *rest.Config, opts Options) (*Provider, error) {
+	if config == nil {
+		return nil, errors.New("must specify Config")
+	}
+	if opts.Namespace == "" {
+		return nil, errors.New("must specify Namespace")
+	}
+	if opts.Selector == nil {
+		opts.Selector = labels.Everything()
+	}
+	if opts.DataKey == "" {
+		opts.DataKey = DefaultDataKey
+	}
+	if opts.NewCluster == nil {
+		opts.NewCluster = cluster.New
+	}
+	if opts.NewCache == nil {
+		opts.NewCache = cache.New
+	}
+	if opts.RateLimiter == nil {
+		opts.RateLimiter = workqueue.DefaultControllerRateLimiter()
+	}
+
+	informers, err := opts.NewCache(config, cache.Options{
+		DefaultNamespaces: map[string]cache.Config{opts.Namespace: {}},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Label: opts.Selector},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Secret cache: %w", err)
+	}
+
+	return &Provider{
+		opts:      opts,
+		informers: informers,
+		queue: workqueue.NewRateLimitingQueueWithConfig(opts.RateLimiter, workqueue.RateLimitingQueueConfig{
+			Name: "kubeconfig-provider",
+		}),
+		kubeconfigs: make(map[string][]byte),
+		metadata:    make(map[string]cluster.Metadata),
+		clusters:    make(map[string]*activeCluster),
+	}, nil
+}
+
+// Get implements cluster.Provider.
+func (p *Provider) Get(_ context.Context, name string) (cluster.Cluster, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cl, ok := p.clusters[name]; ok {
+		return cl, nil
+	}
+	return nil, cluster.ErrClusterNotFound
+}
+
+// Run implements cluster.Provider. It watches the Secrets until ctx is done.
+func (p *Provider) Run(ctx context.Context, aware cluster.Aware) error {
+	informer, err := p.informers.GetInformer(ctx, &corev1.Secret{})
+	if err != nil {
+		return fmt.Errorf("failed to get the Secret informer: %w", err)
+	}
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { p.observeSecret(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { p.observeSecret(obj, false) },
+		DeleteFunc: func(obj interface{}) { p.observeSecret(obj, true) },
+	}); err != nil {
+		return fmt.Errorf("failed to watch Secrets: %w", err)
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- p.informers.Start(ctx)
+	}()
+	go func() {
+		<-ctx.Done()
+		p.queue.ShutDown()
+	}()
+	if !p.informers.WaitForCacheSync(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		return errors.New("failed to wait for the Secret cache to sync")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for p.processNext(ctx, aware) {
+		}
+	}()
+	defer wg.Wait()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return fmt.Errorf("failed to run the Secret cache: %w", err)
+		}
+		<-ctx.Done()
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// observeSecret records the kubeconfig and metadata of a Secret.
+func (p *Provider) observeSecret(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	name := secret.Name
+
+	p.mu.Lock()
+	if deleted {
+		delete(p.kubeconfigs, name)
+		delete(p.metadata, name)
+	} else {
+		p.kubeconfigs[name] = secret.Data[p.opts.DataKey]
+		p.metadata[name] = cluster.Metadata{Labels: secret.Labels, Annotations: secret.Annotations}
+	}
+	p.mu.Unlock()
+	p.queue.Add(name)
+}
+
+func (p *Provider) processNext(ctx context.Context, aware cluster.Aware) bool {
+	item, shutdown := p.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer p.queue.Done(item)
+	name := item.(string)
+
+	if err := p.sync(ctx, aware, name); err != nil {
+		log.Error(err, "Failed to engage cluster, retrying", "cluster", name)
+		p.queue.AddRateLimited(name)
+		return true
+	}
+	p.queue.Forget(name)
+	return true
+}
+
+// sync creates a cluster from the kubeconfig of the Secret with the given name and
+// engages it, replacing the cluster of an earlier version of the Secret if its
+// kubeconfig differs. It disengages the cluster if the Secret is gone.
+func (p *Provider) sync(ctx context.Context, aware cluster.Aware, name string) error {
+	log := log.WithValues("cluster", name)
+
+	p.mu.Lock()
+	kubeconfig, exists := p.kubeconfigs[name]
+	metadata := p.metadata[name]
+	existing, ok := p.clusters[name]
+	p.mu.Unlock()
+
+	if !exists {
+		p.disengage(name)
+		return nil
+	}
+	if ok && bytes.Equal(existing.kubeconfig, kubeconfig) {
+		existing.setMetadata(metadata)
+		return nil
+	}
+	p.disengage(name)
+
+	if len(kubeconfig) == 0 {
+		log.Info("Secret does not contain a kubeconfig", "key", p.opts.DataKey)
+		return nil
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		// Retrying doesn't help, the Secret has to change.
+		log.Error(err, "Failed to load kubeconfig")
+		return nil
+	}
+	cl, err := p.opts.NewCluster(config, p.opts.ClusterOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to create cluster: %w", err)
+	}
+
+	clusterCtx, cancel := context.WithCancel(ctx)
+	active := &activeCluster{Cluster: cl, kubeconfig: kubeconfig, cancel: cancel, metadata: metadata}
+	go func() {
+		if err := cl.Start(clusterCtx); err != nil {
+			log.Error(err, "Failed to start cluster")
+		}
+	}()
+	if err := aware.Engage(clusterCtx, name, active); err != nil {
+		cancel()
+		return fmt.Errorf("failed to engage cluster: %w", err)
+	}
+
+	p.mu.Lock()
+	p.clusters[name] = active
+	p.mu.Unlock()
+	log.Info("Engaged cluster")
+	return nil
+}
+
+// disengage stops the cluster with the given name, if any.
+func (p *Provider) disengage(name string) {
+	p.mu.Lock()
+	cl, ok := p.clusters[name]
+	delete(p.clusters, name)
+	p.mu.Unlock()
+
+	if ok {
+		cl.cancel()
+		log.Info("Disengaged cluster", "cluster", name)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/cluster/providers/kubeconfig"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`
+
+type fakeAware struct {
+	mu      sync.Mutex
+	engaged map[string]context.Context
+	// failures is the number of engages that fail before engaging succeeds.
+	failures int
+}
+
+func (a *fakeAware) Engage(ctx context.Context, name string, _ cluster.Cluster) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failures > 0 {
+		a.failures--
+		return errors.New("failed to engage")
+	}
+	a.engaged[name] = ctx
+	return nil
+}
+
+func (a *fakeAware) setFailures(failures int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures = failures
+}
+
+func (a *fakeAware) get(name string) context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.engaged[name]
+}
+
+// startNotifyingInformers closes started once the informers are started.
+type startNotifyingInformers struct {
+	*informertest.FakeInformers
+	started chan struct{}
+}
+
+func (i *startNotifyingInformers) Start(ctx context.Context) error {
+	close(i.started)
+	return i.FakeInformers.Start(ctx)
+}
+
+var _ = Describe("Provider", func() {
+	var (
+		ctx      context.Context
+		cancel   context.CancelFunc
+		provider *kubeconfig.Provider
+		aware    *fakeAware
+		informer *controllertest.FakeInformer
+	)
+
+	newSecret := func(name, server string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "fleet"},
+			Data:       map[string][]byte{kubeconfig.DefaultDataKey: []byte(fmt.Sprintf(kubeconfigTemplate, server))},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		informers := &startNotifyingInformers{FakeInformers: &informertest.FakeInformers{}, started: make(chan struct{})}
+		var err error
+		informer, err = informers.FakeInformerFor(ctx, &corev1.Secret{})
+		Expect(err).NotTo(HaveOccurred())
+
+		provider, err = kubeconfig.New(&rest.Config{}, kubeconfig.Options{
+			Namespace: "fleet",
+			NewCache: func(*rest.Config, cache.Options) (cache.Cache, error) {
+				return informers, nil
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		aware = &fakeAware{engaged: map[string]context.Context{}}
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			Expect(provider.Run(ctx, aware)).To(Succeed())
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(BeClosed())
+		})
+		// Run registers its event handler before starting the cache.
+		Eventually(informers.started).Should(BeClosed())
+	})
+
+	It("should require a namespace", func() {
+		_, err := kubeconfig.New(&rest.Config{}, kubeconfig.Options{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should engage a cluster for every Secret", func() {
+		informer.Add(newSecret("cluster-a", "https://a.example.com"))
+
+		Eventually(func() context.Context { return aware.get("cluster-a") }).ShouldNot(BeNil())
+		cl, err := provider.Get(ctx, "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cl.GetConfig().Host).To(Equal("https://a.example.com"))
+	})
+
+	It("should retry engaging a cluster that failed to be engaged", func() {
+		aware.setFailures(2)
+		informer.Add(newSecret("cluster-a", "https://a.example.com"))
+
+		Eventually(func() context.Context { return aware.get("cluster-a") }).ShouldNot(BeNil())
+		cl, err := provider.Get(ctx, "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cl.GetConfig().Host).To(Equal("https://a.example.com"))
+	})
+
+	It("should not engage Secrets without a kubeconfig", func() {
+		informer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "fleet"}})
+
+		Consistently(func() context.Context { return aware.get("empty") }).Should(BeNil())
+		_, err := provider.Get(ctx, "empty")
+		Expect(err).To(MatchError(cluster.ErrClusterNotFound))
+	})
+
+	It("should re-engage a cluster when its kubeconfig changes", func() {
+		oldSecret := newSecret("cluster-a", "https://a.example.com")
+		informer.Add(oldSecret)
+		Eventually(func() context.Context { return aware.get("cluster-a") }).ShouldNot(BeNil())
+		oldCtx := aware.get("cluster-a")
+
+		By("updating the Secret without changing the kubeconfig")
+		unchanged := oldSecret.DeepCopy()
+		unchanged.Labels = map[string]string{"foo": "bar"}
+		informer.Update(oldSecret, unchanged)
+		Expect(oldCtx.Err()).NotTo(HaveOccurred())
+		Expect(aware.get("cluster-a")).To(BeIdenticalTo(oldCtx))
+
+		By("updating the kubeconfig")
+		informer.Update(unchanged, newSecret("cluster-a", "https://b.example.com"))
+		Eventually(oldCtx.Done()).Should(BeClosed())
+		Expect(aware.get("cluster-a").Err()).NotTo(HaveOccurred())
+		cl, err := provider.Get(ctx, "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cl.GetConfig().Host).To(Equal("https://b.example.com"))
+	})
+
+	It("should populate the metadata of a cluster from its Secret", func() {
+		oldSecret := newSecret("cluster-a", "https://a.example.com")
+		oldSecret.Labels = map[string]string{"region": "eu"}
+		informer.Add(oldSecret)
+		Eventually(func() context.Context { return aware.get("cluster-a") }).ShouldNot(BeNil())
+		oldCtx := aware.get("cluster-a")
+		cl, err := provider.Get(ctx, "cluster-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.GetMetadata(cl).Labels).To(Equal(map[string]string{"region": "eu"}))
+		changed := cl.(cluster.MetadataNotifier).MetadataChanged()
+
+		By("updating the labels of the Secret")
+		newSecret := oldSecret.DeepCopy()
+		newSecret.Labels = map[string]string{"region": "us"}
+		informer.Update(oldSecret, newSecret)
+		Eventually(changed).Should(BeClosed())
+		Expect(cluster.GetMetadata(cl).Labels).To(Equal(map[string]string{"region": "us"}))
+		Expect(oldCtx.Err()).NotTo(HaveOccurred())
+	})
+
+	It("should disengage a cluster when its Secret is deleted", func() {
+		secret := newSecret("cluster-a", "https://a.example.com")
+		informer.Add(secret)
+		Eventually(func() context.Context { return aware.get("cluster-a") }).ShouldNot(BeNil())
+
+		informer.Delete(secret)
+		Eventually(aware.get("cluster-a").Done()).Should(BeClosed())
+		_, err := provider.Get(ctx, "cluster-a")
+		Expect(err).To(MatchError(cluster.ErrClusterNotFound))
+	})
+})
@@ -46,4 +46,12 @@ type Controller struct {
 	// NeedLeaderElection indicates whether the controller needs to use leader election.
 	// Defaults to true, which means the controller will use leader election.
 	NeedLeaderElection *bool
+
+	// Deterministic enables a development mode that makes stepping through reconciles
+	// with a debugger reproducible: every controller runs a single worker, queued
+	// requests are processed ordered by namespace and name instead of their order of
+	// arrival and failed requests are requeued with a plain exponential backoff
+	// instead of the default rate limiter, unless a controller sets its own RateLimiter.
+	// It overrides MaxConcurrentReconciles and must not be used in production.
+	Deterministic bool
 }
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// Runtime contains the options that can be re-applied while the manager is running,
+// e.g. when the process receives SIGHUP.
+type Runtime struct {
+	// LogLevel is the verbosity of the logs, if set. It is applied by the
+	// SetLogLevel func of the reload options of the manager.
+	LogLevel *int
+
+	// GroupKindConcurrency is a map from a Kind to the number of concurrent
+	// reconciliations allowed for the controllers reconciling it, like
+	// Controller.GroupKindConcurrency. Controllers of Kinds that aren't listed keep
+	// their current concurrency.
+	GroupKindConcurrency map[string]int
+
+	// RateLimit, if set, is an overall limit of the rate in which each controller
+	// requeues requests through its rate limiter, i.e. requests whose reconciliation
+	// failed or asked to be requeued without RequeueAfter. It is applied in addition to
+	// the per-item backoff of the rate limiter. Requests enqueued by events are not
+	// limited. If unset, a previously applied limit is removed.
+	RateLimit *RateLimit
+}
+
+// RateLimit is a token bucket rate limit.
+type RateLimit struct {
+	// QPS is the number of tokens added to the bucket per second.
+	QPS float64
+
+	// Burst is the size of the bucket.
+	Burst int
+}
@@ -22,11 +22,18 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
+	"sigs.k8s.io/controller-runtime/pkg/internal/metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
@@ -39,18 +46,53 @@ type Options struct {
 	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 1.
 	MaxConcurrentReconciles int
 
+	// GroupKind is the GroupKind of the objects the controller reconciles, in the form of
+	// GroupKind.String(). When the manager reloads its runtime configuration, the
+	// concurrency listed for it in GroupKindConcurrency is applied to the controller.
+	// Ignored in deterministic mode. It is set by the builder.
+	GroupKind string
+
 	// CacheSyncTimeout refers to the time limit set to wait for syncing caches.
 	// Defaults to 2 minutes if not set.
 	CacheSyncTimeout time.Duration
 
+	// Restartable indicates whether the controller should be restarted with a backoff when
+	// its sources fail to start or their caches fail to sync, e.g. because a watched kind
+	// is served by a flaky aggregated API server, instead of stopping the manager. While
+	// the controller waits to be restarted, the controller_runtime_controller_restarting
+	// metric is 1 and the error is reported as the last error of its Status.
+	// Only controllers whose sources are Kind or Func sources are restarted, as other
+	// sources, e.g. Channel sources, can't be started again; they still return the error.
+	// Defaults to false.
+	Restartable bool
+
+	// RestartRateLimiter determines the delay before a Restartable controller is restarted.
+	// Ignored if Restartable is false. Defaults to an exponential backoff starting at 1
+	// second and capped at 5 minutes.
+	RestartRateLimiter ratelimiter.RateLimiter
+
 	// RecoverPanic indicates whether the panic caused by reconcile should be recovered.
 	// Defaults to the Controller.RecoverPanic setting from the Manager if unset.
 	RecoverPanic *bool
 
+	// PanicHandler is called whenever the Reconciler panics, with the recovered value
+	// and the stack trace of the panic. It is called regardless of RecoverPanic, before
+	// the panic is either recovered or re-raised. Every panic is additionally counted in
+	// the controller_runtime_reconcile_panics_total metric.
+	// See NewEventPanicHandler for a PanicHandler that records an Event on the reconciled object.
+	PanicHandler PanicHandler
+
 	// NeedLeaderElection indicates whether the controller needs to use leader election.
 	// Defaults to true, which means the controller will use leader election.
 	NeedLeaderElection *bool
 
+	// LeaderElectionID makes the controller use its own leader election lease with the
+	// given name instead of the lease of the manager, so that a process can be the leader
+	// for some controllers and a standby for others. The lease uses the leader election
+	// configuration of the manager and is only used if leader election is enabled for
+	// the manager and the controller needs leader election.
+	LeaderElectionID string
+
 	// Reconciler reconciles an object
 	Reconciler reconcile.Reconciler
 
@@ -59,11 +101,123 @@ type Options struct {
 	// The overall is a token bucket and the per-item is exponential.
 	RateLimiter ratelimiter.RateLimiter
 
+	// SkipInitialSync indicates whether the Create events that are emitted for the objects
+	// existing when an informer first syncs should be dropped, so that only changes happening
+	// after the controller started trigger reconciles.
+	// Defaults to false.
+	SkipInitialSync bool
+
+	// InitialSyncRateLimiter throttles the requests enqueued for the Create events emitted
+	// when an informer first syncs, to avoid stampeding external APIs on restart. Every
+	// such request is delayed by the duration returned from the rate limiter, so a token
+	// bucket rate limiter such as workqueue.BucketRateLimiter should be used.
+	// Ignored if SkipInitialSync is true. Defaults to nil, which means no throttling.
+	InitialSyncRateLimiter ratelimiter.RateLimiter
+
 	// LogConstructor is used to construct a logger used for this controller and passed
 	// to each reconciliation via the context field.
 	LogConstructor func(request *reconcile.Request) logr.Logger
+
+	// LockKeyFunc is used to serialize reconciles of different objects that mutate a shared
+	// resource, for example all Pods on a Node or all objects belonging to a tenant.
+	// It is called for every request before it is reconciled and returns the key of the
+	// shared resource. Requests with the same non-empty key are never reconciled in parallel;
+	// a request whose key is held by an in-flight reconcile is deferred until that reconcile
+	// finishes. Returning an empty key disables locking for the request, returning an error
+	// requeues it with rate limiting.
+	// Defaults to nil, which means requests are only serialized per object.
+	LockKeyFunc func(ctx context.Context, req reconcile.Request) (string, error)
+
+	// TenantFunc returns the tenant of a request, for example its namespace or a label
+	// of its namespace, see TenantFromNamespace and TenantFromNamespaceLabel. It is used
+	// to enforce TenantMaxConcurrentReconciles and NewTenantRateLimiter per tenant, so that
+	// a single tenant can't starve the others. Returning an empty tenant exempts the request
+	// from the limits, returning an error requeues it with rate limiting.
+	// Defaults to nil, which means there are no per-tenant limits.
+	TenantFunc TenantFunc
+
+	// TenantMaxConcurrentReconciles is the maximum number of concurrent reconciles of
+	// requests of the same tenant. A request of a tenant at its limit is deferred until
+	// one of the reconciles of the tenant finishes.
+	// Ignored if TenantFunc is nil. Defaults to 0, which means no limit.
+	TenantMaxConcurrentReconciles int
+
+	// NewTenantRateLimiter returns the rate limiter that throttles the reconciles of a
+	// tenant, it is called once for every tenant. The requests of a tenant are delayed by
+	// the duration returned from the rate limiter, so a token bucket rate limiter such as
+	// workqueue.BucketRateLimiter should be used.
+	// Ignored if TenantFunc is nil. Defaults to nil, which means no rate limit.
+	NewTenantRateLimiter func() ratelimiter.RateLimiter
+
+	// ClusterMaxConcurrentReconciles is the maximum number of concurrent reconciles of
+	// requests of the same cluster in multi-cluster mode, so that a single misbehaving
+	// cluster can't consume all workers of the controller. A request of a cluster at its
+	// limit is deferred until one of the reconciles of the cluster finishes. Requests of
+	// the cluster of the manager, which have an empty ClusterName, are exempt.
+	// Defaults to 0, which means no limit.
+	ClusterMaxConcurrentReconciles int
+
+	// NewClusterRateLimiter returns the rate limiter that throttles the reconciles of a
+	// cluster in multi-cluster mode, it is called once for every cluster. The requests of
+	// a cluster are delayed by the duration returned from the rate limiter, so a token
+	// bucket rate limiter such as workqueue.BucketRateLimiter should be used. Requests of
+	// the cluster of the manager are exempt.
+	// Defaults to nil, which means no rate limit.
+	NewClusterRateLimiter func() ratelimiter.RateLimiter
+
+	// ClusterMetrics records metrics with a cluster label for the requests of provider
+	// clusters in multi-cluster mode: the reconcile total, errors and time, the adds,
+	// depth and wait time of the queue, and the REST client requests made through the
+	// context passed to the reconciler. The metrics of the controller without a cluster
+	// label are recorded either way. Requests of the cluster of the manager are exempt.
+	// Defaults to false, as every cluster adds a set of time series.
+	ClusterMetrics bool
+
+	// ClusterSelector restricts the provider clusters the controller engages with to the
+	// ones whose labels, see cluster.Metadata, match the selector. Requests of other
+	// clusters are dropped. Requests of the cluster of the manager are always reconciled.
+	// Clusters implementing cluster.MetadataNotifier, like the clusters of the built-in
+	// providers, are engaged and disengaged as their labels start and stop matching.
+	// Defaults to nil, which means the controller engages with all clusters.
+	ClusterSelector labels.Selector
+
+	// ClusterWatchRateLimiter determines the delay before a source of a cluster watch, see
+	// WatchClusters, that failed to start or sync is retried. The other sources of the
+	// cluster aren't affected, and the pending sources are reported in the Status.
+	// Defaults to an exponential backoff starting at 1 second and capped at 5 minutes.
+	ClusterWatchRateLimiter ratelimiter.RateLimiter
+}
+
+// TenantFunc returns the tenant of a request.
+type TenantFunc func(ctx context.Context, req reconcile.Request) (string, error)
+
+// TenantFromNamespace is a TenantFunc that uses the namespace of a request as its tenant.
+// Requests for cluster scoped objects have no tenant.
+func TenantFromNamespace(_ context.Context, req reconcile.Request) (string, error) {
+	return req.Namespace, nil
+}
+
+// TenantFromNamespaceLabel returns a TenantFunc that uses the value of the given label of
+// the namespace of a request as its tenant. The namespace is read through reader, which
+// should be backed by a cache. Requests for cluster scoped objects and requests in
+// namespaces without the label have no tenant.
+func TenantFromNamespaceLabel(reader client.Reader, label string) TenantFunc {
+	return func(ctx context.Context, req reconcile.Request) (string, error) {
+		if req.Namespace == "" {
+			return "", nil
+		}
+		ns := &corev1.Namespace{}
+		if err := reader.Get(ctx, client.ObjectKey{Name: req.Namespace}, ns); err != nil {
+			return "", fmt.Errorf("failed to get namespace %q: %w", req.Namespace, err)
+		}
+		return ns.Labels[label], nil
+	}
 }
 
+// PanicHandler handles a panic of a Reconciler. recovered is the value returned by recover()
+// and stack is the stack trace of the panicking goroutine.
+type PanicHandler func(ctx context.Context, req reconcile.Request, recovered any, stack []byte)
+
 // Controller implements a Kubernetes API.  A Controller manages a work queue fed reconcile.Requests
 // from source.Sources.  Work is performed through the reconcile.Reconciler for each enqueued item.
 // Work typically is reads and writes Kubernetes objects to make the system state match the state specified
@@ -88,6 +242,144 @@ type Controller interface {
 	GetLogger() logr.Logger
 }
 
+// Status is a point-in-time view of the state of a controller.
+type Status = controller.Status
+
+// ClusterWatchStatus reports the state of the cluster watches of an engaged provider cluster.
+type ClusterWatchStatus = controller.ClusterWatchStatus
+
+// PendingSourceStatus reports a cluster watch source that is retried.
+type PendingSourceStatus = controller.PendingSourceStatus
+
+// QueueSnapshot is a point-in-time view of the queue of a controller.
+type QueueSnapshot = controller.QueueSnapshot
+
+// QueueItem describes a single request in the queue of a controller.
+type QueueItem = controller.QueueItem
+
+// SimulatedEvent is an event run through the watches of a controller by Simulate.
+type SimulatedEvent = controller.SimulatedEvent
+
+// SimulatedEventType is the type of a SimulatedEvent.
+type SimulatedEventType = controller.SimulatedEventType
+
+const (
+	// SimulatedCreate is a create event of SimulatedEvent.Object.
+	SimulatedCreate = controller.SimulatedCreate
+	// SimulatedUpdate is an update event from SimulatedEvent.ObjectOld to SimulatedEvent.Object.
+	SimulatedUpdate = controller.SimulatedUpdate
+	// SimulatedDelete is a delete event of SimulatedEvent.Object.
+	SimulatedDelete = controller.SimulatedDelete
+	// SimulatedGeneric is a generic event of SimulatedEvent.Object.
+	SimulatedGeneric = controller.SimulatedGeneric
+)
+
+// SimulationResult reports how the watches of a controller handled a SimulatedEvent.
+type SimulationResult = controller.SimulationResult
+
+// WatchSimulationResult reports how a single watch handled a SimulatedEvent.
+type WatchSimulationResult = controller.WatchSimulationResult
+
+// clusterWatcher is implemented by controllers that watch the clusters of a cluster
+// provider.
+type clusterWatcher interface {
+	WatchClusters(newSource func(cl cluster.Cluster) source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) error
+	ResyncCluster(ctx context.Context, name string) error
+}
+
+// WatchClusters makes c watch every provider cluster it is engaged with in
+// multi-cluster mode with the source returned by newSource for the cluster, e.g. a
+// Kind source on the cache of the cluster. The Requests enqueued by the event handler
+// get the name of the cluster as their ClusterName. The sources of a cluster are
+// started once c started and stopped once the cluster is disengaged. A source that
+// fails to start or sync is retried without affecting the other sources.
+func WatchClusters(c Controller, newSource func(cl cluster.Cluster) source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	watcher, ok := c.(clusterWatcher)
+	if !ok {
+		return fmt.Errorf("controller %T doesn't support watching provider clusters", c)
+	}
+	return watcher.WatchClusters(newSource, eventhandler, predicates...)
+}
+
+// ResyncCluster starts the sources of the cluster watches of c, see WatchClusters, of
+// the engaged provider cluster with the given name again and waits for them to sync,
+// e.g. after CRDs were installed in the cluster or its cache was refreshed. c stays
+// engaged with the cluster and the watches of other clusters aren't affected. The
+// error reports the sources that didn't sync in time, which are still retried.
+func ResyncCluster(ctx context.Context, c Controller, name string) error {
+	watcher, ok := c.(clusterWatcher)
+	if !ok {
+		return fmt.Errorf("controller %T doesn't support watching provider clusters", c)
+	}
+	return watcher.ResyncCluster(ctx, name)
+}
+
+// queueSnapshotter is implemented by controllers whose queue can be inspected.
+type queueSnapshotter interface {
+	QueueSnapshot() QueueSnapshot
+}
+
+// GetQueueSnapshot returns a point-in-time view of the requests in the queue of c,
+// including their retry counts and ages. It is intended for debugging and returns an
+// empty snapshot if c has not been started yet.
+func GetQueueSnapshot(c Controller) (QueueSnapshot, error) {
+	snapshotter, ok := c.(queueSnapshotter)
+	if !ok {
+		return QueueSnapshot{}, fmt.Errorf("controller %T doesn't report its queue", c)
+	}
+	return snapshotter.QueueSnapshot(), nil
+}
+
+// statusReporter is implemented by controllers that report their status.
+type statusReporter interface {
+	Status() Status
+}
+
+// GetStatus returns a point-in-time view of the state of c, like the kinds it
+// watches, whether its caches are synced, its queue depth and the last reconcile
+// error.
+func GetStatus(c Controller) (Status, error) {
+	reporter, ok := c.(statusReporter)
+	if !ok {
+		return Status{}, fmt.Errorf("controller %T doesn't report its status", c)
+	}
+	return reporter.Status(), nil
+}
+
+// simulator is implemented by controllers that can simulate events.
+type simulator interface {
+	Simulate(ctx context.Context, evt SimulatedEvent) (SimulationResult, error)
+}
+
+// Simulate runs an event through the predicates and event handlers of the watches of c
+// and reports the requests that would be enqueued, without enqueueing or reconciling
+// anything. It is intended to validate the watches of a controller in tests and can
+// only be used before c is started.
+func Simulate(ctx context.Context, c Controller, evt SimulatedEvent) (SimulationResult, error) {
+	sim, ok := c.(simulator)
+	if !ok {
+		return SimulationResult{}, fmt.Errorf("controller %T doesn't support simulating events", c)
+	}
+	return sim.Simulate(ctx, evt)
+}
+
+// drainer is implemented by controllers that report when they stopped.
+type drainer interface {
+	Drained() <-chan struct{}
+}
+
+// Drained returns a channel that is closed once c stopped, i.e. once its Start
+// returned: its queue is shut down and none of its reconciles is in flight anymore.
+// It can be used to wait for a controller to finish its work before another one
+// takes over, e.g. when a controller is replaced or during a migration.
+func Drained(c Controller) (<-chan struct{}, error) {
+	d, ok := c.(drainer)
+	if !ok {
+		return nil, fmt.Errorf("controller %T doesn't report when it stopped", c)
+	}
+	return d.Drained(), nil
+}
+
 // New returns a new Controller registered with the Manager.  The Manager will ensure that shared Caches have
 // been synced before the Controller is Started.
 func New(name string, mgr manager.Manager, options Options) (Controller, error) {
@@ -122,6 +414,9 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 					"object", klog.KRef(req.Namespace, req.Name),
 					"namespace", req.Namespace, "name", req.Name,
 				)
+				if req.ClusterName != "" {
+					log = log.WithValues("cluster", req.ClusterName)
+				}
 			}
 			return log
 		}
@@ -143,8 +438,31 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 		}
 	}
 
+	deterministic := mgr.GetControllerOptions().Deterministic
+	if deterministic {
+		options.MaxConcurrentReconciles = 1
+	}
+
 	if options.RateLimiter == nil {
-		options.RateLimiter = workqueue.DefaultControllerRateLimiter()
+		if deterministic {
+			options.RateLimiter = workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second)
+		} else {
+			options.RateLimiter = workqueue.DefaultControllerRateLimiter()
+		}
+	}
+
+	// The overall rate limit of the queue can be changed when the manager reloads its
+	// runtime configuration, while the concurrency is fixed in deterministic mode.
+	reloadableRateLimiter := controller.NewReloadableRateLimiter(options.RateLimiter)
+	options.RateLimiter = reloadableRateLimiter
+	if deterministic {
+		options.GroupKind = ""
+	}
+
+	if !options.Restartable {
+		options.RestartRateLimiter = nil
+	} else if options.RestartRateLimiter == nil {
+		options.RestartRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Second, 5*time.Minute)
 	}
 
 	if options.RecoverPanic == nil {
@@ -159,18 +477,64 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 	return &controller.Controller{
 		Do: options.Reconciler,
 		MakeQueue: func() workqueue.RateLimitingInterface {
+			if deterministic {
+				return workqueue.NewRateLimitingQueueWithConfig(options.RateLimiter, workqueue.RateLimitingQueueConfig{
+					DelayingQueue: workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{
+						Name:  name,
+						Queue: controller.NewOrderedQueue(name, metrics.WorkqueueMetricsProvider{}),
+					}),
+				})
+			}
 			return workqueue.NewRateLimitingQueueWithConfig(options.RateLimiter, workqueue.RateLimitingQueueConfig{
 				Name: name,
 			})
 		},
-		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-		CacheSyncTimeout:        options.CacheSyncTimeout,
-		Name:                    name,
-		LogConstructor:          options.LogConstructor,
-		RecoverPanic:            options.RecoverPanic,
-		LeaderElected:           options.NeedLeaderElection,
+		MaxConcurrentReconciles:        options.MaxConcurrentReconciles,
+		GroupKind:                      options.GroupKind,
+		ReloadableRateLimiter:          reloadableRateLimiter,
+		CacheSyncTimeout:               options.CacheSyncTimeout,
+		Name:                           name,
+		LogConstructor:                 options.LogConstructor,
+		RecoverPanic:                   options.RecoverPanic,
+		PanicHandler:                   options.PanicHandler,
+		LeaderElected:                  options.NeedLeaderElection,
+		LeaderElectionID:               options.LeaderElectionID,
+		LockKeyFunc:                    options.LockKeyFunc,
+		TenantFunc:                     options.TenantFunc,
+		TenantMaxConcurrentReconciles:  options.TenantMaxConcurrentReconciles,
+		NewTenantRateLimiter:           options.NewTenantRateLimiter,
+		ClusterMaxConcurrentReconciles: options.ClusterMaxConcurrentReconciles,
+		NewClusterRateLimiter:          options.NewClusterRateLimiter,
+		SkipInitialSync:                options.SkipInitialSync,
+		InitialSyncRateLimiter:         options.InitialSyncRateLimiter,
+		RestartRateLimiter:             options.RestartRateLimiter,
+		ClusterMetrics:                 options.ClusterMetrics,
+		ClusterSelector:                options.ClusterSelector,
+		ClusterWatchRateLimiter:        options.ClusterWatchRateLimiter,
+		GetCluster: func(ctx context.Context, name string) (cluster.Cluster, error) {
+			return manager.GetCluster(ctx, mgr, name)
+		},
+		Scheme: mgr.GetScheme(),
 	}, nil
 }
 
 // ReconcileIDFromContext gets the reconcileID from the current context.
 var ReconcileIDFromContext = controller.ReconcileIDFromContext
+
+// NewEventPanicHandler returns a PanicHandler that records a Warning Event with reason
+// ReconcilePanic on the object being reconciled. obj is used as a template for the type of
+// the reconciled object and is never modified. The object is read through reader, failures
+// to do so are logged and otherwise ignored.
+func NewEventPanicHandler(reader client.Reader, recorder record.EventRecorder, obj client.Object) PanicHandler {
+	return func(ctx context.Context, req reconcile.Request, recovered any, _ []byte) {
+		o, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			return
+		}
+		if err := reader.Get(ctx, req.NamespacedName, o); err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to get object to record panic event")
+			return
+		}
+		recorder.Eventf(o, corev1.EventTypeWarning, "ReconcilePanic", "Reconciler panicked: %v", recovered)
+	}
+}
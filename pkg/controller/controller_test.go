@@ -332,5 +332,31 @@ var _ = Describe("controller.Controller", func() {
 			_, ok := c.(manager.LeaderElectionRunnable)
 			Expect(ok).To(BeTrue())
 		})
+
+		It("should report its status and when it stopped", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			c, err := controller.New("status-controller", m, controller.Options{
+				Reconciler: rec,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			status, err := controller.GetStatus(c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Name).To(Equal("status-controller"))
+
+			drained, err := controller.Drained(c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drained).NotTo(BeClosed())
+		})
+	})
+
+	It("should fail for controllers that don't report their status", func() {
+		c := struct{ controller.Controller }{}
+		_, err := controller.GetStatus(c)
+		Expect(err).To(MatchError(ContainSubstring("doesn't report its status")))
+		_, err = controller.Drained(c)
+		Expect(err).To(MatchError(ContainSubstring("doesn't report when it stopped")))
 	})
 })
@@ -274,33 +274,42 @@ const ( // They should complete the sentence "Deployment default/foo has been ..
 // Note: changes made by MutateFn to any sub-resource (status...), will be
 // discarded.
 func CreateOrUpdate(ctx context.Context, c client.Client, obj client.Object, f MutateFn) (OperationResult, error) {
+	result, changed, err := createOrUpdate(ctx, c, obj, f)
+	recordMutation(ctx, c, operationCreateOrUpdate, obj, result, changed, err)
+	return result, err
+}
+
+// createOrUpdate implements CreateOrUpdate and additionally returns the fields that were
+// changed by an update.
+func createOrUpdate(ctx context.Context, c client.Client, obj client.Object, f MutateFn) (OperationResult, []string, error) {
 	key := client.ObjectKeyFromObject(obj)
 	if err := c.Get(ctx, key, obj); err != nil {
 		if !apierrors.IsNotFound(err) {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
 		if err := mutate(f, key, obj); err != nil {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
 		if err := c.Create(ctx, obj); err != nil {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
-		return OperationResultCreated, nil
+		return OperationResultCreated, nil, nil
 	}
 
 	existing := obj.DeepCopyObject()
 	if err := mutate(f, key, obj); err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, nil, err
 	}
 
 	if equality.Semantic.DeepEqual(existing, obj) {
-		return OperationResultNone, nil
+		return OperationResultNone, nil, nil
 	}
 
+	changed := changedFieldsOf(existing, obj)
 	if err := c.Update(ctx, obj); err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, changed, err
 	}
-	return OperationResultUpdated, nil
+	return OperationResultUpdated, changed, nil
 }
 
 // CreateOrPatch creates or patches the given object in the Kubernetes
@@ -317,20 +326,28 @@ func CreateOrUpdate(ctx context.Context, c client.Client, obj client.Object, f M
 // way is to requeue the object in the controller if OperationResult is
 // OperationResultCreated
 func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f MutateFn) (OperationResult, error) {
+	result, changed, err := createOrPatch(ctx, c, obj, f)
+	recordMutation(ctx, c, operationCreateOrPatch, obj, result, changed, err)
+	return result, err
+}
+
+// createOrPatch implements CreateOrPatch and additionally returns the fields that were
+// changed by the patches.
+func createOrPatch(ctx context.Context, c client.Client, obj client.Object, f MutateFn) (OperationResult, []string, error) {
 	key := client.ObjectKeyFromObject(obj)
 	if err := c.Get(ctx, key, obj); err != nil {
 		if !apierrors.IsNotFound(err) {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
 		if f != nil {
 			if err := mutate(f, key, obj); err != nil {
-				return OperationResultNone, err
+				return OperationResultNone, nil, err
 			}
 		}
 		if err := c.Create(ctx, obj); err != nil {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
-		return OperationResultCreated, nil
+		return OperationResultCreated, nil, nil
 	}
 
 	// Create patches for the object and its possible status.
@@ -341,13 +358,13 @@ func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f Mu
 	// unstructured data.
 	before, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
 	if err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, nil, err
 	}
 
 	// Attempt to extract the status from the resource for easier comparison later
 	beforeStatus, hasBeforeStatus, err := unstructured.NestedFieldCopy(before, "status")
 	if err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, nil, err
 	}
 
 	// If the resource contains a status then remove it from the unstructured
@@ -359,20 +376,20 @@ func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f Mu
 	// Mutate the original object.
 	if f != nil {
 		if err := mutate(f, key, obj); err != nil {
-			return OperationResultNone, err
+			return OperationResultNone, nil, err
 		}
 	}
 
 	// Convert the resource to unstructured to compare against our before copy.
 	after, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, nil, err
 	}
 
 	// Attempt to extract the status from the resource for easier comparison later
 	afterStatus, hasAfterStatus, err := unstructured.NestedFieldCopy(after, "status")
 	if err != nil {
-		return OperationResultNone, err
+		return OperationResultNone, nil, err
 	}
 
 	// If the resource contains a status then remove it from the unstructured
@@ -382,11 +399,15 @@ func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f Mu
 	}
 
 	result := OperationResultNone
+	changed := changedFields(before, after)
+	if hasBeforeStatus || hasAfterStatus {
+		changed = append(changed, changedFields(map[string]interface{}{"status": beforeStatus}, map[string]interface{}{"status": afterStatus})...)
+	}
 
 	if !reflect.DeepEqual(before, after) {
 		// Only issue a Patch if the before and after resources (minus status) differ
 		if err := c.Patch(ctx, obj, objPatch); err != nil {
-			return result, err
+			return result, changed, err
 		}
 		result = OperationResultUpdated
 	}
@@ -398,18 +419,18 @@ func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f Mu
 			// If Status was replaced by Patch before, set it to afterStatus
 			objectAfterPatch, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 			if err != nil {
-				return result, err
+				return result, changed, err
 			}
 			if err = unstructured.SetNestedField(objectAfterPatch, afterStatus, "status"); err != nil {
-				return result, err
+				return result, changed, err
 			}
 			// If Status was replaced by Patch before, restore patched structure to the obj
 			if err = runtime.DefaultUnstructuredConverter.FromUnstructured(objectAfterPatch, obj); err != nil {
-				return result, err
+				return result, changed, err
 			}
 		}
 		if err := c.Status().Patch(ctx, obj, statusPatch); err != nil {
-			return result, err
+			return result, changed, err
 		}
 		if result == OperationResultUpdated {
 			result = OperationResultUpdatedStatus
@@ -418,7 +439,7 @@ func CreateOrPatch(ctx context.Context, c client.Client, obj client.Object, f Mu
 		}
 	}
 
-	return result, nil
+	return result, changed, nil
 }
 
 // mutate wraps a MutateFn and applies validation to its result.
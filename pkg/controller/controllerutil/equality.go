@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil
+
+import (
+	"fmt"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	structuralpruning "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// SchemaEquality compares objects the way the API server would see them, by pruning
+// unknown fields and applying the defaults of the structural schemas of their
+// CustomResourceDefinitions before comparing. This allows checking whether an
+// object read from the API server is up to date with a desired object without
+// being thrown off by fields the API server defaulted or dropped.
+type SchemaEquality struct {
+	scheme  *runtime.Scheme
+	schemas map[schema.GroupVersionKind]*structuralschema.Structural
+}
+
+// NewSchemaEquality returns a SchemaEquality using the schemas of all versions of the
+// given CustomResourceDefinitions. The scheme is used to determine the GroupVersionKind
+// of typed objects.
+func NewSchemaEquality(scheme *runtime.Scheme, crds ...*apiextensionsv1.CustomResourceDefinition) (*SchemaEquality, error) {
+	e := &SchemaEquality{
+		scheme:  scheme,
+		schemas: map[schema.GroupVersionKind]*structuralschema.Structural{},
+	}
+	for _, crd := range crds {
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+
+			internal := &apiextensions.JSONSchemaProps{}
+			if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(version.Schema.OpenAPIV3Schema, internal, nil); err != nil {
+				return nil, fmt.Errorf("failed to convert schema of %s version %s: %w", crd.Name, version.Name, err)
+			}
+			structural, err := structuralschema.NewStructural(internal)
+			if err != nil {
+				return nil, fmt.Errorf("schema of %s version %s is not structural: %w", crd.Name, version.Name, err)
+			}
+
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			e.schemas[gvk] = structural
+		}
+	}
+	return e, nil
+}
+
+// DeepEqual returns true if desired and actual are semantically equal after both were
+// normalized with the schema of their kind. Metadata and status are managed by the
+// API server and controllers and are not compared. Objects of a kind without a known
+// schema are compared without normalization.
+func (e *SchemaEquality) DeepEqual(desired, actual client.Object) (bool, error) {
+	desiredContent, err := e.normalize(desired)
+	if err != nil {
+		return false, err
+	}
+	actualContent, err := e.normalize(actual)
+	if err != nil {
+		return false, err
+	}
+	return equality.Semantic.DeepEqual(desiredContent, actualContent), nil
+}
+
+// normalize returns the content of the object as the API server would persist it.
+func (e *SchemaEquality) normalize(obj client.Object) (map[string]interface{}, error) {
+	gvk, err := apiutil.GVKForObject(obj, e.scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to unstructured: %w", gvk, err)
+	}
+	// ToUnstructured may share memory with obj, make sure to not modify it.
+	content = runtime.DeepCopyJSON(content)
+
+	if structural, ok := e.schemas[gvk]; ok {
+		structuralpruning.Prune(content, structural, true)
+		structuraldefaulting.Default(content, structural)
+	}
+
+	delete(content, "apiVersion")
+	delete(content, "kind")
+	delete(content, "metadata")
+	delete(content, "status")
+	return content, nil
+}
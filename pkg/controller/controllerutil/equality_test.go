@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("SchemaEquality", func() {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Widget"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name: "v1",
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"spec": {
+								Type: "object",
+								Properties: map[string]apiextensionsv1.JSONSchemaProps{
+									"size":     {Type: "string"},
+									"replicas": {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte(`1`)}},
+								},
+							},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	widget := func(spec map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Widget")
+		u.SetName("foo")
+		return u
+	}
+
+	It("should ignore fields defaulted or pruned by the API server", func() {
+		equality, err := controllerutil.NewSchemaEquality(scheme.Scheme, crd)
+		Expect(err).NotTo(HaveOccurred())
+
+		desired := widget(map[string]interface{}{"size": "large", "color": "blue"})
+		actual := widget(map[string]interface{}{"size": "large", "replicas": int64(1)})
+		actual.SetResourceVersion("42")
+
+		Expect(equality.DeepEqual(desired, actual)).To(BeTrue())
+		Expect(desired.Object["spec"]).To(HaveKey("color"))
+	})
+
+	It("should detect differences in fields of the schema", func() {
+		equality, err := controllerutil.NewSchemaEquality(scheme.Scheme, crd)
+		Expect(err).NotTo(HaveOccurred())
+
+		desired := widget(map[string]interface{}{"size": "large"})
+		actual := widget(map[string]interface{}{"size": "large", "replicas": int64(3)})
+
+		Expect(equality.DeepEqual(desired, actual)).To(BeFalse())
+	})
+
+	It("should compare objects without a schema without normalizing them", func() {
+		equality, err := controllerutil.NewSchemaEquality(scheme.Scheme)
+		Expect(err).NotTo(HaveOccurred())
+
+		desired := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)}}
+		actual := desired.DeepCopy()
+		actual.ResourceVersion = "42"
+		Expect(equality.DeepEqual(desired, actual)).To(BeTrue())
+
+		actual.Spec.Paused = true
+		Expect(equality.DeepEqual(desired, actual)).To(BeFalse())
+	})
+})
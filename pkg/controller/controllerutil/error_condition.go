@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ReconcileErrorConditionType is the default type of the condition written by
+	// WithErrorCondition.
+	ReconcileErrorConditionType = "ReconcileError"
+
+	// ReconcileSucceededReason is the reason of the condition written by WithErrorCondition
+	// once an object is reconciled successfully after an error.
+	ReconcileSucceededReason = "ReconcileSucceeded"
+
+	defaultErrorConditionMaxMessageLength = 1024
+)
+
+// ErrorConditionOptions are the options of WithErrorCondition.
+type ErrorConditionOptions struct {
+	// ConditionType is the type of the condition. Defaults to ReconcileError.
+	ConditionType string
+
+	// MaxMessageLength is the maximum length of the message of the condition, longer
+	// error messages are truncated. Defaults to 1024.
+	MaxMessageLength int
+
+	// ClassifyError returns the class of an error, which is used as the reason of the
+	// condition and must be a valid condition reason. Defaults to the reason of API
+	// errors, e.g. Forbidden or Conflict, TerminalError for terminal errors, Timeout for
+	// exceeded deadlines and Error otherwise.
+	ClassifyError func(err error) string
+}
+
+// WithErrorCondition wraps a Reconciler so that errors it returns are made visible to the
+// users of the reconciled object: a condition of type ReconcileError, or the configured
+// type, is set to True on the status of the object, with the class of the error as the
+// reason and the truncated error as the message. Once the object is reconciled
+// successfully again, the condition is set to False. obj is an object of the reconciled
+// kind, its status must have a list of metav1.Condition in the conditions field.
+//
+// The condition on the object is compared with the result of every reconcile, and only
+// written if it changes, so that objects failing with the same error in a hot loop
+// don't flood the API server with status writes. The object is read with the client,
+// which usually reads it from the cache.
+//
+// The condition is written with the client of the cluster of the Request, see
+// cluster.FromContext, or c for the cluster of the manager. Failures to write the
+// condition are logged and don't change the result of the reconcile.
+func WithErrorCondition(c client.Client, obj client.Object, r reconcile.Reconciler, opts ErrorConditionOptions) reconcile.Reconciler {
+	if opts.ConditionType == "" {
+		opts.ConditionType = ReconcileErrorConditionType
+	}
+	if opts.MaxMessageLength <= 0 {
+		opts.MaxMessageLength = defaultErrorConditionMaxMessageLength
+	}
+	if opts.ClassifyError == nil {
+		opts.ClassifyError = classifyError
+	}
+	return &errorConditionReconciler{
+		client:     c,
+		obj:        obj,
+		reconciler: r,
+		opts:       opts,
+	}
+}
+
+type errorConditionReconciler struct {
+	client     client.Client
+	obj        client.Object
+	reconciler reconcile.Reconciler
+	opts       ErrorConditionOptions
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *errorConditionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	res, err := r.reconciler.Reconcile(ctx, req)
+
+	condition := metav1.Condition{
+		Type:   r.opts.ConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: ReconcileSucceededReason,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = r.opts.ClassifyError(err)
+		condition.Message = truncateMessage(err.Error(), r.opts.MaxMessageLength)
+	}
+	if writeErr := r.writeCondition(ctx, req, condition); writeErr != nil {
+		logf.FromContext(ctx).V(1).Info("Failed to write reconcile error condition", "error", writeErr.Error())
+	}
+	return res, err
+}
+
+// writeCondition sets the given condition on the status of the object of the request,
+// unless the condition of the object is the same. A False condition is only written if
+// the object has the condition already.
+func (r *errorConditionReconciler) writeCondition(ctx context.Context, req reconcile.Request, condition metav1.Condition) error {
+	c := r.client
+	if req.ClusterName != "" {
+		cl, err := cluster.FromContext(ctx)
+		if err != nil {
+			return err
+		}
+		c = cl.GetClient()
+	}
+
+	gvk, err := apiutil.GVKForObject(r.obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	// Get the object with its type, so that it's read from the cache of a cached client.
+	typed := r.obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, req.NamespacedName, typed); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+	if err != nil {
+		return err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(gvk)
+	original := obj.DeepCopy()
+
+	var status struct {
+		Conditions []metav1.Condition `json:"conditions,omitempty"`
+	}
+	if content, ok := obj.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &status); err != nil {
+			return fmt.Errorf("unable to decode the conditions of %s: %w", gvk.Kind, err)
+		}
+	}
+	if condition.Status == metav1.ConditionFalse && meta.FindStatusCondition(status.Conditions, condition.Type) == nil {
+		return nil
+	}
+	condition.ObservedGeneration = obj.GetGeneration()
+	if !meta.SetStatusCondition(&status.Conditions, condition) {
+		return nil
+	}
+
+	conditions := make([]interface{}, 0, len(status.Conditions))
+	for i := range status.Conditions {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status.Conditions[i])
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, content)
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+	return c.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// classifyError returns the class of err used as the reason of the error condition.
+func classifyError(err error) string {
+	switch {
+	case errors.Is(err, reconcile.TerminalError(nil)):
+		return "TerminalError"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "Timeout"
+	}
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return "Error"
+}
+
+// truncateMessage truncates msg to at most maxLength bytes without splitting runes.
+func truncateMessage(msg string, maxLength int) string {
+	const ellipsis = "..."
+	if len(msg) <= maxLength {
+		return msg
+	}
+	if maxLength <= len(ellipsis) {
+		return ellipsis[:maxLength]
+	}
+	cut := maxLength - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + ellipsis
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("WithErrorCondition", func() {
+	var (
+		ctx          = context.Background()
+		c            client.Client
+		pdb          *policyv1.PodDisruptionBudget
+		req          reconcile.Request
+		reconErr     error
+		r            reconcile.Reconciler
+		statusWrites atomic.Int32
+	)
+
+	condition := func() *metav1.Condition {
+		current := &policyv1.PodDisruptionBudget{}
+		Expect(c.Get(ctx, req.NamespacedName, current)).To(Succeed())
+		return apimeta.FindStatusCondition(current.Status.Conditions, controllerutil.ReconcileErrorConditionType)
+	}
+
+	BeforeEach(func() {
+		pdb = &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb", Generation: 2}}
+		statusWrites.Store(0)
+		c = fake.NewClientBuilder().WithObjects(pdb).WithStatusSubresource(pdb).WithInterceptorFuncs(interceptor.Funcs{
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				statusWrites.Add(1)
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+		req = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pdb)}
+		reconErr = nil
+		r = controllerutil.WithErrorCondition(c, &policyv1.PodDisruptionBudget{}, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{}, reconErr
+		}), controllerutil.ErrorConditionOptions{MaxMessageLength: 20})
+	})
+
+	It("should not write the condition while reconciles succeed", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition()).To(BeNil())
+		Expect(statusWrites.Load()).To(BeZero())
+	})
+
+	It("should write the class and the truncated message of errors until reconciles succeed", func() {
+		reconErr = apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "secret", fmt.Errorf("denied"))
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(Equal(reconErr))
+
+		cond := condition()
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal("Forbidden"))
+		Expect(cond.Message).To(HaveLen(20))
+		Expect(cond.Message).To(HaveSuffix("..."))
+		Expect(cond.ObservedGeneration).To(BeEquivalentTo(2))
+		Expect(statusWrites.Load()).To(BeEquivalentTo(1))
+
+		By("not writing the condition again for the same error")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+		Expect(statusWrites.Load()).To(BeEquivalentTo(1))
+
+		By("writing the class of a different error")
+		reconErr = reconcile.TerminalError(fmt.Errorf("invalid"))
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+		Expect(condition().Reason).To(Equal("TerminalError"))
+		Expect(statusWrites.Load()).To(BeEquivalentTo(2))
+
+		By("clearing the condition once the object is reconciled successfully")
+		reconErr = nil
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		cond = condition()
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(controllerutil.ReconcileSucceededReason))
+		Expect(statusWrites.Load()).To(BeEquivalentTo(3))
+
+		By("not writing the cleared condition again")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusWrites.Load()).To(BeEquivalentTo(3))
+	})
+
+	It("should clear a condition written before the reconciler was created", func() {
+		reconErr = reconcile.TerminalError(fmt.Errorf("invalid"))
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(HaveOccurred())
+
+		restarted := controllerutil.WithErrorCondition(c, &policyv1.PodDisruptionBudget{}, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{}, nil
+		}), controllerutil.ErrorConditionOptions{})
+		_, err = restarted.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition().Status).To(Equal(metav1.ConditionFalse))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	operationCreateOrUpdate = "create_or_update"
+	operationCreateOrPatch  = "create_or_patch"
+
+	resultError    = "error"
+	resultConflict = "conflict"
+
+	// changedFieldsDepth is the depth up to which changed fields are reported,
+	// e.g. "spec.replicas" but not "spec.template.spec".
+	changedFieldsDepth = 2
+)
+
+var (
+	// mutationTotal counts the calls of CreateOrUpdate and CreateOrPatch by result.
+	mutationTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_mutation_total",
+		Help: "Total number of CreateOrUpdate and CreateOrPatch calls per operation, kind and result",
+	}, []string{"operation", "kind", "result"})
+
+	// mutationChangedFieldsTotal counts the fields that were changed by updates of
+	// CreateOrUpdate and CreateOrPatch. Fields that are changed on every reconcile
+	// usually hint at defaulting mismatches between the desired and the actual object.
+	mutationChangedFieldsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_mutation_changed_fields_total",
+		Help: "Total number of fields changed by CreateOrUpdate and CreateOrPatch per operation, kind and field",
+	}, []string{"operation", "kind", "field"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(mutationTotal, mutationChangedFieldsTotal)
+}
+
+// recordMutation records the outcome of a mutation helper in the metrics. Changed fields
+// are also logged with verbosity 5 to find the source of unexpected updates.
+func recordMutation(ctx context.Context, c client.Client, operation string, obj client.Object, result OperationResult, changedFields []string, err error) {
+	kind := mutationKind(c, obj)
+
+	switch {
+	case apierrors.IsConflict(err):
+		mutationTotal.WithLabelValues(operation, kind, resultConflict).Inc()
+	case err != nil:
+		mutationTotal.WithLabelValues(operation, kind, resultError).Inc()
+	default:
+		mutationTotal.WithLabelValues(operation, kind, string(result)).Inc()
+	}
+
+	if len(changedFields) == 0 {
+		return
+	}
+	for _, field := range changedFields {
+		mutationChangedFieldsTotal.WithLabelValues(operation, kind, field).Inc()
+	}
+	logf.FromContext(ctx).V(5).Info("Object differs from its desired state",
+		"operation", operation, "kind", kind, "object", client.ObjectKeyFromObject(obj), "changedFields", changedFields)
+}
+
+// mutationKind returns the group kind of obj, or the name of its type if the client
+// has no scheme that knows the object.
+func mutationKind(c client.Client, obj client.Object) string {
+	if scheme := c.Scheme(); scheme != nil {
+		if gvk, err := apiutil.GVKForObject(obj, scheme); err == nil {
+			return gvk.GroupKind().String()
+		}
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// changedFields returns the sorted paths of the fields that differ between the
+// unstructured contents before and after, summarized up to changedFieldsDepth.
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	collectChangedFields(before, after, nil, &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+func collectChangedFields(before, after map[string]interface{}, path []string, fields *[]string) {
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		b, a := before[k], after[k]
+		if equality.Semantic.DeepEqual(b, a) {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), k)
+		bMap, bIsMap := b.(map[string]interface{})
+		aMap, aIsMap := a.(map[string]interface{})
+		if len(fieldPath) < changedFieldsDepth && (bIsMap || b == nil) && (aIsMap || a == nil) {
+			collectChangedFields(bMap, aMap, fieldPath, fields)
+			continue
+		}
+		*fields = append(*fields, strings.Join(fieldPath, "."))
+	}
+}
+
+// changedFieldsOf returns the changed fields between two objects, see changedFields.
+func changedFieldsOf(before, after runtime.Object) []string {
+	beforeContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(before)
+	if err != nil {
+		return nil
+	}
+	afterContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(after)
+	if err != nil {
+		return nil
+	}
+	return changedFields(beforeContent, afterContent)
+}
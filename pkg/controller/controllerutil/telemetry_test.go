@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllerutil_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var _ = Describe("mutation telemetry", func() {
+	var deploy *appsv1.Deployment
+
+	BeforeEach(func() {
+		deploy = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "telemetry", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+		}
+	})
+
+	mutationMetric := func(name string, labels map[string]string) float64 {
+		families, err := metrics.Registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+		metric:
+			for _, m := range family.GetMetric() {
+				for _, label := range m.GetLabel() {
+					if value, ok := labels[label.GetName()]; ok && value != label.GetValue() {
+						continue metric
+					}
+				}
+				return m.GetCounter().GetValue()
+			}
+		}
+		return 0
+	}
+
+	It("should count updates and the fields they changed", func() {
+		cl := fake.NewClientBuilder().WithObjects(deploy.DeepCopy()).Build()
+		updates := mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment.apps", "result": "updated"})
+		replicas := mutationMetric("controller_runtime_mutation_changed_fields_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment.apps", "field": "spec.replicas"})
+
+		obj := &appsv1.Deployment{ObjectMeta: deploy.ObjectMeta}
+		result, err := controllerutil.CreateOrUpdate(context.Background(), cl, obj, func() error {
+			obj.Spec.Replicas = ptr.To[int32](2)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+
+		Expect(mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment.apps", "result": "updated"})).To(Equal(updates + 1))
+		Expect(mutationMetric("controller_runtime_mutation_changed_fields_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment.apps", "field": "spec.replicas"})).To(Equal(replicas + 1))
+	})
+
+	It("should fall back to the type name if the client has no scheme", func() {
+		cl := &noSchemeClient{Client: fake.NewClientBuilder().WithObjects(deploy.DeepCopy()).Build()}
+		updates := mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment", "result": "updated"})
+
+		obj := &appsv1.Deployment{ObjectMeta: deploy.ObjectMeta}
+		_, err := controllerutil.CreateOrUpdate(context.Background(), cl, obj, func() error {
+			obj.Spec.Replicas = ptr.To[int32](3)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_update", "kind": "Deployment", "result": "updated"})).To(Equal(updates + 1))
+	})
+
+	It("should count conflicts", func() {
+		cl := fake.NewClientBuilder().WithObjects(deploy.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				return apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "telemetry", nil)
+			},
+		}).Build()
+		conflicts := mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_patch", "kind": "Deployment.apps", "result": "conflict"})
+
+		obj := &appsv1.Deployment{ObjectMeta: deploy.ObjectMeta}
+		_, err := controllerutil.CreateOrPatch(context.Background(), cl, obj, func() error {
+			obj.Spec.Paused = true
+			return nil
+		})
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		Expect(mutationMetric("controller_runtime_mutation_total",
+			map[string]string{"operation": "create_or_patch", "kind": "Deployment.apps", "result": "conflict"})).To(Equal(conflicts + 1))
+	})
+})
+
+// noSchemeClient is a client.Client without a scheme.
+type noSchemeClient struct {
+	client.Client
+}
+
+func (c *noSchemeClient) Scheme() *runtime.Scheme {
+	return nil
+}
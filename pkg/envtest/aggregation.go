@@ -0,0 +1,319 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/internal/testing/addr"
+	"sigs.k8s.io/controller-runtime/pkg/internal/testing/certs"
+	"sigs.k8s.io/controller-runtime/pkg/internal/testing/controlplane"
+)
+
+const (
+	// aggregatedServiceName is the name of the Service the APIServices of the test process refer to.
+	aggregatedServiceName = "envtest-aggregated-apiserver"
+	// aggregatedServiceNamespace is the namespace of the Service the APIServices of the test process refer to.
+	aggregatedServiceNamespace = metav1.NamespaceDefault
+
+	// proxyClientName is the name of the user the kube-apiserver authenticates as when
+	// proxying requests to aggregated API servers.
+	proxyClientName = "front-proxy-client"
+)
+
+// apiServiceGVK is the GroupVersionKind of APIServices, which are handled as unstructured
+// objects to not depend on the kube-aggregator.
+var apiServiceGVK = schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"}
+
+// APIService is an API group version that is served by an aggregated API server
+// running in the test process.
+type APIService struct {
+	// Group is the API group of the APIService.
+	Group string
+
+	// Version is the API version of the APIService.
+	Version string
+
+	// GroupPriorityMinimum is the priority of the group. Defaults to 1000.
+	GroupPriorityMinimum int32
+
+	// VersionPriority is the priority of the version within the group. Defaults to 15.
+	VersionPriority int32
+}
+
+// APIServiceInstallOptions are the options for registering aggregated API servers
+// served by the test process.
+type APIServiceInstallOptions struct {
+	// APIServices is a list of APIServices to register. The kube-apiserver proxies
+	// requests for them to LocalServingHost:LocalServingPort.
+	APIServices []APIService
+
+	// LocalServingHost is the host for serving the aggregated APIs on.
+	// it will be automatically populated
+	LocalServingHost string
+
+	// LocalServingPort is the allocated port for serving the aggregated APIs on.
+	// it will be automatically populated by a random available local port
+	LocalServingPort int
+
+	// LocalServingCertDir is the allocated directory for serving certificates and the
+	// CA of the proxy client certificate of the kube-apiserver.
+	// it will be automatically populated by the local temp dir
+	LocalServingCertDir string
+
+	// LocalServingCAData is the CA that can be used to trust the serving certificates
+	// in LocalServingCertDir.
+	LocalServingCAData []byte
+
+	// ProxyClientCAData is the CA of the client certificate the kube-apiserver uses to
+	// proxy requests to aggregated API servers. The aggregated API server should use it
+	// to authenticate the request headers set by the kube-apiserver, it is also written
+	// to requestheader-ca.crt in LocalServingCertDir.
+	// It is only populated if the control plane is started by the Environment.
+	ProxyClientCAData []byte
+
+	// MaxTime is the max time to wait
+	MaxTime time.Duration
+
+	// PollInterval is the interval to check
+	PollInterval time.Duration
+}
+
+// PrepWithoutInstalling populates the host-port and sets up the CA and serving
+// certificates, without registering the APIServices.
+func (o *APIServiceInstallOptions) PrepWithoutInstalling() error {
+	if o.LocalServingPort == 0 {
+		port, host, err := addr.Suggest(o.LocalServingHost)
+		if err != nil {
+			return fmt.Errorf("unable to grab random port for serving aggregated APIs on: %w", err)
+		}
+		o.LocalServingPort = port
+		o.LocalServingHost = host
+	}
+
+	ca, err := certs.NewTinyCA()
+	if err != nil {
+		return fmt.Errorf("unable to set up aggregated API CA: %w", err)
+	}
+	// The kube-apiserver verifies the serving certificate against the DNS name of the Service.
+	serviceName := fmt.Sprintf("%s.%s.svc", aggregatedServiceName, aggregatedServiceNamespace)
+	servingCert, err := ca.NewServingCertForDNSNames([]string{serviceName}, "localhost", o.LocalServingHost)
+	if err != nil {
+		return fmt.Errorf("unable to set up aggregated API serving certs: %w", err)
+	}
+
+	if o.LocalServingCertDir == "" {
+		o.LocalServingCertDir, err = os.MkdirTemp("", "envtest-aggregated-certs-")
+		if err != nil {
+			return fmt.Errorf("unable to create directory for aggregated API serving certs: %w", err)
+		}
+	}
+
+	certData, keyData, err := servingCert.AsBytes()
+	if err != nil {
+		return fmt.Errorf("unable to marshal aggregated API serving certs: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(o.LocalServingCertDir, "tls.crt"), certData, 0640); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to write aggregated API serving cert to disk: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(o.LocalServingCertDir, "tls.key"), keyData, 0640); err != nil { //nolint:gosec
+		return fmt.Errorf("unable to write aggregated API serving key to disk: %w", err)
+	}
+
+	o.LocalServingCAData = ca.CA.CertBytes()
+	return nil
+}
+
+// configureAPIServer configures the kube-apiserver with a client certificate to proxy
+// requests to aggregated API servers.
+func (o *APIServiceInstallOptions) configureAPIServer(apiServer *controlplane.APIServer) error {
+	ca, err := certs.NewTinyCA()
+	if err != nil {
+		return fmt.Errorf("unable to set up proxy client CA: %w", err)
+	}
+	clientCert, err := ca.NewClientCert(certs.ClientInfo{Name: proxyClientName})
+	if err != nil {
+		return fmt.Errorf("unable to set up proxy client cert: %w", err)
+	}
+	certData, keyData, err := clientCert.AsBytes()
+	if err != nil {
+		return fmt.Errorf("unable to marshal proxy client cert: %w", err)
+	}
+	o.ProxyClientCAData = ca.CA.CertBytes()
+
+	files := map[string][]byte{
+		"proxy-client.crt":     certData,
+		"proxy-client.key":     keyData,
+		"requestheader-ca.crt": o.ProxyClientCAData,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(o.LocalServingCertDir, name), data, 0640); err != nil { //nolint:gosec
+			return fmt.Errorf("unable to write %s to disk: %w", name, err)
+		}
+	}
+
+	apiServer.Configure().
+		Set("proxy-client-cert-file", filepath.Join(o.LocalServingCertDir, "proxy-client.crt")).
+		Set("proxy-client-key-file", filepath.Join(o.LocalServingCertDir, "proxy-client.key")).
+		Set("requestheader-client-ca-file", filepath.Join(o.LocalServingCertDir, "requestheader-ca.crt")).
+		Set("requestheader-allowed-names", proxyClientName).
+		Set("requestheader-username-headers", "X-Remote-User").
+		Set("requestheader-group-headers", "X-Remote-Group").
+		Set("requestheader-extra-headers-prefix", "X-Remote-Extra-")
+	return nil
+}
+
+// Install registers the APIServices with the API server. The Service they refer to
+// points to LocalServingHost:LocalServingPort.
+func (o *APIServiceInstallOptions) Install(config *rest.Config) error {
+	if len(o.APIServices) == 0 {
+		return nil
+	}
+	defaultAPIServiceOptions(o)
+
+	if len(o.LocalServingCAData) == 0 {
+		if err := o.PrepWithoutInstalling(); err != nil {
+			return err
+		}
+	}
+
+	cs, err := client.New(config, client.Options{})
+	if err != nil {
+		return err
+	}
+
+	// The kube-apiserver resolves ExternalName Services to their external name, which
+	// allows proxying to the test process without a cluster network.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: aggregatedServiceName, Namespace: aggregatedServiceNamespace},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: o.LocalServingHost,
+			Ports:        []corev1.ServicePort{{Name: "https", Port: int32(o.LocalServingPort)}},
+		},
+	}
+	if err := ensureCreated(cs, svc); err != nil {
+		return err
+	}
+
+	for _, apiService := range o.APIServices {
+		if err := ensureCreated(cs, o.apiServiceObject(apiService)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForAvailable waits for the APIServices to become available, i.e. until the
+// kube-apiserver was able to reach the aggregated API server of the test process.
+// It must be called after the aggregated API server was started.
+func (o *APIServiceInstallOptions) WaitForAvailable(ctx context.Context, config *rest.Config) error {
+	defaultAPIServiceOptions(o)
+
+	cs, err := client.New(config, client.Options{})
+	if err != nil {
+		return err
+	}
+
+	return wait.PollUntilContextTimeout(ctx, o.PollInterval, o.MaxTime, true, func(ctx context.Context) (bool, error) {
+		for _, apiService := range o.APIServices {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(apiServiceGVK)
+			if err := cs.Get(ctx, client.ObjectKey{Name: apiServiceName(apiService)}, obj); err != nil {
+				return false, nil //nolint:nilerr
+			}
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			available := false
+			for _, c := range conditions {
+				condition, ok := c.(map[string]interface{})
+				if ok && condition["type"] == "Available" && condition["status"] == string(metav1.ConditionTrue) {
+					available = true
+					break
+				}
+			}
+			if !available {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// Cleanup cleans up cert directories.
+func (o *APIServiceInstallOptions) Cleanup() error {
+	if o.LocalServingCertDir != "" {
+		return os.RemoveAll(o.LocalServingCertDir)
+	}
+	return nil
+}
+
+// apiServiceObject returns the APIService object registering the given APIService.
+func (o *APIServiceInstallOptions) apiServiceObject(apiService APIService) *unstructured.Unstructured {
+	groupPriorityMinimum := apiService.GroupPriorityMinimum
+	if groupPriorityMinimum == 0 {
+		groupPriorityMinimum = 1000
+	}
+	versionPriority := apiService.VersionPriority
+	if versionPriority == 0 {
+		versionPriority = 15
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group":                apiService.Group,
+			"version":              apiService.Version,
+			"groupPriorityMinimum": int64(groupPriorityMinimum),
+			"versionPriority":      int64(versionPriority),
+			"caBundle":             base64.StdEncoding.EncodeToString(o.LocalServingCAData),
+			"service": map[string]interface{}{
+				"name":      aggregatedServiceName,
+				"namespace": aggregatedServiceNamespace,
+				"port":      int64(o.LocalServingPort),
+			},
+		},
+	}}
+	obj.SetGroupVersionKind(apiServiceGVK)
+	obj.SetName(apiServiceName(apiService))
+	return obj
+}
+
+func apiServiceName(apiService APIService) string {
+	return apiService.Version + "." + apiService.Group
+}
+
+// defaultAPIServiceOptions sets the default values for APIServices.
+func defaultAPIServiceOptions(o *APIServiceInstallOptions) {
+	if o.MaxTime == 0 {
+		o.MaxTime = defaultMaxWait
+	}
+	if o.PollInterval == 0 {
+		o.PollInterval = defaultPollInterval
+	}
+}
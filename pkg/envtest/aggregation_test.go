@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/testing/controlplane"
+)
+
+var _ = Describe("APIServiceInstallOptions", func() {
+	var opts *APIServiceInstallOptions
+
+	BeforeEach(func() {
+		opts = &APIServiceInstallOptions{
+			APIServices: []APIService{{Group: "metrics.example.com", Version: "v1beta1"}},
+		}
+		Expect(opts.PrepWithoutInstalling()).To(Succeed())
+		DeferCleanup(opts.Cleanup)
+	})
+
+	It("should set up serving certificates valid for the Service of the APIServices", func() {
+		Expect(opts.LocalServingPort).NotTo(BeZero())
+		Expect(opts.LocalServingHost).NotTo(BeEmpty())
+
+		certData, err := os.ReadFile(filepath.Join(opts.LocalServingCertDir, "tls.crt"))
+		Expect(err).NotTo(HaveOccurred())
+		block, _ := pem.Decode(certData)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+
+		roots := x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(opts.LocalServingCAData)).To(BeTrue())
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "envtest-aggregated-apiserver.default.svc"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should configure the kube-apiserver to proxy requests", func() {
+		apiServer := &controlplane.APIServer{}
+		Expect(opts.configureAPIServer(apiServer)).To(Succeed())
+		Expect(opts.ProxyClientCAData).NotTo(BeEmpty())
+
+		args := apiServer.Configure()
+		Expect(args.Get("proxy-client-cert-file").Get(nil)).To(ConsistOf(filepath.Join(opts.LocalServingCertDir, "proxy-client.crt")))
+		Expect(args.Get("requestheader-client-ca-file").Get(nil)).To(ConsistOf(filepath.Join(opts.LocalServingCertDir, "requestheader-ca.crt")))
+		Expect(filepath.Join(opts.LocalServingCertDir, "proxy-client.key")).To(BeAnExistingFile())
+	})
+
+	It("should register the APIServices through the Service of the test process", func() {
+		obj := opts.apiServiceObject(opts.APIServices[0])
+		Expect(obj.GetName()).To(Equal("v1beta1.metrics.example.com"))
+		port, _, _ := unstructured.NestedInt64(obj.Object, "spec", "service", "port")
+		Expect(port).To(Equal(int64(opts.LocalServingPort)))
+		priority, _, _ := unstructured.NestedInt64(obj.Object, "spec", "groupPriorityMinimum")
+		Expect(priority).To(Equal(int64(1000)))
+		caBundle, _, _ := unstructured.NestedString(obj.Object, "spec", "caBundle")
+		Expect(caBundle).To(Equal(base64.StdEncoding.EncodeToString(opts.LocalServingCAData)))
+	})
+})
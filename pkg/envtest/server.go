@@ -132,6 +132,11 @@ type Environment struct {
 	// WebhookInstallOptions are the options for installing webhooks.
 	WebhookInstallOptions WebhookInstallOptions
 
+	// APIServiceInstallOptions are the options for registering aggregated API servers
+	// served by the test process. If APIServices are set, the kube-apiserver is
+	// configured to proxy requests for them to the test process.
+	APIServiceInstallOptions APIServiceInstallOptions
+
 	// ErrorIfCRDPathMissing provides an interface for the underlying
 	// CRDInstallOptions.ErrorIfPathMissing. It prevents silent failures
 	// for missing CRD paths.
@@ -186,6 +191,10 @@ func (te *Environment) Stop() error {
 		return err
 	}
 
+	if err := te.APIServiceInstallOptions.Cleanup(); err != nil {
+		return err
+	}
+
 	if te.useExistingCluster() {
 		return nil
 	}
@@ -245,6 +254,15 @@ func (te *Environment) Start() (*rest.Config, error) {
 		apiServer.StartTimeout = te.ControlPlaneStartTimeout
 		apiServer.StopTimeout = te.ControlPlaneStopTimeout
 
+		if len(te.APIServiceInstallOptions.APIServices) > 0 {
+			if err := te.APIServiceInstallOptions.PrepWithoutInstalling(); err != nil {
+				return nil, err
+			}
+			if err := te.APIServiceInstallOptions.configureAPIServer(apiServer); err != nil {
+				return nil, err
+			}
+		}
+
 		log.V(1).Info("starting control plane")
 		if err := te.startControlPlane(); err != nil {
 			return nil, fmt.Errorf("unable to start control plane itself: %w", err)
@@ -300,6 +318,11 @@ func (te *Environment) Start() (*rest.Config, error) {
 	if err := te.WebhookInstallOptions.Install(te.Config); err != nil {
 		return nil, fmt.Errorf("unable to install webhooks onto control plane: %w", err)
 	}
+
+	log.V(1).Info("installing APIServices")
+	if err := te.APIServiceInstallOptions.Install(te.Config); err != nil {
+		return nil, fmt.Errorf("unable to install APIServices onto control plane: %w", err)
+	}
 	return te.Config, nil
 }
 
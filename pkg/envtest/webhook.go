@@ -332,7 +332,7 @@ func createWebhooks(config *rest.Config, mutHooks []*admissionv1.MutatingWebhook
 // ensureCreated creates or update object if already exists in the cluster.
 func ensureCreated(cs client.Client, obj client.Object) error {
 	existing := obj.DeepCopyObject().(client.Object)
-	err := cs.Get(context.Background(), client.ObjectKey{Name: obj.GetName()}, existing)
+	err := cs.Get(context.Background(), client.ObjectKeyFromObject(obj), existing)
 	switch {
 	case apierrors.IsNotFound(err):
 		if err := cs.Create(context.Background(), obj); err != nil {
@@ -341,7 +341,7 @@ func ensureCreated(cs client.Client, obj client.Object) error {
 	case err != nil:
 		return err
 	default:
-		log.V(1).Info("Webhook configuration already exists, updating", "webhook", obj.GetName())
+		log.V(1).Info("Object already exists, updating", "object", client.ObjectKeyFromObject(obj))
 		obj.SetResourceVersion(existing.GetResourceVersion())
 		if err := cs.Update(context.Background(), obj); err != nil {
 			return err
@@ -23,6 +23,10 @@ import "sigs.k8s.io/controller-runtime/pkg/client"
 type CreateEvent struct {
 	// Object is the object from the event
 	Object client.Object
+
+	// IsInInitialList is true if the Create event was triggered by the initial list of
+	// an informer, i.e. the object existed before the watch was started.
+	IsInInitialList bool
 }
 
 // UpdateEvent is an event where a Kubernetes object was updated.  UpdateEvent should be generated
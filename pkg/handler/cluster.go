@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ForCluster returns an EventHandler that sets the ClusterName of the Requests
+// enqueued by handler to the given cluster name. It is used to watch a cluster
+// engaged by the cluster provider of the manager, so that the reconciler can
+// determine which cluster an event came from, see cluster.FromContext.
+func ForCluster(clusterName string, handler EventHandler) EventHandler {
+	return &forCluster{clusterName: clusterName, handler: handler}
+}
+
+var _ EventHandler = &forCluster{}
+
+type forCluster struct {
+	clusterName string
+	handler     EventHandler
+}
+
+// Create implements EventHandler.
+func (e *forCluster) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Create(ctx, evt, e.queue(q))
+}
+
+// Update implements EventHandler.
+func (e *forCluster) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Update(ctx, evt, e.queue(q))
+}
+
+// Delete implements EventHandler.
+func (e *forCluster) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Delete(ctx, evt, e.queue(q))
+}
+
+// Generic implements EventHandler.
+func (e *forCluster) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Generic(ctx, evt, e.queue(q))
+}
+
+func (e *forCluster) queue(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	return &clusterQueue{RateLimitingInterface: q, clusterName: e.clusterName}
+}
+
+// clusterQueue sets the ClusterName of the Requests added to the queue.
+type clusterQueue struct {
+	workqueue.RateLimitingInterface
+	clusterName string
+}
+
+func (q *clusterQueue) withClusterName(item interface{}) interface{} {
+	if req, ok := item.(reconcile.Request); ok {
+		req.ClusterName = q.clusterName
+		return req
+	}
+	return item
+}
+
+// Add implements workqueue.Interface.
+func (q *clusterQueue) Add(item interface{}) {
+	q.RateLimitingInterface.Add(q.withClusterName(item))
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *clusterQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.RateLimitingInterface.AddAfter(q.withClusterName(item), duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *clusterQueue) AddRateLimited(item interface{}) {
+	q.RateLimitingInterface.AddRateLimited(q.withClusterName(item))
+}
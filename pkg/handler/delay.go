@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// Delays are the delays of the Requests enqueued for each type of event, see WithDelays.
+type Delays struct {
+	// Create is the delay of the Requests enqueued for CreateEvents.
+	Create time.Duration
+
+	// Update is the delay of the Requests enqueued for UpdateEvents.
+	Update time.Duration
+
+	// Delete is the delay of the Requests enqueued for DeleteEvents.
+	Delete time.Duration
+
+	// Generic is the delay of the Requests enqueued for GenericEvents.
+	Generic time.Duration
+}
+
+// WithDelay returns an EventHandler that delays the Requests enqueued by handler for
+// all events by the given delay, see WithDelays.
+func WithDelay(handler EventHandler, delay time.Duration) EventHandler {
+	return WithDelays(handler, Delays{Create: delay, Update: delay, Delete: delay, Generic: delay})
+}
+
+// WithDelays returns an EventHandler that delays the Requests enqueued by handler by
+// the delay of the type of the event, e.g. to let new Nodes settle before they are
+// reconciled instead of returning RequeueAfter from the reconciler:
+//
+//	handler.WithDelays(&handler.EnqueueRequestForObject{}, handler.Delays{Create: 30 * time.Second})
+//
+// Requests that are added with a delay by handler are enqueued after the longer of
+// both delays, and rate limited Requests aren't delayed further. As the queue
+// deduplicates Requests, a delayed Request is processed early if the same Request is
+// enqueued without delay in the meantime.
+func WithDelays(handler EventHandler, delays Delays) EventHandler {
+	return &withDelays{handler: handler, delays: delays}
+}
+
+var _ EventHandler = &withDelays{}
+
+type withDelays struct {
+	handler EventHandler
+	delays  Delays
+}
+
+// Create implements EventHandler.
+func (e *withDelays) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Create(ctx, evt, delayQueue(q, e.delays.Create))
+}
+
+// Update implements EventHandler.
+func (e *withDelays) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Update(ctx, evt, delayQueue(q, e.delays.Update))
+}
+
+// Delete implements EventHandler.
+func (e *withDelays) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Delete(ctx, evt, delayQueue(q, e.delays.Delete))
+}
+
+// Generic implements EventHandler.
+func (e *withDelays) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.handler.Generic(ctx, evt, delayQueue(q, e.delays.Generic))
+}
+
+func delayQueue(q workqueue.RateLimitingInterface, delay time.Duration) workqueue.RateLimitingInterface {
+	if delay <= 0 {
+		return q
+	}
+	return &delayedQueue{RateLimitingInterface: q, delay: delay}
+}
+
+// delayedQueue delays the items added to the queue.
+type delayedQueue struct {
+	workqueue.RateLimitingInterface
+	delay time.Duration
+}
+
+// Add implements workqueue.Interface.
+func (q *delayedQueue) Add(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *delayedQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.RateLimitingInterface.AddAfter(item, max(duration, q.delay))
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// HubOwnerNamespaceAnnotationSuffix and HubOwnerNameAnnotationSuffix are appended to
+	// the annotation prefix of EnqueueRequestForHubOwner to form the annotations that
+	// hold the namespace and the name of the owner in the hub cluster.
+	HubOwnerNamespaceAnnotationSuffix = "/owner-namespace"
+	HubOwnerNameAnnotationSuffix      = "/owner-name"
+)
+
+var _ EventHandler = &enqueueRequestForHubOwner{}
+
+// EnqueueRequestForHubOwner enqueues Requests for the owners of objects in provider
+// clusters that live in the hub cluster, i.e. the cluster of the manager. Owner
+// references can't cross clusters, so the owner is recorded in the annotations
+// <annotationPrefix>/owner-namespace and <annotationPrefix>/owner-name of the object
+// instead, see SetHubOwner. Objects without the name annotation are ignored.
+//
+// The Requests have an empty ClusterName, so the handler must not be wrapped with
+// ForCluster, which would set the ClusterName to the provider cluster of the object.
+func EnqueueRequestForHubOwner(annotationPrefix string) EventHandler {
+	return &enqueueRequestForHubOwner{
+		namespaceAnnotation: annotationPrefix + HubOwnerNamespaceAnnotationSuffix,
+		nameAnnotation:      annotationPrefix + HubOwnerNameAnnotationSuffix,
+	}
+}
+
+// SetHubOwner records owner as the owner of obj in the hub cluster in the annotations
+// read by EnqueueRequestForHubOwner with the same annotationPrefix.
+func SetHubOwner(obj client.Object, annotationPrefix string, owner client.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if owner.GetNamespace() != "" {
+		annotations[annotationPrefix+HubOwnerNamespaceAnnotationSuffix] = owner.GetNamespace()
+	} else {
+		delete(annotations, annotationPrefix+HubOwnerNamespaceAnnotationSuffix)
+	}
+	annotations[annotationPrefix+HubOwnerNameAnnotationSuffix] = owner.GetName()
+	obj.SetAnnotations(annotations)
+}
+
+type enqueueRequestForHubOwner struct {
+	namespaceAnnotation string
+	nameAnnotation      string
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestForHubOwner) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestForHubOwner) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.ObjectOld, reqs)
+	e.addOwnerRequest(evt.ObjectNew, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestForHubOwner) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestForHubOwner) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// addOwnerRequest adds a Request for the hub owner recorded in the annotations of object.
+func (e *enqueueRequestForHubOwner) addOwnerRequest(object metav1.Object, result map[reconcile.Request]empty) {
+	if object == nil {
+		return
+	}
+	annotations := object.GetAnnotations()
+	name := annotations[e.nameAnnotation]
+	if name == "" {
+		return
+	}
+	result[reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: annotations[e.namespaceAnnotation],
+		Name:      name,
+	}}] = empty{}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var indexLog = logf.RuntimeLog.WithName("eventhandler").WithName("enqueueRequestsFromIndex")
+
+// IndexKeyFunc returns the values of a field index the dependents of an object are
+// looked up by, see EnqueueRequestsFromIndex.
+type IndexKeyFunc func(obj client.Object) []string
+
+// EnqueueRequestsFromIndex enqueues Requests for the objects of dependentType whose values
+// of the field index indexName match one of the keys returned by keyFn for the object that
+// was the source of the Event. The dependents are listed from reader, which must have the
+// index for dependentType, usually the cache of the manager. This replaces the common
+// MapFunc that lists the dependents with MatchingFields.
+//
+// If Foos are indexed by the name of the ConfigMap in spec.configMapName, users may
+// reconcile the Foos in response to ConfigMap Events using:
+//
+//	handler.EnqueueRequestsFromIndex(mgr.GetCache(), mgr.GetScheme(), &Foo{}, "spec.configMapName",
+//		func(obj client.Object) []string { return []string{obj.GetName()} })
+//
+// The dependents of namespaced objects are only looked up in the namespace of the object,
+// the dependents of cluster-scoped objects in all namespaces. For UpdateEvents, keyFn is
+// run on both objects and the dependents of both are enqueued.
+//
+// It panics if the list type of dependentType isn't registered in scheme.
+func EnqueueRequestsFromIndex(reader client.Reader, scheme *runtime.Scheme, dependentType client.Object, indexName string, keyFn IndexKeyFunc) EventHandler {
+	return &enqueueRequestsFromIndex{
+		reader:    reader,
+		list:      newObjectList(scheme, dependentType),
+		indexName: indexName,
+		keyFn:     keyFn,
+	}
+}
+
+var _ EventHandler = &enqueueRequestsFromIndex{}
+
+type enqueueRequestsFromIndex struct {
+	// reader lists the dependents through the index.
+	reader client.Reader
+
+	// list is an empty list of the dependent type, copied for every lookup.
+	list client.ObjectList
+
+	// indexName is the name of the field index.
+	indexName string
+
+	// keyFn returns the values of the index to look up.
+	keyFn IndexKeyFunc
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestsFromIndex) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getDependentReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestsFromIndex) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getDependentReconcileRequests(ctx, evt.ObjectOld, reqs)
+	e.getDependentReconcileRequests(ctx, evt.ObjectNew, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestsFromIndex) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getDependentReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestsFromIndex) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getDependentReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// getDependentReconcileRequests lists the dependents of object and adds a reconcile.Request
+// for each of them to result.
+func (e *enqueueRequestsFromIndex) getDependentReconcileRequests(ctx context.Context, object client.Object, result map[reconcile.Request]empty) {
+	if object == nil {
+		return
+	}
+
+	for _, key := range e.keyFn(object) {
+		list := e.list.DeepCopyObject().(client.ObjectList)
+		if err := e.reader.List(ctx, list, client.InNamespace(object.GetNamespace()), client.MatchingFields{e.indexName: key}); err != nil {
+			indexLog.Error(err, "Could not list dependents", "index", e.indexName, "key", key,
+				"object", client.ObjectKeyFromObject(object))
+			continue
+		}
+		if err := meta.EachListItem(list, func(item runtime.Object) error {
+			if dependent, ok := item.(client.Object); ok {
+				result[reconcile.Request{NamespacedName: client.ObjectKeyFromObject(dependent)}] = empty{}
+			}
+			return nil
+		}); err != nil {
+			indexLog.Error(err, "Could not extract dependents", "index", e.indexName)
+		}
+	}
+}
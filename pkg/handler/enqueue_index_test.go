@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("EnqueueRequestsFromIndex", func() {
+	var (
+		ctx = context.Background()
+		q   workqueue.RateLimitingInterface
+		cl  client.Client
+	)
+
+	newPod := func(namespace, name, nodeName, configMap string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Volumes: []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}},
+				}}},
+			},
+		}
+	}
+	byName := func(obj client.Object) []string {
+		return []string{obj.GetName()}
+	}
+	dequeueAll := func() []reconcile.Request {
+		var reqs []reconcile.Request
+		for q.Len() > 0 {
+			i, _ := q.Get()
+			reqs = append(reqs, i.(reconcile.Request))
+		}
+		return reqs
+	}
+
+	BeforeEach(func() {
+		q = &controllertest.Queue{Interface: workqueue.New()}
+		cl = fake.NewClientBuilder().
+			WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+				return []string{obj.(*corev1.Pod).Spec.NodeName}
+			}).
+			WithIndex(&corev1.Pod{}, "spec.volumes.configMap", func(obj client.Object) []string {
+				var names []string
+				for _, v := range obj.(*corev1.Pod).Spec.Volumes {
+					if v.ConfigMap != nil {
+						names = append(names, v.ConfigMap.Name)
+					}
+				}
+				return names
+			}).
+			WithObjects(
+				newPod("biz", "a", "node-1", "config"),
+				newPod("biz", "b", "node-2", "config"),
+				newPod("baz", "c", "node-1", "config"),
+				newPod("biz", "d", "node-2", "other"),
+			).
+			Build()
+	})
+
+	It("should enqueue the dependents of a namespaced object in its namespace", func() {
+		instance := handler.EnqueueRequestsFromIndex(cl, scheme.Scheme, &corev1.Pod{}, "spec.volumes.configMap", byName)
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "config"}}
+		instance.Update(ctx, event.UpdateEvent{ObjectOld: configMap, ObjectNew: configMap}, q)
+
+		Expect(dequeueAll()).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "a"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "b"}},
+		))
+	})
+
+	It("should enqueue the dependents of a cluster-scoped object in all namespaces", func() {
+		instance := handler.EnqueueRequestsFromIndex(cl, scheme.Scheme, &corev1.Pod{}, "spec.nodeName", byName)
+		instance.Delete(ctx, event.DeleteEvent{Object: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}, q)
+
+		Expect(dequeueAll()).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "a"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "baz", Name: "c"}},
+		))
+	})
+
+	It("should enqueue the dependents of all keys", func() {
+		instance := handler.EnqueueRequestsFromIndex(cl, scheme.Scheme, &corev1.Pod{}, "spec.nodeName", func(client.Object) []string {
+			return []string{"node-1", "node-2"}
+		})
+		instance.Generic(ctx, event.GenericEvent{Object: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}, q)
+
+		Expect(dequeueAll()).To(HaveLen(4))
+	})
+})
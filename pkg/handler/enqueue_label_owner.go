@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// LabelOwnerGroupKindSuffix is appended to the name label of EnqueueRequestForLabelOwner
+// to form the label that holds the group kind of the owner.
+const LabelOwnerGroupKindSuffix = "-group-kind"
+
+var _ EventHandler = &enqueueRequestForLabelOwner{}
+
+// EnqueueRequestForLabelOwner enqueues Requests for the owners of objects that are
+// recorded in the labels nameLabel and namespaceLabel of the objects instead of their
+// owner references, e.g. for owners in other namespaces or clusters, which owner
+// references can't express. The keys are looked up in the labels and then in the
+// annotations of an object, as owner names can be too long for label values, see
+// SetLabelOwner. Objects without the name key are ignored, and so are objects whose
+// <nameLabel>-group-kind label records an owner of another group kind than groupKind.
+//
+// If a Foo in namespace a owns ConfigMaps in namespace b, users may reconcile the Foo in
+// response to ConfigMap Events using:
+//
+//	handler.EnqueueRequestForLabelOwner(schema.GroupKind{Group: "example.com", Kind: "Foo"},
+//		"example.com/owner-name", "example.com/owner-namespace")
+//
+// Unlike owner references, the labels don't make the garbage collector delete the
+// objects with their owner.
+func EnqueueRequestForLabelOwner(groupKind schema.GroupKind, nameLabel, namespaceLabel string) EventHandler {
+	return &enqueueRequestForLabelOwner{
+		groupKind:      groupKind.String(),
+		nameLabel:      nameLabel,
+		namespaceLabel: namespaceLabel,
+		groupKindLabel: nameLabel + LabelOwnerGroupKindSuffix,
+	}
+}
+
+// SetLabelOwner records owner of the given group kind as the owner of obj in the labels
+// read by EnqueueRequestForLabelOwner with the same nameLabel and namespaceLabel. Values
+// that aren't valid label values are recorded as annotations instead.
+func SetLabelOwner(obj client.Object, owner client.Object, groupKind schema.GroupKind, nameLabel, namespaceLabel string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := obj.GetAnnotations()
+	set := func(key, value string) {
+		delete(labels, key)
+		delete(annotations, key)
+		if value == "" {
+			return
+		}
+		if len(validation.IsValidLabelValue(value)) == 0 {
+			labels[key] = value
+			return
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+	}
+	set(nameLabel, owner.GetName())
+	set(namespaceLabel, owner.GetNamespace())
+	set(nameLabel+LabelOwnerGroupKindSuffix, groupKind.String())
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+}
+
+type enqueueRequestForLabelOwner struct {
+	groupKind      string
+	nameLabel      string
+	namespaceLabel string
+	groupKindLabel string
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestForLabelOwner) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestForLabelOwner) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.ObjectOld, reqs)
+	e.addOwnerRequest(evt.ObjectNew, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestForLabelOwner) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestForLabelOwner) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.addOwnerRequest(evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// addOwnerRequest adds a Request for the owner recorded in the labels of object.
+func (e *enqueueRequestForLabelOwner) addOwnerRequest(object metav1.Object, result map[reconcile.Request]empty) {
+	if object == nil {
+		return
+	}
+	name := e.lookup(object, e.nameLabel)
+	if name == "" {
+		return
+	}
+	if groupKind := e.lookup(object, e.groupKindLabel); groupKind != "" && groupKind != e.groupKind {
+		return
+	}
+	result[reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: e.lookup(object, e.namespaceLabel),
+		Name:      name,
+	}}] = empty{}
+}
+
+// lookup returns the value of key in the labels of object, or else in its annotations.
+func (e *enqueueRequestForLabelOwner) lookup(object metav1.Object, key string) string {
+	if value, ok := object.GetLabels()[key]; ok {
+		return value
+	}
+	return object.GetAnnotations()[key]
+}
@@ -18,13 +18,32 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+var mapLog = logf.RuntimeLog.WithName("eventhandler").WithName("enqueueRequestsFromMapFunc")
+
+// mapFuncErrors counts the errors returned by the map functions of EnqueueRequestsFromMapFuncWithError.
+var mapFuncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "controller_runtime_map_func_errors_total",
+	Help: "Total number of errors returned by the map functions of event handlers per name",
+}, []string{"name"})
+
+func init() {
+	metrics.Registry.MustRegister(mapFuncErrors)
+}
+
 // MapFunc is the signature required for enqueueing requests from a generic function.
 // This type is usually used with EnqueueRequestsFromMapFunc when registering an event handler.
 type MapFunc func(context.Context, client.Object) []reconcile.Request
@@ -86,3 +105,176 @@ func (e *enqueueRequestsFromMapFunc) mapAndEnqueue(ctx context.Context, q workqu
 		}
 	}
 }
+
+// MapFuncWithError is a MapFunc that can fail, e.g. because it looks up the objects to
+// reconcile. It is used with EnqueueRequestsFromMapFuncWithError.
+type MapFuncWithError func(context.Context, client.Object) ([]reconcile.Request, error)
+
+// MapFuncOption configures EnqueueRequestsFromMapFuncWithError.
+type MapFuncOption func(e *enqueueRequestsFromMapFuncWithError)
+
+// WithMapFuncName sets the name the errors of the map function are logged and counted
+// with in the controller_runtime_map_func_errors_total metric.
+func WithMapFuncName(name string) MapFuncOption {
+	return func(e *enqueueRequestsFromMapFuncWithError) {
+		e.name = name
+	}
+}
+
+// RetryMapFuncErrors makes the map function run again for an object it failed for, up
+// to maxRetries times, after the delays returned by rateLimiter. The Requests returned
+// by a successful retry are enqueued. An object is retried at most once at a time: if
+// the map function fails again for an object that is being retried, the retry uses
+// the newer object. Retries stop once the queue is shut down.
+// Defaults to an exponential backoff starting at 1 second and capped at 5 minutes if
+// rateLimiter is nil.
+func RetryMapFuncErrors(maxRetries int, rateLimiter ratelimiter.RateLimiter) MapFuncOption {
+	return func(e *enqueueRequestsFromMapFuncWithError) {
+		e.maxRetries = maxRetries
+		e.retryRateLimiter = rateLimiter
+	}
+}
+
+// EnqueueRequestsFromMapFuncWithError is EnqueueRequestsFromMapFunc for a map function
+// that can fail. The errors are logged and counted in the
+// controller_runtime_map_func_errors_total metric instead of having to be swallowed by
+// the map function, and the map function can be retried with RetryMapFuncErrors. The
+// Requests returned along with an error are enqueued nevertheless.
+func EnqueueRequestsFromMapFuncWithError(fn MapFuncWithError, opts ...MapFuncOption) EventHandler {
+	e := &enqueueRequestsFromMapFuncWithError{
+		toRequests: fn,
+		retrying:   map[mapFuncRetryKey]client.Object{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.maxRetries > 0 && e.retryRateLimiter == nil {
+		e.retryRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Second, 5*time.Minute)
+	}
+	return e
+}
+
+var _ EventHandler = &enqueueRequestsFromMapFuncWithError{}
+
+type enqueueRequestsFromMapFuncWithError struct {
+	// toRequests transforms the argument into a slice of keys to be reconciled
+	toRequests MapFuncWithError
+
+	// name is the name the errors are logged and counted with.
+	name string
+
+	// maxRetries is the number of times the map function is retried for an object.
+	maxRetries int
+
+	// retryRateLimiter determines the delays of the retries.
+	retryRateLimiter ratelimiter.RateLimiter
+
+	// mu guards retrying.
+	mu sync.Mutex
+
+	// retrying holds the latest object of each object that is being retried.
+	retrying map[mapFuncRetryKey]client.Object
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestsFromMapFuncWithError) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestsFromMapFuncWithError) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, q, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(ctx, q, evt.ObjectNew, reqs)
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestsFromMapFuncWithError) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestsFromMapFuncWithError) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, q, evt.Object, reqs)
+}
+
+func (e *enqueueRequestsFromMapFuncWithError) mapAndEnqueue(ctx context.Context, q workqueue.RateLimitingInterface, object client.Object, reqs map[reconcile.Request]empty) {
+	if e.tryMapAndEnqueue(ctx, q, object, reqs) || e.maxRetries <= 0 {
+		return
+	}
+	key := mapFuncRetryKey{
+		objectType: fmt.Sprintf("%T", object),
+		key:        client.ObjectKeyFromObject(object),
+	}
+	e.mu.Lock()
+	_, retrying := e.retrying[key]
+	e.retrying[key] = object
+	e.mu.Unlock()
+	if !retrying {
+		// The context of the event is cancelled once the event was handled.
+		e.retry(context.WithoutCancel(ctx), q, key)
+	}
+}
+
+// tryMapAndEnqueue runs the map function for object and enqueues the returned Requests
+// that aren't in reqs yet. It returns false if the map function failed.
+func (e *enqueueRequestsFromMapFuncWithError) tryMapAndEnqueue(ctx context.Context, q workqueue.RateLimitingInterface, object client.Object, reqs map[reconcile.Request]empty) bool {
+	result, err := e.toRequests(ctx, object)
+	for _, req := range result {
+		if _, ok := reqs[req]; !ok {
+			q.Add(req)
+			reqs[req] = empty{}
+		}
+	}
+	if err != nil {
+		mapFuncErrors.WithLabelValues(e.name).Inc()
+		mapLog.Error(err, "Map function failed", "name", e.name, "object", client.ObjectKeyFromObject(object))
+		return false
+	}
+	return true
+}
+
+// mapFuncRetryKey identifies the object a map function is retried for.
+type mapFuncRetryKey struct {
+	objectType string
+	key        client.ObjectKey
+}
+
+// retry runs the map function for the latest object of key again after the delay of
+// the rate limiter, until it succeeds or was retried maxRetries times.
+func (e *enqueueRequestsFromMapFuncWithError) retry(ctx context.Context, q workqueue.RateLimitingInterface, key mapFuncRetryKey) {
+	if q.ShuttingDown() || e.retryRateLimiter.NumRequeues(key) >= e.maxRetries {
+		e.stopRetrying(key, nil)
+		return
+	}
+	time.AfterFunc(e.retryRateLimiter.When(key), func() {
+		if q.ShuttingDown() {
+			e.stopRetrying(key, nil)
+			return
+		}
+		e.mu.Lock()
+		object := e.retrying[key]
+		e.mu.Unlock()
+		if e.tryMapAndEnqueue(ctx, q, object, map[reconcile.Request]empty{}) && e.stopRetrying(key, object) {
+			return
+		}
+		e.retry(ctx, q, key)
+	})
+}
+
+// stopRetrying stops retrying key. If object is not nil, it only does so if object is
+// still the latest object of key, so that a newer object that failed in the meantime
+// is retried as well. It returns whether the retries were stopped.
+func (e *enqueueRequestsFromMapFuncWithError) stopRetrying(key mapFuncRetryKey, object client.Object) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if object != nil && e.retrying[key] != object {
+		return false
+	}
+	delete(e.retrying, key)
+	e.retryRateLimiter.Forget(key)
+	return true
+}
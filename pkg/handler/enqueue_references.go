@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReferenceTag is the struct tag that declares a field of a type as a reference to
+// another object. Its value names the reference, which is also the name of the field
+// index built by ReferenceIndexFunc, e.g.:
+//
+//	type FooSpec struct {
+//		SecretRef corev1.LocalObjectReference `json:"secretRef" ref:"spec.secretRef"`
+//	}
+//
+// A reference field is either a string holding the name of the referenced object, or a
+// struct with a Name and an optional Namespace string field (e.g. corev1.LocalObjectReference,
+// corev1.SecretReference or corev1.ObjectReference), or a pointer to or a slice of those.
+// References without a namespace refer to objects in the namespace of the referrer, or
+// to cluster-scoped objects.
+const ReferenceTag = "ref"
+
+var referenceLog = logf.RuntimeLog.WithName("eventhandler").WithName("enqueueRequestsForReferrers")
+
+// ReferenceIndexFunc returns an IndexerFunc that extracts the references declared with
+// `ref:"<field>"` from an object. It is meant to be registered under the same field name
+// with a FieldIndexer for the type of the referrers, which EnqueueRequestsForReferrers
+// relies on.
+//
+// References without a namespace are indexed by name, the others by namespace/name.
+func ReferenceIndexFunc(field string) client.IndexerFunc {
+	return func(obj client.Object) []string {
+		var values []string
+		for _, ref := range collectReferences(reflect.ValueOf(obj), field, nil) {
+			values = append(values, ref.indexValue())
+		}
+		return values
+	}
+}
+
+// EnqueueRequestsForReferrers enqueues Requests for the objects of referrerType that
+// reference the object that was the source of the Event through the field declared with
+// `ref:"<field>"`. The referrers are listed from reader, which must have an index built by
+// ReferenceIndexFunc(field) for referrerType, usually the cache of the manager.
+//
+// If Foos reference Secrets in spec.secretRef, users may reconcile the Foos in response to
+// Secret Events using:
+//
+// - a ReferenceIndexFunc("spec.secretRef") index for Foos.
+//
+// - a source.Kind Source with Type of Secret.
+//
+// - an EnqueueRequestsForReferrers EventHandler with a referrerType of Foo and a field of spec.secretRef.
+//
+// It panics if referrerType has no field declared with `ref:"<field>"`.
+func EnqueueRequestsForReferrers(reader client.Reader, scheme *runtime.Scheme, referrerType client.Object, field string) EventHandler {
+	if !hasReferenceField(reflect.TypeOf(referrerType), field, map[reflect.Type]bool{}) {
+		panic(fmt.Sprintf("type %T has no field declared with `%s:%q`", referrerType, ReferenceTag, field))
+	}
+	return &enqueueRequestsForReferrers{
+		reader: reader,
+		list:   newObjectList(scheme, referrerType),
+		field:  field,
+	}
+}
+
+// newObjectList returns an empty list of objects of the type of obj. It panics if the
+// list type isn't registered in scheme.
+func newObjectList(scheme *runtime.Scheme, obj client.Object) client.ObjectList {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		panic(err)
+	}
+	listObj, err := scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		panic(err)
+	}
+	list, ok := listObj.(client.ObjectList)
+	if !ok {
+		panic(fmt.Sprintf("list type %T of %T is not a client.ObjectList", listObj, obj))
+	}
+	return list
+}
+
+var _ EventHandler = &enqueueRequestsForReferrers{}
+
+type enqueueRequestsForReferrers struct {
+	// reader lists the referrers through the index of field.
+	reader client.Reader
+
+	// list is an empty list of the referrer type, copied for every lookup.
+	list client.ObjectList
+
+	// field is the name of the reference and of its index.
+	field string
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestsForReferrers) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getReferrerReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestsForReferrers) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getReferrerReconcileRequests(ctx, evt.ObjectNew, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestsForReferrers) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getReferrerReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestsForReferrers) Generic(ctx context.Context, evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.getReferrerReconcileRequests(ctx, evt.Object, reqs)
+	for req := range reqs {
+		q.Add(req)
+	}
+}
+
+// getReferrerReconcileRequests lists the referrers of object and adds a reconcile.Request
+// for each of them to result.
+func (e *enqueueRequestsForReferrers) getReferrerReconcileRequests(ctx context.Context, object client.Object, result map[reconcile.Request]empty) {
+	if object == nil {
+		return
+	}
+
+	// References without a namespace are only resolved in the namespace of the referrer,
+	// unless the referenced object is cluster-scoped.
+	lookups := [][]client.ListOption{{
+		client.InNamespace(object.GetNamespace()),
+		client.MatchingFields{e.field: object.GetName()},
+	}}
+	if object.GetNamespace() != "" {
+		lookups = append(lookups, []client.ListOption{
+			client.MatchingFields{e.field: objectReference{Namespace: object.GetNamespace(), Name: object.GetName()}.indexValue()},
+		})
+	}
+
+	for _, opts := range lookups {
+		list := e.list.DeepCopyObject().(client.ObjectList)
+		if err := e.reader.List(ctx, list, opts...); err != nil {
+			referenceLog.Error(err, "Could not list referrers", "field", e.field,
+				"object", client.ObjectKeyFromObject(object))
+			continue
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			referenceLog.Error(err, "Could not extract referrers", "field", e.field)
+			continue
+		}
+		for _, item := range items {
+			referrer, ok := item.(client.Object)
+			if !ok {
+				continue
+			}
+			result[reconcile.Request{NamespacedName: client.ObjectKeyFromObject(referrer)}] = empty{}
+		}
+	}
+}
+
+// objectReference is a reference extracted from a field declared with ReferenceTag.
+type objectReference types.NamespacedName
+
+func (r objectReference) indexValue() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return types.NamespacedName(r).String()
+}
+
+// referenceTagName returns the name of the reference declared by a struct field, if any.
+func referenceTagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get(ReferenceTag), ",")
+	return name
+}
+
+// hasReferenceField reports whether t has a field declared with `ref:"<field>"`.
+func hasReferenceField(t reflect.Type, field string, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if referenceTagName(f) == field || hasReferenceField(f.Type, field, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectReferences appends the references held by the fields of v declared with
+// `ref:"<field>"` to refs.
+func collectReferences(v reflect.Value, field string, refs []objectReference) []objectReference {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return refs
+		}
+		return collectReferences(v.Elem(), field, refs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			refs = collectReferences(v.Index(i), field, refs)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if referenceTagName(f) == field {
+				refs = referencesOf(v.Field(i), refs)
+				continue
+			}
+			refs = collectReferences(v.Field(i), field, refs)
+		}
+	}
+	return refs
+}
+
+// referencesOf appends the references held by the value of a reference field to refs.
+func referencesOf(v reflect.Value, refs []objectReference) []objectReference {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return refs
+		}
+		return referencesOf(v.Elem(), refs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			refs = referencesOf(v.Index(i), refs)
+		}
+	case reflect.String:
+		if v.String() != "" {
+			refs = append(refs, objectReference{Name: v.String()})
+		}
+	case reflect.Struct:
+		name := v.FieldByName("Name")
+		if !name.IsValid() || name.Kind() != reflect.String || name.String() == "" {
+			return refs
+		}
+		ref := objectReference{Name: name.String()}
+		if namespace := v.FieldByName("Namespace"); namespace.IsValid() && namespace.Kind() == reflect.String {
+			ref.Namespace = namespace.String()
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
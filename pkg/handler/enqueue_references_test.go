@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("EnqueueRequestsForReferrers", func() {
+	var (
+		ctx    = context.Background()
+		q      workqueue.RateLimitingInterface
+		scheme *runtime.Scheme
+		cl     client.Client
+	)
+
+	newReferrer := func(namespace, name string, spec referrerSpec) *referrer {
+		return &referrer{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Spec: spec}
+	}
+
+	BeforeEach(func() {
+		q = &controllertest.Queue{Interface: workqueue.New()}
+		scheme = runtime.NewScheme()
+		scheme.AddKnownTypes(referrerGroupVersion, &referrer{}, &referrerList{})
+		metav1.AddToGroupVersion(scheme, referrerGroupVersion)
+
+		cl = fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithIndex(&referrer{}, "spec.secretRef", handler.ReferenceIndexFunc("spec.secretRef")).
+			WithObjects(
+				newReferrer("biz", "local", referrerSpec{SecretRef: corev1.LocalObjectReference{Name: "creds"}}),
+				newReferrer("baz", "other-namespace", referrerSpec{SecretRef: corev1.LocalObjectReference{Name: "creds"}}),
+				newReferrer("baz", "remote", referrerSpec{Backups: []backup{{SecretRef: &corev1.SecretReference{Namespace: "biz", Name: "creds"}}}}),
+				newReferrer("biz", "unrelated", referrerSpec{SecretRef: corev1.LocalObjectReference{Name: "other"}}),
+			).
+			Build()
+	})
+
+	It("should index the references declared with the tag", func() {
+		values := handler.ReferenceIndexFunc("spec.secretRef")(newReferrer("biz", "foo", referrerSpec{
+			SecretRef: corev1.LocalObjectReference{Name: "creds"},
+			Backups: []backup{
+				{SecretRef: &corev1.SecretReference{Namespace: "baz", Name: "backup"}},
+				{},
+			},
+		}))
+		Expect(values).To(ConsistOf("creds", "baz/backup"))
+	})
+
+	It("should enqueue the referrers of the object in its namespace and across namespaces", func() {
+		instance := handler.EnqueueRequestsForReferrers(cl, scheme, &referrer{}, "spec.secretRef")
+		instance.Update(ctx, event.UpdateEvent{
+			ObjectOld: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "creds"}},
+			ObjectNew: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "creds"}},
+		}, q)
+
+		var reqs []reconcile.Request
+		for q.Len() > 0 {
+			i, _ := q.Get()
+			reqs = append(reqs, i.(reconcile.Request))
+		}
+		Expect(reqs).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "local"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "baz", Name: "remote"}},
+		))
+	})
+
+	It("should panic if the type declares no such reference", func() {
+		Expect(func() {
+			handler.EnqueueRequestsForReferrers(cl, scheme, &referrer{}, "spec.configMapRef")
+		}).To(Panic())
+	})
+})
+
+var referrerGroupVersion = schema.GroupVersion{Group: "references.example.com", Version: "v1"}
+
+type backup struct {
+	SecretRef *corev1.SecretReference `json:"secretRef,omitempty" ref:"spec.secretRef"`
+}
+
+type referrerSpec struct {
+	SecretRef corev1.LocalObjectReference `json:"secretRef" ref:"spec.secretRef"`
+	Backups   []backup                    `json:"backups,omitempty"`
+}
+
+type referrer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              referrerSpec `json:"spec"`
+}
+
+func (r *referrer) DeepCopyObject() runtime.Object {
+	out := &referrer{TypeMeta: r.TypeMeta, Spec: r.Spec}
+	r.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.Backups = nil
+	for _, b := range r.Spec.Backups {
+		if b.SecretRef != nil {
+			ref := *b.SecretRef
+			b.SecretRef = &ref
+		}
+		out.Spec.Backups = append(out.Spec.Backups, b)
+	}
+	return out
+}
+
+type referrerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []referrer `json:"items"`
+}
+
+func (l *referrerList) DeepCopyObject() runtime.Object {
+	out := &referrerList{TypeMeta: l.TypeMeta}
+	l.ListMeta.DeepCopyInto(&out.ListMeta)
+	for i := range l.Items {
+		out.Items = append(out.Items, *l.Items[i].DeepCopyObject().(*referrer))
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Enqueuer adds Requests to the queue of a controller. The controllers created by
+// controller.New implement it, which can be checked with a type assertion:
+//
+//	target, ok := secondController.(handler.Enqueuer)
+type Enqueuer interface {
+	// Enqueue adds the request to the queue.
+	Enqueue(req reconcile.Request)
+}
+
+// EnqueueTo returns an EventHandler that enqueues the Requests returned by fn into the
+// queue of target instead of the queue of the controller of the watch, e.g. to build
+// pipelines of controllers where one controller triggers the reconciles of another:
+//
+//	if target, ok := secondController.(handler.Enqueuer); ok {
+//		err = firstController.Watch(src, handler.EnqueueTo(target, mapFn))
+//	}
+//
+// Like with EnqueueRequestsFromMapFunc, fn is run on both objects of UpdateEvents.
+// Controllers created by controller.New add a limited number of Requests enqueued
+// before they started to their queue once they start.
+func EnqueueTo(target Enqueuer, fn MapFunc) EventHandler {
+	return &enqueueTo{target: target, toRequests: fn}
+}
+
+var _ EventHandler = &enqueueTo{}
+
+type enqueueTo struct {
+	target     Enqueuer
+	toRequests MapFunc
+}
+
+// Create implements EventHandler.
+func (e *enqueueTo) Create(ctx context.Context, evt event.CreateEvent, _ workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, evt.Object, reqs)
+}
+
+// Update implements EventHandler.
+func (e *enqueueTo) Update(ctx context.Context, evt event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(ctx, evt.ObjectNew, reqs)
+}
+
+// Delete implements EventHandler.
+func (e *enqueueTo) Delete(ctx context.Context, evt event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, evt.Object, reqs)
+}
+
+// Generic implements EventHandler.
+func (e *enqueueTo) Generic(ctx context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(ctx, evt.Object, reqs)
+}
+
+func (e *enqueueTo) mapAndEnqueue(ctx context.Context, object client.Object, reqs map[reconcile.Request]empty) {
+	for _, req := range e.toRequests(ctx, object) {
+		if _, ok := reqs[req]; !ok {
+			e.target.Enqueue(req)
+			reqs[req] = empty{}
+		}
+	}
+}
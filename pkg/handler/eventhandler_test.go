@@ -18,6 +18,10 @@ package handler_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -36,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -306,6 +312,113 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 
+	Describe("EnqueueRequestsFromMapFuncWithError", func() {
+		mapFuncErrors := func(name string) float64 {
+			families, err := metrics.Registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() != "controller_runtime_map_func_errors_total" {
+					continue
+				}
+				for _, m := range family.GetMetric() {
+					for _, label := range m.GetLabel() {
+						if label.GetName() == "name" && label.GetValue() == name {
+							return m.GetCounter().GetValue()
+						}
+					}
+				}
+			}
+			return 0
+		}
+
+		It("should enqueue the Requests returned along with an error and count the error", func() {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}}
+			instance := handler.EnqueueRequestsFromMapFuncWithError(func(context.Context, client.Object) ([]reconcile.Request, error) {
+				return []reconcile.Request{req}, fmt.Errorf("lookup failed")
+			}, handler.WithMapFuncName("partial"))
+
+			instance.Create(ctx, event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(req))
+			Expect(mapFuncErrors("partial")).To(Equal(1.0))
+		})
+
+		It("should retry the map function until it succeeds", func() {
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			defer q.ShutDown()
+
+			var mu sync.Mutex
+			calls := 0
+			instance := handler.EnqueueRequestsFromMapFuncWithError(func(_ context.Context, obj client.Object) ([]reconcile.Request, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				if calls < 3 {
+					return nil, fmt.Errorf("lookup failed")
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "mapped", Name: obj.GetName()}}}, nil
+			}, handler.WithMapFuncName("retried"), handler.RetryMapFuncErrors(5, workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond)))
+
+			instance.Generic(ctx, event.GenericEvent{Object: pod}, q)
+			Eventually(q.Len).Should(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "mapped", Name: "baz"}}))
+			Expect(mapFuncErrors("retried")).To(Equal(2.0))
+		})
+
+		It("should give up after the maximum number of retries", func() {
+			var mu sync.Mutex
+			calls := 0
+			instance := handler.EnqueueRequestsFromMapFuncWithError(func(context.Context, client.Object) ([]reconcile.Request, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls++
+				return nil, fmt.Errorf("lookup failed")
+			}, handler.RetryMapFuncErrors(2, workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond)))
+
+			instance.Delete(ctx, event.DeleteEvent{Object: pod}, q)
+			getCalls := func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return calls
+			}
+			Eventually(getCalls).Should(Equal(3))
+			Consistently(getCalls).Should(Equal(3))
+			Expect(q.Len()).To(Equal(0))
+		})
+
+		It("should retry an object once at a time with its latest version", func() {
+			var mu sync.Mutex
+			failing := true
+			instance := handler.EnqueueRequestsFromMapFuncWithError(func(_ context.Context, obj client.Object) ([]reconcile.Request, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if failing {
+					return nil, fmt.Errorf("lookup failed")
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetLabels()["target"]}}}, nil
+			}, handler.RetryMapFuncErrors(5, workqueue.NewItemExponentialFailureRateLimiter(10*time.Millisecond, 10*time.Millisecond)))
+
+			oldPod := pod.DeepCopy()
+			oldPod.Labels = map[string]string{"target": "old"}
+			newPod := pod.DeepCopy()
+			newPod.Labels = map[string]string{"target": "new"}
+			instance.Generic(ctx, event.GenericEvent{Object: oldPod}, q)
+			instance.Generic(ctx, event.GenericEvent{Object: newPod}, q)
+			mu.Lock()
+			failing = false
+			mu.Unlock()
+
+			Eventually(q.Len).Should(Equal(1))
+			Consistently(q.Len, 100*time.Millisecond).Should(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "new"}}))
+		})
+	})
+
 	Describe("EnqueueRequestForOwner", func() {
 		It("should enqueue a Request with the Owner of the object in the CreateEvent.", func() {
 			instance := handler.EnqueueRequestForOwner(scheme.Scheme, mapper, &appsv1.ReplicaSet{})
@@ -675,6 +788,154 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 
+	Describe("ForCluster", func() {
+		It("should set the ClusterName of the enqueued Requests", func() {
+			instance := handler.ForCluster("cluster-a", &handler.EnqueueRequestForObject{})
+			instance.Create(ctx, event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "biz", Name: "baz"},
+				ClusterName:    "cluster-a",
+			}))
+		})
+	})
+
+	Describe("WithDelays", func() {
+		It("should delay the Requests enqueued for the configured types of events", func() {
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			defer q.ShutDown()
+
+			instance := handler.WithDelays(&handler.EnqueueRequestForObject{}, handler.Delays{Create: 200 * time.Millisecond})
+			instance.Create(ctx, event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+			Eventually(q.Len).Should(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "baz"}}))
+			q.Done(i)
+
+			instance.Update(ctx, event.UpdateEvent{ObjectOld: pod, ObjectNew: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+		})
+
+		It("should delay the Requests enqueued for all events with WithDelay", func() {
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			defer q.ShutDown()
+
+			instance := handler.WithDelay(&handler.EnqueueRequestForObject{}, 200*time.Millisecond)
+			instance.Delete(ctx, event.DeleteEvent{Object: pod}, q)
+			instance.Generic(ctx, event.GenericEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+			Eventually(q.Len).Should(Equal(1))
+		})
+	})
+
+	Describe("EnqueueTo", func() {
+		It("should enqueue the mapped Requests into the queue of the target", func() {
+			target := &controllertest.Queue{Interface: workqueue.New()}
+			instance := handler.EnqueueTo(enqueuerFunc(func(req reconcile.Request) {
+				target.Add(req)
+			}), func(_ context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "target", Name: obj.GetName()}}}
+			})
+
+			newPod := pod.DeepCopy()
+			newPod.Name = "baz2"
+			instance.Update(ctx, event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod}, q)
+			Expect(q.Len()).To(Equal(0))
+			Expect(target.Len()).To(Equal(2))
+
+			i, _ := target.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "target", Name: "baz"}}))
+			i, _ = target.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "target", Name: "baz2"}}))
+		})
+	})
+
+	Describe("EnqueueRequestForHubOwner", func() {
+		It("should enqueue a Request for the hub owner recorded in the annotations", func() {
+			owner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "hub-ns", Name: "owner"}}
+			newPod := pod.DeepCopy()
+			handler.SetHubOwner(newPod, "example.com", owner)
+			Expect(newPod.Annotations).To(HaveKeyWithValue("example.com/owner-name", "owner"))
+
+			instance := handler.EnqueueRequestForHubOwner("example.com")
+			instance.Update(ctx, event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "hub-ns", Name: "owner"},
+			}))
+		})
+
+		It("should ignore objects without the annotations of its prefix", func() {
+			newPod := pod.DeepCopy()
+			handler.SetHubOwner(newPod, "other.example.com", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "owner"}})
+
+			instance := handler.EnqueueRequestForHubOwner("example.com")
+			instance.Create(ctx, event.CreateEvent{Object: newPod}, q)
+			Expect(q.Len()).To(Equal(0))
+		})
+	})
+
+	Describe("EnqueueRequestForLabelOwner", func() {
+		fooGroupKind := schema.GroupKind{Group: "example.com", Kind: "Foo"}
+
+		It("should enqueue a Request for the owner recorded in the labels", func() {
+			owner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "owner-ns", Name: "owner"}}
+			newPod := pod.DeepCopy()
+			handler.SetLabelOwner(newPod, owner, fooGroupKind, "example.com/owner-name", "example.com/owner-namespace")
+			Expect(newPod.Labels).To(Equal(map[string]string{
+				"example.com/owner-name":            "owner",
+				"example.com/owner-namespace":       "owner-ns",
+				"example.com/owner-name-group-kind": "Foo.example.com",
+			}))
+
+			instance := handler.EnqueueRequestForLabelOwner(fooGroupKind, "example.com/owner-name", "example.com/owner-namespace")
+			instance.Update(ctx, event.UpdateEvent{ObjectOld: pod, ObjectNew: newPod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "owner-ns", Name: "owner"},
+			}))
+		})
+
+		It("should record names that aren't valid label values in the annotations", func() {
+			owner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "owner-ns", Name: strings.Repeat("a", 64)}}
+			newPod := pod.DeepCopy()
+			handler.SetLabelOwner(newPod, owner, fooGroupKind, "example.com/owner-name", "example.com/owner-namespace")
+			Expect(newPod.Labels).NotTo(HaveKey("example.com/owner-name"))
+			Expect(newPod.Annotations).To(HaveKeyWithValue("example.com/owner-name", owner.Name))
+
+			instance := handler.EnqueueRequestForLabelOwner(fooGroupKind, "example.com/owner-name", "example.com/owner-namespace")
+			instance.Create(ctx, event.CreateEvent{Object: newPod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "owner-ns", Name: owner.Name},
+			}))
+		})
+
+		It("should ignore objects without the labels or with owners of other group kinds", func() {
+			newPod := pod.DeepCopy()
+			handler.SetLabelOwner(newPod, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "owner"}},
+				schema.GroupKind{Group: "example.com", Kind: "Bar"}, "example.com/owner-name", "example.com/owner-namespace")
+
+			instance := handler.EnqueueRequestForLabelOwner(fooGroupKind, "example.com/owner-name", "example.com/owner-namespace")
+			instance.Create(ctx, event.CreateEvent{Object: newPod}, q)
+			instance.Delete(ctx, event.DeleteEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+		})
+	})
+
 	Describe("Funcs", func() {
 		failingFuncs := handler.Funcs{
 			CreateFunc: func(context.Context, event.CreateEvent, workqueue.RateLimitingInterface) {
@@ -792,3 +1053,9 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 })
+
+type enqueuerFunc func(req reconcile.Request)
+
+func (f enqueuerFunc) Enqueue(req reconcile.Request) {
+	f(req)
+}
@@ -23,6 +23,10 @@ limitations under the License.
 //
 // The main entrypoint is the Handler -- this serves both aggregated health status
 // and individual health check endpoints.
+//
+// Both return plain text by default and a JSON document with the status, latency and
+// error of each check if requested through an "Accept: application/json" header or
+// the "format=json" query parameter.
 package healthz
 
 import (
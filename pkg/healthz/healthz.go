@@ -17,11 +17,14 @@ limitations under the License.
 package healthz
 
 import (
+	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -40,6 +43,42 @@ type checkStatus struct {
 	name     string
 	healthy  bool
 	excluded bool
+	latency  time.Duration
+	err      error
+}
+
+const (
+	statusOK       = "ok"
+	statusFailed   = "failed"
+	statusExcluded = "excluded"
+)
+
+// StatusResponse is the JSON document served by a Handler if JSON was requested, either
+// through the Accept header or the format=json query parameter.
+type StatusResponse struct {
+	// Status is "ok" if all checks succeeded and "failed" otherwise.
+	Status string `json:"status"`
+
+	// Checks are the results of the individual checks, ordered by name.
+	Checks []CheckResponse `json:"checks"`
+
+	// UnknownExcludes are the excluded checks that don't exist.
+	UnknownExcludes []string `json:"unknownExcludes,omitempty"`
+}
+
+// CheckResponse is the result of a single check in a StatusResponse.
+type CheckResponse struct {
+	// Name is the name of the check.
+	Name string `json:"name"`
+
+	// Status is "ok", "failed" or "excluded".
+	Status string `json:"status"`
+
+	// Latency is how long the check took, e.g. "1.5ms".
+	Latency string `json:"latency,omitempty"`
+
+	// Error is the error returned by a failed check.
+	Error string `json:"error,omitempty"`
 }
 
 func (h *Handler) serveAggregated(resp http.ResponseWriter, req *http.Request) {
@@ -56,12 +95,15 @@ func (h *Handler) serveAggregated(resp http.ResponseWriter, req *http.Request) {
 			parts = append(parts, checkStatus{name: checkName, healthy: true, excluded: true})
 			continue
 		}
-		if err := check(req); err != nil {
+		start := time.Now()
+		err := check(req)
+		latency := time.Since(start)
+		if err != nil {
 			log.V(1).Info("healthz check failed", "checker", checkName, "error", err)
-			parts = append(parts, checkStatus{name: checkName, healthy: false})
+			parts = append(parts, checkStatus{name: checkName, healthy: false, latency: latency, err: err})
 			failed = true
 		} else {
-			parts = append(parts, checkStatus{name: checkName, healthy: true})
+			parts = append(parts, checkStatus{name: checkName, healthy: true, latency: latency})
 		}
 	}
 
@@ -78,11 +120,80 @@ func (h *Handler) serveAggregated(resp http.ResponseWriter, req *http.Request) {
 	sort.Slice(parts, func(i, j int) bool { return parts[i].name < parts[j].name })
 
 	// ...and write out the result
-	// TODO(directxman12): this should also accept a request for JSON content (via a accept header)
+	if wantsJSON(req) {
+		writeStatusesAsJSON(resp, parts, excluded, failed)
+		return
+	}
 	_, forceVerbose := req.URL.Query()["verbose"]
 	writeStatusesAsText(resp, parts, excluded, failed, forceVerbose)
 }
 
+// wantsJSON returns true if the request asks for a JSON response, either through the
+// format=json query parameter or an Accept header that includes application/json.
+func wantsJSON(req *http.Request) bool {
+	if req.URL.Query().Get("format") == "json" {
+		return true
+	}
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err == nil && mediaType == "application/json" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeStatusesAsJSON writes out the given check statuses as a StatusResponse. Unlike the
+// text format, it includes the errors of failed checks.
+func writeStatusesAsJSON(resp http.ResponseWriter, parts []checkStatus, unknownExcludes sets.Set[string], failed bool) {
+	status := StatusResponse{Status: statusOK, Checks: make([]CheckResponse, 0, len(parts))}
+	if failed {
+		status.Status = statusFailed
+	}
+	for _, part := range parts {
+		status.Checks = append(status.Checks, part.response())
+	}
+	if unknownExcludes.Len() > 0 {
+		status.UnknownExcludes = sets.List(unknownExcludes)
+	}
+	if failed {
+		log.Info("healthz check failed", "statuses", parts)
+	}
+	writeJSON(resp, status, failed)
+}
+
+// response returns the result of the check for a StatusResponse.
+func (c checkStatus) response() CheckResponse {
+	res := CheckResponse{Name: c.name, Status: statusOK}
+	switch {
+	case c.excluded:
+		res.Status = statusExcluded
+		return res
+	case !c.healthy:
+		res.Status = statusFailed
+		if c.err != nil {
+			res.Error = c.err.Error()
+		}
+	}
+	res.Latency = c.latency.String()
+	return res
+}
+
+func writeJSON(resp http.ResponseWriter, v interface{}, failed bool) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("X-Content-Type-Options", "nosniff")
+	if failed {
+		resp.WriteHeader(http.StatusInternalServerError)
+	} else {
+		resp.WriteHeader(http.StatusOK)
+	}
+	if err := json.NewEncoder(resp).Encode(v); err != nil {
+		log.Error(err, "failed to write healthz response")
+	}
+}
+
 // writeStatusAsText writes out the given check statuses in some semi-arbitrary
 // bespoke text format that we copied from Kubernetes.  unknownExcludes lists
 // any checks that the user requested to have excluded, but weren't actually
@@ -151,7 +262,7 @@ func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 
 	// ...the default check (if nothing else is present)...
 	if len(h.Checks) == 0 && reqPath[1:] == "ping" {
-		CheckHandler{Checker: Ping}.ServeHTTP(resp, req)
+		CheckHandler{Checker: Ping, name: "ping"}.ServeHTTP(resp, req)
 		return
 	}
 
@@ -163,17 +274,29 @@ func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	CheckHandler{Checker: checker}.ServeHTTP(resp, req)
+	CheckHandler{Checker: checker, name: checkName}.ServeHTTP(resp, req)
 }
 
 // CheckHandler is an http.Handler that serves a health check endpoint at the root path,
 // based on its checker.
+//
+// If JSON was requested, the result is served as a CheckResponse.
 type CheckHandler struct {
 	Checker
+
+	// name is the name of the check in JSON responses.
+	name string
 }
 
 func (h CheckHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	if err := h.Checker(req); err != nil {
+	start := time.Now()
+	err := h.Checker(req)
+	if wantsJSON(req) {
+		part := checkStatus{name: h.name, healthy: err == nil, latency: time.Since(start), err: err}
+		writeJSON(resp, part.response(), err != nil)
+		return
+	}
+	if err != nil {
 		http.Error(resp, fmt.Sprintf("internal server error: %v", err), http.StatusInternalServerError)
 	} else {
 		fmt.Fprint(resp, "ok")
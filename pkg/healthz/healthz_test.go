@@ -17,6 +17,7 @@ limitations under the License.
 package healthz_test
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -201,3 +202,72 @@ var _ = Describe("Healthz Handler", func() {
 		})
 	})
 })
+
+var _ = Describe("Healthz Handler JSON output", func() {
+	var handler *healthz.Handler
+
+	BeforeEach(func() {
+		handler = &healthz.Handler{Checks: map[string]healthz.Checker{
+			"ok1": healthz.Ping,
+			"bad1": func(req *http.Request) error {
+				return errors.New("blech")
+			},
+		}}
+	})
+
+	decode := func(resp *httptest.ResponseRecorder, into interface{}) {
+		Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(json.NewDecoder(resp.Body).Decode(into)).To(Succeed())
+	}
+
+	It("should return the results of all checks if requested through the query", func() {
+		resp := requestTo(handler, "/?format=json&exclude=nonexistent")
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+		status := healthz.StatusResponse{}
+		decode(resp, &status)
+		Expect(status.Status).To(Equal("failed"))
+		Expect(status.UnknownExcludes).To(Equal([]string{"nonexistent"}))
+		Expect(status.Checks).To(HaveLen(2))
+		Expect(status.Checks[0].Name).To(Equal("bad1"))
+		Expect(status.Checks[0].Status).To(Equal("failed"))
+		Expect(status.Checks[0].Error).To(Equal("blech"))
+		Expect(status.Checks[0].Latency).NotTo(BeEmpty())
+		Expect(status.Checks[1].Name).To(Equal("ok1"))
+		Expect(status.Checks[1].Status).To(Equal("ok"))
+		Expect(status.Checks[1].Error).To(BeEmpty())
+	})
+
+	It("should return JSON if requested through the Accept header", func() {
+		req, err := http.NewRequest("GET", "/?exclude=bad1", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", "text/plain;q=0.5, application/json")
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		Expect(resp.Code).To(Equal(http.StatusOK))
+
+		status := healthz.StatusResponse{}
+		decode(resp, &status)
+		Expect(status.Status).To(Equal("ok"))
+		Expect(status.Checks).To(ConsistOf(
+			healthz.CheckResponse{Name: "bad1", Status: "excluded"},
+			HaveField("Name", "ok1"),
+		))
+	})
+
+	It("should return the result of a single check as JSON", func() {
+		resp := requestTo(handler, "/bad1?format=json")
+		Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+		check := healthz.CheckResponse{}
+		decode(resp, &check)
+		Expect(check.Name).To(Equal("bad1"))
+		Expect(check.Status).To(Equal("failed"))
+		Expect(check.Error).To(Equal("blech"))
+	})
+
+	It("should keep returning text if JSON was not requested", func() {
+		resp := requestTo(handler, "/?verbose")
+		Expect(resp.Header().Get("Content-Type")).To(Equal(contentType))
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+)
+
+var _ cluster.Aware = &Controller{}
+
+// clusterEngagement is a provider cluster engaged with the controller.
+type clusterEngagement struct {
+	// ctx is done once the cluster is disengaged and marked as such, it is used to
+	// cancel the in-flight reconciles of the cluster.
+	ctx context.Context
+
+	// cl is the engaged cluster.
+	cl cluster.Cluster
+
+	// watchCtx is the context the cluster watches of the cluster were started with,
+	// stopWatches cancels it. They are nil until the cluster watches are started.
+	watchCtx    context.Context
+	stopWatches context.CancelFunc
+
+	// watches are the states of the cluster watches of the cluster.
+	watches []*clusterWatchState
+}
+
+// Engage implements cluster.Aware. Once the cluster is disengaged, the in-flight
+// reconciles of its requests are cancelled, and its queued requests are dropped
+// when they are dequeued instead of being reconciled against a dead cluster.
+func (c *Controller) Engage(ctx context.Context, name string, cl cluster.Cluster) error {
+	if name == "" || c.ClusterSelector == nil {
+		c.engage(ctx, name, cl)
+		return nil
+	}
+
+	// The cluster is engaged while it is selected, which can change while the cluster
+	// is engaged with the manager if its metadata change.
+	var disengage context.CancelFunc
+	reselect := func() {
+		switch selected := c.selectsCluster(name, cl); {
+		case selected && disengage == nil:
+			var selectedCtx context.Context
+			selectedCtx, disengage = context.WithCancel(ctx)
+			c.engage(selectedCtx, name, cl)
+		case !selected && disengage != nil:
+			disengage()
+			disengage = nil
+		}
+	}
+	notifier, ok := cl.(cluster.MetadataNotifier)
+	if !ok {
+		reselect()
+		return nil
+	}
+	changed := notifier.MetadataChanged()
+	reselect()
+	go func() {
+		for {
+			select {
+			case <-changed:
+				changed = notifier.MetadataChanged()
+				reselect()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// engage engages the controller with the given cluster until ctx is done.
+func (c *Controller) engage(ctx context.Context, name string, cl cluster.Cluster) {
+	engagementCtx, cancel := context.WithCancel(context.Background())
+	engagement := &clusterEngagement{ctx: engagementCtx, cl: cl}
+
+	c.clustersMu.Lock()
+	if c.engagedClusters == nil {
+		c.engagedClusters = map[string]*clusterEngagement{}
+	}
+	if c.disengagedClusters == nil {
+		c.disengagedClusters = map[string]struct{}{}
+	}
+	if previous, ok := c.engagedClusters[name]; ok && previous.stopWatches != nil {
+		previous.stopWatches()
+	}
+	c.engagedClusters[name] = engagement
+	delete(c.disengagedClusters, name)
+	if c.clusterWatchesCtx != nil {
+		c.startClusterWatchesLocked(name, engagement)
+	}
+	c.clustersMu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		// Mark the cluster as disengaged before cancelling its reconciles, so that
+		// they aren't requeued.
+		defer cancel()
+		c.clustersMu.Lock()
+		defer c.clustersMu.Unlock()
+		// The cluster may have been engaged again in the meantime.
+		if c.engagedClusters[name] == engagement {
+			delete(c.engagedClusters, name)
+			// Only the queued requests of the cluster need to be dropped.
+			if c.clusterQueue != nil && c.clusterQueue.hasClusterItems(name) {
+				c.disengagedClusters[name] = struct{}{}
+			} else {
+				ctrlmetrics.DeleteClusterMetrics(c.Name, name)
+			}
+		}
+	})
+}
+
+// forgetDisengagedCluster stops dropping the requests of the cluster with the given
+// name and deletes its metrics once none of them are queued anymore.
+func (c *Controller) forgetDisengagedCluster(name string) {
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	if _, ok := c.disengagedClusters[name]; !ok {
+		return
+	}
+	if c.clusterQueue != nil && !c.clusterQueue.hasClusterItems(name) {
+		delete(c.disengagedClusters, name)
+		ctrlmetrics.DeleteClusterMetrics(c.Name, name)
+	}
+}
+
+// selectsCluster returns whether the controller engages with the cluster with the given
+// name. The cluster of the manager is always selected. Clusters are selected again when
+// their metadata change, see cluster.MetadataNotifier.
+func (c *Controller) selectsCluster(name string, cl cluster.Cluster) bool {
+	if name == "" || c.ClusterSelector == nil {
+		return true
+	}
+	return c.ClusterSelector.Matches(labels.Set(cluster.GetMetadata(cl).Labels))
+}
+
+// isDisengaged returns whether the cluster with the given name was disengaged and
+// not engaged again since.
+func (c *Controller) isDisengaged(name string) bool {
+	if name == "" {
+		return false
+	}
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	_, ok := c.disengagedClusters[name]
+	return ok
+}
+
+// withClusterContext returns a context that is additionally cancelled once the
+// cluster with the given name is disengaged.
+func (c *Controller) withClusterContext(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if name == "" {
+		return ctx, cancel
+	}
+
+	c.clustersMu.Lock()
+	engagement, ok := c.engagedClusters[name]
+	c.clustersMu.Unlock()
+	if !ok {
+		return ctx, cancel
+	}
+	stop := context.AfterFunc(engagement.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// ClusterWatchStatus reports the state of the cluster watches of an engaged provider
+// cluster, see WatchClusters.
+type ClusterWatchStatus struct {
+	// Cluster is the name of the cluster.
+	Cluster string `json:"cluster"`
+
+	// LiveSources are the sources that were started and whose caches are synced.
+	LiveSources []string `json:"liveSources"`
+
+	// PendingSources are the sources that failed to start or sync and are retried.
+	PendingSources []PendingSourceStatus `json:"pendingSources"`
+}
+
+// PendingSourceStatus reports a cluster watch source that is retried.
+type PendingSourceStatus struct {
+	// Source describes the source.
+	Source string `json:"source"`
+
+	// Retries is the number of failed attempts to start the source.
+	Retries int `json:"retries"`
+
+	// LastError is the error of the last failed attempt, if any.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// clusterWatchDescription describes a watch that is bound to every engaged provider
+// cluster, see WatchClusters.
+type clusterWatchDescription struct {
+	newSource  func(cl cluster.Cluster) source.Source
+	handler    handler.EventHandler
+	predicates []predicate.Predicate
+}
+
+// clusterWatchState is the state of a cluster watch of a single cluster.
+type clusterWatchState struct {
+	mu      sync.Mutex
+	source  string
+	live    bool
+	retries int
+	lastErr error
+
+	// synced is closed once the source is live.
+	synced chan struct{}
+}
+
+func newClusterWatchState() *clusterWatchState {
+	return &clusterWatchState{synced: make(chan struct{})}
+}
+
+// setSource records the description of the source that is being started.
+func (s *clusterWatchState) setSource(src source.Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.source = fmt.Sprintf("%s", src)
+}
+
+// setFailed records a failed attempt and returns whether it is the first one.
+func (s *clusterWatchState) setFailed(err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+	s.lastErr = err
+	return s.retries == 1
+}
+
+func (s *clusterWatchState) setLive() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live = true
+	close(s.synced)
+}
+
+// pending returns whether the source is retried after a failed attempt.
+func (s *clusterWatchState) pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.live && s.retries > 0
+}
+
+// WatchClusters watches every provider cluster engaged with the controller with the
+// source returned by newSource for the cluster, e.g. a Kind source on the cache of the
+// cluster. The Requests enqueued by the event handler get the name of the cluster as
+// their ClusterName, see handler.ForCluster. The sources of a cluster are started once
+// the controller started its sources, stopped once the cluster is disengaged and
+// started again by ResyncCluster. A source that fails to start or sync is retried with
+// the ClusterWatchRateLimiter, without affecting the other sources of the cluster.
+func (c *Controller) WatchClusters(newSource func(cl cluster.Cluster) source.Source, evthdler handler.EventHandler, prct ...predicate.Predicate) error {
+	watch := clusterWatchDescription{newSource: newSource, handler: evthdler, predicates: prct}
+
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	c.clusterWatches = append(c.clusterWatches, watch)
+	for name, engagement := range c.engagedClusters {
+		if engagement.watchCtx == nil {
+			continue
+		}
+		state := newClusterWatchState()
+		engagement.watches = append(engagement.watches, state)
+		go c.runClusterWatch(engagement.watchCtx, name, engagement.cl, watch, state)
+	}
+	return nil
+}
+
+// ResyncCluster stops the sources of the cluster watches of the engaged provider cluster
+// with the given name and starts them again, e.g. after CRDs were installed in the
+// cluster or its cache was refreshed, and waits up to the CacheSyncTimeout for them to
+// sync. The watches of other clusters aren't affected. It fails if the cluster isn't
+// engaged or the controller hasn't started its sources yet, and reports the sources
+// that are still pending if they don't sync in time; those keep being retried.
+func (c *Controller) ResyncCluster(ctx context.Context, name string) error {
+	c.clustersMu.Lock()
+	engagement, ok := c.engagedClusters[name]
+	if !ok {
+		c.clustersMu.Unlock()
+		return fmt.Errorf("%w: %q is not engaged with the controller", cluster.ErrClusterNotFound, name)
+	}
+	if c.clusterWatchesCtx == nil {
+		c.clustersMu.Unlock()
+		return fmt.Errorf("unable to resync cluster %q of a controller whose sources weren't started", name)
+	}
+	c.LogConstructor(nil).Info("Resyncing cluster watches", "cluster", name)
+	c.startClusterWatchesLocked(name, engagement)
+	watchCtx, states := engagement.watchCtx, engagement.watches
+	c.clustersMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.CacheSyncTimeout)
+	defer cancel()
+	stop := context.AfterFunc(watchCtx, cancel)
+	defer stop()
+	for _, state := range states {
+		select {
+		case <-state.synced:
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for %s caches of cluster %q to sync: %s", c.Name, name, describePending(states))
+		}
+	}
+	return nil
+}
+
+// describePending describes the sources of the given states that aren't live.
+func describePending(states []*clusterWatchState) string {
+	var pending []string
+	for _, state := range states {
+		state.mu.Lock()
+		switch {
+		case state.live:
+		case state.lastErr != nil:
+			pending = append(pending, fmt.Sprintf("%s: %v", state.source, state.lastErr))
+		default:
+			pending = append(pending, fmt.Sprintf("%s: not synced yet", state.source))
+		}
+		state.mu.Unlock()
+	}
+	return strings.Join(pending, "; ")
+}
+
+// startEngagedClusterWatches starts the cluster watches of all engaged clusters with
+// the given context, it is called once the sources of the controller are started.
+func (c *Controller) startEngagedClusterWatches(ctx context.Context) {
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	c.clusterWatchesCtx = ctx
+	for name, engagement := range c.engagedClusters {
+		c.startClusterWatchesLocked(name, engagement)
+	}
+}
+
+// startClusterWatchesLocked stops the cluster watches of the given cluster, if any, and
+// starts them again. c.clustersMu must be held and c.clusterWatchesCtx must be set.
+func (c *Controller) startClusterWatchesLocked(name string, engagement *clusterEngagement) {
+	if engagement.stopWatches != nil {
+		engagement.stopWatches()
+	}
+	ctx, cancel := context.WithCancel(c.clusterWatchesCtx)
+	stop := context.AfterFunc(engagement.ctx, cancel)
+	engagement.watchCtx = ctx
+	engagement.stopWatches = func() {
+		stop()
+		cancel()
+	}
+
+	engagement.watches = make([]*clusterWatchState, 0, len(c.clusterWatches))
+	for _, watch := range c.clusterWatches {
+		state := newClusterWatchState()
+		engagement.watches = append(engagement.watches, state)
+		go c.runClusterWatch(ctx, name, engagement.cl, watch, state)
+	}
+}
+
+// runClusterWatch starts the source of the given cluster watch for the given cluster
+// and waits for it to sync, retrying with the ClusterWatchRateLimiter until it is live
+// or ctx is done.
+func (c *Controller) runClusterWatch(ctx context.Context, name string, cl cluster.Cluster, watch clusterWatchDescription, state *clusterWatchState) {
+	log := c.LogConstructor(nil).WithValues("cluster", name)
+	defer c.ClusterWatchRateLimiter.Forget(state)
+	for {
+		err := c.startClusterWatch(ctx, name, cl, watch, state)
+		if ctx.Err() != nil {
+			c.clustersMu.Lock()
+			// The metrics of a disengaged cluster are deleted.
+			if _, engaged := c.engagedClusters[name]; engaged && state.pending() {
+				ctrlmetrics.ClusterWatchesPending.WithLabelValues(c.Name, name).Dec()
+			}
+			c.clustersMu.Unlock()
+			return
+		}
+		if err == nil {
+			if state.pending() {
+				ctrlmetrics.ClusterWatchesPending.WithLabelValues(c.Name, name).Dec()
+			}
+			state.setLive()
+			return
+		}
+
+		if state.setFailed(err) {
+			ctrlmetrics.ClusterWatchesPending.WithLabelValues(c.Name, name).Inc()
+		}
+		ctrlmetrics.ClusterWatchFailures.WithLabelValues(c.Name, name).Inc()
+		delay := c.ClusterWatchRateLimiter.When(state)
+		log.Error(err, "Failed to start cluster watch, retrying", "delay", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+}
+
+// startClusterWatch starts the source of the given cluster watch for the given cluster
+// and waits for it to sync.
+func (c *Controller) startClusterWatch(ctx context.Context, name string, cl cluster.Cluster, watch clusterWatchDescription, state *clusterWatchState) error {
+	src := watch.newSource(cl)
+	state.setSource(src)
+	c.LogConstructor(nil).Info("Starting EventSource", "source", src, "cluster", name)
+	if err := src.Start(ctx, c.wrapHandler(handler.ForCluster(name, watch.handler)), c.Queue, watch.predicates...); err != nil {
+		return err
+	}
+	syncingSource, ok := src.(source.SyncingSource)
+	if !ok {
+		return nil
+	}
+
+	// A Kind source stops itself if it doesn't sync before the context expires.
+	syncCtx, cancel := context.WithTimeout(ctx, c.CacheSyncTimeout)
+	defer cancel()
+	if err := syncingSource.WaitForSync(syncCtx); err != nil {
+		return err
+	}
+	if syncCtx.Err() != nil && ctx.Err() == nil {
+		return errors.New("timed out waiting for cache to be synced")
+	}
+	return nil
+}
+
+// clusterWatchStatuses returns the status of the cluster watches of the engaged
+// clusters, sorted by cluster.
+func (c *Controller) clusterWatchStatuses() []ClusterWatchStatus {
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+
+	var statuses []ClusterWatchStatus
+	for name, engagement := range c.engagedClusters {
+		if engagement.watchCtx == nil || len(engagement.watches) == 0 {
+			continue
+		}
+		status := ClusterWatchStatus{Cluster: name, LiveSources: []string{}, PendingSources: []PendingSourceStatus{}}
+		for _, state := range engagement.watches {
+			state.mu.Lock()
+			switch {
+			case state.live:
+				status.LiveSources = append(status.LiveSources, state.source)
+			default:
+				pending := PendingSourceStatus{Source: state.source, Retries: state.retries}
+				if state.lastErr != nil {
+					pending.LastError = state.lastErr.Error()
+				}
+				status.PendingSources = append(status.PendingSources, pending)
+			}
+			state.mu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Cluster < statuses[j].Cluster
+	})
+	return statuses
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config is a view of the options a controller was created with.
+type Config struct {
+	// Name is the name of the controller.
+	Name string `json:"name"`
+
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles.
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles"`
+
+	// TenantMaxConcurrentReconciles is the maximum number of concurrent reconciles
+	// of requests of the same tenant. Zero means no limit.
+	TenantMaxConcurrentReconciles int `json:"tenantMaxConcurrentReconciles,omitempty"`
+
+	// CacheSyncTimeout is the time limit on waiting for the caches of the sources to sync.
+	CacheSyncTimeout metav1.Duration `json:"cacheSyncTimeout"`
+
+	// RecoverPanic is true if panics of the reconciler are recovered.
+	RecoverPanic bool `json:"recoverPanic"`
+
+	// NeedLeaderElection is true if the controller only runs on the leader.
+	NeedLeaderElection bool `json:"needLeaderElection"`
+
+	// LeaderElectionID is the name of the leader election lease of the controller, if any.
+	LeaderElectionID string `json:"leaderElectionID,omitempty"`
+
+	// SkipInitialSync is true if Create events from the initial list are dropped.
+	SkipInitialSync bool `json:"skipInitialSync,omitempty"`
+}
+
+// Config returns the options the controller was created with.
+func (c *Controller) Config() Config {
+	maxConcurrentReconciles := c.MaxConcurrentReconciles
+	if workers := c.workers.size(); workers > 0 {
+		maxConcurrentReconciles = workers
+	}
+	return Config{
+		Name:                          c.Name,
+		MaxConcurrentReconciles:       maxConcurrentReconciles,
+		TenantMaxConcurrentReconciles: c.TenantMaxConcurrentReconciles,
+		CacheSyncTimeout:              metav1.Duration{Duration: c.CacheSyncTimeout},
+		RecoverPanic:                  c.RecoverPanic != nil && *c.RecoverPanic,
+		NeedLeaderElection:            c.NeedLeaderElection(),
+		LeaderElectionID:              c.LeaderElectionID,
+		SkipInitialSync:               c.SkipInitialSync,
+	}
+}
@@ -20,19 +20,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/util/workqueue"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	internalsource "sigs.k8s.io/controller-runtime/pkg/internal/source"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -45,6 +53,17 @@ type Controller struct {
 	// MaxConcurrentReconciles is the maximum number of concurrent Reconciles which can be run. Defaults to 1.
 	MaxConcurrentReconciles int
 
+	// GroupKind is the GroupKind of the objects the controller reconciles, if known. It is
+	// used to look up the concurrency of the controller when the configuration is reloaded.
+	GroupKind string
+
+	// ReloadableRateLimiter, if set, is the rate limiter of the queue. Its overall rate
+	// limit is changed when the configuration is reloaded.
+	ReloadableRateLimiter *ReloadableRateLimiter
+
+	// workers runs the workers of the controller.
+	workers workerPool
+
 	// Reconciler is a function that can be called at any time with the Name / Namespace of an object and
 	// ensures that the state of the system matches the state specified in the object.
 	// Defaults to the DefaultReconcileFunc.
@@ -65,6 +84,23 @@ type Controller struct {
 	// Started is true if the Controller has been Started
 	Started bool
 
+	// sourcesStarted is true if the sources of the Controller were started, either by
+	// Start or by Warmup.
+	sourcesStarted bool
+
+	// warmup is closed once Warmup returned, it is nil if Warmup wasn't called or Start
+	// already took over the queue of Warmup.
+	warmup chan struct{}
+
+	// warmupErr is the error Warmup failed with, if any.
+	warmupErr error
+
+	// drained is closed once Start returned, see Drained.
+	drained chan struct{}
+
+	// enqueuer adds the requests passed to Enqueue to the queue.
+	enqueuer enqueuer
+
 	// ctx is the context that was passed to Start() and used when starting watches.
 	//
 	// According to the docs, contexts should not be stored in a struct: https://golang.org/pkg/context,
@@ -88,8 +124,118 @@ type Controller struct {
 	// RecoverPanic indicates whether the panic caused by reconcile should be recovered.
 	RecoverPanic *bool
 
+	// PanicHandler is called with the recovered value and the stack trace whenever
+	// the reconciler panics, regardless of RecoverPanic.
+	PanicHandler func(ctx context.Context, req reconcile.Request, recovered any, stack []byte)
+
 	// LeaderElected indicates whether the controller is leader elected or always running.
 	LeaderElected *bool
+
+	// LeaderElectionID is the name of the leader election lease of the controller.
+	// If empty, the controller uses the lease of the manager.
+	LeaderElectionID string
+
+	// SkipInitialSync indicates whether Create events from the initial list of informers are dropped.
+	SkipInitialSync bool
+
+	// InitialSyncRateLimiter, if set, throttles the requests enqueued for Create events from
+	// the initial list of informers.
+	InitialSyncRateLimiter ratelimiter.RateLimiter
+
+	// RestartRateLimiter, if set, makes Start retry starting the sources of the controller
+	// after the delay returned by the rate limiter for the name of the controller when
+	// they fail to start or sync, instead of returning the error. Controllers with sources
+	// that can't be started again, e.g. Channel sources, still return the error.
+	RestartRateLimiter ratelimiter.RateLimiter
+
+	// LockKeyFunc, if set, returns a key for each request. Requests sharing the same
+	// non-empty key are never reconciled concurrently.
+	LockKeyFunc func(ctx context.Context, req reconcile.Request) (string, error)
+
+	// lockMu guards heldLockKeys and deferredRequests.
+	lockMu sync.Mutex
+
+	// heldLockKeys contains the lock keys of the reconciles currently in flight.
+	heldLockKeys map[string]struct{}
+
+	// deferredRequests contains, per lock key, the requests that were dequeued while
+	// the key was held. They are added back to the queue once the key is released.
+	deferredRequests map[string][]reconcile.Request
+
+	// TenantFunc, if set, returns the tenant of each request. The limits below are
+	// enforced per non-empty tenant.
+	TenantFunc func(ctx context.Context, req reconcile.Request) (string, error)
+
+	// TenantMaxConcurrentReconciles is the maximum number of concurrent reconciles of
+	// requests of the same tenant. Zero means no limit.
+	TenantMaxConcurrentReconciles int
+
+	// NewTenantRateLimiter, if set, returns the rate limiter used to throttle the
+	// reconciles of a tenant. It is called once per tenant.
+	NewTenantRateLimiter func() ratelimiter.RateLimiter
+
+	// tenants contains the state of the tenants with in-flight or throttled reconciles.
+	tenants keyedLimits
+
+	// ClusterMaxConcurrentReconciles is the maximum number of concurrent reconciles of
+	// requests of the same cluster. Zero means no limit.
+	ClusterMaxConcurrentReconciles int
+
+	// NewClusterRateLimiter, if set, returns the rate limiter used to throttle the
+	// reconciles of a cluster. It is called once per cluster.
+	NewClusterRateLimiter func() ratelimiter.RateLimiter
+
+	// clusters contains the state of the clusters with in-flight or throttled reconciles.
+	clusters keyedLimits
+
+	// ClusterMetrics, if set, records the reconcile and queue metrics of the requests
+	// of provider clusters per cluster, and the metrics of the REST client requests
+	// made by their reconciles, see metrics.WithClusterName.
+	ClusterMetrics bool
+
+	// ClusterSelector, if set, restricts the provider clusters the controller engages with
+	// to the ones whose labels match it.
+	ClusterSelector labels.Selector
+
+	// clustersMu guards engagedClusters, disengagedClusters, clusterQueue, clusterWatches
+	// and clusterWatchesCtx.
+	clustersMu sync.Mutex
+
+	// engagedClusters contains the provider clusters engaged with the controller.
+	engagedClusters map[string]*clusterEngagement
+
+	// disengagedClusters contains the provider clusters that were disengaged and not
+	// engaged again since. Their requests are dropped. A cluster is only kept while
+	// requests of it are in clusterQueue.
+	disengagedClusters map[string]struct{}
+
+	// clusterQueue is the queue of the controller, which tracks the requests of the
+	// provider clusters. It is nil until the controller is started.
+	clusterQueue *trackingQueue
+
+	// clusterWatches are the watches bound to every engaged provider cluster, see
+	// WatchClusters.
+	clusterWatches []clusterWatchDescription
+
+	// clusterWatchesCtx is the context the cluster watches are started with. It is nil
+	// until the sources of the controller are started.
+	clusterWatchesCtx context.Context
+
+	// ClusterWatchRateLimiter returns the delay after which a cluster watch source that
+	// failed to start or sync is retried. Defaults to an exponential backoff from 1s up
+	// to 5m.
+	ClusterWatchRateLimiter ratelimiter.RateLimiter
+
+	// GetCluster, if set, returns the cluster of a request by name. It is passed to
+	// the reconciler through the context, see cluster.FromContext.
+	GetCluster func(ctx context.Context, name string) (cluster.Cluster, error)
+
+	// Scheme, if set, is used to determine the kinds of the watched objects reported
+	// by Status.
+	Scheme *runtime.Scheme
+
+	// status records the state reported by Status.
+	status statusTracker
 }
 
 // watchDescription contains all the information necessary to start a watch.
@@ -103,6 +249,11 @@ type watchDescription struct {
 func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (_ reconcile.Result, err error) {
 	defer func() {
 		if r := recover(); r != nil {
+			ctrlmetrics.ReconcilePanics.WithLabelValues(c.Name).Inc()
+			if c.PanicHandler != nil {
+				c.PanicHandler(ctx, req, r, debug.Stack())
+			}
+
 			if c.RecoverPanic != nil && *c.RecoverPanic {
 				for _, fn := range utilruntime.PanicHandlers {
 					fn(r)
@@ -124,16 +275,18 @@ func (c *Controller) Watch(src source.Source, evthdler handler.EventHandler, prc
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.status.addSource(src)
+
 	// Controller hasn't started yet, store the watches locally and return.
 	//
 	// These watches are going to be held on the controller struct until the manager or user calls Start(...).
-	if !c.Started {
-		c.startWatches = append(c.startWatches, watchDescription{src: src, handler: evthdler, predicates: prct})
+	if !c.sourcesStarted {
+		c.startWatches = append(c.startWatches, watchDescription{src: src, handler: c.wrapHandler(evthdler), predicates: prct})
 		return nil
 	}
 
 	c.LogConstructor(nil).Info("Starting EventSource", "source", src)
-	return src.Start(c.ctx, evthdler, c.Queue, prct...)
+	return src.Start(c.ctx, c.wrapHandler(evthdler), c.Queue, prct...)
 }
 
 // NeedLeaderElection implements the manager.LeaderElectionRunnable interface.
@@ -144,105 +297,317 @@ func (c *Controller) NeedLeaderElection() bool {
 	return *c.LeaderElected
 }
 
+// GetLeaderElectionID implements the manager.LeaderElectionIDRunnable interface.
+func (c *Controller) GetLeaderElectionID() string {
+	return c.LeaderElectionID
+}
+
+// Warmup implements the manager.WarmupRunnable interface. It starts the sources of the
+// controller and waits for their caches to sync, so that Start only has to launch the
+// workers. The sources run until ctx is cancelled.
+func (c *Controller) Warmup(ctx context.Context) (err error) {
+	c.mu.Lock()
+	if c.Started || c.sourcesStarted || c.warmup != nil {
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.init(ctx)
+	queue := c.Queue
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// Once started, the queue is shut down by Start.
+		if !c.Started {
+			queue.ShutDown()
+		}
+	}()
+
+	// c.mu isn't held while the caches sync, Start waits for warmup to be closed
+	// instead.
+	warmup := make(chan struct{})
+	c.warmup = warmup
+	watches := c.startWatches
+	c.mu.Unlock()
+
+	// TODO(pwittrock): Reconsider HandleCrash
+	defer utilruntime.HandleCrash()
+
+	// Stop the sources if Warmup fails, Start starts them again with the queue of
+	// Warmup, like on a restart.
+	sourcesCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	err = c.syncSources(sourcesCtx, watches)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(warmup)
+	if err != nil {
+		c.warmupErr = err
+		return err
+	}
+	// Start the sources of the watches added while the caches synced, like Watch does
+	// once the sources were started.
+	for _, watch := range c.startWatches[len(watches):] {
+		c.LogConstructor(nil).Info("Starting EventSource", "source", watch.src)
+		if err := watch.src.Start(sourcesCtx, watch.handler, c.Queue, watch.predicates...); err != nil {
+			c.warmupErr = err
+			return err
+		}
+	}
+	c.sourcesSynced(sourcesCtx)
+	return nil
+}
+
 // Start implements controller.Controller.
 func (c *Controller) Start(ctx context.Context) error {
 	// use an IIFE to get proper lock handling
 	// but lock outside to get proper handling of the queue shutdown
 	c.mu.Lock()
+	// Wait for Warmup to start the sources and take over its queue.
+	warmedUp := false
+	if warmup := c.warmup; warmup != nil {
+		c.mu.Unlock()
+		<-warmup
+		c.mu.Lock()
+		c.warmup, warmedUp = nil, true
+	}
+
 	if c.Started {
 		return errors.New("controller was started more than once. This is likely to be caused by being added to a manager multiple times")
 	}
 
-	c.initMetrics()
+	if c.warmupErr != nil {
+		if src := c.unrestartableSource(); src != nil {
+			c.mu.Unlock()
+			return fmt.Errorf("failed to warm up and source %s can not be started again: %w", src, c.warmupErr)
+		}
+	}
 
+	if !c.sourcesStarted && !warmedUp {
+		c.init(ctx)
+	}
 	// Set the internal context.
 	c.ctx = ctx
 
-	c.Queue = c.MakeQueue()
+	queue := c.Queue
 	go func() {
 		<-ctx.Done()
-		c.Queue.ShutDown()
+		queue.ShutDown()
+	}()
+	// Start can be called again if it failed before, which drains the controller again.
+	select {
+	case <-c.drained:
+		c.drained = nil
+	default:
+	}
+	drained := c.drainedLocked()
+	defer func() {
+		// Start only returns once all workers finished, or if they never started.
+		queue.ShutDown()
+		close(drained)
 	}()
 
-	wg := &sync.WaitGroup{}
-	err := func() error {
+	start := func() error {
 		defer c.mu.Unlock()
 
 		// TODO(pwittrock): Reconsider HandleCrash
 		defer utilruntime.HandleCrash()
 
-		// NB(directxman12): launch the sources *before* trying to wait for the
-		// caches to sync so that they have a chance to register their intendeded
-		// caches.
-		for _, watch := range c.startWatches {
-			c.LogConstructor(nil).Info("Starting EventSource", "source", fmt.Sprintf("%s", watch.src))
-
-			if err := watch.src.Start(ctx, watch.handler, c.Queue, watch.predicates...); err != nil {
+		if !c.sourcesStarted {
+			if err := c.startSources(ctx); err != nil {
 				return err
 			}
 		}
 
-		// Start the SharedIndexInformer factories to begin populating the SharedIndexInformer caches
-		c.LogConstructor(nil).Info("Starting Controller")
-
-		for _, watch := range c.startWatches {
-			syncingSource, ok := watch.src.(source.SyncingSource)
-			if !ok {
-				continue
-			}
-
-			if err := func() error {
-				// use a context with timeout for launching sources and syncing caches.
-				sourceStartCtx, cancel := context.WithTimeout(ctx, c.CacheSyncTimeout)
-				defer cancel()
-
-				// WaitForSync waits for a definitive timeout, and returns if there
-				// is an error or a timeout
-				if err := syncingSource.WaitForSync(sourceStartCtx); err != nil {
-					err := fmt.Errorf("failed to wait for %s caches to sync: %w", c.Name, err)
-					c.LogConstructor(nil).Error(err, "Could not wait for Cache to sync")
-					return err
-				}
-
-				return nil
-			}(); err != nil {
-				return err
-			}
-		}
-
-		// All the watches have been started, we can reset the local slice.
-		//
-		// We should never hold watches more than necessary, each watch source can hold a backing cache,
-		// which won't be garbage collected if we hold a reference to it.
-		c.startWatches = nil
-
 		// Launch workers to process resources
 		c.LogConstructor(nil).Info("Starting workers", "worker count", c.MaxConcurrentReconciles)
-		wg.Add(c.MaxConcurrentReconciles)
-		for i := 0; i < c.MaxConcurrentReconciles; i++ {
-			go func() {
-				defer wg.Done()
-				// Run a worker thread that just dequeues items, processes them, and marks them done.
-				// It enforces that the reconcileHandler is never invoked concurrently with the same object.
-				for c.processNextWorkItem(ctx) {
-				}
-			}()
-		}
+		c.workers.start(ctx, c.MaxConcurrentReconciles, c.processNextWorkItem)
 
 		c.Started = true
+		c.status.setStarted()
 		return nil
-	}()
-	if err != nil {
-		return err
+	}
+	for {
+		err := start()
+		if err == nil {
+			break
+		}
+		if c.RestartRateLimiter == nil || ctx.Err() != nil {
+			return err
+		}
+		if src := c.unrestartableSource(); src != nil {
+			c.LogConstructor(nil).Error(err, "Controller failed to start and can not be restarted", "source", fmt.Sprintf("%s", src))
+			return err
+		}
+
+		delay := c.RestartRateLimiter.When(c.Name)
+		c.LogConstructor(nil).Error(err, "Controller failed to start, restarting", "delay", delay)
+		c.status.setError(err)
+		ctrlmetrics.Restarting.WithLabelValues(c.Name).Set(1)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			ctrlmetrics.Restarting.WithLabelValues(c.Name).Set(0)
+			return nil
+		}
+		ctrlmetrics.Restarting.WithLabelValues(c.Name).Set(0)
+		ctrlmetrics.Restarts.WithLabelValues(c.Name).Inc()
+		c.mu.Lock()
+	}
+	if c.RestartRateLimiter != nil {
+		c.RestartRateLimiter.Forget(c.Name)
 	}
 
 	<-ctx.Done()
 	c.LogConstructor(nil).Info("Shutdown signal received, waiting for all workers to finish")
-	wg.Wait()
+	c.workers.wait()
 	c.LogConstructor(nil).Info("All workers finished")
 	return nil
 }
 
+// Drained returns a channel that is closed once the controller stopped: its queue is
+// shut down and none of its reconciles is in flight anymore. This happens once Start
+// returns, either because its context was cancelled or because it failed.
+func (c *Controller) Drained() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drainedLocked()
+}
+
+// drainedLocked returns the channel returned by Drained. c.mu must be held.
+func (c *Controller) drainedLocked() chan struct{} {
+	if c.drained == nil {
+		c.drained = make(chan struct{})
+	}
+	return c.drained
+}
+
+// init initializes the metrics, the internal context and the queue of the controller.
+// c.mu must be held.
+func (c *Controller) init(ctx context.Context) {
+	c.initMetrics()
+
+	// Set the internal context.
+	c.ctx = ctx
+
+	q := newTrackingQueue(c.MakeQueue())
+	if c.ClusterMetrics {
+		q.clusterMetricsController = c.Name
+	}
+	q.onClusterDrained = c.forgetDisengagedCluster
+	c.Queue = q
+	c.clustersMu.Lock()
+	c.clusterQueue = q
+	c.clustersMu.Unlock()
+	c.status.setQueue(c.Queue)
+	if dropped := c.enqueuer.setQueue(c.Queue); dropped > 0 {
+		c.GetLogger().Info("Dropped requests enqueued before the controller started", "dropped", dropped, "maxPending", maxPendingRequests)
+	}
+
+	if c.ClusterWatchRateLimiter == nil {
+		c.ClusterWatchRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Second, 5*time.Minute)
+	}
+}
+
+// startSources starts the sources of the controller and waits for their caches to sync.
+// c.mu must be held.
+func (c *Controller) startSources(ctx context.Context) error {
+	if err := c.syncSources(ctx, c.startWatches); err != nil {
+		return err
+	}
+	c.sourcesSynced(ctx)
+	return nil
+}
+
+// syncSources starts the sources of the given watches and waits for their caches to
+// sync. It doesn't need c.mu, as long as the queue of the controller isn't replaced.
+func (c *Controller) syncSources(ctx context.Context, watches []watchDescription) (err error) {
+	// Stop the sources that were already started if one of them fails, so that they
+	// don't leak when the sources are started again on restart.
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	// NB(directxman12): launch the sources *before* trying to wait for the
+	// caches to sync so that they have a chance to register their intendeded
+	// caches.
+	for _, watch := range watches {
+		c.LogConstructor(nil).Info("Starting EventSource", "source", fmt.Sprintf("%s", watch.src))
+
+		if err := watch.src.Start(ctx, watch.handler, c.Queue, watch.predicates...); err != nil {
+			return err
+		}
+	}
+
+	// Start the SharedIndexInformer factories to begin populating the SharedIndexInformer caches
+	c.LogConstructor(nil).Info("Starting Controller")
+
+	for _, watch := range watches {
+		syncingSource, ok := watch.src.(source.SyncingSource)
+		if !ok {
+			continue
+		}
+
+		if err := func() error {
+			// use a context with timeout for launching sources and syncing caches.
+			sourceStartCtx, cancel := context.WithTimeout(ctx, c.CacheSyncTimeout)
+			defer cancel()
+
+			// WaitForSync waits for a definitive timeout, and returns if there
+			// is an error or a timeout
+			if err := syncingSource.WaitForSync(sourceStartCtx); err != nil {
+				err := fmt.Errorf("failed to wait for %s caches to sync: %w", c.Name, err)
+				c.LogConstructor(nil).Error(err, "Could not wait for Cache to sync")
+				return err
+			}
+
+			return nil
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourcesSynced records that the sources of the controller were started and their
+// caches synced. c.mu must be held.
+func (c *Controller) sourcesSynced(ctx context.Context) {
+	c.startEngagedClusterWatches(ctx)
+
+	// All the watches have been started, we can reset the local slice.
+	//
+	// We should never hold watches more than necessary, each watch source can hold a backing cache,
+	// which won't be garbage collected if we hold a reference to it.
+	c.startWatches = nil
+	c.sourcesStarted = true
+	c.status.setSynced()
+}
+
+// unrestartableSource returns the first source of the controller that can't be started
+// again once the context it was started with is cancelled, or nil. c.mu must be held.
+func (c *Controller) unrestartableSource() source.Source {
+	for _, watch := range c.startWatches {
+		switch watch.src.(type) {
+		// Kind removes its event handler once its context is cancelled, and Func sources
+		// are expected to handle being started again by whoever restarts the controller.
+		case *internalsource.Kind, source.Func:
+		default:
+			return watch.src
+		}
+	}
+	return nil
+}
+
 // processNextWorkItem will read a single work item off the workqueue and
 // attempt to process it, by calling the reconcileHandler.
 func (c *Controller) processNextWorkItem(ctx context.Context) bool {
@@ -277,11 +642,16 @@ const (
 func (c *Controller) initMetrics() {
 	ctrlmetrics.ActiveWorkers.WithLabelValues(c.Name).Set(0)
 	ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.ReconcilePanics.WithLabelValues(c.Name).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelRequeueAfter).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelRequeue).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelSuccess).Add(0)
+	ctrlmetrics.LeaderReconcileTotal.WithLabelValues(c.Name, "true").Add(0)
+	ctrlmetrics.LeaderReconcileTotal.WithLabelValues(c.Name, "false").Add(0)
 	ctrlmetrics.WorkerCount.WithLabelValues(c.Name).Set(float64(c.MaxConcurrentReconciles))
+	ctrlmetrics.Restarts.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.Restarting.WithLabelValues(c.Name).Set(0)
 }
 
 func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
@@ -306,16 +676,110 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	log := c.LogConstructor(&req)
 	reconcileID := uuid.NewUUID()
 
+	if c.isDisengaged(req.ClusterName) {
+		c.Queue.Forget(obj)
+		log.V(1).Info("Dropping request of a disengaged cluster")
+		return
+	}
+	ctx, cancel := c.withClusterContext(ctx, req.ClusterName)
+	defer cancel()
+	recordCluster := c.ClusterMetrics && req.ClusterName != ""
+	if recordCluster {
+		ctx = metrics.WithClusterName(ctx, req.ClusterName)
+	}
+
 	log = log.WithValues("reconcileID", reconcileID)
 	ctx = logf.IntoContext(ctx, log)
 	ctx = addReconcileID(ctx, reconcileID)
+	ctx = client.WithControllerName(ctx, c.Name)
+
+	if c.GetCluster != nil {
+		cl, err := c.GetCluster(ctx, req.ClusterName)
+		switch {
+		case errors.Is(err, cluster.ErrClusterNotFound):
+			// The cluster was disengaged, there is nothing left to reconcile.
+			c.Queue.Forget(obj)
+			log.V(1).Info("Dropping request of a cluster that is not engaged")
+			return
+		case err != nil:
+			c.Queue.AddRateLimited(req)
+			ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Inc()
+			ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Inc()
+			log.Error(err, "Failed to get cluster")
+			return
+		}
+		if !c.selectsCluster(req.ClusterName, cl) {
+			c.Queue.Forget(obj)
+			log.V(1).Info("Dropping request of a cluster that is not selected")
+			return
+		}
+		ctx = cluster.IntoContext(ctx, req.ClusterName, cl)
+	}
+
+	if c.LockKeyFunc != nil {
+		lockKey, err := c.LockKeyFunc(ctx, req)
+		if err != nil {
+			c.Queue.AddRateLimited(req)
+			ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Inc()
+			ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Inc()
+			log.Error(err, "Failed to determine lock key")
+			return
+		}
+		if lockKey != "" {
+			if !c.acquireLockKey(lockKey, req) {
+				log.V(5).Info("Lock key is held by another reconcile, deferring", "lockKey", lockKey)
+				return
+			}
+			defer c.releaseLockKey(lockKey)
+		}
+	}
+
+	if req.ClusterName != "" && c.hasClusterLimits() {
+		cfg := c.clusterLimitConfig()
+		if !c.admit(&c.clusters, cfg, req.ClusterName, req) {
+			log.V(5).Info("Cluster is throttled, deferring")
+			return
+		}
+		defer c.release(&c.clusters, cfg, req.ClusterName)
+	}
+
+	if c.TenantFunc != nil {
+		tenant, err := c.TenantFunc(ctx, req)
+		if err != nil {
+			c.Queue.AddRateLimited(req)
+			ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Inc()
+			ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Inc()
+			log.Error(err, "Failed to determine tenant")
+			return
+		}
+		if tenant != "" {
+			cfg := c.tenantLimitConfig()
+			if !c.admit(&c.tenants, cfg, tenant, req) {
+				log.V(5).Info("Tenant is throttled, deferring", "tenant", tenant)
+				return
+			}
+			defer c.release(&c.tenants, cfg, tenant)
+		}
+	}
 
 	// RunInformersAndControllers the syncHandler, passing it the Namespace/Name string of the
 	// resource to be synced.
 	log.V(5).Info("Reconciling")
+	clusterReconcileStartTS := time.Now()
 	result, err := c.Reconcile(ctx, req)
+	ctrlmetrics.LeaderReconcileTotal.WithLabelValues(c.Name, metrics.LeaderLabelValue()).Inc()
+	if recordCluster {
+		c.updateClusterMetrics(req.ClusterName, time.Since(clusterReconcileStartTS), err)
+	}
+	if c.isDisengaged(req.ClusterName) {
+		// The reconcile was cancelled because its cluster was disengaged, don't requeue it.
+		c.Queue.Forget(obj)
+		log.V(1).Info("Dropping request of a disengaged cluster")
+		return
+	}
 	switch {
 	case err != nil:
+		c.status.setError(err)
 		if errors.Is(err, reconcile.TerminalError(nil)) {
 			ctrlmetrics.TerminalReconcileErrors.WithLabelValues(c.Name).Inc()
 		} else {
@@ -349,6 +813,44 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	}
 }
 
+// acquireLockKey marks the given lock key as held. If it is already held, the request
+// is deferred until the key is released and false is returned.
+func (c *Controller) acquireLockKey(key string, req reconcile.Request) bool {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+
+	if c.heldLockKeys == nil {
+		c.heldLockKeys = map[string]struct{}{}
+		c.deferredRequests = map[string][]reconcile.Request{}
+	}
+
+	if _, held := c.heldLockKeys[key]; !held {
+		c.heldLockKeys[key] = struct{}{}
+		return true
+	}
+
+	for _, deferred := range c.deferredRequests[key] {
+		if deferred == req {
+			return false
+		}
+	}
+	c.deferredRequests[key] = append(c.deferredRequests[key], req)
+	return false
+}
+
+// releaseLockKey releases the given lock key and adds all requests that were
+// deferred while it was held back to the queue.
+func (c *Controller) releaseLockKey(key string) {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+
+	delete(c.heldLockKeys, key)
+	for _, req := range c.deferredRequests[key] {
+		c.Queue.Add(req)
+	}
+	delete(c.deferredRequests, key)
+}
+
 // GetLogger returns this controller's logger.
 func (c *Controller) GetLogger() logr.Logger {
 	return c.LogConstructor(nil)
@@ -359,6 +861,19 @@ func (c *Controller) updateMetrics(reconcileTime time.Duration) {
 	ctrlmetrics.ReconcileTime.WithLabelValues(c.Name).Observe(reconcileTime.Seconds())
 }
 
+// updateClusterMetrics records the per-cluster metrics of a reconcile of the given
+// provider cluster. The reconciles of a cluster are already counted when the cluster
+// has limits.
+func (c *Controller) updateClusterMetrics(clusterName string, reconcileTime time.Duration, err error) {
+	if !c.hasClusterLimits() {
+		ctrlmetrics.ClusterReconcileTotal.WithLabelValues(c.Name, clusterName).Inc()
+	}
+	if err != nil {
+		ctrlmetrics.ClusterReconcileErrors.WithLabelValues(c.Name, clusterName).Inc()
+	}
+	ctrlmetrics.ClusterReconcileTime.WithLabelValues(c.Name, clusterName).Observe(reconcileTime.Seconds())
+}
+
 // ReconcileIDFromContext gets the reconcileID from the current context.
 func ReconcileIDFromContext(ctx context.Context) types.UID {
 	r, ok := ctx.Value(reconcileIDKey{}).(types.UID)
@@ -20,29 +20,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	dto "github.com/prometheus/client_model/go"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -121,6 +134,31 @@ var _ = Describe("controller", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("[recovered]"))
 		})
+		It("should call the PanicHandler and count the panic", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var handledReq reconcile.Request
+			var handledValue any
+			var handledStack []byte
+			ctrl.Name = "panic-handler"
+			ctrl.RecoverPanic = ptr.To(true)
+			ctrl.PanicHandler = func(_ context.Context, req reconcile.Request, recovered any, stack []byte) {
+				handledReq, handledValue, handledStack = req, recovered, stack
+			}
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				panic("boom")
+			})
+			_, err := ctrl.Reconcile(ctx, request)
+			Expect(err).To(HaveOccurred())
+			Expect(handledReq).To(Equal(request))
+			Expect(handledValue).To(Equal("boom"))
+			Expect(string(handledStack)).To(ContainSubstring("panic"))
+
+			var metric dto.Metric
+			Expect(ctrlmetrics.ReconcilePanics.WithLabelValues(ctrl.Name).Write(&metric)).To(Succeed())
+			Expect(metric.GetCounter().GetValue()).To(Equal(1.0))
+		})
 	})
 
 	Describe("Start", func() {
@@ -301,6 +339,50 @@ var _ = Describe("controller", func() {
 			Expect(ctrl.Start(ctx)).To(Equal(err))
 		})
 
+		It("should restart the controller if it is restartable and its sources fail to start", func() {
+			var starts atomic.Int32
+			src := source.Func(func(context.Context, handler.EventHandler,
+				workqueue.RateLimitingInterface,
+				...predicate.Predicate) error {
+				if starts.Add(1) < 3 {
+					return fmt.Errorf("Expected Error: could not start source")
+				}
+				return nil
+			})
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+			ctrl.RestartRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- ctrl.Start(ctx)
+			}()
+			Eventually(ctrl.Status).Should(HaveField("Started", BeTrue()))
+			Expect(starts.Load()).To(BeEquivalentTo(3))
+			Expect(ctrl.Status().LastError).To(ContainSubstring("could not start source"))
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		It("should not restart the controller if one of its sources can't be started again", func() {
+			var starts atomic.Int32
+			src := source.Func(func(context.Context, handler.EventHandler,
+				workqueue.RateLimitingInterface,
+				...predicate.Predicate) error {
+				starts.Add(1)
+				return fmt.Errorf("Expected Error: could not start source")
+			})
+			Expect(ctrl.Watch(&source.Channel{Source: make(chan event.GenericEvent)}, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+			ctrl.RestartRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, 10*time.Millisecond)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(ctrl.Start(ctx)).To(MatchError(ContainSubstring("could not start source")))
+			Expect(starts.Load()).To(BeEquivalentTo(1))
+		})
+
 		It("should return an error if it gets started more than once", func() {
 			// Use a cancelled context so Start doesn't block
 			ctx, cancel := context.WithCancel(context.Background())
@@ -313,6 +395,382 @@ var _ = Describe("controller", func() {
 
 	})
 
+	Describe("Drained", func() {
+		It("should be closed once the in-flight reconciles finished after the controller was stopped", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			reconciling, finish := make(chan struct{}), make(chan struct{})
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				close(reconciling)
+				<-finish
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			queue.Add(request)
+			Eventually(reconciling).Should(BeClosed())
+
+			By("Not being drained while a reconcile is in flight")
+			cancel()
+			Consistently(ctrl.Drained()).ShouldNot(BeClosed())
+
+			close(finish)
+			Eventually(ctrl.Drained()).Should(BeClosed())
+			Expect(queue.ShuttingDown()).To(BeTrue())
+		})
+
+		It("should be closed again once a controller that failed to start is started again", func() {
+			src := source.Func(func(context.Context, handler.EventHandler,
+				workqueue.RateLimitingInterface,
+				...predicate.Predicate) error {
+				return fmt.Errorf("Expected Error: could not start source")
+			})
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			Expect(ctrl.Start(ctx)).NotTo(Succeed())
+			Expect(ctrl.Drained()).To(BeClosed())
+			Expect(ctrl.Start(ctx)).NotTo(Succeed())
+			Expect(ctrl.Drained()).To(BeClosed())
+		})
+	})
+
+	Describe("Enqueue", func() {
+		It("should reconcile the enqueued requests, including the ones enqueued before it started", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			early := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "early"}}
+			ctrl.Enqueue(early)
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			Expect(<-reconciled).To(Equal(early))
+
+			ctrl.Enqueue(request)
+			Expect(<-reconciled).To(Equal(request))
+		})
+
+		It("should keep a limited number of requests enqueued before it started", func() {
+			for i := 0; i < maxPendingRequests+10; i++ {
+				ctrl.Enqueue(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: fmt.Sprintf("early-%d", i)}})
+			}
+			// Requests that are already pending are still accepted.
+			ctrl.Enqueue(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "early-0"}})
+
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{})
+			defer q.ShutDown()
+			Expect(ctrl.enqueuer.setQueue(q)).To(Equal(10))
+			Expect(q.Len()).To(Equal(maxPendingRequests))
+		})
+	})
+
+	Describe("Cluster watches", func() {
+		It("should watch every engaged cluster and resync the watches of a single cluster", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ctrl.CacheSyncTimeout = 10 * time.Second
+
+			type sourceStart struct {
+				cluster string
+				ctx     context.Context
+			}
+			starts := make(chan sourceStart, 10)
+			newSource := func(cl cluster.Cluster) source.Source {
+				name := cluster.GetMetadata(cl).Labels["name"]
+				return source.Func(func(ctx context.Context, h handler.EventHandler, q workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+					starts <- sourceStart{cluster: name, ctx: ctx}
+					h.Create(ctx, event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"}}}, q)
+					return nil
+				})
+			}
+			reconciling := make(chan reconcile.Request, 10)
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciling <- req
+				return reconcile.Result{}, nil
+			})
+
+			Expect(ctrl.WatchClusters(newSource, &handler.EnqueueRequestForObject{})).To(Succeed())
+			for _, name := range []string{"cluster-a", "cluster-b"} {
+				cl := &fakeCluster{metadata: cluster.Metadata{Labels: map[string]string{"name": name}}}
+				Expect(ctrl.Engage(ctx, name, cl)).To(Succeed())
+			}
+
+			By("Not starting the watches before the controller is started")
+			Expect(ctrl.ResyncCluster(ctx, "cluster-a")).NotTo(Succeed())
+			Consistently(starts).ShouldNot(Receive())
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			started := map[string]context.Context{}
+			for i := 0; i < 2; i++ {
+				var start sourceStart
+				Eventually(starts).Should(Receive(&start))
+				started[start.cluster] = start.ctx
+			}
+			Expect(started).To(HaveKey("cluster-a"))
+			Expect(started).To(HaveKey("cluster-b"))
+
+			By("Enqueueing the Requests of the watches with the name of their cluster")
+			var reqs []reconcile.Request
+			for i := 0; i < 2; i++ {
+				var req reconcile.Request
+				Eventually(reconciling).Should(Receive(&req))
+				reqs = append(reqs, req)
+			}
+			Expect(reqs).To(ConsistOf(
+				reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-a"},
+				reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-b"},
+			))
+
+			By("Restarting only the watches of the resynced cluster")
+			Expect(ctrl.ResyncCluster(ctx, "cluster-a")).To(Succeed())
+			var resynced sourceStart
+			Expect(starts).To(Receive(&resynced))
+			Expect(resynced.cluster).To(Equal("cluster-a"))
+			Expect(resynced.ctx.Err()).NotTo(HaveOccurred())
+			Expect(started["cluster-a"].Err()).To(HaveOccurred())
+			Expect(started["cluster-b"].Err()).NotTo(HaveOccurred())
+			Consistently(starts).ShouldNot(Receive())
+			Eventually(reconciling).Should(Receive(Equal(reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-a"})))
+
+			By("Failing to resync clusters that aren't engaged")
+			Expect(ctrl.ResyncCluster(ctx, "cluster-c")).To(MatchError(cluster.ErrClusterNotFound))
+		})
+
+		It("should retry the sources that fail to start without affecting the other sources", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ctrlmetrics.ClusterWatchFailures.Reset()
+			ctrl.ClusterWatchRateLimiter = workqueue.NewItemExponentialFailureRateLimiter(50*time.Millisecond, 50*time.Millisecond)
+
+			healthy := func(cl cluster.Cluster) source.Source {
+				return source.Func(func(context.Context, handler.EventHandler, workqueue.RateLimitingInterface, ...predicate.Predicate) error {
+					return nil
+				})
+			}
+			var attempts atomic.Int32
+			flaky := func(cl cluster.Cluster) source.Source {
+				return source.Func(func(context.Context, handler.EventHandler, workqueue.RateLimitingInterface, ...predicate.Predicate) error {
+					if attempts.Add(1) <= 3 {
+						return errors.New("no matches for kind")
+					}
+					return nil
+				})
+			}
+			Expect(ctrl.WatchClusters(healthy, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.WatchClusters(flaky, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.Engage(ctx, "cluster-a", &fakeCluster{})).To(Succeed())
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+
+			By("Reporting the failing source as pending and the other source as live")
+			Eventually(func(g Gomega) {
+				statuses := ctrl.Status().ClusterWatches
+				g.Expect(statuses).To(HaveLen(1))
+				g.Expect(statuses[0].Cluster).To(Equal("cluster-a"))
+				g.Expect(statuses[0].LiveSources).To(HaveLen(1))
+				g.Expect(statuses[0].PendingSources).To(HaveLen(1))
+				g.Expect(statuses[0].PendingSources[0].LastError).To(Equal("no matches for kind"))
+				g.Expect(statuses[0].PendingSources[0].Retries).To(BeNumerically(">", 0))
+			}).Should(Succeed())
+
+			By("Starting the failing source once it succeeds")
+			Eventually(func(g Gomega) {
+				statuses := ctrl.Status().ClusterWatches
+				g.Expect(statuses).To(HaveLen(1))
+				g.Expect(statuses[0].LiveSources).To(HaveLen(2))
+				g.Expect(statuses[0].PendingSources).To(BeEmpty())
+			}).Should(Succeed())
+			Expect(attempts.Load()).To(BeEquivalentTo(4))
+
+			var metric dto.Metric
+			Expect(ctrlmetrics.ClusterWatchFailures.WithLabelValues(ctrl.Name, "cluster-a").Write(&metric)).To(Succeed())
+			Expect(metric.GetCounter().GetValue()).To(Equal(3.0))
+			Expect(ctrlmetrics.ClusterWatchesPending.WithLabelValues(ctrl.Name, "cluster-a").Write(&metric)).To(Succeed())
+			Expect(metric.GetGauge().GetValue()).To(Equal(0.0))
+		})
+	})
+
+	Describe("Simulate", func() {
+		It("should report the requests the watches of the event's kind would enqueue", func() {
+			ctrl.Scheme = scheme.Scheme
+			pods := source.Kind(&informertest.FakeInformers{}, &corev1.Pod{})
+			Expect(ctrl.Watch(pods, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.Watch(pods, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetLabels()["app"]}}}
+			}), predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()["app"] != ""
+			}))).To(Succeed())
+			Expect(ctrl.Watch(source.Kind(&informertest.FakeInformers{}, &appsv1.Deployment{}), &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod", Labels: map[string]string{"app": "app"}}}
+			result, err := ctrl.Simulate(context.Background(), SimulatedEvent{Type: SimulatedCreate, Object: pod})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requests).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}},
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod"}},
+			}))
+			Expect(result.Watches).To(HaveLen(2))
+
+			By("Reporting the predicate that filtered the event out")
+			pod.Labels = nil
+			result, err = ctrl.Simulate(context.Background(), SimulatedEvent{Type: SimulatedDelete, Object: pod})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requests).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod"}},
+			}))
+			Expect(result.Watches[1].FilteredBy).To(Equal("predicate.Funcs"))
+			Expect(result.Watches[1].Requests).To(BeEmpty())
+		})
+
+		It("should support event handlers using the other methods of the queue", func() {
+			Expect(ctrl.Watch(source.Kind(&informertest.FakeInformers{}, &corev1.Pod{}), handler.Funcs{
+				CreateFunc: func(_ context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+					req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: evt.Object.GetNamespace(), Name: evt.Object.GetName()}}
+					if q.Len() == 0 && q.NumRequeues(req) == 0 {
+						q.AddRateLimited(req)
+					}
+				},
+			})).To(Succeed())
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"}}
+			result, err := ctrl.Simulate(context.Background(), SimulatedEvent{Type: SimulatedCreate, Object: pod})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requests).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod"}},
+			}))
+		})
+
+		It("should fail once the controller was started", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+
+			_, err := ctrl.Simulate(context.Background(), SimulatedEvent{Type: SimulatedCreate, Object: &corev1.Pod{}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Warmup", func() {
+		It("should start the sources without reconciling until the controller is started", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var starts atomic.Int32
+			src := source.Func(func(_ context.Context, _ handler.EventHandler, q workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+				starts.Add(1)
+				q.Add(request)
+				return nil
+			})
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			Expect(ctrl.Warmup(ctx)).To(Succeed())
+			Expect(starts.Load()).To(Equal(int32(1)))
+			Expect(ctrl.Queue.Len()).To(Equal(1))
+			Consistently(reconciled).ShouldNot(Receive())
+
+			By("Starting the controller")
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			Eventually(reconciled).Should(Receive(Equal(request)))
+			Expect(starts.Load()).To(Equal(int32(1)))
+		})
+
+		It("should not start the sources if the controller was already started", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+
+			src := source.Func(func(context.Context, handler.EventHandler, workqueue.RateLimitingInterface, ...predicate.Predicate) error {
+				defer GinkgoRecover()
+				Fail("source should not be started")
+				return nil
+			})
+			ctrl.startWatches = append(ctrl.startWatches, watchDescription{src: src})
+			Expect(ctrl.Warmup(ctx)).To(Succeed())
+		})
+
+		It("should start the sources again with the queue of Warmup if it failed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var queues []workqueue.RateLimitingInterface
+			src := source.Func(func(_ context.Context, _ handler.EventHandler, q workqueue.RateLimitingInterface, _ ...predicate.Predicate) error {
+				queues = append(queues, q)
+				if len(queues) == 1 {
+					return fmt.Errorf("Expected Error: could not start source")
+				}
+				q.Add(request)
+				return nil
+			})
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			Expect(ctrl.Warmup(ctx)).To(MatchError(ContainSubstring("could not start source")))
+			queue := ctrl.Queue
+
+			By("Starting the controller")
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			Eventually(reconciled).Should(Receive(Equal(request)))
+			Expect(queues).To(HaveLen(2))
+			Expect(queues[1]).To(BeIdenticalTo(queues[0]))
+			Expect(ctrl.Queue).To(BeIdenticalTo(queue))
+		})
+
+		It("should not start the sources again if Warmup failed to sync a source that can't be started again", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			src := &failingSyncSource{}
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			Expect(ctrl.Warmup(ctx)).To(MatchError(ContainSubstring("could not sync source")))
+			Expect(ctrl.Start(ctx)).To(MatchError(ContainSubstring("failed to warm up")))
+			Expect(src.starts.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("should not hold the lock of the controller while the sources of Warmup sync", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			synced := make(chan struct{})
+			src := &failingSyncSource{synced: synced}
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			warmedUp := make(chan error)
+			go func() {
+				warmedUp <- ctrl.Warmup(ctx)
+			}()
+			Eventually(src.starts.Load).Should(BeEquivalentTo(1))
+			Expect(ctrl.Watch(source.Func(func(context.Context, handler.EventHandler, workqueue.RateLimitingInterface, ...predicate.Predicate) error {
+				return nil
+			}), &handler.EnqueueRequestForObject{})).To(Succeed())
+			Consistently(warmedUp).ShouldNot(Receive())
+
+			close(synced)
+			Eventually(warmedUp).Should(Receive(MatchError(ContainSubstring("could not sync source"))))
+		})
+	})
+
 	Describe("Processing queue items from a Controller", func() {
 		It("should call Reconciler if an item is enqueued", func() {
 			ctx, cancel := context.WithCancel(context.Background())
@@ -332,6 +790,153 @@ var _ = Describe("controller", func() {
 			Eventually(func() int { return queue.NumRequeues(request) }).Should(Equal(0))
 		})
 
+		It("should pass the name of the controller to the Reconciler", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ctrl.Name = "test-controller"
+			names := make(chan string, 1)
+			ctrl.Do = reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				names <- client.ControllerNameFromContext(ctx)
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+			queue.Add(request)
+			Eventually(names).Should(Receive(Equal("test-controller")))
+		})
+
+		It("should pass the cluster of the Request to the Reconciler", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			engaged := &fakeCluster{}
+			ctrl.GetCluster = func(_ context.Context, name string) (cluster.Cluster, error) {
+				if name != "cluster-a" {
+					return nil, cluster.ErrClusterNotFound
+				}
+				return engaged, nil
+			}
+			clusters := make(chan cluster.Cluster, 1)
+			ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				defer GinkgoRecover()
+				cl, err := cluster.FromContext(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.NameFromContext(ctx)).To(Equal(req.ClusterName))
+				clusters <- cl
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			By("dropping the Requests of clusters that aren't engaged")
+			disengaged := reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-b"}
+			queue.Add(disengaged)
+			Eventually(queue.Len).Should(Equal(0))
+			Expect(queue.NumRequeues(disengaged)).To(Equal(0))
+			Expect(clusters).NotTo(Receive())
+
+			queue.Add(reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-a"})
+			Eventually(clusters).Should(Receive(BeIdenticalTo(engaged)))
+		})
+
+		It("should only reconcile the Requests of clusters matching the ClusterSelector", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			clusters := map[string]cluster.Cluster{
+				"prod": &fakeCluster{metadata: cluster.Metadata{Labels: map[string]string{"env": "prod"}}},
+				"dev":  &fakeCluster{metadata: cluster.Metadata{Labels: map[string]string{"env": "dev"}}},
+			}
+			ctrl.GetCluster = func(_ context.Context, name string) (cluster.Cluster, error) {
+				return clusters[name], nil
+			}
+			ctrl.ClusterSelector = labels.SelectorFromSet(labels.Set{"env": "prod"})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			queue.Add(reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "dev"})
+			Eventually(queue.Len).Should(Equal(0))
+			Consistently(reconciled).ShouldNot(Receive())
+
+			prod := reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "prod"}
+			queue.Add(prod)
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			Eventually(reconciled).Should(Receive(Equal(prod)))
+		})
+
+		It("should engage and disengage clusters as their labels start and stop matching the ClusterSelector", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cl := &notifyingCluster{}
+			cl.setLabels(map[string]string{"env": "dev"})
+			ctrl.ClusterSelector = labels.SelectorFromSet(labels.Set{"env": "prod"})
+			Expect(ctrl.Engage(ctx, "cluster-a", cl)).To(Succeed())
+			engaged := func() bool {
+				ctrl.clustersMu.Lock()
+				defer ctrl.clustersMu.Unlock()
+				_, ok := ctrl.engagedClusters["cluster-a"]
+				return ok
+			}
+			Expect(engaged()).To(BeFalse())
+
+			cl.setLabels(map[string]string{"env": "prod"})
+			Eventually(engaged).Should(BeTrue())
+
+			cl.setLabels(map[string]string{"env": "dev"})
+			Eventually(engaged).Should(BeFalse())
+		})
+
+		It("should cancel and drop the Requests of disengaged clusters", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			clusterCtx, disengage := context.WithCancel(ctx)
+			Expect(ctrl.Engage(clusterCtx, "cluster-a", &fakeCluster{})).To(Succeed())
+			reconciling := make(chan reconcile.Request, 2)
+			ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciling <- req
+				<-ctx.Done()
+				return reconcile.Result{}, ctx.Err()
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			inFlight := reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-a"}
+			queue.Add(inFlight)
+			Eventually(reconciling).Should(Receive(Equal(inFlight)))
+			queued := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "baz"}, ClusterName: "cluster-a"}
+			ctrl.Queue.Add(queued)
+
+			By("cancelling the in-flight reconcile without requeueing it")
+			disengage()
+			Eventually(queue.Len).Should(Equal(0))
+			Eventually(func() int { return queue.NumRequeues(inFlight) }).Should(Equal(0))
+			queue.AddedRateLimitedLock.Lock()
+			Expect(queue.AddedRatelimited).To(BeEmpty())
+			queue.AddedRateLimitedLock.Unlock()
+
+			By("dropping queued Requests of the cluster")
+			Consistently(reconciling).ShouldNot(Receive())
+
+			By("forgetting the cluster once none of its Requests are queued")
+			Eventually(func() bool {
+				ctrl.clustersMu.Lock()
+				defer ctrl.clustersMu.Unlock()
+				_, ok := ctrl.disengagedClusters["cluster-a"]
+				return ok
+			}).Should(BeFalse())
+		})
+
 		It("should continue to process additional queue items after the first", func() {
 			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
 				defer GinkgoRecover()
@@ -405,6 +1010,259 @@ var _ = Describe("controller", func() {
 			Expect(queue.Len()).Should(Equal(0))
 		})
 
+		It("should not reconcile requests with the same lock key concurrently", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var mu sync.Mutex
+			inFlight, maxInFlight := 0, 0
+			processed := make(chan reconcile.Request, 2)
+			ctrl.MaxConcurrentReconciles = 2
+			ctrl.LockKeyFunc = func(context.Context, reconcile.Request) (string, error) {
+				return "shared", nil
+			}
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				processed <- req
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			other := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "baz"}}
+			queue.Add(request)
+			queue.Add(other)
+
+			By("Reconciling both requests")
+			Eventually(processed).Should(Receive())
+			Eventually(processed).Should(Receive())
+
+			By("Never running them in parallel")
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(maxInFlight).To(Equal(1))
+		})
+
+		It("should limit the concurrent reconciles of a tenant", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var mu sync.Mutex
+			inFlight, maxInFlight := map[string]int{}, map[string]int{}
+			processed := make(chan reconcile.Request, 3)
+			ctrl.MaxConcurrentReconciles = 3
+			ctrl.TenantMaxConcurrentReconciles = 1
+			ctrl.TenantFunc = func(_ context.Context, req reconcile.Request) (string, error) {
+				return req.Namespace, nil
+			}
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				mu.Lock()
+				inFlight[req.Namespace]++
+				if inFlight[req.Namespace] > maxInFlight[req.Namespace] {
+					maxInFlight[req.Namespace] = inFlight[req.Namespace]
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				inFlight[req.Namespace]--
+				mu.Unlock()
+				processed <- req
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			queue.Add(request)
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "baz"}})
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "other", Name: "bar"}})
+
+			By("Reconciling all requests")
+			for i := 0; i < 3; i++ {
+				Eventually(processed).Should(Receive())
+			}
+
+			By("Never running the requests of a tenant in parallel")
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(maxInFlight).To(Equal(map[string]int{"foo": 1, "other": 1}))
+		})
+
+		It("should limit the concurrent reconciles of a cluster", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var mu sync.Mutex
+			inFlight, maxInFlight := map[string]int{}, map[string]int{}
+			processed := make(chan reconcile.Request, 4)
+			ctrl.MaxConcurrentReconciles = 4
+			ctrl.ClusterMaxConcurrentReconciles = 1
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				mu.Lock()
+				inFlight[req.ClusterName]++
+				if inFlight[req.ClusterName] > maxInFlight[req.ClusterName] {
+					maxInFlight[req.ClusterName] = inFlight[req.ClusterName]
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				inFlight[req.ClusterName]--
+				mu.Unlock()
+				processed <- req
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			for _, req := range []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}, ClusterName: "cluster-a"},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "baz"}, ClusterName: "cluster-a"},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}, ClusterName: "cluster-b"},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}},
+			} {
+				queue.Add(req)
+			}
+
+			By("Reconciling all requests")
+			for i := 0; i < 4; i++ {
+				Eventually(processed).Should(Receive())
+			}
+
+			By("Never running the requests of a cluster in parallel")
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(maxInFlight).To(Equal(map[string]int{"cluster-a": 1, "cluster-b": 1, "": 1}))
+		})
+
+		It("should record per-cluster metrics if ClusterMetrics is set", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ctrlmetrics.ClusterReconcileTotal.Reset()
+			ctrlmetrics.ClusterReconcileErrors.Reset()
+			clusterNames := make(chan string, 3)
+			ctrl.ClusterMetrics = true
+			ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				clusterNames <- metrics.ClusterNameFromContext(ctx)
+				if req.Name == "fail" {
+					return reconcile.Result{}, reconcile.TerminalError(errors.New("failed"))
+				}
+				return reconcile.Result{}, nil
+			})
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			for _, req := range []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}, ClusterName: "cluster-a"},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "fail"}, ClusterName: "cluster-a"},
+				{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}},
+			} {
+				queue.Add(req)
+			}
+
+			By("Attributing the REST client requests of a reconcile to its cluster")
+			var names []string
+			for i := 0; i < 3; i++ {
+				var name string
+				Eventually(clusterNames).Should(Receive(&name))
+				names = append(names, name)
+			}
+			Expect(names).To(ConsistOf("cluster-a", "cluster-a", ""))
+
+			By("Recording the reconciles of the provider cluster")
+			var metric dto.Metric
+			Eventually(func() float64 {
+				Expect(ctrlmetrics.ClusterReconcileErrors.WithLabelValues(ctrl.Name, "cluster-a").Write(&metric)).To(Succeed())
+				return metric.GetCounter().GetValue()
+			}).Should(Equal(1.0))
+			Expect(ctrlmetrics.ClusterReconcileTotal.WithLabelValues(ctrl.Name, "cluster-a").Write(&metric)).To(Succeed())
+			Expect(metric.GetCounter().GetValue()).To(Equal(2.0))
+			Expect(ctrlmetrics.ClusterReconcileTotal.WithLabelValues(ctrl.Name, "").Write(&metric)).To(Succeed())
+			Expect(metric.GetCounter().GetValue()).To(BeZero())
+		})
+
+		It("should delete the per-cluster metrics of a disengaged cluster", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ctrlmetrics.ClusterReconcileTotal.Reset()
+			ctrlmetrics.ClusterReconcileTime.Reset()
+			ctrl.ClusterMetrics = true
+			clusterCtx, disengage := context.WithCancel(ctx)
+			Expect(ctrl.Engage(clusterCtx, "cluster-a", &fakeCluster{})).To(Succeed())
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			req := reconcile.Request{NamespacedName: request.NamespacedName, ClusterName: "cluster-a"}
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			queue.Add(req)
+			Eventually(reconciled).Should(Receive(Equal(req)))
+			Eventually(func() int { return testutil.CollectAndCount(ctrlmetrics.ClusterReconcileTotal) }).Should(Equal(1))
+			Expect(testutil.CollectAndCount(ctrlmetrics.ClusterReconcileTime)).To(Equal(1))
+
+			disengage()
+			Eventually(func() int { return testutil.CollectAndCount(ctrlmetrics.ClusterReconcileTotal) }).Should(BeZero())
+			Expect(testutil.CollectAndCount(ctrlmetrics.ClusterReconcileTime)).To(BeZero())
+		})
+
+		It("should delay the requests of a tenant throttled by its rate limiter", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			limiters := 0
+			ctrl.TenantFunc = func(_ context.Context, req reconcile.Request) (string, error) {
+				return req.Namespace, nil
+			}
+			ctrl.NewTenantRateLimiter = func() ratelimiter.RateLimiter {
+				limiters++
+				return &delayOnceRateLimiter{delay: 100 * time.Millisecond}
+			}
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			ctrl.MakeQueue = func() workqueue.RateLimitingInterface { return queue }
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			start := time.Now()
+			queue.Add(request)
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			Expect(<-reconciled).To(Equal(request))
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+
+			By("Not delaying the following requests")
+			start = time.Now()
+			queue.Add(request)
+			fakeReconcile.AddResult(reconcile.Result{}, nil)
+			Expect(<-reconciled).To(Equal(request))
+			Expect(time.Since(start)).To(BeNumerically("<", 100*time.Millisecond))
+			Expect(limiters).To(Equal(1))
+		})
+
 		// TODO(directxman12): we should ensure that backoff occurrs with error requeue
 
 		It("should not reset backoff until there's a non-error result", func() {
@@ -668,6 +1526,37 @@ var _ = Describe("controller", func() {
 					return nil
 				}, 2.0).Should(Succeed())
 			})
+
+			It("should label reconciliations with the leadership state of the replica", func() {
+				ctrlmetrics.LeaderReconcileTotal.Reset()
+				metrics.SetLeader(true)
+				DeferCleanup(metrics.SetLeader, false)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+				}()
+				By("Invoking Reconciler as the leader")
+				queue.Add(request)
+				fakeReconcile.AddResult(reconcile.Result{}, nil)
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(func() float64 {
+					Expect(ctrlmetrics.LeaderReconcileTotal.WithLabelValues(ctrl.Name, "true").Write(&reconcileTotal)).To(Succeed())
+					return reconcileTotal.GetCounter().GetValue()
+				}).Should(Equal(1.0))
+
+				By("Invoking Reconciler as a standby replica")
+				metrics.SetLeader(false)
+				queue.Add(request)
+				fakeReconcile.AddResult(reconcile.Result{}, nil)
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(func() float64 {
+					Expect(ctrlmetrics.LeaderReconcileTotal.WithLabelValues(ctrl.Name, "false").Write(&reconcileTotal)).To(Succeed())
+					return reconcileTotal.GetCounter().GetValue()
+				}).Should(Equal(1.0))
+			})
 		})
 
 		Context("should update prometheus metrics", func() {
@@ -754,6 +1643,292 @@ var _ = Describe("controller", func() {
 	})
 })
 
+var _ = Describe("QueueSnapshot", func() {
+	It("should return an empty snapshot before the controller is started", func() {
+		ctrl := &Controller{Name: "not-started"}
+		Expect(ctrl.QueueSnapshot()).To(Equal(QueueSnapshot{Controller: "not-started", Items: []QueueItem{}}))
+	})
+
+	It("should report queued and processing requests", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		release := make(chan struct{})
+		processing := make(chan struct{})
+		ctrl := &Controller{
+			Name:                    "snapshot",
+			MaxConcurrentReconciles: 1,
+			MakeQueue: func() workqueue.RateLimitingInterface {
+				return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			},
+			LogConstructor: func(_ *reconcile.Request) logr.Logger {
+				return log.RuntimeLog.WithName("controller").WithName("test")
+			},
+			Do: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				processing <- struct{}{}
+				<-release
+				return reconcile.Result{}, nil
+			}),
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+		}()
+		Eventually(func() bool {
+			ctrl.mu.Lock()
+			defer ctrl.mu.Unlock()
+			return ctrl.Started
+		}).Should(BeTrue())
+
+		first := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "first"}}
+		second := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "second"}}
+		ctrl.Queue.Add(first)
+		<-processing
+		ctrl.Queue.Add(second)
+
+		snapshot := ctrl.QueueSnapshot()
+		Expect(snapshot.Controller).To(Equal("snapshot"))
+		Expect(snapshot.Items).To(HaveLen(2))
+		Expect(snapshot.Items[0].Request).To(Equal(first))
+		Expect(snapshot.Items[0].Processing).To(BeTrue())
+		Expect(snapshot.Items[1].Request).To(Equal(second))
+		Expect(snapshot.Items[1].Processing).To(BeFalse())
+
+		close(release)
+		Eventually(processing).Should(Receive())
+		Eventually(func() []QueueItem { return ctrl.QueueSnapshot().Items }).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("Status", func() {
+	It("should report the watched kinds, sync status, queue depth and last error", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ctrl := &Controller{
+			Name:                    "status",
+			MaxConcurrentReconciles: 1,
+			Scheme:                  scheme.Scheme,
+			CacheSyncTimeout:        10 * time.Second,
+			MakeQueue: func() workqueue.RateLimitingInterface {
+				return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			},
+			LogConstructor: func(_ *reconcile.Request) logr.Logger {
+				return log.RuntimeLog.WithName("controller").WithName("test")
+			},
+			Do: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, reconcile.TerminalError(errors.New("boom"))
+			}),
+		}
+		Expect(ctrl.Watch(source.Kind(&informertest.FakeInformers{}, &corev1.Pod{}), &handler.EnqueueRequestForObject{})).To(Succeed())
+		Expect(ctrl.Watch(source.Kind(&informertest.FakeInformers{}, &appsv1.Deployment{}), &handler.EnqueueRequestForObject{})).To(Succeed())
+
+		Expect(ctrl.Status()).To(Equal(Status{
+			Name: "status",
+			WatchedKinds: []metav1.GroupVersionKind{
+				{Version: "v1", Kind: "Pod"},
+				{Group: "apps", Version: "v1", Kind: "Deployment"},
+			},
+		}))
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+		}()
+		Eventually(func() bool { return ctrl.Status().Started }).Should(BeTrue())
+		Expect(ctrl.Status().Synced).To(BeTrue())
+
+		ctrl.Queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}})
+		Eventually(func() string { return ctrl.Status().LastError }).Should(ContainSubstring("boom"))
+		status := ctrl.Status()
+		Expect(status.LastErrorTime).NotTo(BeNil())
+		Expect(status.QueueDepth).To(BeZero())
+	})
+
+	It("should report the controller as degraded while the informer of a watched kind is stopped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+		c, err := cache.New(&rest.Config{Host: server.URL}, cache.Options{
+			Mapper:                    mapper,
+			InformerWatchErrorHandler: cache.StopOnPersistentWatchErrors(1, nil),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			Expect(c.Start(ctx)).To(Succeed())
+		}()
+
+		ctrl := &Controller{Name: "degraded", Scheme: scheme.Scheme}
+		Expect(ctrl.Watch(source.Kind(c, &corev1.Pod{}), &handler.EnqueueRequestForObject{})).To(Succeed())
+		Expect(ctrl.Status().Degraded).To(BeFalse())
+
+		_, err = c.GetInformer(ctx, &corev1.Pod{}, cache.BlockUntilSynced(false))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			status := ctrl.Status()
+			g.Expect(status.Degraded).To(BeTrue())
+			g.Expect(status.DegradedReason).To(HavePrefix("informer of Pod stopped after watch error"))
+		}).Should(Succeed())
+	})
+
+	It("should report the options the controller was created with", func() {
+		ctrl := &Controller{
+			Name:                    "config",
+			MaxConcurrentReconciles: 3,
+			CacheSyncTimeout:        time.Minute,
+			RecoverPanic:            ptr.To(true),
+			LeaderElected:           ptr.To(false),
+		}
+		Expect(ctrl.Config()).To(Equal(Config{
+			Name:                    "config",
+			MaxConcurrentReconciles: 3,
+			CacheSyncTimeout:        metav1.Duration{Duration: time.Minute},
+			RecoverPanic:            true,
+		}))
+	})
+})
+
+var _ = Describe("Reload", func() {
+	It("should change the number of workers of a running controller", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var inFlight, maxInFlight atomic.Int32
+		release := make(chan struct{})
+		ctrl := &Controller{
+			Name:                    "reload",
+			MaxConcurrentReconciles: 1,
+			GroupKind:               "Deployment.apps",
+			MakeQueue: func() workqueue.RateLimitingInterface {
+				return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			},
+			LogConstructor: func(_ *reconcile.Request) logr.Logger {
+				return log.RuntimeLog.WithName("controller").WithName("test")
+			},
+			Do: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					peak := maxInFlight.Load()
+					if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+						break
+					}
+				}
+				<-release
+				return reconcile.Result{}, nil
+			}),
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+		}()
+		Eventually(func() bool { return ctrl.Status().Started }).Should(BeTrue())
+
+		for _, name := range []string{"a", "b", "c"} {
+			ctrl.Queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+		Eventually(inFlight.Load).Should(Equal(int32(1)))
+		Consistently(inFlight.Load).Should(Equal(int32(1)))
+
+		Expect(ctrl.Reload(ctx, config.Runtime{GroupKindConcurrency: map[string]int{"ReplicaSet.apps": 5}})).To(Succeed())
+		Consistently(inFlight.Load).Should(Equal(int32(1)))
+
+		Expect(ctrl.Reload(ctx, config.Runtime{GroupKindConcurrency: map[string]int{"Deployment.apps": 3}})).To(Succeed())
+		Eventually(inFlight.Load).Should(Equal(int32(3)))
+		Expect(ctrl.Config().MaxConcurrentReconciles).To(Equal(3))
+
+		Expect(ctrl.Reload(ctx, config.Runtime{GroupKindConcurrency: map[string]int{"Deployment.apps": 1}})).To(Succeed())
+		close(release)
+		for _, name := range []string{"d", "e", "f"} {
+			ctrl.Queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+		Eventually(func() int { return ctrl.Queue.Len() }).Should(BeZero())
+		Expect(ctrl.Config().MaxConcurrentReconciles).To(Equal(1))
+	})
+
+	It("should change the overall rate limit", func() {
+		limiter := NewReloadableRateLimiter(workqueue.NewItemExponentialFailureRateLimiter(0, 0))
+		ctrl := &Controller{ReloadableRateLimiter: limiter}
+		Expect(limiter.When("a")).To(BeZero())
+
+		Expect(ctrl.Reload(context.Background(), config.Runtime{RateLimit: &config.RateLimit{QPS: 1, Burst: 1}})).To(Succeed())
+		Expect(limiter.When("a")).To(BeZero())
+		Expect(limiter.When("b")).To(BeNumerically(">", 500*time.Millisecond))
+
+		Expect(ctrl.Reload(context.Background(), config.Runtime{})).To(Succeed())
+		Expect(limiter.When("c")).To(BeZero())
+	})
+})
+
+var _ = Describe("initial sync handling", func() {
+	var ctrl *Controller
+	var created []event.CreateEvent
+	var hdler handler.EventHandler
+
+	BeforeEach(func() {
+		ctrl = &Controller{}
+		created = nil
+		hdler = handler.Funcs{
+			CreateFunc: func(_ context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+				created = append(created, evt)
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evt.Object)})
+			},
+		}
+	})
+
+	It("should not wrap the handler by default", func() {
+		Expect(ctrl.wrapHandler(hdler)).To(BeAssignableToTypeOf(handler.Funcs{}))
+	})
+
+	It("should drop Create events from the initial list if SkipInitialSync is set", func() {
+		ctrl.SkipInitialSync = true
+		q := &DelegatingQueue{RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+		defer q.ShutDown()
+
+		wrapped := ctrl.wrapHandler(hdler)
+		wrapped.Create(context.Background(), event.CreateEvent{Object: &corev1.Pod{}, IsInInitialList: true}, q)
+		Expect(created).To(BeEmpty())
+
+		wrapped.Create(context.Background(), event.CreateEvent{Object: &corev1.Pod{}}, q)
+		Expect(created).To(HaveLen(1))
+		Expect(q.getCounts().Trying).To(Equal(1))
+	})
+
+	It("should delay requests for Create events from the initial list if InitialSyncRateLimiter is set", func() {
+		ctrl.InitialSyncRateLimiter = workqueue.NewItemFastSlowRateLimiter(time.Hour, time.Hour, 0)
+		q := &DelegatingQueue{RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+		defer q.ShutDown()
+
+		ctrl.wrapHandler(hdler).Create(context.Background(), event.CreateEvent{Object: &corev1.Pod{}, IsInInitialList: true}, q)
+		Expect(created).To(HaveLen(1))
+		Expect(q.getCounts()).To(Equal(countInfo{AddAfter: 1}))
+		Expect(q.Len()).To(Equal(0))
+	})
+
+	It("should delay requests added with AddAfter or AddRateLimited from the initial list", func() {
+		ctrl.InitialSyncRateLimiter = workqueue.NewItemFastSlowRateLimiter(time.Hour, time.Hour, 0)
+		q := &DelegatingQueue{RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+		defer q.ShutDown()
+
+		hdler = handler.Funcs{
+			CreateFunc: func(_ context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+				q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Name: "after"}}, 0)
+				q.AddRateLimited(reconcile.Request{NamespacedName: types.NamespacedName{Name: "limited"}})
+			},
+		}
+		ctrl.wrapHandler(hdler).Create(context.Background(), event.CreateEvent{Object: &corev1.Pod{}, IsInInitialList: true}, q)
+		Expect(q.getCounts()).To(Equal(countInfo{AddAfter: 2}))
+		Expect(q.Len()).To(Equal(0))
+	})
+})
+
 var _ = Describe("ReconcileIDFromContext function", func() {
 	It("should return an empty string if there is nothing in the context", func() {
 		ctx := context.Background()
@@ -771,6 +1946,47 @@ var _ = Describe("ReconcileIDFromContext function", func() {
 	})
 })
 
+type fakeCluster struct {
+	cluster.Cluster
+	metadata cluster.Metadata
+}
+
+func (c *fakeCluster) Metadata() cluster.Metadata {
+	return c.metadata
+}
+
+// notifyingCluster is a fakeCluster whose metadata can change.
+type notifyingCluster struct {
+	fakeCluster
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+func (c *notifyingCluster) Metadata() cluster.Metadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metadata
+}
+
+func (c *notifyingCluster) MetadataChanged() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.changed == nil {
+		c.changed = make(chan struct{})
+	}
+	return c.changed
+}
+
+func (c *notifyingCluster) setLabels(labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metadata = cluster.Metadata{Labels: labels}
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
 type DelegatingQueue struct {
 	workqueue.RateLimitingInterface
 	mu sync.Mutex
@@ -861,6 +2077,24 @@ func (s *singnallingSourceWrapper) WaitForSync(ctx context.Context) error {
 	return s.SyncingSource.WaitForSync(ctx)
 }
 
+// failingSyncSource is a SyncingSource that fails to sync, once synced is closed if set.
+type failingSyncSource struct {
+	synced chan struct{}
+	starts atomic.Int32
+}
+
+func (s *failingSyncSource) Start(context.Context, handler.EventHandler, workqueue.RateLimitingInterface, ...predicate.Predicate) error {
+	s.starts.Add(1)
+	return nil
+}
+
+func (s *failingSyncSource) WaitForSync(ctx context.Context) error {
+	if s.synced != nil {
+		<-s.synced
+	}
+	return errors.New("Expected Error: could not sync source")
+}
+
 var _ cache.Cache = &cacheWithIndefinitelyBlockingGetInformer{}
 
 // cacheWithIndefinitelyBlockingGetInformer has a GetInformer implementation that blocks indefinitely or until its
@@ -877,3 +2111,21 @@ func (c *cacheWithIndefinitelyBlockingGetInformer) GetInformer(ctx context.Conte
 	<-ctx.Done()
 	return nil, errors.New("GetInformer timed out")
 }
+
+// delayOnceRateLimiter delays the first item and no other item.
+type delayOnceRateLimiter struct {
+	delay   time.Duration
+	delayed bool
+}
+
+func (r *delayOnceRateLimiter) When(interface{}) time.Duration {
+	if r.delayed {
+		return 0
+	}
+	r.delayed = true
+	return r.delay
+}
+
+func (r *delayOnceRateLimiter) Forget(interface{}) {}
+
+func (r *delayOnceRateLimiter) NumRequeues(interface{}) int { return 0 }
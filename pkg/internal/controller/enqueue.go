@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ handler.Enqueuer = &Controller{}
+
+// maxPendingRequests is the maximum number of requests enqueued before the controller
+// started that are kept until it starts.
+const maxPendingRequests = 1000
+
+// enqueuer adds the requests passed to Enqueue to the queue of the controller. It has
+// its own lock, as c.mu is held while the caches sync and Enqueue is called from the
+// event handlers of other controllers.
+type enqueuer struct {
+	mu      sync.Mutex
+	queue   workqueue.RateLimitingInterface
+	pending map[reconcile.Request]struct{}
+	// dropped is the number of requests dropped since there were too many pending.
+	dropped int
+}
+
+// Enqueue adds req to the queue of the controller, e.g. for the requests handed over by
+// the watches of other controllers, see handler.EnqueueTo. Up to maxPendingRequests
+// requests enqueued before the queue was created when the controller started are added
+// once it is, further ones are dropped, e.g. for controllers that are never started.
+func (c *Controller) Enqueue(req reconcile.Request) {
+	c.enqueuer.mu.Lock()
+	defer c.enqueuer.mu.Unlock()
+	if c.enqueuer.queue == nil {
+		if c.enqueuer.pending == nil {
+			c.enqueuer.pending = map[reconcile.Request]struct{}{}
+		}
+		if _, ok := c.enqueuer.pending[req]; !ok && len(c.enqueuer.pending) >= maxPendingRequests {
+			c.enqueuer.dropped++
+			return
+		}
+		c.enqueuer.pending[req] = struct{}{}
+		return
+	}
+	c.enqueuer.queue.Add(req)
+}
+
+// setQueue sets the queue the requests are added to and adds the pending requests to
+// it. It returns the number of requests that were dropped before.
+func (e *enqueuer) setQueue(q workqueue.RateLimitingInterface) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queue = q
+	for req := range e.pending {
+		q.Add(req)
+	}
+	e.pending = nil
+	dropped := e.dropped
+	e.dropped = 0
+	return dropped
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// wrapHandler applies the initial sync settings of the controller to the given handler.
+func (c *Controller) wrapHandler(h handler.EventHandler) handler.EventHandler {
+	if !c.SkipInitialSync && c.InitialSyncRateLimiter == nil {
+		return h
+	}
+	return &initialSyncHandler{
+		EventHandler: h,
+		skip:         c.SkipInitialSync,
+		rateLimiter:  c.InitialSyncRateLimiter,
+	}
+}
+
+// initialSyncHandler drops or throttles the Create events that are emitted for the
+// initial list of an informer.
+type initialSyncHandler struct {
+	handler.EventHandler
+	skip        bool
+	rateLimiter ratelimiter.RateLimiter
+}
+
+// Create implements handler.EventHandler.
+func (h *initialSyncHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if !evt.IsInInitialList {
+		h.EventHandler.Create(ctx, evt, q)
+		return
+	}
+	if h.skip {
+		return
+	}
+	h.EventHandler.Create(ctx, evt, &throttledQueue{RateLimitingInterface: q, rateLimiter: h.rateLimiter})
+}
+
+// throttledQueue delays all items added to it by the duration returned from its rate limiter.
+type throttledQueue struct {
+	workqueue.RateLimitingInterface
+	rateLimiter ratelimiter.RateLimiter
+}
+
+// Add implements workqueue.Interface.
+func (q *throttledQueue) Add(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay(item))
+}
+
+// AddAfter implements workqueue.DelayingInterface. The item is added after the
+// longer of the requested duration and the throttling delay.
+func (q *throttledQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.RateLimitingInterface.AddAfter(item, max(duration, q.delay(item)))
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface. Items added from the
+// initial list have not failed yet, so only the throttling delay applies.
+func (q *throttledQueue) AddRateLimited(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay(item))
+}
+
+func (q *throttledQueue) delay(item interface{}) time.Duration {
+	delay := q.rateLimiter.When(item)
+	q.rateLimiter.Forget(item)
+	return delay
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	limitConcurrency = "concurrency"
+	limitRate        = "rate"
+)
+
+// keyedLimits contains the state of the keys, e.g. tenants or clusters, with in-flight
+// or throttled reconciles.
+type keyedLimits struct {
+	mu     sync.Mutex
+	states map[string]*keyState
+}
+
+// keyLimitConfig configures the limits that are enforced per key.
+type keyLimitConfig struct {
+	// maxConcurrent is the maximum number of concurrent reconciles per key, zero means no limit.
+	maxConcurrent int
+
+	// newRateLimiter, if set, returns the rate limiter of a key.
+	newRateLimiter func() ratelimiter.RateLimiter
+
+	// reconcileTotal, activeWorkers and throttledTotal are the metrics of the keys.
+	reconcileTotal *prometheus.CounterVec
+	activeWorkers  *prometheus.GaugeVec
+	throttledTotal *prometheus.CounterVec
+}
+
+// keyState is the state of a key of keyedLimits.
+type keyState struct {
+	// active is the number of in-flight reconciles of the key.
+	active int
+
+	// deferred contains the requests that were dequeued while the key was at its
+	// concurrency limit. They are added back to the queue once a reconcile finishes.
+	deferred []reconcile.Request
+
+	// limiter throttles the reconciles of the key, it is nil if there is no rate limit.
+	limiter ratelimiter.RateLimiter
+
+	// reserved contains the requests that were delayed by limiter. They already
+	// consumed their share of the rate and are admitted without asking limiter again.
+	reserved map[reconcile.Request]struct{}
+}
+
+// tenantLimitConfig returns the limits enforced per tenant.
+func (c *Controller) tenantLimitConfig() keyLimitConfig {
+	return keyLimitConfig{
+		maxConcurrent:  c.TenantMaxConcurrentReconciles,
+		newRateLimiter: c.NewTenantRateLimiter,
+		reconcileTotal: ctrlmetrics.TenantReconcileTotal,
+		activeWorkers:  ctrlmetrics.TenantActiveWorkers,
+		throttledTotal: ctrlmetrics.TenantThrottledTotal,
+	}
+}
+
+// clusterLimitConfig returns the limits enforced per cluster.
+func (c *Controller) clusterLimitConfig() keyLimitConfig {
+	return keyLimitConfig{
+		maxConcurrent:  c.ClusterMaxConcurrentReconciles,
+		newRateLimiter: c.NewClusterRateLimiter,
+		reconcileTotal: ctrlmetrics.ClusterReconcileTotal,
+		activeWorkers:  ctrlmetrics.ClusterActiveWorkers,
+		throttledTotal: ctrlmetrics.ClusterThrottledTotal,
+	}
+}
+
+// hasClusterLimits returns whether limits are enforced per cluster.
+func (c *Controller) hasClusterLimits() bool {
+	return c.ClusterMaxConcurrentReconciles > 0 || c.NewClusterRateLimiter != nil
+}
+
+// admit marks a reconcile of the given key as in-flight. If the key is at its concurrency
+// limit or throttled by its rate limiter, the request is deferred and false is returned.
+func (c *Controller) admit(l *keyedLimits, cfg keyLimitConfig, key string, req reconcile.Request) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.states == nil {
+		l.states = map[string]*keyState{}
+	}
+	s, ok := l.states[key]
+	if !ok {
+		s = &keyState{reserved: map[reconcile.Request]struct{}{}}
+		if cfg.newRateLimiter != nil {
+			s.limiter = cfg.newRateLimiter()
+		}
+		l.states[key] = s
+	}
+
+	if cfg.maxConcurrent > 0 && s.active >= cfg.maxConcurrent {
+		cfg.throttledTotal.WithLabelValues(c.Name, key, limitConcurrency).Inc()
+		for _, deferred := range s.deferred {
+			if deferred == req {
+				return false
+			}
+		}
+		s.deferred = append(s.deferred, req)
+		return false
+	}
+
+	if _, reserved := s.reserved[req]; reserved {
+		delete(s.reserved, req)
+	} else if s.limiter != nil {
+		if delay := s.limiter.When(req); delay > 0 {
+			cfg.throttledTotal.WithLabelValues(c.Name, key, limitRate).Inc()
+			s.reserved[req] = struct{}{}
+			c.Queue.AddAfter(req, delay)
+			return false
+		}
+	}
+
+	s.active++
+	cfg.activeWorkers.WithLabelValues(c.Name, key).Inc()
+	cfg.reconcileTotal.WithLabelValues(c.Name, key).Inc()
+	return true
+}
+
+// release marks a reconcile of the given key as finished and adds all requests that
+// were deferred by the concurrency limit of the key back to the queue.
+func (c *Controller) release(l *keyedLimits, cfg keyLimitConfig, key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.states[key]
+	s.active--
+	cfg.activeWorkers.WithLabelValues(c.Name, key).Dec()
+	for _, req := range s.deferred {
+		c.Queue.Add(req)
+	}
+	s.deferred = nil
+
+	// The state of rate limited keys is kept, the rate limiter has to remember the
+	// reconciles of the key.
+	if s.active == 0 && s.limiter == nil {
+		delete(l.states, key)
+	}
+}
@@ -46,6 +46,13 @@ var (
 		Help: "Total number of terminal reconciliation errors per controller",
 	}, []string{"controller"})
 
+	// ReconcilePanics is a prometheus counter metrics which holds the total
+	// number of panics from the Reconciler.
+	ReconcilePanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_panics_total",
+		Help: "Total number of reconciliation panics per controller",
+	}, []string{"controller"})
+
 	// ReconcileTime is a prometheus metric which keeps track of the duration
 	// of reconciliations.
 	ReconcileTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -55,6 +62,14 @@ var (
 			1.25, 1.5, 1.75, 2.0, 2.5, 3.0, 3.5, 4.0, 4.5, 5, 6, 7, 8, 9, 10, 15, 20, 25, 30, 40, 50, 60},
 	}, []string{"controller"})
 
+	// LeaderReconcileTotal is a prometheus counter metrics which holds the total
+	// number of reconciliations per controller and whether the replica was the
+	// leader when the reconciliation finished, see metrics.LeaderLabelValue.
+	LeaderReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_leader_reconcile_total",
+		Help: "Total number of reconciliations per controller and leadership state of the replica",
+	}, []string{"controller", metrics.LeaderLabel})
+
 	// WorkerCount is a prometheus metric which holds the number of
 	// concurrent reconciles per controller.
 	WorkerCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -68,6 +83,155 @@ var (
 		Name: "controller_runtime_active_workers",
 		Help: "Number of currently used workers per controller",
 	}, []string{"controller"})
+
+	// Restarts is a prometheus counter metrics which holds the total number
+	// of restarts of a restartable controller whose sources failed to start.
+	Restarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_controller_restarts_total",
+		Help: "Total number of restarts per controller",
+	}, []string{"controller"})
+
+	// Restarting is a prometheus metric which is 1 while a restartable
+	// controller waits to be restarted after its sources failed to start.
+	Restarting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_controller_restarting",
+		Help: "Whether the controller is waiting to be restarted per controller",
+	}, []string{"controller"})
+
+	// TenantReconcileTotal is a prometheus counter metrics which holds the total
+	// number of reconciliations per controller and tenant.
+	TenantReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_tenant_reconcile_total",
+		Help: "Total number of reconciliations per controller and tenant",
+	}, []string{"controller", "tenant"})
+
+	// TenantActiveWorkers is a prometheus metric which holds the number
+	// of active workers per controller and tenant.
+	TenantActiveWorkers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_tenant_active_workers",
+		Help: "Number of currently used workers per controller and tenant",
+	}, []string{"controller", "tenant"})
+
+	// TenantThrottledTotal is a prometheus counter metrics which holds the total
+	// number of reconciliations deferred because a limit of the tenant was reached,
+	// per controller, tenant and limit ("concurrency" or "rate").
+	TenantThrottledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_tenant_throttled_total",
+		Help: "Total number of reconciliations deferred by tenant limits per controller, tenant and limit",
+	}, []string{"controller", "tenant", "limit"})
+
+	// ClusterReconcileTotal is a prometheus counter metrics which holds the total
+	// number of reconciliations per controller and cluster, for controllers with
+	// per-cluster limits.
+	ClusterReconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_reconcile_total",
+		Help: "Total number of reconciliations per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterActiveWorkers is a prometheus metric which holds the number of
+	// active workers per controller and cluster, for controllers with
+	// per-cluster limits.
+	ClusterActiveWorkers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cluster_active_workers",
+		Help: "Number of currently used workers per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterThrottledTotal is a prometheus counter metrics which holds the total
+	// number of reconciliations deferred because a limit of the cluster was reached,
+	// per controller, cluster and limit ("concurrency" or "rate").
+	ClusterThrottledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_throttled_total",
+		Help: "Total number of reconciliations deferred by cluster limits per controller, cluster and limit",
+	}, []string{"controller", "cluster", "limit"})
+
+	// ClusterReconcileErrors is a prometheus counter metrics which holds the total
+	// number of errors from the Reconciler per controller and cluster, for
+	// controllers with per-cluster metrics.
+	ClusterReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_reconcile_errors_total",
+		Help: "Total number of reconciliation errors per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterReconcileTime is a prometheus metric which keeps track of the duration
+	// of reconciliations per controller and cluster, for controllers with
+	// per-cluster metrics.
+	ClusterReconcileTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "controller_runtime_cluster_reconcile_time_seconds",
+		Help:    "Length of time per reconciliation per controller and cluster",
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5, 10, 30, 60},
+	}, []string{"controller", "cluster"})
+
+	// ClusterWorkqueueAdds is a prometheus counter metrics which holds the total
+	// number of requests added to the queue of a controller per cluster, for
+	// controllers with per-cluster metrics.
+	ClusterWorkqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_workqueue_adds_total",
+		Help: "Total number of requests added to the queue per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterWorkqueueDepth is a prometheus metric which holds the number of
+	// requests waiting in the queue of a controller per cluster, for controllers
+	// with per-cluster metrics.
+	ClusterWorkqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cluster_workqueue_depth",
+		Help: "Current number of requests waiting in the queue per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterWorkqueueWait is a prometheus metric which keeps track of how long
+	// requests waited in the queue of a controller, including delays, per cluster,
+	// for controllers with per-cluster metrics.
+	ClusterWorkqueueWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "controller_runtime_cluster_workqueue_wait_seconds",
+		Help:    "How long in seconds requests waited in the queue before being reconciled per controller and cluster",
+		Buckets: prometheus.ExponentialBuckets(10e-9, 10, 12),
+	}, []string{"controller", "cluster"})
+
+	// ClusterWatchesPending is a prometheus metric which holds the number of
+	// cluster watch sources of a controller that failed to start or sync and
+	// are retried, per cluster.
+	ClusterWatchesPending = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cluster_watches_pending",
+		Help: "Current number of cluster watch sources that are retried per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// ClusterWatchFailures is a prometheus counter metrics which holds the total
+	// number of failed attempts to start or sync the cluster watch sources of a
+	// controller per cluster.
+	ClusterWatchFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_watch_failures_total",
+		Help: "Total number of failed attempts to start cluster watch sources per controller and cluster",
+	}, []string{"controller", "cluster"})
+
+	// WatchEventsTotal is a prometheus counter metrics which holds the total
+	// number of events received by the watches of a controller, before any
+	// predicates are applied.
+	WatchEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_watch_events_total",
+		Help: "Total number of events received per controller, source GVK and event type",
+	}, []string{"controller", "gvk", "event"})
+
+	// WatchEventsFilteredTotal is a prometheus counter metrics which holds the
+	// total number of events rejected by a predicate of a controller watch.
+	WatchEventsFilteredTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_watch_events_filtered_total",
+		Help: "Total number of events filtered out by predicates per controller, source GVK, event type and predicate",
+	}, []string{"controller", "gvk", "event", "predicate"})
+
+	// WatchRequestsTotal is a prometheus counter metrics which holds the total
+	// number of reconcile requests the event handlers of a controller mapped
+	// events to.
+	WatchRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_watch_requests_total",
+		Help: "Total number of requests enqueued by event handlers per controller, source GVK and event type",
+	}, []string{"controller", "gvk", "event"})
+
+	// WatchEventsDroppedTotal is a prometheus counter metrics which holds the
+	// total number of events that passed all predicates, but were not mapped
+	// to any reconcile request by the event handler.
+	WatchEventsDroppedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_watch_events_dropped_total",
+		Help: "Total number of events that did not result in any request per controller, source GVK and event type",
+	}, []string{"controller", "gvk", "event"})
 )
 
 func init() {
@@ -75,12 +239,53 @@ func init() {
 		ReconcileTotal,
 		ReconcileErrors,
 		TerminalReconcileErrors,
+		ReconcilePanics,
 		ReconcileTime,
+		LeaderReconcileTotal,
 		WorkerCount,
 		ActiveWorkers,
+		Restarts,
+		Restarting,
+		TenantReconcileTotal,
+		TenantActiveWorkers,
+		TenantThrottledTotal,
+		ClusterReconcileTotal,
+		ClusterActiveWorkers,
+		ClusterThrottledTotal,
+		ClusterReconcileErrors,
+		ClusterReconcileTime,
+		ClusterWorkqueueAdds,
+		ClusterWorkqueueDepth,
+		ClusterWorkqueueWait,
+		ClusterWatchesPending,
+		ClusterWatchFailures,
+		WatchEventsTotal,
+		WatchEventsFilteredTotal,
+		WatchRequestsTotal,
+		WatchEventsDroppedTotal,
 		// expose process metrics like CPU, Memory, file descriptor usage etc.
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 		// expose Go runtime metrics like GC stats, memory stats etc.
 		collectors.NewGoCollector(),
 	)
 }
+
+// DeleteClusterMetrics deletes the series of the per-cluster metrics of the given
+// controller and cluster, once the cluster was disengaged.
+func DeleteClusterMetrics(controller, cluster string) {
+	labels := prometheus.Labels{"controller": controller, "cluster": cluster}
+	for _, vec := range []*prometheus.MetricVec{
+		ClusterReconcileTotal.MetricVec,
+		ClusterActiveWorkers.MetricVec,
+		ClusterThrottledTotal.MetricVec,
+		ClusterReconcileErrors.MetricVec,
+		ClusterReconcileTime.MetricVec,
+		ClusterWorkqueueAdds.MetricVec,
+		ClusterWorkqueueDepth.MetricVec,
+		ClusterWorkqueueWait.MetricVec,
+		ClusterWatchesPending.MetricVec,
+		ClusterWatchFailures.MetricVec,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+}
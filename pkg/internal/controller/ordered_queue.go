@@ -0,0 +1,262 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// NewOrderedQueue returns a workqueue.Interface that hands out its items ordered by their
+// string representation instead of the order they were added in, so that items queued
+// together are processed in a reproducible order. Like the default queue, an item is
+// never processed concurrently and is only queued once.
+//
+// If name is set, the queue reports the workqueue metrics through the given provider
+// like the default queue does.
+func NewOrderedQueue(name string, provider workqueue.MetricsProvider) workqueue.Interface {
+	q := &orderedQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		dirty:      map[interface{}]struct{}{},
+		processing: map[interface{}]struct{}{},
+	}
+	if name != "" && provider != nil {
+		q.metrics = newOrderedQueueMetrics(name, provider)
+		go q.updateUnfinishedWorkLoop()
+	}
+	return q
+}
+
+// orderedQueueMetrics are the workqueue metrics of an orderedQueue. They are guarded
+// by the lock of the queue.
+type orderedQueueMetrics struct {
+	depth                   workqueue.GaugeMetric
+	adds                    workqueue.CounterMetric
+	latency                 workqueue.HistogramMetric
+	workDuration            workqueue.HistogramMetric
+	unfinishedWorkSeconds   workqueue.SettableGaugeMetric
+	longestRunningProcessor workqueue.SettableGaugeMetric
+
+	addTimes             map[interface{}]time.Time
+	processingStartTimes map[interface{}]time.Time
+}
+
+func newOrderedQueueMetrics(name string, provider workqueue.MetricsProvider) *orderedQueueMetrics {
+	return &orderedQueueMetrics{
+		depth:                   provider.NewDepthMetric(name),
+		adds:                    provider.NewAddsMetric(name),
+		latency:                 provider.NewLatencyMetric(name),
+		workDuration:            provider.NewWorkDurationMetric(name),
+		unfinishedWorkSeconds:   provider.NewUnfinishedWorkSecondsMetric(name),
+		longestRunningProcessor: provider.NewLongestRunningProcessorSecondsMetric(name),
+		addTimes:                map[interface{}]time.Time{},
+		processingStartTimes:    map[interface{}]time.Time{},
+	}
+}
+
+func (m *orderedQueueMetrics) add(item interface{}) {
+	if m == nil {
+		return
+	}
+	m.adds.Inc()
+	m.depth.Inc()
+	if _, exists := m.addTimes[item]; !exists {
+		m.addTimes[item] = time.Now()
+	}
+}
+
+func (m *orderedQueueMetrics) get(item interface{}) {
+	if m == nil {
+		return
+	}
+	m.depth.Dec()
+	m.processingStartTimes[item] = time.Now()
+	if start, exists := m.addTimes[item]; exists {
+		m.latency.Observe(time.Since(start).Seconds())
+		delete(m.addTimes, item)
+	}
+}
+
+func (m *orderedQueueMetrics) done(item interface{}) {
+	if m == nil {
+		return
+	}
+	if start, exists := m.processingStartTimes[item]; exists {
+		m.workDuration.Observe(time.Since(start).Seconds())
+		delete(m.processingStartTimes, item)
+	}
+}
+
+func (m *orderedQueueMetrics) updateUnfinishedWork() {
+	var total, oldest float64
+	for _, start := range m.processingStartTimes {
+		age := time.Since(start).Seconds()
+		total += age
+		oldest = max(oldest, age)
+	}
+	m.unfinishedWorkSeconds.Set(total)
+	m.longestRunningProcessor.Set(oldest)
+}
+
+type orderedQueue struct {
+	cond *sync.Cond
+
+	// queue are the items waiting to be processed, sorted by their key.
+	queue []interface{}
+	// dirty are the items that need to be processed.
+	dirty map[interface{}]struct{}
+	// processing are the items currently being processed.
+	processing map[interface{}]struct{}
+
+	shuttingDown bool
+	drain        bool
+
+	// metrics is nil if the queue doesn't report metrics.
+	metrics *orderedQueueMetrics
+}
+
+var _ workqueue.Interface = &orderedQueue{}
+
+func orderKey(item interface{}) string {
+	return fmt.Sprint(item)
+}
+
+// insert adds the item to the queue at the position of its key.
+func (q *orderedQueue) insert(item interface{}) {
+	key := orderKey(item)
+	i := sort.Search(len(q.queue), func(i int) bool {
+		return orderKey(q.queue[i]) > key
+	})
+	q.queue = append(q.queue, nil)
+	copy(q.queue[i+1:], q.queue[i:])
+	q.queue[i] = item
+}
+
+// Add implements workqueue.Interface.
+func (q *orderedQueue) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+	q.metrics.add(item)
+	q.dirty[item] = struct{}{}
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+	q.insert(item)
+	q.cond.Signal()
+}
+
+// Len implements workqueue.Interface.
+func (q *orderedQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.queue)
+}
+
+// Get implements workqueue.Interface.
+func (q *orderedQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.queue) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.queue) == 0 {
+		return nil, true
+	}
+
+	item := q.queue[0]
+	q.queue[0] = nil
+	q.queue = q.queue[1:]
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+	q.metrics.get(item)
+	return item, false
+}
+
+// Done implements workqueue.Interface.
+func (q *orderedQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.metrics.done(item)
+	delete(q.processing, item)
+	if _, ok := q.dirty[item]; ok {
+		q.insert(item)
+		q.cond.Signal()
+	} else if len(q.processing) == 0 {
+		// Wake up ShutDownWithDrain.
+		q.cond.Broadcast()
+	}
+}
+
+// ShutDown implements workqueue.Interface.
+func (q *orderedQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.drain = false
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain implements workqueue.Interface.
+func (q *orderedQueue) ShutDownWithDrain() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.drain = true
+	q.shuttingDown = true
+	q.cond.Broadcast()
+
+	for len(q.processing) != 0 && q.drain {
+		q.cond.Wait()
+	}
+}
+
+// ShuttingDown implements workqueue.Interface.
+func (q *orderedQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+// updateUnfinishedWorkLoop periodically updates the metrics of the items being
+// processed until the queue shuts down.
+func (q *orderedQueue) updateUnfinishedWorkLoop() {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.cond.L.Lock()
+		if q.shuttingDown {
+			q.cond.L.Unlock()
+			return
+		}
+		q.metrics.updateUnfinishedWork()
+		q.cond.L.Unlock()
+	}
+}
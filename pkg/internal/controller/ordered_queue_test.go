@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("OrderedQueue", func() {
+	request := func(namespace, name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+	}
+
+	It("should hand out items ordered by namespace and name", func() {
+		q := NewOrderedQueue("", nil)
+		defer q.ShutDown()
+
+		q.Add(request("b", "a"))
+		q.Add(request("a", "c"))
+		q.Add(request("a", "b"))
+		q.Add(request("a", "c"))
+		Expect(q.Len()).To(Equal(3))
+
+		var got []reconcile.Request
+		for q.Len() > 0 {
+			item, shutdown := q.Get()
+			Expect(shutdown).To(BeFalse())
+			got = append(got, item.(reconcile.Request))
+			q.Done(item)
+		}
+		Expect(got).To(Equal([]reconcile.Request{request("a", "b"), request("a", "c"), request("b", "a")}))
+	})
+
+	It("should requeue items added while they were processed once they are done", func() {
+		q := NewOrderedQueue("", nil)
+		defer q.ShutDown()
+
+		q.Add(request("a", "a"))
+		item, _ := q.Get()
+		q.Add(request("a", "a"))
+		Expect(q.Len()).To(Equal(0))
+
+		q.Done(item)
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("should stop handing out items when shut down", func() {
+		q := NewOrderedQueue("", nil)
+		q.Add(request("a", "a"))
+		q.ShutDown()
+
+		Expect(q.ShuttingDown()).To(BeTrue())
+		q.Add(request("a", "b"))
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("should report the workqueue metrics", func() {
+		provider := &fakeQueueMetricsProvider{}
+		q := NewOrderedQueue("ordered", provider)
+		defer q.ShutDown()
+
+		q.Add(request("a", "b"))
+		q.Add(request("a", "a"))
+		q.Add(request("a", "a"))
+		Expect(provider.depth.value()).To(Equal(2.0))
+		Expect(provider.adds.value()).To(Equal(2.0))
+
+		item, _ := q.Get()
+		Expect(provider.depth.value()).To(Equal(1.0))
+		Expect(provider.latency.value()).To(Equal(1.0))
+
+		// The item is queued again once it is done.
+		q.Add(item)
+		Expect(provider.depth.value()).To(Equal(2.0))
+		q.Done(item)
+		Expect(provider.workDuration.value()).To(Equal(1.0))
+		Expect(q.Len()).To(Equal(2))
+	})
+})
+
+// fakeQueueMetric counts the observations of a metric, or holds the value of a gauge.
+type fakeQueueMetric struct {
+	mu  sync.Mutex
+	val float64
+}
+
+func (m *fakeQueueMetric) Inc() {
+	m.Add(1)
+}
+
+func (m *fakeQueueMetric) Dec() {
+	m.Add(-1)
+}
+
+func (m *fakeQueueMetric) Observe(float64) {
+	m.Add(1)
+}
+
+func (m *fakeQueueMetric) Add(delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.val += delta
+}
+
+func (m *fakeQueueMetric) Set(val float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.val = val
+}
+
+func (m *fakeQueueMetric) value() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.val
+}
+
+type fakeQueueMetricsProvider struct {
+	depth, adds, latency, workDuration, unfinished, longest, retries fakeQueueMetric
+}
+
+func (p *fakeQueueMetricsProvider) NewDepthMetric(string) workqueue.GaugeMetric {
+	return &p.depth
+}
+
+func (p *fakeQueueMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric {
+	return &p.adds
+}
+
+func (p *fakeQueueMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric {
+	return &p.latency
+}
+
+func (p *fakeQueueMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return &p.workDuration
+}
+
+func (p *fakeQueueMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &p.unfinished
+}
+
+func (p *fakeQueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return &p.longest
+}
+
+func (p *fakeQueueMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric {
+	return &p.retries
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// QueueSnapshot is a point-in-time view of the queue of a controller.
+type QueueSnapshot struct {
+	// Controller is the name of the controller owning the queue.
+	Controller string `json:"controller"`
+
+	// Items are the requests currently in the queue, including the ones that are
+	// waiting for a delay to expire and the ones being reconciled, oldest first.
+	Items []QueueItem `json:"items"`
+}
+
+// QueueItem describes a single request in the queue of a controller.
+type QueueItem struct {
+	// Request is the queued request.
+	Request reconcile.Request `json:"request"`
+
+	// Retries is the number of times the request was requeued with rate limiting
+	// since it last succeeded.
+	Retries int `json:"retries"`
+
+	// Age is the time since the request was added to the queue.
+	Age time.Duration `json:"age"`
+
+	// Processing is true if the request is currently being reconciled.
+	Processing bool `json:"processing"`
+}
+
+// QueueSnapshot returns a snapshot of the queue of the controller. The snapshot is
+// empty if the controller has not been started yet.
+func (c *Controller) QueueSnapshot() QueueSnapshot {
+	snapshot := QueueSnapshot{Controller: c.Name, Items: []QueueItem{}}
+
+	c.mu.Lock()
+	q, ok := c.Queue.(*trackingQueue)
+	c.mu.Unlock()
+	if !ok {
+		return snapshot
+	}
+
+	snapshot.Items = q.snapshot()
+	return snapshot
+}
+
+// trackingQueue records when items were added to the wrapped queue and whether
+// they are being processed, so that the queue contents can be inspected.
+type trackingQueue struct {
+	workqueue.RateLimitingInterface
+
+	// clusterMetricsController, if set, is the name of the controller the per-cluster
+	// queue metrics of the requests of provider clusters are recorded for.
+	clusterMetricsController string
+
+	// onClusterDrained, if set, is called once the last tracked request of a provider
+	// cluster is done.
+	onClusterDrained func(cluster string)
+
+	mu    sync.Mutex
+	items map[interface{}]*trackedItem
+	// clusterItems is the number of tracked requests per provider cluster.
+	clusterItems map[string]int
+}
+
+type trackedItem struct {
+	added      time.Time
+	processing bool
+	// readded is set if the item was added again while it was processing.
+	readded bool
+}
+
+func newTrackingQueue(q workqueue.RateLimitingInterface) *trackingQueue {
+	return &trackingQueue{
+		RateLimitingInterface: q,
+		items:                 map[interface{}]*trackedItem{},
+		clusterItems:          map[string]int{},
+	}
+}
+
+// newItem starts tracking the given item. q.mu must be held.
+func (q *trackingQueue) newItem(item interface{}) *trackedItem {
+	t := &trackedItem{added: time.Now()}
+	q.items[item] = t
+	if req, ok := item.(reconcile.Request); ok && req.ClusterName != "" {
+		q.clusterItems[req.ClusterName]++
+	}
+	return t
+}
+
+// hasClusterItems returns whether requests of the given provider cluster are tracked,
+// including the ones waiting for a delay and the ones being reconciled.
+func (q *trackingQueue) hasClusterItems(cluster string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.clusterItems[cluster] > 0
+}
+
+func (q *trackingQueue) track(item interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cluster, recordCluster := q.clusterOf(item)
+	if recordCluster {
+		ctrlmetrics.ClusterWorkqueueAdds.WithLabelValues(q.clusterMetricsController, cluster).Inc()
+	}
+
+	t, ok := q.items[item]
+	switch {
+	case !ok:
+		q.newItem(item)
+	case t.processing && !t.readded:
+		t.readded = true
+		t.added = time.Now()
+	default:
+		return
+	}
+	if recordCluster {
+		ctrlmetrics.ClusterWorkqueueDepth.WithLabelValues(q.clusterMetricsController, cluster).Inc()
+	}
+}
+
+// clusterOf returns the provider cluster of the given item and whether per-cluster
+// metrics are recorded for it.
+func (q *trackingQueue) clusterOf(item interface{}) (string, bool) {
+	if q.clusterMetricsController == "" {
+		return "", false
+	}
+	req, ok := item.(reconcile.Request)
+	if !ok || req.ClusterName == "" {
+		return "", false
+	}
+	return req.ClusterName, true
+}
+
+// Add implements workqueue.Interface.
+func (q *trackingQueue) Add(item interface{}) {
+	q.track(item)
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *trackingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.track(item)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *trackingQueue) AddRateLimited(item interface{}) {
+	q.track(item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+// Get implements workqueue.Interface.
+func (q *trackingQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if shutdown {
+		return item, shutdown
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t, ok := q.items[item]
+	if !ok {
+		// The item was added to the underlying queue directly.
+		t = q.newItem(item)
+	} else if cluster, recordCluster := q.clusterOf(item); recordCluster {
+		ctrlmetrics.ClusterWorkqueueDepth.WithLabelValues(q.clusterMetricsController, cluster).Dec()
+		ctrlmetrics.ClusterWorkqueueWait.WithLabelValues(q.clusterMetricsController, cluster).Observe(time.Since(t.added).Seconds())
+	}
+	t.processing = true
+	t.readded = false
+	return item, shutdown
+}
+
+// Done implements workqueue.Interface.
+func (q *trackingQueue) Done(item interface{}) {
+	drained := ""
+	q.mu.Lock()
+	if t, ok := q.items[item]; ok {
+		if t.readded {
+			t.processing, t.readded = false, false
+		} else {
+			delete(q.items, item)
+			if req, ok := item.(reconcile.Request); ok && req.ClusterName != "" {
+				q.clusterItems[req.ClusterName]--
+				if q.clusterItems[req.ClusterName] <= 0 {
+					delete(q.clusterItems, req.ClusterName)
+					drained = req.ClusterName
+				}
+			}
+		}
+	}
+	q.mu.Unlock()
+
+	q.RateLimitingInterface.Done(item)
+	if drained != "" && q.onClusterDrained != nil {
+		q.onClusterDrained(drained)
+	}
+}
+
+func (q *trackingQueue) snapshot() []QueueItem {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	items := make([]QueueItem, 0, len(q.items))
+	for item, t := range q.items {
+		req, ok := item.(reconcile.Request)
+		if !ok {
+			continue
+		}
+		items = append(items, QueueItem{
+			Request:    req,
+			Retries:    q.RateLimitingInterface.NumRequeues(item),
+			Age:        now.Sub(t.added),
+			Processing: t.processing,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Age > items[j].Age
+	})
+	return items
+}
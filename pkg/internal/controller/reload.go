@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+)
+
+// Reload re-applies the runtime configuration: the concurrency of the GroupKind of the
+// controller, if listed, and the overall rate limit.
+func (c *Controller) Reload(_ context.Context, cfg config.Runtime) error {
+	if concurrency, ok := cfg.GroupKindConcurrency[c.GroupKind]; ok && c.GroupKind != "" && concurrency > 0 {
+		c.LogConstructor(nil).Info("Changing worker count", "worker count", concurrency)
+		c.workers.resize(concurrency)
+	}
+	if c.ReloadableRateLimiter != nil {
+		c.ReloadableRateLimiter.SetRateLimit(cfg.RateLimit)
+	}
+	return nil
+}
+
+// workerPool runs the workers of a controller. The number of workers can be changed
+// while they are running.
+type workerPool struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	ctx     context.Context
+	process func(ctx context.Context) bool
+	target  int
+	running int
+}
+
+// start starts the given number of workers, unless a number was set by resize before.
+func (p *workerPool) start(ctx context.Context, workers int, process func(ctx context.Context) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ctx = ctx
+	p.process = process
+	if p.target == 0 {
+		p.target = workers
+	}
+	p.spawnLocked()
+}
+
+// resize changes the number of workers. Surplus workers stop after their current item.
+func (p *workerPool) resize(workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.target = workers
+	if p.ctx != nil {
+		p.spawnLocked()
+	}
+}
+
+// size returns the number of workers, or zero if it wasn't set yet.
+func (p *workerPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.target
+}
+
+// wait waits for all workers to stop.
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
+
+func (p *workerPool) spawnLocked() {
+	for ; p.running < p.target; p.running++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+}
+
+func (p *workerPool) work() {
+	defer p.wg.Done()
+	// Run a worker thread that just dequeues items, processes them, and marks them done.
+	// It enforces that the reconcileHandler is never invoked concurrently with the same object.
+	for p.process(p.ctx) {
+		if p.retire() {
+			return
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+}
+
+// retire stops the calling worker if there are more workers than wanted.
+func (p *workerPool) retire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running <= p.target {
+		return false
+	}
+	p.running--
+	return true
+}
+
+// ReloadableRateLimiter adds an overall rate limit, which can be changed at runtime,
+// to a rate limiter. Like the rate limiter, it only delays the items added to the
+// queue with AddRateLimited.
+type ReloadableRateLimiter struct {
+	ratelimiter.RateLimiter
+	limiter atomic.Pointer[rate.Limiter]
+}
+
+// NewReloadableRateLimiter returns a ReloadableRateLimiter without overall rate limit.
+func NewReloadableRateLimiter(rateLimiter ratelimiter.RateLimiter) *ReloadableRateLimiter {
+	return &ReloadableRateLimiter{RateLimiter: rateLimiter}
+}
+
+// When returns the longer of the delays of the rate limiter and the overall rate limit.
+func (r *ReloadableRateLimiter) When(item interface{}) time.Duration {
+	delay := r.RateLimiter.When(item)
+	if limiter := r.limiter.Load(); limiter != nil {
+		if overall := limiter.Reserve().Delay(); overall > delay {
+			delay = overall
+		}
+	}
+	return delay
+}
+
+// SetRateLimit sets the overall rate limit, or removes it if limit is nil.
+func (r *ReloadableRateLimiter) SetRateLimit(limit *config.RateLimit) {
+	if limit == nil {
+		r.limiter.Store(nil)
+		return
+	}
+	r.limiter.Store(rate.NewLimiter(rate.Limit(limit.QPS), limit.Burst))
+}
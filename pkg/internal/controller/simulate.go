@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	internalsource "sigs.k8s.io/controller-runtime/pkg/internal/source"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// SimulatedEventType is the type of a SimulatedEvent.
+type SimulatedEventType string
+
+const (
+	// SimulatedCreate is a create event of SimulatedEvent.Object.
+	SimulatedCreate SimulatedEventType = "Create"
+	// SimulatedUpdate is an update event from SimulatedEvent.ObjectOld to SimulatedEvent.Object.
+	SimulatedUpdate SimulatedEventType = "Update"
+	// SimulatedDelete is a delete event of SimulatedEvent.Object.
+	SimulatedDelete SimulatedEventType = "Delete"
+	// SimulatedGeneric is a generic event of SimulatedEvent.Object.
+	SimulatedGeneric SimulatedEventType = "Generic"
+)
+
+// SimulatedEvent is an event run through the watches of a controller by Simulate.
+type SimulatedEvent struct {
+	// Type is the type of the event.
+	Type SimulatedEventType `json:"type"`
+
+	// Object is the object of the event, the new object for update events.
+	Object client.Object `json:"object"`
+
+	// ObjectOld is the old object of update events.
+	ObjectOld client.Object `json:"objectOld,omitempty"`
+}
+
+// SimulationResult reports how the watches of a controller handled a SimulatedEvent.
+type SimulationResult struct {
+	// Requests are the requests that would be enqueued, sorted by cluster, namespace and name.
+	Requests []reconcile.Request `json:"requests"`
+
+	// Watches are the results of the watches of the objects of the event's kind, in the
+	// order the watches were added.
+	Watches []WatchSimulationResult `json:"watches"`
+}
+
+// WatchSimulationResult reports how a single watch handled a SimulatedEvent.
+type WatchSimulationResult struct {
+	// Source describes the source of the watch.
+	Source string `json:"source"`
+
+	// FilteredBy is the type of the first predicate that filtered the event out, if any.
+	FilteredBy string `json:"filteredBy,omitempty"`
+
+	// Requests are the requests the event handler of the watch would enqueue.
+	Requests []reconcile.Request `json:"requests"`
+}
+
+// Simulate runs the given event through the predicates and the event handlers of the
+// watches of the controller whose Kind sources watch the kind of the event's object, and
+// reports the requests that would be enqueued. Nothing is enqueued or reconciled. The
+// watches are only known until the controller is started, so Simulate fails afterwards.
+func (c *Controller) Simulate(ctx context.Context, evt SimulatedEvent) (SimulationResult, error) {
+	result := SimulationResult{Requests: []reconcile.Request{}, Watches: []WatchSimulationResult{}}
+	if evt.Object == nil || (evt.Type == SimulatedUpdate && evt.ObjectOld == nil) {
+		return result, errors.New("simulated event is missing its object")
+	}
+
+	c.mu.Lock()
+	if c.sourcesStarted {
+		c.mu.Unlock()
+		return result, errors.New("unable to simulate events on a controller that was already started")
+	}
+	watches := append([]watchDescription(nil), c.startWatches...)
+	c.mu.Unlock()
+
+	all := map[reconcile.Request]struct{}{}
+	for _, watch := range watches {
+		kind, ok := watch.src.(*internalsource.Kind)
+		if !ok || kind.Type == nil {
+			continue
+		}
+		matches, err := c.sameKind(kind.Type, evt.Object)
+		if err != nil {
+			return result, err
+		}
+		if !matches {
+			continue
+		}
+
+		watchResult := WatchSimulationResult{Source: kind.String(), Requests: []reconcile.Request{}}
+		if p := filteredBy(evt, watch.predicates); p != nil {
+			watchResult.FilteredBy = fmt.Sprintf("%T", p)
+		} else {
+			q := newSimulationQueue()
+			switch evt.Type {
+			case SimulatedCreate:
+				watch.handler.Create(ctx, event.CreateEvent{Object: evt.Object}, q)
+			case SimulatedUpdate:
+				watch.handler.Update(ctx, event.UpdateEvent{ObjectOld: evt.ObjectOld, ObjectNew: evt.Object}, q)
+			case SimulatedDelete:
+				watch.handler.Delete(ctx, event.DeleteEvent{Object: evt.Object}, q)
+			case SimulatedGeneric:
+				watch.handler.Generic(ctx, event.GenericEvent{Object: evt.Object}, q)
+			}
+			q.ShutDown()
+			for req := range q.requests {
+				watchResult.Requests = append(watchResult.Requests, req)
+				all[req] = struct{}{}
+			}
+			sortRequests(watchResult.Requests)
+		}
+		result.Watches = append(result.Watches, watchResult)
+	}
+
+	for req := range all {
+		result.Requests = append(result.Requests, req)
+	}
+	sortRequests(result.Requests)
+	return result, nil
+}
+
+// sameKind returns whether a and b are objects of the same kind.
+func (c *Controller) sameKind(a, b client.Object) (bool, error) {
+	if c.Scheme == nil {
+		return reflect.TypeOf(a) == reflect.TypeOf(b), nil
+	}
+	aGVK, err := apiutil.GVKForObject(a, c.Scheme)
+	if err != nil {
+		return false, err
+	}
+	bGVK, err := apiutil.GVKForObject(b, c.Scheme)
+	if err != nil {
+		return false, err
+	}
+	return aGVK.GroupKind() == bGVK.GroupKind(), nil
+}
+
+// filteredBy returns the first of the given predicates that filters the event out.
+func filteredBy(evt SimulatedEvent, predicates []predicate.Predicate) predicate.Predicate {
+	for _, p := range predicates {
+		var ok bool
+		switch evt.Type {
+		case SimulatedCreate:
+			ok = p.Create(event.CreateEvent{Object: evt.Object})
+		case SimulatedUpdate:
+			ok = p.Update(event.UpdateEvent{ObjectOld: evt.ObjectOld, ObjectNew: evt.Object})
+		case SimulatedDelete:
+			ok = p.Delete(event.DeleteEvent{Object: evt.Object})
+		default:
+			ok = p.Generic(event.GenericEvent{Object: evt.Object})
+		}
+		if !ok {
+			return p
+		}
+	}
+	return nil
+}
+
+func sortRequests(reqs []reconcile.Request) {
+	sort.Slice(reqs, func(i, j int) bool {
+		if reqs[i].ClusterName != reqs[j].ClusterName {
+			return reqs[i].ClusterName < reqs[j].ClusterName
+		}
+		return reqs[i].String() < reqs[j].String()
+	})
+}
+
+// simulationQueue records the requests added by the event handlers of a simulation.
+// Requests added with a delay are added right away, so that they are recorded too.
+type simulationQueue struct {
+	workqueue.RateLimitingInterface
+	requests map[reconcile.Request]struct{}
+}
+
+func newSimulationQueue() *simulationQueue {
+	return &simulationQueue{
+		RateLimitingInterface: workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{}),
+		requests:              map[reconcile.Request]struct{}{},
+	}
+}
+
+// Add implements workqueue.Interface.
+func (q *simulationQueue) Add(item interface{}) {
+	if req, ok := item.(reconcile.Request); ok {
+		q.requests[req] = struct{}{}
+	}
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *simulationQueue) AddAfter(item interface{}, _ time.Duration) {
+	q.Add(item)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *simulationQueue) AddRateLimited(item interface{}) {
+	q.Add(item)
+}
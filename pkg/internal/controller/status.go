@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	internalsource "sigs.k8s.io/controller-runtime/pkg/internal/source"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Status is a point-in-time view of the state of a controller.
+type Status struct {
+	// Name is the name of the controller.
+	Name string `json:"name"`
+
+	// WatchedKinds are the kinds of the objects watched through Kind sources.
+	WatchedKinds []metav1.GroupVersionKind `json:"watchedKinds"`
+
+	// Started is true if the workers of the controller were started.
+	Started bool `json:"started"`
+
+	// Synced is true if the caches of the sources of the controller were synced.
+	Synced bool `json:"synced"`
+
+	// QueueDepth is the number of requests waiting in the queue of the controller.
+	QueueDepth int `json:"queueDepth"`
+
+	// LastError is the error of the most recent failed reconcile or failed start of a
+	// restartable controller, if any.
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorTime is the time of the most recent failed reconcile, if any.
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+
+	// Degraded is true while the informer of a watched kind is stopped after a watch
+	// error, see cache.WatchErrorHandler, as the controller doesn't receive its events.
+	Degraded bool `json:"degraded"`
+
+	// DegradedReason describes the stopped informers if the controller is degraded.
+	DegradedReason string `json:"degradedReason,omitempty"`
+
+	// ClusterWatches reports the cluster watches of the engaged provider clusters,
+	// sorted by cluster.
+	ClusterWatches []ClusterWatchStatus `json:"clusterWatches,omitempty"`
+}
+
+// statusTracker records the state reported by Controller.Status. It has its own
+// lock, as the lock of the controller is held while waiting for caches to sync.
+type statusTracker struct {
+	mu            sync.Mutex
+	watchedKinds  []*internalsource.Kind
+	queue         workqueue.RateLimitingInterface
+	started       bool
+	synced        bool
+	lastError     error
+	lastErrorTime time.Time
+}
+
+func (s *statusTracker) addSource(src source.Source) {
+	kind, ok := src.(*internalsource.Kind)
+	if !ok || kind.Type == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchedKinds = append(s.watchedKinds, kind)
+}
+
+func (s *statusTracker) setQueue(queue workqueue.RateLimitingInterface) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = queue
+}
+
+func (s *statusTracker) setSynced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.synced = true
+}
+
+func (s *statusTracker) setStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = true
+}
+
+func (s *statusTracker) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err
+	s.lastErrorTime = time.Now()
+}
+
+// Status returns the current status of the controller.
+func (c *Controller) Status() Status {
+	clusterWatches := c.clusterWatchStatuses()
+	degradedReason := c.degradedReason()
+
+	c.status.mu.Lock()
+	defer c.status.mu.Unlock()
+
+	status := Status{
+		Name:           c.Name,
+		WatchedKinds:   []metav1.GroupVersionKind{},
+		Started:        c.status.started,
+		Synced:         c.status.synced,
+		Degraded:       degradedReason != "",
+		DegradedReason: degradedReason,
+		ClusterWatches: clusterWatches,
+	}
+	seen := map[metav1.GroupVersionKind]bool{}
+	for _, kind := range c.status.watchedKinds {
+		gvk := c.gvkFor(kind.Type)
+		if gvk.Empty() {
+			continue
+		}
+		metaGVK := metav1.GroupVersionKind(gvk)
+		if !seen[metaGVK] {
+			seen[metaGVK] = true
+			status.WatchedKinds = append(status.WatchedKinds, metaGVK)
+		}
+	}
+	if c.status.queue != nil {
+		status.QueueDepth = c.status.queue.Len()
+	}
+	if c.status.lastError != nil {
+		status.LastError = c.status.lastError.Error()
+		status.LastErrorTime = &metav1.Time{Time: c.status.lastErrorTime}
+	}
+	return status
+}
+
+// gvkFor returns the GroupVersionKind of obj, which is empty if it is unknown.
+func (c *Controller) gvkFor(obj client.Object) schema.GroupVersionKind {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if c.Scheme != nil {
+		if schemeGVK, err := apiutil.GVKForObject(obj, c.Scheme); err == nil {
+			gvk = schemeGVK
+		}
+	}
+	return gvk
+}
+
+// degradedReason describes the informers of the watched kinds that were stopped after
+// watch errors, if any.
+func (c *Controller) degradedReason() string {
+	c.status.mu.Lock()
+	kinds := slices.Clone(c.status.watchedKinds)
+	c.status.mu.Unlock()
+
+	var reasons []string
+	seen := map[string]bool{}
+	for _, kind := range kinds {
+		gvk := c.gvkFor(kind.Type)
+		stats, err := cache.GetInformerStats(kind.Cache)
+		if gvk.Empty() || err != nil {
+			continue
+		}
+		for _, s := range stats {
+			if s.StopError == "" || s.Group != gvk.Group || s.Version != gvk.Version || s.Kind != gvk.Kind {
+				continue
+			}
+			reason := fmt.Sprintf("informer of %s stopped after watch error: %s", gvk.Kind, s.StopError)
+			if s.Namespace != "" {
+				reason = fmt.Sprintf("informer of %s in namespace %s stopped after watch error: %s", gvk.Kind, s.Namespace, s.StopError)
+			}
+			if !seen[reason] {
+				seen[reason] = true
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
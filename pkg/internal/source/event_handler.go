@@ -19,6 +19,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -51,12 +54,65 @@ type EventHandler struct {
 	handler    handler.EventHandler
 	queue      workqueue.RateLimitingInterface
 	predicates []predicate.Predicate
+
+	// initialListLess, if set, orders the Create events of the initial list.
+	initialListLess func(a, b client.Object) bool
+
+	// initialListMu guards initialList and initialListFlushed.
+	initialListMu sync.Mutex
+
+	// initialList buffers the objects of the initial list until it is flushed.
+	initialList []interface{}
+
+	// initialListFlushed is true once the initial list was delivered.
+	initialListFlushed bool
+}
+
+// OrderInitialList makes the EventHandler buffer the Create events of the initial list and
+// deliver them ordered by less once FlushInitialList is called or the first event that is not
+// part of the initial list is received. It must be called before the EventHandler is used.
+func (e *EventHandler) OrderInitialList(less func(a, b client.Object) bool) {
+	e.initialListLess = less
+}
+
+// FlushInitialList delivers the buffered Create events of the initial list in order. Later
+// Create events of the initial list are delivered immediately.
+func (e *EventHandler) FlushInitialList() {
+	if e.initialListLess == nil {
+		return
+	}
+	e.initialListMu.Lock()
+	defer e.initialListMu.Unlock()
+	e.flushInitialListLocked()
+}
+
+// flushInitialListLocked delivers the buffered initial list. initialListMu must be held,
+// so that no other event is delivered in between.
+func (e *EventHandler) flushInitialListLocked() {
+	if e.initialListFlushed {
+		return
+	}
+	e.initialListFlushed = true
+
+	objs := e.initialList
+	e.initialList = nil
+	sort.SliceStable(objs, func(i, j int) bool {
+		a, aOK := objs[i].(client.Object)
+		b, bOK := objs[j].(client.Object)
+		// Invalid objects are delivered first, so that their errors get logged.
+		if !aOK || !bOK {
+			return !aOK && bOK
+		}
+		return e.initialListLess(a, b)
+	})
+	for _, obj := range objs {
+		e.onAdd(obj, true)
+	}
 }
 
-// HandlerFuncs converts EventHandler to a ResourceEventHandlerFuncs
-// TODO: switch to ResourceEventHandlerDetailedFuncs with client-go 1.27
-func (e *EventHandler) HandlerFuncs() cache.ResourceEventHandlerFuncs {
-	return cache.ResourceEventHandlerFuncs{
+// HandlerFuncs converts EventHandler to a ResourceEventHandlerDetailedFuncs.
+func (e *EventHandler) HandlerFuncs() cache.ResourceEventHandlerDetailedFuncs {
+	return cache.ResourceEventHandlerDetailedFuncs{
 		AddFunc:    e.OnAdd,
 		UpdateFunc: e.OnUpdate,
 		DeleteFunc: e.OnDelete,
@@ -64,8 +120,22 @@ func (e *EventHandler) HandlerFuncs() cache.ResourceEventHandlerFuncs {
 }
 
 // OnAdd creates CreateEvent and calls Create on EventHandler.
-func (e *EventHandler) OnAdd(obj interface{}) {
-	c := event.CreateEvent{}
+func (e *EventHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	if e.initialListLess != nil {
+		e.initialListMu.Lock()
+		if isInInitialList && !e.initialListFlushed {
+			e.initialList = append(e.initialList, obj)
+			e.initialListMu.Unlock()
+			return
+		}
+		e.flushInitialListLocked()
+		e.initialListMu.Unlock()
+	}
+	e.onAdd(obj, isInInitialList)
+}
+
+func (e *EventHandler) onAdd(obj interface{}, isInInitialList bool) {
+	c := event.CreateEvent{IsInInitialList: isInInitialList}
 
 	// Pull Object out of the object
 	if o, ok := obj.(client.Object); ok {
@@ -85,11 +155,13 @@ func (e *EventHandler) OnAdd(obj interface{}) {
 	// Invoke create handler
 	ctx, cancel := context.WithCancel(e.ctx)
 	defer cancel()
-	e.handler.Create(ctx, c, e.queue)
+	e.handler.Create(ctx, c, e.queueFor(c.Object))
 }
 
 // OnUpdate creates UpdateEvent and calls Update on EventHandler.
 func (e *EventHandler) OnUpdate(oldObj, newObj interface{}) {
+	e.FlushInitialList()
+
 	u := event.UpdateEvent{}
 
 	if o, ok := oldObj.(client.Object); ok {
@@ -118,11 +190,13 @@ func (e *EventHandler) OnUpdate(oldObj, newObj interface{}) {
 	// Invoke update handler
 	ctx, cancel := context.WithCancel(e.ctx)
 	defer cancel()
-	e.handler.Update(ctx, u, e.queue)
+	e.handler.Update(ctx, u, e.queueFor(u.ObjectNew))
 }
 
 // OnDelete creates DeleteEvent and calls Delete on EventHandler.
 func (e *EventHandler) OnDelete(obj interface{}) {
+	e.FlushInitialList()
+
 	d := event.DeleteEvent{}
 
 	// Deal with tombstone events by pulling the object out.  Tombstone events wrap the object in a
@@ -166,5 +240,31 @@ func (e *EventHandler) OnDelete(obj interface{}) {
 	// Invoke delete handler
 	ctx, cancel := context.WithCancel(e.ctx)
 	defer cancel()
-	e.handler.Delete(ctx, d, e.queue)
+	e.handler.Delete(ctx, d, e.queueFor(d.Object))
+}
+
+// queueFor returns the queue for the requests of the admitted event of obj, which
+// delays them by the longest delay of the predicates implementing predicate.Delayer.
+func (e *EventHandler) queueFor(obj client.Object) workqueue.RateLimitingInterface {
+	var delay time.Duration
+	for _, p := range e.predicates {
+		if delayer, ok := p.(predicate.Delayer); ok {
+			delay = max(delay, delayer.DelayFor(obj))
+		}
+	}
+	if delay <= 0 {
+		return e.queue
+	}
+	return &delayingQueue{RateLimitingInterface: e.queue, delay: delay}
+}
+
+// delayingQueue delays the items added to it.
+type delayingQueue struct {
+	workqueue.RateLimitingInterface
+	delay time.Duration
+}
+
+// Add implements workqueue.Interface.
+func (q *delayingQueue) Add(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay)
 }
@@ -18,6 +18,7 @@ package internal_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -95,7 +97,7 @@ var _ = Describe("Internal", func() {
 				defer GinkgoRecover()
 				Expect(evt.Object).To(Equal(pod))
 			}
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 		})
 
 		It("should used Predicates to filter CreateEvents", func() {
@@ -103,14 +105,14 @@ var _ = Describe("Internal", func() {
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return false }},
 			})
 			set = false
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 			Expect(set).To(BeFalse())
 
 			set = false
 			instance = internal.NewEventHandler(ctx, &controllertest.Queue{}, setfuncs, []predicate.Predicate{
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return true }},
 			})
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 			Expect(set).To(BeTrue())
 
 			set = false
@@ -118,7 +120,7 @@ var _ = Describe("Internal", func() {
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return true }},
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return false }},
 			})
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 			Expect(set).To(BeFalse())
 
 			set = false
@@ -126,7 +128,7 @@ var _ = Describe("Internal", func() {
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return false }},
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return true }},
 			})
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 			Expect(set).To(BeFalse())
 
 			set = false
@@ -134,16 +136,16 @@ var _ = Describe("Internal", func() {
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return true }},
 				predicate.Funcs{CreateFunc: func(event.CreateEvent) bool { return true }},
 			})
-			instance.OnAdd(pod)
+			instance.OnAdd(pod, false)
 			Expect(set).To(BeTrue())
 		})
 
 		It("should not call Create EventHandler if the object is not a runtime.Object", func() {
-			instance.OnAdd(&metav1.ObjectMeta{})
+			instance.OnAdd(&metav1.ObjectMeta{}, false)
 		})
 
 		It("should not call Create EventHandler if the object does not have metadata", func() {
-			instance.OnAdd(FooRuntimeObject{})
+			instance.OnAdd(FooRuntimeObject{}, false)
 		})
 
 		It("should create an UpdateEvent", func() {
@@ -280,10 +282,76 @@ var _ = Describe("Internal", func() {
 			instance.OnDelete(tombstone)
 		})
 		It("should ignore objects without meta", func() {
-			instance.OnAdd(Foo{})
+			instance.OnAdd(Foo{}, false)
 			instance.OnUpdate(Foo{}, Foo{})
 			instance.OnDelete(Foo{})
 		})
+
+		It("should delay the requests of events delayed by a predicate", func() {
+			queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer queue.ShutDown()
+			instance = internal.NewEventHandler(ctx, queue, &handler.EnqueueRequestForObject{}, []predicate.Predicate{
+				predicate.Debounce(time.Hour),
+			})
+			pod.Namespace, pod.Name = "default", "debounced"
+			newPod.Namespace, newPod.Name = "default", "debounced"
+
+			instance.OnAdd(pod, false)
+			Expect(queue.Len()).To(Equal(1))
+			item, _ := queue.Get()
+			queue.Done(item)
+
+			instance.OnUpdate(pod, newPod)
+			Consistently(queue.Len).Should(BeZero())
+
+			instance.OnDelete(newPod)
+			Expect(queue.Len()).To(Equal(1))
+		})
+
+		Describe("with an ordered initial list", func() {
+			var created []string
+			var newNamedPod func(name string) *corev1.Pod
+
+			BeforeEach(func() {
+				created = nil
+				newNamedPod = func(name string) *corev1.Pod {
+					return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+				}
+				funcs.CreateFunc = func(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+					Expect(evt.IsInInitialList).To(BeTrue())
+					created = append(created, evt.Object.GetName())
+				}
+				instance.OrderInitialList(func(a, b client.Object) bool { return a.GetName() < b.GetName() })
+			})
+
+			It("should deliver the initial list in order when flushed", func() {
+				instance.OnAdd(newNamedPod("c"), true)
+				instance.OnAdd(newNamedPod("a"), true)
+				instance.OnAdd(newNamedPod("b"), true)
+				Expect(created).To(BeEmpty())
+
+				instance.FlushInitialList()
+				Expect(created).To(Equal([]string{"a", "b", "c"}))
+
+				instance.OnAdd(newNamedPod("0"), true)
+				Expect(created).To(Equal([]string{"a", "b", "c", "0"}))
+			})
+
+			It("should deliver the initial list before later events", func() {
+				instance.OnAdd(newNamedPod("b"), true)
+				instance.OnAdd(newNamedPod("a"), true)
+				funcs.UpdateFunc = func(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+					Expect(created).To(Equal([]string{"a", "b"}))
+					created = append(created, "updated-"+evt.ObjectNew.GetName())
+				}
+
+				instance.OnUpdate(newNamedPod("b"), newNamedPod("b"))
+				Expect(created).To(Equal([]string{"a", "b", "updated-b"}))
+
+				instance.FlushInitialList()
+				Expect(created).To(Equal([]string{"a", "b", "updated-b"}))
+			})
+		})
 	})
 })
 
@@ -9,6 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +25,10 @@ type Kind struct {
 	// Cache used to watch APIs
 	Cache cache.Cache
 
+	// InitialListLess, if set, orders the Create events of the initial list of the informer.
+	// They are delivered once all of them were received, before WaitForSync returns.
+	InitialListLess func(a, b client.Object) bool
+
 	// started may contain an error if one was encountered during startup. If its closed and does not
 	// contain an error, startup and syncing finished.
 	started     chan error
@@ -44,7 +49,10 @@ func (ks *Kind) Start(ctx context.Context, handler handler.EventHandler, queue w
 	// cache.GetInformer will block until its context is cancelled if the cache was already started and it can not
 	// sync that informer (most commonly due to RBAC issues).
 	ctx, ks.startCancel = context.WithCancel(ctx)
-	ks.started = make(chan error)
+	// started is buffered, so that the goroutine doesn't leak if the Kind is started
+	// again, e.g. by a controller that is restarted, before WaitForSync received it.
+	started := make(chan error, 1)
+	ks.started = started
 	go func() {
 		var (
 			i       cache.Informer
@@ -72,28 +80,61 @@ func (ks *Kind) Start(ctx context.Context, handler handler.EventHandler, queue w
 			return true, nil
 		}); err != nil {
 			if lastErr != nil {
-				ks.started <- fmt.Errorf("failed to get informer from cache: %w", lastErr)
+				started <- fmt.Errorf("failed to get informer from cache: %w", lastErr)
 				return
 			}
-			ks.started <- err
+			started <- err
 			return
 		}
 
-		_, err := i.AddEventHandler(NewEventHandler(ctx, queue, handler, prct).HandlerFuncs())
+		eventHandler := NewEventHandler(ctx, queue, handler, prct)
+		if ks.InitialListLess != nil {
+			eventHandler.OrderInitialList(ks.InitialListLess)
+		}
+		registration, err := i.AddEventHandler(eventHandler.HandlerFuncs())
 		if err != nil {
-			ks.started <- err
+			started <- err
 			return
 		}
 		if !ks.Cache.WaitForCacheSync(ctx) {
 			// Would be great to return something more informative here
-			ks.started <- errors.New("cache did not sync")
+			err = errors.New("cache did not sync")
+		} else if ks.InitialListLess != nil {
+			err = ks.flushInitialList(ctx, eventHandler, registration)
 		}
-		close(ks.started)
+		if err != nil {
+			// Don't deliver events twice if the Kind is started again.
+			_ = i.RemoveEventHandler(registration)
+			started <- err
+		} else {
+			// Stop delivering events once the Kind is stopped, e.g. because the watches
+			// of a cluster are started again.
+			context.AfterFunc(ctx, func() {
+				_ = i.RemoveEventHandler(registration)
+			})
+		}
+		close(started)
 	}()
 
 	return nil
 }
 
+// flushInitialList waits for the initial list to be delivered to the event handler and
+// then delivers it in order.
+func (ks *Kind) flushInitialList(ctx context.Context, eventHandler *EventHandler, registration toolscache.ResourceEventHandlerRegistration) error {
+	// The informer being synced doesn't mean that the initial list was delivered to the
+	// handler yet, which is what the registration tracks.
+	if registration != nil {
+		if err := wait.PollUntilContextCancel(ctx, 10*time.Millisecond, true, func(context.Context) (bool, error) {
+			return registration.HasSynced(), nil
+		}); err != nil {
+			return fmt.Errorf("initial list was not delivered: %w", err)
+		}
+	}
+	eventHandler.FlushInitialList()
+	return nil
+}
+
 func (ks *Kind) String() string {
 	if ks.Type != nil {
 		return fmt.Sprintf("kind source: %T", ks.Type)
@@ -164,6 +164,22 @@ func (c *TinyCA) NewServingCert(names ...string) (CertPair, error) {
 		return CertPair{}, err
 	}
 
+	return c.newServingCert(dnsNames, ips)
+}
+
+// NewServingCertForDNSNames produces a new CertPair for serving HTTPS on the given
+// names like NewServingCert, which is additionally valid for the given DNS names
+// without resolving them, e.g. for cluster internal names of Services.
+func (c *TinyCA) NewServingCertForDNSNames(unresolvedNames []string, names ...string) (CertPair, error) {
+	dnsNames, ips, err := resolveNames(names)
+	if err != nil {
+		return CertPair{}, err
+	}
+
+	return c.newServingCert(append(dnsNames, unresolvedNames...), ips)
+}
+
+func (c *TinyCA) newServingCert(dnsNames []string, ips []net.IP) (CertPair, error) {
 	return c.makeCert(certutil.Config{
 		CommonName:   "localhost",
 		Organization: []string{c.orgName},
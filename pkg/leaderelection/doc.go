@@ -20,5 +20,8 @@ This is used to ensure that multiple copies of a controller manager can be run w
 only one active set of controllers, for active-passive HA.
 
 It uses built-in Kubernetes leader election APIs.
+
+The ObjectLocker locks individual objects instead, for processes that act on the same
+objects without sharing a leader.
 */
 package leaderelection
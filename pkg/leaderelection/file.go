@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// fileResource is the resource reported in errors of file locks.
+var fileResource = schema.GroupResource{Resource: "files"}
+
+// FileProvider creates resource locks that coordinate the leader election of processes
+// on a single node through files, without an API server. The leader holds an exclusive
+// lock on the file for as long as it leads, which the operating system releases if
+// the process dies. It is not supported on non-unix systems.
+type FileProvider struct {
+	// Dir is the directory the lock files are created in.
+	Dir string
+}
+
+// NewResourceLock returns a file lock for the leader election with the given ID.
+func (p *FileProvider) NewResourceLock(id string) (resourcelock.Interface, error) {
+	if id == "" {
+		return nil, errors.New("LeaderElectionID must be configured")
+	}
+	identity, err := newIdentity()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileResourceLock(filepath.Join(p.Dir, id), identity), nil
+}
+
+// NewFileResourceLock returns a resource lock storing the leader election record
+// in the file at path, see FileProvider.
+func NewFileResourceLock(path, identity string) resourcelock.Interface {
+	return &fileLock{path: path, identity: identity}
+}
+
+type fileLock struct {
+	path     string
+	identity string
+
+	// mu guards held.
+	mu sync.Mutex
+	// held is the lock file while the exclusive lock on it is held.
+	held *os.File
+}
+
+// Get implements resourcelock.Interface.
+func (l *fileLock) Get(_ context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	raw, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, apierrors.NewNotFound(fileResource, l.path)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := &resourcelock.LeaderElectionRecord{}
+	if err := json.Unmarshal(raw, record); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode leader election record %q: %w", l.path, err)
+	}
+	return record, raw, nil
+}
+
+// Create implements resourcelock.Interface.
+func (l *fileLock) Create(_ context.Context, ler resourcelock.LeaderElectionRecord) error {
+	return l.write(ler)
+}
+
+// Update implements resourcelock.Interface.
+func (l *fileLock) Update(_ context.Context, ler resourcelock.LeaderElectionRecord) error {
+	return l.write(ler)
+}
+
+// write stores the record, which requires holding the exclusive lock on the lock file.
+// The lock is released when the record is written without a holder, which happens
+// when the leader steps down.
+func (l *fileLock) write(ler resourcelock.LeaderElectionRecord) (retErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held == nil {
+		f, err := os.OpenFile(l.path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return err
+		}
+		if err := tryLockFile(f); err != nil {
+			f.Close()
+			return apierrors.NewConflict(fileResource, l.path, err)
+		}
+		l.held = f
+		// Don't keep a lock we just acquired if the record can't be written, as no
+		// other process could acquire it anymore.
+		defer func() {
+			if retErr != nil && l.held != nil {
+				l.held.Close()
+				l.held = nil
+			}
+		}()
+	}
+
+	raw, err := json.Marshal(ler)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that readers never see a partial record.
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+
+	if ler.HolderIdentity == "" {
+		err := l.held.Close()
+		l.held = nil
+		return err
+	}
+	return nil
+}
+
+// RecordEvent implements resourcelock.Interface.
+func (l *fileLock) RecordEvent(string) {}
+
+// Identity implements resourcelock.Interface.
+func (l *fileLock) Identity() string {
+	return l.identity
+}
+
+// Describe implements resourcelock.Interface.
+func (l *fileLock) Describe() string {
+	return l.path
+}
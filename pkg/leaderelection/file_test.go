@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
+)
+
+var _ = Describe("FileProvider", func() {
+	var provider *leaderelection.FileProvider
+
+	BeforeEach(func() {
+		provider = &leaderelection.FileProvider{Dir: GinkgoT().TempDir()}
+	})
+
+	record := func(holder string) resourcelock.LeaderElectionRecord {
+		return resourcelock.LeaderElectionRecord{
+			HolderIdentity:       holder,
+			LeaseDurationSeconds: 15,
+			RenewTime:            metav1.NewTime(time.Now().Truncate(time.Second)),
+		}
+	}
+
+	It("should return NotFound before a record was written", func() {
+		lock, err := provider.NewResourceLock("my-manager")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, err = lock.Get(context.Background())
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should only allow the holder of the lock to write records", func() {
+		leader, err := provider.NewResourceLock("my-manager")
+		Expect(err).NotTo(HaveOccurred())
+		candidate, err := provider.NewResourceLock("my-manager")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(leader.Identity()).NotTo(Equal(candidate.Identity()))
+
+		Expect(leader.Create(context.Background(), record(leader.Identity()))).To(Succeed())
+		Expect(leader.Update(context.Background(), record(leader.Identity()))).To(Succeed())
+		got, _, err := candidate.Get(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.HolderIdentity).To(Equal(leader.Identity()))
+
+		err = candidate.Update(context.Background(), record(candidate.Identity()))
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+		By("stepping down")
+		Expect(leader.Update(context.Background(), record(""))).To(Succeed())
+		Expect(candidate.Update(context.Background(), record(candidate.Identity()))).To(Succeed())
+		got, _, err = leader.Get(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.HolderIdentity).To(Equal(candidate.Identity()))
+	})
+
+	It("should release the lock if the record can't be written", func() {
+		leader, err := provider.NewResourceLock("my-manager")
+		Expect(err).NotTo(HaveOccurred())
+		candidate, err := provider.NewResourceLock("my-manager")
+		Expect(err).NotTo(HaveOccurred())
+
+		// A non-empty directory in place of the record makes the rename fail.
+		Expect(os.MkdirAll(filepath.Join(provider.Dir, "my-manager", "blocker"), 0o755)).To(Succeed())
+		Expect(leader.Create(context.Background(), record(leader.Identity()))).NotTo(Succeed())
+
+		Expect(os.RemoveAll(filepath.Join(provider.Dir, "my-manager"))).To(Succeed())
+		Expect(candidate.Create(context.Background(), record(candidate.Identity()))).To(Succeed())
+	})
+
+	It("should require an ID", func() {
+		_, err := provider.NewResourceLock("")
+		Expect(err).To(HaveOccurred())
+	})
+})
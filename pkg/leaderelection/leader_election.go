@@ -80,11 +80,10 @@ func NewResourceLock(config *rest.Config, recorderProvider recorder.Provider, op
 	}
 
 	// Leader id, needs to be unique
-	id, err := os.Hostname()
+	id, err := newIdentity()
 	if err != nil {
 		return nil, err
 	}
-	id = id + "_" + string(uuid.NewUUID())
 
 	// Construct clients for leader election
 	rest.AddUserAgent(config, "leader-election")
@@ -109,6 +108,15 @@ func NewResourceLock(config *rest.Config, recorderProvider recorder.Provider, op
 		})
 }
 
+// newIdentity returns a unique identity for a leader election candidate.
+func newIdentity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
+
 func getInClusterNamespace() (string, error) {
 	// Check whether the namespace file exists.
 	// If not, we are not running in cluster so can't guess the namespace.
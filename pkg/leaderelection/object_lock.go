@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ObjectLockGroupKindAnnotation, ObjectLockNamespaceAnnotation and ObjectLockNameAnnotation
+	// are set on the Lease of an object lock to the locked object, so that locks can be
+	// attributed to objects when they are inspected.
+	ObjectLockGroupKindAnnotation = "objectlock.controller-runtime.sigs.k8s.io/group-kind"
+	ObjectLockNamespaceAnnotation = "objectlock.controller-runtime.sigs.k8s.io/namespace"
+	ObjectLockNameAnnotation      = "objectlock.controller-runtime.sigs.k8s.io/name"
+
+	// ObjectLockLockerAnnotation is set on the Lease of an object lock to the name of
+	// the ObjectLocker that created it.
+	ObjectLockLockerAnnotation = "objectlock.controller-runtime.sigs.k8s.io/locker"
+
+	defaultObjectLockDuration  = 15 * time.Second
+	defaultObjectLockNamespace = "default"
+)
+
+const (
+	objectLockAcquired  = "acquired"
+	objectLockRenewed   = "renewed"
+	objectLockTakenOver = "taken_over"
+	objectLockContended = "contended"
+)
+
+var objectLockAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "controller_runtime_object_lock_attempts_total",
+	Help: "Total number of attempts to lock an object per locker and result (acquired, renewed, taken_over or contended)",
+}, []string{"locker", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(objectLockAttempts)
+}
+
+// ObjectLockOptions are the options of an ObjectLocker.
+type ObjectLockOptions struct {
+	// Name identifies the ObjectLocker in metrics and in the annotations of its Leases.
+	// Name is required.
+	Name string
+
+	// Identity is the identity of the holder of the locks. It must be unique across all
+	// processes locking the same objects.
+	// Defaults to the hostname with a random suffix.
+	Identity string
+
+	// Namespace is the namespace of the Leases of cluster scoped objects. The Leases of
+	// namespaced objects are created in the namespace of the object.
+	// Defaults to "default".
+	Namespace string
+
+	// LeaseDuration is the duration after which a lock that was not renewed expires and
+	// can be taken over by another holder, e.g. because its holder died. Locks are renewed
+	// by locking the object again. It is rounded up to whole seconds.
+	// Defaults to 15 seconds.
+	LeaseDuration time.Duration
+}
+
+// ObjectLocker locks individual objects through short-lived Leases named after the UID
+// of the object, e.g. to keep distinct operator deployments that act on the same objects
+// during a migration from reconciling them at the same time. Unlike leader election, it
+// doesn't require the deployments to agree on a single leader:
+//
+//	locked, err := locker.TryLock(ctx, obj)
+//	if err != nil || !locked {
+//		return reconcile.Result{RequeueAfter: 5 * time.Second}, err
+//	}
+//	defer locker.Unlock(ctx, obj)
+//
+// Locks of holders that don't unlock expire after the LeaseDuration. The Leases are
+// owned by the locked objects where ownership is possible, so that they are garbage
+// collected together with the objects.
+type ObjectLocker struct {
+	client client.Client
+	opts   ObjectLockOptions
+}
+
+// NewObjectLocker returns an ObjectLocker that manages its Leases through the given client.
+func NewObjectLocker(c client.Client, opts ObjectLockOptions) (*ObjectLocker, error) {
+	if opts.Name == "" {
+		return nil, errors.New("the name of the object locker must be configured")
+	}
+	if opts.Identity == "" {
+		identity, err := newIdentity()
+		if err != nil {
+			return nil, err
+		}
+		opts.Identity = identity
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = defaultObjectLockNamespace
+	}
+	if opts.LeaseDuration <= 0 {
+		opts.LeaseDuration = defaultObjectLockDuration
+	}
+	return &ObjectLocker{client: c, opts: opts}, nil
+}
+
+// TryLock locks the given object or renews the lock if it is already held by this locker.
+// It returns false without an error if the lock is held by another holder that didn't
+// let it expire. The object must have a UID, i.e. it must have been read from the API server.
+func (l *ObjectLocker) TryLock(ctx context.Context, obj client.Object) (bool, error) {
+	key, err := l.leaseKey(obj)
+	if err != nil {
+		return false, err
+	}
+
+	lease := &coordinationv1.Lease{}
+	if err := l.client.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get lease %s: %w", key, err)
+		}
+		return l.create(ctx, key, obj)
+	}
+
+	now := metav1.NewMicroTime(time.Now())
+	result := objectLockRenewed
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != l.opts.Identity {
+		if !l.expired(lease, now.Time) {
+			objectLockAttempts.WithLabelValues(l.opts.Name, objectLockContended).Inc()
+			return false, nil
+		}
+		result = objectLockTakenOver
+		lease.Spec.HolderIdentity = ptr.To(l.opts.Identity)
+		lease.Spec.AcquireTime = &now
+		lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	}
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseDurationSeconds = ptr.To(l.leaseDurationSeconds())
+	// The update fails with a conflict if another holder updated the Lease in the meantime.
+	if err := l.client.Update(ctx, lease); err != nil {
+		if apierrors.IsConflict(err) {
+			objectLockAttempts.WithLabelValues(l.opts.Name, objectLockContended).Inc()
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update lease %s: %w", key, err)
+	}
+	objectLockAttempts.WithLabelValues(l.opts.Name, result).Inc()
+	return true, nil
+}
+
+// Unlock releases the lock of the given object if it is held by this locker.
+func (l *ObjectLocker) Unlock(ctx context.Context, obj client.Object) error {
+	key, err := l.leaseKey(obj)
+	if err != nil {
+		return err
+	}
+
+	lease := &coordinationv1.Lease{}
+	if err := l.client.Get(ctx, key, lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != l.opts.Identity {
+		return nil
+	}
+	// Don't delete the Lease if another holder took it over in the meantime.
+	err = l.client.Delete(ctx, lease, client.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to delete lease %s: %w", key, err)
+	}
+	return nil
+}
+
+func (l *ObjectLocker) create(ctx context.Context, key client.ObjectKey, obj client.Object) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, l.client.Scheme())
+	if err != nil {
+		return false, err
+	}
+	now := metav1.NewMicroTime(time.Now())
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Annotations: map[string]string{
+				ObjectLockGroupKindAnnotation: gvk.GroupKind().String(),
+				ObjectLockNamespaceAnnotation: obj.GetNamespace(),
+				ObjectLockNameAnnotation:      obj.GetName(),
+				ObjectLockLockerAnnotation:    l.opts.Name,
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(l.opts.Identity),
+			LeaseDurationSeconds: ptr.To(l.leaseDurationSeconds()),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+	// Owner references can't cross namespaces, only objects in the namespace of the Lease
+	// and cluster scoped objects can own it.
+	if obj.GetNamespace() == "" || obj.GetNamespace() == key.Namespace {
+		lease.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       obj.GetName(),
+			UID:        obj.GetUID(),
+		}}
+	}
+
+	if err := l.client.Create(ctx, lease); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			objectLockAttempts.WithLabelValues(l.opts.Name, objectLockContended).Inc()
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create lease %s: %w", key, err)
+	}
+	objectLockAttempts.WithLabelValues(l.opts.Name, objectLockAcquired).Inc()
+	return true, nil
+}
+
+// leaseKey returns the key of the Lease of the given object.
+func (l *ObjectLocker) leaseKey(obj client.Object) (client.ObjectKey, error) {
+	if obj.GetUID() == "" {
+		return client.ObjectKey{}, fmt.Errorf("unable to lock %s without a UID", client.ObjectKeyFromObject(obj))
+	}
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = l.opts.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: "objectlock-" + string(obj.GetUID())}, nil
+}
+
+// expired returns whether the lock of the given Lease expired at now.
+func (l *ObjectLocker) expired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	return lease.Spec.RenewTime.Add(duration).Before(now)
+}
+
+func (l *ObjectLocker) leaseDurationSeconds() int32 {
+	return int32((l.opts.LeaseDuration + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
+)
+
+var _ = Describe("ObjectLocker", func() {
+	var (
+		ctx      context.Context
+		c        client.Client
+		obj      *corev1.ConfigMap
+		leaseKey client.ObjectKey
+	)
+
+	newLocker := func(identity string) *leaderelection.ObjectLocker {
+		locker, err := leaderelection.NewObjectLocker(c, leaderelection.ObjectLockOptions{Name: "test", Identity: identity})
+		Expect(err).NotTo(HaveOccurred())
+		return locker
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		c = fake.NewClientBuilder().Build()
+		obj = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cm", UID: types.UID("1234")}}
+		leaseKey = client.ObjectKey{Namespace: "ns", Name: "objectlock-1234"}
+	})
+
+	It("should require a name", func() {
+		_, err := leaderelection.NewObjectLocker(c, leaderelection.ObjectLockOptions{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only let one holder lock an object at a time", func() {
+		a, b := newLocker("a"), newLocker("b")
+		Expect(a.TryLock(ctx, obj)).To(BeTrue())
+		Expect(b.TryLock(ctx, obj)).To(BeFalse())
+
+		By("Renewing the lock of the holder")
+		Expect(a.TryLock(ctx, obj)).To(BeTrue())
+
+		By("Recording the locked object on the Lease")
+		lease := &coordinationv1.Lease{}
+		Expect(c.Get(ctx, leaseKey, lease)).To(Succeed())
+		Expect(lease.Spec.HolderIdentity).To(HaveValue(Equal("a")))
+		Expect(lease.Annotations).To(HaveKeyWithValue(leaderelection.ObjectLockGroupKindAnnotation, "ConfigMap"))
+		Expect(lease.Annotations).To(HaveKeyWithValue(leaderelection.ObjectLockNameAnnotation, "cm"))
+		Expect(lease.OwnerReferences).To(ConsistOf(HaveField("UID", types.UID("1234"))))
+
+		By("Letting the other holder lock the object once it is unlocked")
+		Expect(b.Unlock(ctx, obj)).To(Succeed())
+		Expect(b.TryLock(ctx, obj)).To(BeFalse())
+		Expect(a.Unlock(ctx, obj)).To(Succeed())
+		Expect(c.Get(ctx, leaseKey, lease)).To(Satisfy(apierrors.IsNotFound))
+		Expect(b.TryLock(ctx, obj)).To(BeTrue())
+	})
+
+	It("should take over expired locks", func() {
+		renewed := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+		Expect(c.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: leaseKey.Namespace, Name: leaseKey.Name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To("dead"),
+				LeaseDurationSeconds: ptr.To[int32](15),
+				RenewTime:            &renewed,
+			},
+		})).To(Succeed())
+
+		Expect(newLocker("a").TryLock(ctx, obj)).To(BeTrue())
+		lease := &coordinationv1.Lease{}
+		Expect(c.Get(ctx, leaseKey, lease)).To(Succeed())
+		Expect(lease.Spec.HolderIdentity).To(HaveValue(Equal("a")))
+		Expect(lease.Spec.LeaseTransitions).To(HaveValue(BeEquivalentTo(1)))
+	})
+
+	It("should fail to lock objects without a UID", func() {
+		obj.UID = ""
+		_, err := newLocker("a").TryLock(ctx, obj)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	defaultClusterHealthFailureThreshold = 3
+	defaultClusterHealthSuccessThreshold = 1
+
+	clusterHealthy   = "healthy"
+	clusterUnhealthy = "unhealthy"
+
+	clusterHealthRecorderName = "cluster-health"
+)
+
+var (
+	// clusterHealthStatus is a prometheus metric which is 1 while a provider cluster
+	// is healthy and 0 while its runnables are disengaged because it is unhealthy.
+	clusterHealthStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cluster_healthy",
+		Help: "Whether the API server of the provider cluster is healthy per cluster",
+	}, []string{"cluster"})
+
+	// clusterHealthProbeFailures is a prometheus counter metrics which holds the total
+	// number of failed health probes per provider cluster.
+	clusterHealthProbeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_health_probe_failures_total",
+		Help: "Total number of failed health probes of the API server per cluster",
+	}, []string{"cluster"})
+
+	// clusterHealthTransitions is a prometheus counter metrics which holds the total
+	// number of health transitions per provider cluster and state it transitioned to.
+	clusterHealthTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cluster_health_transitions_total",
+		Help: "Total number of health transitions per cluster and state (healthy or unhealthy)",
+	}, []string{"cluster", "state"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterHealthStatus, clusterHealthProbeFailures, clusterHealthTransitions)
+}
+
+// ClusterHealthOptions configure the health monitoring of the provider clusters. The
+// API server of every engaged cluster is probed periodically. Once a cluster is
+// unhealthy, the Runnables are disengaged from it, which e.g. stops the watches of the
+// controllers and drops their requests of the cluster, and engaged again once it
+// recovered. The cluster stays engaged with the manager in the meantime.
+type ClusterHealthOptions struct {
+	// ProbeInterval is the interval at which the API servers of the clusters are probed.
+	// Defaults to 0, which disables the health monitoring.
+	ProbeInterval time.Duration
+
+	// ProbeTimeout is the timeout of a single probe. Defaults to ProbeInterval.
+	ProbeTimeout time.Duration
+
+	// FailureThreshold is the number of consecutive failed probes after which a cluster
+	// is unhealthy. Defaults to 3.
+	FailureThreshold int
+
+	// SuccessThreshold is the number of consecutive successful probes after which an
+	// unhealthy cluster is healthy again. Defaults to 1.
+	SuccessThreshold int
+
+	// EventObject, if set, is the object Events are recorded for with the EventRecorder of
+	// the manager when a cluster becomes unhealthy or recovers, e.g. the Deployment of the
+	// manager, as clusters aren't objects of the cluster of the manager.
+	// Defaults to nil, which means no Events are recorded.
+	EventObject runtime.Object
+}
+
+// setDefaults sets the defaults of the options that aren't set.
+func (o ClusterHealthOptions) setDefaults() ClusterHealthOptions {
+	if o.ProbeTimeout <= 0 {
+		o.ProbeTimeout = o.ProbeInterval
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = defaultClusterHealthFailureThreshold
+	}
+	if o.SuccessThreshold <= 0 {
+		o.SuccessThreshold = defaultClusterHealthSuccessThreshold
+	}
+	return o
+}
+
+// monitorClusterHealth probes the API server of the given cluster until it is
+// disengaged, and disengages and engages the Runnables as its health changes.
+func (cm *controllerManager) monitorClusterHealth(name string, engaged *engagedCluster) {
+	opts := cm.clusterHealth.setDefaults()
+	log := cm.logger.WithValues("cluster", name)
+
+	clusterHealthStatus.WithLabelValues(name).Set(1)
+	defer clusterHealthStatus.DeleteLabelValues(name)
+	defer clusterHealthProbeFailures.DeleteLabelValues(name)
+
+	ticker := time.NewTicker(opts.ProbeInterval)
+	defer ticker.Stop()
+	var failures, successes int
+	for {
+		select {
+		case <-engaged.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(engaged.ctx, opts.ProbeTimeout)
+		err := engaged.probe(ctx)
+		cancel()
+		if engaged.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			clusterHealthProbeFailures.WithLabelValues(name).Inc()
+			failures, successes = failures+1, 0
+			if failures >= opts.FailureThreshold && !engaged.unhealthy.Load() {
+				log.Info("Cluster is unhealthy, disengaging runnables", "failures", failures, "error", err.Error())
+				cm.disengageUnhealthyCluster(name, engaged)
+				cm.recordClusterHealthEvent(opts, corev1.EventTypeWarning, "ClusterUnhealthy",
+					"Cluster %s is unhealthy after %d failed probes, disengaged runnables: %v", name, failures, err)
+			}
+			continue
+		}
+
+		failures, successes = 0, successes+1
+		if successes >= opts.SuccessThreshold && engaged.unhealthy.Load() {
+			// Engaging is retried on the next successful probe if it fails.
+			if err := cm.reengageHealthyCluster(name, engaged); err != nil {
+				log.Error(err, "Failed to engage runnables with recovered cluster")
+				continue
+			}
+			log.Info("Cluster recovered, engaged runnables", "successes", successes)
+			cm.recordClusterHealthEvent(opts, corev1.EventTypeNormal, "ClusterHealthy",
+				"Cluster %s recovered, engaged runnables", name)
+		}
+	}
+}
+
+// recordClusterHealthEvent records an Event for the EventObject of the given options, if set.
+func (cm *controllerManager) recordClusterHealthEvent(opts ClusterHealthOptions, eventType, reason, messageFmt string, args ...interface{}) {
+	if opts.EventObject == nil {
+		return
+	}
+	cm.GetEventRecorderFor(clusterHealthRecorderName).Eventf(opts.EventObject, eventType, reason, messageFmt, args...)
+}
+
+// disengageUnhealthyCluster disengages the Runnables from the given cluster.
+func (cm *controllerManager) disengageUnhealthyCluster(name string, engaged *engagedCluster) {
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+
+	engaged.disengageRunnables()
+	engaged.unhealthy.Store(true)
+	clusterHealthStatus.WithLabelValues(name).Set(0)
+	clusterHealthTransitions.WithLabelValues(name, clusterUnhealthy).Inc()
+}
+
+// reengageHealthyCluster engages the Runnables with the given cluster again.
+func (cm *controllerManager) reengageHealthyCluster(name string, engaged *engagedCluster) error {
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+
+	if err := cm.engageRunnablesLocked(name, engaged); err != nil {
+		return err
+	}
+	engaged.unhealthy.Store(false)
+	clusterHealthStatus.WithLabelValues(name).Set(1)
+	clusterHealthTransitions.WithLabelValues(name, clusterHealthy).Inc()
+	return nil
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
+)
+
+const (
+	versionEndpoint = "/version"
+	configzEndpoint = "/configz"
+
+	controllerRuntimeModule = "sigs.k8s.io/controller-runtime"
+)
+
+// VersionInfo describes the build of the running binary.
+type VersionInfo struct {
+	// ControllerRuntimeVersion is the version of the controller-runtime module
+	// the binary was built with.
+	ControllerRuntimeVersion string `json:"controllerRuntimeVersion"`
+
+	// Module is the path of the main module of the binary.
+	Module string `json:"module,omitempty"`
+
+	// ModuleVersion is the version of the main module of the binary.
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+
+	// Revision is the VCS revision the binary was built from, if known.
+	Revision string `json:"revision,omitempty"`
+
+	// GoVersion is the version of Go the binary was built with.
+	GoVersion string `json:"goVersion"`
+
+	// Platform is the operating system and architecture of the binary.
+	Platform string `json:"platform"`
+}
+
+// ControllerConfig is a view of the options a controller was created with.
+type ControllerConfig = controller.Config
+
+// CacheConfig is a view of the selectors the cache of the manager was configured with.
+type CacheConfig struct {
+	// SyncPeriod is the resync period of the informers, if set.
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+
+	// DefaultNamespaces are the namespaces objects are cached from by default.
+	// The empty string stands for all namespaces.
+	DefaultNamespaces []string `json:"defaultNamespaces,omitempty"`
+
+	// DefaultLabelSelector is the label selector used by default.
+	DefaultLabelSelector string `json:"defaultLabelSelector,omitempty"`
+
+	// DefaultFieldSelector is the field selector used by default.
+	DefaultFieldSelector string `json:"defaultFieldSelector,omitempty"`
+
+	// ByObject are the selectors of specific objects.
+	ByObject []CacheObjectConfig `json:"byObject,omitempty"`
+}
+
+// CacheObjectConfig is a view of the cache options of a specific object.
+type CacheObjectConfig struct {
+	// Type is the GroupVersionKind of the object, or its Go type if it isn't
+	// registered in the scheme.
+	Type string `json:"type"`
+
+	// Namespaces are the namespaces the object is cached from.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// LabelSelector is the label selector of the object.
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// FieldSelector is the field selector of the object.
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// PollInterval is the interval the object is polled at, if it isn't watched.
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+// ConfigSnapshot is a view of the configuration of a manager.
+type ConfigSnapshot struct {
+	// Version describes the build of the running binary.
+	Version VersionInfo `json:"version"`
+
+	// Features reports which optional behaviors of the manager are enabled.
+	Features map[string]bool `json:"features"`
+
+	// Controllers are the options of the controllers added to the manager.
+	Controllers []ControllerConfig `json:"controllers"`
+
+	// Cache are the selectors the cache of the manager was configured with.
+	Cache CacheConfig `json:"cache"`
+}
+
+// controllerConfigReporter is implemented by controllers that report their options.
+type controllerConfigReporter interface {
+	Config() controller.Config
+}
+
+// configSnapshotter is implemented by managers that report their configuration.
+type configSnapshotter interface {
+	GetConfigSnapshot() ConfigSnapshot
+}
+
+// GetConfigSnapshot returns the configuration of mgr, i.e. its enabled features, the
+// options of its controllers and the selectors of its cache. It is also served as JSON
+// at /configz by the pprof server, if enabled.
+func GetConfigSnapshot(mgr Manager) (ConfigSnapshot, error) {
+	snapshotter, ok := mgr.(configSnapshotter)
+	if !ok {
+		return ConfigSnapshot{}, fmt.Errorf("manager %T doesn't report its configuration", mgr)
+	}
+	return snapshotter.GetConfigSnapshot(), nil
+}
+
+// GetConfigSnapshot returns the configuration of the manager, its controllers and its cache.
+func (cm *controllerManager) GetConfigSnapshot() ConfigSnapshot {
+	cm.debugLock.Lock()
+	reporters := append([]controllerStatusReporter(nil), cm.controllerStatusReporters...)
+	cm.debugLock.Unlock()
+
+	controllers := []ControllerConfig{}
+	for _, r := range reporters {
+		if cr, ok := r.(controllerConfigReporter); ok {
+			controllers = append(controllers, cr.Config())
+		}
+	}
+
+	return ConfigSnapshot{
+		Version: GetVersionInfo(),
+		Features: map[string]bool{
+			"leaderElection": cm.resourceLock != nil,
+			"warmStandby":    cm.warmStandby,
+			"resilientStart": cm.apiServer != nil,
+			"recoverPanic":   ptr.Deref(cm.controllerConfig.RecoverPanic, false),
+			"deterministic":  cm.controllerConfig.Deterministic,
+		},
+		Controllers: controllers,
+		Cache:       cm.cacheConfig,
+	}
+}
+
+// GetVersionInfo returns the build information of the running binary, e.g. the
+// controller-runtime version. It is also served as JSON at /version by the pprof
+// server, if enabled.
+func GetVersionInfo() VersionInfo {
+	info := VersionInfo{
+		ControllerRuntimeVersion: "unknown",
+		GoVersion:                runtime.Version(),
+		Platform:                 runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Module = bi.Main.Path
+	info.ModuleVersion = bi.Main.Version
+	if bi.Main.Path == controllerRuntimeModule {
+		info.ControllerRuntimeVersion = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path != controllerRuntimeModule {
+			continue
+		}
+		info.ControllerRuntimeVersion = dep.Version
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			info.ControllerRuntimeVersion = dep.Replace.Version
+		}
+	}
+	for _, setting := range bi.Settings {
+		if setting.Key == "vcs.revision" {
+			info.Revision = setting.Value
+		}
+	}
+	return info
+}
+
+// newCacheConfig returns a view of the selectors of the given cache options.
+func newCacheConfig(opts cache.Options, scheme *kruntime.Scheme) CacheConfig {
+	cfg := CacheConfig{
+		DefaultNamespaces: sortedKeys(opts.DefaultNamespaces),
+	}
+	if opts.SyncPeriod != nil {
+		cfg.SyncPeriod = &metav1.Duration{Duration: *opts.SyncPeriod}
+	}
+	if opts.DefaultLabelSelector != nil {
+		cfg.DefaultLabelSelector = opts.DefaultLabelSelector.String()
+	}
+	if opts.DefaultFieldSelector != nil {
+		cfg.DefaultFieldSelector = opts.DefaultFieldSelector.String()
+	}
+
+	for obj, byObject := range opts.ByObject {
+		objCfg := CacheObjectConfig{
+			Type:       fmt.Sprintf("%T", obj),
+			Namespaces: sortedKeys(byObject.Namespaces),
+		}
+		if gvk, err := apiutil.GVKForObject(obj, scheme); err == nil {
+			objCfg.Type = gvk.String()
+		}
+		if byObject.Label != nil {
+			objCfg.LabelSelector = byObject.Label.String()
+		}
+		if byObject.Field != nil {
+			objCfg.FieldSelector = byObject.Field.String()
+		}
+		if byObject.PollInterval > 0 {
+			objCfg.PollInterval = &metav1.Duration{Duration: byObject.PollInterval}
+		}
+		cfg.ByObject = append(cfg.ByObject, objCfg)
+	}
+	sort.Slice(cfg.ByObject, func(i, j int) bool {
+		return cfg.ByObject[i].Type < cfg.ByObject[j].Type
+	})
+	return cfg
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// versionHandler serves the build information of the running binary as JSON.
+type versionHandler struct {
+	cm *controllerManager
+}
+
+func (h *versionHandler) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(GetVersionInfo()); err != nil {
+		h.cm.logger.Error(err, "failed to write version info")
+	}
+}
+
+// configzHandler serves the configuration snapshot of the manager as JSON.
+type configzHandler struct {
+	cm *controllerManager
+}
+
+func (h *configzHandler) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(h.cm.GetConfigSnapshot()); err != nil {
+		h.cm.logger.Error(err, "failed to write config snapshot")
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+)
+
+var _ = Describe("configz", func() {
+	var cm *controllerManager
+
+	BeforeEach(func() {
+		errChan := make(chan error, 1)
+		cm = &controllerManager{
+			logger:           logr.Discard(),
+			errChan:          errChan,
+			runnables:        newRunnables(defaultBaseContext, errChan),
+			controllerConfig: config.Controller{RecoverPanic: ptr.To(true)},
+			warmStandby:      true,
+		}
+	})
+
+	It("should report the version of the binary", func() {
+		info := GetVersionInfo()
+		Expect(info.ControllerRuntimeVersion).NotTo(BeEmpty())
+		Expect(info.GoVersion).To(Equal(runtime.Version()))
+		Expect(info.Platform).To(Equal(runtime.GOOS + "/" + runtime.GOARCH))
+
+		rec := httptest.NewRecorder()
+		(&versionHandler{cm: cm}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, versionEndpoint, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var served VersionInfo
+		Expect(json.NewDecoder(rec.Body).Decode(&served)).To(Succeed())
+		Expect(served).To(Equal(info))
+	})
+
+	It("should report the features and the options of the added controllers", func() {
+		Expect(cm.Add(&fakeConfigReporter{config: ControllerConfig{Name: "first", MaxConcurrentReconciles: 2}})).To(Succeed())
+		Expect(cm.Add(&fakeStatusReporter{status: ControllerStatus{Name: "no-config"}})).To(Succeed())
+
+		snapshot, err := GetConfigSnapshot(cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.Features).To(HaveKeyWithValue("recoverPanic", true))
+		Expect(snapshot.Features).To(HaveKeyWithValue("leaderElection", false))
+		Expect(snapshot.Features).To(HaveKeyWithValue("warmStandby", true))
+		Expect(snapshot.Controllers).To(Equal([]ControllerConfig{{Name: "first", MaxConcurrentReconciles: 2}}))
+	})
+
+	It("should report the selectors of the cache", func() {
+		cm.cacheConfig = newCacheConfig(cache.Options{
+			SyncPeriod:           ptr.To(time.Hour),
+			DefaultNamespaces:    map[string]cache.Config{"b": {}, "a": {}},
+			DefaultLabelSelector: labels.SelectorFromSet(labels.Set{"app": "foo"}),
+			ByObject: map[client.Object]cache.ByObject{
+				&corev1.Pod{}:        {Field: fields.OneTermEqualSelector("spec.nodeName", "node")},
+				&appsv1.Deployment{}: {PollInterval: time.Minute},
+			},
+		}, scheme.Scheme)
+
+		rec := httptest.NewRecorder()
+		(&configzHandler{cm: cm}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, configzEndpoint, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+
+		var snapshot ConfigSnapshot
+		Expect(json.NewDecoder(rec.Body).Decode(&snapshot)).To(Succeed())
+		Expect(snapshot.Cache).To(Equal(CacheConfig{
+			SyncPeriod:           &metav1.Duration{Duration: time.Hour},
+			DefaultNamespaces:    []string{"a", "b"},
+			DefaultLabelSelector: "app=foo",
+			ByObject: []CacheObjectConfig{
+				{Type: "/v1, Kind=Pod", FieldSelector: "spec.nodeName=node"},
+				{Type: "apps/v1, Kind=Deployment", PollInterval: &metav1.Duration{Duration: time.Minute}},
+			},
+		}))
+	})
+})
+
+type fakeConfigReporter struct {
+	fakeStatusReporter
+	config ControllerConfig
+}
+
+func (f *fakeConfigReporter) Config() ControllerConfig {
+	return f.config
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
+)
+
+const (
+	queueSnapshotEndpoint    = "/debug/controllers/queues"
+	controllerStatusEndpoint = "/debug/controllers"
+)
+
+// ControllerStatus is a point-in-time view of the state of a controller.
+type ControllerStatus = controller.Status
+
+// controllerStatusReporter is implemented by controllers that report their status.
+type controllerStatusReporter interface {
+	Status() controller.Status
+}
+
+// queueSnapshotter is implemented by controllers whose queue can be inspected.
+type queueSnapshotter interface {
+	QueueSnapshot() controller.QueueSnapshot
+}
+
+// queueSnapshotHandler serves the queue snapshots of all controllers added to the manager
+// as a JSON list. The controller query parameter can be used to select a single controller.
+type queueSnapshotHandler struct {
+	cm *controllerManager
+}
+
+func (h *queueSnapshotHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	h.cm.debugLock.Lock()
+	snapshotters := append([]queueSnapshotter(nil), h.cm.queueSnapshotters...)
+	h.cm.debugLock.Unlock()
+
+	name := req.URL.Query().Get("controller")
+	snapshots := []controller.QueueSnapshot{}
+	for _, s := range snapshotters {
+		snapshot := s.QueueSnapshot()
+		if name != "" && snapshot.Controller != name {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(snapshots); err != nil {
+		h.cm.logger.Error(err, "failed to write queue snapshots")
+	}
+}
+
+// controllersReporter is implemented by managers that report the status of their
+// controllers.
+type controllersReporter interface {
+	GetControllers() []ControllerStatus
+}
+
+// GetControllers returns the status of all controllers added to mgr, e.g. their
+// watched kinds, sync status, queue depth and last error. The statuses are also
+// served as JSON at /debug/controllers by the pprof server, if enabled.
+func GetControllers(mgr Manager) ([]ControllerStatus, error) {
+	reporter, ok := mgr.(controllersReporter)
+	if !ok {
+		return nil, fmt.Errorf("manager %T doesn't report the status of its controllers", mgr)
+	}
+	return reporter.GetControllers(), nil
+}
+
+// GetControllers returns the status of all controllers added to the manager.
+func (cm *controllerManager) GetControllers() []ControllerStatus {
+	cm.debugLock.Lock()
+	reporters := append([]controllerStatusReporter(nil), cm.controllerStatusReporters...)
+	cm.debugLock.Unlock()
+
+	statuses := make([]ControllerStatus, 0, len(reporters))
+	for _, r := range reporters {
+		statuses = append(statuses, r.Status())
+	}
+	return statuses
+}
+
+// controllerStatusHandler serves the status of all controllers added to the manager
+// as a JSON list. The controller query parameter can be used to select a single controller.
+type controllerStatusHandler struct {
+	cm *controllerManager
+}
+
+func (h *controllerStatusHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("controller")
+	statuses := []ControllerStatus{}
+	for _, status := range h.cm.GetControllers() {
+		if name != "" && status.Name != name {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(resp).Encode(statuses); err != nil {
+		h.cm.logger.Error(err, "failed to write controller statuses")
+	}
+}
+
+// removeQueueSnapshotter stops serving the queue of the given snapshotter.
+func (cm *controllerManager) removeQueueSnapshotter(qs queueSnapshotter) {
+	cm.debugLock.Lock()
+	defer cm.debugLock.Unlock()
+	cm.queueSnapshotters = removeDebugTarget(cm.queueSnapshotters, qs)
+}
+
+// removeControllerStatusReporter stops reporting the status of the given controller.
+func (cm *controllerManager) removeControllerStatusReporter(r controllerStatusReporter) {
+	cm.debugLock.Lock()
+	defer cm.debugLock.Unlock()
+	cm.controllerStatusReporters = removeDebugTarget(cm.controllerStatusReporters, r)
+}
+
+// removeDebugTarget removes target from targets. Targets of types that aren't
+// comparable can't be found and are never removed.
+func removeDebugTarget[T any](targets []T, target T) []T {
+	if !reflect.TypeOf(target).Comparable() {
+		return targets
+	}
+	for i, existing := range targets {
+		if reflect.TypeOf(existing) == reflect.TypeOf(target) && any(existing) == any(target) {
+			return append(targets[:i], targets[i+1:]...)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("controller registry", func() {
+	var cm *controllerManager
+
+	BeforeEach(func() {
+		errChan := make(chan error, 1)
+		cm = &controllerManager{
+			logger:    logr.Discard(),
+			errChan:   errChan,
+			runnables: newRunnables(defaultBaseContext, errChan),
+		}
+	})
+
+	It("should return the status of the added controllers until they are removed", func() {
+		Expect(cm.Add(&fakeStatusReporter{status: ControllerStatus{Name: "first", QueueDepth: 3}})).To(Succeed())
+		handle, err := AddWithHandle(cm, &fakeStatusReporter{status: ControllerStatus{Name: "second", Synced: true}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Add(RunnableFunc(func(context.Context) error { return nil }))).To(Succeed())
+
+		Expect(GetControllers(cm)).To(Equal([]ControllerStatus{
+			{Name: "first", QueueDepth: 3},
+			{Name: "second", Synced: true},
+		}))
+
+		Expect(handle.Stop(context.Background())).To(Succeed())
+		Expect(GetControllers(cm)).To(Equal([]ControllerStatus{{Name: "first", QueueDepth: 3}}))
+	})
+
+	It("should fail for managers that don't report the status of their controllers", func() {
+		_, err := GetControllers(struct{ Manager }{cm})
+		Expect(err).To(MatchError(ContainSubstring("doesn't report the status of its controllers")))
+	})
+
+	It("should serve the statuses as JSON", func() {
+		Expect(cm.Add(&fakeStatusReporter{status: ControllerStatus{
+			Name:         "first",
+			WatchedKinds: []metav1.GroupVersionKind{{Version: "v1", Kind: "Pod"}},
+			LastError:    "boom",
+		}})).To(Succeed())
+		Expect(cm.Add(&fakeStatusReporter{status: ControllerStatus{Name: "second"}})).To(Succeed())
+
+		rec := httptest.NewRecorder()
+		(&controllerStatusHandler{cm: cm}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, controllerStatusEndpoint+"?controller=first", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var statuses []ControllerStatus
+		Expect(json.NewDecoder(rec.Body).Decode(&statuses)).To(Succeed())
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].Name).To(Equal("first"))
+		Expect(statuses[0].WatchedKinds).To(Equal([]metav1.GroupVersionKind{{Version: "v1", Kind: "Pod"}}))
+		Expect(statuses[0].LastError).To(Equal("boom"))
+	})
+})
+
+type fakeStatusReporter struct {
+	status ControllerStatus
+}
+
+func (f *fakeStatusReporter) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (f *fakeStatusReporter) Status() ControllerStatus {
+	return f.status
+}
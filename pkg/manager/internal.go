@@ -19,6 +19,7 @@ package manager
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"net"
 	"net/http"
@@ -43,6 +44,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/internal/httpserver"
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -78,6 +80,10 @@ type controllerManager struct {
 	// resourceLock forms the basis for leader election
 	resourceLock resourcelock.Interface
 
+	// newRunnableResourceLock creates the resource locks of Runnables with their own
+	// leader election ID. It is nil if leader election is disabled.
+	newRunnableResourceLock func(id string) (resourcelock.Interface, error)
+
 	// leaderElectionReleaseOnCancel defines if the manager should step back from the leader lease
 	// on shutdown
 	leaderElectionReleaseOnCancel bool
@@ -103,9 +109,60 @@ type controllerManager struct {
 	// pprofListener is used to serve pprof
 	pprofListener net.Listener
 
+	// pprofFilter, if set, is added around the handlers of the pprof server.
+	pprofFilter metricsserver.Filter
+
+	// pprofExpvar indicates whether the pprof server serves expvar.
+	pprofExpvar bool
+
+	// apiServer, if set, is waited for to become reachable before the caches are started.
+	apiServer *apiServerReachability
+
+	// debugLock guards queueSnapshotters and controllerStatusReporters.
+	debugLock sync.Mutex
+
+	// queueSnapshotters are the runnables whose queues are served by the debug handlers.
+	queueSnapshotters []queueSnapshotter
+
+	// controllerStatusReporters are the controllers returned by GetControllers.
+	controllerStatusReporters []controllerStatusReporter
+
+	// clusterProvider, if set, discovers the clusters engaged with the manager.
+	clusterProvider cluster.Provider
+
+	// clusterReadinessPolicy decides whether the manager is ready depending on the engaged clusters.
+	clusterReadinessPolicy ClusterReadinessPolicy
+
+	// clusterHealth configures the health monitoring of the provider clusters.
+	clusterHealth ClusterHealthOptions
+
+	// clustersLock guards clusters and clusterAwareRunnables.
+	clustersLock sync.Mutex
+
+	// clusters are the engaged provider clusters by name.
+	clusters map[string]*engagedCluster
+
+	// clusterAwareRunnables are the runnables engaged with the provider clusters.
+	clusterAwareRunnables []cluster.Aware
+
+	// reload configures how the runtime configuration is reloaded.
+	reload ReloadOptions
+
+	// reloadLock serializes reloads of the runtime configuration.
+	reloadLock sync.Mutex
+
+	// reloadablesLock guards reloadables.
+	reloadablesLock sync.Mutex
+
+	// reloadables are the runnables the runtime configuration is applied to.
+	reloadables []Reloadable
+
 	// controllerConfig are the global controller options.
 	controllerConfig config.Controller
 
+	// cacheConfig is a view of the selectors of the cache, as reported by GetConfigSnapshot.
+	cacheConfig CacheConfig
+
 	// Logger is the logger that should be used by this manager.
 	// If none is set, it defaults to log.Log global logger.
 	logger logr.Logger
@@ -124,11 +181,36 @@ type controllerManager struct {
 	// election was configured.
 	elected chan struct{}
 
+	// leadershipLost is closed when this manager loses the leadership it held.
+	leadershipLost chan struct{}
+
+	// shutdownHooksLock guards shutdownHooks.
+	shutdownHooksLock sync.Mutex
+
+	// shutdownHooks are called in order once all runnables stopped.
+	shutdownHooks []shutdownHook
+
+	// warmStandby indicates whether Runnables that need leader election are warmed up
+	// while the manager is not the leader.
+	warmStandby bool
+
+	// leaderCallbacks are the callbacks of the user for leadership transitions,
+	// each of them may be nil.
+	leaderCallbacks leaderelection.LeaderCallbacks
+
 	webhookServer webhook.Server
 	// webhookServerOnce will be called in GetWebhookServer() to optionally initialize
 	// webhookServer if unset, and Add() it to controllerManager.
 	webhookServerOnce sync.Once
 
+	// webhookServers are the additional webhook servers by name, see
+	// Options.WebhookServers.
+	webhookServers map[string]webhook.Server
+	// webhookServersAdded records which of webhookServers have been Add()ed
+	// to the controllerManager by GetNamedWebhookServer().
+	webhookServersAdded map[string]bool
+	webhookServersLock  sync.Mutex
+
 	// leaderElectionID is the name of the resource that leader election
 	// will use for holding the leader lock.
 	leaderElectionID string
@@ -175,8 +257,91 @@ func (cm *controllerManager) Add(r Runnable) error {
 	return cm.add(r)
 }
 
-func (cm *controllerManager) add(r Runnable) error {
-	return cm.runnables.Add(r)
+// AddWithOptions implements optionsAdder.
+func (cm *controllerManager) AddWithOptions(r Runnable, opts ...AddOption) error {
+	cm.Lock()
+	defer cm.Unlock()
+	return cm.add(r, opts...)
+}
+
+// AddWithHandle sets dependencies on i, adds it to the list of Runnables to start
+// and returns a handle to remove it again.
+func (cm *controllerManager) AddWithHandle(r Runnable, opts ...AddOption) (RunnableHandle, error) {
+	cm.Lock()
+	defer cm.Unlock()
+	return cm.addWithHandle(r, opts...)
+}
+
+func (cm *controllerManager) add(r Runnable, opts ...AddOption) error {
+	_, err := cm.addWithHandle(r, opts...)
+	return err
+}
+
+func (cm *controllerManager) addWithHandle(r Runnable, opts ...AddOption) (*runnableHandle, error) {
+	addOpts := &AddOptions{}
+	for _, opt := range opts {
+		opt.ApplyToAdd(addOpts)
+	}
+
+	if group, ok := r.(*RunnableGroup); ok {
+		group.setDefaultLogger(cm.logger)
+	}
+
+	for _, dep := range addOpts.After {
+		if _, ok := dep.(LeaderElectionRunnable); ok {
+			cm.logger.Info("Runnable depends on a LeaderElectionRunnable, it is only started once the dependency returned from Start without an error",
+				"runnable", fmt.Sprintf("%T", r), "dependency", fmt.Sprintf("%T", dep))
+		}
+	}
+
+	toAdd, err := cm.withOwnLeaderElection(r)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := cm.runnables.addWithHandle(toAdd, addOpts.After...)
+	if err != nil {
+		return nil, err
+	}
+
+	if w, ok := r.(WarmupRunnable); ok && cm.warmStandby && cm.resourceLock != nil && needsLeaderElection(r) {
+		warmupHandle, err := cm.runnables.addWithHandle(&warmupRunnable{runnable: w})
+		if err != nil {
+			handle.remove()
+			return nil, err
+		}
+		handle.onRemove = append(handle.onRemove, warmupHandle.remove)
+	}
+
+	if qs, ok := r.(queueSnapshotter); ok {
+		cm.debugLock.Lock()
+		cm.queueSnapshotters = append(cm.queueSnapshotters, qs)
+		cm.debugLock.Unlock()
+		handle.onRemove = append(handle.onRemove, func() { cm.removeQueueSnapshotter(qs) })
+	}
+
+	if sr, ok := r.(controllerStatusReporter); ok {
+		cm.debugLock.Lock()
+		cm.controllerStatusReporters = append(cm.controllerStatusReporters, sr)
+		cm.debugLock.Unlock()
+		handle.onRemove = append(handle.onRemove, func() { cm.removeControllerStatusReporter(sr) })
+	}
+
+	if reloadable, ok := r.(Reloadable); ok {
+		cm.reloadablesLock.Lock()
+		cm.reloadables = append(cm.reloadables, reloadable)
+		cm.reloadablesLock.Unlock()
+		handle.onRemove = append(handle.onRemove, func() { cm.removeReloadable(reloadable) })
+	}
+
+	if aware, ok := r.(cluster.Aware); ok {
+		if err := cm.addClusterAware(aware); err != nil {
+			handle.remove()
+			return nil, err
+		}
+		handle.onRemove = append(handle.onRemove, func() { cm.removeClusterAware(aware) })
+	}
+	return handle, nil
 }
 
 // AddHealthzCheck allows you to add Healthz checker.
@@ -249,6 +414,11 @@ func (cm *controllerManager) GetAPIReader() client.Reader {
 	return cm.cluster.GetAPIReader()
 }
 
+// Metadata implements cluster.MetadataReporter.
+func (cm *controllerManager) Metadata() cluster.Metadata {
+	return cluster.GetMetadata(cm.cluster)
+}
+
 func (cm *controllerManager) GetWebhookServer() webhook.Server {
 	cm.webhookServerOnce.Do(func() {
 		if cm.webhookServer == nil {
@@ -261,6 +431,28 @@ func (cm *controllerManager) GetWebhookServer() webhook.Server {
 	return cm.webhookServer
 }
 
+// GetNamedWebhookServer implements namedWebhookServerGetter.
+func (cm *controllerManager) GetNamedWebhookServer(name string) webhook.Server {
+	if name == "" {
+		return cm.GetWebhookServer()
+	}
+
+	cm.webhookServersLock.Lock()
+	defer cm.webhookServersLock.Unlock()
+
+	srv, ok := cm.webhookServers[name]
+	if !ok {
+		return nil
+	}
+	if !cm.webhookServersAdded[name] {
+		if err := cm.Add(srv); err != nil {
+			panic(fmt.Sprintf("unable to add webhook server %q to the controller manager: %s", name, err))
+		}
+		cm.webhookServersAdded[name] = true
+	}
+	return srv
+}
+
 func (cm *controllerManager) GetLogger() logr.Logger {
 	return cm.logger
 }
@@ -278,6 +470,12 @@ func (cm *controllerManager) addHealthProbeServer() error {
 		// Append '/' suffix to handle subpaths
 		mux.Handle(cm.readinessEndpointName+"/", http.StripPrefix(cm.readinessEndpointName, cm.readyzHandler))
 	}
+	if cm.readyzHandler != nil && cm.clusterProvider != nil {
+		clusterPath := cm.readinessEndpointName + clusterReadyzPath
+		clusterHandler := &clusterReadyzHandler{cm: cm}
+		mux.Handle(clusterPath, http.StripPrefix(clusterPath, clusterHandler))
+		mux.Handle(clusterPath+"/", http.StripPrefix(clusterPath, clusterHandler))
+	}
 	if cm.healthzHandler != nil {
 		mux.Handle(cm.livenessEndpointName, http.StripPrefix(cm.livenessEndpointName, cm.healthzHandler))
 		// Append '/' suffix to handle subpaths
@@ -301,6 +499,21 @@ func (cm *controllerManager) addPprofServer() error {
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle(queueSnapshotEndpoint, &queueSnapshotHandler{cm: cm})
+	mux.Handle(controllerStatusEndpoint, &controllerStatusHandler{cm: cm})
+	mux.Handle(versionEndpoint, &versionHandler{cm: cm})
+	mux.Handle(configzEndpoint, &configzHandler{cm: cm})
+	if cm.pprofExpvar {
+		mux.Handle("/debug/vars", expvar.Handler())
+	}
+
+	if cm.pprofFilter != nil {
+		handler, err := cm.pprofFilter(cm.logger, mux)
+		if err != nil {
+			return fmt.Errorf("failed to add filter to the pprof server: %w", err)
+		}
+		srv.Handler = handler
+	}
 
 	return cm.add(&server{
 		Kind:     "pprof",
@@ -406,6 +619,14 @@ func (cm *controllerManager) Start(ctx context.Context) (err error) {
 		}
 	}
 
+	// Wait for the API server if the manager may be started before it's reachable.
+	if cm.apiServer != nil {
+		if err := cm.apiServer.wait(cm.internalCtx, cm.logger); err != nil {
+			// The context was cancelled before the API server was reached.
+			return nil
+		}
+	}
+
 	// Start and wait for caches.
 	if err := cm.runnables.Caches.Start(cm.internalCtx); err != nil {
 		if err != nil {
@@ -435,6 +656,7 @@ func (cm *controllerManager) Start(ctx context.Context) (err error) {
 					cm.errChan <- err
 				}
 				close(cm.elected)
+				metrics.SetLeader(true)
 			}
 		}()
 	}
@@ -533,6 +755,8 @@ func (cm *controllerManager) engageStopProcedure(stopComplete <-chan struct{}) e
 		cm.logger.Info("Stopping and waiting for HTTP servers")
 		cm.runnables.HTTPServers.StopAndWait(cm.shutdownCtx)
 
+		cm.runShutdownHooks(cm.shutdownCtx)
+
 		// Proceed to close the manager and overall shutdown context.
 		cm.logger.Info("Wait completed, proceeding to shutdown the manager")
 		shutdownCancel()
@@ -564,14 +788,32 @@ func (cm *controllerManager) startLeaderElection(ctx context.Context) (err error
 		RenewDeadline: cm.renewDeadline,
 		RetryPeriod:   cm.retryPeriod,
 		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(_ context.Context) {
+			OnStartedLeading: func(leaderCtx context.Context) {
 				if err := cm.startLeaderElectionRunnables(); err != nil {
 					cm.errChan <- err
 					return
 				}
 				close(cm.elected)
+				metrics.SetLeader(true)
+				if cm.leaderCallbacks.OnStartedLeading != nil {
+					cm.leaderCallbacks.OnStartedLeading(leaderCtx)
+				}
 			},
 			OnStoppedLeading: func() {
+				// The callback is also called when the manager stops without ever
+				// having been the leader.
+				select {
+				case <-cm.elected:
+					// Stepping down because the manager stops isn't a loss of the lease.
+					if ctx.Err() == nil {
+						close(cm.leadershipLost)
+					}
+					metrics.SetLeader(false)
+					if cm.leaderCallbacks.OnStoppedLeading != nil {
+						cm.leaderCallbacks.OnStoppedLeading()
+					}
+				default:
+				}
 				if cm.onStoppedLeading != nil {
 					cm.onStoppedLeading()
 				}
@@ -583,6 +825,11 @@ func (cm *controllerManager) startLeaderElection(ctx context.Context) (err error
 				// an error here which will cause the program to exit.
 				cm.errChan <- errors.New("leader election lost")
 			},
+			OnNewLeader: func(identity string) {
+				if cm.leaderCallbacks.OnNewLeader != nil {
+					cm.leaderCallbacks.OnNewLeader(identity)
+				}
+			},
 		},
 		ReleaseOnCancel: cm.leaderElectionReleaseOnCancel,
 		Name:            cm.leaderElectionID,
@@ -603,3 +850,8 @@ func (cm *controllerManager) startLeaderElection(ctx context.Context) (err error
 func (cm *controllerManager) Elected() <-chan struct{} {
 	return cm.elected
 }
+
+// LeadershipLost implements leadershipLostNotifier.
+func (cm *controllerManager) LeadershipLost() <-chan struct{} {
+	return cm.leadershipLost
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	kleaderelection "k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
+	fakeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection/fake"
+)
+
+var _ = Describe("leadership transition callbacks", func() {
+	It("should call the callbacks and close LeadershipLost when the leadership is lost", func() {
+		fakeLock, err := fakeleaderelection.NewResourceLock(nil, nil, leaderelection.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		lock := &failingResourceLock{Interface: fakeLock}
+
+		started := make(chan struct{})
+		stopped := make(chan struct{})
+		var newLeader atomic.Value
+		errChan := make(chan error, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cm := &controllerManager{
+			logger:                logr.Discard(),
+			errChan:               errChan,
+			runnables:             newRunnables(defaultBaseContext, errChan),
+			internalCtx:           ctx,
+			resourceLock:          lock,
+			leaseDuration:         time.Second,
+			renewDeadline:         500 * time.Millisecond,
+			retryPeriod:           100 * time.Millisecond,
+			elected:               make(chan struct{}),
+			leadershipLost:        make(chan struct{}),
+			leaderElectionStopped: make(chan struct{}),
+			onStoppedLeading:      func() {},
+			leaderCallbacks: kleaderelection.LeaderCallbacks{
+				OnStartedLeading: func(context.Context) { close(started) },
+				OnStoppedLeading: func() { close(stopped) },
+				OnNewLeader:      func(identity string) { newLeader.Store(identity) },
+			},
+		}
+
+		Expect(cm.startLeaderElection(ctx)).To(Succeed())
+		Eventually(started).Should(BeClosed())
+		Expect(cm.Elected()).To(BeClosed())
+		Eventually(newLeader.Load).Should(Equal(lock.Identity()))
+		lost, ok := LeadershipLost(cm)
+		Expect(ok).To(BeTrue())
+		Expect(lost).NotTo(BeClosed())
+
+		By("Failing to renew the lease")
+		lock.failing.Store(true)
+		Eventually(stopped).Should(BeClosed())
+		Expect(lost).To(BeClosed())
+		Eventually(errChan).Should(Receive(MatchError("leader election lost")))
+	})
+
+	It("should report managers that don't notify about the loss of their leadership", func() {
+		lost, ok := LeadershipLost(struct{ Manager }{&controllerManager{}})
+		Expect(ok).To(BeFalse())
+		Expect(lost).To(BeNil())
+	})
+
+	It("should not close LeadershipLost when the manager stops", func() {
+		fakeLock, err := fakeleaderelection.NewResourceLock(nil, nil, leaderelection.Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		stopped := make(chan struct{})
+		errChan := make(chan error, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cm := &controllerManager{
+			logger:                logr.Discard(),
+			errChan:               errChan,
+			runnables:             newRunnables(defaultBaseContext, errChan),
+			internalCtx:           ctx,
+			resourceLock:          fakeLock,
+			leaseDuration:         time.Second,
+			renewDeadline:         500 * time.Millisecond,
+			retryPeriod:           100 * time.Millisecond,
+			elected:               make(chan struct{}),
+			leadershipLost:        make(chan struct{}),
+			leaderElectionStopped: make(chan struct{}),
+			onStoppedLeading:      func() {},
+			leaderCallbacks: kleaderelection.LeaderCallbacks{
+				OnStoppedLeading: func() { close(stopped) },
+			},
+		}
+
+		Expect(cm.startLeaderElection(ctx)).To(Succeed())
+		Eventually(cm.Elected()).Should(BeClosed())
+
+		cancel()
+		Eventually(stopped).Should(BeClosed())
+		Expect(cm.LeadershipLost()).NotTo(BeClosed())
+	})
+})
+
+// failingResourceLock is a resourcelock.Interface whose updates fail once failing is set.
+type failingResourceLock struct {
+	resourcelock.Interface
+	failing atomic.Bool
+}
+
+func (l *failingResourceLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if l.failing.Load() {
+		return errors.New("failed to update lock")
+	}
+	return l.Interface.Update(ctx, ler)
+}
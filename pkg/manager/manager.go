@@ -18,8 +18,10 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
 	"reflect"
@@ -32,6 +34,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	kleaderelection "k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
@@ -60,6 +63,7 @@ type Manager interface {
 	// started when Start is called.
 	// Depending on if a Runnable implements LeaderElectionRunnable interface, a Runnable can be run in either
 	// non-leaderelection mode (always running) or leader election mode (managed by leader election if enabled).
+	// See AddWithOptions for adding a component with AddOptions.
 	Add(Runnable) error
 
 	// Elected is closed when this manager is elected leader of a group of
@@ -120,6 +124,13 @@ type Options struct {
 	// Only use a custom NewCache if you know what you are doing.
 	NewCache cache.NewCacheFunc
 
+	// WatchErrorHandler is the default WatchErrorHandler of the informers of the cache,
+	// used unless Cache.InformerWatchErrorHandler is set, e.g.
+	// cache.StopOnPersistentWatchErrors(3, nil) to stop the informers of the kinds the
+	// manager isn't allowed to watch. The controllers watching the kinds of stopped
+	// informers report themselves as degraded in their Status.
+	WatchErrorHandler cache.WatchErrorHandler
+
 	// Client is the client.Options that will be used to create the default Client.
 	// By default, the client will use the cache for reads and direct calls for writes.
 	Client client.Options
@@ -201,6 +212,65 @@ type Options struct {
 	// want to use a locking mechanism that is currently not supported, like a MultiLock across two Kubernetes clusters.
 	LeaderElectionResourceLockInterface resourcelock.Interface
 
+	// LeaderElectionProvider allows to use other backends than the API server for leader
+	// election, e.g. leaderelection.FileProvider to coordinate managers on a single node
+	// that run outside of the target cluster. If set, the options LeaderElectionNamespace,
+	// LeaderElectionResourceLock and LeaderElectionConfig are ignored. It is also used
+	// for the leader election of controllers with their own LeaderElectionID.
+	// LeaderElectionResourceLockInterface takes precedence over it.
+	LeaderElectionProvider LeaderElectionProvider
+
+	// WarmStandby makes managers that are not the leader warm up the Runnables that need
+	// leader election and implement WarmupRunnable, e.g. controllers start their sources
+	// and keep their caches synced without running reconcilers. This reduces the failover
+	// latency to the time it takes to acquire the lease, at the cost of every replica
+	// watching all resources. Only used if leader election is enabled.
+	WarmStandby bool
+
+	// ResilientStart makes the manager wait for the API server to become reachable
+	// when it starts instead of failing, e.g. because the control plane is started
+	// after the manager. The manager retries with backoff before it starts the caches
+	// and the Runnables that depend on them, while health probes, metrics and webhooks
+	// are already served. Until the API server was reached, the "apiserver" readiness
+	// check fails, the liveness checks are not affected.
+	ResilientStart bool
+
+	// ClusterProvider, if set, discovers further clusters at runtime. The manager runs
+	// the provider and engages the Runnables that implement cluster.Aware with the
+	// clusters it discovers. The readiness of each engaged cluster, i.e. whether its
+	// cache is synced and its API server is reachable, is served at
+	// /readyz/cluster/<name>, and the "clusters" readiness check fails according to
+	// ClusterReadinessPolicy.
+	ClusterProvider cluster.Provider
+
+	// Reload configures when and how the manager reloads its runtime configuration, e.g.
+	// the log level and the concurrency of the controllers, while it is running.
+	Reload ReloadOptions
+
+	// ClusterReadinessPolicy decides whether the manager is ready depending on the
+	// readiness of the engaged provider clusters. Defaults to ClusterReadinessAll.
+	ClusterReadinessPolicy ClusterReadinessPolicy
+
+	// ClusterHealth configures the health monitoring of the provider clusters, which
+	// disengages the Runnables from clusters whose API server is unreachable and engages
+	// them again once it recovered. It is disabled by default.
+	ClusterHealth ClusterHealthOptions
+
+	// OnStartedLeading is called when this manager becomes the leader, after the
+	// Runnables that need leader election were started. The context is cancelled
+	// when the leadership is lost. Only used if leader election is enabled.
+	OnStartedLeading func(ctx context.Context)
+
+	// OnStoppedLeading is called when this manager loses the leadership it held,
+	// before the manager stops. It can be used to flush state or flip readiness, but
+	// must not block as the Runnables of the leader are still running.
+	// Only used if leader election is enabled.
+	OnStoppedLeading func()
+
+	// OnNewLeader is called with the identity of the leader whenever a new leader
+	// is observed, including this manager. Only used if leader election is enabled.
+	OnNewLeader func(identity string)
+
 	// LeaseDuration is the duration that non-leader candidates will
 	// wait to force acquire leadership. This is measured against time of
 	// last observed ack. Default is 15 seconds.
@@ -233,13 +303,26 @@ type Options struct {
 	// It can be set to "" or "0" to disable the pprof serving.
 	// Since pprof may contain sensitive information, make sure to protect it
 	// before exposing it to public.
+	//
+	// The pprof server additionally serves the queue snapshots of all controllers
+	// added to the manager as JSON under /debug/controllers/queues. The controller
+	// query parameter can be used to select a single controller.
 	PprofBindAddress string
 
+	// Pprof configures the server serving pprof on PprofBindAddress.
+	Pprof PprofOptions
+
 	// WebhookServer is an externally configured webhook.Server. By default,
 	// a Manager will create a server via webhook.NewServer with default settings.
 	// If this is set, the Manager will use this server instead.
 	WebhookServer webhook.Server
 
+	// WebhookServers are additional webhook servers, keyed by their name. They
+	// allow to serve webhooks on different ports or with different certificates,
+	// e.g. to isolate conversion webhooks from admission webhooks. A server is
+	// only added to the Manager once it is retrieved via GetNamedWebhookServer.
+	WebhookServers map[string]webhook.Server
+
 	// BaseContext is the function that provides Context values to Runnables
 	// managed by the Manager. If a BaseContext function isn't provided, Runnables
 	// will receive a new Background Context instead.
@@ -301,6 +384,155 @@ func (r RunnableFunc) Start(ctx context.Context) error {
 	return r(ctx)
 }
 
+// RunnableHandle is a handle to a Runnable added to a Manager.
+type RunnableHandle interface {
+	// Stop removes the Runnable from the Manager and cancels its context. A Runnable
+	// that was not started yet is never started. Stop waits until the Runnable
+	// returned or ctx is done and returns the error returned by the Runnable, if any.
+	Stop(ctx context.Context) error
+
+	// Done returns a channel that is closed once the Runnable returned, or once it
+	// was removed before it was started.
+	Done() <-chan struct{}
+}
+
+// runnableHandleAdder is implemented by managers that can add Runnables with a handle.
+type runnableHandleAdder interface {
+	AddWithHandle(Runnable, ...AddOption) (RunnableHandle, error)
+}
+
+// AddWithHandle adds r to mgr like Manager.Add and returns a handle that allows stopping
+// and removing r again, e.g. to disable a controller at runtime. AddOptions like After
+// can be used to order the start of r relative to other Runnables. Runnables can be
+// added before and after Start, each Runnable is started with its own context that is
+// cancelled when it is stopped through its handle.
+func AddWithHandle(mgr Manager, r Runnable, opts ...AddOption) (RunnableHandle, error) {
+	adder, ok := mgr.(runnableHandleAdder)
+	if !ok {
+		return nil, fmt.Errorf("manager %T doesn't support adding Runnables with a handle", mgr)
+	}
+	return adder.AddWithHandle(r, opts...)
+}
+
+// leadershipLostNotifier is implemented by managers that report the loss of their
+// leadership.
+type leadershipLostNotifier interface {
+	LeadershipLost() <-chan struct{}
+}
+
+// LeadershipLost returns a channel that is closed when mgr loses the leadership it held,
+// e.g. because the lease could not be renewed in time. It is never closed if leader
+// election is not enabled, if mgr never became the leader or if mgr gives up the
+// leadership because it is stopped.
+//
+// The returned bool is false if mgr doesn't report the loss of its leadership. The
+// channel is nil then and blocks forever, so it must not be mistaken for a leadership
+// that was never lost.
+func LeadershipLost(mgr Manager) (<-chan struct{}, bool) {
+	notifier, ok := mgr.(leadershipLostNotifier)
+	if !ok {
+		return nil, false
+	}
+	return notifier.LeadershipLost(), true
+}
+
+// namedWebhookServerGetter is implemented by managers that serve webhooks on several
+// servers.
+type namedWebhookServerGetter interface {
+	GetNamedWebhookServer(name string) webhook.Server
+}
+
+// GetNamedWebhookServer returns the webhook.Server of mgr configured under the given
+// name in Options.WebhookServers, or nil if there is none. The empty name refers to
+// the server returned by Manager.GetWebhookServer.
+func GetNamedWebhookServer(mgr Manager, name string) webhook.Server {
+	if name == "" {
+		return mgr.GetWebhookServer()
+	}
+	getter, ok := mgr.(namedWebhookServerGetter)
+	if !ok {
+		return nil
+	}
+	return getter.GetNamedWebhookServer(name)
+}
+
+// PprofOptions configures the pprof server of the manager.
+type PprofOptions struct {
+	// EnableExpvar additionally serves the variables published through the expvar
+	// package as JSON under /debug/vars.
+	EnableExpvar bool
+
+	// SecureServing enables serving pprof via https. The certificate has to be
+	// configured through TLSOpts, e.g. using certwatcher.CertWatcher.GetCertificate.
+	SecureServing bool
+
+	// TLSOpts is used to configure the TLS config used when serving via https.
+	TLSOpts []func(*tls.Config)
+
+	// FilterProvider provides a filter which is added around all handlers of the pprof
+	// server, e.g. filters.WithAuthenticationAndAuthorization of the metrics filters
+	// package to protect them with authentication and authorization.
+	FilterProvider func(c *rest.Config, httpClient *http.Client) (metricsserver.Filter, error)
+}
+
+// LeaderElectionProvider creates the resource locks used for leader election, which
+// allows coordinating managers through other backends than the API server.
+type LeaderElectionProvider interface {
+	// NewResourceLock returns the lock for the leader election with the given ID.
+	// Every lock must have a unique identity.
+	NewResourceLock(id string) (resourcelock.Interface, error)
+}
+
+var _ LeaderElectionProvider = &leaderelection.FileProvider{}
+
+// AddOption configures how a Runnable is added to a Manager with AddWithOptions or
+// AddWithHandle.
+type AddOption interface {
+	// ApplyToAdd applies this configuration to the given AddOptions.
+	ApplyToAdd(*AddOptions)
+}
+
+// AddOptions are the options for adding a Runnable to a Manager.
+type AddOptions struct {
+	// After are the Runnables that have to return from Start without an error
+	// before the added Runnable is started. They must have been added to the
+	// Manager before and must be comparable, e.g. pointers. They must not be
+	// started after the added Runnable, e.g. a Runnable that doesn't need leader
+	// election can't depend on a Runnable that needs it. Caches, webhook servers
+	// and HTTP servers can't be depended on as they run until the Manager is stopped.
+	After []Runnable
+}
+
+// ApplyToAdd implements AddOption.
+func (o *AddOptions) ApplyToAdd(target *AddOptions) {
+	target.After = append(target.After, o.After...)
+}
+
+// After returns an AddOption that delays the start of the added Runnable until
+// all of the given Runnables have completed successfully, e.g. to seed data
+// before the controllers relying on it are started. Note that a Runnable that
+// keeps running until the context is cancelled, like a controller, never completes,
+// so Runnables depending on it are never started. Adding a Runnable that depends on
+// a LeaderElectionRunnable, which typically keeps running, logs a warning.
+func After(runnables ...Runnable) AddOption {
+	return &AddOptions{After: runnables}
+}
+
+// optionsAdder is implemented by managers that can add Runnables with AddOptions.
+type optionsAdder interface {
+	AddWithOptions(Runnable, ...AddOption) error
+}
+
+// AddWithOptions adds r to mgr like Manager.Add, configured with opts, e.g. with
+// After to order the start of r relative to other Runnables.
+func AddWithOptions(mgr Manager, r Runnable, opts ...AddOption) error {
+	adder, ok := mgr.(optionsAdder)
+	if !ok {
+		return fmt.Errorf("manager %T doesn't support adding Runnables with options", mgr)
+	}
+	return adder.AddWithOptions(r, opts...)
+}
+
 // LeaderElectionRunnable knows if a Runnable needs to be run in the leader election mode.
 type LeaderElectionRunnable interface {
 	// NeedLeaderElection returns true if the Runnable needs to be run in the leader election mode.
@@ -308,6 +540,27 @@ type LeaderElectionRunnable interface {
 	NeedLeaderElection() bool
 }
 
+// WarmupRunnable knows how to prepare for being started while the manager is not the
+// leader, to reduce the latency of a failover.
+type WarmupRunnable interface {
+	// Warmup is called when the manager starts if warm standby is enabled and the
+	// Runnable needs leader election. It must not block until ctx is cancelled and
+	// should prepare everything that can be done without leadership, e.g. start
+	// watches and wait for caches to sync. Start may be called while Warmup runs.
+	Warmup(ctx context.Context) error
+}
+
+// LeaderElectionIDRunnable is a Runnable that can be run under its own leader election
+// lease instead of the lease of the manager, so that a process can be the leader for
+// some Runnables and a standby for others.
+type LeaderElectionIDRunnable interface {
+	// GetLeaderElectionID returns the name of the resource used for the leader election
+	// of the Runnable. If empty, the Runnable uses the leader election of the manager.
+	// The ID is ignored if the Runnable does not need leader election or leader
+	// election is disabled for the manager.
+	GetLeaderElectionID() string
+}
+
 // New returns a new Manager for creating Controllers.
 // Note that if ContentType in the given config is not set, "application/vnd.kubernetes.protobuf"
 // will be used for all built-in resources of Kubernetes, and "application/json" is for other types
@@ -319,6 +572,23 @@ func New(config *rest.Config, options Options) (Manager, error) {
 	// Set default values for options fields
 	options = setOptionsDefaults(options)
 
+	switch options.ClusterReadinessPolicy {
+	case ClusterReadinessAll, ClusterReadinessQuorum:
+	default:
+		return nil, fmt.Errorf("unknown cluster readiness policy %q", options.ClusterReadinessPolicy)
+	}
+	for name, srv := range options.WebhookServers {
+		if name == "" {
+			return nil, errors.New("webhook servers must have a non-empty name")
+		}
+		if srv == nil {
+			return nil, fmt.Errorf("webhook server %q must not be nil", name)
+		}
+	}
+	if options.Reload.Load == nil && (options.Reload.OnSIGHUP || options.Reload.ConfigMap != nil) {
+		return nil, errors.New("must specify Reload.Load to reload the runtime configuration")
+	}
+
 	cluster, err := cluster.New(config, func(clusterOptions *cluster.Options) {
 		clusterOptions.Scheme = options.Scheme
 		clusterOptions.MapperProvider = options.MapperProvider
@@ -326,6 +596,9 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		clusterOptions.NewCache = options.NewCache
 		clusterOptions.NewClient = options.NewClient
 		clusterOptions.Cache = options.Cache
+		if clusterOptions.Cache.InformerWatchErrorHandler == nil {
+			clusterOptions.Cache.InformerWatchErrorHandler = options.WatchErrorHandler
+		}
 		clusterOptions.Client = options.Client
 		clusterOptions.EventBroadcaster = options.EventBroadcaster //nolint:staticcheck
 	})
@@ -375,9 +648,15 @@ func New(config *rest.Config, options Options) (Manager, error) {
 	}
 
 	var resourceLock resourcelock.Interface
-	if options.LeaderElectionResourceLockInterface != nil && options.LeaderElection {
+	switch {
+	case options.LeaderElectionResourceLockInterface != nil && options.LeaderElection:
 		resourceLock = options.LeaderElectionResourceLockInterface
-	} else {
+	case options.LeaderElectionProvider != nil && options.LeaderElection:
+		resourceLock, err = options.LeaderElectionProvider.NewResourceLock(options.LeaderElectionID)
+		if err != nil {
+			return nil, err
+		}
+	default:
 		resourceLock, err = options.newResourceLock(leaderConfig, leaderRecorderProvider, leaderelection.Options{
 			LeaderElection:             options.LeaderElection,
 			LeaderElectionResourceLock: options.LeaderElectionResourceLock,
@@ -389,6 +668,21 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		}
 	}
 
+	var newRunnableResourceLock func(string) (resourcelock.Interface, error)
+	switch {
+	case options.LeaderElectionProvider != nil && options.LeaderElection:
+		newRunnableResourceLock = options.LeaderElectionProvider.NewResourceLock
+	case options.LeaderElection:
+		newRunnableResourceLock = func(id string) (resourcelock.Interface, error) {
+			return options.newResourceLock(leaderConfig, leaderRecorderProvider, leaderelection.Options{
+				LeaderElection:             true,
+				LeaderElectionResourceLock: options.LeaderElectionResourceLock,
+				LeaderElectionID:           id,
+				LeaderElectionNamespace:    options.LeaderElectionNamespace,
+			})
+		}
+	}
+
 	// Create the metrics server.
 	metricsServer, err := options.newMetricsServer(options.Metrics, config, cluster.GetHTTPClient())
 	if err != nil {
@@ -402,40 +696,106 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		return nil, err
 	}
 
+	var pprofFilter metricsserver.Filter
+	if options.Pprof.FilterProvider != nil {
+		pprofFilter, err = options.Pprof.FilterProvider(config, cluster.GetHTTPClient())
+		if err != nil {
+			return nil, fmt.Errorf("filter provider failed to create filter for the pprof server: %w", err)
+		}
+	}
+
 	// Create pprof listener. This will throw an error if the bind
 	// address is invalid or already in use.
 	pprofListener, err := options.newPprofListener(options.PprofBindAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to new pprof listener: %w", err)
 	}
+	if pprofListener != nil && options.Pprof.SecureServing {
+		cfg := &tls.Config{} //nolint:gosec
+		for _, op := range options.Pprof.TLSOpts {
+			op(cfg)
+		}
+		if cfg.GetCertificate == nil && len(cfg.Certificates) == 0 {
+			pprofListener.Close()
+			return nil, errors.New("pprof server requires a certificate configured through Pprof.TLSOpts to serve securely")
+		}
+		pprofListener = tls.NewListener(pprofListener, cfg)
+	}
+
+	var apiServer *apiServerReachability
+	var readyzHandler *healthz.Handler
+	if options.ResilientStart {
+		apiServer, err = newAPIServerReachability(config, cluster.GetHTTPClient())
+		if err != nil {
+			return nil, err
+		}
+		readyzHandler = &healthz.Handler{Checks: map[string]healthz.Checker{apiServerCheckName: apiServer.Check}}
+	}
 
 	errChan := make(chan error, 1)
 	runnables := newRunnables(options.BaseContext, errChan)
-	return &controllerManager{
-		stopProcedureEngaged:          ptr.To(int64(0)),
-		cluster:                       cluster,
-		runnables:                     runnables,
-		errChan:                       errChan,
-		recorderProvider:              recorderProvider,
-		resourceLock:                  resourceLock,
-		metricsServer:                 metricsServer,
-		controllerConfig:              options.Controller,
-		logger:                        options.Logger,
-		elected:                       make(chan struct{}),
+	cm := &controllerManager{
+		stopProcedureEngaged:    ptr.To(int64(0)),
+		cluster:                 cluster,
+		runnables:               runnables,
+		errChan:                 errChan,
+		recorderProvider:        recorderProvider,
+		resourceLock:            resourceLock,
+		newRunnableResourceLock: newRunnableResourceLock,
+		metricsServer:           metricsServer,
+		controllerConfig:        options.Controller,
+		cacheConfig:             newCacheConfig(options.Cache, cluster.GetScheme()),
+		logger:                  options.Logger,
+		elected:                 make(chan struct{}),
+		leadershipLost:          make(chan struct{}),
+		leaderCallbacks: kleaderelection.LeaderCallbacks{
+			OnStartedLeading: options.OnStartedLeading,
+			OnStoppedLeading: options.OnStoppedLeading,
+			OnNewLeader:      options.OnNewLeader,
+		},
 		webhookServer:                 options.WebhookServer,
+		webhookServers:                maps.Clone(options.WebhookServers),
+		webhookServersAdded:           make(map[string]bool),
 		leaderElectionID:              options.LeaderElectionID,
 		leaseDuration:                 *options.LeaseDuration,
 		renewDeadline:                 *options.RenewDeadline,
 		retryPeriod:                   *options.RetryPeriod,
 		healthProbeListener:           healthProbeListener,
+		readyzHandler:                 readyzHandler,
+		apiServer:                     apiServer,
+		warmStandby:                   options.WarmStandby,
 		readinessEndpointName:         options.ReadinessEndpointName,
 		livenessEndpointName:          options.LivenessEndpointName,
 		pprofListener:                 pprofListener,
+		pprofFilter:                   pprofFilter,
+		pprofExpvar:                   options.Pprof.EnableExpvar,
 		gracefulShutdownTimeout:       *options.GracefulShutdownTimeout,
 		internalProceduresStop:        make(chan struct{}),
 		leaderElectionStopped:         make(chan struct{}),
 		leaderElectionReleaseOnCancel: options.LeaderElectionReleaseOnCancel,
-	}, nil
+		clusterProvider:               options.ClusterProvider,
+		clusterReadinessPolicy:        options.ClusterReadinessPolicy,
+		clusterHealth:                 options.ClusterHealth,
+		clusters:                      map[string]*engagedCluster{},
+		reload:                        options.Reload,
+	}
+
+	if options.Reload.OnSIGHUP || options.Reload.ConfigMap != nil {
+		if err := cm.add(newReloader(cm)); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.ClusterProvider != nil {
+		if cm.readyzHandler == nil {
+			cm.readyzHandler = &healthz.Handler{Checks: map[string]healthz.Checker{}}
+		}
+		cm.readyzHandler.Checks[clustersCheckName] = cm.checkClusters
+		if err := cm.add(&clusterProviderRunnable{provider: options.ClusterProvider, aware: cm}); err != nil {
+			return nil, err
+		}
+	}
+	return cm, nil
 }
 
 // AndFrom will use a supplied type and convert to Options
@@ -592,6 +952,10 @@ func defaultBaseContext() context.Context {
 
 // setOptionsDefaults set default values for Options fields.
 func setOptionsDefaults(options Options) Options {
+	if options.ClusterReadinessPolicy == "" {
+		options.ClusterReadinessPolicy = ClusterReadinessAll
+	}
+
 	// Allow newResourceLock to be mocked
 	if options.newResourceLock == nil {
 		options.newResourceLock = leaderelection.NewResourceLock
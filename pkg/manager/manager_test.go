@@ -19,6 +19,7 @@ package manager
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -48,6 +49,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+	intcontroller "sigs.k8s.io/controller-runtime/pkg/internal/controller"
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
 	fakeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection/fake"
@@ -1648,6 +1650,84 @@ var _ = Describe("manger.Manager", func() {
 			defer resp.Body.Close()
 			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 		})
+		It("should serve controller queue snapshots", func() {
+			opts.PprofBindAddress = ":0"
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.Add(&fakeQueueSnapshotter{snapshot: intcontroller.QueueSnapshot{
+				Controller: "fake",
+				Items:      []intcontroller.QueueItem{{Retries: 3}},
+			}})).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+			}()
+			<-m.Elected()
+
+			queuesEndpoint := fmt.Sprintf("http://%s/debug/controllers/queues?controller=fake", listener.Addr().String())
+			resp, err := http.Get(queuesEndpoint)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var snapshots []intcontroller.QueueSnapshot
+			Expect(json.NewDecoder(resp.Body).Decode(&snapshots)).To(Succeed())
+			Expect(snapshots).To(HaveLen(1))
+			Expect(snapshots[0].Controller).To(Equal("fake"))
+			Expect(snapshots[0].Items[0].Retries).To(Equal(3))
+		})
+
+		It("should serve expvar behind the filter", func() {
+			opts.PprofBindAddress = ":0"
+			opts.Pprof = PprofOptions{
+				EnableExpvar: true,
+				FilterProvider: func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+					return func(_ logr.Logger, handler http.Handler) (http.Handler, error) {
+						return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+							if req.Header.Get("Authorization") == "" {
+								w.WriteHeader(http.StatusUnauthorized)
+								return
+							}
+							handler.ServeHTTP(w, req)
+						}), nil
+					}, nil
+				},
+			}
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+			}()
+			<-m.Elected()
+
+			expvarEndpoint := fmt.Sprintf("http://%s/debug/vars", listener.Addr().String())
+			resp, err := http.Get(expvarEndpoint)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+			req, err := http.NewRequest(http.MethodGet, expvarEndpoint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer token")
+			resp, err = http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should fail to serve securely without a certificate", func() {
+			opts.PprofBindAddress = ":0"
+			opts.Pprof.SecureServing = true
+			_, err := New(cfg, opts)
+			Expect(err).To(MatchError(ContainSubstring("requires a certificate")))
+		})
 	})
 
 	Describe("Add", func() {
@@ -1929,3 +2009,16 @@ func (f *fakeDeferredLoader) InjectScheme(scheme *runtime.Scheme) error {
 type metricsDefaultServer interface {
 	GetBindAddr() string
 }
+
+type fakeQueueSnapshotter struct {
+	snapshot intcontroller.QueueSnapshot
+}
+
+func (f *fakeQueueSnapshotter) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (f *fakeQueueSnapshotter) QueueSnapshot() intcontroller.QueueSnapshot {
+	return f.snapshot
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	clustersCheckName = "clusters"
+	clusterReadyzPath = "/cluster"
+)
+
+// ClusterReadinessPolicy decides whether the manager is ready depending on the
+// readiness of the engaged provider clusters.
+type ClusterReadinessPolicy string
+
+const (
+	// ClusterReadinessAll makes the manager ready only if all engaged clusters are ready.
+	ClusterReadinessAll ClusterReadinessPolicy = "All"
+
+	// ClusterReadinessQuorum makes the manager ready if more than half of the engaged
+	// clusters are ready.
+	ClusterReadinessQuorum ClusterReadinessPolicy = "Quorum"
+)
+
+// engagedCluster is a provider cluster engaged with the manager.
+type engagedCluster struct {
+	cluster.Cluster
+
+	// ctx is done once the cluster is disengaged.
+	ctx context.Context
+
+	// synced is set once the cache of the cluster is synced.
+	synced atomic.Bool
+
+	// probe checks whether the API server of the cluster is reachable.
+	probe func(ctx context.Context) error
+
+	// runnablesCtx is the context the Runnables are engaged with, disengageRunnables
+	// cancels it. Both are guarded by clustersLock.
+	runnablesCtx       context.Context
+	disengageRunnables context.CancelFunc
+
+	// unhealthy is set while the Runnables are disengaged because the health
+	// monitoring found the cluster unhealthy, see ClusterHealthOptions.
+	unhealthy atomic.Bool
+}
+
+// check implements healthz.Checker. A cluster is ready if its cache is synced and
+// its API server is reachable.
+func (c *engagedCluster) check(req *http.Request) error {
+	if c.unhealthy.Load() {
+		return errors.New("cluster is unhealthy, runnables are disengaged")
+	}
+	if !c.synced.Load() {
+		return errors.New("cache is not synced")
+	}
+	if err := c.probe(req.Context()); err != nil {
+		return fmt.Errorf("API server is not reachable: %w", err)
+	}
+	return nil
+}
+
+// Engage implements cluster.Aware. It engages the given provider cluster with all
+// runnables that implement cluster.Aware, and reports its readiness at
+// /readyz/cluster/<name>.
+func (cm *controllerManager) Engage(ctx context.Context, name string, cl cluster.Cluster) error {
+	reachability, err := newAPIServerReachability(cl.GetConfig(), cl.GetHTTPClient())
+	if err != nil {
+		return fmt.Errorf("failed to engage cluster %q: %w", name, err)
+	}
+
+	// Cancelling ctx on failure disengages the runnables that were already engaged.
+	ctx, cancel := context.WithCancel(ctx)
+	engaged := &engagedCluster{Cluster: cl, ctx: ctx, probe: reachability.probe}
+
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+
+	if existing, ok := cm.clusters[name]; ok && existing.ctx.Err() == nil {
+		cancel()
+		return fmt.Errorf("cluster %q is already engaged", name)
+	}
+	if err := cm.engageRunnablesLocked(name, engaged); err != nil {
+		cancel()
+		return err
+	}
+	cm.clusters[name] = engaged
+
+	go func() {
+		if cl.GetCache().WaitForCacheSync(ctx) {
+			engaged.synced.Store(true)
+		}
+	}()
+	if cm.clusterHealth.ProbeInterval > 0 {
+		go cm.monitorClusterHealth(name, engaged)
+	}
+	go func() {
+		<-ctx.Done()
+		cancel()
+		cm.clustersLock.Lock()
+		defer cm.clustersLock.Unlock()
+		if cm.clusters[name] == engaged {
+			delete(cm.clusters, name)
+			metrics.DeleteClusterMetrics(name)
+		}
+	}()
+	return nil
+}
+
+// engageRunnablesLocked engages all Runnables that implement cluster.Aware with the
+// given cluster. If one of them fails, the ones that were already engaged are
+// disengaged again. clustersLock must be held.
+func (cm *controllerManager) engageRunnablesLocked(name string, engaged *engagedCluster) error {
+	ctx, cancel := context.WithCancel(engaged.ctx)
+	for _, aware := range cm.clusterAwareRunnables {
+		if err := aware.Engage(ctx, name, engaged.Cluster); err != nil {
+			cancel()
+			return fmt.Errorf("failed to engage cluster %q: %w", name, err)
+		}
+	}
+	engaged.runnablesCtx, engaged.disengageRunnables = ctx, cancel
+	return nil
+}
+
+// clusterGetter is implemented by managers that know the clusters of a cluster provider.
+type clusterGetter interface {
+	GetCluster(ctx context.Context, name string) (cluster.Cluster, error)
+}
+
+// GetCluster returns the cluster of mgr with the given name. The empty name refers to
+// mgr itself, any other name to a cluster of the ClusterProvider.
+func GetCluster(ctx context.Context, mgr Manager, name string) (cluster.Cluster, error) {
+	if name == "" {
+		return mgr, nil
+	}
+	getter, ok := mgr.(clusterGetter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q, manager %T doesn't support a cluster provider", cluster.ErrClusterNotFound, name, mgr)
+	}
+	return getter.GetCluster(ctx, name)
+}
+
+// GetCluster returns the cluster with the given name. The empty name refers to the
+// cluster of the manager, any other name to a cluster of the cluster provider.
+func (cm *controllerManager) GetCluster(ctx context.Context, name string) (cluster.Cluster, error) {
+	if name == "" {
+		return cm.cluster, nil
+	}
+	if cm.clusterProvider == nil {
+		return nil, fmt.Errorf("%w: %q, no cluster provider is configured", cluster.ErrClusterNotFound, name)
+	}
+	return cm.clusterProvider.Get(ctx, name)
+}
+
+// addClusterAware engages the already engaged clusters with the given runnable and
+// engages it with the clusters engaged later on.
+func (cm *controllerManager) addClusterAware(aware cluster.Aware) error {
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+
+	for name, engaged := range cm.clusters {
+		// The Runnables are disengaged from unhealthy clusters.
+		if engaged.runnablesCtx.Err() != nil {
+			continue
+		}
+		if err := aware.Engage(engaged.runnablesCtx, name, engaged.Cluster); err != nil {
+			return fmt.Errorf("failed to engage cluster %q: %w", name, err)
+		}
+	}
+	cm.clusterAwareRunnables = append(cm.clusterAwareRunnables, aware)
+	return nil
+}
+
+// removeClusterAware stops engaging clusters with the given runnable.
+func (cm *controllerManager) removeClusterAware(aware cluster.Aware) {
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+	cm.clusterAwareRunnables = removeDebugTarget(cm.clusterAwareRunnables, aware)
+}
+
+// clusterChecks returns the readiness checks of the engaged clusters by name.
+func (cm *controllerManager) clusterChecks() map[string]healthz.Checker {
+	cm.clustersLock.Lock()
+	defer cm.clustersLock.Unlock()
+
+	checks := make(map[string]healthz.Checker, len(cm.clusters))
+	for name, engaged := range cm.clusters {
+		if engaged.ctx.Err() == nil {
+			checks[name] = engaged.check
+		}
+	}
+	return checks
+}
+
+// checkClusters implements healthz.Checker. It applies the cluster readiness policy
+// to the readiness of the engaged clusters.
+func (cm *controllerManager) checkClusters(req *http.Request) error {
+	checks := cm.clusterChecks()
+	var unready []string
+	for name, check := range checks {
+		if err := check(req); err != nil {
+			unready = append(unready, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(unready) == 0 {
+		return nil
+	}
+	sort.Strings(unready)
+
+	ready := len(checks) - len(unready)
+	if cm.clusterReadinessPolicy == ClusterReadinessQuorum {
+		if 2*ready > len(checks) {
+			return nil
+		}
+		return fmt.Errorf("%d of %d clusters are ready, which is no quorum: %s", ready, len(checks), strings.Join(unready, "; "))
+	}
+	return fmt.Errorf("%d of %d clusters are not ready: %s", len(unready), len(checks), strings.Join(unready, "; "))
+}
+
+// clusterReadyzHandler serves the readiness of the individual engaged clusters.
+type clusterReadyzHandler struct {
+	cm *controllerManager
+}
+
+func (h *clusterReadyzHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	(&healthz.Handler{Checks: h.cm.clusterChecks()}).ServeHTTP(resp, req)
+}
+
+// clusterProviderRunnable runs the cluster provider of the manager. It runs on all
+// replicas, as runnables decide on their own whether they need leader election.
+type clusterProviderRunnable struct {
+	provider cluster.Provider
+	aware    cluster.Aware
+}
+
+func (r *clusterProviderRunnable) Start(ctx context.Context) error {
+	return r.provider.Run(ctx, r.aware)
+}
+
+func (r *clusterProviderRunnable) NeedLeaderElection() bool {
+	return false
+}
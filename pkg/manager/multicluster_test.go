@@ -0,0 +1,275 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+var _ = Describe("cluster engagement", func() {
+	var (
+		cm        *controllerManager
+		apiServer *httptest.Server
+		healthy   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		errChan := make(chan error, 1)
+		cm = &controllerManager{
+			logger:                 logr.Discard(),
+			errChan:                errChan,
+			runnables:              newRunnables(defaultBaseContext, errChan),
+			clusterProvider:        &fakeClusterProvider{},
+			clusterReadinessPolicy: ClusterReadinessAll,
+			clusters:               map[string]*engagedCluster{},
+		}
+		healthy = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			resp.WriteHeader(http.StatusOK)
+		}))
+		apiServer = healthy
+	})
+
+	AfterEach(func() {
+		healthy.Close()
+		if apiServer != healthy {
+			apiServer.Close()
+		}
+	})
+
+	newCluster := func(synced bool) cluster.Cluster {
+		return &fakeCluster{config: &rest.Config{Host: apiServer.URL}, cache: &fakeSyncCache{synced: synced}}
+	}
+
+	It("should engage the cluster aware runnables until the cluster is disengaged", func() {
+		before := &fakeClusterAware{}
+		Expect(cm.Add(before)).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(cm.Engage(ctx, "member", newCluster(true))).To(Succeed())
+		Expect(cm.Engage(ctx, "member", newCluster(true))).NotTo(Succeed())
+
+		after := &fakeClusterAware{}
+		Expect(cm.Add(after)).To(Succeed())
+		Expect(before.engagedClusters()).To(ConsistOf("member"))
+		Expect(after.engagedClusters()).To(ConsistOf("member"))
+		Expect(cm.clusterChecks()).To(HaveKey("member"))
+
+		cancel()
+		Eventually(cm.clusterChecks).Should(BeEmpty())
+		Expect(cm.Engage(context.Background(), "member", newCluster(true))).To(Succeed())
+	})
+
+	It("should disengage the runnables that were engaged if engaging fails", func() {
+		first := &fakeClusterAware{}
+		Expect(cm.Add(first)).To(Succeed())
+		Expect(cm.Add(&fakeClusterAware{err: errors.New("boom")})).To(Succeed())
+
+		err := cm.Engage(context.Background(), "member", newCluster(true))
+		Expect(err).To(MatchError(ContainSubstring("boom")))
+		Expect(cm.clusterChecks()).To(BeEmpty())
+		Eventually(func() error { return first.contexts()[0].Err() }).Should(MatchError(context.Canceled))
+	})
+
+	It("should serve the readiness of the individual clusters", func() {
+		Expect(cm.Engage(context.Background(), "synced", newCluster(true))).To(Succeed())
+		Expect(cm.Engage(context.Background(), "unsynced", newCluster(false))).To(Succeed())
+
+		handler := &clusterReadyzHandler{cm: cm}
+		rec := httptest.NewRecorder()
+		Eventually(func() int {
+			rec = httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/synced", nil))
+			return rec.Code
+		}).Should(Equal(http.StatusOK))
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unsynced", nil))
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(rec.Body.String()).To(ContainSubstring("cache is not synced"))
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should apply the cluster readiness policy", func() {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		Expect(cm.checkClusters(req)).To(Succeed())
+
+		Expect(cm.Engage(context.Background(), "a", newCluster(true))).To(Succeed())
+		Expect(cm.Engage(context.Background(), "b", newCluster(true))).To(Succeed())
+		apiServer = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		Expect(cm.Engage(context.Background(), "c", newCluster(true))).To(Succeed())
+
+		Eventually(func() error { return cm.checkClusters(req) }).Should(MatchError(And(
+			ContainSubstring("1 of 3 clusters are not ready"),
+			ContainSubstring("c: API server is not reachable"),
+		)))
+
+		cm.clusterReadinessPolicy = ClusterReadinessQuorum
+		Expect(cm.checkClusters(req)).To(Succeed())
+	})
+
+	It("should disengage the runnables from unhealthy clusters until they recover", func() {
+		var failing atomic.Bool
+		apiServer = httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, _ *http.Request) {
+			if failing.Load() {
+				resp.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			resp.WriteHeader(http.StatusOK)
+		}))
+		recorder := record.NewFakeRecorder(10)
+		cm.cluster = &fakeCluster{recorder: recorder}
+		cm.clusterHealth = ClusterHealthOptions{
+			ProbeInterval:    10 * time.Millisecond,
+			ProbeTimeout:     time.Second,
+			FailureThreshold: 2,
+			EventObject:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "manager"}},
+		}
+		before := &fakeClusterAware{}
+		Expect(cm.Add(before)).To(Succeed())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(cm.Engage(ctx, "member", newCluster(true))).To(Succeed())
+
+		By("disengaging the runnables once the cluster is unhealthy")
+		failing.Store(true)
+		Eventually(func() error { return before.contexts()[0].Err() }).Should(MatchError(context.Canceled))
+		Expect(ctx.Err()).NotTo(HaveOccurred())
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		Expect(cm.checkClusters(req)).To(MatchError(ContainSubstring("cluster is unhealthy")))
+		after := &fakeClusterAware{}
+		Expect(cm.Add(after)).To(Succeed())
+		Expect(after.engagedClusters()).To(BeEmpty())
+		Eventually(recorder.Events).Should(Receive(HavePrefix("Warning ClusterUnhealthy Cluster member is unhealthy")))
+
+		By("engaging the runnables again once the cluster recovered")
+		failing.Store(false)
+		Eventually(before.engagedClusters).Should(Equal([]string{"member", "member"}))
+		Expect(before.contexts()[1].Err()).NotTo(HaveOccurred())
+		Expect(after.engagedClusters()).To(ConsistOf("member"))
+		Eventually(func() error { return cm.checkClusters(req) }).Should(Succeed())
+		Eventually(recorder.Events).Should(Receive(Equal("Normal ClusterHealthy Cluster member recovered, engaged runnables")))
+	})
+
+	It("should return the clusters by name", func() {
+		cm.clusterProvider = nil
+		_, err := cm.GetCluster(context.Background(), "member")
+		Expect(err).To(MatchError(cluster.ErrClusterNotFound))
+
+		member := newCluster(true)
+		cm.clusterProvider = &fakeClusterProvider{clusters: map[string]cluster.Cluster{"member": member}}
+		Expect(GetCluster(context.Background(), cm, "member")).To(BeIdenticalTo(member))
+		Expect(GetCluster(context.Background(), cm, "")).To(BeIdenticalTo(cm))
+	})
+})
+
+type fakeCluster struct {
+	cluster.Cluster
+	config   *rest.Config
+	cache    cache.Cache
+	recorder record.EventRecorder
+}
+
+func (c *fakeCluster) GetConfig() *rest.Config     { return c.config }
+func (c *fakeCluster) GetHTTPClient() *http.Client { return http.DefaultClient }
+func (c *fakeCluster) GetCache() cache.Cache       { return c.cache }
+func (c *fakeCluster) GetEventRecorderFor(string) record.EventRecorder {
+	return c.recorder
+}
+
+type fakeSyncCache struct {
+	cache.Cache
+	synced bool
+}
+
+func (c *fakeSyncCache) WaitForCacheSync(context.Context) bool {
+	return c.synced
+}
+
+type fakeClusterProvider struct {
+	clusters map[string]cluster.Cluster
+}
+
+func (p *fakeClusterProvider) Get(_ context.Context, name string) (cluster.Cluster, error) {
+	if cl, ok := p.clusters[name]; ok {
+		return cl, nil
+	}
+	return nil, cluster.ErrClusterNotFound
+}
+
+func (p *fakeClusterProvider) Run(ctx context.Context, _ cluster.Aware) error {
+	<-ctx.Done()
+	return nil
+}
+
+type fakeClusterAware struct {
+	err error
+
+	mu      sync.Mutex
+	engaged []string
+	ctxs    []context.Context
+}
+
+func (a *fakeClusterAware) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (a *fakeClusterAware) Engage(ctx context.Context, name string, _ cluster.Cluster) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.engaged = append(a.engaged, name)
+	a.ctxs = append(a.ctxs, ctx)
+	return nil
+}
+
+func (a *fakeClusterAware) engagedClusters() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.engaged...)
+}
+
+func (a *fakeClusterAware) contexts() []context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]context.Context(nil), a.ctxs...)
+}
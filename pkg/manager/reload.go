@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+)
+
+// Reloadable is implemented by Runnables that re-apply the runtime configuration when
+// the manager reloads it, e.g. the controllers, which apply their GroupKindConcurrency
+// and the overall RateLimit.
+type Reloadable interface {
+	// Reload applies the given runtime configuration.
+	Reload(ctx context.Context, cfg config.Runtime) error
+}
+
+// ReloadOptions configure when and how the manager reloads its runtime configuration.
+type ReloadOptions struct {
+	// Load returns the runtime configuration, e.g. read from a file or a ConfigMap.
+	// Reloading is disabled if it is unset.
+	Load func(ctx context.Context) (config.Runtime, error)
+
+	// OnSIGHUP makes the manager reload the runtime configuration whenever the
+	// process receives SIGHUP.
+	OnSIGHUP bool
+
+	// ConfigMap, if set, makes the manager reload the runtime configuration whenever
+	// the ConfigMap with this key is created or changed. Load is expected to read it.
+	ConfigMap *client.ObjectKey
+
+	// SetLogLevel, if set, applies the LogLevel of the runtime configuration, e.g. to
+	// the zap.AtomicLevel of the logger.
+	SetLogLevel func(level int)
+}
+
+// configReloader is implemented by managers that reload their runtime configuration.
+type configReloader interface {
+	Reload(ctx context.Context) error
+}
+
+// Reload loads the runtime configuration with the Load func of the reload options of
+// mgr and applies it to all Runnables that implement Reloadable, e.g. the controllers.
+func Reload(ctx context.Context, mgr Manager) error {
+	r, ok := mgr.(configReloader)
+	if !ok {
+		return fmt.Errorf("manager %T doesn't support reloading its runtime configuration", mgr)
+	}
+	return r.Reload(ctx)
+}
+
+// Reload loads the runtime configuration and applies it to all Runnables that
+// implement Reloadable. Reloads are serialized.
+func (cm *controllerManager) Reload(ctx context.Context) error {
+	if cm.reload.Load == nil {
+		return errors.New("no runtime configuration loader is configured")
+	}
+
+	cm.reloadLock.Lock()
+	defer cm.reloadLock.Unlock()
+
+	cfg, err := cm.reload.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load runtime configuration: %w", err)
+	}
+	if cfg.LogLevel != nil && cm.reload.SetLogLevel != nil {
+		cm.reload.SetLogLevel(*cfg.LogLevel)
+	}
+
+	cm.reloadablesLock.Lock()
+	reloadables := append([]Reloadable(nil), cm.reloadables...)
+	cm.reloadablesLock.Unlock()
+
+	var errs []error
+	for _, r := range reloadables {
+		if err := r.Reload(ctx, cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := kerrors.NewAggregate(errs); err != nil {
+		return fmt.Errorf("failed to apply runtime configuration: %w", err)
+	}
+	cm.logger.Info("Reloaded runtime configuration")
+	return nil
+}
+
+// removeReloadable stops applying the runtime configuration to the given Runnable.
+func (cm *controllerManager) removeReloadable(r Reloadable) {
+	cm.reloadablesLock.Lock()
+	defer cm.reloadablesLock.Unlock()
+	cm.reloadables = removeDebugTarget(cm.reloadables, r)
+}
+
+// reloader reloads the runtime configuration of the manager on SIGHUP or whenever the
+// configured ConfigMap changes.
+type reloader struct {
+	cm *controllerManager
+
+	// signals receives SIGHUP. It is set up when the reloader starts, unless it is set.
+	signals <-chan os.Signal
+
+	// configMapInformer, if set, returns the informer of the ConfigMap.
+	configMapInformer func() (toolscache.SharedIndexInformer, error)
+}
+
+func newReloader(cm *controllerManager) *reloader {
+	r := &reloader{cm: cm}
+	if key := cm.reload.ConfigMap; key != nil {
+		r.configMapInformer = func() (toolscache.SharedIndexInformer, error) {
+			coreClient, err := corev1client.NewForConfigAndClient(cm.cluster.GetConfig(), cm.cluster.GetHTTPClient())
+			if err != nil {
+				return nil, err
+			}
+			lw := toolscache.NewListWatchFromClient(coreClient.RESTClient(), "configmaps", key.Namespace,
+				fields.OneTermEqualSelector("metadata.name", key.Name))
+			return toolscache.NewSharedIndexInformer(lw, &corev1.ConfigMap{}, 0, toolscache.Indexers{}), nil
+		}
+	}
+	return r
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, all replicas reload.
+func (r *reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start reloads the runtime configuration whenever it is triggered until ctx is done.
+func (r *reloader) Start(ctx context.Context) error {
+	triggers := make(chan struct{}, 1)
+	trigger := func() {
+		select {
+		case triggers <- struct{}{}:
+		default:
+		}
+	}
+
+	signals := r.signals
+	if signals == nil && r.cm.reload.OnSIGHUP {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+		defer signal.Stop(ch)
+		signals = ch
+	}
+
+	if r.configMapInformer != nil {
+		informer, err := r.configMapInformer()
+		if err != nil {
+			return fmt.Errorf("failed to watch runtime configuration ConfigMap: %w", err)
+		}
+		if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerDetailedFuncs{
+			// The ConfigMap existing when the manager starts isn't a change.
+			AddFunc: func(_ interface{}, isInInitialList bool) {
+				if !isInInitialList {
+					trigger()
+				}
+			},
+			UpdateFunc: func(_, _ interface{}) { trigger() },
+		}); err != nil {
+			return err
+		}
+		go informer.Run(ctx.Done())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			r.cm.logger.Info("Received SIGHUP, reloading runtime configuration")
+			trigger()
+		case <-triggers:
+			if err := r.cm.Reload(ctx); err != nil {
+				r.cm.logger.Error(err, "Failed to reload runtime configuration")
+			}
+		}
+	}
+}
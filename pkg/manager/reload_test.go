@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/config"
+)
+
+var _ = Describe("runtime configuration reload", func() {
+	var (
+		cm       *controllerManager
+		mu       sync.Mutex
+		loads    int
+		loadErr  error
+		logLevel int
+	)
+
+	BeforeEach(func() {
+		loads, loadErr, logLevel = 0, nil, 0
+		errChan := make(chan error, 1)
+		cm = &controllerManager{
+			logger:    logr.Discard(),
+			errChan:   errChan,
+			runnables: newRunnables(defaultBaseContext, errChan),
+			reload: ReloadOptions{
+				Load: func(context.Context) (config.Runtime, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					loads++
+					return config.Runtime{
+						LogLevel:             ptr.To(loads),
+						GroupKindConcurrency: map[string]int{"Deployment.apps": loads},
+					}, loadErr
+				},
+				SetLogLevel: func(level int) {
+					mu.Lock()
+					defer mu.Unlock()
+					logLevel = level
+				},
+			},
+		}
+	})
+
+	loaded := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return loads
+	}
+
+	It("should apply the runtime configuration to the reloadable runnables", func() {
+		first := &fakeReloadable{}
+		Expect(cm.Add(first)).To(Succeed())
+		second := &fakeReloadable{}
+		handle, err := cm.AddWithHandle(second)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(cm.Reload(context.Background())).To(Succeed())
+		Expect(logLevel).To(Equal(1))
+		Expect(first.reloaded()).To(Equal([]config.Runtime{{LogLevel: ptr.To(1), GroupKindConcurrency: map[string]int{"Deployment.apps": 1}}}))
+		Expect(second.reloaded()).To(HaveLen(1))
+
+		Expect(handle.Stop(context.Background())).To(Succeed())
+		Expect(cm.Reload(context.Background())).To(Succeed())
+		Expect(first.reloaded()).To(HaveLen(2))
+		Expect(second.reloaded()).To(HaveLen(1))
+	})
+
+	It("should return the errors of loading and applying the runtime configuration", func() {
+		Expect(cm.Add(&fakeReloadable{err: errors.New("boom")})).To(Succeed())
+		Expect(cm.Reload(context.Background())).To(MatchError(ContainSubstring("boom")))
+
+		loadErr = errors.New("no config")
+		Expect(cm.Reload(context.Background())).To(MatchError(ContainSubstring("no config")))
+
+		cm.reload.Load = nil
+		Expect(cm.Reload(context.Background())).NotTo(Succeed())
+	})
+
+	It("should reload on SIGHUP", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		signals := make(chan os.Signal, 1)
+		r := newReloader(cm)
+		r.signals = signals
+		go func() {
+			defer GinkgoRecover()
+			Expect(r.Start(ctx)).To(Succeed())
+		}()
+
+		signals <- syscall.SIGHUP
+		Eventually(loaded).Should(Equal(1))
+	})
+
+	It("should reload when the ConfigMap changes", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		watcher := watch.NewFake()
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "config", ResourceVersion: "1"}}
+		r := newReloader(cm)
+		r.configMapInformer = func() (toolscache.SharedIndexInformer, error) {
+			lw := &toolscache.ListWatch{
+				ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+					return &corev1.ConfigMapList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}, Items: []corev1.ConfigMap{*configMap}}, nil
+				},
+				WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+					return watcher, nil
+				},
+			}
+			return toolscache.NewSharedIndexInformer(lw, &corev1.ConfigMap{}, 0, toolscache.Indexers{}), nil
+		}
+		go func() {
+			defer GinkgoRecover()
+			Expect(r.Start(ctx)).To(Succeed())
+		}()
+
+		Consistently(loaded).Should(BeZero())
+		changed := configMap.DeepCopy()
+		changed.ResourceVersion = "2"
+		changed.Data = map[string]string{"logLevel": "2"}
+		watcher.Modify(changed)
+		Eventually(loaded).Should(Equal(1))
+	})
+})
+
+type fakeReloadable struct {
+	err error
+
+	mu      sync.Mutex
+	configs []config.Runtime
+}
+
+func (r *fakeReloadable) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (r *fakeReloadable) Reload(_ context.Context, cfg config.Runtime) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs = append(r.configs, cfg)
+	return r.err
+}
+
+func (r *fakeReloadable) reloaded() []config.Runtime {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]config.Runtime(nil), r.configs...)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+const apiServerCheckName = "apiserver"
+
+// defaultAPIServerBackoff is the backoff between attempts to reach the API server
+// when the manager starts with ResilientStart.
+var defaultAPIServerBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      30 * time.Second,
+}
+
+// apiServerReachability waits for the API server to become reachable when the
+// manager starts and reports whether it was reached as a readiness check.
+type apiServerReachability struct {
+	probe     func(ctx context.Context) error
+	backoff   wait.Backoff
+	reachable atomic.Bool
+}
+
+func newAPIServerReachability(config *rest.Config, httpClient *http.Client) (*apiServerReachability, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfigAndClient(config, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	return &apiServerReachability{
+		probe: func(ctx context.Context) error {
+			return discoveryClient.RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+		},
+		backoff: defaultAPIServerBackoff,
+	}, nil
+}
+
+// Check implements healthz.Checker. It fails until the API server was reached.
+func (a *apiServerReachability) Check(_ *http.Request) error {
+	if !a.reachable.Load() {
+		return errors.New("API server was not reached yet")
+	}
+	return nil
+}
+
+// wait blocks until the API server is reachable, retrying with backoff. It returns
+// an error only if ctx is done before.
+func (a *apiServerReachability) wait(ctx context.Context, log logr.Logger) error {
+	backoff := a.backoff
+	for {
+		err := a.probe(ctx)
+		if err == nil {
+			a.reachable.Store(true)
+			return nil
+		}
+		// Once the steps are used up, Step keeps returning the last delay.
+		delay := backoff.Step()
+		log.Info("API server is not reachable, retrying", "error", err.Error(), "retryAfter", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var _ = Describe("resilient start", func() {
+	It("should retry until the API server is reachable", func() {
+		var attempts atomic.Int32
+		a := &apiServerReachability{
+			probe: func(context.Context) error {
+				if attempts.Add(1) < 3 {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			backoff: wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 1},
+		}
+		Expect(a.Check(nil)).NotTo(Succeed())
+
+		Expect(a.wait(context.Background(), logr.Discard())).To(Succeed())
+		Expect(attempts.Load()).To(BeEquivalentTo(3))
+		Expect(a.Check(nil)).To(Succeed())
+	})
+
+	It("should stop waiting when the context is cancelled", func() {
+		a := &apiServerReachability{
+			probe:   func(context.Context) error { return errors.New("connection refused") },
+			backoff: wait.Backoff{Duration: time.Hour},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() {
+			result <- a.wait(ctx, logr.Discard())
+		}()
+		Consistently(result).ShouldNot(Receive())
+		cancel()
+		Eventually(result).Should(Receive(MatchError(context.Canceled)))
+		Expect(a.Check(nil)).NotTo(Succeed())
+	})
+
+	It("should start without a reachable API server and report it as not ready", func() {
+		// Nothing listens on the address of the closed listener.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		host := "http://" + l.Addr().String()
+		Expect(l.Close()).To(Succeed())
+
+		m, err := New(&rest.Config{Host: host}, Options{
+			ResilientStart:         true,
+			HealthProbeBindAddress: "127.0.0.1:0",
+			Metrics:                metricsserver.Options{BindAddress: "0"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		cm := m.(*controllerManager)
+		Expect(cm.AddHealthzCheck("ping", func(*http.Request) error { return nil })).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() {
+			result <- m.Start(ctx)
+		}()
+
+		endpoint := "http://" + cm.healthProbeListener.Addr().String()
+		Eventually(func() (int, error) {
+			resp, err := http.Get(endpoint + defaultLivenessEndpoint)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode, nil
+		}).Should(Equal(http.StatusOK))
+
+		resp, err := http.Get(endpoint + defaultReadinessEndpoint + "/" + apiServerCheckName)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Consistently(result).ShouldNot(Receive())
+
+		cancel()
+		Eventually(result).Should(Receive(BeNil()))
+	})
+})
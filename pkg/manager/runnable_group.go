@@ -3,7 +3,10 @@ package manager
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -18,6 +21,74 @@ type readyRunnable struct {
 	Runnable
 	Check       runnableCheck
 	signalReady bool
+
+	// group is the group the runnable was added to.
+	group *runnableGroup
+
+	// after are closed when the runnables this runnable depends on have completed.
+	after []<-chan struct{}
+	// done is closed when Start of the runnable returned without an error.
+	done chan struct{}
+
+	// ctx is the context the runnable is started with, it is cancelled
+	// when the runnable is removed or the group is stopped.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// removed is set when the runnable was removed from its group.
+	removed atomic.Bool
+	// exited is closed when the runnable returned or was removed before it started.
+	exited   chan struct{}
+	exitOnce sync.Once
+	// err is the error returned by the runnable, set before exited is closed.
+	err error
+}
+
+// exit records the result of the runnable and marks it as exited.
+func (rn *readyRunnable) exit(err error) {
+	rn.exitOnce.Do(func() {
+		rn.err = err
+		close(rn.exited)
+	})
+}
+
+// runnableHandle is the RunnableHandle of a runnable in a runnableGroup.
+type runnableHandle struct {
+	group    *runnableGroup
+	runnable *readyRunnable
+
+	// onRemove are called once the runnable was removed.
+	onRemove []func()
+	// removeOnce ensures onRemove are only called once.
+	removeOnce sync.Once
+}
+
+var _ RunnableHandle = &runnableHandle{}
+
+// Stop implements RunnableHandle.
+func (h *runnableHandle) Stop(ctx context.Context) error {
+	h.remove()
+
+	select {
+	case <-h.runnable.exited:
+		return h.runnable.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// remove stops and removes the runnable without waiting for it to exit.
+func (h *runnableHandle) remove() {
+	h.removeOnce.Do(func() {
+		h.group.remove(h.runnable)
+		for _, fn := range h.onRemove {
+			fn()
+		}
+	})
+}
+
+// Done implements RunnableHandle.
+func (h *runnableHandle) Done() <-chan struct{} {
+	return h.runnable.exited
 }
 
 // runnableCheck can be passed to Add() to let the runnable group determine that a
@@ -33,6 +104,11 @@ type runnables struct {
 	Caches         *runnableGroup
 	LeaderElection *runnableGroup
 	Others         *runnableGroup
+
+	// added contains all runnables added through Add, so that dependencies
+	// on them can be resolved.
+	added     []*readyRunnable
+	addedLock sync.Mutex
 }
 
 // newRunnables creates a new runnables object.
@@ -52,24 +128,127 @@ func newRunnables(baseContext BaseContextFunc, errChan chan error) *runnables {
 // Add should return an error when called during StopAndWait.
 // The runnables added before Start are started when Start is called.
 // The runnables added after Start are started directly.
-func (r *runnables) Add(fn Runnable) error {
+//
+// If after is not empty, the runnable is only started once all runnables in
+// after have returned from Start without an error. They must have been added before,
+// must not be caches or servers, which never complete, and must not be in a group that
+// is started after the group of the runnable.
+func (r *runnables) Add(fn Runnable, after ...Runnable) error {
+	_, err := r.addWithHandle(fn, after...)
+	return err
+}
+
+// addWithHandle adds the runnable like Add and returns a handle to remove it again.
+func (r *runnables) addWithHandle(fn Runnable, after ...Runnable) (*runnableHandle, error) {
+	r.addedLock.Lock()
+	defer r.addedLock.Unlock()
+
+	deps := make([]*readyRunnable, 0, len(after))
+	for _, dep := range after {
+		if t := reflect.TypeOf(dep); t == nil || !t.Comparable() {
+			return nil, fmt.Errorf("runnable %T can not be used as a dependency as it is not comparable", dep)
+		}
+		var found *readyRunnable
+		for _, added := range r.added {
+			if sameRunnable(added.Runnable, dep) {
+				found = added
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("dependency %T must be added before the runnables depending on it", dep)
+		}
+		if r.runsUntilStopped(found.group) {
+			return nil, fmt.Errorf("dependency %T runs until the manager is stopped, so the runnables depending on it would never be started", dep)
+		}
+		deps = append(deps, found)
+	}
+
+	var group *runnableGroup
+	var ready runnableCheck
 	switch runnable := fn.(type) {
 	case *server:
-		return r.HTTPServers.Add(fn, nil)
+		group = r.HTTPServers
 	case hasCache:
-		return r.Caches.Add(fn, func(ctx context.Context) bool {
+		group = r.Caches
+		ready = func(ctx context.Context) bool {
 			return runnable.GetCache().WaitForCacheSync(ctx)
-		})
+		}
 	case webhook.Server:
-		return r.Webhooks.Add(fn, nil)
+		group = r.Webhooks
 	case LeaderElectionRunnable:
+		group = r.LeaderElection
 		if !runnable.NeedLeaderElection() {
-			return r.Others.Add(fn, nil)
+			group = r.Others
 		}
-		return r.LeaderElection.Add(fn, nil)
 	default:
-		return r.LeaderElection.Add(fn, nil)
+		group = r.LeaderElection
 	}
+
+	// The start of a group waits for its runnables to be ready, so a runnable must not
+	// depend on a runnable of a group that is only started after its own group.
+	done := make([]<-chan struct{}, 0, len(deps))
+	for i, dep := range deps {
+		if r.startOrder(dep.group) > r.startOrder(group) {
+			return nil, fmt.Errorf("dependency %T is started after runnable %T depending on it", after[i], fn)
+		}
+		done = append(done, dep.done)
+	}
+
+	added, err := group.add(fn, ready, done)
+	if err != nil {
+		return nil, err
+	}
+	r.added = append(r.added, added)
+
+	return &runnableHandle{
+		group:    group,
+		runnable: added,
+		onRemove: []func(){func() { r.forget(added) }},
+	}, nil
+}
+
+// forget removes a runnable from the runnables dependencies can be declared on.
+func (r *runnables) forget(rn *readyRunnable) {
+	r.addedLock.Lock()
+	defer r.addedLock.Unlock()
+
+	for i, added := range r.added {
+		if added == rn {
+			r.added = append(r.added[:i], r.added[i+1:]...)
+			return
+		}
+	}
+}
+
+// startOrder returns the position of group in the order in which the manager starts
+// the groups of runnables.
+func (r *runnables) startOrder(group *runnableGroup) int {
+	for i, g := range []*runnableGroup{r.HTTPServers, r.Webhooks, r.Caches, r.Others, r.LeaderElection} {
+		if g == group {
+			return i
+		}
+	}
+	return -1
+}
+
+// runsUntilStopped returns whether the runnables of group, like caches and servers,
+// keep running until the manager is stopped, so that they never complete.
+func (r *runnables) runsUntilStopped(group *runnableGroup) bool {
+	return group == r.HTTPServers || group == r.Webhooks || group == r.Caches
+}
+
+// sameRunnable returns true if a and b are the same comparable runnable.
+// Runnables wrapped by the manager are compared by the runnable that was added.
+func sameRunnable(a, b Runnable) bool {
+	if w, ok := a.(interface{ unwrap() Runnable }); ok {
+		a = w.unwrap()
+	}
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() {
+		return false
+	}
+	return a == b
 }
 
 // runnableGroup manages a group of runnables that are
@@ -189,6 +368,7 @@ func (r *runnableGroup) reconcile() {
 			r.stop.RLock()
 			if r.stopped {
 				// Drop any runnables if we're stopped.
+				runnable.exit(errRunnableGroupStopped)
 				r.errChan <- errRunnableGroupStopped
 				r.stop.RUnlock()
 				continue
@@ -206,7 +386,8 @@ func (r *runnableGroup) reconcile() {
 		// Start the runnable.
 		go func(rn *readyRunnable) {
 			go func() {
-				if rn.Check(r.ctx) {
+				// Removed runnables must not block the start of the group.
+				if rn.Check(rn.ctx) || rn.removed.Load() {
 					if rn.signalReady {
 						r.startReadyCh <- rn
 					}
@@ -219,10 +400,33 @@ func (r *runnableGroup) reconcile() {
 			// We should always decrement the WaitGroup here.
 			defer r.wg.Done()
 
+			// Wait for the runnables this runnable depends on.
+			for _, dep := range rn.after {
+				select {
+				case <-dep:
+				case <-rn.ctx.Done():
+					rn.exit(nil)
+					return
+				}
+			}
+
+			// Don't start runnables that were removed in the meantime.
+			if rn.ctx.Err() != nil {
+				rn.exit(nil)
+				return
+			}
+
 			// Start the runnable.
-			if err := rn.Start(r.ctx); err != nil {
-				r.errChan <- err
+			err := rn.Start(rn.ctx)
+			rn.exit(err)
+			if err != nil {
+				// Errors of removed runnables are returned by their handle.
+				if !rn.removed.Load() {
+					r.errChan <- err
+				}
+				return
 			}
+			close(rn.done)
 		}(runnable)
 	}
 }
@@ -230,10 +434,16 @@ func (r *runnableGroup) reconcile() {
 // Add should be able to be called before and after Start, but not after StopAndWait.
 // Add should return an error when called during StopAndWait.
 func (r *runnableGroup) Add(rn Runnable, ready runnableCheck) error {
+	_, err := r.add(rn, ready, nil)
+	return err
+}
+
+// add adds the runnable to the group, to be started once all channels in after are closed.
+func (r *runnableGroup) add(rn Runnable, ready runnableCheck, after []<-chan struct{}) (*readyRunnable, error) {
 	r.stop.RLock()
 	if r.stopped {
 		r.stop.RUnlock()
-		return errRunnableGroupStopped
+		return nil, errRunnableGroupStopped
 	}
 	r.stop.RUnlock()
 
@@ -244,7 +454,12 @@ func (r *runnableGroup) Add(rn Runnable, ready runnableCheck) error {
 	readyRunnable := &readyRunnable{
 		Runnable: rn,
 		Check:    ready,
+		group:    r,
+		after:    after,
+		done:     make(chan struct{}),
+		exited:   make(chan struct{}),
 	}
+	readyRunnable.ctx, readyRunnable.cancel = context.WithCancel(r.ctx)
 
 	// Handle start.
 	// If the overall runnable group isn't started yet
@@ -258,14 +473,33 @@ func (r *runnableGroup) Add(rn Runnable, ready runnableCheck) error {
 			// Store the runnable in the internal if not.
 			r.startQueue = append(r.startQueue, readyRunnable)
 			r.start.Unlock()
-			return nil
+			return readyRunnable, nil
 		}
 		r.start.Unlock()
 	}
 
 	// Enqueue the runnable.
 	r.ch <- readyRunnable
-	return nil
+	return readyRunnable, nil
+}
+
+// remove cancels the context of the runnable and removes it from the group.
+// Runnables that were not started yet are never started.
+func (r *runnableGroup) remove(rn *readyRunnable) {
+	r.start.Lock()
+	rn.removed.Store(true)
+	if !r.started {
+		for i, queued := range r.startQueue {
+			if queued == rn {
+				r.startQueue = append(r.startQueue[:i], r.startQueue[i+1:]...)
+				break
+			}
+		}
+		rn.exit(nil)
+	}
+	r.start.Unlock()
+
+	rn.cancel()
 }
 
 // StopAndWait waits for all the runnables to finish before returning.
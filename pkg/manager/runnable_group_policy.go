@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// ErrorPolicy decides what a RunnableGroup does when one of its Runnables returns an error.
+type ErrorPolicy string
+
+const (
+	// ErrorPolicyFailManager stops the group and returns the error to the manager, which
+	// stops the manager. This is how Runnables added to the manager directly behave.
+	ErrorPolicyFailManager ErrorPolicy = "FailManager"
+
+	// ErrorPolicyRestart restarts the Runnable with an exponential backoff. As a Runnable
+	// can't be started twice, every restart starts a new Runnable, so Runnables must be
+	// added to groups with this policy with AddFactory.
+	ErrorPolicyRestart ErrorPolicy = "Restart"
+
+	// ErrorPolicyLogAndContinue logs the error and keeps the other Runnables of the group
+	// running. The failed Runnable is reported by the health check of the group.
+	ErrorPolicyLogAndContinue ErrorPolicy = "LogAndContinue"
+)
+
+const (
+	defaultMinRestartDelay = time.Second
+	defaultMaxRestartDelay = 5 * time.Minute
+)
+
+// RunnableGroupOptions are the options of a RunnableGroup.
+type RunnableGroupOptions struct {
+	// ErrorPolicy decides what happens when a Runnable of the group returns an error.
+	// Defaults to ErrorPolicyFailManager.
+	ErrorPolicy ErrorPolicy
+
+	// MinRestartDelay is the delay before a failed Runnable is restarted the first time.
+	// It doubles with every consecutive failure. Only used with ErrorPolicyRestart.
+	// Defaults to 1 second.
+	MinRestartDelay time.Duration
+
+	// MaxRestartDelay is the maximum delay before a failed Runnable is restarted. A
+	// Runnable that ran for longer than MaxRestartDelay before failing is restarted after
+	// MinRestartDelay again. Only used with ErrorPolicyRestart. Defaults to 5 minutes.
+	MaxRestartDelay time.Duration
+
+	// NeedLeaderElection determines whether the group runs only on the leader. Defaults
+	// to whether any of its Runnables needs leader election.
+	NeedLeaderElection *bool
+
+	// Logger is used to log the errors of the Runnables. Defaults to the logger of the
+	// manager the group is added to.
+	Logger logr.Logger
+}
+
+// RunnableGroup runs a group of Runnables as a single Runnable with a shared error
+// policy, e.g. to keep auxiliary components from stopping the manager when they fail.
+// The group is added to the manager like any other Runnable, and its state can be
+// exposed through a health check:
+//
+//	group := manager.NewRunnableGroup("auxiliary", manager.RunnableGroupOptions{ErrorPolicy: manager.ErrorPolicyRestart})
+//	_ = group.AddFactory("exporter", newExporter)
+//	_ = mgr.Add(group)
+//	_ = mgr.AddHealthzCheck("auxiliary", group.Check)
+type RunnableGroup struct {
+	name string
+	opts RunnableGroupOptions
+
+	mu      sync.Mutex
+	started bool
+	members []*groupMember
+}
+
+var _ Runnable = &RunnableGroup{}
+var _ LeaderElectionRunnable = &RunnableGroup{}
+
+// memberState is the state of a Runnable of a RunnableGroup.
+type memberState string
+
+const (
+	memberPending    memberState = "pending"
+	memberRunning    memberState = "running"
+	memberRestarting memberState = "restarting"
+	memberFailed     memberState = "failed"
+	memberDone       memberState = "done"
+)
+
+// RunnableFactory creates a new instance of a Runnable.
+type RunnableFactory func() (Runnable, error)
+
+// groupMember is a Runnable of a RunnableGroup.
+type groupMember struct {
+	name     string
+	runnable Runnable
+	// newRunnable creates the Runnables of restarts, it is nil for Runnables that
+	// can't be restarted.
+	newRunnable RunnableFactory
+
+	// state, lastErr and restarts are guarded by the mutex of the group.
+	state    memberState
+	lastErr  error
+	restarts int
+}
+
+// NewRunnableGroup returns an empty RunnableGroup with the given name.
+func NewRunnableGroup(name string, opts RunnableGroupOptions) *RunnableGroup {
+	if opts.ErrorPolicy == "" {
+		opts.ErrorPolicy = ErrorPolicyFailManager
+	}
+	if opts.MinRestartDelay <= 0 {
+		opts.MinRestartDelay = defaultMinRestartDelay
+	}
+	if opts.MaxRestartDelay <= 0 {
+		opts.MaxRestartDelay = defaultMaxRestartDelay
+	}
+	if opts.MaxRestartDelay < opts.MinRestartDelay {
+		opts.MaxRestartDelay = opts.MinRestartDelay
+	}
+	return &RunnableGroup{name: name, opts: opts}
+}
+
+// Name returns the name of the group.
+func (g *RunnableGroup) Name() string {
+	return g.name
+}
+
+// Add adds a Runnable with the given name to the group. Runnables can only be added
+// before the group is started. Groups with ErrorPolicyRestart don't accept Runnables
+// added with Add, as they can't be restarted.
+func (g *RunnableGroup) Add(name string, r Runnable) error {
+	if g.opts.ErrorPolicy == ErrorPolicyRestart {
+		return fmt.Errorf("unable to add runnable %q to group %q, groups with the %s policy require AddFactory", name, g.name, ErrorPolicyRestart)
+	}
+	return g.add(&groupMember{name: name, runnable: r, state: memberPending})
+}
+
+// AddFactory adds a Runnable with the given name to the group, which is created by
+// the given factory. The factory is called once right away, and again whenever the
+// Runnable is restarted. Runnables can only be added before the group is started.
+func (g *RunnableGroup) AddFactory(name string, factory RunnableFactory) error {
+	r, err := factory()
+	if err != nil {
+		return fmt.Errorf("unable to create runnable %q of group %q: %w", name, g.name, err)
+	}
+	return g.add(&groupMember{name: name, runnable: r, newRunnable: factory, state: memberPending})
+}
+
+func (g *RunnableGroup) add(member *groupMember) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	name := member.name
+	if g.started {
+		return fmt.Errorf("unable to add runnable %q to group %q, the group is already started", name, g.name)
+	}
+	for _, m := range g.members {
+		if m.name == name {
+			return fmt.Errorf("runnable %q is already part of group %q", name, g.name)
+		}
+	}
+	g.members = append(g.members, member)
+	return nil
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.
+func (g *RunnableGroup) NeedLeaderElection() bool {
+	if g.opts.NeedLeaderElection != nil {
+		return *g.opts.NeedLeaderElection
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, m := range g.members {
+		if needsLeaderElection(m.runnable) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start implements Runnable. It starts all Runnables of the group and blocks until
+// ctx is done and all of them returned. With ErrorPolicyFailManager, it stops the
+// other Runnables and returns the error of the first one that failed.
+func (g *RunnableGroup) Start(ctx context.Context) error {
+	g.mu.Lock()
+	if g.started {
+		g.mu.Unlock()
+		return fmt.Errorf("group %q was already started", g.name)
+	}
+	g.started = true
+	members := g.members
+	g.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errChan := make(chan error, len(members))
+	var wg sync.WaitGroup
+	wg.Add(len(members))
+	for _, m := range members {
+		go func(m *groupMember) {
+			defer wg.Done()
+			if err := g.run(ctx, m); err != nil {
+				errChan <- err
+				cancel()
+			}
+		}(m)
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errChan:
+		return err
+	default:
+		return nil
+	}
+}
+
+// run runs the given member according to the error policy. It only returns an error
+// with ErrorPolicyFailManager.
+func (g *RunnableGroup) run(ctx context.Context, m *groupMember) error {
+	delay := g.opts.MinRestartDelay
+	runnable := m.runnable
+	for {
+		var err error
+		started := time.Now()
+		if runnable == nil {
+			// A Runnable can't be started twice, so restarts start a new one.
+			runnable, err = m.newRunnable()
+		}
+		if err == nil {
+			g.setState(m, memberRunning, nil)
+			err = runnable.Start(ctx)
+			runnable = nil
+			if err == nil || ctx.Err() != nil {
+				g.setState(m, memberDone, err)
+				return nil
+			}
+		}
+
+		log := g.opts.Logger.WithValues("runnableGroup", g.name, "runnable", m.name)
+		switch g.opts.ErrorPolicy {
+		case ErrorPolicyRestart:
+			if time.Since(started) > g.opts.MaxRestartDelay {
+				delay = g.opts.MinRestartDelay
+			}
+			g.setState(m, memberRestarting, err)
+			log.Error(err, "Runnable failed, restarting", "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				g.setState(m, memberDone, err)
+				return nil
+			}
+			g.mu.Lock()
+			m.restarts++
+			g.mu.Unlock()
+			delay *= 2
+			if delay > g.opts.MaxRestartDelay {
+				delay = g.opts.MaxRestartDelay
+			}
+		case ErrorPolicyLogAndContinue:
+			g.setState(m, memberFailed, err)
+			log.Error(err, "Runnable failed")
+			return nil
+		default:
+			g.setState(m, memberFailed, err)
+			return fmt.Errorf("runnable %q of group %q failed: %w", m.name, g.name, err)
+		}
+	}
+}
+
+func (g *RunnableGroup) setState(m *groupMember, state memberState, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m.state = state
+	if err != nil {
+		m.lastErr = err
+	}
+}
+
+// Check implements healthz.Checker. It fails if any Runnable of the group failed or
+// is waiting to be restarted.
+func (g *RunnableGroup) Check(_ *http.Request) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var unhealthy []string
+	for _, m := range g.members {
+		switch m.state {
+		case memberFailed:
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: failed: %v", m.name, m.lastErr))
+		case memberRestarting:
+			unhealthy = append(unhealthy, fmt.Sprintf("%s: restarting after %d restarts: %v", m.name, m.restarts, m.lastErr))
+		}
+	}
+	if len(unhealthy) == 0 {
+		return nil
+	}
+	sort.Strings(unhealthy)
+	return errors.New(strings.Join(unhealthy, "; "))
+}
+
+// setDefaultLogger sets the logger of the group if none was configured.
+func (g *RunnableGroup) setDefaultLogger(log logr.Logger) {
+	if g.opts.Logger.GetSink() == nil {
+		g.opts.Logger = log
+	}
+}
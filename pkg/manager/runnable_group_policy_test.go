@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("RunnableGroup", func() {
+	// blocking runs until its context is done.
+	blocking := RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	It("should not allow adding runnables twice or after it was started", func() {
+		g := NewRunnableGroup("test", RunnableGroupOptions{})
+		Expect(g.Add("a", blocking)).To(Succeed())
+		Expect(g.Add("a", blocking)).NotTo(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(g.Start(ctx)).To(Succeed())
+		Expect(g.Add("b", blocking)).NotTo(Succeed())
+	})
+
+	It("should need leader election if any of its runnables does", func() {
+		g := NewRunnableGroup("test", RunnableGroupOptions{})
+		Expect(g.Add("server", &server{})).To(Succeed())
+		Expect(g.NeedLeaderElection()).To(BeFalse())
+		Expect(g.Add("runnable", blocking)).To(Succeed())
+		Expect(g.NeedLeaderElection()).To(BeTrue())
+
+		g = NewRunnableGroup("test", RunnableGroupOptions{NeedLeaderElection: ptr.To(false)})
+		Expect(g.Add("runnable", blocking)).To(Succeed())
+		Expect(g.NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("should stop the other runnables and return the error with the FailManager policy", func() {
+		g := NewRunnableGroup("test", RunnableGroupOptions{ErrorPolicy: ErrorPolicyFailManager})
+		Expect(g.Add("blocking", blocking)).To(Succeed())
+		Expect(g.Add("failing", RunnableFunc(func(context.Context) error {
+			return errors.New("expected error")
+		}))).To(Succeed())
+
+		err := g.Start(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("expected error")))
+		Expect(err).To(MatchError(ContainSubstring(`runnable "failing" of group "test" failed`)))
+		Expect(g.Check(nil)).To(MatchError(ContainSubstring("failing: failed")))
+	})
+
+	It("should keep the other runnables running with the LogAndContinue policy", func() {
+		g := NewRunnableGroup("test", RunnableGroupOptions{ErrorPolicy: ErrorPolicyLogAndContinue})
+		Expect(g.Add("blocking", blocking)).To(Succeed())
+		Expect(g.Add("failing", RunnableFunc(func(context.Context) error {
+			return errors.New("expected error")
+		}))).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- g.Start(ctx)
+		}()
+		Eventually(func() error { return g.Check(nil) }).Should(MatchError(ContainSubstring("failing: failed: expected error")))
+		Consistently(done).ShouldNot(Receive())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should restart failed runnables with the Restart policy", func() {
+		g := NewRunnableGroup("test", RunnableGroupOptions{
+			ErrorPolicy:     ErrorPolicyRestart,
+			MinRestartDelay: 10 * time.Millisecond,
+			MaxRestartDelay: 20 * time.Millisecond,
+		})
+		Expect(g.Add("blocking", blocking)).To(MatchError(ContainSubstring("require AddFactory")))
+
+		var created, starts atomic.Int32
+		Expect(g.AddFactory("flaky", func() (Runnable, error) {
+			created.Add(1)
+			var started atomic.Bool
+			return RunnableFunc(func(ctx context.Context) error {
+				defer GinkgoRecover()
+				Expect(started.Swap(true)).To(BeFalse(), "runnable was started twice")
+				if starts.Add(1) < 3 {
+					return errors.New("expected error")
+				}
+				<-ctx.Done()
+				return nil
+			}), nil
+		})).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- g.Start(ctx)
+		}()
+		Eventually(starts.Load).Should(BeEquivalentTo(3))
+		Expect(created.Load()).To(BeEquivalentTo(3))
+		Eventually(func() error { return g.Check(nil) }).Should(Succeed())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
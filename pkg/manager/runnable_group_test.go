@@ -52,6 +52,180 @@ var _ = Describe("runnables", func() {
 		Expect(r.Add(runnable)).To(Succeed())
 		Expect(r.LeaderElection.startQueue).To(HaveLen(1))
 	})
+
+	It("should start runnables only after their dependencies completed", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		release := make(chan struct{})
+		seeded := &atomic.Bool{}
+		seeder := &funcRunnable{fn: func(context.Context) error {
+			<-release
+			seeded.Store(true)
+			return nil
+		}}
+		started := make(chan bool, 1)
+		dependent := &funcRunnable{fn: func(context.Context) error {
+			started <- seeded.Load()
+			return nil
+		}}
+
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(seeder)).To(Succeed())
+		Expect(r.Add(dependent, seeder)).To(Succeed())
+		Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+
+		Consistently(started).ShouldNot(Receive())
+		close(release)
+		Eventually(started).Should(Receive(BeTrue()))
+	})
+
+	It("should not start runnables whose dependencies failed", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errs := make(chan error, 1)
+		failing := &funcRunnable{fn: func(context.Context) error {
+			return errors.New("seeding failed")
+		}}
+		started := &atomic.Bool{}
+		dependent := &funcRunnable{fn: func(context.Context) error {
+			started.Store(true)
+			return nil
+		}}
+
+		r := newRunnables(defaultBaseContext, errs)
+		Expect(r.Add(failing)).To(Succeed())
+		Expect(r.Add(dependent, failing)).To(Succeed())
+		Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+
+		Eventually(errs).Should(Receive(MatchError("seeding failed")))
+		Consistently(started.Load).Should(BeFalse())
+	})
+
+	It("should return an error if a dependency was not added", func() {
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(&funcRunnable{}, &funcRunnable{})).NotTo(Succeed())
+	})
+
+	It("should return an error if a dependency is not comparable", func() {
+		dep := RunnableFunc(func(context.Context) error { return nil })
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(dep)).To(Succeed())
+		Expect(r.Add(&funcRunnable{}, dep)).NotTo(Succeed())
+	})
+
+	It("should return an error if a dependency runs until the manager is stopped", func() {
+		cache := &cacheProvider{cache: &informertest.FakeInformers{}}
+		server := &server{}
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(cache)).To(Succeed())
+		Expect(r.Add(server)).To(Succeed())
+
+		Expect(r.Add(&funcRunnable{}, cache)).To(MatchError(ContainSubstring("runs until the manager is stopped")))
+		Expect(r.Add(&funcRunnable{}, server)).To(MatchError(ContainSubstring("runs until the manager is stopped")))
+		Expect(r.LeaderElection.startQueue).To(BeEmpty())
+	})
+
+	It("should return an error if a dependency is started after the runnable depending on it", func() {
+		dep := &funcRunnable{fn: func(context.Context) error { return nil }}
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(dep)).To(Succeed())
+
+		cache := &cacheProvider{cache: &informertest.FakeInformers{}}
+		Expect(r.Add(cache, dep)).To(MatchError(ContainSubstring("is started after runnable")))
+		Expect(r.Add(&nonLeaderElectionRunnable{}, dep)).To(MatchError(ContainSubstring("is started after runnable")))
+		Expect(r.Caches.startQueue).To(BeEmpty())
+		Expect(r.Others.startQueue).To(BeEmpty())
+	})
+
+	It("should start runnables depending on runnables of groups started before", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dep := &nonLeaderElectionRunnable{funcRunnable{fn: func(context.Context) error { return nil }}}
+		started := make(chan struct{})
+		dependent := &funcRunnable{fn: func(context.Context) error {
+			close(started)
+			return nil
+		}}
+
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.Add(dep)).To(Succeed())
+		Expect(r.Add(dependent, dep)).To(Succeed())
+		Expect(r.Others.Start(ctx)).To(Succeed())
+		Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+		Eventually(started).Should(BeClosed())
+	})
+
+	It("should stop a started runnable through its handle", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stopErr := errors.New("stopped")
+		r := newRunnables(defaultBaseContext, errCh)
+		Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+
+		running := make(chan struct{})
+		handle, err := r.addWithHandle(RunnableFunc(func(c context.Context) error {
+			close(running)
+			<-c.Done()
+			return stopErr
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(running).Should(BeClosed())
+
+		Expect(handle.Stop(ctx)).To(MatchError(stopErr))
+		Expect(handle.Done()).To(BeClosed())
+	})
+
+	It("should not start a runnable stopped before the group started", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := &atomic.Bool{}
+		r := newRunnables(defaultBaseContext, errCh)
+		handle, err := r.addWithHandle(RunnableFunc(func(context.Context) error {
+			started.Store(true)
+			return nil
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(handle.Stop(ctx)).To(Succeed())
+		Expect(r.LeaderElection.startQueue).To(BeEmpty())
+		Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+		Consistently(started.Load).Should(BeFalse())
+	})
+
+	It("should keep other runnables running when one is stopped", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		r := newRunnables(defaultBaseContext, errCh)
+		var contexts [2]context.Context
+		var handles [2]*runnableHandle
+		for i := range handles {
+			i := i
+			started := make(chan struct{})
+			handle, err := r.addWithHandle(RunnableFunc(func(c context.Context) error {
+				contexts[i] = c
+				close(started)
+				<-c.Done()
+				return nil
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			handles[i] = handle
+			if i == 0 {
+				Expect(r.LeaderElection.Start(ctx)).To(Succeed())
+			}
+			Eventually(started).Should(BeClosed())
+		}
+
+		Expect(handles[0].Stop(ctx)).To(Succeed())
+		Expect(contexts[0].Err()).To(HaveOccurred())
+		Expect(contexts[1].Err()).NotTo(HaveOccurred())
+		Expect(handles[1].Done()).NotTo(BeClosed())
+	})
 })
 
 var _ = Describe("runnableGroup", func() {
@@ -187,3 +361,21 @@ var _ = Describe("runnableGroup", func() {
 		}
 	})
 })
+
+// funcRunnable is a comparable Runnable, so that it can be used as a dependency.
+type funcRunnable struct {
+	fn func(context.Context) error
+}
+
+func (r *funcRunnable) Start(ctx context.Context) error {
+	return r.fn(ctx)
+}
+
+// nonLeaderElectionRunnable is a comparable Runnable that doesn't need leader election.
+type nonLeaderElectionRunnable struct {
+	funcRunnable
+}
+
+func (r *nonLeaderElectionRunnable) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// withOwnLeaderElection wraps the runnable so that it runs under its own leader election
+// lease if it requests one. Other runnables are returned unchanged.
+func (cm *controllerManager) withOwnLeaderElection(r Runnable) (Runnable, error) {
+	idRunnable, ok := r.(LeaderElectionIDRunnable)
+	if !ok || idRunnable.GetLeaderElectionID() == "" || cm.newRunnableResourceLock == nil {
+		return r, nil
+	}
+	if leRunnable, ok := r.(LeaderElectionRunnable); ok && !leRunnable.NeedLeaderElection() {
+		return r, nil
+	}
+
+	id := idRunnable.GetLeaderElectionID()
+	lock, err := cm.newRunnableResourceLock(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource lock %q: %w", id, err)
+	}
+	return &leaderElectedRunnable{
+		Runnable: r,
+		id:       id,
+		lock:     lock,
+		cm:       cm,
+	}, nil
+}
+
+// leaderElectedRunnable runs a Runnable only while holding its own leader election lease.
+// It doesn't need the leader election of the manager, so that it's started independently.
+type leaderElectedRunnable struct {
+	Runnable
+
+	id   string
+	lock resourcelock.Interface
+	cm   *controllerManager
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.
+func (r *leaderElectedRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// unwrap returns the Runnable that was added to the manager.
+func (r *leaderElectedRunnable) unwrap() Runnable {
+	return r.Runnable
+}
+
+// Start campaigns for the lease and starts the Runnable once it was acquired. Like for the
+// leader election of the manager, losing the lease returns an error so that the program exits.
+func (r *leaderElectedRunnable) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var started atomic.Bool
+	var lost bool
+	done := make(chan error, 1)
+	l, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          r.lock,
+		LeaseDuration: r.cm.leaseDuration,
+		RenewDeadline: r.cm.renewDeadline,
+		RetryPeriod:   r.cm.retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				started.Store(true)
+				r.cm.logger.Info("Acquired lease, starting runnable", "leaderElectionID", r.id)
+				done <- r.Runnable.Start(leaderCtx)
+				// Release the lease once the runnable returned on its own.
+				cancel()
+			},
+			OnStoppedLeading: func() {
+				lost = runCtx.Err() == nil
+			},
+		},
+		ReleaseOnCancel: r.cm.leaderElectionReleaseOnCancel,
+		Name:            r.id,
+	})
+	if err != nil {
+		return err
+	}
+
+	l.Run(runCtx)
+	if started.Load() {
+		if err := <-done; err != nil {
+			return err
+		}
+	}
+	if lost {
+		return fmt.Errorf("leader election lost for %q", r.id)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
+	fakeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection/fake"
+)
+
+var _ = Describe("runnables with their own leader election", func() {
+	var cm *controllerManager
+	var locks []string
+
+	BeforeEach(func() {
+		locks = nil
+		cm = &controllerManager{
+			logger:        logr.Discard(),
+			leaseDuration: 15 * time.Second,
+			renewDeadline: 10 * time.Second,
+			retryPeriod:   2 * time.Second,
+			newRunnableResourceLock: func(id string) (resourcelock.Interface, error) {
+				locks = append(locks, id)
+				return fakeleaderelection.NewResourceLock(nil, nil, leaderelection.Options{})
+			},
+		}
+	})
+
+	It("should not wrap runnables without a leader election ID", func() {
+		r := &leaderElectionIDRunnable{}
+		wrapped, err := cm.withOwnLeaderElection(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wrapped).To(BeIdenticalTo(r))
+		Expect(locks).To(BeEmpty())
+	})
+
+	It("should not wrap runnables if leader election is disabled", func() {
+		cm.newRunnableResourceLock = nil
+		r := &leaderElectionIDRunnable{id: "my-controller"}
+		wrapped, err := cm.withOwnLeaderElection(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wrapped).To(BeIdenticalTo(r))
+	})
+
+	It("should start the runnable once its own lease was acquired", func() {
+		started := make(chan struct{})
+		r := &leaderElectionIDRunnable{id: "my-controller", start: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		}}
+		wrapped, err := cm.withOwnLeaderElection(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(locks).To(Equal([]string{"my-controller"}))
+		Expect(wrapped.(LeaderElectionRunnable).NeedLeaderElection()).To(BeFalse())
+		Expect(sameRunnable(wrapped, r)).To(BeTrue())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() {
+			result <- wrapped.Start(ctx)
+		}()
+		Eventually(started).Should(BeClosed())
+
+		cancel()
+		Eventually(result).Should(Receive(BeNil()))
+	})
+})
+
+type leaderElectionIDRunnable struct {
+	id    string
+	start func(context.Context) error
+}
+
+func (r *leaderElectionIDRunnable) Start(ctx context.Context) error {
+	return r.start(ctx)
+}
+
+func (r *leaderElectionIDRunnable) GetLeaderElectionID() string {
+	return r.id
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	shutdownHookSuccess = "success"
+	shutdownHookError   = "error"
+	shutdownHookTimeout = "timeout"
+)
+
+// shutdownHookDuration is a prometheus metric which keeps track of the duration
+// of the shutdown hooks of the manager.
+var shutdownHookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "controller_runtime_shutdown_hook_duration_seconds",
+	Help:    "Length of time per shutdown hook of the manager",
+	Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5, 10, 15, 30, 60},
+}, []string{"name", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(shutdownHookDuration)
+}
+
+// shutdownHook is a function called when the manager shuts down.
+type shutdownHook struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// shutdownHookAdder is implemented by managers that call hooks when they shut down.
+type shutdownHookAdder interface {
+	AddShutdownHook(name string, timeout time.Duration, fn func(ctx context.Context) error)
+}
+
+// AddShutdownHook adds a hook to mgr that is called when mgr shuts down, after all
+// Runnables stopped. Hooks are called in the order they were added, each with a
+// context that is cancelled after the given timeout or when the graceful shutdown
+// timeout of mgr expires. A timeout of zero means no timeout of the hook. Errors of
+// hooks are logged and don't prevent later hooks from being called.
+func AddShutdownHook(mgr Manager, name string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	adder, ok := mgr.(shutdownHookAdder)
+	if !ok {
+		return fmt.Errorf("manager %T doesn't support shutdown hooks", mgr)
+	}
+	adder.AddShutdownHook(name, timeout, fn)
+	return nil
+}
+
+// AddShutdownHook implements shutdownHookAdder.
+func (cm *controllerManager) AddShutdownHook(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	cm.shutdownHooksLock.Lock()
+	defer cm.shutdownHooksLock.Unlock()
+	cm.shutdownHooks = append(cm.shutdownHooks, shutdownHook{name: name, timeout: timeout, fn: fn})
+}
+
+// runShutdownHooks calls the shutdown hooks in the order they were added.
+func (cm *controllerManager) runShutdownHooks(ctx context.Context) {
+	// The manager lock may still be held by Start if it failed before it was ready.
+	cm.shutdownHooksLock.Lock()
+	hooks := cm.shutdownHooks
+	cm.shutdownHooksLock.Unlock()
+
+	for _, hook := range hooks {
+		cm.logger.Info("Running shutdown hook", "name", hook.name)
+		hookCtx, cancel := ctx, context.CancelFunc(func() {})
+		if hook.timeout > 0 {
+			hookCtx, cancel = context.WithTimeout(ctx, hook.timeout)
+		}
+
+		start := time.Now()
+		done := make(chan error, 1)
+		go func(fn func(context.Context) error) {
+			done <- fn(hookCtx)
+		}(hook.fn)
+		// Don't wait for hooks that ignore their context beyond their timeout.
+		var err error
+		select {
+		case err = <-done:
+		case <-hookCtx.Done():
+			err = hookCtx.Err()
+		}
+		cancel()
+
+		result := shutdownHookSuccess
+		switch {
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+			result = shutdownHookTimeout
+			cm.logger.Error(err, "Shutdown hook timed out", "name", hook.name)
+		case err != nil:
+			result = shutdownHookError
+			cm.logger.Error(err, "Shutdown hook failed", "name", hook.name)
+		}
+		shutdownHookDuration.WithLabelValues(hook.name, result).Observe(time.Since(start).Seconds())
+	}
+}
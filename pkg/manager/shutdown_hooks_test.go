@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("shutdown hooks", func() {
+	It("should call the hooks in order even if hooks fail or time out", func() {
+		cm := &controllerManager{logger: logr.Discard()}
+
+		var mu sync.Mutex
+		var called []string
+		call := func(name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			called = append(called, name)
+		}
+		Expect(AddShutdownHook(cm, "first", 0, func(context.Context) error {
+			call("first")
+			return errors.New("failed")
+		})).To(Succeed())
+		cm.AddShutdownHook("second", 10*time.Millisecond, func(context.Context) error {
+			call("second")
+			// Ignore the context to make sure the timeout is enforced anyway.
+			time.Sleep(time.Second)
+			return nil
+		})
+		cm.AddShutdownHook("third", time.Second, func(ctx context.Context) error {
+			call("third")
+			_, hasDeadline := ctx.Deadline()
+			Expect(hasDeadline).To(BeTrue())
+			return nil
+		})
+
+		start := time.Now()
+		cm.runShutdownHooks(context.Background())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(called).To(Equal([]string{"first", "second", "third"}))
+	})
+
+	It("should cancel the hooks when the shutdown context is done", func() {
+		cm := &controllerManager{logger: logr.Discard()}
+		hookErr := make(chan error, 1)
+		cm.AddShutdownHook("hook", 0, func(ctx context.Context) error {
+			<-ctx.Done()
+			hookErr <- ctx.Err()
+			return ctx.Err()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		cm.runShutdownHooks(ctx)
+		Eventually(hookErr).Should(Receive(MatchError(context.DeadlineExceeded)))
+	})
+})
@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+)
+
+// needsLeaderElection returns whether the Runnable is only started while holding a lease.
+func needsLeaderElection(r Runnable) bool {
+	leRunnable, ok := r.(LeaderElectionRunnable)
+	return !ok || leRunnable.NeedLeaderElection()
+}
+
+// warmupRunnable warms up a Runnable that needs leader election. It doesn't need
+// leader election itself, so that it's started by managers that are not the leader.
+type warmupRunnable struct {
+	runnable WarmupRunnable
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.
+func (r *warmupRunnable) NeedLeaderElection() bool {
+	return false
+}
+
+// Start warms up the Runnable and keeps what it prepared running until ctx is cancelled.
+func (r *warmupRunnable) Start(ctx context.Context) error {
+	if err := r.runnable.Warmup(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
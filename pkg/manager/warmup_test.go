@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
+	fakeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection/fake"
+)
+
+var _ = Describe("warm standby", func() {
+	var cm *controllerManager
+
+	BeforeEach(func() {
+		lock, err := fakeleaderelection.NewResourceLock(nil, nil, leaderelection.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		errChan := make(chan error, 1)
+		cm = &controllerManager{
+			logger:       logr.Discard(),
+			errChan:      errChan,
+			runnables:    newRunnables(defaultBaseContext, errChan),
+			resourceLock: lock,
+			warmStandby:  true,
+		}
+	})
+
+	It("should warm up runnables that need leader election while not being the leader", func() {
+		r := &warmupTestRunnable{warmedUp: make(chan struct{}), warmupStopped: make(chan struct{})}
+		handle, err := cm.AddWithHandle(r)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(cm.runnables.Others.Start(ctx)).To(Succeed())
+		Eventually(r.warmedUp).Should(BeClosed())
+		Expect(r.started).To(BeFalse())
+
+		By("Stopping the warm up together with the runnable")
+		Expect(handle.Stop(context.Background())).To(Succeed())
+		Eventually(r.warmupStopped).Should(BeClosed())
+	})
+
+	It("should not warm up runnables if warm standby is disabled", func() {
+		cm.warmStandby = false
+		Expect(cm.Add(&warmupTestRunnable{})).To(Succeed())
+		Expect(cm.runnables.Others.startQueue).To(BeEmpty())
+	})
+
+	It("should not warm up runnables that don't need leader election", func() {
+		Expect(cm.Add(&warmupTestRunnable{noLeaderElection: true})).To(Succeed())
+		Expect(cm.runnables.Others.startQueue).To(HaveLen(1))
+	})
+})
+
+type warmupTestRunnable struct {
+	noLeaderElection bool
+	started          bool
+	warmedUp         chan struct{}
+	warmupStopped    chan struct{}
+}
+
+func (r *warmupTestRunnable) Start(ctx context.Context) error {
+	r.started = true
+	<-ctx.Done()
+	return nil
+}
+
+func (r *warmupTestRunnable) NeedLeaderElection() bool {
+	return !r.noLeaderElection
+}
+
+func (r *warmupTestRunnable) Warmup(ctx context.Context) error {
+	close(r.warmedUp)
+	go func() {
+		<-ctx.Done()
+		close(r.warmupStopped)
+	}()
+	return nil
+}
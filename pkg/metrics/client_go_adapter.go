@@ -18,6 +18,8 @@ package metrics
 
 import (
 	"context"
+	"net/url"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	clientmetrics "k8s.io/client-go/tools/metrics"
@@ -37,8 +39,50 @@ var (
 		},
 		[]string{"code", "method", "host"},
 	)
+
+	// clusterRequestResult and clusterRequestLatency are only recorded for requests
+	// whose context carries a cluster name, see WithClusterName.
+	clusterRequestResult = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rest_client_cluster_requests_total",
+			Help: "Number of HTTP requests made on behalf of provider clusters, partitioned by cluster, status code, method, and host.",
+		},
+		[]string{"cluster", "code", "method", "host"},
+	)
+
+	clusterRequestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rest_client_cluster_request_duration_seconds",
+			Help:    "Latency in seconds of HTTP requests made on behalf of provider clusters, partitioned by cluster, verb, and host.",
+			Buckets: []float64{0.005, 0.025, 0.1, 0.25, 0.5, 1.0, 2.0, 4.0, 8.0, 15.0, 30.0, 60.0},
+		},
+		[]string{"cluster", "verb", "host"},
+	)
 )
 
+type clusterNameKey struct{}
+
+// WithClusterName returns a copy of ctx that attributes the REST client requests made
+// with it to the given provider cluster. Those requests are additionally recorded by
+// metrics with a cluster label.
+func WithClusterName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, clusterNameKey{}, name)
+}
+
+// ClusterNameFromContext returns the name of the provider cluster set by WithClusterName,
+// or an empty string if none was set.
+func ClusterNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(clusterNameKey{}).(string)
+	return name
+}
+
+// DeleteClusterMetrics deletes the series of the REST client metrics of the provider
+// cluster with the given name, e.g. once the cluster was disengaged.
+func DeleteClusterMetrics(name string) {
+	clusterRequestResult.DeletePartialMatch(prometheus.Labels{"cluster": name})
+	clusterRequestLatency.DeletePartialMatch(prometheus.Labels{"cluster": name})
+}
+
 func init() {
 	registerClientMetrics()
 }
@@ -46,11 +90,12 @@ func init() {
 // registerClientMetrics sets up the client latency metrics from client-go.
 func registerClientMetrics() {
 	// register the metrics with our registry
-	Registry.MustRegister(requestResult)
+	Registry.MustRegister(requestResult, clusterRequestResult, clusterRequestLatency)
 
 	// register the metrics with client-go
 	clientmetrics.Register(clientmetrics.RegisterOpts{
-		RequestResult: &resultAdapter{metric: requestResult},
+		RequestResult:  &resultAdapter{metric: requestResult, clusterMetric: clusterRequestResult},
+		RequestLatency: &latencyAdapter{clusterMetric: clusterRequestLatency},
 	})
 }
 
@@ -63,9 +108,25 @@ func registerClientMetrics() {
 // (which isn't anywhere in an easily-importable place).
 
 type resultAdapter struct {
-	metric *prometheus.CounterVec
+	metric        *prometheus.CounterVec
+	clusterMetric *prometheus.CounterVec
 }
 
-func (r *resultAdapter) Increment(_ context.Context, code, method, host string) {
+func (r *resultAdapter) Increment(ctx context.Context, code, method, host string) {
 	r.metric.WithLabelValues(code, method, host).Inc()
+	if cluster := ClusterNameFromContext(ctx); cluster != "" {
+		r.clusterMetric.WithLabelValues(cluster, code, method, host).Inc()
+	}
+}
+
+// latencyAdapter only records the latency of requests made on behalf of provider
+// clusters, the latency of other requests isn't exposed.
+type latencyAdapter struct {
+	clusterMetric *prometheus.HistogramVec
+}
+
+func (l *latencyAdapter) Observe(ctx context.Context, verb string, u url.URL, latency time.Duration) {
+	if cluster := ClusterNameFromContext(ctx); cluster != "" {
+		l.clusterMetric.WithLabelValues(cluster, verb, u.Host).Observe(latency.Seconds())
+	}
 }
@@ -1,12 +1,14 @@
 package filters
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
@@ -119,3 +121,37 @@ func WithAuthenticationAndAuthorization(config *rest.Config, httpClient *http.Cl
 		}), nil
 	}, nil
 }
+
+// WithClientCertificates provides a metrics.Filter that only admits requests with a verified
+// client certificate whose common name is one of the given names, e.g. to restrict debug
+// endpoints to a specific client.
+// The metrics server must be configured to verify client certificates, e.g. by setting
+// ClientCAs and ClientAuth to tls.VerifyClientCertIfGiven through its TLSOpts.
+func WithClientCertificates(commonNames ...string) func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+	allowed := sets.New(commonNames...)
+	return func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+		if allowed.Len() == 0 {
+			return nil, errors.New("at least one common name must be allowed")
+		}
+
+		return func(log logr.Logger, handler http.Handler) (http.Handler, error) {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+					log.V(4).Info("Request without verified client certificate")
+					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+
+				commonName := req.TLS.VerifiedChains[0][0].Subject.CommonName
+				if !allowed.Has(commonName) {
+					msg := fmt.Sprintf("Client certificate %s is not allowed", commonName)
+					log.V(4).Info(msg)
+					http.Error(w, msg, http.StatusForbidden)
+					return
+				}
+
+				handler.ServeHTTP(w, req)
+			}), nil
+		}, nil
+	}
+}
@@ -19,13 +19,17 @@ package filters
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
@@ -197,6 +201,99 @@ var _ = Describe("manger.Manager", func() {
 	})
 })
 
+var _ = Describe("WithClientCertificates", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		filter, err := WithClientCertificates("prometheus")(nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler, err = filter(logr.Discard(), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	serve := func(commonName string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		if commonName != "" {
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}}}
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should admit requests with an allowed client certificate", func() {
+		rec := serve("prometheus")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("ok"))
+	})
+
+	It("should reject requests without or with another client certificate", func() {
+		Expect(serve("").Code).To(Equal(http.StatusUnauthorized))
+		Expect(serve("someone-else").Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require an allowed common name", func() {
+		_, err := WithClientCertificates()(nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("metrics server filtered handlers", func() {
+	It("should serve each filtered handler with its own filter", func() {
+		ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		deny := func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+			return func(_ logr.Logger, _ http.Handler) (http.Handler, error) {
+				return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					http.Error(w, "denied", http.StatusForbidden)
+				}), nil
+			}, nil
+		}
+		srv, err := metricsserver.NewServer(metricsserver.Options{
+			BindAddress: "127.0.0.1:0",
+			FilteredHandlers: map[string]metricsserver.FilteredHandler{
+				"/debug":  {Handler: ok, FilterProvider: deny},
+				"/public": {Handler: ok},
+			},
+		}, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(srv.Start(ctx)).To(Succeed())
+		}()
+
+		get := func(path string) (int, error) {
+			addr := srv.(metricsDefaultServer).GetBindAddr()
+			if addr == "" {
+				return 0, errors.New("server is not listening yet")
+			}
+			resp, err := http.Get("http://" + addr + path)
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			return resp.StatusCode, nil
+		}
+		Eventually(func() (int, error) { return get("/public") }).Should(Equal(http.StatusOK))
+		Expect(get("/debug")).To(Equal(http.StatusForbidden))
+		Expect(get("/metrics")).To(Equal(http.StatusOK))
+	})
+
+	It("should not allow overriding the metrics endpoint", func() {
+		_, err := metricsserver.NewServer(metricsserver.Options{
+			BindAddress:      "127.0.0.1:0",
+			FilteredHandlers: map[string]metricsserver.FilteredHandler{"/metrics": {Handler: http.NotFoundHandler()}},
+		}, nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
 type metricsDefaultServer interface {
 	GetBindAddr() string
 }
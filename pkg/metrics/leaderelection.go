@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"strconv"
+	"sync/atomic"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/tools/leaderelection"
 )
@@ -16,11 +19,70 @@ var (
 	}, []string{"name"})
 )
 
+// LeaderLabel is the label that leader-election-aware metrics use for whether the
+// replica was the leader when they were recorded, see LeaderLabelValue.
+const LeaderLabel = "leader"
+
+const (
+	leaderStateLeader  = "leader"
+	leaderStateStandby = "standby"
+)
+
+var (
+	leaderStatus = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "controller_runtime_leader",
+		Help: "Whether this replica is the leader of the manager, 1 for the leader and for managers without leader election, 0 for standby replicas",
+	})
+	leaderTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_leader_transitions_total",
+		Help: "Total number of leadership transitions of this replica per state it transitioned to (leader or standby)",
+	}, []string{"state"})
+	leaderLastTransition = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "controller_runtime_leader_last_transition_timestamp_seconds",
+		Help: "Unix time of the last leadership transition of this replica",
+	})
+
+	isLeader atomic.Bool
+)
+
 func init() {
-	Registry.MustRegister(leaderGauge)
+	Registry.MustRegister(leaderGauge, leaderStatus, leaderTransitions, leaderLastTransition)
+	leaderTransitions.WithLabelValues(leaderStateLeader).Add(0)
+	leaderTransitions.WithLabelValues(leaderStateStandby).Add(0)
 	leaderelection.SetProvider(leaderelectionMetricsProvider{})
 }
 
+// SetLeader records whether this replica is currently the leader, which is reported by
+// the controller_runtime_leader gauge and used for the LeaderLabel of metrics. The manager
+// calls it once it is elected, or once it started if leader election is disabled, and
+// when it loses the leadership.
+func SetLeader(leader bool) {
+	if isLeader.Swap(leader) == leader {
+		return
+	}
+	state := leaderStateStandby
+	if leader {
+		state = leaderStateLeader
+		leaderStatus.Set(1)
+	} else {
+		leaderStatus.Set(0)
+	}
+	leaderTransitions.WithLabelValues(state).Inc()
+	leaderLastTransition.SetToCurrentTime()
+}
+
+// IsLeader returns whether this replica is currently the leader, as recorded by SetLeader.
+func IsLeader() bool {
+	return isLeader.Load()
+}
+
+// LeaderLabelValue returns the value of the LeaderLabel for metrics recorded now, "true"
+// if this replica is currently the leader and "false" otherwise. It allows dashboards that
+// aggregate the metrics of all replicas to tell active from standby time series.
+func LeaderLabelValue() string {
+	return strconv.FormatBool(IsLeader())
+}
+
 type leaderelectionMetricsProvider struct{}
 
 func (leaderelectionMetricsProvider) NewLeaderMetric() leaderelection.SwitchMetric {
@@ -73,6 +73,11 @@ type Options struct {
 	// server/listener should be added as Runnable to the manager via the Add method.
 	ExtraHandlers map[string]http.Handler
 
+	// FilteredHandlers contains a map of handlers (by path) which will be added to the metrics
+	// server, each with its own filter. This allows serving e.g. debug endpoints next to the
+	// metrics under a different access policy than the one of the metrics.
+	FilteredHandlers map[string]FilteredHandler
+
 	// FilterProvider provides a filter which is a func that is added around
 	// the metrics and the extra handlers on the metrics server.
 	// This can be e.g. used to enforce authentication and authorization on the handlers
@@ -109,6 +114,17 @@ type Options struct {
 // Filter is a func that is added around metrics and extra handlers on the metrics server.
 type Filter func(log logr.Logger, handler http.Handler) (http.Handler, error)
 
+// FilteredHandler is a handler that is added to the metrics server with its own filter.
+type FilteredHandler struct {
+	// Handler is the handler serving the path.
+	Handler http.Handler
+
+	// FilterProvider provides the filter that is added around Handler, e.g.
+	// filters.WithAuthenticationAndAuthorization. If unset, the filter of
+	// Options.FilterProvider is added around Handler, like for the extra handlers.
+	FilterProvider func(c *rest.Config, httpClient *http.Client) (Filter, error)
+}
+
 // NewServer constructs a new metrics.Server from the provided options.
 func NewServer(o Options, config *rest.Config, httpClient *http.Client) (Server, error) {
 	o.setDefaults()
@@ -124,6 +140,17 @@ func NewServer(o Options, config *rest.Config, httpClient *http.Client) (Server,
 			return nil, fmt.Errorf("overriding builtin %s endpoint is not allowed", defaultMetricsEndpoint)
 		}
 	}
+	for path, filtered := range o.FilteredHandlers {
+		if path == defaultMetricsEndpoint {
+			return nil, fmt.Errorf("overriding builtin %s endpoint is not allowed", defaultMetricsEndpoint)
+		}
+		if _, ok := o.ExtraHandlers[path]; ok {
+			return nil, fmt.Errorf("path %s is registered both as extra and as filtered handler", path)
+		}
+		if filtered.Handler == nil {
+			return nil, fmt.Errorf("filtered handler for path %s has no handler", path)
+		}
+	}
 
 	// Create the metrics filter if a FilterProvider is set.
 	var metricsFilter Filter
@@ -135,9 +162,24 @@ func NewServer(o Options, config *rest.Config, httpClient *http.Client) (Server,
 		}
 	}
 
+	// Create the filters of the filtered handlers, defaulting to the metrics filter.
+	handlerFilters := make(map[string]Filter, len(o.FilteredHandlers))
+	for path, filtered := range o.FilteredHandlers {
+		handlerFilters[path] = metricsFilter
+		if filtered.FilterProvider == nil {
+			continue
+		}
+		filter, err := filtered.FilterProvider(config, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("filter provider failed to create filter for path %s of the metrics server: %w", path, err)
+		}
+		handlerFilters[path] = filter
+	}
+
 	return &defaultServer{
-		metricsFilter: metricsFilter,
-		options:       o,
+		metricsFilter:  metricsFilter,
+		handlerFilters: handlerFilters,
+		options:        o,
 	}, nil
 }
 
@@ -149,6 +191,9 @@ type defaultServer struct {
 	// the metrics and the extra handlers on the metrics server.
 	metricsFilter Filter
 
+	// handlerFilters are the filters which are added around the filtered handlers by path.
+	handlerFilters map[string]Filter
+
 	// mu protects access to the bindAddr field.
 	mu sync.RWMutex
 
@@ -224,6 +269,19 @@ func (s *defaultServer) Start(ctx context.Context) error {
 		mux.Handle(path, extraHandler)
 	}
 
+	for path, filtered := range s.options.FilteredHandlers {
+		handler := filtered.Handler
+		if filter := s.handlerFilters[path]; filter != nil {
+			log := log.WithValues("path", path)
+			var err error
+			handler, err = filter(log, handler)
+			if err != nil {
+				return fmt.Errorf("failed to start metrics server: failed to add filter to filtered handler for path %s: %w", path, err)
+			}
+		}
+		mux.Handle(path, handler)
+	}
+
 	log.Info("Serving metrics server", "bindAddress", s.options.BindAddress, "secure", s.options.SecureServing)
 
 	srv := httpserver.New(mux)
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package multicluster contains helpers for controllers that reconcile objects of the
+// cluster of the manager, the hub, across the clusters engaged by its cluster provider.
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ClusterReconcileFunc reconciles the hub object of the Request in a single engaged cluster.
+type ClusterReconcileFunc func(ctx context.Context, req reconcile.Request, clusterName string, cl cluster.Cluster) (reconcile.Result, error)
+
+// ClusterResult is the outcome of reconciling a hub object in a single cluster.
+type ClusterResult struct {
+	// ClusterName is the name of the cluster.
+	ClusterName string
+
+	// Result is the result returned for the cluster.
+	Result reconcile.Result
+
+	// Err is the error returned for the cluster, if any.
+	Err error
+}
+
+// FanOutOptions are the options of a FanOutReconciler.
+type FanOutOptions struct {
+	// MaxConcurrentClusters is the maximum number of clusters a hub object is reconciled
+	// in concurrently. Defaults to 1.
+	MaxConcurrentClusters int
+
+	// Report is called with the results of all clusters after a hub object was reconciled
+	// in every cluster, e.g. to write them to the status of the hub object. Its error is
+	// returned from Reconcile together with the errors of the clusters.
+	// Defaults to nil, which means the results are only aggregated into the result of
+	// Reconcile.
+	Report func(ctx context.Context, req reconcile.Request, results []ClusterResult) error
+}
+
+// FanOutReconciler is a reconcile.Reconciler for hub objects that invokes a
+// ClusterReconcileFunc for every cluster currently engaged by the cluster provider
+// of the manager. The errors of the clusters are aggregated, and the hub object is
+// requeued after the shortest RequeueAfter returned for any cluster. The reconcile of
+// a cluster is cancelled when the cluster is disengaged.
+type FanOutReconciler struct {
+	reconcileCluster ClusterReconcileFunc
+	opts             FanOutOptions
+
+	mu       sync.Mutex
+	clusters map[string]*engagedCluster
+}
+
+// engagedCluster is a cluster engaged with the FanOutReconciler.
+type engagedCluster struct {
+	cluster.Cluster
+
+	// ctx is done once the cluster is disengaged.
+	ctx context.Context
+}
+
+var _ reconcile.Reconciler = &FanOutReconciler{}
+var _ cluster.Aware = &FanOutReconciler{}
+
+// NewFanOutReconciler returns a FanOutReconciler invoking fn for every engaged cluster.
+// It is added to mgr, so that it is engaged with the clusters of its cluster provider.
+func NewFanOutReconciler(mgr manager.Manager, fn ClusterReconcileFunc, opts FanOutOptions) (*FanOutReconciler, error) {
+	r := newFanOutReconciler(fn, opts)
+	if err := mgr.Add(r); err != nil {
+		return nil, fmt.Errorf("failed to add fan-out reconciler to the manager: %w", err)
+	}
+	return r, nil
+}
+
+func newFanOutReconciler(fn ClusterReconcileFunc, opts FanOutOptions) *FanOutReconciler {
+	if opts.MaxConcurrentClusters <= 0 {
+		opts.MaxConcurrentClusters = 1
+	}
+	return &FanOutReconciler{reconcileCluster: fn, opts: opts, clusters: map[string]*engagedCluster{}}
+}
+
+// Start implements manager.Runnable. The FanOutReconciler is only added to the manager
+// to be engaged with its clusters, so Start blocks until ctx is done.
+func (r *FanOutReconciler) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (r *FanOutReconciler) NeedLeaderElection() bool {
+	return false
+}
+
+// Engage implements cluster.Aware.
+func (r *FanOutReconciler) Engage(ctx context.Context, name string, cl cluster.Cluster) error {
+	engaged := &engagedCluster{Cluster: cl, ctx: ctx}
+
+	r.mu.Lock()
+	r.clusters[name] = engaged
+	r.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// The cluster may have been engaged again in the meantime.
+		if r.clusters[name] == engaged {
+			delete(r.clusters, name)
+		}
+	})
+	return nil
+}
+
+// Clusters returns the names of the clusters currently engaged, sorted by name.
+func (r *FanOutReconciler) Clusters() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.clusters))
+	for name := range r.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *FanOutReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	r.mu.Lock()
+	clusters := make(map[string]*engagedCluster, len(r.clusters))
+	for name, cl := range r.clusters {
+		clusters[name] = cl
+	}
+	r.mu.Unlock()
+
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]ClusterResult, len(names))
+	sem := make(chan struct{}, r.opts.MaxConcurrentClusters)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = r.reconcileIn(ctx, req, name, clusters[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	var result reconcile.Result
+	var errs []error
+	for _, res := range results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("cluster %q: %w", res.ClusterName, res.Err))
+			continue
+		}
+		result.Requeue = result.Requeue || res.Result.Requeue
+		if res.Result.RequeueAfter > 0 && (result.RequeueAfter == 0 || res.Result.RequeueAfter < result.RequeueAfter) {
+			result.RequeueAfter = res.Result.RequeueAfter
+		}
+	}
+	if r.opts.Report != nil {
+		if err := r.opts.Report(ctx, req, results); err != nil {
+			errs = append(errs, fmt.Errorf("failed to report results: %w", err))
+		}
+	}
+	return result, kerrors.NewAggregate(errs)
+}
+
+// reconcileIn reconciles the hub object in the given cluster. The reconcile is cancelled
+// if the cluster is disengaged, which is not reported as an error.
+func (r *FanOutReconciler) reconcileIn(ctx context.Context, req reconcile.Request, name string, cl *engagedCluster) ClusterResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(cl.ctx, cancel)
+	defer stop()
+
+	ctx = logf.IntoContext(ctx, logf.FromContext(ctx).WithValues("cluster", name))
+	ctx = cluster.IntoContext(ctx, name, cl.Cluster)
+	res, err := r.reconcileCluster(ctx, req, name, cl.Cluster)
+	if err != nil && cl.ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		err = nil
+	}
+	return ClusterResult{ClusterName: name, Result: res, Err: err}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type fakeCluster struct {
+	cluster.Cluster
+}
+
+var _ = Describe("FanOutReconciler", func() {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "hub", Name: "obj"}}
+
+	It("should reconcile the hub object in every engaged cluster and aggregate the results", func() {
+		var mu sync.Mutex
+		reconciled := map[string]cluster.Cluster{}
+		var reported []ClusterResult
+		r := newFanOutReconciler(func(ctx context.Context, _ reconcile.Request, name string, cl cluster.Cluster) (reconcile.Result, error) {
+			fromCtx, err := cluster.FromContext(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fromCtx).To(BeIdenticalTo(cl))
+
+			mu.Lock()
+			reconciled[name] = cl
+			mu.Unlock()
+			switch name {
+			case "a":
+				return reconcile.Result{RequeueAfter: time.Minute}, nil
+			case "b":
+				return reconcile.Result{RequeueAfter: time.Second}, nil
+			default:
+				return reconcile.Result{}, errors.New("expected error")
+			}
+		}, FanOutOptions{
+			MaxConcurrentClusters: 2,
+			Report: func(_ context.Context, _ reconcile.Request, results []ClusterResult) error {
+				reported = results
+				return nil
+			},
+		})
+
+		ctx := context.Background()
+		a, b, c := &fakeCluster{}, &fakeCluster{}, &fakeCluster{}
+		Expect(r.Engage(ctx, "a", a)).To(Succeed())
+		Expect(r.Engage(ctx, "b", b)).To(Succeed())
+		Expect(r.Engage(ctx, "c", c)).To(Succeed())
+		Expect(r.Clusters()).To(Equal([]string{"a", "b", "c"}))
+
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError(ContainSubstring(`cluster "c": expected error`)))
+		Expect(result).To(Equal(reconcile.Result{RequeueAfter: time.Second}))
+		Expect(reconciled).To(HaveLen(3))
+		Expect(reconciled["a"]).To(BeIdenticalTo(a))
+		Expect(reported).To(HaveLen(3))
+		Expect(reported[2].ClusterName).To(Equal("c"))
+		Expect(reported[2].Err).To(HaveOccurred())
+	})
+
+	It("should stop reconciling in disengaged clusters", func() {
+		var reconciled []string
+		r := newFanOutReconciler(func(_ context.Context, _ reconcile.Request, name string, _ cluster.Cluster) (reconcile.Result, error) {
+			reconciled = append(reconciled, name)
+			return reconcile.Result{}, nil
+		}, FanOutOptions{})
+
+		clusterCtx, disengage := context.WithCancel(context.Background())
+		Expect(r.Engage(clusterCtx, "a", &fakeCluster{})).To(Succeed())
+		Expect(r.Engage(context.Background(), "b", &fakeCluster{})).To(Succeed())
+		disengage()
+		Eventually(r.Clusters).Should(Equal([]string{"b"}))
+
+		_, err := r.Reconcile(context.Background(), req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciled).To(Equal([]string{"b"}))
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ Predicate = &celPredicate{}
+
+// celCostLimit is the maximum cost of evaluating a CEL expression for an event, like
+// the limit of the API server for the validation rules of CustomResourceDefinitions.
+const celCostLimit = 1000000
+
+// CEL returns a predicate evaluating the given CEL expression for every event, which
+// is processed if it evaluates to true, e.g. to load the filters of events from
+// configuration instead of writing them in Go:
+//
+//	predicate.CEL("object.spec.replicas != oldObject.spec.replicas")
+//
+// The expression can refer to the object of the event as object and, for update
+// events, to the old object as oldObject, which is null for other events. Both are the
+// unstructured content of the objects, like in the validation rules of
+// CustomResourceDefinitions. Events for which the expression fails, e.g. because it
+// selects a field the object doesn't have, aren't processed; has() can test for the
+// presence of fields, e.g. has(object.spec.replicas). Evaluations are aborted once
+// their cost exceeds the per-call limit of the API server for CEL expressions, which
+// also rejects the event.
+//
+// An error is returned if the expression doesn't compile or can't evaluate to a bool.
+func CEL(expression string) (Predicate, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("failed to compile CEL expression %q: %w", expression, issues.Err())
+	}
+	if outputType := ast.OutputType(); !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("CEL expression %q must evaluate to a bool, not %s", expression, outputType)
+	}
+	program, err := env.Program(ast, cel.CostLimit(celCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create program of CEL expression %q: %w", expression, err)
+	}
+	return &celPredicate{expression: expression, program: program}, nil
+}
+
+type celPredicate struct {
+	expression string
+	program    cel.Program
+}
+
+func (p *celPredicate) Create(e event.CreateEvent) bool {
+	return p.evaluate(e.Object, nil)
+}
+
+func (p *celPredicate) Update(e event.UpdateEvent) bool {
+	return p.evaluate(e.ObjectNew, e.ObjectOld)
+}
+
+func (p *celPredicate) Delete(e event.DeleteEvent) bool {
+	return p.evaluate(e.Object, nil)
+}
+
+func (p *celPredicate) Generic(e event.GenericEvent) bool {
+	return p.evaluate(e.Object, nil)
+}
+
+// evaluate returns the result of the expression for the given objects, oldObj may be nil.
+func (p *celPredicate) evaluate(obj, oldObj client.Object) bool {
+	if obj == nil {
+		log.Error(nil, "Event has no object to evaluate the CEL expression for", "expression", p.expression)
+		return false
+	}
+	object, err := toUnstructured(obj)
+	if err != nil {
+		log.Error(err, "Failed to convert the object of the event to evaluate the CEL expression", "expression", p.expression)
+		return false
+	}
+	// oldObject is null unless there is an old object.
+	vars := map[string]interface{}{"object": object, "oldObject": nil}
+	if oldObj != nil {
+		oldObject, err := toUnstructured(oldObj)
+		if err != nil {
+			log.Error(err, "Failed to convert the old object of the event to evaluate the CEL expression", "expression", p.expression)
+			return false
+		}
+		vars["oldObject"] = oldObject
+	}
+
+	result, _, err := p.program.Eval(vars)
+	if err != nil {
+		log.V(5).Info("CEL expression failed, rejecting event", "expression", p.expression, "object", client.ObjectKeyFromObject(obj), "error", err.Error())
+		return false
+	}
+	matches, ok := result.Value().(bool)
+	if !ok {
+		log.Error(nil, "CEL expression didn't evaluate to a bool, rejecting event", "expression", p.expression, "result", result)
+		return false
+	}
+	return matches
+}
+
+// toUnstructured returns the unstructured content of obj.
+func toUnstructured(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
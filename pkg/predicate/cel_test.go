@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ = Describe("CEL", func() {
+	deployment := func(replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz", Labels: map[string]string{"app": "baz"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+		}
+	}
+
+	It("should compare the new and old objects of update events", func() {
+		p, err := predicate.CEL("object.spec.replicas != oldObject.spec.replicas")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: deployment(1), ObjectNew: deployment(2)})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: deployment(1), ObjectNew: deployment(1)})).To(BeFalse())
+	})
+
+	It("should reject the events the expression fails for", func() {
+		p, err := predicate.CEL("object.spec.replicas != oldObject.spec.replicas")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Create(event.CreateEvent{Object: deployment(1)})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: &appsv1.Deployment{}, ObjectNew: deployment(1)})).To(BeFalse())
+	})
+
+	It("should evaluate the expression for the object of any event", func() {
+		p, err := predicate.CEL(`oldObject == null && object.metadata.labels.app == "baz"`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(p.Create(event.CreateEvent{Object: deployment(1)})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: deployment(1)})).To(BeTrue())
+		Expect(p.Generic(event.GenericEvent{Object: deployment(1)})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: &appsv1.Deployment{}})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: deployment(1), ObjectNew: deployment(1)})).To(BeFalse())
+	})
+
+	It("should evaluate the expression for unstructured objects", func() {
+		p, err := predicate.CEL("has(object.spec.paused) && object.spec.paused")
+		Expect(err).NotTo(HaveOccurred())
+
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"spec":       map[string]interface{}{"paused": true},
+		}}
+		Expect(p.Create(event.CreateEvent{Object: u})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: deployment(1)})).To(BeFalse())
+	})
+
+	It("should reject the events the evaluation of the expression is too expensive for", func() {
+		p, err := predicate.CEL("object.metadata.labels.all(a, object.metadata.labels.all(b, a != b || a == b))")
+		Expect(err).NotTo(HaveOccurred())
+
+		labels := map[string]interface{}{}
+		for i := 0; i < 2000; i++ {
+			labels[fmt.Sprintf("label-%d", i)] = "value"
+		}
+		u := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"labels": labels},
+		}}
+		Expect(p.Create(event.CreateEvent{Object: u})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: deployment(1)})).To(BeTrue())
+	})
+
+	It("should fail for invalid expressions", func() {
+		_, err := predicate.CEL("object.spec.replicas !=")
+		Expect(err).To(MatchError(ContainSubstring("failed to compile")))
+
+		_, err = predicate.CEL(`"replicas"`)
+		Expect(err).To(MatchError(ContainSubstring("must evaluate to a bool")))
+	})
+})
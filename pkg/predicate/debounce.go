@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// Delayer is implemented by the predicates that delay the requests enqueued for the
+// events they admit, like Debounce. The delay is applied by the Kind and Informer
+// sources if the predicate is passed to them directly, not within And, Or or Not.
+type Delayer interface {
+	// DelayFor returns how long the requests of the admitted event of obj are delayed,
+	// zero to enqueue them immediately.
+	DelayFor(obj client.Object) time.Duration
+}
+
+var _ Predicate = &debounce{}
+var _ Delayer = &debounce{}
+
+// Debounce returns a predicate delaying the repeated events of an object within the
+// given window, e.g. for objects that get hammered with status updates by other
+// controllers. The first event of an object is delivered immediately, and all further
+// events within the window are delivered once at its end, the trailing edge, so that
+// the object is reconciled at most twice per window but always reconciled after its
+// last change, as the request is deduplicated by the queue of the controller.
+//
+// Delete events are delivered immediately. Debounce relies on delaying the requests in
+// the queue, see Delayer, so it must be passed directly to the watches of controllers.
+func Debounce(window time.Duration) Predicate {
+	return &debounce{
+		window:  window,
+		windows: map[debounceKey]time.Time{},
+		now:     time.Now,
+	}
+}
+
+type debounceKey struct {
+	// objType distinguishes the types of objects, and the kinds of unstructured ones.
+	objType   string
+	namespace string
+	name      string
+}
+
+type debounce struct {
+	window time.Duration
+
+	mu sync.Mutex
+	// windows are the start times of the windows of the objects.
+	windows   map[debounceKey]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func (d *debounce) Create(event.CreateEvent) bool {
+	return true
+}
+
+func (d *debounce) Update(event.UpdateEvent) bool {
+	return true
+}
+
+func (d *debounce) Delete(e event.DeleteEvent) bool {
+	if e.Object != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.windows, keyFor(e.Object))
+	}
+	return true
+}
+
+func (d *debounce) Generic(event.GenericEvent) bool {
+	return true
+}
+
+// DelayFor implements Delayer. It starts a window for obj if it has none, and delays
+// its event to the end of its window otherwise.
+func (d *debounce) DelayFor(obj client.Object) time.Duration {
+	if obj == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.pruneLocked(now)
+	key := keyFor(obj)
+	if start, ok := d.windows[key]; ok && now.Before(start.Add(d.window)) {
+		return start.Add(d.window).Sub(now)
+	}
+	d.windows[key] = now
+	return 0
+}
+
+// pruneLocked forgets the windows that ended, at most once per window.
+func (d *debounce) pruneLocked(now time.Time) {
+	if now.Sub(d.lastPrune) < d.window {
+		return
+	}
+	d.lastPrune = now
+	for key, start := range d.windows {
+		if !now.Before(start.Add(d.window)) {
+			delete(d.windows, key)
+		}
+	}
+}
+
+func keyFor(obj client.Object) debounceKey {
+	return debounceKey{objType: fmt.Sprintf("%T %s", obj, obj.GetObjectKind().GroupVersionKind()), namespace: obj.GetNamespace(), name: obj.GetName()}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ = Describe("Debounce", func() {
+	const window = 200 * time.Millisecond
+	var pod, otherPod *corev1.Pod
+	BeforeEach(func() {
+		pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "baz"}}
+		otherPod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "other"}}
+	})
+
+	It("should admit all events", func() {
+		p := predicate.Debounce(window)
+		Expect(p.Create(event.CreateEvent{Object: pod})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: pod})).To(BeTrue())
+		Expect(p.Generic(event.GenericEvent{Object: pod})).To(BeTrue())
+	})
+
+	It("should delay the repeated events of an object to the end of its window", func() {
+		delayer := predicate.Debounce(window).(predicate.Delayer)
+		Expect(delayer.DelayFor(pod)).To(BeZero())
+		Expect(delayer.DelayFor(pod)).To(And(BeNumerically(">", 0), BeNumerically("<=", window)))
+		Expect(delayer.DelayFor(otherPod)).To(BeZero())
+
+		time.Sleep(window)
+		Expect(delayer.DelayFor(pod)).To(BeZero())
+	})
+
+	It("should not delay the event after a delete event", func() {
+		p := predicate.Debounce(window)
+		delayer := p.(predicate.Delayer)
+		Expect(delayer.DelayFor(pod)).To(BeZero())
+		Expect(p.Delete(event.DeleteEvent{Object: pod})).To(BeTrue())
+		Expect(delayer.DelayFor(pod)).To(BeZero())
+	})
+})
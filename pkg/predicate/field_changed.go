@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ Predicate = fieldChangedPredicate{}
+
+// FieldChanged returns a predicate skipping the update events that don't change any of
+// the given fields, which are compared deeply, e.g. to ignore the changes of the status
+// and of irrelevant fields:
+//
+//	predicate.FieldChanged("spec.template", "metadata.labels['app']")
+//
+// The fields are JSON paths into the objects, whose elements are separated by dots.
+// Elements can also be selected in brackets, by quoted keys, e.g. for keys containing
+// dots, or by indexes into lists, e.g. "spec.containers[0].image". A field that is
+// added or removed is changed. Create, delete and generic events are processed.
+//
+// FieldChanged panics if a path is invalid, like regexp.MustCompile, see
+// ParseFieldChanged for paths that aren't constant.
+func FieldChanged(paths ...string) Predicate {
+	p, err := ParseFieldChanged(paths...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// ParseFieldChanged returns the predicate of FieldChanged, or an error if a path is
+// invalid, e.g. for paths loaded from configuration.
+func ParseFieldChanged(paths ...string) (Predicate, error) {
+	p := fieldChangedPredicate{}
+	for _, path := range paths {
+		fieldPath, err := parseFieldPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field path %q: %w", path, err)
+		}
+		p.paths = append(p.paths, fieldPath)
+	}
+	return p, nil
+}
+
+type fieldChangedPredicate struct {
+	Funcs
+	paths [][]fieldPathElement
+}
+
+// Update implements default UpdateEvent filter for checking the changes of the fields.
+func (p fieldChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil {
+		log.Error(nil, "Update event has no old object to update", "event", e)
+		return false
+	}
+	if e.ObjectNew == nil {
+		log.Error(nil, "Update event has no new object for update", "event", e)
+		return false
+	}
+
+	oldObj, err := toUnstructured(e.ObjectOld)
+	if err != nil {
+		log.Error(err, "Failed to convert the old object of the update event to compare its fields")
+		return false
+	}
+	newObj, err := toUnstructured(e.ObjectNew)
+	if err != nil {
+		log.Error(err, "Failed to convert the new object of the update event to compare its fields")
+		return false
+	}
+	for _, path := range p.paths {
+		oldValue, oldFound := lookupFieldPath(oldObj, path)
+		newValue, newFound := lookupFieldPath(newObj, path)
+		if oldFound != newFound || !reflect.DeepEqual(oldValue, newValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPathElement selects a field of an object by its key, or an element of a list by
+// its index if isIndex is set.
+type fieldPathElement struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseFieldPath parses a path like "spec.containers[0].image" or "metadata.labels['app']".
+func parseFieldPath(path string) ([]fieldPathElement, error) {
+	var elements []fieldPathElement
+	rest := path
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("missing ]")
+			}
+			selector := rest[1:end]
+			switch {
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				elements = append(elements, fieldPathElement{key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("%q must be a quoted key or an index", selector)
+				}
+				elements = append(elements, fieldPathElement{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		} else {
+			if len(elements) > 0 {
+				var found bool
+				if rest, found = strings.CutPrefix(rest, "."); !found {
+					return nil, fmt.Errorf("missing . before %q", rest)
+				}
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key")
+			}
+			elements = append(elements, fieldPathElement{key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return elements, nil
+}
+
+// lookupFieldPath returns the value of the field at path in obj, and whether it exists.
+func lookupFieldPath(obj map[string]interface{}, path []fieldPathElement) (interface{}, bool) {
+	var value interface{} = obj
+	for _, element := range path {
+		if element.isIndex {
+			list, ok := value.([]interface{})
+			if !ok || element.index >= len(list) {
+				return nil, false
+			}
+			value = list[element.index]
+			continue
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = fields[element.key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicate_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var _ = Describe("FieldChanged", func() {
+	var oldDeployment *appsv1.Deployment
+	BeforeEach(func() {
+		oldDeployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "biz",
+				Name:      "baz",
+				Labels:    map[string]string{"app": "baz", "app.kubernetes.io/version": "1"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "baz", Image: "baz:1"}}},
+				},
+			},
+		}
+	})
+
+	update := func(p predicate.Predicate, mutate func(*appsv1.Deployment)) bool {
+		newDeployment := oldDeployment.DeepCopy()
+		mutate(newDeployment)
+		return p.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: newDeployment})
+	}
+
+	It("should process the update events changing one of the fields", func() {
+		p := predicate.FieldChanged("spec.template", "metadata.labels['app']")
+
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Image = "baz:2"
+		})).To(BeTrue())
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Labels["app"] = "other"
+		})).To(BeTrue())
+		Expect(update(p, func(d *appsv1.Deployment) {
+			delete(d.Labels, "app")
+		})).To(BeTrue())
+	})
+
+	It("should skip the update events not changing the fields", func() {
+		p := predicate.FieldChanged("spec.template", "metadata.labels['app']")
+
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Status.Replicas = 3
+			d.Labels["other"] = "label"
+			d.Spec.Replicas = ptr.To(int32(2))
+		})).To(BeFalse())
+	})
+
+	It("should select keys containing dots and list elements", func() {
+		p := predicate.FieldChanged(`metadata.labels["app.kubernetes.io/version"]`, "spec.template.spec.containers[0].image")
+
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Labels["app.kubernetes.io/version"] = "2"
+		})).To(BeTrue())
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Image = "baz:2"
+		})).To(BeTrue())
+		Expect(update(p, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Name = "other"
+		})).To(BeFalse())
+	})
+
+	It("should process the other events", func() {
+		p := predicate.FieldChanged("spec")
+
+		Expect(p.Create(event.CreateEvent{Object: oldDeployment})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: oldDeployment})).To(BeTrue())
+		Expect(p.Generic(event.GenericEvent{Object: oldDeployment})).To(BeTrue())
+	})
+
+	It("should reject invalid paths", func() {
+		for _, path := range []string{"", "spec.", "spec..template", "labels['app'", "containers[-1]", "containers[name]", "labels['app']name"} {
+			_, err := predicate.ParseFieldChanged(path)
+			Expect(err).To(HaveOccurred(), path)
+		}
+		Expect(func() { predicate.FieldChanged("spec.") }).To(Panic())
+	})
+})
@@ -17,8 +17,13 @@ limitations under the License.
 package predicate
 
 import (
+	"context"
 	"reflect"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -50,6 +55,7 @@ var _ Predicate = AnnotationChangedPredicate{}
 var _ Predicate = or{}
 var _ Predicate = and{}
 var _ Predicate = not{}
+var _ Predicate = &named{}
 
 // Funcs is a function that implements Predicate.
 type Funcs struct {
@@ -358,3 +364,118 @@ func LabelSelectorPredicate(s metav1.LabelSelector) (Predicate, error) {
 		return selector.Matches(labels.Set(o.GetLabels()))
 	}), nil
 }
+
+// namespaceSelectorTimeout is the maximum time NamespaceSelector waits for the reader.
+const namespaceSelectorTimeout = 10 * time.Second
+
+// NamespaceSelector constructs a Predicate admitting the objects in the namespaces whose
+// labels match the selector, e.g. to only reconcile the objects of tenant namespaces.
+// The labels of the namespaces are read with reader, which should be a cache like the
+// one of the manager, so that the Namespaces are watched instead of read for every event.
+//
+// Namespaces themselves are admitted if their own labels match the selector, other
+// cluster-scoped objects are never admitted. The objects of namespaces that can't be
+// read, e.g. because they were deleted, aren't admitted either. Reading a namespace
+// times out after ten seconds, e.g. while a cache waits for its informer of
+// Namespaces to sync, so that a slow reader doesn't block the handling of events. As
+// only the events of the objects are filtered, changes of the labels of a namespace
+// don't trigger events for its objects.
+func NamespaceSelector(reader client.Reader, selector labels.Selector) Predicate {
+	return NewPredicateFuncs(func(o client.Object) bool {
+		if o.GetNamespace() == "" {
+			_, isNamespace := o.(*corev1.Namespace)
+			isNamespace = isNamespace || o.GetObjectKind().GroupVersionKind().GroupKind() == corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind()
+			return isNamespace && selector.Matches(labels.Set(o.GetLabels()))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), namespaceSelectorTimeout)
+		defer cancel()
+		ns := &corev1.Namespace{}
+		if err := reader.Get(ctx, client.ObjectKey{Name: o.GetNamespace()}, ns); err != nil {
+			if apierrors.IsNotFound(err) {
+				log.V(1).Info("Namespace of object not found, rejecting event", "object", client.ObjectKeyFromObject(o))
+			} else {
+				log.Error(err, "Failed to get namespace of object, rejecting event", "object", client.ObjectKeyFromObject(o))
+			}
+			return false
+		}
+		return selector.Matches(labels.Set(ns.GetLabels()))
+	})
+}
+
+// rejectionLogInterval is the minimum interval between two logged rejections of a named predicate.
+const rejectionLogInterval = time.Second
+
+// Named returns a predicate that delegates to the given predicate and carries the given name.
+// The name is used to attribute filtered events, e.g. in the watch metrics of controllers
+// built with the builder.
+//
+// Events rejected by a named predicate are logged with the predicate name, event type and
+// object at debug verbosity (V(5)). Logging is sampled to at most one message per second per
+// predicate, with the number of rejections that were not logged in between. The messages are
+// only emitted if V(5) logging is enabled on the logger passed to pkg/log.SetLogger.
+func Named(name string, p Predicate) Predicate {
+	return &named{name: name, predicate: p}
+}
+
+// NameOf returns the name of a predicate created with Named, or an empty string if
+// the predicate has no name.
+func NameOf(p Predicate) string {
+	if n, ok := p.(*named); ok {
+		return n.name
+	}
+	return ""
+}
+
+type named struct {
+	name      string
+	predicate Predicate
+
+	mu         sync.Mutex
+	lastLogged time.Time
+	suppressed int
+}
+
+func (n *named) Create(e event.CreateEvent) bool {
+	return n.observe(n.predicate.Create(e), "create", e.Object)
+}
+
+func (n *named) Update(e event.UpdateEvent) bool {
+	return n.observe(n.predicate.Update(e), "update", e.ObjectNew)
+}
+
+func (n *named) Delete(e event.DeleteEvent) bool {
+	return n.observe(n.predicate.Delete(e), "delete", e.Object)
+}
+
+func (n *named) Generic(e event.GenericEvent) bool {
+	return n.observe(n.predicate.Generic(e), "generic", e.Object)
+}
+
+// observe logs a sampled debug message if the event was rejected and returns the result unchanged.
+func (n *named) observe(result bool, eventType string, obj client.Object) bool {
+	if result {
+		return result
+	}
+
+	debugLog := log.V(5)
+	if !debugLog.Enabled() {
+		return result
+	}
+
+	n.mu.Lock()
+	if time.Since(n.lastLogged) < rejectionLogInterval {
+		n.suppressed++
+		n.mu.Unlock()
+		return result
+	}
+	suppressed := n.suppressed
+	n.lastLogged, n.suppressed = time.Now(), 0
+	n.mu.Unlock()
+
+	keysAndValues := []interface{}{"predicate", n.name, "event", eventType, "suppressedRejections", suppressed}
+	if obj != nil {
+		keysAndValues = append(keysAndValues, "object", client.ObjectKeyFromObject(obj))
+	}
+	debugLog.Info("Predicate rejected event", keysAndValues...)
+	return result
+}
@@ -17,12 +17,17 @@ limitations under the License.
 package predicate_test
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -956,4 +961,66 @@ var _ = Describe("Predicate", func() {
 			})
 		})
 	})
+
+	Describe("When checking a NamespaceSelector predicate", func() {
+		tenant := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Labels: map[string]string{"tenant": "true"}}}
+		system := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "system"}}
+		reader := fake.NewClientBuilder().WithObjects(tenant, system).Build()
+		instance := predicate.NamespaceSelector(reader, labels.SelectorFromSet(labels.Set{"tenant": "true"}))
+
+		It("should admit the objects of matching namespaces", func() {
+			match := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "foo"}}
+			Expect(instance.Create(event.CreateEvent{Object: match})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{Object: match})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Object: match})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectNew: match})).To(BeTrue())
+		})
+
+		It("should reject the objects of other namespaces", func() {
+			Expect(instance.Create(event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "system", Name: "foo"}}})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "missing", Name: "foo"}}})).To(BeFalse())
+		})
+
+		It("should match namespaces by their own labels and reject other cluster-scoped objects", func() {
+			Expect(instance.Create(event.CreateEvent{Object: tenant})).To(BeTrue())
+			Expect(instance.Create(event.CreateEvent{Object: system})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Object: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"tenant": "true"}}}})).To(BeFalse())
+		})
+
+		It("should read the namespaces with a timeout", func() {
+			var hasDeadline bool
+			reader := interceptor.NewClient(fake.NewClientBuilder().WithObjects(tenant).Build(), interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					_, hasDeadline = ctx.Deadline()
+					return c.Get(ctx, key, obj, opts...)
+				},
+			})
+			instance := predicate.NamespaceSelector(reader, labels.SelectorFromSet(labels.Set{"tenant": "true"}))
+			Expect(instance.Create(event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant", Name: "foo"}}})).To(BeTrue())
+			Expect(hasDeadline).To(BeTrue())
+		})
+	})
+
+	Describe("When checking a Named predicate", func() {
+		rejectFoo := predicate.NewPredicateFuncs(func(o client.Object) bool { return o.GetName() != "foo" })
+		instance := predicate.Named("reject-foo", rejectFoo)
+
+		It("should carry its name", func() {
+			Expect(predicate.NameOf(instance)).To(Equal("reject-foo"))
+			Expect(predicate.NameOf(rejectFoo)).To(BeEmpty())
+		})
+
+		It("should return the result of the wrapped predicate", func() {
+			foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+			bar := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}
+			Expect(instance.Create(event.CreateEvent{Object: foo})).To(BeFalse())
+			Expect(instance.Delete(event.DeleteEvent{Object: foo})).To(BeFalse())
+			Expect(instance.Generic(event.GenericEvent{Object: foo})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: bar, ObjectNew: foo})).To(BeFalse())
+			Expect(instance.Create(event.CreateEvent{Object: bar})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{Object: bar})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{Object: bar})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: foo, ObjectNew: bar})).To(BeTrue())
+		})
+	})
 })
@@ -50,6 +50,12 @@ func (r *Result) IsZero() bool {
 type Request struct {
 	// NamespacedName is the name and namespace of the object to reconcile.
 	types.NamespacedName
+
+	// ClusterName is the name of the cluster the object lives in, as engaged by the
+	// cluster provider of the manager. The empty name refers to the cluster of the
+	// manager. The controller passes the cluster to the reconciler through the context,
+	// see cluster.FromContext.
+	ClusterName string
 }
 
 /*
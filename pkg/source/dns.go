@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// defaultResolveInterval is the default interval in which DNS names are resolved.
+	defaultResolveInterval = 30 * time.Second
+)
+
+var dnsLog = logf.RuntimeLog.WithName("source").WithName("DNS")
+
+var _ Source = &DNS{}
+
+// DNS is used to provide a source of events originating from changes of the
+// addresses DNS names resolve to, e.g. to reissue configurations when the set of
+// backend IPs of an external endpoint changes. The names are resolved periodically
+// and a GenericEvent is emitted for the object of a name whenever the set of
+// addresses it resolves to changes. The endpoints of an in-cluster Service can be
+// followed by resolving the name of a headless Service.
+type DNS struct {
+	// Targets maps the DNS names to resolve to the objects the GenericEvents are emitted for.
+	Targets map[string]client.Object
+
+	// Interval is the interval in which the names are resolved.
+	// Defaults to 30 seconds.
+	Interval time.Duration
+
+	// LookupHost resolves a name to its addresses.
+	// Defaults to net.DefaultResolver.LookupHost.
+	LookupHost func(ctx context.Context, host string) ([]string, error)
+}
+
+func (ds *DNS) String() string {
+	return fmt.Sprintf("dns source: %p", ds)
+}
+
+// Start implements Source and should only be called by the Controller.
+func (ds *DNS) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if len(ds.Targets) == 0 {
+		return fmt.Errorf("must specify DNS.Targets")
+	}
+
+	interval := ds.Interval
+	if interval <= 0 {
+		interval = defaultResolveInterval
+	}
+	lookupHost := ds.LookupHost
+	if lookupHost == nil {
+		lookupHost = net.DefaultResolver.LookupHost
+	}
+
+	// Copy the targets so that they can't be modified while resolving.
+	targets := make(map[string]client.Object, len(ds.Targets))
+	for name, obj := range ds.Targets {
+		targets[name] = obj
+	}
+
+	go func() {
+		// The first successful resolution of a name only records the addresses to compare against.
+		addresses := map[string][]string{}
+		resolve := func() {
+			for name, obj := range targets {
+				addrs, err := lookupHost(ctx, name)
+				if err != nil {
+					// Keep the previous addresses, a failed lookup is not a change.
+					dnsLog.Error(err, "failed to resolve DNS name", "name", name)
+					continue
+				}
+				slices.Sort(addrs)
+				previous, known := addresses[name]
+				addresses[name] = addrs
+				if !known || slices.Equal(previous, addrs) {
+					continue
+				}
+
+				evt := event.GenericEvent{Object: obj}
+				shouldHandle := true
+				for _, p := range prct {
+					if !p.Generic(evt) {
+						shouldHandle = false
+						break
+					}
+				}
+				if shouldHandle {
+					handler.Generic(ctx, evt, queue)
+				}
+			}
+		}
+
+		resolve()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				resolve()
+			}
+		}
+	}()
+
+	return nil
+}
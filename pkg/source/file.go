@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var fileLog = logf.RuntimeLog.WithName("source").WithName("File")
+
+// FileMapFunc maps a changed path to the objects the GenericEvents are emitted for.
+type FileMapFunc func(ctx context.Context, path string) []client.Object
+
+// File creates a source emitting GenericEvents for the objects mapper maps changed
+// paths to, e.g. to reconcile cluster state from mounted configuration or GitOps
+// checkouts:
+//
+//	source.File(func(ctx context.Context, path string) []client.Object {
+//		return []client.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+//			Namespace: "default", Name: strings.TrimSuffix(filepath.Base(path), ".yaml"),
+//		}}}
+//	}, "/etc/config/settings.yaml", "/var/lib/checkout/manifests")
+//
+// The paths can be files or directories. A directory is watched for the creation,
+// modification, removal and renaming of its direct entries, which are passed to mapper.
+// Its parent directory is watched as well, so that a directory that is removed and
+// created again is watched again, and all its entries are passed to mapper then.
+// A file is watched through its parent directory, so that it can be missing when the
+// source is started and replacements by renames or symlink swaps, like the updates
+// of mounted ConfigMaps and Secrets, are followed; the file is passed to mapper whenever
+// its size or modification time change or it is created or removed.
+func File(mapper FileMapFunc, paths ...string) Source {
+	return &file{mapper: mapper, paths: paths}
+}
+
+type file struct {
+	mapper FileMapFunc
+	paths  []string
+}
+
+// fileState is the state of a watched file used to detect its changes.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) fileState {
+	// Stat follows symlinks, so that swaps of their targets change the state.
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+func (fs *file) String() string {
+	return fmt.Sprintf("file source: %s", strings.Join(fs.paths, ", "))
+}
+
+// Start implements Source and should only be called by the Controller.
+func (fs *file) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if fs.mapper == nil {
+		return fmt.Errorf("must specify a File mapper")
+	}
+	if len(fs.paths) == 0 {
+		return fmt.Errorf("must specify File paths")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{}
+	files := map[string]fileState{}
+	for _, path := range fs.paths {
+		path = filepath.Clean(path)
+		watched := path
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs[path] = true
+			// Watch the parent to notice when the directory is created again after its
+			// removal, which ends its watch.
+			if parent := filepath.Dir(path); parent != path {
+				if err := watcher.Add(parent); err != nil {
+					fileLog.Error(err, "failed to watch the parent of a directory, it won't be watched again if it is removed", "path", path)
+				}
+			}
+		} else {
+			files[path] = statFile(path)
+			watched = filepath.Dir(path)
+		}
+		if err := watcher.Add(watched); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %q: %w", watched, err)
+		}
+	}
+
+	emit := func(path string) {
+		for _, obj := range fs.mapper(ctx, path) {
+			evt := event.GenericEvent{Object: obj}
+			shouldHandle := true
+			for _, p := range prct {
+				if !p.Generic(evt) {
+					shouldHandle = false
+					break
+				}
+			}
+			if shouldHandle {
+				handler.Generic(ctx, evt, queue)
+			}
+		}
+	}
+
+	// readdDir watches a watched directory again when it is created again.
+	readdDir := func(e fsnotify.Event) {
+		if !e.Has(fsnotify.Create) {
+			fileLog.V(1).Info("watched directory was removed, waiting for it to be created again", "path", e.Name)
+			return
+		}
+		if err := watcher.Add(e.Name); err != nil {
+			fileLog.Error(err, "failed to watch directory again after it was created", "path", e.Name)
+			return
+		}
+		// The entries may have been created before the directory was watched.
+		entries, err := os.ReadDir(e.Name)
+		if err != nil {
+			fileLog.Error(err, "failed to read directory after it was created", "path", e.Name)
+			return
+		}
+		for _, entry := range entries {
+			emit(filepath.Join(e.Name, entry.Name()))
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fileLog.Error(err, "failed to watch files")
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Permission changes don't change the contents.
+				if e.Op == fsnotify.Chmod {
+					continue
+				}
+				if dirs[e.Name] {
+					readdDir(e)
+				}
+				dir := filepath.Dir(e.Name)
+				if dirs[dir] {
+					emit(e.Name)
+				}
+				for path, last := range files {
+					if filepath.Dir(path) != dir {
+						continue
+					}
+					if current := statFile(path); current != last {
+						files[path] = current
+						emit(path)
+					}
+				}
+			}
+		}
+	}()
+
+	return nil
+}
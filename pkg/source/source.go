@@ -58,8 +58,34 @@ type SyncingSource interface {
 }
 
 // Kind creates a KindSource with the given cache provider.
-func Kind(cache cache.Cache, object client.Object) SyncingSource {
-	return &internal.Kind{Type: object, Cache: cache}
+func Kind(cache cache.Cache, object client.Object, opts ...KindOption) SyncingSource {
+	ks := &internal.Kind{Type: object, Cache: cache}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	return ks
+}
+
+// KindOption configures a KindSource.
+type KindOption func(*internal.Kind)
+
+// WithInitialListOrder makes the KindSource deliver the Create events for the initial list of
+// objects in the order given by less, instead of the random order of the informer, e.g. to
+// reconcile owners before the objects they own when the controller starts. The events are
+// delivered once the whole initial list was received, before the controller starts its workers.
+func WithInitialListOrder(less func(a, b client.Object) bool) KindOption {
+	return func(ks *internal.Kind) {
+		ks.InitialListLess = less
+	}
+}
+
+// ByNamespacedName orders objects by their namespace and name. It can be used with
+// WithInitialListOrder.
+func ByNamespacedName(a, b client.Object) bool {
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	return a.GetName() < b.GetName()
 }
 
 var _ Source = &Channel{}
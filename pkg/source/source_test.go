@@ -18,13 +18,23 @@ package source_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -32,6 +42,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -530,4 +541,412 @@ var _ = Describe("Source", func() {
 			})
 		})
 	})
+
+	Describe("DNS", func() {
+		It("should provide a GenericEvent when the resolved addresses change", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			addresses := make(chan []string, 1)
+			addresses <- []string{"10.0.0.2", "10.0.0.1"}
+			current := []string{}
+			target := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+			instance := &source.DNS{
+				Targets:  map[string]client.Object{"backend.example.com": target},
+				Interval: 10 * time.Millisecond,
+				LookupHost: func(_ context.Context, host string) ([]string, error) {
+					defer GinkgoRecover()
+					Expect(host).To(Equal("backend.example.com"))
+					select {
+					case current = <-addresses:
+					default:
+					}
+					return append([]string{}, current...), nil
+				},
+			}
+
+			events := make(chan event.GenericEvent, 10)
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					events <- evt
+				},
+			}, q)).To(Succeed())
+
+			// Neither the initial resolution nor a reordering of the addresses is a change.
+			Consistently(events).ShouldNot(Receive())
+			addresses <- []string{"10.0.0.1", "10.0.0.2"}
+			Consistently(events).ShouldNot(Receive())
+
+			addresses <- []string{"10.0.0.1", "10.0.0.3"}
+			Eventually(events).Should(Receive(Equal(event.GenericEvent{Object: target})))
+			Consistently(events).ShouldNot(Receive())
+		})
+
+		It("should get error if no targets are specified", func() {
+			instance := &source.DNS{}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Verifier", func() {
+		It("should provide a GenericEvent for the objects whose events the cache missed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pod := func(name string) *corev1.Pod {
+				return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+			}
+			apiServer := fake.NewClientBuilder().WithObjects(pod("unchanged"), pod("updated"), pod("deleted")).Build()
+			podList := &corev1.PodList{}
+			Expect(apiServer.List(ctx, podList)).To(Succeed())
+			cached := fake.NewClientBuilder()
+			for i := range podList.Items {
+				cached = cached.WithObjects(&podList.Items[i])
+			}
+			instance := &source.Verifier{
+				Type:      &corev1.Pod{},
+				Cache:     cached.Build(),
+				APIReader: apiServer,
+				Scheme:    scheme.Scheme,
+				Interval:  10 * time.Millisecond,
+			}
+
+			var mu sync.Mutex
+			events := map[string]client.Object{}
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					mu.Lock()
+					defer mu.Unlock()
+					events[evt.Object.GetName()] = evt.Object
+				},
+			}, q)).To(Succeed())
+
+			updated := pod("updated")
+			Expect(apiServer.Get(ctx, client.ObjectKeyFromObject(updated), updated)).To(Succeed())
+			updated.Labels = map[string]string{"changed": "true"}
+			Expect(apiServer.Update(ctx, updated)).To(Succeed())
+			Expect(apiServer.Delete(ctx, pod("deleted"))).To(Succeed())
+			Expect(apiServer.Create(ctx, pod("created"))).To(Succeed())
+
+			Eventually(func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				names := []string{}
+				for name := range events {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				return names
+			}).Should(Equal([]string{"created", "deleted", "updated"}))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(events["updated"]).To(BeAssignableToTypeOf(&metav1.PartialObjectMetadata{}))
+			Expect(events["updated"].GetResourceVersion()).To(Equal(updated.GetResourceVersion()))
+			Expect(events["deleted"]).To(BeAssignableToTypeOf(&corev1.Pod{}))
+		})
+
+		It("should get error if no readers are specified", func() {
+			instance := &source.Verifier{Type: &corev1.Pod{}, Scheme: scheme.Scheme}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Ticker", func() {
+		It("should provide a GenericEvent for every listed object in every interval", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pod := func(namespace, name string) *corev1.Pod {
+				return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+			}
+			c := fake.NewClientBuilder().WithObjects(pod("default", "foo"), pod("default", "bar"), pod("other", "baz")).Build()
+			instance := source.Ticker(10*time.Millisecond, source.ListObjects(c, &corev1.PodList{}, client.InNamespace("default")))
+
+			events := make(chan event.GenericEvent, 100)
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					events <- evt
+				},
+			}, q, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() != "bar"
+			}))).To(Succeed())
+
+			// The objects are listed again at every tick.
+			for i := 0; i < 2; i++ {
+				var evt event.GenericEvent
+				Eventually(events).Should(Receive(&evt))
+				Expect(evt.Object.GetNamespace()).To(Equal("default"))
+				Expect(evt.Object.GetName()).To(Equal("foo"))
+			}
+			Expect(c.Create(ctx, pod("default", "qux"))).To(Succeed())
+			Eventually(func() string {
+				var evt event.GenericEvent
+				Eventually(events).Should(Receive(&evt))
+				return evt.Object.GetName()
+			}).Should(Equal("qux"))
+		})
+
+		It("should get error if the interval or the lister aren't specified", func() {
+			lister := func(context.Context) ([]client.Object, error) { return nil, nil }
+			Expect(source.Ticker(0, lister).Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			Expect(source.Ticker(time.Second, nil).Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Webhook", func() {
+		It("should provide a GenericEvent for every object decoded from a request", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			server := &webhookServer{}
+			instance := &source.Webhook{
+				Server: server,
+				Path:   "/hooks/git",
+				Decode: func(req *http.Request) ([]client.Object, error) {
+					payload := struct{ Repositories []string }{}
+					if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+						return nil, err
+					}
+					objs := []client.Object{}
+					for _, name := range payload.Repositories {
+						objs = append(objs, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}})
+					}
+					return objs, nil
+				},
+			}
+
+			events := make(chan event.GenericEvent, 10)
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					events <- evt
+				},
+			}, q, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() != "ignored"
+			}))).To(Succeed())
+			Expect(server.path).To(Equal("/hooks/git"))
+
+			serve := func(method, body string) int {
+				rec := httptest.NewRecorder()
+				server.hook.ServeHTTP(rec, httptest.NewRequest(method, "/hooks/git", strings.NewReader(body)))
+				return rec.Code
+			}
+			Expect(serve(http.MethodPost, `{"repositories": ["foo", "ignored", "bar"]}`)).To(Equal(http.StatusAccepted))
+			var evt event.GenericEvent
+			Expect(events).To(Receive(&evt))
+			Expect(evt.Object.GetName()).To(Equal("foo"))
+			Expect(events).To(Receive(&evt))
+			Expect(evt.Object.GetName()).To(Equal("bar"))
+			Expect(events).NotTo(Receive())
+
+			Expect(serve(http.MethodPost, `{`)).To(Equal(http.StatusBadRequest))
+			Expect(serve(http.MethodGet, "")).To(Equal(http.StatusMethodNotAllowed))
+			cancel()
+			Expect(serve(http.MethodPost, `{"repositories": ["foo"]}`)).To(Equal(http.StatusServiceUnavailable))
+			Expect(events).NotTo(Receive())
+
+			// Restarting the source serves the new handler on the registered path.
+			restarted := make(chan event.GenericEvent, 10)
+			Expect(instance.Start(context.Background(), handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					restarted <- evt
+				},
+			}, q)).To(Succeed())
+			Expect(serve(http.MethodPost, `{"repositories": ["ignored"]}`)).To(Equal(http.StatusAccepted))
+			Expect(restarted).To(Receive(&evt))
+			Expect(evt.Object.GetName()).To(Equal("ignored"))
+			Expect(events).NotTo(Receive())
+		})
+
+		It("should get error if no server, path or decoder is specified", func() {
+			decode := func(*http.Request) ([]client.Object, error) { return nil, nil }
+			instance := &source.Webhook{Path: "/hook", Decode: decode}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			instance = &source.Webhook{Server: &webhookServer{}, Decode: decode}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			instance = &source.Webhook{Server: &webhookServer{}, Path: "/hook"}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("MessageBus", func() {
+		var subscriber *source.InProcessSubscriber
+		var instance *source.MessageBus
+
+		BeforeEach(func() {
+			subscriber = source.NewInProcessSubscriber()
+			instance = &source.MessageBus{
+				Subscriber: subscriber,
+				Decode: func(msg source.Message) ([]client.Object, error) {
+					name := string(msg.Data())
+					if name == "invalid" {
+						return nil, fmt.Errorf("invalid message")
+					}
+					return []client.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}}, nil
+				},
+			}
+		})
+
+		It("should provide a GenericEvent for every object decoded from a message", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events := make(chan event.GenericEvent, 10)
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					events <- evt
+				},
+			}, q, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() != "ignored"
+			}))).To(Succeed())
+
+			for _, name := range []string{"foo", "invalid", "ignored", "bar"} {
+				subscriber.Publish([]byte(name))
+			}
+			var evt event.GenericEvent
+			Eventually(events).Should(Receive(&evt))
+			Expect(evt.Object.GetName()).To(Equal("foo"))
+			Eventually(events).Should(Receive(&evt))
+			Expect(evt.Object.GetName()).To(Equal("bar"))
+			Consistently(events).ShouldNot(Receive())
+			Expect(subscriber.Pending()).To(BeZero())
+		})
+
+		It("should reject messages and stop receiving once the queue was shut down", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			q.ShutDown()
+			received := make(chan string, 10)
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					received <- evt.Object.GetName()
+				},
+			}, q)).To(Succeed())
+
+			subscriber.Publish([]byte("foo"))
+			Eventually(received).Should(Receive(Equal("foo")))
+			Consistently(received).ShouldNot(Receive())
+			// The rejected message is pending for redelivery.
+			Expect(subscriber.Pending()).To(Equal(1))
+		})
+
+		It("should get error if no subscriber or decoder is specified", func() {
+			instance.Subscriber = nil
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			instance = &source.MessageBus{Subscriber: subscriber}
+			Expect(instance.Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Describe("File", func() {
+		It("should provide a GenericEvent when watched files and directories change", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dir := GinkgoT().TempDir()
+			config := filepath.Join(dir, "config.yaml")
+			manifests := filepath.Join(dir, "manifests")
+			Expect(os.Mkdir(manifests, 0o755)).To(Succeed())
+			instance := source.File(func(_ context.Context, path string) []client.Object {
+				defer GinkgoRecover()
+				rel, err := filepath.Rel(dir, path)
+				Expect(err).NotTo(HaveOccurred())
+				return []client.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: rel}}}
+			}, config, manifests)
+
+			events := make(chan string, 100)
+			q := workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(), workqueue.RateLimitingQueueConfig{
+				Name: "test",
+			})
+			Expect(instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(_ context.Context, evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					events <- evt.Object.GetName()
+				},
+			}, q)).To(Succeed())
+
+			// The files are replaced by renames, so that every change is a single event.
+			replace := func(path, data string) {
+				Expect(os.WriteFile(filepath.Join(dir, "tmp"), []byte(data), 0o600)).To(Succeed())
+				Expect(os.Rename(filepath.Join(dir, "tmp"), path)).To(Succeed())
+			}
+
+			// The watched file is created after the source was started.
+			replace(config, "a: b")
+			Eventually(events).Should(Receive(Equal("config.yaml")))
+			Consistently(events).ShouldNot(Receive())
+
+			// Other files next to a watched file are ignored.
+			replace(filepath.Join(dir, "other.yaml"), "c: d")
+			Consistently(events).ShouldNot(Receive())
+
+			replace(config, "a: changed")
+			Eventually(events).Should(Receive(Equal("config.yaml")))
+
+			Expect(os.WriteFile(filepath.Join(manifests, "deployment.yaml"), []byte("kind: Deployment"), 0o600)).To(Succeed())
+			Eventually(events).Should(Receive(Equal(filepath.Join("manifests", "deployment.yaml"))))
+
+			// Watched directories are watched again when they are created again.
+			Expect(os.RemoveAll(manifests)).To(Succeed())
+			Eventually(events).Should(Receive(Equal(filepath.Join("manifests", "deployment.yaml"))))
+			Expect(os.Mkdir(manifests, 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(manifests, "service.yaml"), []byte("kind: Service"), 0o600)).To(Succeed())
+			Eventually(events).Should(Receive(Equal(filepath.Join("manifests", "service.yaml"))))
+		})
+
+		It("should get error if no mapper or paths are specified", func() {
+			mapper := func(context.Context, string) []client.Object { return nil }
+			Expect(source.File(nil, GinkgoT().TempDir()).Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			Expect(source.File(mapper).Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+			Expect(source.File(mapper, "/does/not/exist/config.yaml").Start(context.Background(), handler.Funcs{}, nil)).NotTo(Succeed())
+		})
+	})
 })
+
+var _ = Describe("ByNamespacedName", func() {
+	It("should order objects by namespace and name", func() {
+		objs := []client.Object{
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "b", Name: "a"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "b"}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "a"}},
+		}
+		sort.Slice(objs, func(i, j int) bool { return source.ByNamespacedName(objs[i], objs[j]) })
+
+		names := []string{}
+		for _, obj := range objs {
+			names = append(names, client.ObjectKeyFromObject(obj).String())
+		}
+		Expect(names).To(Equal([]string{"a/a", "a/b", "b/a"}))
+	})
+})
+
+type webhookServer struct {
+	path string
+	hook http.Handler
+}
+
+func (s *webhookServer) Register(path string, hook http.Handler) {
+	if s.hook != nil {
+		panic(fmt.Sprintf("can't register duplicate path: %v", path))
+	}
+	s.path = path
+	s.hook = hook
+}
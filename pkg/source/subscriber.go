@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// defaultReceiveRetryInterval is the default interval in which failed receives are retried.
+	defaultReceiveRetryInterval = 5 * time.Second
+)
+
+var messageBusLog = logf.RuntimeLog.WithName("source").WithName("MessageBus")
+
+// Message is a message received from a message bus.
+type Message interface {
+	// Data returns the payload of the message.
+	Data() []byte
+
+	// Ack acknowledges that the message was processed.
+	Ack() error
+
+	// Nack signals that the message couldn't be processed. Whether and when it is
+	// redelivered depends on the message bus.
+	Nack() error
+}
+
+// Subscriber is a driver receiving the messages of a message bus, e.g. a NATS
+// subscription or a Kafka consumer.
+type Subscriber interface {
+	// Receive blocks until a message is received or ctx is done.
+	Receive(ctx context.Context) (Message, error)
+}
+
+// MessageDecoder decodes a message into the objects the GenericEvents are emitted for.
+// Messages that can't be decoded are logged and dropped, since redelivering them
+// wouldn't help.
+type MessageDecoder func(msg Message) ([]client.Object, error)
+
+var _ Source = &MessageBus{}
+
+// MessageBus is used to provide a source of events originating from the messages of
+// a message bus, e.g. to reconcile objects in response to notifications of external
+// systems. The messages are received from the Subscriber one at a time, and a
+// GenericEvent is emitted for every object decoded from a message. A message is
+// acknowledged once its events were passed to the handler, i.e. once the requests
+// were queued for the handlers of controllers, and rejected if the queue was shut
+// down, so that the message bus can redeliver it, e.g. to the next leader, in which
+// case no further messages are received. Failed receives are logged and retried.
+type MessageBus struct {
+	// Subscriber receives the messages.
+	Subscriber Subscriber
+
+	// Decode decodes the messages.
+	Decode MessageDecoder
+
+	// RetryInterval is the interval in which failed receives are retried.
+	// Defaults to 5 seconds.
+	RetryInterval time.Duration
+}
+
+func (ms *MessageBus) String() string {
+	return fmt.Sprintf("message bus source: %T", ms.Subscriber)
+}
+
+// Start implements Source and should only be called by the Controller.
+func (ms *MessageBus) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if ms.Subscriber == nil {
+		return fmt.Errorf("must specify MessageBus.Subscriber")
+	}
+	if ms.Decode == nil {
+		return fmt.Errorf("must specify MessageBus.Decode")
+	}
+
+	retryInterval := ms.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultReceiveRetryInterval
+	}
+
+	go func() {
+		for {
+			msg, err := ms.Subscriber.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				messageBusLog.Error(err, "failed to receive message")
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(retryInterval):
+				}
+				continue
+			}
+			if !ms.handle(ctx, msg, handler, queue, prct) {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handle emits the events of msg, and returns false if the queue was shut down.
+func (ms *MessageBus) handle(ctx context.Context, msg Message, handler handler.EventHandler, queue workqueue.RateLimitingInterface, prct []predicate.Predicate) bool {
+	objs, err := ms.Decode(msg)
+	if err != nil {
+		messageBusLog.Error(err, "failed to decode message, dropping it")
+		if err := msg.Ack(); err != nil {
+			messageBusLog.Error(err, "failed to acknowledge message")
+		}
+		return true
+	}
+
+	for _, obj := range objs {
+		evt := event.GenericEvent{Object: obj}
+		shouldHandle := true
+		for _, p := range prct {
+			if !p.Generic(evt) {
+				shouldHandle = false
+				break
+			}
+		}
+		if shouldHandle {
+			handler.Generic(ctx, evt, queue)
+		}
+	}
+
+	if queue.ShuttingDown() {
+		if err := msg.Nack(); err != nil {
+			messageBusLog.Error(err, "failed to reject message")
+		}
+		return false
+	}
+	if err := msg.Ack(); err != nil {
+		messageBusLog.Error(err, "failed to acknowledge message")
+	}
+	return true
+}
+
+var _ Subscriber = &InProcessSubscriber{}
+
+// InProcessSubscriber is a Subscriber receiving the messages published in the same
+// process, e.g. by other components of the manager or in tests. Rejected messages are
+// redelivered after the messages published in the meantime.
+type InProcessSubscriber struct {
+	mu      sync.Mutex
+	pending [][]byte
+	notify  chan struct{}
+}
+
+// NewInProcessSubscriber returns a new InProcessSubscriber.
+func NewInProcessSubscriber() *InProcessSubscriber {
+	return &InProcessSubscriber{notify: make(chan struct{}, 1)}
+}
+
+// Publish publishes a message with the payload data. It never blocks.
+func (s *InProcessSubscriber) Publish(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, data)
+	s.signal()
+}
+
+// Pending returns the number of messages that weren't received yet.
+func (s *InProcessSubscriber) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Receive implements Subscriber.
+func (s *InProcessSubscriber) Receive(ctx context.Context) (Message, error) {
+	for {
+		s.mu.Lock()
+		if len(s.pending) > 0 {
+			data := s.pending[0]
+			s.pending = s.pending[1:]
+			if len(s.pending) > 0 {
+				// Wake up the next receiver for the remaining messages.
+				s.signal()
+			}
+			s.mu.Unlock()
+			return &inProcessMessage{subscriber: s, data: data}, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.notify:
+		}
+	}
+}
+
+// signal wakes up a receiver, and must be called with the lock held.
+func (s *InProcessSubscriber) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// inProcessMessage is a Message received by an InProcessSubscriber.
+type inProcessMessage struct {
+	subscriber *InProcessSubscriber
+	data       []byte
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Data implements Message.
+func (m *inProcessMessage) Data() []byte {
+	return m.data
+}
+
+// Ack implements Message.
+func (m *inProcessMessage) Ack() error {
+	return m.complete()
+}
+
+// Nack implements Message.
+func (m *inProcessMessage) Nack() error {
+	if err := m.complete(); err != nil {
+		return err
+	}
+	m.subscriber.Publish(m.data)
+	return nil
+}
+
+func (m *inProcessMessage) complete() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return errors.New("message was already acknowledged or rejected")
+	}
+	m.done = true
+	return nil
+}
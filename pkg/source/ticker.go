@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var tickerLog = logf.RuntimeLog.WithName("source").WithName("Ticker")
+
+// ObjectLister lists the objects a Ticker emits events for.
+type ObjectLister func(ctx context.Context) ([]client.Object, error)
+
+// ListObjects returns an ObjectLister listing the objects of list, e.g.
+// &appsv1.DeploymentList{}, with reader, which should be a cache like the one of the
+// manager. The objects can be selected with opts, e.g. client.InNamespace.
+func ListObjects(reader client.Reader, list client.ObjectList, opts ...client.ListOption) ObjectLister {
+	return func(ctx context.Context) ([]client.Object, error) {
+		// Lists are filled in place, so every tick lists into a copy.
+		l := list.DeepCopyObject().(client.ObjectList)
+		if err := reader.List(ctx, l, opts...); err != nil {
+			return nil, err
+		}
+		var objs []client.Object
+		if err := meta.EachListItem(l, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok {
+				return fmt.Errorf("list item %T is not an object", item)
+			}
+			objs = append(objs, obj)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return objs, nil
+	}
+}
+
+// Ticker creates a source emitting a GenericEvent for every object listed by list in
+// the given interval, e.g. to periodically reconcile all objects for drift detection
+// independently of the resyncs of the informers:
+//
+//	source.Ticker(time.Hour, source.ListObjects(mgr.GetCache(), &appsv1.DeploymentList{}))
+//
+// The first events are emitted one interval after the source was started. Failed lists
+// are logged and retried at the next tick.
+func Ticker(interval time.Duration, list ObjectLister) Source {
+	return &ticker{interval: interval, list: list}
+}
+
+type ticker struct {
+	interval time.Duration
+	list     ObjectLister
+}
+
+func (ts *ticker) String() string {
+	return fmt.Sprintf("ticker source: %s", ts.interval)
+}
+
+// Start implements Source and should only be called by the Controller.
+func (ts *ticker) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if ts.interval <= 0 {
+		return fmt.Errorf("must specify a positive Ticker interval")
+	}
+	if ts.list == nil {
+		return fmt.Errorf("must specify a Ticker ObjectLister")
+	}
+
+	go func() {
+		t := time.NewTicker(ts.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			objs, err := ts.list(ctx)
+			if err != nil {
+				tickerLog.Error(err, "failed to list objects")
+				continue
+			}
+			for _, obj := range objs {
+				evt := event.GenericEvent{Object: obj}
+				shouldHandle := true
+				for _, p := range prct {
+					if !p.Generic(evt) {
+						shouldHandle = false
+						break
+					}
+				}
+				if shouldHandle {
+					handler.Generic(ctx, evt, queue)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// defaultVerifyInterval is the default interval in which the cache is verified.
+	defaultVerifyInterval = 10 * time.Minute
+
+	// verifyPageSize is the page size of the metadata-only lists from the API server.
+	verifyPageSize = 500
+)
+
+var verifierLog = logf.RuntimeLog.WithName("source").WithName("Verifier")
+
+var (
+	// verifierMissedEvents counts the events the verifier found to be missed.
+	verifierMissedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_verifier_missed_events_total",
+		Help: "Total number of events found to be missed by the cache per group, version, kind and event type",
+	}, []string{"group", "version", "kind", "event"})
+
+	// verifierErrors counts the verifications that failed.
+	verifierErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_verifier_errors_total",
+		Help: "Total number of failed verifications of the cache per group, version and kind",
+	}, []string{"group", "version", "kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(verifierMissedEvents, verifierErrors)
+}
+
+var _ Source = &Verifier{}
+
+// Verifier is used to provide a source of events for objects whose changes the
+// cache appears to have missed, as a safety net against rarely dropped watch
+// events in long-lived controllers. In every interval, the resourceVersions of the
+// cached objects are compared against a metadata-only List from the API server.
+// Objects whose resourceVersions differ, that are only cached or that aren't cached
+// at all are reported if the difference persists until the next verification, so
+// that events that are still in flight aren't reported. A GenericEvent is emitted
+// for each reported object, with the cached object for missed deletes and the
+// metadata from the API server otherwise.
+//
+// The ListOptions must select the same objects as the cache does, e.g. the same
+// namespace and label selector, as objects that aren't cached are reported too.
+type Verifier struct {
+	// Type is the type of the verified objects.
+	Type client.Object
+
+	// Cache is the reader for the cached objects, e.g. the cache of the manager.
+	Cache client.Reader
+
+	// APIReader is the reader for the objects in the API server, e.g. the
+	// APIReader of the manager.
+	APIReader client.Reader
+
+	// Scheme is used to determine the GroupVersionKind of Type.
+	Scheme *runtime.Scheme
+
+	// Interval is the interval in which the cache is verified.
+	// Defaults to 10 minutes.
+	Interval time.Duration
+
+	// ListOptions are the options of the lists from the cache and the API server.
+	ListOptions []client.ListOption
+}
+
+func (vs *Verifier) String() string {
+	return fmt.Sprintf("verifier source: %p", vs)
+}
+
+// missedEvent is an object whose cached resourceVersion differs from the one in the API server.
+type missedEvent struct {
+	// eventType is the type of the missed event, i.e. "create", "update" or "delete".
+	eventType string
+
+	// cachedVersion is the resourceVersion of the cached object, or empty if it isn't cached.
+	cachedVersion string
+
+	// object is the object the GenericEvent is emitted for.
+	object client.Object
+}
+
+// Start implements Source and should only be called by the Controller.
+func (vs *Verifier) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if vs.Type == nil {
+		return fmt.Errorf("must specify Verifier.Type")
+	}
+	if vs.Cache == nil || vs.APIReader == nil {
+		return fmt.Errorf("must specify Verifier.Cache and Verifier.APIReader")
+	}
+	if vs.Scheme == nil {
+		return fmt.Errorf("must specify Verifier.Scheme")
+	}
+	gvk, err := apiutil.GVKForObject(vs.Type, vs.Scheme)
+	if err != nil {
+		return err
+	}
+	if _, err := vs.newCachedList(gvk); err != nil {
+		return err
+	}
+
+	interval := vs.Interval
+	if interval <= 0 {
+		interval = defaultVerifyInterval
+	}
+	log := verifierLog.WithValues("kind", gvk.Kind)
+
+	go func() {
+		// suspects are the differences found by the previous verification.
+		suspects := map[string]missedEvent{}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			differences, err := vs.verify(ctx, gvk)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error(err, "failed to verify the cache")
+					verifierErrors.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+				}
+				continue
+			}
+
+			for key, difference := range differences {
+				suspect, ok := suspects[key]
+				if !ok || suspect.cachedVersion != difference.cachedVersion {
+					continue
+				}
+				// The cache didn't change for a whole interval, so the event was missed.
+				delete(differences, key)
+				log.V(1).Info("Found missed event", "event", difference.eventType, "object", key)
+				verifierMissedEvents.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, difference.eventType).Inc()
+
+				evt := event.GenericEvent{Object: difference.object}
+				shouldHandle := true
+				for _, p := range prct {
+					if !p.Generic(evt) {
+						shouldHandle = false
+						break
+					}
+				}
+				if shouldHandle {
+					handler.Generic(ctx, evt, queue)
+				}
+			}
+			suspects = differences
+		}
+	}()
+
+	return nil
+}
+
+// verify returns the objects whose resourceVersions differ between the API server
+// and the cache by key.
+func (vs *Verifier) verify(ctx context.Context, gvk schema.GroupVersionKind) (map[string]missedEvent, error) {
+	// List from the API server first, so that the cache had the time to catch up.
+	live, err := vs.listLive(ctx, gvk)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := vs.listCached(ctx, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	differences := map[string]missedEvent{}
+	for key, obj := range live {
+		cachedObj, ok := cached[key]
+		switch {
+		case !ok:
+			differences[key] = missedEvent{eventType: "create", object: obj}
+		case cachedObj.GetResourceVersion() != obj.GetResourceVersion():
+			differences[key] = missedEvent{eventType: "update", cachedVersion: cachedObj.GetResourceVersion(), object: obj}
+		}
+	}
+	for key, cachedObj := range cached {
+		if _, ok := live[key]; !ok {
+			differences[key] = missedEvent{
+				eventType:     "delete",
+				cachedVersion: cachedObj.GetResourceVersion(),
+				object:        cachedObj.DeepCopyObject().(client.Object),
+			}
+		}
+	}
+	return differences, nil
+}
+
+// listLive lists the metadata of the objects from the API server page by page.
+func (vs *Verifier) listLive(ctx context.Context, gvk schema.GroupVersionKind) (map[string]client.Object, error) {
+	objs := map[string]client.Object{}
+	continueToken := ""
+	for {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		opts := append([]client.ListOption{client.Limit(verifyPageSize), client.Continue(continueToken)}, vs.ListOptions...)
+		if err := vs.APIReader.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("failed to list from the API server: %w", err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			obj.SetGroupVersionKind(gvk)
+			objs[client.ObjectKeyFromObject(obj).String()] = obj
+		}
+		continueToken = list.GetContinue()
+		if continueToken == "" {
+			return objs, nil
+		}
+	}
+}
+
+// listCached lists the cached objects without copying them.
+func (vs *Verifier) listCached(ctx context.Context, gvk schema.GroupVersionKind) (map[string]client.Object, error) {
+	list, err := vs.newCachedList(gvk)
+	if err != nil {
+		return nil, err
+	}
+	opts := append([]client.ListOption{client.UnsafeDisableDeepCopy}, vs.ListOptions...)
+	if err := vs.Cache.List(ctx, list, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list from the cache: %w", err)
+	}
+
+	objs := map[string]client.Object{}
+	err = meta.EachListItem(list, func(item runtime.Object) error {
+		obj, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("cached object %T is not a client.Object", item)
+		}
+		objs[client.ObjectKeyFromObject(obj).String()] = obj
+		return nil
+	})
+	return objs, err
+}
+
+// newCachedList returns an empty list for the type of the verified objects.
+func (vs *Verifier) newCachedList(gvk schema.GroupVersionKind) (client.ObjectList, error) {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	switch vs.Type.(type) {
+	case *unstructured.Unstructured:
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(listGVK)
+		return list, nil
+	case *metav1.PartialObjectMetadata:
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(listGVK)
+		return list, nil
+	}
+
+	obj, err := vs.Scheme.New(listGVK)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(client.ObjectList)
+	if !ok {
+		return nil, errors.New("list of the verified objects is not a client.ObjectList")
+	}
+	return list, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var webhookLog = logf.RuntimeLog.WithName("source").WithName("Webhook")
+
+var _ Source = &Webhook{}
+
+// WebhookServer is a server the HTTP endpoint of a Webhook is registered on, e.g. the
+// webhook.Server returned by the GetWebhookServer method of the manager.
+type WebhookServer interface {
+	// Register marks the given handler as being served at the given path.
+	Register(path string, hook http.Handler)
+}
+
+// WebhookDecoder decodes the payload of a request to a Webhook into the objects the
+// GenericEvents are emitted for. A returned error rejects the request.
+type WebhookDecoder func(req *http.Request) ([]client.Object, error)
+
+// Webhook is used to provide a source of events originating from external systems
+// calling an HTTP endpoint, e.g. the push notifications of Git providers or the
+// callbacks of cloud providers. The endpoint is registered on the Server when the
+// source is first started, as servers can't register a path twice, and serves the
+// handler and queue of the last start of the source. A GenericEvent is emitted for every object decoded from
+// the payload of a POST request. Requests are answered with 202 Accepted once the
+// events were emitted, 400 Bad Request if the payload couldn't be decoded and 503
+// Service Unavailable after the source was stopped.
+//
+// Requests aren't authenticated by the Webhook, so Decode should verify the
+// signatures or tokens the calling system provides.
+type Webhook struct {
+	// Server is the server the endpoint is registered on.
+	Server WebhookServer
+
+	// Path is the path the endpoint is served at.
+	Path string
+
+	// Decode decodes the payloads of the requests.
+	Decode WebhookDecoder
+
+	registerOnce sync.Once
+	target       atomic.Pointer[webhookTarget]
+}
+
+// webhookTarget is what a start of a Webhook emits the events of the requests to.
+type webhookTarget struct {
+	ctx     context.Context
+	handler handler.EventHandler
+	queue   workqueue.RateLimitingInterface
+	prct    []predicate.Predicate
+}
+
+func (ws *Webhook) String() string {
+	return fmt.Sprintf("webhook source: %s", ws.Path)
+}
+
+// Start implements Source and should only be called by the Controller.
+func (ws *Webhook) Start(
+	ctx context.Context,
+	handler handler.EventHandler,
+	queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if ws.Server == nil {
+		return fmt.Errorf("must specify Webhook.Server")
+	}
+	if ws.Path == "" {
+		return fmt.Errorf("must specify Webhook.Path")
+	}
+	if ws.Decode == nil {
+		return fmt.Errorf("must specify Webhook.Decode")
+	}
+
+	// Restarted sources replace the target of the handler registered by the first start.
+	ws.target.Store(&webhookTarget{ctx: ctx, handler: handler, queue: queue, prct: prct})
+	ws.registerOnce.Do(func() {
+		ws.Server.Register(ws.Path, http.HandlerFunc(ws.serveHTTP))
+	})
+
+	return nil
+}
+
+func (ws *Webhook) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	target := ws.target.Load()
+	if target.ctx.Err() != nil {
+		http.Error(w, "the source was stopped", http.StatusServiceUnavailable)
+		return
+	}
+
+	objs, err := ws.Decode(req)
+	if err != nil {
+		webhookLog.V(1).Info("Rejecting request", "path", ws.Path, "error", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, obj := range objs {
+		evt := event.GenericEvent{Object: obj}
+		shouldHandle := true
+		for _, p := range target.prct {
+			if !p.Generic(evt) {
+				shouldHandle = false
+				break
+			}
+		}
+		if shouldHandle {
+			target.handler.Generic(target.ctx, evt, target.queue)
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ImmutableTag is the struct tag that declares a field as immutable, e.g.
+//
+//	StorageClassName string `json:"storageClassName" immutable:"true"`
+const ImmutableTag = "immutable"
+
+// WithImmutableFields returns a CustomValidator that rejects updates changing an immutable
+// field of the object, before delegating to the given validator. Immutable fields are
+// declared with the ImmutableTag struct tag on the fields of typed objects, or as paths
+// of dot separated JSON field names such as "spec.storageClassName". The validator may
+// be nil to only enforce immutability.
+func WithImmutableFields(validator CustomValidator, paths ...string) CustomValidator {
+	return &immutableFieldsValidator{validator: validator, paths: paths}
+}
+
+type immutableFieldsValidator struct {
+	validator CustomValidator
+	paths     []string
+}
+
+// ValidateCreate implements CustomValidator.
+func (v *immutableFieldsValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (Warnings, error) {
+	if v.validator == nil {
+		return nil, nil
+	}
+	return v.validator.ValidateCreate(ctx, obj)
+}
+
+// ValidateUpdate implements CustomValidator.
+func (v *immutableFieldsValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (Warnings, error) {
+	errs, err := ValidateImmutableFields(oldObj, newObj, v.paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		var name string
+		if accessor, err := meta.Accessor(newObj); err == nil {
+			name = accessor.GetName()
+		}
+		return nil, apierrors.NewInvalid(newObj.GetObjectKind().GroupVersionKind().GroupKind(), name, errs)
+	}
+
+	if v.validator == nil {
+		return nil, nil
+	}
+	return v.validator.ValidateUpdate(ctx, oldObj, newObj)
+}
+
+// ValidateDelete implements CustomValidator.
+func (v *immutableFieldsValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (Warnings, error) {
+	if v.validator == nil {
+		return nil, nil
+	}
+	return v.validator.ValidateDelete(ctx, obj)
+}
+
+// ValidateImmutableFields returns an error for every immutable field that differs between
+// oldObj and newObj. The immutable fields are the fields of the type of newObj declared
+// with the ImmutableTag struct tag and the given paths of dot separated JSON field names.
+// Setting or removing an immutable field counts as a change. It can be used in the
+// ValidateUpdate method of a CustomValidator.
+func ValidateImmutableFields(oldObj, newObj runtime.Object, paths ...string) (field.ErrorList, error) {
+	oldContent, err := toUnstructuredContent(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newContent, err := toUnstructuredContent(newObj)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var errs field.ErrorList
+	for _, path := range append(immutablePathsFor(reflect.TypeOf(newObj), nil, map[reflect.Type]bool{}), paths...) {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		fields := strings.Split(path, ".")
+		// Missing fields are nil, so setting or removing a field is a change.
+		oldValue, _, err := unstructured.NestedFieldNoCopy(oldContent, fields...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get field %q of old object: %w", path, err)
+		}
+		newValue, _, err := unstructured.NestedFieldNoCopy(newContent, fields...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get field %q of new object: %w", path, err)
+		}
+		errs = append(errs, apivalidation.ValidateImmutableField(newValue, oldValue, field.NewPath(fields[0], fields[1:]...))...)
+	}
+	return errs, nil
+}
+
+func toUnstructuredContent(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to unstructured: %w", obj, err)
+	}
+	return content, nil
+}
+
+// immutablePathsFor returns the paths of the fields of t and its nested structs that are
+// declared immutable with the ImmutableTag. Lists and maps are not descended into.
+func immutablePathsFor(t reflect.Type, prefix []string, visiting map[reflect.Type]bool) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, inline := jsonFieldName(f)
+		if name == "-" {
+			continue
+		}
+		path := prefix
+		if !inline {
+			path = append(append([]string(nil), prefix...), name)
+		}
+		if f.Tag.Get(ImmutableTag) == "true" && !inline {
+			paths = append(paths, strings.Join(path, "."))
+			continue
+		}
+		paths = append(paths, immutablePathsFor(f.Type, path, visiting)...)
+	}
+	return paths
+}
+
+// jsonFieldName returns the JSON name of the field and whether it is inlined into its parent.
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" && opts == "" {
+		return "-", false
+	}
+	if strings.Contains(","+opts+",", ",inline,") || (name == "" && f.Anonymous) {
+		return "", true
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, false
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("immutable fields", func() {
+	var oldObj, newObj *immutableObject
+
+	BeforeEach(func() {
+		oldObj = &immutableObject{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "example.com/v1", Kind: "Widget"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec:       immutableSpec{Size: "large", Replicas: 1},
+		}
+		newObj = oldObj.DeepCopyObject().(*immutableObject)
+	})
+
+	It("should allow changes of mutable fields", func() {
+		newObj.Spec.Replicas = 3
+		Expect(ValidateImmutableFields(oldObj, newObj, "spec.class")).To(BeEmpty())
+	})
+
+	It("should reject changes of fields declared immutable with the struct tag", func() {
+		newObj.Spec.Size = "small"
+		errs, err := ValidateImmutableFields(oldObj, newObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.size"))
+		Expect(errs[0].Detail).To(Equal("field is immutable"))
+	})
+
+	It("should reject setting a field declared immutable with a path", func() {
+		newObj.Spec.Class = ptr.To("gold")
+		errs, err := ValidateImmutableFields(oldObj, newObj, "spec.class")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.class"))
+	})
+
+	It("should validate unstructured objects", func() {
+		oldU := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"size": "large"}}}
+		newU := oldU.DeepCopy()
+		Expect(unstructured.SetNestedField(newU.Object, "small", "spec", "size")).To(Succeed())
+
+		errs, err := ValidateImmutableFields(oldU, newU, "spec.size")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.size"))
+	})
+
+	It("should return an Invalid error from the validator before delegating", func() {
+		delegate := &fakeImmutableDelegate{}
+		validator := WithImmutableFields(delegate)
+
+		_, err := validator.ValidateUpdate(context.Background(), oldObj, newObj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(delegate.updates).To(Equal(1))
+
+		newObj.Spec.Size = "small"
+		_, err = validator.ValidateUpdate(context.Background(), oldObj, newObj)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`Widget.example.com "foo" is invalid: spec.size`))
+		Expect(delegate.updates).To(Equal(1))
+	})
+
+	It("should allow all operations without a validator", func() {
+		validator := WithImmutableFields(nil)
+		Expect(validator.ValidateCreate(context.Background(), newObj)).To(BeEmpty())
+		Expect(validator.ValidateUpdate(context.Background(), oldObj, newObj)).To(BeEmpty())
+		Expect(validator.ValidateDelete(context.Background(), oldObj)).To(BeEmpty())
+	})
+})
+
+type immutableSpec struct {
+	Size     string  `json:"size" immutable:"true"`
+	Replicas int     `json:"replicas"`
+	Class    *string `json:"class,omitempty"`
+}
+
+type immutableObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              immutableSpec `json:"spec"`
+}
+
+func (o *immutableObject) DeepCopyObject() runtime.Object {
+	out := *o
+	o.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if o.Spec.Class != nil {
+		out.Spec.Class = ptr.To(*o.Spec.Class)
+	}
+	return &out
+}
+
+func (o *immutableObject) GetObjectKind() schema.ObjectKind { return &o.TypeMeta }
+
+type fakeImmutableDelegate struct {
+	updates int
+}
+
+func (v *fakeImmutableDelegate) ValidateCreate(context.Context, runtime.Object) (Warnings, error) {
+	return nil, nil
+}
+
+func (v *fakeImmutableDelegate) ValidateUpdate(context.Context, runtime.Object, runtime.Object) (Warnings, error) {
+	v.updates++
+	return nil, nil
+}
+
+func (v *fakeImmutableDelegate) ValidateDelete(context.Context, runtime.Object) (Warnings, error) {
+	return nil, nil
+}